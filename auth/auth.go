@@ -49,6 +49,8 @@ var (
 type simpleProvider struct {
 	Token  string
 	Cookie []*http.Cookie
+	// OAuth is set, if the token was obtained with the OAuth flow.
+	OAuth *oauthState `json:",omitempty"`
 }
 
 func (c simpleProvider) Validate() error {
@@ -62,6 +64,9 @@ func (c simpleProvider) Validate() error {
 }
 
 func (c simpleProvider) SlackToken() string {
+	if c.OAuth != nil {
+		return c.OAuth.current(c.Token)
+	}
 	return c.Token
 }
 
@@ -92,6 +97,9 @@ func Save(w io.Writer, p Provider) error {
 		Token:  p.SlackToken(),
 		Cookie: p.Cookies(),
 	}
+	if op, ok := p.(interface{ oauth() *oauthState }); ok {
+		s.OAuth = op.oauth()
+	}
 
 	enc := json.NewEncoder(w)
 	if err := enc.Encode(s); err != nil {
@@ -127,8 +135,21 @@ func (s simpleProvider) Test(ctx context.Context) (*slack.AuthTestResponse, erro
 	return ai, nil
 }
 
+// HTTPClient returns the HTTP client with the session cookies.  If the token
+// can be refreshed, the client refreshes it before it expires.
 func (s simpleProvider) HTTPClient() (*http.Client, error) {
-	return chttp.New(SlackURL, s.Cookies())
+	cl, err := chttp.New(SlackURL, s.Cookies())
+	if err != nil {
+		return nil, err
+	}
+	if s.OAuth != nil && s.OAuth.RefreshToken != "" {
+		base := cl.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		cl.Transport = &rotatingTransport{base: base, st: s.OAuth, initial: s.Token, flow: defFlow}
+	}
+	return cl, nil
 }
 
 func IsDocker() bool {
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"runtime/trace"
+	"strings"
+
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+var _ Provider = BrowserProfileAuth{}
+
+// BrowserProfileAuth is the provider with the Slack cookies, read from the
+// browser profile installed on this machine, and the token, obtained with
+// them from the workspace page.  It allows to log in on the machine, where
+// the browser can't be opened, but the user has logged in to Slack before.
+//
+// Reading the browser cookies must only be done with the user's consent.
+type BrowserProfileAuth struct {
+	simpleProvider
+}
+
+// CookieStore is the cookie store of the browser profile.
+type CookieStore interface {
+	// String returns the human readable name of the store.
+	String() string
+	// Cookies returns the cookies of the domain and its subdomains.
+	Cookies(ctx context.Context, domain string) ([]*http.Cookie, error)
+}
+
+// ProfileReader returns the cookie stores of the browser profiles, see
+// [NewBrowserProfileAuth] for the browser values.
+type ProfileReader func(browser string) ([]CookieStore, error)
+
+// maxPageSize is the maximum size of the workspace page, that is searched
+// for the token.
+const maxPageSize = 16 << 20
+
+// workspaceURL returns the URL of the workspace page.
+var workspaceURL = func(wsp string) string {
+	return "https://" + wsp + ".slack.com/"
+}
+
+// NewBrowserProfileAuth returns the provider with the cookies from the
+// browser profiles.  browser is the name of the browser, "auto" or empty for
+// all browsers, or the path to the cookie database file.  The profiles are
+// read with the reader, that must be set with [BrowserWithProfileReader],
+// and the workspace must be set with [BrowserWithWorkspace].  The profiles
+// are tried in turn, until the one, that is logged in to the workspace, is
+// found.
+func NewBrowserProfileAuth(ctx context.Context, browser string, opts ...Option) (BrowserProfileAuth, error) {
+	ctx, task := trace.NewTask(ctx, "NewBrowserProfileAuth")
+	defer task.End()
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.workspace == "" {
+		return BrowserProfileAuth{}, errors.New("workspace name is required to log in with the browser profile")
+	}
+	if o.profileReader == nil {
+		return BrowserProfileAuth{}, errors.New("internal error: browser profile reader is not set")
+	}
+	wsp, err := structures.ExtractWorkspace(o.workspace)
+	if err != nil {
+		return BrowserProfileAuth{}, err
+	}
+	stores, err := o.profileReader(browser)
+	if err != nil {
+		return BrowserProfileAuth{}, err
+	}
+
+	var errs []error
+	for _, s := range stores {
+		token, cookies, err := storeCreds(ctx, s, workspaceURL(wsp))
+		if err != nil {
+			slog.DebugContext(ctx, "browser profile skipped", "profile", s.String(), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", s, err))
+			continue
+		}
+		slog.InfoContext(ctx, "using the Slack login from the browser profile", "profile", s.String())
+		return BrowserProfileAuth{simpleProvider{Token: token, Cookie: cookies}}, nil
+	}
+	return BrowserProfileAuth{}, &Error{Err: ErrNoCookies, Msg: "no browser profile is logged in to " + wsp + ": " + errors.Join(errs...).Error()}
+}
+
+// storeCreds reads the Slack cookies from the store, and obtains the token
+// from the workspace page at pageURL.
+func storeCreds(ctx context.Context, s CookieStore, pageURL string) (string, []*http.Cookie, error) {
+	cookies, err := s.Cookies(ctx, "slack.com")
+	if err != nil {
+		return "", nil, err
+	}
+	if !hasCookie(cookies, "d") {
+		return "", nil, ErrNoCookies
+	}
+	jar, err := slackJar(cookies)
+	if err != nil {
+		return "", nil, err
+	}
+	token, err := fetchToken(ctx, &http.Client{Jar: jar}, pageURL)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, cookies, nil
+}
+
+func hasCookie(cookies []*http.Cookie, name string) bool {
+	for _, c := range cookies {
+		if c.Name == name && c.Value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// slackJar returns the cookie jar with the cookies, so that they are sent to
+// the hosts, that they were set for, including the redirects between the
+// Slack subdomains.
+func slackJar(cookies []*http.Cookie) (*cookiejar.Jar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cookies {
+		u := &url.URL{Scheme: "https", Host: strings.TrimPrefix(c.Domain, "."), Path: "/"}
+		jar.SetCookies(u, []*http.Cookie{c})
+	}
+	return jar, nil
+}
+
+// fetchToken requests the page with the client cl, and returns the client
+// token, that the page contains, if the user is logged in.
+func fetchToken(ctx context.Context, cl *http.Client, pageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := cl.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: unexpected status: %s", pageURL, resp.Status)
+	}
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return "", err
+	}
+	token := tokenRE.Find(page)
+	if token == nil {
+		return "", fmt.Errorf("%w: not logged in to the workspace, or the session has expired", ErrNoToken)
+	}
+	return string(token), nil
+}
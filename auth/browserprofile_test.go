@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testClientToken = "xoxc-1-2-3-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// fakeStore is the cookie store with the "d" cookie value d, if it is not
+// empty.
+type fakeStore string
+
+func (d fakeStore) String() string { return "fake" }
+
+func (d fakeStore) Cookies(context.Context, string) ([]*http.Cookie, error) {
+	if d == "" {
+		return nil, nil
+	}
+	return []*http.Cookie{{Name: "d", Value: string(d), Domain: ".slack.com", Path: "/"}}, nil
+}
+
+// fakeReader returns the profile reader, that returns the store with the
+// "d" cookie value d.
+func fakeReader(d string) Option {
+	return BrowserWithProfileReader(func(string) ([]CookieStore, error) {
+		return []CookieStore{fakeStore(d)}, nil
+	})
+}
+
+func TestNewBrowserProfileAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the jar does not send the .slack.com cookies to the test
+		// server, so the page is always returned.
+		w.Write([]byte(`<script>var boot_data = {"api_token":"` + testClientToken + `"};</script>`))
+	}))
+	defer srv.Close()
+	oldURL := workspaceURL
+	t.Cleanup(func() { workspaceURL = oldURL })
+	workspaceURL = func(string) string { return srv.URL + "/" }
+
+	ctx := context.Background()
+	t.Run("logged in", func(t *testing.T) {
+		p, err := NewBrowserProfileAuth(ctx, "auto", fakeReader("xoxd-123"), BrowserWithWorkspace("acme"))
+		require.NoError(t, err)
+		assert.Equal(t, testClientToken, p.SlackToken())
+		require.Len(t, p.Cookies(), 1)
+		assert.Equal(t, "xoxd-123", p.Cookies()[0].Value)
+		assert.NoError(t, p.Validate())
+	})
+	t.Run("no cookie", func(t *testing.T) {
+		_, err := NewBrowserProfileAuth(ctx, "auto", fakeReader(""), BrowserWithWorkspace("acme"))
+		assert.ErrorIs(t, err, ErrNoCookies)
+	})
+	t.Run("no workspace", func(t *testing.T) {
+		_, err := NewBrowserProfileAuth(ctx, "auto", fakeReader("xoxd-123"))
+		assert.ErrorContains(t, err, "workspace name is required")
+	})
+	t.Run("no reader", func(t *testing.T) {
+		_, err := NewBrowserProfileAuth(ctx, "auto", BrowserWithWorkspace("acme"))
+		assert.ErrorContains(t, err, "browser profile reader is not set")
+	})
+}
+
+func Test_fetchToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("d"); err != nil || c.Value != "xoxd-123" {
+			w.Write([]byte("<html>sign in</html>"))
+			return
+		}
+		w.Write([]byte(`"api_token":"` + testClientToken + `"`))
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	jar, err := slackJar([]*http.Cookie{{Name: "d", Value: "xoxd-123", Domain: u.Hostname(), Path: "/"}})
+	require.NoError(t, err)
+
+	got, err := fetchToken(context.Background(), &http.Client{Jar: jar}, srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, testClientToken, got)
+
+	_, err = fetchToken(context.Background(), http.DefaultClient, srv.URL)
+	assert.ErrorIs(t, err, ErrNoToken)
+}
+
+func Test_slackJar(t *testing.T) {
+	jar, err := slackJar([]*http.Cookie{
+		{Name: "d", Value: "xoxd-123", Domain: ".slack.com", Path: "/"},
+		{Name: "x", Value: "1", Domain: "acme.slack.com", Path: "/"},
+	})
+	require.NoError(t, err)
+	names := func(host string) string {
+		var ss []string
+		for _, c := range jar.Cookies(&url.URL{Scheme: "https", Host: host, Path: "/"}) {
+			ss = append(ss, c.Name)
+		}
+		return strings.Join(ss, ",")
+	}
+	assert.Equal(t, "d,x", names("acme.slack.com"))
+	assert.Equal(t, "d", names("app.slack.com"), "redirects to the other subdomains must keep the session")
+}
@@ -0,0 +1,315 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"runtime/trace"
+	"strings"
+	"sync"
+	"time"
+
+	br "github.com/pkg/browser"
+	"github.com/rusq/slack"
+)
+
+const (
+	// DefOAuthRedirectURL is the default redirect URL of the OAuth flow.  It
+	// must be added to the Redirect URLs of the Slack app.
+	DefOAuthRedirectURL = "http://localhost:8765/callback"
+
+	oauthAuthorizeURL = SlackURL + "/oauth/v2/authorize"
+	// oauthRefreshMargin is the time before the token expiry, when it is
+	// considered expired and is refreshed.
+	oauthRefreshMargin = 5 * time.Minute
+)
+
+// DefOAuthUserScopes are the user scopes requested, if no scopes are
+// configured.  They allow to read all conversations of the user, the users
+// and the files.
+var DefOAuthUserScopes = []string{
+	"channels:history", "channels:read",
+	"groups:history", "groups:read",
+	"im:history", "im:read",
+	"mpim:history", "mpim:read",
+	"users:read", "users:read.email",
+	"files:read", "emoji:read",
+}
+
+// ErrNoClientID is returned by [NewOAuthAuth], if the client ID or secret of
+// the Slack app is not set.
+var ErrNoClientID = errors.New("no OAuth client ID or secret")
+
+// OAuthConfig is the configuration of the Slack app, used in the OAuth v2
+// flow.
+type OAuthConfig struct {
+	// ClientID and ClientSecret of the Slack app.
+	ClientID     string
+	ClientSecret string
+	// Scopes are the bot token scopes.
+	Scopes []string
+	// UserScopes are the user token scopes.  If both Scopes and UserScopes
+	// are empty, DefOAuthUserScopes are requested.
+	UserScopes []string
+	// RedirectURL is the redirect URL registered in the Slack app.  Slackdump
+	// listens on its host and port for the callback.  If empty,
+	// DefOAuthRedirectURL is used.
+	RedirectURL string
+}
+
+// oauthState is the state of the OAuth token, that is necessary to refresh
+// it, if token rotation is enabled for the Slack app.
+type oauthState struct {
+	ClientID     string
+	ClientSecret string
+	RefreshToken string    `json:",omitempty"`
+	Expiry       time.Time `json:",omitempty"`
+
+	mu        sync.Mutex
+	token     string                 // access token, if it was refreshed.
+	onRefresh func(p Provider) error // called after each refresh.
+}
+
+func (s simpleProvider) oauth() *oauthState {
+	return s.OAuth
+}
+
+// OAuthAuth is the authentication provider, that obtains the token of the
+// Slack app using the OAuth v2 flow.  It opens the authorisation page in the
+// browser, and receives the callback on the local listener.
+//
+// If the user scopes are requested, the user token is used, otherwise the
+// bot token.  User token sees all conversations of the user, bot token only
+// the conversations that the bot is a member of.
+type OAuthAuth struct {
+	simpleProvider
+}
+
+var _ Provider = OAuthAuth{}
+
+// oauthFlow holds the endpoints of the OAuth flow, so that they can be
+// replaced in tests.
+type oauthFlow struct {
+	cl           *http.Client
+	authorizeURL string
+	openURL      func(string) error
+}
+
+var defFlow = oauthFlow{
+	cl:           http.DefaultClient,
+	authorizeURL: oauthAuthorizeURL,
+	openURL:      br.OpenURL,
+}
+
+// NewOAuthAuth runs the OAuth v2 flow with the Slack app and returns the
+// provider with the obtained token.  It returns ErrCancelled, if the user
+// denies the access.  The flow waits for the callback until ctx is done.
+func NewOAuthAuth(ctx context.Context, cfg OAuthConfig) (OAuthAuth, error) {
+	return defFlow.run(ctx, cfg)
+}
+
+func (f oauthFlow) run(ctx context.Context, cfg OAuthConfig) (OAuthAuth, error) {
+	ctx, task := trace.NewTask(ctx, "NewOAuthAuth")
+	defer task.End()
+
+	if cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return OAuthAuth{}, ErrNoClientID
+	}
+	if cfg.RedirectURL == "" {
+		cfg.RedirectURL = DefOAuthRedirectURL
+	}
+	if len(cfg.Scopes) == 0 && len(cfg.UserScopes) == 0 {
+		cfg.UserScopes = DefOAuthUserScopes
+	}
+	redir, err := url.Parse(cfg.RedirectURL)
+	if err != nil {
+		return OAuthAuth{}, fmt.Errorf("invalid redirect URL: %w", err)
+	}
+	state, err := randomState()
+	if err != nil {
+		return OAuthAuth{}, err
+	}
+
+	l, err := net.Listen("tcp", redir.Host)
+	if err != nil {
+		return OAuthAuth{}, fmt.Errorf("unable to listen for the OAuth callback: %w", err)
+	}
+	codeC := make(chan string, 1)
+	errC := make(chan error, 1)
+	srv := &http.Server{Handler: callbackHandler(redir.Path, state, codeC, errC)}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	v := url.Values{
+		"client_id":    {cfg.ClientID},
+		"redirect_uri": {cfg.RedirectURL},
+		"state":        {state},
+	}
+	if len(cfg.Scopes) > 0 {
+		v.Set("scope", strings.Join(cfg.Scopes, ","))
+	}
+	if len(cfg.UserScopes) > 0 {
+		v.Set("user_scope", strings.Join(cfg.UserScopes, ","))
+	}
+	authURL := f.authorizeURL + "?" + v.Encode()
+	slog.InfoContext(ctx, "ℹ️ Authorise the Slack app in the browser, if it did not open, visit the URL", "url", authURL)
+	if err := f.openURL(authURL); err != nil {
+		slog.DebugContext(ctx, "unable to open the browser", "error", err)
+	}
+
+	var code string
+	select {
+	case <-ctx.Done():
+		return OAuthAuth{}, ctx.Err()
+	case err := <-errC:
+		return OAuthAuth{}, err
+	case code = <-codeC:
+	}
+
+	resp, err := slack.GetOAuthV2ResponseContext(ctx, f.cl, cfg.ClientID, cfg.ClientSecret, code, cfg.RedirectURL)
+	if err != nil {
+		return OAuthAuth{}, &Error{Err: err}
+	}
+	return newOAuthAuth(resp, cfg.ClientID, cfg.ClientSecret, len(cfg.UserScopes) > 0)
+}
+
+// callbackHandler handles the OAuth redirect on path.  It sends the code to
+// codeC, or the error to errC.
+func callbackHandler(path string, state string, codeC chan<- string, errC chan<- error) http.Handler {
+	if path == "" {
+		path = "/"
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("state") != state {
+			http.Error(w, "invalid state", http.StatusBadRequest)
+			return
+		}
+		var err error
+		switch e := q.Get("error"); {
+		case e == "access_denied":
+			err = ErrCancelled
+		case e != "":
+			err = fmt.Errorf("authorisation failed: %s", e)
+		case q.Get("code") == "":
+			err = errors.New("authorisation failed: no code in the callback")
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			select {
+			case errC <- err:
+			default:
+			}
+			return
+		}
+		fmt.Fprintln(w, "Slackdump is authorised, you can close this window.")
+		select {
+		case codeC <- q.Get("code"):
+		default:
+		}
+	})
+	return mux
+}
+
+// newOAuthAuth returns the provider with the user token from resp, if user is
+// true, or the bot token otherwise.
+func newOAuthAuth(resp *slack.OAuthV2Response, clientID, clientSecret string, user bool) (OAuthAuth, error) {
+	var (
+		token     = resp.AccessToken
+		refresh   = resp.RefreshToken
+		expiresIn = resp.ExpiresIn
+	)
+	if user {
+		token = resp.AuthedUser.AccessToken
+		refresh = resp.AuthedUser.RefreshToken
+		expiresIn = resp.AuthedUser.ExpiresIn
+	}
+	if token == "" {
+		return OAuthAuth{}, ErrNoToken
+	}
+	st := &oauthState{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RefreshToken: refresh,
+	}
+	if expiresIn > 0 {
+		st.Expiry = timeFunc().Add(time.Duration(expiresIn) * time.Second).UTC()
+	}
+	return OAuthAuth{simpleProvider{Token: token, OAuth: st}}, nil
+}
+
+// Expired returns true if the token will expire soon and should be refreshed.
+// Tokens of the apps without the token rotation never expire.
+func (o OAuthAuth) Expired() bool {
+	if o.OAuth == nil {
+		return false
+	}
+	o.OAuth.mu.Lock()
+	defer o.OAuth.mu.Unlock()
+	return o.OAuth.expired()
+}
+
+// expired must be called with the lock held.
+func (st *oauthState) expired() bool {
+	if st.Expiry.IsZero() {
+		return false
+	}
+	return timeFunc().Add(oauthRefreshMargin).After(st.Expiry)
+}
+
+// Refresh exchanges the refresh token for the new token, and returns the
+// provider with it.  It is only possible, if the token rotation is enabled
+// for the Slack app.
+func (o OAuthAuth) Refresh(ctx context.Context) (OAuthAuth, error) {
+	return defFlow.refresh(ctx, o)
+}
+
+func (f oauthFlow) refresh(ctx context.Context, o OAuthAuth) (OAuthAuth, error) {
+	ctx, task := trace.NewTask(ctx, "OAuthRefresh")
+	defer task.End()
+
+	if o.OAuth == nil || o.OAuth.RefreshToken == "" {
+		return OAuthAuth{}, errors.New("token can't be refreshed: no refresh token")
+	}
+	resp, err := slack.RefreshOAuthV2TokenContext(ctx, f.cl, o.OAuth.ClientID, o.OAuth.ClientSecret, o.OAuth.RefreshToken)
+	if err != nil {
+		return OAuthAuth{}, &Error{Err: err}
+	}
+	// refresh response has the token of the same type at the top level.
+	return newOAuthAuth(resp, o.OAuth.ClientID, o.OAuth.ClientSecret, false)
+}
+
+// RefreshIfExpired refreshes the OAuth token of the provider p, if it is
+// about to expire.  It returns the new provider and true, if the token was
+// refreshed, or p and false otherwise.  Providers, that were not obtained
+// with the OAuth flow, are returned as is.
+func RefreshIfExpired(ctx context.Context, p Provider) (Provider, bool, error) {
+	op, ok := p.(interface{ oauth() *oauthState })
+	if !ok || op.oauth() == nil {
+		return p, false, nil
+	}
+	o := OAuthAuth{simpleProvider{Token: p.SlackToken(), OAuth: op.oauth()}}
+	if !o.Expired() {
+		return p, false, nil
+	}
+	no, err := o.Refresh(ctx)
+	if err != nil {
+		return p, false, err
+	}
+	return no, true, nil
+}
+
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
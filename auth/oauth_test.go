@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// freeRedirectURL returns the redirect URL on the free local port.
+func freeRedirectURL(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	l.Close()
+	return "http://" + addr + "/callback"
+}
+
+// fakeSlack is the fake oauth.v2.access endpoint, that returns the response
+// for the code or the refresh token.
+func fakeSlack(t *testing.T, responses map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth.v2.access" || r.FormValue("client_secret") != "secret" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		key := r.FormValue("code")
+		if r.FormValue("grant_type") == "refresh_token" {
+			key = r.FormValue("refresh_token")
+		}
+		resp, ok := responses[key]
+		if !ok {
+			resp = `{"ok":false,"error":"invalid_code"}`
+		}
+		w.Write([]byte(resp))
+	}))
+}
+
+// fakeClient returns the HTTP client, that sends the Slack API requests to
+// the fake server srv.
+func fakeClient(srv *httptest.Server) *http.Client {
+	return &http.Client{Transport: rewriteTransport{srv: srv}}
+}
+
+// rewriteTransport sends all requests to the test server.
+type rewriteTransport struct {
+	srv *httptest.Server
+}
+
+func (rt rewriteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	u, err := url.Parse(rt.srv.URL)
+	if err != nil {
+		return nil, err
+	}
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host = u.Scheme, u.Host
+	r.URL.Path = strings.TrimPrefix(r.URL.Path, "/api")
+	return rt.srv.Client().Transport.RoundTrip(r)
+}
+
+// browserFn returns the function, that acts as the user, who approves or
+// denies the access in the browser.
+func browserFn(t *testing.T, query func(state string) url.Values) func(string) error {
+	return func(authURL string) error {
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+		q := u.Query()
+		go func() {
+			resp, err := http.Get(q.Get("redirect_uri") + "?" + query(q.Get("state")).Encode())
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+}
+
+func TestOAuthFlow(t *testing.T) {
+	srv := fakeSlack(t, map[string]string{
+		"code1": `{"ok":true,"access_token":"xoxb-bot","authed_user":{"id":"U1","access_token":"xoxp-user","refresh_token":"xoxe-1","expires_in":43200}}`,
+	})
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("user token", func(t *testing.T) {
+		f := oauthFlow{
+			cl:           fakeClient(srv),
+			authorizeURL: "https://slack.test/oauth/v2/authorize",
+			openURL: browserFn(t, func(state string) url.Values {
+				return url.Values{"code": {"code1"}, "state": {state}}
+			}),
+		}
+		got, err := f.run(ctx, OAuthConfig{ClientID: "id", ClientSecret: "secret", RedirectURL: freeRedirectURL(t)})
+		require.NoError(t, err)
+		assert.Equal(t, "xoxp-user", got.SlackToken())
+		assert.NoError(t, got.Validate())
+		assert.Equal(t, "xoxe-1", got.OAuth.RefreshToken)
+		assert.False(t, got.Expired())
+	})
+	t.Run("bot token", func(t *testing.T) {
+		f := oauthFlow{
+			cl: fakeClient(srv),
+			openURL: browserFn(t, func(state string) url.Values {
+				return url.Values{"code": {"code1"}, "state": {state}}
+			}),
+		}
+		got, err := f.run(ctx, OAuthConfig{ClientID: "id", ClientSecret: "secret", Scopes: []string{"channels:history"}, RedirectURL: freeRedirectURL(t)})
+		require.NoError(t, err)
+		assert.Equal(t, "xoxb-bot", got.SlackToken())
+		assert.True(t, got.OAuth.Expiry.IsZero())
+	})
+	t.Run("access denied", func(t *testing.T) {
+		f := oauthFlow{
+			cl: fakeClient(srv),
+			openURL: browserFn(t, func(state string) url.Values {
+				return url.Values{"error": {"access_denied"}, "state": {state}}
+			}),
+		}
+		_, err := f.run(ctx, OAuthConfig{ClientID: "id", ClientSecret: "secret", RedirectURL: freeRedirectURL(t)})
+		assert.ErrorIs(t, err, ErrCancelled)
+	})
+	t.Run("invalid code", func(t *testing.T) {
+		f := oauthFlow{
+			cl: fakeClient(srv),
+			openURL: browserFn(t, func(state string) url.Values {
+				return url.Values{"code": {"bad"}, "state": {state}}
+			}),
+		}
+		_, err := f.run(ctx, OAuthConfig{ClientID: "id", ClientSecret: "secret", RedirectURL: freeRedirectURL(t)})
+		assert.ErrorContains(t, err, "invalid_code")
+	})
+	t.Run("no client id", func(t *testing.T) {
+		_, err := defFlow.run(ctx, OAuthConfig{})
+		assert.ErrorIs(t, err, ErrNoClientID)
+	})
+}
+
+func Test_callbackHandler_state(t *testing.T) {
+	codeC := make(chan string, 1)
+	errC := make(chan error, 1)
+	h := callbackHandler("/callback", "good", codeC, errC)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/callback?code=c&state=forged", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, codeC)
+	assert.Empty(t, errC)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/callback?code=c&state=good", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "c", <-codeC)
+}
+
+func TestOAuthAuth_refresh(t *testing.T) {
+	srv := fakeSlack(t, map[string]string{
+		"xoxe-1": `{"ok":true,"access_token":"xoxp-new","refresh_token":"xoxe-2","expires_in":43200}`,
+	})
+	defer srv.Close()
+	f := oauthFlow{cl: fakeClient(srv)}
+
+	old := OAuthAuth{simpleProvider{Token: "xoxp-old", OAuth: &oauthState{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		RefreshToken: "xoxe-1",
+		Expiry:       time.Now().Add(time.Minute),
+	}}}
+	assert.True(t, old.Expired())
+
+	got, err := f.refresh(context.Background(), old)
+	require.NoError(t, err)
+	assert.Equal(t, "xoxp-new", got.SlackToken())
+	assert.Equal(t, "xoxe-2", got.OAuth.RefreshToken)
+	assert.False(t, got.Expired())
+
+	_, err = f.refresh(context.Background(), OAuthAuth{simpleProvider{Token: "xoxb"}})
+	assert.Error(t, err)
+}
+
+func TestRefreshIfExpired_notOAuth(t *testing.T) {
+	p, _ := NewValueAuth("xoxp-123", "")
+	got, refreshed, err := RefreshIfExpired(context.Background(), p)
+	require.NoError(t, err)
+	assert.False(t, refreshed)
+	assert.Equal(t, p, got)
+}
+
+func TestSave_oauth(t *testing.T) {
+	p := OAuthAuth{simpleProvider{Token: "xoxp-user", OAuth: &oauthState{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		RefreshToken: "xoxe-1",
+		Expiry:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}}}
+	var buf bytes.Buffer
+	require.NoError(t, Save(&buf, p))
+	got, err := Load(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, p.OAuth, got.OAuth, "refresh state must survive the round trip")
+}
@@ -10,7 +10,8 @@ import (
 type options struct {
 	playwrightOptions
 	rodOpts
-	workspace string
+	workspace     string
+	profileReader ProfileReader
 }
 
 type Option func(*options)
@@ -30,6 +31,14 @@ func BrowserWithWorkspace(name string) Option {
 	}
 }
 
+// BrowserWithProfileReader sets the reader of the browser profiles, that is
+// used by [NewBrowserProfileAuth].
+func BrowserWithProfileReader(r ProfileReader) Option {
+	return func(o *options) {
+		o.profileReader = r
+	}
+}
+
 func BrowserWithBrowser(b browser.Browser) Option {
 	return func(o *options) {
 		o.playwrightOptions.browser = b
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variables set by slackdump for the plugin processes.
+const (
+	// EnvPluginSession is the one-time session key that the plugin must
+	// present to slackdump to receive the credentials.
+	EnvPluginSession = "SLACKDUMP_PLUGIN_SESSION"
+	// EnvPluginFD holds the file descriptors of the handshake channel in the
+	// form "r,w", where r is the descriptor the plugin reads from, and w is
+	// the descriptor the plugin writes to.
+	EnvPluginFD = "SLACKDUMP_PLUGIN_FD"
+)
+
+// pluginProtoVersion is the version of the plugin handshake protocol.
+const pluginProtoVersion = 1
+
+// ErrNotPlugin is returned by [FromPlugin] if the process was not started by
+// slackdump as a plugin, or the credential handoff is not available.
+var ErrNotPlugin = errors.New("not running as a slackdump plugin")
+
+type pluginRequest struct {
+	Version int    `json:"v"`
+	Session string `json:"session"`
+}
+
+type pluginResponse struct {
+	Version int             `json:"v"`
+	Error   string          `json:"error,omitempty"`
+	Auth    *simpleProvider `json:"auth,omitempty"`
+}
+
+// FromPlugin requests the credentials of the current slackdump workspace from
+// the slackdump process that started this plugin.  The credentials are never
+// passed in the environment or saved to disk, instead they are sent over the
+// private channel inherited from slackdump, once the plugin presents the
+// session key.  It returns ErrNotPlugin, if the process was not started by
+// slackdump.  The handoff can be done only once per plugin run.
+func FromPlugin() (ValueAuth, error) {
+	session := os.Getenv(EnvPluginSession)
+	fds := os.Getenv(EnvPluginFD)
+	if session == "" || fds == "" {
+		return ValueAuth{}, ErrNotPlugin
+	}
+	rfd, wfd, err := parseFDs(fds)
+	if err != nil {
+		return ValueAuth{}, err
+	}
+	r := os.NewFile(rfd, "slackdump-handshake-r")
+	w := os.NewFile(wfd, "slackdump-handshake-w")
+	if r == nil || w == nil {
+		return ValueAuth{}, ErrNotPlugin
+	}
+	defer r.Close()
+	defer w.Close()
+	return pluginHandshake(r, w, session)
+}
+
+func parseFDs(s string) (r uintptr, w uintptr, err error) {
+	rs, ws, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid %s value: %q", EnvPluginFD, s)
+	}
+	rn, err := strconv.ParseUint(rs, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid %s value: %w", EnvPluginFD, err)
+	}
+	wn, err := strconv.ParseUint(ws, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid %s value: %w", EnvPluginFD, err)
+	}
+	return uintptr(rn), uintptr(wn), nil
+}
+
+// pluginHandshake is the plugin side of the handshake.
+func pluginHandshake(r io.Reader, w io.Writer, session string) (ValueAuth, error) {
+	if err := json.NewEncoder(w).Encode(pluginRequest{Version: pluginProtoVersion, Session: session}); err != nil {
+		return ValueAuth{}, fmt.Errorf("plugin handshake: %w", err)
+	}
+	var resp pluginResponse
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return ValueAuth{}, fmt.Errorf("plugin handshake: %w", err)
+	}
+	if resp.Error != "" {
+		return ValueAuth{}, fmt.Errorf("plugin handshake: %s", resp.Error)
+	}
+	if resp.Auth == nil {
+		return ValueAuth{}, errors.New("plugin handshake: no credentials received")
+	}
+	return ValueAuth{*resp.Auth}, resp.Auth.Validate()
+}
+
+// ServePlugin serves a single credentials request of the plugin, reading the
+// request from r and writing the response to w.  The request is honoured only
+// if the plugin presents the session key, that slackdump has set in the
+// plugin environment.  The provider is obtained by calling getProv only when
+// requested, so plugins that don't need the credentials don't trigger the
+// authentication.  If the plugin exits without making a request, ServePlugin
+// returns nil.
+func ServePlugin(ctx context.Context, r io.Reader, w io.Writer, session string, getProv func(context.Context) (Provider, error)) error {
+	line, err := bufio.NewReader(r).ReadBytes('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(line) == 0 {
+			return nil // plugin did not ask for credentials
+		}
+		return fmt.Errorf("plugin handshake: %w", err)
+	}
+	enc := json.NewEncoder(w)
+	var req pluginRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		return fmt.Errorf("plugin handshake: %w", err)
+	}
+	reject := func(reason string) error {
+		if err := enc.Encode(pluginResponse{Version: pluginProtoVersion, Error: reason}); err != nil {
+			return fmt.Errorf("plugin handshake: %w", err)
+		}
+		return fmt.Errorf("plugin handshake: %s", reason)
+	}
+	if req.Version != pluginProtoVersion {
+		return reject(fmt.Sprintf("unsupported protocol version %d", req.Version))
+	}
+	if session == "" || subtle.ConstantTimeCompare([]byte(req.Session), []byte(session)) != 1 {
+		return reject("invalid session key")
+	}
+	prov, err := getProv(ctx)
+	if err != nil {
+		return reject(err.Error())
+	}
+	resp := pluginResponse{
+		Version: pluginProtoVersion,
+		Auth:    &simpleProvider{Token: prov.SlackToken(), Cookie: prov.Cookies()},
+	}
+	if err := enc.Encode(resp); err != nil {
+		return fmt.Errorf("plugin handshake: %w", err)
+	}
+	return nil
+}
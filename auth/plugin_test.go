@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServePlugin(t *testing.T) {
+	const session = "s3cr3t"
+	prov, err := NewValueAuth("xoxb-token", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	getProv := func(context.Context) (Provider, error) { return prov, nil }
+
+	// handshake runs the plugin side with the given session key and returns
+	// results of both sides.
+	handshake := func(t *testing.T, pluginSession string, getProv func(context.Context) (Provider, error)) (ValueAuth, error, error) {
+		t.Helper()
+		reqR, reqW := io.Pipe()
+		respR, respW := io.Pipe()
+		srvErr := make(chan error, 1)
+		go func() {
+			defer respW.Close()
+			srvErr <- ServePlugin(context.Background(), reqR, respW, session, getProv)
+		}()
+		got, err := pluginHandshake(respR, reqW, pluginSession)
+		reqW.Close()
+		return got, err, <-srvErr
+	}
+
+	t.Run("valid session", func(t *testing.T) {
+		got, err, srvErr := handshake(t, session, getProv)
+		assert.NoError(t, err)
+		assert.NoError(t, srvErr)
+		assert.Equal(t, prov.SlackToken(), got.SlackToken())
+	})
+	t.Run("invalid session", func(t *testing.T) {
+		called := false
+		_, err, srvErr := handshake(t, "guess", func(context.Context) (Provider, error) {
+			called = true
+			return prov, nil
+		})
+		assert.Error(t, err)
+		assert.Error(t, srvErr)
+		assert.False(t, called, "provider must not be requested")
+	})
+	t.Run("auth error", func(t *testing.T) {
+		_, err, srvErr := handshake(t, session, func(context.Context) (Provider, error) {
+			return nil, errors.New("no workspaces")
+		})
+		assert.ErrorContains(t, err, "no workspaces")
+		assert.Error(t, srvErr)
+	})
+	t.Run("no request", func(t *testing.T) {
+		r, w := io.Pipe()
+		w.Close()
+		assert.NoError(t, ServePlugin(context.Background(), r, io.Discard, session, getProv))
+	})
+}
+
+func TestFromPlugin_notPlugin(t *testing.T) {
+	t.Setenv(EnvPluginSession, "")
+	t.Setenv(EnvPluginFD, "")
+	if _, err := FromPlugin(); !errors.Is(err, ErrNotPlugin) {
+		t.Errorf("FromPlugin() error = %v, want %v", err, ErrNotPlugin)
+	}
+}
@@ -0,0 +1,143 @@
+package profile
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// decryptFunc decrypts the encrypted value of the Chromium cookie.
+type decryptFunc func(enc []byte) ([]byte, error)
+
+// hashedValueVersion is the version of the Chromium cookie database, starting
+// from which the decrypted value is prefixed with the SHA-256 hash of the
+// cookie domain.
+const hashedValueVersion = 24
+
+// chromiumEpochOffset is the number of microseconds between the epoch of the
+// Chromium timestamps, 1601-01-01, and the Unix epoch.
+const chromiumEpochOffset = 11644473600 * 1000000
+
+// chromiumCookies reads the cookies of the domain from the Chromium database,
+// decrypting the encrypted values.
+func (s Store) chromiumCookies(ctx context.Context, db *sql.DB, domain string) ([]*http.Cookie, error) {
+	version, err := chromiumVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx,
+		"SELECT host_key, name, value, encrypted_value, path, expires_utc, is_secure, is_httponly FROM cookies WHERE "+hostCond("host_key"),
+		domain)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		cc      []*http.Cookie
+		decrypt decryptFunc
+	)
+	for rows.Next() {
+		var (
+			c       http.Cookie
+			enc     []byte
+			expires int64
+		)
+		if err := rows.Scan(&c.Domain, &c.Name, &c.Value, &enc, &c.Path, &expires, &c.Secure, &c.HttpOnly); err != nil {
+			return nil, err
+		}
+		if c.Value == "" && len(enc) > 0 {
+			if decrypt == nil {
+				// the key is obtained only if there are encrypted
+				// cookies, as the system may ask the user.
+				if decrypt, err = newDecrypter(ctx, s); err != nil {
+					return nil, fmt.Errorf("%s: %w", s, err)
+				}
+			}
+			val, err := decrypt(enc)
+			if err != nil {
+				return nil, fmt.Errorf("%s: cookie %q: %w", s, c.Name, err)
+			}
+			if version >= hashedValueVersion {
+				val, err = stripDomainHash(val, c.Domain)
+				if err != nil {
+					return nil, fmt.Errorf("%s: cookie %q: %w", s, c.Name, err)
+				}
+			}
+			c.Value = string(val)
+		}
+		if expires > 0 {
+			c.Expires = time.UnixMicro(expires - chromiumEpochOffset).UTC()
+		}
+		cc = append(cc, &c)
+	}
+	return cc, rows.Err()
+}
+
+// chromiumVersion returns the version of the cookie database.
+func chromiumVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var v string
+	if err := db.QueryRowContext(ctx, "SELECT value FROM meta WHERE key = 'version'").Scan(&v); err != nil {
+		return 0, fmt.Errorf("unable to read the database version: %w", err)
+	}
+	return strconv.Atoi(v)
+}
+
+// stripDomainHash removes the SHA-256 hash of the domain from the decrypted
+// value.
+func stripDomainHash(val []byte, domain string) ([]byte, error) {
+	h := sha256.Sum256([]byte(domain))
+	if !bytes.HasPrefix(val, h[:]) {
+		return nil, fmt.Errorf("%w: domain hash mismatch", ErrEncrypted)
+	}
+	return val[len(h):], nil
+}
+
+// cbcDecrypt decrypts the value, encrypted with AES-128-CBC, that is used on
+// Linux and macOS.
+func cbcDecrypt(key []byte, enc []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(enc) == 0 || len(enc)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("%w: invalid length", ErrEncrypted)
+	}
+	iv := bytes.Repeat([]byte{' '}, aes.BlockSize)
+	out := make([]byte, len(enc))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, enc)
+	// PKCS#7 padding
+	n := int(out[len(out)-1])
+	if n == 0 || n > aes.BlockSize || n > len(out) || !bytes.Equal(out[len(out)-n:], bytes.Repeat([]byte{byte(n)}, n)) {
+		return nil, fmt.Errorf("%w: invalid padding, wrong key", ErrEncrypted)
+	}
+	return out[:len(out)-n], nil
+}
+
+// gcmDecrypt decrypts the value, encrypted with AES-256-GCM, that is used on
+// Windows.  enc is the nonce followed by the ciphertext.
+func gcmDecrypt(key []byte, enc []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(enc) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid length", ErrEncrypted)
+	}
+	out, err := aead.Open(nil, enc[:aead.NonceSize()], enc[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrEncrypted, err)
+	}
+	return out, nil
+}
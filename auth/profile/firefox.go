@@ -0,0 +1,42 @@
+package profile
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+)
+
+// firefoxCookies reads the cookies of the domain from the Firefox database.
+func firefoxCookies(ctx context.Context, db *sql.DB, domain string) ([]*http.Cookie, error) {
+	rows, err := db.QueryContext(ctx,
+		"SELECT host, name, value, path, expiry, isSecure, isHttpOnly FROM moz_cookies WHERE "+hostCond("host"),
+		domain)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cc []*http.Cookie
+	for rows.Next() {
+		var (
+			c      http.Cookie
+			expiry int64
+		)
+		if err := rows.Scan(&c.Domain, &c.Name, &c.Value, &c.Path, &expiry, &c.Secure, &c.HttpOnly); err != nil {
+			return nil, err
+		}
+		c.Expires = firefoxTime(expiry)
+		cc = append(cc, &c)
+	}
+	return cc, rows.Err()
+}
+
+// firefoxTime converts the Firefox cookie expiry to time.  It is in seconds
+// since the epoch, newer versions use milliseconds.
+func firefoxTime(v int64) time.Time {
+	if v > 1e11 {
+		return time.UnixMilli(v).UTC()
+	}
+	return time.Unix(v, 0).UTC()
+}
@@ -0,0 +1,275 @@
+// Package profile reads the Slack cookies from the cookie stores of the
+// browser profiles, installed on this machine, so that slackdump can log in
+// without opening the browser, i.e. on the headless machine, where the user
+// has logged in to Slack with the browser before.
+//
+// Firefox and the Chromium based browsers (Chrome, Chromium, Brave and
+// Edge) are supported.  The cookie stores of the Chromium based browsers are
+// encrypted, and the encryption key is obtained from the operating system
+// keyring (see [Store.Cookies]).
+package profile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rusq/slackdump/v3/internal/sqlite/driver"
+)
+
+// Kind is the kind of the cookie store.
+type Kind uint8
+
+const (
+	KindUnknown Kind = iota
+	// KindFirefox is the "cookies.sqlite" database of Firefox.
+	KindFirefox
+	// KindChromium is the "Cookies" database of the Chromium based browser.
+	KindChromium
+)
+
+const (
+	firefoxFile  = "cookies.sqlite"
+	chromiumFile = "Cookies"
+)
+
+var (
+	// ErrNotFound is returned by [Find] if no cookie stores were found.
+	ErrNotFound = errors.New("no browser profiles found")
+	// ErrEncrypted is returned if the cookie can't be decrypted.
+	ErrEncrypted = errors.New("unable to decrypt the cookie")
+)
+
+// Store is the cookie store of the browser profile.
+type Store struct {
+	// Browser is the browser name, i.e. "firefox" or "chrome".
+	Browser string
+	// Profile is the name of the profile directory.
+	Profile string
+	// Path is the path to the cookie database.
+	Path string
+	Kind Kind
+
+	// safeStorage is the name of the keyring entry with the password, that
+	// the cookies of the Chromium based browser are encrypted with.
+	safeStorage string
+}
+
+func (s Store) String() string {
+	return s.Browser + " (" + s.Profile + ")"
+}
+
+// location is the location of the profiles of the browser.
+type location struct {
+	browser     string
+	kind        Kind
+	root        string
+	patterns    []string // glob patterns of the cookie stores under root.
+	safeStorage string
+}
+
+// chromiumLocation returns the location of the profiles of the Chromium
+// based browser with the user data directory root.
+func chromiumLocation(browser string, root string, safeStorage string) location {
+	return location{
+		browser:     browser,
+		kind:        KindChromium,
+		root:        root,
+		patterns:    []string{filepath.Join("*", chromiumFile), filepath.Join("*", "Network", chromiumFile)},
+		safeStorage: safeStorage,
+	}
+}
+
+// join joins the path elements to the base directory, it returns an empty
+// string if base is empty, i.e. if it could not be determined.
+func join(base string, elem ...string) string {
+	if base == "" {
+		return ""
+	}
+	return filepath.Join(append([]string{base}, elem...)...)
+}
+
+// Browsers returns the names of the browsers, that are supported on this
+// operating system.
+func Browsers() []string {
+	var names []string
+	for _, loc := range locations() {
+		if !slices.Contains(names, loc.browser) {
+			names = append(names, loc.browser)
+		}
+	}
+	return names
+}
+
+// Find returns the cookie stores of all profiles of the browser, installed
+// on this machine.  If browser is empty or "auto", the stores of all
+// supported browsers are returned.  It returns ErrNotFound, if there are
+// none.
+func Find(browser string) ([]Store, error) {
+	browser = strings.ToLower(strings.TrimSpace(browser))
+	if browser == "auto" {
+		browser = ""
+	}
+	if browser != "" && !slices.Contains(Browsers(), browser) {
+		return nil, fmt.Errorf("unsupported browser %q, supported: %s", browser, strings.Join(Browsers(), ", "))
+	}
+	var stores []Store
+	for _, loc := range locations() {
+		if browser != "" && loc.browser != browser {
+			continue
+		}
+		ss, err := loc.find()
+		if err != nil {
+			return nil, err
+		}
+		stores = append(stores, ss...)
+	}
+	if len(stores) == 0 {
+		return nil, ErrNotFound
+	}
+	return stores, nil
+}
+
+// find returns the cookie stores in the location.
+func (loc location) find() ([]Store, error) {
+	if loc.root == "" {
+		return nil, nil
+	}
+	var stores []Store
+	for _, p := range loc.patterns {
+		matches, err := filepath.Glob(filepath.Join(loc.root, p))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			stores = append(stores, Store{
+				Browser:     loc.browser,
+				Profile:     profileName(m),
+				Path:        m,
+				Kind:        loc.kind,
+				safeStorage: loc.safeStorage,
+			})
+		}
+	}
+	return stores, nil
+}
+
+// Open returns the store of the cookie database filename.  The kind of the
+// store is determined by the filename.
+func Open(filename string) (Store, error) {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return Store{}, err
+	}
+	if fi.IsDir() {
+		return Store{}, fmt.Errorf("%s: is a directory", filename)
+	}
+	s := Store{Path: filename, Profile: profileName(filename)}
+	switch filepath.Base(filename) {
+	case firefoxFile:
+		s.Kind, s.Browser = KindFirefox, "firefox"
+	case chromiumFile:
+		s.Kind, s.Browser = KindChromium, "chrome"
+		abs, _ := filepath.Abs(filename)
+		for _, loc := range locations() {
+			if loc.kind == KindChromium && loc.root != "" && strings.HasPrefix(abs, loc.root+string(filepath.Separator)) {
+				s.Browser, s.safeStorage = loc.browser, loc.safeStorage
+				break
+			}
+		}
+		if s.safeStorage == "" {
+			s.safeStorage = defSafeStorage
+		}
+	default:
+		return Store{}, fmt.Errorf("%s: unknown cookie database, expected %q or %q", filename, firefoxFile, chromiumFile)
+	}
+	return s, nil
+}
+
+// profileName returns the name of the profile directory of the cookie
+// database filename.
+func profileName(filename string) string {
+	dir := filepath.Dir(filename)
+	if filepath.Base(dir) == "Network" {
+		// newer Chromium versions keep the cookies in the Network
+		// subdirectory of the profile.
+		dir = filepath.Dir(dir)
+	}
+	return filepath.Base(dir)
+}
+
+// Cookies returns the cookies of the domain and its subdomains from the
+// store.  The database is copied to the temporary directory first, as the
+// running browser keeps it locked.
+//
+// The cookies of the Chromium based browsers are decrypted with the key,
+// that is obtained from the operating system: the Keychain on macOS (the
+// system may ask for the permission), the Secret Service keyring on Linux
+// (with the "secret-tool" command), or DPAPI on Windows.
+func (s Store) Cookies(ctx context.Context, domain string) ([]*http.Cookie, error) {
+	dir, err := os.MkdirTemp("", "slackdump-cookies-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	filename, err := snapshot(dir, s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s, err)
+	}
+	db, err := driver.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+	switch s.Kind {
+	case KindFirefox:
+		return firefoxCookies(ctx, db, domain)
+	case KindChromium:
+		return s.chromiumCookies(ctx, db, domain)
+	}
+	return nil, fmt.Errorf("%s: unknown store kind %d", s, s.Kind)
+}
+
+// snapshot copies the database filename with its write-ahead log, if any, to
+// the directory dir, and returns the name of the copy.
+func snapshot(dir string, filename string) (string, error) {
+	dst := filepath.Join(dir, filepath.Base(filename))
+	if err := copyFile(dst, filename); err != nil {
+		return "", err
+	}
+	if err := copyFile(dst+"-wal", filename+"-wal"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	return dst, nil
+}
+
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// hostCond is the SQL condition, that matches the host column against the
+// domain and its subdomains.
+func hostCond(col string) string {
+	return "(" + col + " = ?1 OR " + col + " = '.' || ?1 OR " + col + " LIKE '%.' || ?1)"
+}
@@ -0,0 +1,45 @@
+package profile
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// defSafeStorage is the Keychain entry of Chrome.
+const defSafeStorage = "Chrome Safe Storage"
+
+func locations() []location {
+	// Library/Application Support
+	support, _ := os.UserConfigDir()
+	return []location{
+		{browser: "firefox", kind: KindFirefox, root: join(support, "Firefox", "Profiles"), patterns: []string{filepath.Join("*", firefoxFile)}},
+		chromiumLocation("chrome", join(support, "Google", "Chrome"), "Chrome Safe Storage"),
+		chromiumLocation("chromium", join(support, "Chromium"), "Chromium Safe Storage"),
+		chromiumLocation("brave", join(support, "BraveSoftware", "Brave-Browser"), "Brave Safe Storage"),
+		chromiumLocation("edge", join(support, "Microsoft Edge"), "Microsoft Edge Safe Storage"),
+	}
+}
+
+// newDecrypter returns the function, that decrypts the "v10" values with the
+// key, derived from the password in the Keychain.  macOS asks the user to
+// allow the access to the Keychain entry.
+func newDecrypter(ctx context.Context, s Store) (decryptFunc, error) {
+	out, err := exec.CommandContext(ctx, "security", "find-generic-password", "-w", "-s", s.safeStorage).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get %q from the Keychain: %s", ErrEncrypted, s.safeStorage, err)
+	}
+	key := pbkdf2.Key(bytes.TrimSpace(out), []byte("saltysalt"), 1003, 16, sha1.New)
+	return func(enc []byte) ([]byte, error) {
+		if !bytes.HasPrefix(enc, []byte("v10")) {
+			return nil, fmt.Errorf("%w: unsupported encryption version %q", ErrEncrypted, enc[:min(3, len(enc))])
+		}
+		return cbcDecrypt(key, enc[3:])
+	}, nil
+}
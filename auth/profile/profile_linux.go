@@ -0,0 +1,70 @@
+package profile
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// defSafeStorage is the keyring application of Chrome.
+const defSafeStorage = "chrome"
+
+func locations() []location {
+	home, _ := os.UserHomeDir()
+	config, _ := os.UserConfigDir()
+	firefox := []string{filepath.Join("*", firefoxFile)}
+	return []location{
+		{browser: "firefox", kind: KindFirefox, root: join(home, ".mozilla", "firefox"), patterns: firefox},
+		{browser: "firefox", kind: KindFirefox, root: join(home, "snap", "firefox", "common", ".mozilla", "firefox"), patterns: firefox},
+		chromiumLocation("chrome", join(config, "google-chrome"), "chrome"),
+		chromiumLocation("chromium", join(config, "chromium"), "chromium"),
+		chromiumLocation("brave", join(config, "BraveSoftware", "Brave-Browser"), "brave"),
+		chromiumLocation("edge", join(config, "microsoft-edge"), "chromium"),
+	}
+}
+
+var (
+	chromiumSalt = []byte("saltysalt")
+	// v10Key is the key of the "v10" values, that are encrypted with the
+	// hardcoded password, if the keyring is not available.
+	v10Key = pbkdf2.Key([]byte("peanuts"), chromiumSalt, 1, 16, sha1.New)
+)
+
+// newDecrypter returns the function, that decrypts the "v10" values with the
+// hardcoded key, and "v11" values with the key, derived from the password in
+// the Secret Service keyring.
+func newDecrypter(ctx context.Context, s Store) (decryptFunc, error) {
+	var v11Key []byte
+	return func(enc []byte) ([]byte, error) {
+		switch {
+		case bytes.HasPrefix(enc, []byte("v10")):
+			return cbcDecrypt(v10Key, enc[3:])
+		case bytes.HasPrefix(enc, []byte("v11")):
+			if v11Key == nil {
+				pwd, err := keyringPassword(ctx, s.safeStorage)
+				if err != nil {
+					return nil, err
+				}
+				v11Key = pbkdf2.Key(pwd, chromiumSalt, 1, 16, sha1.New)
+			}
+			return cbcDecrypt(v11Key, enc[3:])
+		}
+		return nil, fmt.Errorf("%w: unsupported encryption version %q", ErrEncrypted, enc[:min(3, len(enc))])
+	}, nil
+}
+
+// keyringPassword returns the password of the application app from the
+// Secret Service keyring.
+var keyringPassword = func(ctx context.Context, app string) ([]byte, error) {
+	out, err := exec.CommandContext(ctx, "secret-tool", "lookup", "application", app).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get the password from the keyring with secret-tool: %s", ErrEncrypted, err)
+	}
+	return bytes.TrimSpace(out), nil
+}
@@ -0,0 +1,63 @@
+package profile
+
+import (
+	"context"
+	"crypto/sha1"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/pbkdf2"
+
+	"github.com/rusq/slackdump/v3/internal/sqlite/driver"
+)
+
+func TestStore_Cookies_chromium(t *testing.T) {
+	oldPwd := keyringPassword
+	t.Cleanup(func() { keyringPassword = oldPwd })
+	keyringPassword = func(context.Context, string) ([]byte, error) { return []byte("keyring-secret"), nil }
+	v11Key := pbkdf2Key(t, "keyring-secret")
+
+	for _, version := range []int{23, 24} {
+		filename := filepath.Join(t.TempDir(), "Default", chromiumFile)
+		makeDB(t, filename,
+			`CREATE TABLE meta (key TEXT, value TEXT)`,
+			`CREATE TABLE cookies (host_key TEXT, name TEXT, value TEXT, encrypted_value BLOB, path TEXT, expires_utc INTEGER, is_secure INTEGER, is_httponly INTEGER)`,
+		)
+		db, err := driver.Open(filename)
+		require.NoError(t, err)
+		_, err = db.Exec(`INSERT INTO meta VALUES ('version', ?)`, version)
+		require.NoError(t, err)
+		plain := func(domain, v string) []byte {
+			if version >= hashedValueVersion {
+				return append(sha256Sum(domain), v...)
+			}
+			return []byte(v)
+		}
+		v10 := append([]byte("v10"), cbcEncrypt(t, v10Key, plain(".slack.com", "xoxd-10"))...)
+		v11 := append([]byte("v11"), cbcEncrypt(t, v11Key, plain("acme.slack.com", "xoxd-11"))...)
+		_, err = db.Exec(`INSERT INTO cookies VALUES
+			('.slack.com', 'd', '', ?, '/', 13383820800000000, 1, 1),
+			('acme.slack.com', 'x', '', ?, '/', 0, 1, 0),
+			('.slack.com', 'plain', 'value', NULL, '/', 0, 0, 0)`, v10, v11)
+		require.NoError(t, err)
+		db.Close()
+
+		s, err := Open(filename)
+		require.NoError(t, err)
+		cc, err := s.Cookies(context.Background(), ".slack.com")
+		require.NoError(t, err, "version %d", version)
+		require.Len(t, cc, 3)
+		assert.Equal(t, "xoxd-10", cc[0].Value)
+		assert.Equal(t, 2025, cc[0].Expires.Year())
+		assert.Equal(t, "xoxd-11", cc[1].Value)
+		assert.True(t, cc[1].Expires.IsZero())
+		assert.Equal(t, "value", cc[2].Value)
+	}
+}
+
+func pbkdf2Key(t *testing.T, pwd string) []byte {
+	t.Helper()
+	return pbkdf2.Key([]byte(pwd), chromiumSalt, 1, 16, sha1.New)
+}
@@ -0,0 +1,25 @@
+//go:build !linux && !darwin && !windows
+
+package profile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// defSafeStorage is the keyring application of Chrome.
+const defSafeStorage = "chrome"
+
+func locations() []location {
+	home, _ := os.UserHomeDir()
+	return []location{
+		{browser: "firefox", kind: KindFirefox, root: join(home, ".mozilla", "firefox"), patterns: []string{filepath.Join("*", firefoxFile)}},
+	}
+}
+
+// newDecrypter returns an error, the encrypted cookies are not supported on
+// this operating system.
+func newDecrypter(context.Context, Store) (decryptFunc, error) {
+	return nil, ErrEncrypted
+}
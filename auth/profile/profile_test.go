@@ -0,0 +1,122 @@
+package profile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/sqlite/driver"
+)
+
+// makeDB creates the sqlite database filename with the statements.
+func makeDB(t *testing.T, filename string, stmts ...string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o700))
+	db, err := driver.Open(filename)
+	require.NoError(t, err)
+	defer db.Close()
+	for _, s := range stmts {
+		_, err := db.Exec(s)
+		require.NoError(t, err, s)
+	}
+}
+
+func TestStore_Cookies_firefox(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "abcd.default-release", firefoxFile)
+	makeDB(t, filename,
+		`CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, name TEXT, value TEXT, host TEXT, path TEXT, expiry INTEGER, isSecure INTEGER, isHttpOnly INTEGER)`,
+		`INSERT INTO moz_cookies (name, value, host, path, expiry, isSecure, isHttpOnly) VALUES
+			('d', 'xoxd-123', '.slack.com', '/', 1893456000, 1, 1),
+			('d-s', '1700000000', '.slack.com', '/', 1893456000000, 1, 1),
+			('b', 'x', 'acme.slack.com', '/', 1893456000, 1, 0),
+			('d', 'other', '.notslack.com', '/', 1893456000, 1, 1)`,
+	)
+
+	s, err := Open(filename)
+	require.NoError(t, err)
+	assert.Equal(t, KindFirefox, s.Kind)
+	assert.Equal(t, "firefox (abcd.default-release)", s.String())
+
+	cc, err := s.Cookies(context.Background(), "slack.com")
+	require.NoError(t, err)
+	require.Len(t, cc, 3)
+	assert.Equal(t, "d", cc[0].Name)
+	assert.Equal(t, "xoxd-123", cc[0].Value)
+	assert.Equal(t, ".slack.com", cc[0].Domain)
+	assert.True(t, cc[0].Secure)
+	want := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, want, cc[0].Expires)
+	assert.Equal(t, want, cc[1].Expires, "milliseconds expiry")
+	assert.Equal(t, "acme.slack.com", cc[2].Domain)
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	_, err := Open(dir)
+	assert.Error(t, err, "directory")
+
+	other := filepath.Join(dir, "History")
+	require.NoError(t, os.WriteFile(other, nil, 0o600))
+	_, err = Open(other)
+	assert.ErrorContains(t, err, "unknown cookie database")
+
+	chrome := filepath.Join(dir, "Profile 1", "Network", chromiumFile)
+	require.NoError(t, os.MkdirAll(filepath.Dir(chrome), 0o700))
+	require.NoError(t, os.WriteFile(chrome, nil, 0o600))
+	s, err := Open(chrome)
+	require.NoError(t, err)
+	assert.Equal(t, KindChromium, s.Kind)
+	assert.Equal(t, "Profile 1", s.Profile)
+}
+
+func TestFind(t *testing.T) {
+	_, err := Find("netscape")
+	assert.ErrorContains(t, err, "unsupported browser")
+
+	root := t.TempDir()
+	loc := chromiumLocation("chrome", root, "chrome")
+	for _, p := range []string{
+		filepath.Join(root, "Default", chromiumFile),
+		filepath.Join(root, "Profile 2", "Network", chromiumFile),
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o700))
+		require.NoError(t, os.WriteFile(p, nil, 0o600))
+	}
+	got, err := loc.find()
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "Default", got[0].Profile)
+	assert.Equal(t, "Profile 2", got[1].Profile)
+
+	got, err = location{}.find()
+	assert.NoError(t, err)
+	assert.Empty(t, got, "unknown root is skipped")
+}
+
+func Test_cbcDecrypt(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	enc := cbcEncrypt(t, key, []byte("xoxd-secret"))
+	got, err := cbcDecrypt(key, enc)
+	require.NoError(t, err)
+	assert.Equal(t, "xoxd-secret", string(got))
+
+	_, err = cbcDecrypt([]byte("fedcba9876543210"), enc)
+	assert.ErrorIs(t, err, ErrEncrypted)
+	_, err = cbcDecrypt(key, enc[:5])
+	assert.ErrorIs(t, err, ErrEncrypted)
+}
+
+func Test_stripDomainHash(t *testing.T) {
+	h := sha256Sum(".slack.com")
+	got, err := stripDomainHash(append(h, "xoxd"...), ".slack.com")
+	require.NoError(t, err)
+	assert.Equal(t, "xoxd", string(got))
+
+	_, err = stripDomainHash(append(h, "xoxd"...), "acme.slack.com")
+	assert.ErrorIs(t, err, ErrEncrypted)
+}
@@ -0,0 +1,88 @@
+package profile
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// defSafeStorage is not used on Windows, the key is in the "Local State"
+// file of the user data directory.
+const defSafeStorage = ""
+
+func locations() []location {
+	appData, _ := os.UserConfigDir()
+	localAppData := os.Getenv("LOCALAPPDATA")
+	return []location{
+		{browser: "firefox", kind: KindFirefox, root: join(appData, "Mozilla", "Firefox", "Profiles"), patterns: []string{filepath.Join("*", firefoxFile)}},
+		chromiumLocation("chrome", join(localAppData, "Google", "Chrome", "User Data"), ""),
+		chromiumLocation("chromium", join(localAppData, "Chromium", "User Data"), ""),
+		chromiumLocation("brave", join(localAppData, "BraveSoftware", "Brave-Browser", "User Data"), ""),
+		chromiumLocation("edge", join(localAppData, "Microsoft", "Edge", "User Data"), ""),
+	}
+}
+
+// newDecrypter returns the function, that decrypts the "v10" values with the
+// key from the "Local State" file, protected with DPAPI.  The app-bound
+// "v20" values of the newer Chrome versions can't be decrypted.
+func newDecrypter(ctx context.Context, s Store) (decryptFunc, error) {
+	key, err := localStateKey(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	return func(enc []byte) ([]byte, error) {
+		switch {
+		case bytes.HasPrefix(enc, []byte("v10")), bytes.HasPrefix(enc, []byte("v11")):
+			return gcmDecrypt(key, enc[3:])
+		case bytes.HasPrefix(enc, []byte("v20")):
+			return nil, fmt.Errorf("%w: app-bound encryption is not supported, use Firefox", ErrEncrypted)
+		}
+		return nil, fmt.Errorf("%w: unsupported encryption version %q", ErrEncrypted, enc[:min(3, len(enc))])
+	}, nil
+}
+
+// localStateKey finds the "Local State" file in the parent directories of the
+// cookie database filename, and returns the decrypted key.
+func localStateKey(filename string) ([]byte, error) {
+	var data []byte
+	for dir := filepath.Dir(filename); ; dir = filepath.Dir(dir) {
+		var err error
+		if data, err = os.ReadFile(filepath.Join(dir, "Local State")); err == nil {
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			return nil, fmt.Errorf("%w: Local State file not found", ErrEncrypted)
+		}
+	}
+	var ls struct {
+		OSCrypt struct {
+			EncryptedKey string `json:"encrypted_key"`
+		} `json:"os_crypt"`
+	}
+	if err := json.Unmarshal(data, &ls); err != nil {
+		return nil, fmt.Errorf("%w: invalid Local State: %s", ErrEncrypted, err)
+	}
+	enc, err := base64.StdEncoding.DecodeString(ls.OSCrypt.EncryptedKey)
+	if err != nil || !bytes.HasPrefix(enc, []byte("DPAPI")) {
+		return nil, fmt.Errorf("%w: invalid encrypted key", ErrEncrypted)
+	}
+	return dpapiDecrypt(enc[len("DPAPI"):])
+}
+
+// dpapiDecrypt decrypts the data, protected with DPAPI for the current user.
+func dpapiDecrypt(data []byte) ([]byte, error) {
+	in := windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(&in, nil, nil, 0, nil, 0, &out); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrEncrypted, err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return bytes.Clone(unsafe.Slice(out.Data, out.Size)), nil
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// rotatingPrefix is the prefix of the access tokens of the Slack apps with
+// the token rotation enabled.
+const rotatingPrefix = "xoxe."
+
+// IsRotatingToken returns true if tok is the access token of the Slack app
+// with the token rotation enabled, i.e. "xoxe.xoxp-...".
+func IsRotatingToken(tok string) bool {
+	return strings.HasPrefix(tok, rotatingPrefix)
+}
+
+// NewRotatingAuth returns the provider for the rotating access token and its
+// refresh token, issued to the Slack app with the clientID and clientSecret.
+// As the token expiry is unknown, the token is refreshed on the first use.
+func NewRotatingAuth(token, refreshToken, clientID, clientSecret string) (OAuthAuth, error) {
+	if token == "" || refreshToken == "" {
+		return OAuthAuth{}, ErrNoToken
+	}
+	if clientID == "" || clientSecret == "" {
+		return OAuthAuth{}, ErrNoClientID
+	}
+	return OAuthAuth{simpleProvider{
+		Token: token,
+		OAuth: &oauthState{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RefreshToken: refreshToken,
+			Expiry:       timeFunc().UTC(),
+		},
+	}}, nil
+}
+
+// OnRefresh sets the function, that is called with the provider p each time
+// its token is refreshed, i.e. to save the new credentials.  It returns false
+// if the token of p can't be refreshed.
+func OnRefresh(p Provider, fn func(Provider) error) bool {
+	op, ok := p.(interface{ oauth() *oauthState })
+	if !ok || op.oauth() == nil || op.oauth().RefreshToken == "" {
+		return false
+	}
+	st := op.oauth()
+	st.mu.Lock()
+	st.onRefresh = fn
+	st.mu.Unlock()
+	return true
+}
+
+// current returns the refreshed access token or initial, if the token was not
+// refreshed.
+func (st *oauthState) current(initial string) string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.token != "" {
+		return st.token
+	}
+	return initial
+}
+
+// fresh returns the access token, refreshing it with f, if it is about to
+// expire.  initial is the token, that the provider was created with.
+func (st *oauthState) fresh(ctx context.Context, f oauthFlow, initial string) (string, error) {
+	st.mu.Lock()
+	tok := st.token
+	if tok == "" {
+		tok = initial
+	}
+	if !st.expired() {
+		st.mu.Unlock()
+		return tok, nil
+	}
+	np, err := f.refresh(ctx, OAuthAuth{simpleProvider{Token: tok, OAuth: st}})
+	if err != nil {
+		st.mu.Unlock()
+		return "", err
+	}
+	st.token = np.Token
+	st.RefreshToken = np.OAuth.RefreshToken
+	st.Expiry = np.OAuth.Expiry
+	hook := st.onRefresh
+	st.mu.Unlock()
+
+	slog.DebugContext(ctx, "access token refreshed", "expiry", np.OAuth.Expiry)
+	if hook != nil {
+		if err := hook(OAuthAuth{simpleProvider{Token: np.Token, OAuth: st}}); err != nil {
+			slog.WarnContext(ctx, "unable to save the refreshed credentials", "error", err)
+		}
+	}
+	return np.Token, nil
+}
+
+// rotatingTransport refreshes the access token before it expires, and
+// replaces the token in the outgoing requests with the current one, so that
+// the clients, that were created with the initial token, continue to work.
+type rotatingTransport struct {
+	base    http.RoundTripper
+	st      *oauthState
+	initial string
+	flow    oauthFlow
+}
+
+func (t *rotatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tok, err := t.st.fresh(req.Context(), t.flow, t.initial)
+	if err != nil {
+		return nil, &Error{Err: err}
+	}
+	req, err = withToken(req, tok)
+	if err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// withToken returns the copy of the request with the token in the
+// Authorization header, query and the form body replaced by tok.  The
+// parameters, that are not present in the request, are not added.
+func withToken(req *http.Request, tok string) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		r.Header.Set("Authorization", "Bearer "+tok)
+	}
+	if q := r.URL.Query(); q.Has("token") {
+		q.Set("token", tok)
+		r.URL.RawQuery = q.Encode()
+	}
+	if r.Body == nil || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return r, nil
+	}
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if v, err := url.ParseQuery(string(body)); err == nil && v.Has("token") {
+		v.Set("token", tok)
+		body = []byte(v.Encode())
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	r.ContentLength = int64(len(body))
+	r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return r, nil
+}
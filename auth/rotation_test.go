@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingTransport(t *testing.T) {
+	var refreshes atomic.Int32
+	var gotTokens []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth.v2.access":
+			if r.FormValue("refresh_token") != "xoxe-1" {
+				w.Write([]byte(`{"ok":false,"error":"invalid_refresh_token"}`))
+				return
+			}
+			refreshes.Add(1)
+			w.Write([]byte(`{"ok":true,"access_token":"xoxe.xoxp-new","refresh_token":"xoxe-2","expires_in":43200}`))
+		case "/conversations.history":
+			gotTokens = append(gotTokens, r.FormValue("token"))
+			w.Write([]byte(`{"ok":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	p, err := NewRotatingAuth("xoxe.xoxp-old", "xoxe-1", "id", "secret")
+	require.NoError(t, err)
+	var saved []Provider
+	require.True(t, OnRefresh(p, func(p Provider) error {
+		saved = append(saved, p)
+		return nil
+	}))
+
+	cl := &http.Client{Transport: &rotatingTransport{
+		base:    http.DefaultTransport,
+		st:      p.OAuth,
+		initial: p.Token,
+		flow:    oauthFlow{cl: fakeClient(srv)},
+	}}
+	for range 2 {
+		resp, err := cl.PostForm(srv.URL+"/conversations.history", url.Values{"token": {"xoxe.xoxp-old"}, "channel": {"C1"}})
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, int32(1), refreshes.Load(), "token must be refreshed once")
+	assert.Equal(t, []string{"xoxe.xoxp-new", "xoxe.xoxp-new"}, gotTokens)
+	assert.Equal(t, "xoxe.xoxp-new", p.SlackToken(), "all copies of the provider must see the new token")
+	require.Len(t, saved, 1)
+	assert.Equal(t, "xoxe.xoxp-new", saved[0].SlackToken())
+	assert.Equal(t, "xoxe-2", p.OAuth.RefreshToken)
+	assert.False(t, p.Expired())
+}
+
+func Test_withToken(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "https://slack.com/api/files.list?token=old&count=10", strings.NewReader("token=old&channel=C1"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer old")
+
+	got, err := withToken(req, "new")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer new", got.Header.Get("Authorization"))
+	assert.Equal(t, "new", got.URL.Query().Get("token"))
+	assert.Equal(t, "10", got.URL.Query().Get("count"))
+	body, err := io.ReadAll(got.Body)
+	require.NoError(t, err)
+	v, err := url.ParseQuery(string(body))
+	require.NoError(t, err)
+	assert.Equal(t, url.Values{"token": {"new"}, "channel": {"C1"}}, v)
+	assert.Equal(t, int64(len(body)), got.ContentLength)
+}
+
+func TestNewRotatingAuth(t *testing.T) {
+	_, err := NewRotatingAuth("xoxe.xoxp-1", "xoxe-1", "", "")
+	assert.ErrorIs(t, err, ErrNoClientID)
+	_, err = NewRotatingAuth("xoxe.xoxp-1", "", "id", "secret")
+	assert.ErrorIs(t, err, ErrNoToken)
+
+	p, err := NewRotatingAuth("xoxe.xoxp-1", "xoxe-1", "id", "secret")
+	require.NoError(t, err)
+	assert.True(t, p.Expired(), "token of unknown age must be refreshed on the first use")
+	assert.True(t, IsRotatingToken(p.SlackToken()))
+}
+
+func TestOnRefresh_notRefreshable(t *testing.T) {
+	p, _ := NewValueAuth("xoxp-1", "")
+	assert.False(t, OnRefresh(p, func(Provider) error { return nil }))
+}
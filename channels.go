@@ -41,10 +41,25 @@ func (s *Session) StreamChannels(ctx context.Context, chanTypes []string, cb fun
 	})
 }
 
+// StreamChannelPages requests the channels from the API, starting from the
+// page with the cursor, or from the first page, if the cursor is empty, and
+// calls the callback function cb for each page with the cursor of the next
+// page.  The cursor is empty on the last page.  It allows to resume the
+// interrupted listing from the last processed page.
+func (s *Session) StreamChannelPages(ctx context.Context, chanTypes []string, cursor string, cb func(cc types.Channels, next string) error) error {
+	return s.getChannelPages(ctx, chanTypes, cursor, cb)
+}
+
 // getChannels list all conversations for a user.  `chanTypes` specifies
 // the type of messages to fetch.  See github.com/rusq/slack docs for possible
 // values
 func (s *Session) getChannels(ctx context.Context, chanTypes []string, cb func(types.Channels) error) error {
+	return s.getChannelPages(ctx, chanTypes, "", func(cc types.Channels, _ string) error {
+		return cb(cc)
+	})
+}
+
+func (s *Session) getChannelPages(ctx context.Context, chanTypes []string, cursor string, cb func(types.Channels, string) error) error {
 	ctx, task := trace.NewTask(ctx, "getChannels")
 	defer task.End()
 
@@ -54,7 +69,7 @@ func (s *Session) getChannels(ctx context.Context, chanTypes []string, cb func(t
 		chanTypes = AllChanTypes
 	}
 
-	params := &slack.GetConversationsParameters{Types: chanTypes, Limit: s.cfg.limits.Request.Channels}
+	params := &slack.GetConversationsParameters{Types: chanTypes, Limit: s.cfg.limits.Request.Channels, Cursor: cursor}
 	fetchStart := time.Now()
 	var total int
 	for i := 1; ; i++ {
@@ -63,7 +78,7 @@ func (s *Session) getChannels(ctx context.Context, chanTypes []string, cb func(t
 			nextcur string
 		)
 		reqStart := time.Now()
-		if err := network.WithRetry(ctx, limiter, s.cfg.limits.Tier3.Retries, func() error {
+		if err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "conversations.list"), s.adaptive, limiter, s.cfg.limits.Tier3.Retries, func() error {
 			var err error
 			trace.WithRegion(ctx, "GetConversationsContext", func() {
 				chans, nextcur, err = s.client.GetConversationsContext(ctx, params)
@@ -74,7 +89,7 @@ func (s *Session) getChannels(ctx context.Context, chanTypes []string, cb func(t
 			return err
 		}
 
-		if err := cb(chans); err != nil {
+		if err := cb(chans, nextcur); err != nil {
 			return err
 		}
 		total += len(chans)
@@ -105,7 +120,7 @@ func (sd *Session) GetChannelMembers(ctx context.Context, channelID string) ([]s
 	for {
 		var uu []string
 		var next string
-		if err := network.WithRetry(ctx, sd.limiter(network.Tier4), sd.cfg.limits.Tier4.Retries, func() error {
+		if err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "conversations.members"), sd.adaptive, sd.limiter(network.Tier4), sd.cfg.limits.Tier4.Retries, func() error {
 			var err error
 			uu, next, err = sd.client.GetUsersInConversationContext(ctx, &slack.GetUsersInConversationParameters{
 				ChannelID: channelID,
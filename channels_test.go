@@ -103,6 +103,42 @@ func TestSession_getChannels(t *testing.T) {
 	}
 }
 
+func TestSession_StreamChannelPages(t *testing.T) {
+	mc := NewmockClienter(gomock.NewController(t))
+	sd := &Session{client: mc, cfg: defConfig, log: slog.Default()}
+
+	ch := func(id string) slack.Channel {
+		var c slack.Channel
+		c.ID = id
+		return c
+	}
+	gomock.InOrder(
+		mc.EXPECT().GetConversationsContext(gomock.Any(), &slack.GetConversationsParameters{
+			Limit:  network.DefLimits.Request.Channels,
+			Types:  AllChanTypes,
+			Cursor: "page2",
+		}).Return([]slack.Channel{ch("C2")}, "page3", nil),
+		mc.EXPECT().GetConversationsContext(gomock.Any(), &slack.GetConversationsParameters{
+			Limit:  network.DefLimits.Request.Channels,
+			Types:  AllChanTypes,
+			Cursor: "page3",
+		}).Return([]slack.Channel{ch("C3")}, "", nil),
+	)
+
+	var (
+		got   types.Channels
+		nexts []string
+	)
+	err := sd.StreamChannelPages(context.Background(), nil, "page2", func(cc types.Channels, next string) error {
+		got = append(got, cc...)
+		nexts = append(nexts, next)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, types.Channels{ch("C2"), ch("C3")}, got)
+	assert.Equal(t, []string{"page3", ""}, nexts)
+}
+
 func TestSession_GetChannels(t *testing.T) {
 	type fields struct {
 		client clienter
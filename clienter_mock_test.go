@@ -57,6 +57,21 @@ func (mr *MockSlackerMockRecorder) AuthTestContext(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthTestContext", reflect.TypeOf((*MockSlacker)(nil).AuthTestContext), arg0)
 }
 
+// GetBotInfoContext mocks base method.
+func (m *MockSlacker) GetBotInfoContext(ctx context.Context, parameters slack.GetBotInfoParameters) (*slack.Bot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBotInfoContext", ctx, parameters)
+	ret0, _ := ret[0].(*slack.Bot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBotInfoContext indicates an expected call of GetBotInfoContext.
+func (mr *MockSlackerMockRecorder) GetBotInfoContext(ctx, parameters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBotInfoContext", reflect.TypeOf((*MockSlacker)(nil).GetBotInfoContext), ctx, parameters)
+}
+
 // GetConversationHistoryContext mocks base method.
 func (m *MockSlacker) GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
 	m.ctrl.T.Helper()
@@ -254,6 +269,21 @@ func (mr *mockClienterMockRecorder) AuthTestContext(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthTestContext", reflect.TypeOf((*mockClienter)(nil).AuthTestContext), arg0)
 }
 
+// GetBotInfoContext mocks base method.
+func (m *mockClienter) GetBotInfoContext(ctx context.Context, parameters slack.GetBotInfoParameters) (*slack.Bot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBotInfoContext", ctx, parameters)
+	ret0, _ := ret[0].(*slack.Bot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBotInfoContext indicates an expected call of GetBotInfoContext.
+func (mr *mockClienterMockRecorder) GetBotInfoContext(ctx, parameters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBotInfoContext", reflect.TypeOf((*mockClienter)(nil).GetBotInfoContext), ctx, parameters)
+}
+
 // GetConversationHistoryContext mocks base method.
 func (m *mockClienter) GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
 	m.ctrl.T.Helper()
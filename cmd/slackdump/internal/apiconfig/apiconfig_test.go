@@ -33,6 +33,10 @@ download_retries = 3
   conversations = 100
   channels = 100
   replies = 200
+
+[adaptive]
+  max_boost = 2.0
+  ramp_after = 20
 `
 	// workers set to 55 in this one, tier2.retries to 330
 	updatedConfigYaml = `workers = 55
@@ -83,6 +87,10 @@ var testLimits = network.Limits{
 		Channels:      100,
 		Replies:       200,
 	},
+	Adaptive: network.AdaptiveLimit{
+		MaxBoost:  2,
+		RampAfter: 20,
+	},
 }
 
 func Test_readConfig(t *testing.T) {
@@ -26,6 +26,18 @@ to specify the filename, for example:
     slackdump config new myconfig.toml
 
 If the extension is omitted, ".toml" is automatically appended to the filename.
+
+## Adaptive rate limiting
+
+The "[adaptive]" section controls the adaptive rate limiting, which is
+disabled by default.  When it is enabled, each API method starts with the
+rate of its tier, and the rate is increased by 10% of the tier rate after
+every "ramp_after" successful calls, up to "max_boost" times the tier rate.
+When the method is rate limited, its rate is halved, but not lower than a
+quarter of the tier rate.  Other methods are not affected.  If the section is
+missing, or "enabled" is false, the static tier limits are used.  Note that
+with "max_boost" above 1 the requests may exceed the rates documented by
+Slack.
 `,
 	FlagMask:   cfg.OmitAll,
 	PrintFlags: true,
@@ -8,13 +8,17 @@ import (
 	"time"
 
 	"github.com/rusq/fsadapter"
+	"github.com/schollz/progressbar/v3"
 
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/bootstrap"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/downloader"
 	"github.com/rusq/slackdump/v3/internal/chunk"
 	"github.com/rusq/slackdump/v3/internal/chunk/control"
 	"github.com/rusq/slackdump/v3/internal/chunk/transform/fileproc"
+	"github.com/rusq/slackdump/v3/internal/errreport"
+	"github.com/rusq/slackdump/v3/internal/linkpreview"
 	"github.com/rusq/slackdump/v3/internal/structures"
 	"github.com/rusq/slackdump/v3/stream"
 )
@@ -32,8 +36,24 @@ var CmdArchive = &base.Command{
 	PrintFlags:  true,
 }
 
+// userGroups enables recording of the user groups.
+var userGroups bool
+
+// linkPreviews enables fetching the snapshots of the linked pages.
+var linkPreviews bool
+
+// linkPreviewWorkers is the number of the pages fetched concurrently.
+const linkPreviewWorkers = 8
+
 func init() {
 	CmdArchive.Wizard = archiveWizard
+	CmdArchive.Flag.BoolVar(&linkPreviews, "link-previews", false, "fetch the title, description and image of the external links in the\nmessages, and save them to \""+linkpreview.Filename+"\"")
+	CmdArchive.Flag.BoolVar(&userGroups, "usergroups", false, "record the user groups of the workspace with their members")
+	cfg.SetAnnotationFlags(&CmdArchive.Flag)
+	cfg.SetRetentionFlags(&CmdArchive.Flag)
+	cfg.SetChannelFilterFlags(&CmdArchive.Flag)
+	cfg.SetFilterFlags(&CmdArchive.Flag)
+	cfg.SetRedactFlags(&CmdArchive.Flag)
 }
 
 var errNoOutput = errors.New("output directory is required")
@@ -63,41 +83,98 @@ func RunArchive(ctx context.Context, cmd *base.Command, args []string) error {
 		base.SetExitStatus(base.SInitializationError)
 		return err
 	}
+	if err := bootstrap.ResolveChannelNames(ctx, sess, list); err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	bootstrap.Preflight(ctx, sess, time.Time(cfg.Oldest))
 	lg := cfg.Log
+	rep := errreport.New()
+	pb := bootstrap.ProgressBar(ctx, lg, progressbar.OptionShowCount())
+	pt := bootstrap.ProgressTracker(pb)
 	stream := sess.Stream(
 		stream.OptLatest(time.Time(cfg.Latest)),
 		stream.OptOldest(time.Time(cfg.Oldest)),
+		stream.OptChannelSplit(cfg.ChannelSplit),
+		stream.OptPins(cfg.Pins),
 		stream.OptResultFn(resultLogger(lg)),
+		stream.OptErrorFn(rep.StreamError),
+		stream.OptProgress(pt),
 	)
-	dl, stop := fileproc.NewDownloader(
-		ctx,
-		cfg.DownloadFiles,
-		sess.Client(),
-		fsadapter.NewDirectory(cd.Name()),
-		lg,
-	)
+	fsa := fsadapter.NewDirectory(cd.Name())
+	defer bootstrap.FinishReport(ctx, fsa, rep)
+	sched, err := bootstrap.RetentionScheduler(ctx, sess)
+	if err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	defer bootstrap.ReportRetention(ctx, sched, rep)
+	dlState, tracker := bootstrap.DownloadState(cd.Name())
+	defer bootstrap.FinishDownloadState(ctx, fsa, dlState)
+	dlOpts := append(sess.DownloadOptions(), downloader.WithErrorFunc(rep.DownloadError), tracker, downloader.WithTracker(pt.Downloads()))
+	dl, stop := fileproc.NewDownloader(ctx, cfg.DownloadFiles, sess.Client(), fsa, lg, dlOpts...)
 	defer stop()
 	// we are using the same file subprocessor as the mattermost export.
 	subproc := fileproc.NewExport(fileproc.STmattermost, dl)
-	ctrl := control.New(
-		cd,
-		stream,
+	opts := []control.Option{
 		control.WithLogger(lg),
 		control.WithFiler(subproc),
-		control.WithFlags(control.Flags{MemberOnly: cfg.MemberOnly}),
-	)
+		control.WithFlags(control.Flags{
+			MemberOnly:      cfg.MemberOnly,
+			UserGroups:      userGroups,
+			ExcludeArchived: !cfg.IncludeArchived,
+			ExcludeShared:   !cfg.IncludeShared,
+		}),
+		control.WithAnnotations(cfg.Annotations()...),
+	}
+	if sched != nil {
+		opts = append(opts, control.WithRetention(sched))
+	}
+	if mw := cfg.Middlewares(); len(mw) > 0 {
+		opts = append(opts, control.WithMiddleware(mw...))
+	}
+	ctrl := control.New(cd, stream, opts...)
 	if err := ctrl.Run(ctx, list); err != nil {
+		_ = pb.Finish()
 		base.SetExitStatus(base.SApplicationError)
 		return err
 	}
+	pt.Finish()
+	_ = pb.Finish()
+	if linkPreviews {
+		if err := saveLinkPreviews(ctx, cd, fsa); err != nil {
+			lg.WarnContext(ctx, "failed to save the link previews", "error", err)
+		}
+	}
 	lg.Info("Recorded workspace data", "filename", cd.Name(), "took", time.Since(start))
 
 	return nil
 }
 
+// saveLinkPreviews fetches the previews of the links in the recorded
+// messages, and saves them to the sidecar file.
+func saveLinkPreviews(ctx context.Context, cd *chunk.Directory, fsa fsadapter.FS) error {
+	links, err := linkpreview.FromChunks(ctx, cd)
+	if err != nil {
+		return err
+	}
+	lg := cfg.Log
+	lg.InfoContext(ctx, "fetching the link previews", "links", len(links))
+	pp := linkpreview.NewFetcher(nil).FetchAll(ctx, links, linkPreviewWorkers)
+	w, err := fsa.Create(linkpreview.Filename)
+	if err != nil {
+		return err
+	}
+	if err := linkpreview.Write(w, pp); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
 func resultLogger(lg *slog.Logger) func(sr stream.Result) error {
 	return func(sr stream.Result) error {
-		lg.Info("stream", "result", sr.String())
+		lg.Debug("stream", "result", sr.String())
 		return nil
 	}
 }
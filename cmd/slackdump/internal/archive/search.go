@@ -3,21 +3,28 @@ package archive
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/rusq/fsadapter"
 	"github.com/schollz/progressbar/v3"
 
+	"github.com/rusq/slackdump/v3"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/bootstrap"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
 	"github.com/rusq/slackdump/v3/internal/chunk"
 	"github.com/rusq/slackdump/v3/internal/chunk/control"
 	"github.com/rusq/slackdump/v3/internal/chunk/transform/fileproc"
+	"github.com/rusq/slackdump/v3/internal/nametmpl"
+	"github.com/rusq/slackdump/v3/internal/remotefs"
 	"github.com/rusq/slackdump/v3/stream"
+	"github.com/rusq/slackdump/v3/types"
 )
 
 var CmdSearch = &base.Command{
@@ -26,6 +33,8 @@ var CmdSearch = &base.Command{
 	Long:        searchMD,
 	Wizard:      wizSearch,
 	RequireAuth: true,
+	FlagMask:    flagMask,
+	PrintFlags:  true,
 	Commands: []*base.Command{
 		cmdSearchMessages,
 		cmdSearchFiles,
@@ -71,11 +80,84 @@ var cmdSearchAll = &base.Command{
 var fastSearch bool
 
 func init() {
+	// CmdSearch run directly with the query dumps the messages in the
+	// standard dump format, subcommands record the results to chunks.
+	CmdSearch.Run = runSearch
 	for _, cmd := range []*base.Command{cmdSearchMessages, cmdSearchFiles, cmdSearchAll} {
 		cmd.Flag.BoolVar(&fastSearch, "no-channel-users", false, "skip channel users (approx ~2.5x faster)")
 	}
 }
 
+// runSearch dumps all messages matching the query in the standard dump
+// format, one file per channel.
+func runSearch(ctx context.Context, cmd *base.Command, args []string) error {
+	if len(args) == 0 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("missing query parameter")
+	}
+	if cfg.Output == "" {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errNoOutput
+	}
+	query := strings.Join(args, " ")
+
+	tmpl, err := nametmpl.New(nametmpl.Default + ".json")
+	if err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+
+	fsa, err := remotefs.New(cfg.Output)
+	if err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	defer func() {
+		if err := fsa.Close(); err != nil {
+			cfg.Log.WarnContext(ctx, "warning: failed to close the filesystem", "error", err)
+		}
+	}()
+
+	sess, err := bootstrap.SlackdumpSession(ctx, slackdump.WithFilesystem(fsa))
+	if err != nil {
+		base.SetExitStatus(base.SInitializationError)
+		return err
+	}
+
+	start := time.Now()
+	convs, err := sess.DumpSearch(ctx, query)
+	if err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	if err := writeConversations(fsa, tmpl, convs); err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	cfg.Log.InfoContext(ctx, "search dump finished", "query", query, "conversations", len(convs), "output", cfg.Output, "took", time.Since(start))
+	return nil
+}
+
+// writeConversations writes each conversation to a separate file, named
+// according to the template tmpl.
+func writeConversations(fsa fsadapter.FS, tmpl *nametmpl.Template, convs []*types.Conversation) error {
+	for _, conv := range convs {
+		name := tmpl.Execute(conv)
+		f, err := fsa.Create(name)
+		if err != nil {
+			return fmt.Errorf("unable to create file %s: %w", name, err)
+		}
+		if err := json.NewEncoder(f).Encode(conv); err != nil {
+			f.Close()
+			return fmt.Errorf("error encoding %s: %w", name, err)
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func runSearchMsg(ctx context.Context, cmd *base.Command, args []string) error {
 	ctrl, stop, err := initController(ctx, args)
 	if err != nil {
@@ -144,17 +226,21 @@ func initController(ctx context.Context, args []string) (*control.Controller, fu
 	defer cd.Close()
 
 	lg := slog.Default()
+	fsa := fsadapter.NewDirectory(cd.Name())
+	dlState, tracker := bootstrap.DownloadState(cd.Name())
 	dl, dlstop := fileproc.NewDownloader(
 		ctx,
 		cfg.DownloadFiles,
 		sess.Client(),
-		fsadapter.NewDirectory(cd.Name()),
+		fsa,
 		lg,
+		append(sess.DownloadOptions(), tracker)...,
 	)
 
 	pb := bootstrap.ProgressBar(ctx, lg, progressbar.OptionShowCount()) // progress bar
 	stop := func() {
 		dlstop()
+		bootstrap.FinishDownloadState(ctx, fsa, dlState)
 		pb.Finish()
 	}
 
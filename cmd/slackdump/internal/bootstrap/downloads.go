@@ -0,0 +1,56 @@
+package bootstrap
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/rusq/fsadapter"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/downloader"
+	"github.com/rusq/slackdump/v3/internal/chunk/state"
+	"github.com/rusq/slackdump/v3/internal/chunk/transform/fileproc"
+	"github.com/rusq/slackdump/v3/internal/remotefs"
+)
+
+// DownloadStateFile is the name of the file download state file, that is
+// written to the output, if any files were downloaded.
+const DownloadStateFile = "downloads.state.json"
+
+// DownloadState returns the file download state for the output, and the
+// downloader option that tracks the downloads in it.  The output is the
+// directory, the ZIP file or the remote location, where the files are
+// downloaded to.
+func DownloadState(output string) (*state.State, downloader.Option) {
+	st := state.New("")
+	if abs, err := filepath.Abs(output); err == nil && !remotefs.IsRemote(output) {
+		output = abs
+	}
+	st.SetFilesDir(output)
+	return st, downloader.WithTracker(fileproc.NewStateTracker(st))
+}
+
+// FinishDownloadState writes the download state to fsa, if any files were
+// queued for download.  If some of the files failed, it prints the command
+// to retry them.  It must be called after the downloads are complete and
+// before fsa is closed.
+func FinishDownloadState(ctx context.Context, fsa fsadapter.FS, st *state.State) {
+	if len(st.Downloads) == 0 {
+		return
+	}
+	lg := cfg.Log
+	if err := st.SaveFSA(fsa, DownloadStateFile); err != nil {
+		lg.ErrorContext(ctx, "unable to save the download state", "error", err)
+		return
+	}
+	failed := st.DownloadPaths(state.DSFailed)
+	if len(failed) == 0 {
+		return
+	}
+	if cfg.StripZipExt(st.FilesDir) != st.FilesDir {
+		// files in the ZIP archive can't be retried in place.
+		lg.WarnContext(ctx, "some files failed to download", "count", len(failed), "state_file", DownloadStateFile)
+		return
+	}
+	lg.WarnContext(ctx, "some files failed to download, to retry, run: slackdump files retry "+filepath.Join(st.FilesDir, DownloadStateFile), "count", len(failed))
+}
@@ -0,0 +1,29 @@
+package bootstrap
+
+import (
+	"context"
+	"os"
+
+	"github.com/rusq/fsadapter"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/internal/errreport"
+)
+
+// FinishReport writes the errors.jsonl report to fsa and prints the summary
+// table to stderr, if any channels, threads or files failed during the run.
+// It must be called after the downloads are complete and before fsa is
+// closed.
+func FinishReport(ctx context.Context, fsa fsadapter.FS, rep *errreport.Report) {
+	if rep.Len() == 0 {
+		return
+	}
+	lg := cfg.Log
+	if err := rep.Write(fsa); err != nil {
+		lg.ErrorContext(ctx, "unable to write the error report", "error", err)
+	}
+	lg.WarnContext(ctx, "some of the entities were skipped or failed", "count", rep.Len(), "report", errreport.Filename)
+	if err := rep.Summary(os.Stderr); err != nil {
+		lg.ErrorContext(ctx, "unable to print the error summary", "error", err)
+	}
+}
@@ -0,0 +1,64 @@
+package bootstrap
+
+import (
+	"context"
+	"os"
+
+	"github.com/rusq/slackdump/v3/auth"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/workspace"
+	"github.com/rusq/slackdump/v3/internal/hydrate"
+)
+
+// Hydrator returns the hydration service for the archive directory dir,
+// initialised with the results cached in it.  If online is true, and the
+// credentials for the current workspace are available, the unknown IDs are
+// looked up with the API, otherwise, only the cached results are used.  It
+// never prompts the user to log in.
+func Hydrator(ctx context.Context, dir string, online bool) *hydrate.Service {
+	lg := cfg.Log
+	var cache *hydrate.Cache
+	if isDir(dir) {
+		c, err := hydrate.LoadCache(dir)
+		if err != nil {
+			lg.WarnContext(ctx, "unable to load the hydration cache, ignoring", "dir", dir, "error", err)
+		} else {
+			cache = c
+		}
+	}
+	opts := []hydrate.Option{hydrate.WithLogger(lg)}
+	if !online {
+		return hydrate.New(nil, cache, opts...)
+	}
+	prov, err := workspace.AuthCurrent(ctx, cfg.CacheDir(), cfg.Workspace, cfg.LegacyBrowser)
+	if err != nil {
+		lg.WarnContext(ctx, "no credentials, unknown users and channels will be resolved from the cache only", "error", err)
+		return hydrate.New(nil, cache, opts...)
+	}
+	sess, err := SlackdumpSession(auth.WithContext(ctx, prov))
+	if err != nil || sess.Client() == nil {
+		lg.WarnContext(ctx, "unable to initialise the session, unknown users and channels will be resolved from the cache only", "error", err)
+		return hydrate.New(nil, cache, opts...)
+	}
+	return hydrate.New(sess.Client(), cache, opts...)
+}
+
+// SaveHydrator saves the results of the lookups to the archive directory dir,
+// if there are new ones.  If dir is not a directory, i.e. a ZIP file, the
+// results are not saved.
+func SaveHydrator(ctx context.Context, dir string, h *hydrate.Service) {
+	if h == nil || !h.Dirty() {
+		return
+	}
+	if !isDir(dir) {
+		return
+	}
+	if err := h.Cache().Save(dir); err != nil {
+		cfg.Log.WarnContext(ctx, "unable to save the hydration cache", "dir", dir, "error", err)
+	}
+}
+
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
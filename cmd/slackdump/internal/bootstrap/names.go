@@ -0,0 +1,61 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/internal/cache"
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+// chanCacheRetention is the maximum age of the channel cache, that is used
+// to resolve the channel names.  Same as the default of "list channels".
+const chanCacheRetention = 20 * time.Minute
+
+// ResolveChannelNames resolves the channel name patterns in the list, i.e.
+// "#dev-*", to the channel IDs.  Channels are loaded from the channel cache,
+// or, if it's stale, fetched from the API and cached.  It does nothing, if
+// the list has no name patterns.
+func ResolveChannelNames(ctx context.Context, sess *slackdump.Session, list *structures.EntityList) error {
+	if !list.HasPatterns() {
+		return nil
+	}
+	lg := cfg.Log
+	cc, err := channels(ctx, sess)
+	if err != nil {
+		return fmt.Errorf("error resolving channel names: %w", err)
+	}
+	for _, p := range list.ResolveNames(cc) {
+		lg.WarnContext(ctx, "channel name pattern did not match any channels", "pattern", p)
+	}
+	lg.DebugContext(ctx, "channel names resolved", "included", list.IncludeCount(), "excluded", list.ExcludeCount())
+	return nil
+}
+
+// channels returns the channels from the cache, or from the API, if the cache
+// is stale.
+func channels(ctx context.Context, sess *slackdump.Session) ([]slack.Channel, error) {
+	lg := cfg.Log
+	teamID := sess.Info().TeamID
+	m, err := cache.NewManager(cfg.CacheDir())
+	if err != nil {
+		return nil, err
+	}
+	if cc, err := m.LoadChannels(teamID, chanCacheRetention); err == nil {
+		return cc, nil
+	}
+	lg.InfoContext(ctx, "fetching channels to resolve the channel names")
+	cc, err := sess.GetChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.CacheChannels(teamID, cc); err != nil {
+		lg.WarnContext(ctx, "failed to cache channels (ignored)", "error", err)
+	}
+	return cc, nil
+}
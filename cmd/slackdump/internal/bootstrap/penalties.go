@@ -0,0 +1,34 @@
+package bootstrap
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/cache"
+	"github.com/rusq/slackdump/v3/internal/network"
+)
+
+// WithPenalties returns the context with the rate limit penalties recorded
+// for the workspace wsp by the previous run, so that back-to-back runs don't
+// hit the same endpoints while they're still penalised.  The penalties are
+// saved back to the cache directory on exit.
+func WithPenalties(ctx context.Context, wsp string) context.Context {
+	m, err := cache.NewManager(cfg.CacheDir())
+	if err != nil {
+		slog.WarnContext(ctx, "failed to open the cache directory", "error", err)
+		return ctx
+	}
+	pb, err := m.LoadPenalties(wsp)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load rate limit penalties", "workspace", wsp, "error", err)
+		pb = network.NewPenaltyBox()
+	}
+	base.AtExit(func() {
+		if err := m.SavePenalties(wsp, pb); err != nil {
+			slog.Warn("failed to save rate limit penalties", "workspace", wsp, "error", err)
+		}
+	})
+	return network.WithPenaltyBox(ctx, pb)
+}
@@ -0,0 +1,24 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/rusq/slackdump/v3"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/types"
+)
+
+// Preflight fetches the workspace details, and warns the user about the API
+// limitations of the workspace, i.e. that the messages older than 90 days
+// are not accessible on the free plan.  oldest is the oldest requested
+// message time, zero means from the beginning.  It never returns nil.
+func Preflight(ctx context.Context, sess *slackdump.Session, oldest time.Time) *types.Workspace {
+	lg := cfg.Log
+	wd := sess.Stream().WorkspaceDetails(ctx, sess.Info())
+	lg.DebugContext(ctx, "workspace details", "plan", wd.Plan, "users", wd.UserCount())
+	if from := wd.AccessibleFrom(time.Now()); !from.IsZero() && oldest.Before(from) {
+		lg.WarnContext(ctx, "the workspace is on the free plan, messages older than 90 days are not accessible and will be missing", "accessible_from", from.Format(time.DateOnly))
+	}
+	return wd
+}
@@ -5,6 +5,8 @@ import (
 	"log/slog"
 
 	"github.com/schollz/progressbar/v3"
+
+	"github.com/rusq/slackdump/v3/progress"
 )
 
 func ProgressBar(ctx context.Context, lg *slog.Logger, opts ...progressbar.Option) *progressbar.ProgressBar {
@@ -28,3 +30,16 @@ func newProgressBar(pb *progressbar.ProgressBar, debug bool) *progressbar.Progre
 	}
 	return pb
 }
+
+// ProgressTracker returns the progress tracker, that renders the progress
+// stats on the progress bar pb.  Once the total number of channels is known,
+// the spinner turns into the bar.
+func ProgressTracker(pb *progressbar.ProgressBar) *progress.Tracker {
+	return progress.New(func(s progress.Stats) {
+		if s.ChannelsTotal > 0 && int64(s.ChannelsTotal) != pb.GetMax64() {
+			pb.ChangeMax(s.ChannelsTotal)
+		}
+		pb.Describe(s.String())
+		_ = pb.Set(s.Channels)
+	})
+}
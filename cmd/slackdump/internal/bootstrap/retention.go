@@ -0,0 +1,75 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rusq/slackdump/v3"
+	"github.com/rusq/slackdump/v3/auth"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/internal/edge"
+	"github.com/rusq/slackdump/v3/internal/errreport"
+	"github.com/rusq/slackdump/v3/internal/retention"
+)
+
+// RetentionScheduler returns the retention scheduler, if the retention-aware
+// mode is enabled, otherwise, or if the workspace keeps the messages forever,
+// it returns nil.  The retention is taken from the -retention flag, or from
+// the workspace settings, which are available only to the browser tokens.
+func RetentionScheduler(ctx context.Context, sess *slackdump.Session) (*retention.Scheduler, error) {
+	if !cfg.RetentionAware && cfg.Retention == 0 {
+		return nil, nil
+	}
+	lg := cfg.Log
+	var p retention.Policy
+	if cfg.Retention > 0 {
+		p = retention.Uniform(time.Duration(cfg.Retention))
+	} else {
+		var err error
+		if p, err = workspaceRetention(ctx, sess); err != nil {
+			return nil, fmt.Errorf("unable to get the message retention settings of the workspace, specify it with -retention: %w", err)
+		}
+	}
+	if p.IsZero() {
+		lg.InfoContext(ctx, "the workspace keeps the messages forever, the retention-aware mode has no effect")
+		return nil, nil
+	}
+	lg.InfoContext(ctx, "fetching the conversations at risk of deletion first", "retention", p.String())
+	return retention.NewScheduler(p), nil
+}
+
+// workspaceRetention returns the retention policy from the workspace
+// preferences.
+func workspaceRetention(ctx context.Context, sess *slackdump.Session) (retention.Policy, error) {
+	prov, err := auth.FromContext(ctx)
+	if err != nil {
+		return retention.Policy{}, err
+	}
+	cl, err := edge.NewWithInfo(sess.Info(), prov)
+	if err != nil {
+		return retention.Policy{}, err
+	}
+	defer cl.Close()
+	ub, err := cl.ClientUserBoot(ctx)
+	if err != nil {
+		return retention.Policy{}, err
+	}
+	return retention.FromPrefs(&ub.Team.Prefs), nil
+}
+
+// ReportRetention adds the conversations, the messages of which might have
+// been deleted by the retention policy before they were saved, to the report.
+// sched may be nil.  It must be called before [FinishReport].
+func ReportRetention(ctx context.Context, sched *retention.Scheduler, rep *errreport.Report) {
+	if sched == nil {
+		return
+	}
+	risks := sched.Risks()
+	for _, r := range risks {
+		rep.Add(errreport.Entry{Kind: errreport.KRetention, ChannelID: r.ChannelID, Reason: r.String()})
+	}
+	if len(risks) > 0 {
+		cfg.Log.WarnContext(ctx, "some conversations might have lost the messages to the retention policy", "count", len(risks))
+	}
+}
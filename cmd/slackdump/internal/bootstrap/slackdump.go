@@ -2,10 +2,14 @@ package bootstrap
 
 import (
 	"context"
+	"log/slog"
 
 	"github.com/rusq/slackdump/v3"
 	"github.com/rusq/slackdump/v3/auth"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/workspace"
+	"github.com/rusq/slackdump/v3/internal/cache"
 )
 
 // SlackdumpSession returns the Slackdump Session initialised with the provider
@@ -22,12 +26,49 @@ func SlackdumpSession(ctx context.Context, opts ...slackdump.Option) (*slackdump
 		slackdump.WithLogger(cfg.Log),
 		slackdump.WithForceEnterprise(cfg.ForceEnterprise),
 		slackdump.WithLimits(cfg.Limits),
+		slackdump.WithFileWorkers(cfg.FileWorkers),
+		slackdump.WithBandwidthLimit(cfg.BandwidthLimit),
+	}
+
+	if !cfg.NoChanInfoCache {
+		if c, err := chanInfoCache(); err != nil {
+			cfg.Log.WarnContext(ctx, "channel information cache is disabled", "error", err)
+		} else {
+			stdOpts = append(stdOpts, slackdump.WithChannelInfoCache(c))
+		}
 	}
 
 	stdOpts = append(stdOpts, opts...)
-	return slackdump.NewNoValidate(
+	sess, err := slackdump.NewNoValidate(
 		ctx,
 		prov,
 		stdOpts...,
 	)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Monitor != nil {
+		cfg.Monitor.Adaptive(sess.Adaptive())
+	}
+	return sess, nil
+}
+
+// chanInfoCache returns the channel information cache of the current
+// workspace, that is saved on exit.
+func chanInfoCache() (*cache.ChannelInfoCache, error) {
+	m, err := cache.NewManager(cfg.CacheDir())
+	if err != nil {
+		return nil, err
+	}
+	wsp, err := workspace.Current(cfg.CacheDir(), cfg.Workspace)
+	if err != nil {
+		return nil, err
+	}
+	c := m.ChannelInfoCache(wsp, cfg.ChanInfoCacheRetention)
+	base.AtExit(func() {
+		if err := c.Save(); err != nil {
+			slog.Warn("failed to save the channel information cache", "error", err)
+		}
+	})
+	return c, nil
 }
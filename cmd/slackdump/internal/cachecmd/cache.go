@@ -0,0 +1,63 @@
+// Package cachecmd implements the commands to maintain the cache directory.
+package cachecmd
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/cache"
+)
+
+const baseCommand = "slackdump cache"
+
+var flagmask = cfg.OmitAll &^ cfg.OmitCacheDir
+
+var CmdCache = &base.Command{
+	UsageLine: baseCommand,
+	Short:     "maintain the cache directory",
+	Long: `
+# Cache Command
+
+Cache command allows to maintain the cache directory, where slackdump keeps
+the workspace credentials and the user and channel caches.
+
+The cache directory is automatically detected to be:
+    ` + cfg.CacheDir() + `
+`,
+	FlagMask: flagmask,
+	Commands: []*base.Command{
+		CmdCacheMigrate,
+	},
+}
+
+// AutoMigrate migrates the files in the cache directory dir, created by the
+// previous versions of slackdump, to the current format, if it was not done
+// yet.  The errors are logged, as they should not prevent slackdump from
+// running.
+func AutoMigrate(ctx context.Context, dir string) {
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		// nothing to migrate, and the manager would create the directory.
+		return
+	}
+	m, err := cache.NewManager(dir)
+	if err != nil {
+		slog.WarnContext(ctx, "unable to check the cache directory", "error", err)
+		return
+	}
+	if m.Migrated() {
+		return
+	}
+	steps, backup, err := m.Migrate()
+	if err != nil {
+		slog.WarnContext(ctx, "unable to migrate the cache directory, run \""+baseCommand+" migrate\" to retry", "error", err, "backup", backup)
+		return
+	}
+	if len(steps) > 0 {
+		slog.InfoContext(ctx, "cache directory migrated to the current format", "changes", len(steps), "backup", backup)
+	}
+}
@@ -0,0 +1,78 @@
+package cachecmd
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/cache"
+)
+
+//go:embed assets/migrate.md
+var migrateMd string
+
+var CmdCacheMigrate = &base.Command{
+	UsageLine:  baseCommand + " migrate [flags]",
+	Short:      "migrate the cache files of the previous versions",
+	Long:       migrateMd,
+	FlagMask:   flagmask,
+	PrintFlags: true,
+}
+
+var dryRun = CmdCacheMigrate.Flag.Bool("dry-run", false, "print the changes without applying them")
+
+func init() {
+	CmdCacheMigrate.Run = runMigrate
+}
+
+// migrator is used for test rigging.
+type migrator interface {
+	Plan() ([]cache.Step, error)
+	Migrate() ([]cache.Step, string, error)
+}
+
+func runMigrate(ctx context.Context, cmd *base.Command, args []string) error {
+	m, err := cache.NewManager(cfg.CacheDir())
+	if err != nil {
+		base.SetExitStatus(base.SCacheError)
+		return err
+	}
+	return migrate(os.Stdout, m, *dryRun)
+}
+
+func migrate(w io.Writer, m migrator, dry bool) error {
+	var (
+		steps  []cache.Step
+		backup string
+		err    error
+	)
+	if dry {
+		steps, err = m.Plan()
+	} else {
+		steps, backup, err = m.Migrate()
+	}
+	if err != nil {
+		base.SetExitStatus(base.SCacheError)
+		if backup != "" {
+			return fmt.Errorf("%w (original files are in %s)", err, backup)
+		}
+		return err
+	}
+	if len(steps) == 0 {
+		fmt.Fprintln(w, "Cache directory is up to date.")
+		return nil
+	}
+	for _, s := range steps {
+		fmt.Fprintln(w, s)
+	}
+	if dry {
+		fmt.Fprintf(w, "\n%d change(s) will be made, run without -dry-run to apply.\n", len(steps))
+	} else {
+		fmt.Fprintf(w, "\n%d change(s) made, original files are in %s\n", len(steps), backup)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cachecmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v3/internal/cache"
+)
+
+type fakeMigrator struct {
+	steps   []cache.Step
+	backup  string
+	err     error
+	applied bool
+}
+
+func (f *fakeMigrator) Plan() ([]cache.Step, error) {
+	return f.steps, f.err
+}
+
+func (f *fakeMigrator) Migrate() ([]cache.Step, string, error) {
+	f.applied = true
+	return f.steps, f.backup, f.err
+}
+
+func Test_migrate(t *testing.T) {
+	steps := []cache.Step{
+		{Action: cache.ActEncryptCreds, File: "acme.bin"},
+		{Action: cache.ActConvertCache, File: "users-T1.json", Target: "users-T1.cache"},
+	}
+	t.Run("dry run", func(t *testing.T) {
+		var buf bytes.Buffer
+		m := &fakeMigrator{steps: steps}
+		assert.NoError(t, migrate(&buf, m, true))
+		assert.False(t, m.applied, "dry run must not change anything")
+		assert.Equal(t, "encrypt credentials: acme.bin\n"+
+			"convert cache: users-T1.json -> users-T1.cache\n"+
+			"\n2 change(s) will be made, run without -dry-run to apply.\n", buf.String())
+	})
+	t.Run("migrate", func(t *testing.T) {
+		var buf bytes.Buffer
+		m := &fakeMigrator{steps: steps, backup: "/tmp/slackdump-backup"}
+		assert.NoError(t, migrate(&buf, m, false))
+		assert.True(t, m.applied)
+		assert.Contains(t, buf.String(), "2 change(s) made, original files are in /tmp/slackdump-backup")
+	})
+	t.Run("up to date", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, migrate(&buf, &fakeMigrator{}, false))
+		assert.Equal(t, "Cache directory is up to date.\n", buf.String())
+	})
+	t.Run("error", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := migrate(&buf, &fakeMigrator{err: errors.New("boom"), backup: "/tmp/b"}, false)
+		assert.ErrorContains(t, err, "boom (original files are in /tmp/b)")
+	})
+}
@@ -0,0 +1,66 @@
+package cfg
+
+import (
+	"flag"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/rusq/osenv/v2"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+)
+
+var (
+	// Notes are the operator notes, that are recorded with the data, i.e.
+	// "legal hold case #123".
+	Notes NoteList
+	// Operator is the name of the operator, who makes the notes.
+	Operator string
+)
+
+// NoteList satisfies flag.Value, it collects the values of the flag that
+// can be specified multiple times.
+type NoteList []string
+
+var _ flag.Value = &NoteList{}
+
+func (nl NoteList) String() string {
+	return strings.Join(nl, "; ")
+}
+
+func (nl *NoteList) Set(s string) error {
+	if s = strings.TrimSpace(s); s != "" {
+		*nl = append(*nl, s)
+	}
+	return nil
+}
+
+// SetAnnotationFlags sets the operator note flags on the flagset fs, it is
+// used by the commands that record the data.
+func SetAnnotationFlags(fs *flag.FlagSet) {
+	fs.Var(&Notes, "note", "operator `note` to record with the data, i.e. \"legal hold case #123\",\ncan be specified multiple times")
+	fs.StringVar(&Operator, "operator", osenv.Value("SLACKDUMP_OPERATOR", currentUser()), "operator `name`, recorded with the notes")
+}
+
+// Annotations returns the notes as chunk annotations, made at the current
+// time by the Operator.
+func Annotations() []chunk.Annotation {
+	if len(Notes) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	aa := make([]chunk.Annotation, 0, len(Notes))
+	for _, n := range Notes {
+		aa = append(aa, chunk.Annotation{Time: now, Author: Operator, Text: n})
+	}
+	return aa
+}
+
+func currentUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/rusq/osenv/v2"
 
 	"github.com/rusq/slackdump/v3/auth"
 	"github.com/rusq/slackdump/v3/auth/browser"
+	"github.com/rusq/slackdump/v3/internal/monitor"
 	"github.com/rusq/slackdump/v3/internal/network"
 )
 
@@ -24,24 +26,47 @@ var (
 	LogFile     string
 	JsonHandler bool
 	Verbose     bool
+	RunID       string
 
 	Output     string
 	ConfigFile string
 	Workspace  string
 
-	SlackToken      string
-	SlackCookie     string
-	LoginTimeout    time.Duration = browser.DefLoginTimeout // overall login time.
-	HeadlessTimeout time.Duration = auth.RODHeadlessTimeout // net interaction time.
-	Limits                        = network.DefLimits
-	RODUserAgent    string        // when empty, slackauth uses the default user agent.
+	SlackToken  string
+	SlackCookie string
+	// SlackRefreshToken is the refresh token of the rotating SlackToken.
+	SlackRefreshToken string
+	LoginTimeout      time.Duration = browser.DefLoginTimeout // overall login time.
+	HeadlessTimeout   time.Duration = auth.RODHeadlessTimeout // net interaction time.
+	Limits                          = network.DefLimits
+	RODUserAgent      string        // when empty, slackauth uses the default user agent.
 	// playwright stuff
 	Browser         browser.Browser
 	LegacyBrowser   bool
 	ForceEnterprise bool
-
-	MemberOnly    bool
-	DownloadFiles bool
+	// BrowserProfile is the browser, which profiles the Slack cookies are
+	// read from, or the path to the cookie database.
+	BrowserProfile string
+	// OAuth app flow
+	OAuthClientID     string
+	OAuthClientSecret string
+	OAuthScopes       string // comma-separated bot scopes.
+	OAuthUserScopes   string // comma-separated user scopes.
+	OAuthRedirectURL  string
+
+	MemberOnly bool
+	// IncludeArchived and IncludeShared control whether the archived
+	// channels and the channels shared with the external organisations
+	// (Slack Connect) are exported, when the channels are not specified.
+	IncludeArchived bool
+	IncludeShared   bool
+	DownloadFiles   bool
+	// FileWorkers is the number of concurrent file downloads, if it is zero,
+	// the Workers value of the Limits is used.
+	FileWorkers int
+	// BandwidthLimit is the combined file download rate limit, in bytes per
+	// second, zero means no limit.
+	BandwidthLimit int64
 
 	// Oldest is the default timestamp of the oldest message to fetch, that is
 	// used by the dump and export commands.
@@ -50,11 +75,28 @@ var (
 	// used by the dump and export commands.  It is set to an exact value
 	// for the dump to be consistent.
 	Latest = TimeValue(time.Now())
+	// ChannelSplit is the number of date sub-ranges each channel history is
+	// split into to be fetched concurrently.
+	ChannelSplit int
+	// Pins enables fetching of the pinned items and bookmarks of each
+	// channel.
+	Pins bool
 
 	LocalCacheDir      string
 	UserCacheRetention time.Duration
 	NoUserCache        bool
 	NoChunkCache       bool
+	// ChanInfoCacheRetention is the retention of the channel information
+	// cache, that is shared between the commands.
+	ChanInfoCacheRetention = 60 * time.Minute
+	NoChanInfoCache        bool
+
+	// MonitorAddr is the address to serve the health and metrics endpoints
+	// on, see [Monitor].
+	MonitorAddr string
+	// Monitor is the monitor of the running process, it is nil, if the
+	// monitoring is disabled.
+	Monitor *monitor.Monitor
 
 	Log *slog.Logger = slog.Default()
 	// LoadSecrets is a flag that indicates whether to load secrets from the
@@ -64,6 +106,28 @@ var (
 	Version BuildInfo // version propagated by main package.
 )
 
+// OAuth returns the OAuth configuration of the Slack app, set by the flags.
+func OAuth() auth.OAuthConfig {
+	return auth.OAuthConfig{
+		ClientID:     OAuthClientID,
+		ClientSecret: OAuthClientSecret,
+		Scopes:       splitList(OAuthScopes),
+		UserScopes:   splitList(OAuthUserScopes),
+		RedirectURL:  OAuthRedirectURL,
+	}
+}
+
+// splitList splits the comma-separated list, omitting the empty values.
+func splitList(s string) []string {
+	var ss []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			ss = append(ss, v)
+		}
+	}
+	return ss
+}
+
 type BuildInfo struct {
 	Version string `json:"version"`
 	Commit  string `json:"commit"`
@@ -103,31 +167,42 @@ const (
 
 // SetBaseFlags sets base flags
 func SetBaseFlags(fs *flag.FlagSet, mask FlagMask) {
-	fs.StringVar(&TraceFile, "trace", os.Getenv("TRACE_FILE"), "trace `filename`")
+	fs.StringVar(&TraceFile, "trace", os.Getenv("TRACE_FILE"), "write the runtime trace to `filename`, open it with \"go tool trace\"\nto diagnose stalls")
 	fs.StringVar(&LogFile, "log", os.Getenv("LOG_FILE"), "log `file`, if not specified, messages are printed to STDERR")
 	fs.BoolVar(&JsonHandler, "log-json", osenv.Value("JSON_LOG", false), "log in JSON format")
 	fs.BoolVar(&Verbose, "v", osenv.Value("DEBUG", false), "verbose messages")
+	fs.StringVar(&MonitorAddr, "monitor", os.Getenv("MONITOR_ADDR"), "serve /healthz and Prometheus /metrics on the localhost `address`,\ni.e. 127.0.0.1:9090")
+	fs.StringVar(&RunID, "run-id", os.Getenv("RUN_ID"), "run `ID` that is attached to log messages and output files to correlate\nthe outputs of a multi-step pipeline (default: random UUID)")
 
 	if mask&OmitAuthFlags == 0 {
 		fs.StringVar(&SlackToken, "token", osenv.Secret("SLACK_TOKEN", ""), "Slack `token`")
 		fs.StringVar(&SlackCookie, "cookie", osenv.Secret("SLACK_COOKIE", ""), "d= cookie `value` or a path to a cookie.txt file\n(environment: SLACK_COOKIE)")
+		fs.StringVar(&SlackRefreshToken, "refresh-token", osenv.Secret("SLACK_REFRESH_TOKEN", ""), "refresh `token` of the rotating xoxe.* token, requires -oauth-client-id\nand -oauth-client-secret (environment: SLACK_REFRESH_TOKEN)")
 		fs.Var(&Browser, "browser", "browser to use for legacy EZ-Login 3000 (default: firefox)")
 		fs.DurationVar(&LoginTimeout, "browser-timeout", LoginTimeout, "Browser login `timeout`")
 		fs.DurationVar(&HeadlessTimeout, "autologin-timeout", HeadlessTimeout, "headless autologin `timeout`, without the browser starting time, just the interaction time")
 		fs.BoolVar(&LegacyBrowser, "legacy-browser", false, "use legacy browser automation (playwright) for EZ-Login 3000")
+		fs.StringVar(&BrowserProfile, "browser-profile", "", "log in with the Slack cookies from the installed `browser` profiles:\n\"auto\", \"firefox\", \"chrome\", \"chromium\", \"brave\", \"edge\", or the path\nto the cookie database, i.e. cookies.sqlite")
 		fs.BoolVar(&ForceEnterprise, "enterprise", false, "enable Enteprise module, you need to specify this option if you're using Slack Enterprise Grid")
 		fs.StringVar(&RODUserAgent, "user-agent", "", "override the user agent string for EZ-Login 3000")
 		fs.BoolVar(&LoadSecrets, "load-env", false, "load secrets from the .env, .env.txt or secrets.txt file")
+		fs.StringVar(&OAuthClientID, "oauth-client-id", osenv.Value("SLACK_CLIENT_ID", ""), "client `ID` of the Slack app to authenticate with the OAuth flow,\ninstead of the browser login (environment: SLACK_CLIENT_ID)")
+		fs.StringVar(&OAuthClientSecret, "oauth-client-secret", osenv.Secret("SLACK_CLIENT_SECRET", ""), "client `secret` of the Slack app (environment: SLACK_CLIENT_SECRET)")
+		fs.StringVar(&OAuthScopes, "oauth-scopes", "", "comma-separated bot token `scopes` to request in the OAuth flow")
+		fs.StringVar(&OAuthUserScopes, "oauth-user-scopes", "", "comma-separated user token `scopes` to request in the OAuth flow\n(default: the scopes to read the conversations, users and files)")
+		fs.StringVar(&OAuthRedirectURL, "oauth-redirect", auth.DefOAuthRedirectURL, "OAuth redirect `URL` registered in the Slack app, slackdump listens\non its host and port for the callback")
 	}
 	if mask&OmitDownloadFlag == 0 {
 		fs.BoolVar(&DownloadFiles, "files", true, "enables file attachments (to disable, specify: -files=false)")
+		fs.IntVar(&FileWorkers, "file-workers", osenv.Value("FILE_WORKERS", 0), "number of concurrent file download `workers` (default: the workers value\nof the API limits, 4)")
+		fs.Int64Var(&BandwidthLimit, "bandwidth-limit", osenv.Value("BANDWIDTH_LIMIT", int64(0)), "limit the combined file download rate to `bytes` per second, i.e.\n1048576 for 1 MiB/s (default: no limit)")
 	}
 	if mask&OmitConfigFlag == 0 {
 		fs.StringVar(&ConfigFile, "api-config", "", "configuration `file` with Slack API limits overrides.\nYou can generate one with default values with 'slackdump config new`")
 	}
 	if mask&OmitOutputFlag == 0 {
 		base := fmt.Sprintf("slackdump_%s.zip", time.Now().Format(filenameLayout))
-		fs.StringVar(&Output, "o", osenv.Value("BASE_LOC", base), "a `location` (a directory or a ZIP file) on the local disk to save\ndownloaded files to.")
+		fs.StringVar(&Output, "o", osenv.Value("BASE_LOC", base), "a `location` (a directory or a ZIP file on the local disk, or\ns3://, sftp://, webdav:// or webdavs:// URL) to save downloaded files to.")
 	}
 	if mask&OmitCacheDir == 0 {
 		fs.StringVar(&LocalCacheDir, "cache-dir", osenv.Value("CACHE_DIR", CacheDir()), "cache `directory` location\n")
@@ -143,6 +218,8 @@ func SetBaseFlags(fs *flag.FlagSet, mask FlagMask) {
 	if mask&OmitUserCacheFlag == 0 {
 		fs.BoolVar(&NoUserCache, "no-user-cache", false, "disable user cache (file cache)")
 		fs.DurationVar(&UserCacheRetention, "user-cache-retention", 60*time.Minute, "user cache retention duration.  After this time, the cache is considered stale and will be refreshed.")
+		fs.BoolVar(&NoChanInfoCache, "no-chan-info-cache", false, "disable channel information cache (file cache)")
+		fs.DurationVar(&ChanInfoCacheRetention, "chan-info-cache-retention", ChanInfoCacheRetention, "channel information (topic, purpose, member count) cache retention duration.")
 	}
 	if mask&OmitChunkCacheFlag == 0 {
 		// ChunkCache can decrease the time of conversion for the archives
@@ -152,8 +229,17 @@ func SetBaseFlags(fs *flag.FlagSet, mask FlagMask) {
 	if mask&OmitTimeframeFlag == 0 {
 		fs.Var(&Oldest, "time-from", "timestamp of the oldest message to fetch (UTC timezone)")
 		fs.Var(&Latest, "time-to", "timestamp of the newest message to fetch (UTC timezone)")
+		fs.IntVar(&ChannelSplit, "channel-split", osenv.Value("CHANNEL_SPLIT", 1), "split the history of each channel into `n` date ranges, that are\nfetched concurrently, speeds up the channels with a long history")
+		fs.BoolVar(&Pins, "pins", osenv.Value("PINS", false), "fetch the pinned items and bookmarks of each channel (slower)")
 	}
 	if mask&OmitMemberOnlyFlag == 0 {
 		fs.BoolVar(&MemberOnly, "member-only", false, "export only channels, which the current user belongs to (if no channels are specified)")
 	}
 }
+
+// SetChannelFilterFlags sets the flags, that control whether the archived
+// and the shared channels are included, when the channels are enumerated.
+func SetChannelFilterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&IncludeArchived, "include-archived", osenv.Value("INCLUDE_ARCHIVED", true), "include the archived channels (if no channels are specified),\nto exclude, specify: -include-archived=false")
+	fs.BoolVar(&IncludeShared, "include-shared", osenv.Value("INCLUDE_SHARED", true), "include the channels shared with the external organisations (Slack\nConnect), if no channels are specified, to exclude, specify:\n-include-shared=false")
+}
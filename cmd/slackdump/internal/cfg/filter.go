@@ -0,0 +1,64 @@
+package cfg
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/rusq/slackdump/v3/internal/filter"
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+// Filters are the message filters, the messages matching any of them are not
+// recorded.
+var Filters FilterList
+
+// FilterList satisfies flag.Value, it collects the message filters, that
+// can be specified multiple times.  The filters are validated when set.
+type FilterList struct {
+	specs []string
+	preds []filter.Predicate
+}
+
+var _ flag.Value = &FilterList{}
+
+func (fl *FilterList) String() string {
+	return strings.Join(fl.specs, ", ")
+}
+
+func (fl *FilterList) Set(s string) error {
+	p, err := filter.Parse(s)
+	if err != nil {
+		return err
+	}
+	fl.specs = append(fl.specs, s)
+	fl.preds = append(fl.preds, p)
+	return nil
+}
+
+// SetFilterFlags sets the message filter flags on the flagset fs, it is used
+// by the commands that fetch the conversations.
+func SetFilterFlags(fs *flag.FlagSet) {
+	fs.Var(&Filters, "filter", "drop the messages matching the `filter`: bots, joins, system,\nsubtype:<name>, user:<ID> or text:<regexp>, can be specified multiple\ntimes")
+}
+
+// MessageFilter returns the conversation processor middleware, that drops
+// the messages matching the Filters, or nil, if there are no filters.
+func MessageFilter() processor.Middleware {
+	if len(Filters.preds) == 0 {
+		return nil
+	}
+	return filter.Middleware(Filters.preds...)
+}
+
+// Middlewares returns the conversation processor middlewares, configured by
+// the flags: the message filter, followed by the redactor, so that the
+// dropped messages are not redacted needlessly.
+func Middlewares() []processor.Middleware {
+	var mw []processor.Middleware
+	for _, m := range []processor.Middleware{MessageFilter(), MessageRedactor()} {
+		if m != nil {
+			mw = append(mw, m)
+		}
+	}
+	return mw
+}
@@ -0,0 +1,51 @@
+package cfg
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/rusq/slackdump/v3/internal/redact"
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+// Redactions are the redaction rules, that are applied to the messages
+// before they are recorded.
+var Redactions RedactList
+
+// RedactList satisfies flag.Value, it collects the redaction rules, that
+// can be specified multiple times.  The rules are validated when set.
+type RedactList struct {
+	specs []string
+	rules []redact.Rule
+}
+
+var _ flag.Value = &RedactList{}
+
+func (rl *RedactList) String() string {
+	return strings.Join(rl.specs, ", ")
+}
+
+func (rl *RedactList) Set(s string) error {
+	r, err := redact.Parse(s)
+	if err != nil {
+		return err
+	}
+	rl.specs = append(rl.specs, s)
+	rl.rules = append(rl.rules, r)
+	return nil
+}
+
+// SetRedactFlags sets the redaction flags on the flagset fs, it is used by
+// the commands that fetch the conversations.
+func SetRedactFlags(fs *flag.FlagSet) {
+	fs.Var(&Redactions, "redact", "redact the `rule` matches in the message text and attachments: cards,\ntokens, emails, re:<regexp> or <name>=<regexp>, can be specified\nmultiple times")
+}
+
+// MessageRedactor returns the conversation processor middleware, that
+// redacts the messages with the Redactions, or nil, if there are none.
+func MessageRedactor() processor.Middleware {
+	if len(Redactions.rules) == 0 {
+		return nil
+	}
+	return redact.Middleware(Redactions.rules...)
+}
@@ -0,0 +1,46 @@
+package cfg
+
+import (
+	"flag"
+	"time"
+
+	"github.com/rusq/slackdump/v3/internal/retention"
+)
+
+var (
+	// RetentionAware enables the scheduling of the conversations by the risk
+	// of deletion by the message retention policy of the workspace.
+	RetentionAware bool
+	// Retention is the message retention of the workspace, if it is set, it
+	// is used instead of the workspace settings, and implies RetentionAware.
+	Retention RetentionValue
+)
+
+// RetentionValue satisfies flag.Value, it is the retention duration, that
+// accepts the number of days, i.e. "90d".
+type RetentionValue time.Duration
+
+var _ flag.Value = new(RetentionValue)
+
+func (v RetentionValue) String() string {
+	if v == 0 {
+		return ""
+	}
+	return retention.FormatDuration(time.Duration(v))
+}
+
+func (v *RetentionValue) Set(s string) error {
+	d, err := retention.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*v = RetentionValue(d)
+	return nil
+}
+
+// SetRetentionFlags sets the retention-aware scheduling flags on the flagset
+// fs, it is used by the commands that fetch the conversations in bulk.
+func SetRetentionFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&RetentionAware, "retention-aware", false, "fetch the conversations at risk of deletion by the message retention\npolicy of the workspace first, and report the ones that might have lost\nthe messages during the run")
+	fs.Var(&Retention, "retention", "message retention `duration` of the workspace, i.e. 90d, if it can't be\ndetermined from the workspace settings, implies -retention-aware")
+}
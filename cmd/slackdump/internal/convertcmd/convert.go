@@ -3,14 +3,23 @@ package convertcmd
 import (
 	"context"
 	"errors"
+	"io"
+	"os"
 	"time"
 
 	"github.com/rusq/fsadapter"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/bootstrap"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
 	"github.com/rusq/slackdump/v3/internal/chunk"
 	"github.com/rusq/slackdump/v3/internal/chunk/transform/fileproc"
 	"github.com/rusq/slackdump/v3/internal/convert"
+	"github.com/rusq/slackdump/v3/internal/osext"
+	"github.com/rusq/slackdump/v3/internal/remotefs"
+	"github.com/rusq/slackdump/v3/internal/reproducible"
+	"github.com/rusq/slackdump/v3/internal/source"
+	"github.com/rusq/slackdump/v3/internal/sqlite"
 )
 
 var CmdConvert = &base.Command{
@@ -24,6 +33,86 @@ Convert slackdump Chunks (output of "record") to various formats.
 
 By default it converts a directory with chunks to an archive or directory
 in Slack Export format.
+
+To convert chunks into the SQLite database, use "-output sqlite" flag, the
+database file name is set with "-o" flag, i.e.:
+
+    slackdump convert -output sqlite -o archive.db <chunk_dir>
+
+If the database exists, the data is added to it.  The SQLite output is not
+available on some platforms, i.e. NetBSD.
+
+## Converting Recordings
+
+The single file recording, produced by "slackdump tools record stream", can
+be converted to the Slack Export format without network access:
+
+    slackdump convert -o export.zip recording.jsonl
+
+The recording format is selected automatically, if the source is a file, or it
+can be set explicitly with "-input record" flag.  Use "-" as the source to
+read the recording from the standard input.  Recordings do not contain file
+attachments, so they are not included in the export.  If the recording has
+no users, the user IDs are used as user names.
+
+## Converting to Mattermost
+
+To migrate the workspace to Mattermost, convert the archive (output of
+"slackdump archive") to the Mattermost bulk import format:
+
+    slackdump convert -output mattermost -o mattermost_import.zip <chunk_dir>
+
+The Slack export and the dump can be converted as well, with "-input export"
+or "-input dump" flag respectively.
+
+The ZIP file contains the "mattermost_import.jsonl" file with the team,
+channels, users and posts, and the attachments in the "data" directory, if
+the files were downloaded with the archive (to skip them, specify
+"-files=false").  Upload and process it with:
+
+    mmctl import upload mattermost_import.zip
+    mmctl import process <uploaded file name>
+
+The team name is derived from the workspace name.  Users without an email
+address get a placeholder address "<username>@localhost", and messages with
+no user (i.e. bot messages) are skipped, as Mattermost requires the post
+author to exist.
+
+## Resolving Missing Users
+
+Messages may reference users that are not in the archive, i.e. the ones
+that left the workspace, and channels that were not archived.  To look them
+up with the API when converting to the Slack Export format, specify the
+"-hydrate" flag:
+
+    slackdump convert -hydrate -o export.zip <chunk_dir>
+
+It uses the credentials of the current workspace, if they are available,
+and does not ask to log in.  The results are cached in the "hydrate.json"
+file in the chunk directory, and the subsequent conversions, and the
+viewer, use them without the network access.
+
+## Reproducible Output
+
+To get the byte-identical output for the same input, i.e. to diff the
+archives or to record their hashes for the chain of custody, specify the
+"-deterministic" flag:
+
+    slackdump convert -deterministic -o export.zip <chunk_dir>
+
+The entries of the ZIP file are sorted by name and have the fixed
+modification time, 1980-01-01 00:00:00 UTC, or the time set by the
+SOURCE_DATE_EPOCH environment variable.  It is not supported for the SQLite
+output, and the "-hydrate" flag may change the output, if it looks up the
+missing users with the API.
+
+## Integrity Check
+
+To check that the chunk files were not truncated or edited before the
+conversion, specify the "-verify" flag.  It requires an extra pass over all
+chunk files, so it is off by default.  The conversion does not start, if any
+of the files fails the check, use "slackdump tools chunk audit" to see all
+breaks.  Recordings are not verified.
 `,
 	CustomFlags: false,
 	FlagMask:    cfg.OmitAll & ^cfg.OmitDownloadFlag &^ cfg.OmitOutputFlag,
@@ -31,9 +120,12 @@ in Slack Export format.
 }
 
 type tparams struct {
-	storageType fileproc.StorageType
-	inputfmt    datafmt
-	outputfmt   datafmt
+	storageType   fileproc.StorageType
+	inputfmt      datafmt
+	outputfmt     datafmt
+	hydrate       bool
+	deterministic bool
+	verify        bool
 }
 
 var params = tparams{
@@ -46,6 +138,9 @@ func init() {
 	CmdConvert.Flag.Var(&params.storageType, "storage", "storage type")
 	CmdConvert.Flag.Var(&params.inputfmt, "input", "input format")
 	CmdConvert.Flag.Var(&params.outputfmt, "output", "output format")
+	CmdConvert.Flag.BoolVar(&params.hydrate, "hydrate", false, "look up the users and channels missing in the archive with the API")
+	CmdConvert.Flag.BoolVar(&params.deterministic, "deterministic", false, "produce the byte-identical output for the same input: sorted ZIP entries\nwith fixed modification times")
+	CmdConvert.Flag.BoolVar(&params.verify, "verify", false, "verify the integrity of the chunk files before the conversion")
 }
 
 func runConvert(ctx context.Context, cmd *base.Command, args []string) error {
@@ -53,18 +148,29 @@ func runConvert(ctx context.Context, cmd *base.Command, args []string) error {
 		base.SetExitStatus(base.SInvalidParameters)
 		return errors.New("source and destination are required")
 	}
-	fn, exist := converter(params.inputfmt, params.outputfmt)
+	inputfmt := params.inputfmt
+	if inputfmt == Fchunk && isFile(args[0]) {
+		inputfmt = Frecord
+	}
+	fn, exist := converter(inputfmt, params.outputfmt)
 	if !exist {
 		base.SetExitStatus(base.SInvalidParameters)
 		return errors.New("unsupported conversion type")
 	}
+	if params.deterministic && params.outputfmt == Fsqlite {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("-deterministic is not supported for the sqlite output")
+	}
 
 	lg := cfg.Log
-	lg.InfoContext(ctx, "converting", "input_format", params.inputfmt, "source", args[0], "output_format", params.outputfmt, "output", cfg.Output)
+	lg.InfoContext(ctx, "converting", "input_format", inputfmt, "source", args[0], "output_format", params.outputfmt, "output", cfg.Output)
 
 	cflg := convertflags{
-		withFiles: cfg.DownloadFiles,
-		stt:       params.storageType,
+		withFiles:     cfg.DownloadFiles,
+		stt:           params.storageType,
+		hydrate:       params.hydrate,
+		deterministic: params.deterministic,
+		verify:        params.verify,
 	}
 	start := time.Now()
 	if err := fn(ctx, args[0], cfg.Output, cflg); err != nil {
@@ -91,22 +197,68 @@ type convertFunc func(ctx context.Context, input, output string, cflg convertfla
 // ..................input.......output..............
 var converters = map[datafmt]map[datafmt]convertFunc{
 	Fchunk: {
-		Fexport: chunk2export,
+		Fexport:     chunk2export,
+		Fsqlite:     chunk2sqlite,
+		Fmattermost: source2mattermost,
+	},
+	Fexport: {
+		Fmattermost: source2mattermost,
+	},
+	Fdump: {
+		Fmattermost: source2mattermost,
+	},
+	Frecord: {
+		Fexport: record2export,
 	},
 }
 
+// isFile returns true if the source is the standard input or a regular file.
+func isFile(src string) bool {
+	if src == "-" {
+		return true
+	}
+	fi, err := os.Stat(src)
+	return err == nil && fi.Mode().IsRegular()
+}
+
 type convertflags struct {
-	withFiles bool
-	stt       fileproc.StorageType
+	withFiles     bool
+	stt           fileproc.StorageType
+	hydrate       bool
+	deterministic bool
+	verify        bool
 }
 
-func chunk2export(ctx context.Context, src, trg string, cflg convertflags) error {
+// openDir opens the chunk directory src, and verifies it, if requested.
+func (cflg convertflags) openDir(src string) (*chunk.Directory, error) {
 	cd, err := chunk.OpenDir(src)
+	if err != nil {
+		return nil, err
+	}
+	if cflg.verify {
+		if err := cd.Verify(); err != nil {
+			cd.Close()
+			return nil, err
+		}
+	}
+	return cd, nil
+}
+
+// create creates the output filesystem adapter for the location trg.
+func (cflg convertflags) create(trg string) (fsadapter.FSCloser, error) {
+	if cflg.deterministic {
+		return reproducible.New(trg)
+	}
+	return remotefs.New(trg)
+}
+
+func chunk2export(ctx context.Context, src, trg string, cflg convertflags) error {
+	cd, err := cflg.openDir(src)
 	if err != nil {
 		return err
 	}
 	defer cd.Close()
-	fsa, err := fsadapter.New(trg)
+	fsa, err := cflg.create(trg)
 	if err != nil {
 		return err
 	}
@@ -117,16 +269,110 @@ func chunk2export(ctx context.Context, src, trg string, cflg convertflags) error
 		return errors.New("unknown storage type")
 	}
 
+	h := bootstrap.Hydrator(ctx, src, cflg.hydrate)
 	cvt := convert.NewChunkToExport(
 		cd,
 		fsa,
 		convert.WithIncludeFiles(cflg.withFiles),
 		convert.WithTrgFileLoc(sttFn),
 		convert.WithLogger(cfg.Log),
+		convert.WithHydrator(h),
 	)
 	if err := cvt.Convert(ctx); err != nil {
 		return err
 	}
+	bootstrap.SaveHydrator(ctx, src, h)
 
 	return nil
 }
+
+func chunk2sqlite(ctx context.Context, src, trg string, cflg convertflags) error {
+	cd, err := cflg.openDir(src)
+	if err != nil {
+		return err
+	}
+	defer cd.Close()
+	db, err := sqlite.Open(ctx, trg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := convert.NewChunkToSQLite(cd, db, cfg.Log).Convert(ctx); err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+// source2mattermost converts any source, that [source.Load] recognises, to
+// the Mattermost bulk import.
+func source2mattermost(ctx context.Context, src, trg string, cflg convertflags) error {
+	s, err := source.Load(ctx, src)
+	if err != nil {
+		return err
+	}
+	if cl, ok := s.(io.Closer); ok {
+		defer cl.Close()
+	}
+	fsa, err := cflg.create(trg)
+	if err != nil {
+		return err
+	}
+	defer fsa.Close()
+
+	cvt := convert.NewSourceToMattermost(
+		s,
+		fsa,
+		convert.MattermostIncludeFiles(cflg.withFiles),
+		convert.MattermostLogger(cfg.Log),
+	)
+	if err := cvt.Convert(ctx); err != nil {
+		return err
+	}
+	return fsa.Close()
+}
+
+func record2export(ctx context.Context, src, trg string, cflg convertflags) error {
+	f, err := openRecording(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := osext.CompressionOf(src).NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	fsa, err := cflg.create(trg)
+	if err != nil {
+		return err
+	}
+	defer fsa.Close()
+
+	sttFn, ok := fileproc.StorageTypeFuncs[cflg.stt]
+	if !ok {
+		return errors.New("unknown storage type")
+	}
+	if cflg.withFiles {
+		cfg.Log.InfoContext(ctx, "recordings have no files, files will not be included")
+	}
+
+	cvt := convert.NewRecordingToExport(
+		r,
+		fsa,
+		convert.WithTrgFileLoc(sttFn),
+		convert.WithLogger(cfg.Log),
+	)
+	if err := cvt.Convert(ctx); err != nil {
+		return err
+	}
+	return fsa.Close()
+}
+
+// openRecording opens the recording file, "-" is the standard input.
+func openRecording(src string) (io.ReadCloser, error) {
+	if src == "-" {
+		return os.Stdin, nil
+	}
+	return os.Open(src)
+}
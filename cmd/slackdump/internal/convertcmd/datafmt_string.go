@@ -11,11 +11,14 @@ func _() {
 	_ = x[Fdump-0]
 	_ = x[Fexport-1]
 	_ = x[Fchunk-2]
+	_ = x[Fsqlite-3]
+	_ = x[Frecord-4]
+	_ = x[Fmattermost-5]
 }
 
-const _datafmt_name = "dumpexportchunk"
+const _datafmt_name = "dumpexportchunksqliterecordmattermost"
 
-var _datafmt_index = [...]uint8{0, 4, 10, 15}
+var _datafmt_index = [...]uint8{0, 4, 10, 15, 21, 27, 37}
 
 func (i datafmt) String() string {
 	if i >= datafmt(len(_datafmt_index)-1) {
@@ -14,6 +14,9 @@ const (
 	Fdump datafmt = iota
 	Fexport
 	Fchunk
+	Fsqlite
+	Frecord
+	Fmattermost
 )
 
 func (e *datafmt) Set(v string) error {
@@ -0,0 +1,218 @@
+package diag
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rusq/fsadapter"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/chunk/obfuscate"
+	"github.com/rusq/slackdump/v3/internal/osext"
+)
+
+// cmdAnonymize is the command to anonymize the chunk files and exports.
+var cmdAnonymize = &base.Command{
+	UsageLine: "slackdump tools anonymize [flags] <input> <output>",
+	Short:     "anonymize a chunk file, archive or export for sharing",
+	Long: `
+# Anonymize tool
+
+Anonymize tool rewrites a chunk file, an archive directory (the output of
+"slackdump archive"), or a Slack export directory or ZIP file, replacing the
+user, channel and file IDs, names, emails and the message text, while
+preserving the structure of the data, so that the result can be shared with
+the developers or researchers without leaking the personal data.
+
+The IDs are replaced with the salted hashes, so the references between the
+messages, users and channels remain consistent within the output.  The
+text replacement is set with the -text flag:
+
+- "fake" (default) replaces the names and emails with the generated ones,
+  that are the same for each user, and the message text with the lorem
+  ipsum words, keeping the word count and the user and channel mentions;
+- "hash" replaces the text with the salted hash, the same text gives the
+  same hash;
+- "redact" replaces the text with the placeholder;
+- "random" replaces the text with the random characters of the similar
+  length, same as the "obfuscate" tool does.
+
+The output is reproducible for the same -seed value.  The output type
+follows the input: a file for the chunk file (use "-" for the standard
+output), a directory for the archive, and a directory or ZIP file for the
+export.  File attachments are not copied.
+`,
+	FlagMask:    cfg.OmitAll,
+	CustomFlags: true,
+	PrintFlags:  true,
+}
+
+var anonParams = struct {
+	overwrite bool
+	seed      int64
+	text      obfuscate.TextMode
+}{
+	text: obfuscate.TMFake,
+}
+
+func init() {
+	cmdAnonymize.Run = runAnonymize
+
+	cmdAnonymize.Flag.BoolVar(&anonParams.overwrite, "f", false, "force overwrite")
+	cmdAnonymize.Flag.Int64Var(&anonParams.seed, "seed", time.Now().UnixNano(), "seed for the random number generator")
+	cmdAnonymize.Flag.Var(&anonParams.text, "text", "text replacement `mode`: fake, hash, redact or random")
+}
+
+func runAnonymize(ctx context.Context, cmd *base.Command, args []string) error {
+	if err := cmd.Flag.Parse(args); err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	if cmd.Flag.NArg() != 2 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("expected input and output")
+	}
+	input, output := cmd.Flag.Arg(0), cmd.Flag.Arg(1)
+	if filepath.Clean(input) == filepath.Clean(output) {
+		base.SetExitStatus(base.SInvalidParameters)
+		return ErrObfSame
+	}
+	opts := []obfuscate.Option{
+		obfuscate.WithSeed(anonParams.seed),
+		obfuscate.WithTextMode(anonParams.text),
+	}
+
+	var err error
+	switch inType := objtype(input); {
+	case inType == otDir && isExportDir(input):
+		err = anonExport(ctx, os.DirFS(input), output, opts)
+	case inType == otDir:
+		if err := prepareOutDir(output); err != nil {
+			return err
+		}
+		err = obfuscate.DoDir(ctx, input, output, opts...)
+	case inType == otFile && strings.EqualFold(filepath.Ext(input), ".zip"):
+		zr, zerr := zip.OpenReader(input)
+		if zerr != nil {
+			base.SetExitStatus(base.SUserError)
+			return zerr
+		}
+		defer zr.Close()
+		err = anonExport(ctx, zr, output, opts)
+	case inType == otFile || inType == otTerm:
+		err = anonFile(ctx, input, output, opts)
+	default:
+		base.SetExitStatus(base.SInvalidParameters)
+		return fmt.Errorf("input %s is invalid", input)
+	}
+	if err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	return nil
+}
+
+// isExportDir returns true if the directory contains the Slack export.
+func isExportDir(dir string) bool {
+	for _, name := range []string{"channels.json", "users.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOutput checks that the output does not exist, or can be overwritten.
+func checkOutput(output string) error {
+	if isTerm(output) || objtype(output) == otNotExist {
+		return nil
+	}
+	if !anonParams.overwrite {
+		base.SetExitStatus(base.SUserError)
+		return ErrObfTargetExist
+	}
+	return nil
+}
+
+func prepareOutDir(output string) error {
+	switch objtype(output) {
+	case otFile, otTerm:
+		base.SetExitStatus(base.SInvalidParameters)
+		return fmt.Errorf("output %s must be a directory", output)
+	case otDir:
+		if err := checkOutput(output); err != nil {
+			return err
+		}
+		if err := os.RemoveAll(output); err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			return err
+		}
+	}
+	return nil
+}
+
+func anonExport(ctx context.Context, fsys fs.FS, output string, opts []obfuscate.Option) error {
+	if !strings.EqualFold(filepath.Ext(output), ".zip") {
+		if err := prepareOutDir(output); err != nil {
+			return err
+		}
+	} else if err := checkOutput(output); err != nil {
+		return err
+	}
+	fsa, err := fsadapter.New(output)
+	if err != nil {
+		return err
+	}
+	defer fsa.Close()
+	if err := obfuscate.DoExport(ctx, fsys, fsa, opts...); err != nil {
+		return err
+	}
+	return fsa.Close()
+}
+
+// anonFile anonymizes the single chunk file, the file name ending with ".gz"
+// or ".zst" is treated as the compressed file, both for input and output.
+func anonFile(ctx context.Context, input, output string, opts []obfuscate.Option) error {
+	if objtype(output) == otDir {
+		base.SetExitStatus(base.SInvalidParameters)
+		return fmt.Errorf("output %s must be a file", output)
+	}
+	if err := checkOutput(output); err != nil {
+		return err
+	}
+	var in io.Reader = os.Stdin
+	if !isTerm(input) {
+		f, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r, err := osext.CompressionOf(input).NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		in = r
+	}
+	if isTerm(output) {
+		return obfuscate.Do(ctx, os.Stdout, in, opts...)
+	}
+	out, err := osext.CreateFile(output)
+	if err != nil {
+		return err
+	}
+	if err := obfuscate.Do(ctx, out, in, opts...); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
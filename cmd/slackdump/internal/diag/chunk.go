@@ -0,0 +1,99 @@
+package diag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/osext"
+)
+
+var cmdChunk = &base.Command{
+	UsageLine:  "slackdump tools chunk",
+	Short:      "chunk file utilities",
+	Commands:   []*base.Command{cmdChunkAudit, cmdChunkMap, cmdChunkNotes},
+	HideWizard: true,
+}
+
+var cmdChunkAudit = &base.Command{
+	UsageLine: "slackdump tools chunk audit [flags] <chunk_file_or_directory>",
+	Short:     "verify the integrity of chunk files",
+	Long: `
+# Chunk audit tool
+
+Audit tool verifies the integrity of the chunk files.  Each chunk written by
+slackdump has a sequence number and the hash of the previous chunk, and each
+recording ends with a trailer chunk.  The tool reports the breaks in the
+chain, that indicate that the file was truncated, or edited manually.
+
+It accepts either a single chunk file (plain, or compressed, if the file
+name ends with ".gz" or ".zst"), or an archive directory, in which case all chunk
+files in the directory are verified.
+
+Chunks written by older versions of slackdump do not have the sequence
+numbers, and are reported as unchained.
+`,
+	FlagMask:   cfg.OmitAll,
+	PrintFlags: true,
+}
+
+func init() {
+	cmdChunkAudit.Run = runChunkAudit
+}
+
+func runChunkAudit(ctx context.Context, cmd *base.Command, args []string) error {
+	if len(args) != 1 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("expected exactly one chunk file or directory")
+	}
+	files, err := chunkFiles(args[0])
+	if err != nil {
+		base.SetExitStatus(base.SUserError)
+		return err
+	}
+	var nBroken int
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		r, err := auditFile(f)
+		if err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			return fmt.Errorf("%s: %w", f, err)
+		}
+		status := "OK"
+		if !r.OK() {
+			status = "BROKEN"
+			nBroken++
+		}
+		fmt.Printf("%s: %s: %d chunks, %d recordings, %d unchained\n", f, status, r.Chunks, r.Segments, r.Unchained)
+		for _, b := range r.Breaks {
+			fmt.Printf("\t%s\n", b)
+		}
+	}
+	if nBroken > 0 {
+		base.SetExitStatus(base.SApplicationError)
+		return fmt.Errorf("%w: %d of %d files", chunk.ErrIntegrity, nBroken, len(files))
+	}
+	return nil
+}
+
+// auditFile verifies the chunk file.  If the file name ends with ".gz" or
+// ".zst", it is decompressed on the fly.
+func auditFile(filename string) (*chunk.AuditReport, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := osext.CompressionOf(filename).NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return chunk.Audit(r)
+}
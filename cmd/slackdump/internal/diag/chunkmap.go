@@ -0,0 +1,239 @@
+package diag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/itchyny/gojq"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/osext"
+)
+
+var cmdChunkMap = &base.Command{
+	UsageLine: "slackdump tools chunk map [flags] -expr <expression> <input> [<output>]",
+	Short:     "transform chunks with a jq expression",
+	Long: `
+# Chunk map tool
+
+Chunk map tool applies the jq expression to each chunk of the chunk
+file, and writes the resulting chunks into the new chunk file.  It allows to
+perform custom surgery on the recording, such as dropping fields or
+messages, or renaming channels, without writing Go code.
+
+The expression receives each chunk as the input, and may produce zero, one
+or more chunks.  If it produces no output, i.e. with ` + "`select`" + `, the chunk is
+dropped.  Each output must be an object, that is a valid chunk.  Trailer
+chunks are not passed to the expression, the output file gets a new trailer
+with the statistics of the transformed messages.
+
+Input is a chunk file (plain, or compressed, if the file name ends with
+".gz" or ".zst"), or "-" for the standard input.  If output is omitted or
+"-", chunks are written to the standard output, if it ends with ".gz" or
+".zst", the output is compressed.
+
+## Chunk fields
+
+The most useful chunk fields are:
+
+- "t": chunk type, 0 — messages, 1 — thread messages, 2 — files, 3 —
+  users, 4 — channels, 5 — channel information;
+- "id": channel ID;
+- "ci": channel information object, where "name" is the channel name;
+- "m": messages;
+- "p": thread parent message;
+- "f": files;
+- "u": users;
+- "ch": channels.
+
+## Expressions
+
+The expression is evaluated with gojq, the Go implementation of jq, and
+supports the full jq language, including variables, function definitions
+and string interpolation.  See https://github.com/itchyny/gojq for the
+differences from jq.  The "input" and "inputs" functions are not
+available, as each chunk is processed separately.
+
+## Examples
+
+Drop the message blocks:
+
+	slackdump tools chunk map -expr 'del(.m[]?.blocks)' C123.json.gz C123_new.json.gz
+
+Rename the channel:
+
+	slackdump tools chunk map -expr 'if .ci.name == "old" then .ci.name = "new" else . end' channels.json.gz out.json.gz
+
+Drop the bot messages:
+
+	slackdump tools chunk map -expr 'del(.m[]? | select(.bot_id))' C123.json.gz out.json.gz
+
+Drop the user chunks:
+
+	slackdump tools chunk map -expr 'select(.t != 3)' recording.jsonl out.jsonl
+`,
+	FlagMask:    cfg.OmitAll,
+	PrintFlags:  true,
+	CustomFlags: true,
+}
+
+var chunkMapParams struct {
+	expr      string
+	overwrite bool
+}
+
+func init() {
+	cmdChunkMap.Run = runChunkMap
+	cmdChunkMap.Flag.StringVar(&chunkMapParams.expr, "expr", "", "jq `expression` to apply to each chunk")
+	cmdChunkMap.Flag.BoolVar(&chunkMapParams.overwrite, "f", false, "force overwrite of the output file")
+}
+
+func runChunkMap(ctx context.Context, cmd *base.Command, args []string) error {
+	if err := cmd.Flag.Parse(args); err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	if chunkMapParams.expr == "" {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("expression is required")
+	}
+	if cmd.Flag.NArg() < 1 || cmd.Flag.NArg() > 2 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("expected input and optional output file")
+	}
+	input, output := cmd.Flag.Arg(0), cmd.Flag.Arg(1)
+	if !isTerm(output) {
+		if input == output {
+			base.SetExitStatus(base.SInvalidParameters)
+			return ErrObfSame
+		}
+		if _, err := os.Stat(output); err == nil && !chunkMapParams.overwrite {
+			base.SetExitStatus(base.SUserError)
+			return ErrObfTargetExist
+		}
+	}
+	q, err := gojq.Parse(chunkMapParams.expr)
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	code, err := gojq.Compile(q)
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+
+	r, err := openChunkInput(input)
+	if err != nil {
+		base.SetExitStatus(base.SUserError)
+		return err
+	}
+	defer r.Close()
+	w, err := createChunkOutput(output)
+	if err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	if err := chunk.Rewrite(ctx, w, r, mapChunkFunc(code)); err != nil {
+		w.Close()
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	if err := w.Close(); err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	return nil
+}
+
+// mapChunkFunc returns the rewrite function that applies the compiled jq
+// expression to the chunk.
+func mapChunkFunc(code *gojq.Code) chunk.RewriteFunc {
+	return func(c *chunk.Chunk) ([]chunk.Chunk, error) {
+		data, err := json.Marshal(c)
+		if err != nil {
+			return nil, err
+		}
+		// numbers are decoded as json.Number to keep the precision of the
+		// large integers.
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		var out []chunk.Chunk
+		iter := code.Run(v)
+		for {
+			res, ok := iter.Next()
+			if !ok {
+				break
+			}
+			if err, ok := res.(error); ok {
+				return nil, err
+			}
+			b, err := json.Marshal(res)
+			if err != nil {
+				return nil, err
+			}
+			var oc chunk.Chunk
+			if err := json.Unmarshal(b, &oc); err != nil {
+				return nil, fmt.Errorf("output %d is not a valid chunk: %w", len(out)+1, err)
+			}
+			out = append(out, oc)
+		}
+		return out, nil
+	}
+}
+
+// openChunkInput opens the chunk file, or the standard input, if the name is
+// "-".  If the file name ends with ".gz" or ".zst", it is decompressed on
+// the fly.
+func openChunkInput(name string) (io.ReadCloser, error) {
+	if isTerm(name) {
+		return io.NopCloser(os.Stdin), nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	r, err := osext.CompressionOf(name).NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &fileReadCloser{ReadCloser: r, f: f}, nil
+}
+
+// fileReadCloser closes the decompressor and the underlying file.
+type fileReadCloser struct {
+	io.ReadCloser
+	f *os.File
+}
+
+func (r *fileReadCloser) Close() error {
+	return errors.Join(r.ReadCloser.Close(), r.f.Close())
+}
+
+// createChunkOutput creates the output file, or returns the standard output,
+// if the name is empty or "-".  If the file name ends with ".gz" or ".zst",
+// the output is compressed.
+func createChunkOutput(name string) (io.WriteCloser, error) {
+	if isTerm(name) {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	return osext.CreateFile(name)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,133 @@
+package diag
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/itchyny/gojq"
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+)
+
+func Test_mapChunkFunc(t *testing.T) {
+	msgs := &chunk.Chunk{
+		Type:      chunk.CMessages,
+		ChannelID: "C1",
+		Messages: []slack.Message{
+			{Msg: slack.Msg{Timestamp: "1700000001.000000", Text: "hello", BotID: "B1"}},
+			{Msg: slack.Msg{Timestamp: "1700000002.000000", Text: "world"}},
+		},
+	}
+	tests := []struct {
+		name    string
+		expr    string
+		in      *chunk.Chunk
+		wantLen int
+		check   func(t *testing.T, got []chunk.Chunk)
+		wantErr bool
+	}{
+		{
+			name:    "identity",
+			expr:    ".",
+			in:      msgs,
+			wantLen: 1,
+			check: func(t *testing.T, got []chunk.Chunk) {
+				assert.Equal(t, msgs.Messages, got[0].Messages)
+			},
+		},
+		{
+			name:    "drops bot messages",
+			expr:    "del(.m[]? | select(.bot_id))",
+			in:      msgs,
+			wantLen: 1,
+			check: func(t *testing.T, got []chunk.Chunk) {
+				if assert.Len(t, got[0].Messages, 1) {
+					assert.Equal(t, "world", got[0].Messages[0].Text)
+				}
+			},
+		},
+		{
+			name:    "select drops the chunk",
+			expr:    "select(.t != 0)",
+			in:      msgs,
+			wantLen: 0,
+		},
+		{
+			name:    "splits the chunk",
+			expr:    ".m[] as $m | .m = [$m]",
+			in:      msgs,
+			wantLen: 2,
+			check: func(t *testing.T, got []chunk.Chunk) {
+				assert.Equal(t, "hello", got[0].Messages[0].Text)
+				assert.Equal(t, "world", got[1].Messages[0].Text)
+			},
+		},
+		{
+			name:    "string interpolation",
+			expr:    `.m[].text |= "\(.)!"`,
+			in:      msgs,
+			wantLen: 1,
+			check: func(t *testing.T, got []chunk.Chunk) {
+				assert.Equal(t, "hello!", got[0].Messages[0].Text)
+			},
+		},
+		{
+			name:    "not an object",
+			expr:    ".id",
+			in:      msgs,
+			wantErr: true,
+		},
+		{
+			name:    "runtime error",
+			expr:    `error("boom")`,
+			in:      msgs,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := gojq.Parse(tt.expr)
+			require.NoError(t, err)
+			code, err := gojq.Compile(q)
+			require.NoError(t, err)
+			got, err := mapChunkFunc(code)(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, got, tt.wantLen)
+			if tt.check != nil {
+				tt.check(t, got)
+			}
+		})
+	}
+}
+
+func Test_chunkInputOutput(t *testing.T) {
+	for _, name := range []string{"c.json", "c.json.gz", "c.json.zst"} {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), name)
+			w, err := createChunkOutput(filename)
+			require.NoError(t, err)
+			_, err = io.WriteString(w, "{}\n")
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			r, err := openChunkInput(filename)
+			require.NoError(t, err)
+			defer r.Close()
+			data, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, "{}\n", string(data))
+
+			rep, err := auditFile(filename)
+			require.NoError(t, err)
+			assert.Equal(t, 1, rep.Chunks)
+		})
+	}
+}
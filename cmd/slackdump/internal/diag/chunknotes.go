@@ -0,0 +1,78 @@
+package diag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/chunk"
+)
+
+var cmdChunkNotes = &base.Command{
+	UsageLine: "slackdump tools chunk notes <chunk_file_or_directory>",
+	Short:     "list the operator notes recorded in chunk files",
+	Long: `
+# Chunk notes tool
+
+Lists the operator notes, that were recorded with the data, i.e. with the
+"-note" flag of the archive and export commands.  Notes document the chain
+of custody of the recording: the case reference, the name of the operator,
+and the time the note was made.
+
+It accepts either a single chunk file (plain, or gzip-compressed, if the
+file name ends with ".gz"), or an archive directory, in which case the notes
+from all chunk files in the directory are listed.
+`,
+	FlagMask:   cfg.OmitAll,
+	PrintFlags: true,
+}
+
+func init() {
+	cmdChunkNotes.Run = runChunkNotes
+}
+
+func runChunkNotes(ctx context.Context, cmd *base.Command, args []string) error {
+	if len(args) != 1 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("expected exactly one chunk file or directory")
+	}
+	files, err := chunkFiles(args[0])
+	if err != nil {
+		base.SetExitStatus(base.SUserError)
+		return err
+	}
+	var n int
+	for _, name := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		aa, err := fileAnnotations(name)
+		if err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		for _, a := range aa {
+			fmt.Printf("%s: %s\n", name, a)
+		}
+		n += len(aa)
+	}
+	if n == 0 {
+		fmt.Println("no notes found")
+	}
+	return nil
+}
+
+func fileAnnotations(filename string) ([]chunk.Annotation, error) {
+	cf, err := openChunkFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer cf.Close()
+	aa, err := cf.Annotations()
+	if err != nil && !errors.Is(err, chunk.ErrNotFound) {
+		return nil, err
+	}
+	return aa, nil
+}
@@ -0,0 +1,163 @@
+package diag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/osext"
+)
+
+// cmdCompact is the command to remove duplicate messages from the chunk
+// files.
+var cmdCompact = &base.Command{
+	UsageLine: "slackdump tools compact [flags] <chunk_file_or_directory>",
+	Short:     "remove duplicate messages from chunk files",
+	Long: `
+# Compact tool
+
+Compact tool removes duplicate messages from the chunk files, that result
+from resumed or repeated runs, keeping only the latest recorded version of
+each message.
+
+It accepts either a single chunk file (plain, or compressed, if the file
+name ends with ".gz" or ".zst"), or an archive directory, in which case all chunk
+files in the directory are compacted.
+
+Files are compacted in place.  Use -dry-run flag to see how many messages
+would be removed without modifying the files.
+`,
+	FlagMask:    cfg.OmitAll,
+	PrintFlags:  true,
+	CustomFlags: true,
+}
+
+var compactDryRun bool
+
+func init() {
+	cmdCompact.Run = runCompact
+	cmdCompact.Flag.BoolVar(&compactDryRun, "dry-run", false, "do not modify the files, only print the statistics")
+}
+
+func runCompact(ctx context.Context, cmd *base.Command, args []string) error {
+	if err := cmd.Flag.Parse(args); err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	if cmd.Flag.NArg() != 1 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("expected exactly one chunk file or directory")
+	}
+	files, err := chunkFiles(cmd.Flag.Arg(0))
+	if err != nil {
+		base.SetExitStatus(base.SUserError)
+		return err
+	}
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		st, err := compactFile(f, compactDryRun)
+		if err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			return fmt.Errorf("%s: %w", f, err)
+		}
+		fmt.Printf("%s: %d chunks written, %d chunks and %d duplicate messages removed\n", f, st.Chunks, st.ChunksRemoved, st.MessagesRemoved)
+	}
+	return nil
+}
+
+// compactFile compacts the chunk file in place.  If the file name ends with
+// ".gz" or ".zst", it is treated as a compressed file.  If dryRun is true, the
+// compacted data is discarded.
+func compactFile(filename string, dryRun bool) (chunk.CompactStats, error) {
+	var st chunk.CompactStats
+	cf, err := openChunkFile(filename)
+	if err != nil {
+		return st, err
+	}
+	defer cf.Close()
+
+	if dryRun {
+		return cf.Compact(io.Discard)
+	}
+
+	// writing to a temporary file in the same directory, so that it can be
+	// atomically renamed over the original file.
+	out, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".compact*")
+	if err != nil {
+		return st, err
+	}
+	defer os.Remove(out.Name()) // noop after rename
+	defer out.Close()
+
+	w, err := osext.CompressionOf(filename).NewWriter(out)
+	if err != nil {
+		return st, err
+	}
+	st, err = cf.Compact(w)
+	if err != nil {
+		return st, err
+	}
+	if err := w.Close(); err != nil {
+		return st, err
+	}
+	if err := out.Close(); err != nil {
+		return st, err
+	}
+	if err := cf.Close(); err != nil {
+		return st, err
+	}
+	if err := os.Rename(out.Name(), filename); err != nil {
+		return st, err
+	}
+	return st, nil
+}
+
+// chunkFiles returns the list of chunk files for the name.  If name is a
+// directory, it returns all chunk files in it.
+func chunkFiles(name string) ([]string, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []string{name}, nil
+	}
+	des, err := os.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, de := range des {
+		if _, ok := chunk.FileIDOf(de.Name()); ok && !de.IsDir() {
+			files = append(files, filepath.Join(name, de.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no chunk files in %s", name)
+	}
+	return files, nil
+}
+
+// openChunkFile opens the chunk file.  If the file name ends with ".gz" or
+// ".zst", it is decompressed into a temporary file, which is removed when the
+// returned file is closed.
+func openChunkFile(filename string) (*chunk.File, error) {
+	rs, err := osext.OpenFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	cf, err := chunk.FromReader(rs)
+	if err != nil {
+		rs.Close()
+		return nil, err
+	}
+	return cf, nil
+}
@@ -1,6 +1,7 @@
 package diag
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"io"
@@ -9,9 +10,12 @@ import (
 
 	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
 	"github.com/ProtonMail/go-crypto/openpgp/packet"
+
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/ui"
 )
 
 // pub   rsa4096 2020-03-22 [SC] [expires: 2029-03-21]
@@ -77,26 +81,41 @@ DKOKJRTJslrewS0MeTopOa/NUI5zC1z9GsqWBAzrbUU=
 var cmdEncrypt = &base.Command{
 	Run:       runEncrypt,
 	UsageLine: "slackdump tools encrypt [flags] file",
-	Short:     "encrypts a file to post in github issues",
+	Short:     "encrypts a file to post in github issues, or with a passphrase",
 	Long: `
 # Command Encrypt
 
 Encrypt a file with the developer key to attach to a github issue or send
-as a message.
+as a message, or encrypt your own files (i.e. archives) with a passphrase.
 
-It uses the assymetric encryption (GPG) to encrypt the file with the
-developer key, and can only be decrypted by the developer.
+By default, it uses the assymetric encryption (GPG) to encrypt the file
+with the developer key, and can only be decrypted by the developer.
 
 ## Usage
 
 Encrypt a file to attach as a file to github issue:
 
-	$ slackdump tools encrypt -a file
+	$ slackdump tools encrypt file file.gpg
 
 Encrypt a file to post as a message (for small files):
 
 	$ slackdump tools encrypt -a file
 
+## Passphrase Encryption
+
+To protect your own files, use the -s flag.  The file is encrypted with the
+passphrase (OpenPGP symmetric encryption, same as "gpg -c"), and can be
+decrypted with the -d flag, or with any OpenPGP compatible tool, i.e. GnuPG:
+
+	$ slackdump tools encrypt -s archive.zip archive.zip.gpg
+	$ slackdump tools encrypt -d archive.zip.gpg archive.zip
+
+The passphrase is read from the terminal.  If the terminal is not available,
+i.e. when the file is piped to the command, or in scripts, the passphrase
+is taken from the ` + ui.PassphraseEnv + ` environment variable.
+
+Decryption supports both binary and armored input.  Files encrypted with the
+developer key can't be decrypted.
 `,
 	FlagMask:   cfg.OmitAll,
 	PrintFlags: true,
@@ -105,7 +124,20 @@ Encrypt a file to post as a message (for small files):
 var recipient *openpgp.Entity
 
 // flags
-var gArm bool
+var (
+	gArm       bool
+	gSymmetric bool
+	gDecrypt   bool
+)
+
+// passphraseFn returns the passphrase.  If confirm is true, the passphrase
+// must be entered twice.
+var passphraseFn = ui.Passphrase
+
+var (
+	errNotSymmetric    = errors.New("the file is not encrypted with a passphrase")
+	errWrongPassphrase = errors.New("wrong passphrase")
+)
 
 func init() {
 	if err := initRecipient(); err != nil {
@@ -113,6 +145,10 @@ func init() {
 	}
 	cmdEncrypt.Flag.BoolVar(&gArm, "a", false, "shorthand for -armor")
 	cmdEncrypt.Flag.BoolVar(&gArm, "armor", false, "armor the output")
+	cmdEncrypt.Flag.BoolVar(&gSymmetric, "s", false, "shorthand for -symmetric")
+	cmdEncrypt.Flag.BoolVar(&gSymmetric, "symmetric", false, "encrypt with a passphrase instead of the developer key")
+	cmdEncrypt.Flag.BoolVar(&gDecrypt, "d", false, "shorthand for -decrypt")
+	cmdEncrypt.Flag.BoolVar(&gDecrypt, "decrypt", false, "decrypt the file, encrypted with a passphrase")
 }
 
 func initRecipient() error {
@@ -133,6 +169,10 @@ func initRecipient() error {
 }
 
 func runEncrypt(ctx context.Context, cmd *base.Command, args []string) error {
+	if gDecrypt && gSymmetric {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("-decrypt and -symmetric can't be used together")
+	}
 	in, out, arm, err := parseArgs(args)
 	if err != nil {
 		return err
@@ -140,6 +180,19 @@ func runEncrypt(ctx context.Context, cmd *base.Command, args []string) error {
 	defer in.Close()
 	defer out.Close()
 
+	if gDecrypt {
+		pass, err := passphraseFn(false)
+		if err != nil {
+			base.SetExitStatus(base.SInvalidParameters)
+			return err
+		}
+		if err := decryptSymmetric(out, in, pass); err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			return err
+		}
+		return nil
+	}
+
 	var w io.Writer = out
 	if arm || gArm {
 		// arm if requested
@@ -152,6 +205,19 @@ func runEncrypt(ctx context.Context, cmd *base.Command, args []string) error {
 		w = aw
 	}
 
+	if gSymmetric {
+		pass, err := passphraseFn(true)
+		if err != nil {
+			base.SetExitStatus(base.SInvalidParameters)
+			return err
+		}
+		if err := encryptSymmetric(w, in, pass); err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			return err
+		}
+		return nil
+	}
+
 	cw, err := openpgp.Encrypt(w, []*openpgp.Entity{recipient}, nil, &openpgp.FileHints{IsBinary: true}, nil)
 	if err != nil {
 		base.SetExitStatus(base.SApplicationError)
@@ -165,6 +231,62 @@ func runEncrypt(ctx context.Context, cmd *base.Command, args []string) error {
 	return nil
 }
 
+// encryptSymmetric encrypts the data from r with the passphrase and writes it
+// to w.
+func encryptSymmetric(w io.Writer, r io.Reader, passphrase []byte) error {
+	cw, err := openpgp.SymmetricallyEncrypt(w, passphrase, &openpgp.FileHints{IsBinary: true}, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(cw, r); err != nil {
+		cw.Close()
+		return err
+	}
+	return cw.Close()
+}
+
+// decryptSymmetric decrypts the data from r, encrypted with the passphrase,
+// and writes it to w.  The input can be either binary or armored.
+func decryptSymmetric(w io.Writer, r io.Reader, passphrase []byte) error {
+	br := bufio.NewReader(r)
+	if hdr, err := br.Peek(len(armorHeader)); err == nil && string(hdr) == armorHeader {
+		block, err := armor.Decode(br)
+		if err != nil {
+			return err
+		}
+		r = block.Body
+	} else {
+		r = br
+	}
+
+	tried := false
+	prompt := func(keys []openpgp.Key, symmetric bool) ([]byte, error) {
+		if !symmetric {
+			return nil, errNotSymmetric
+		}
+		if tried {
+			// openpgp calls the prompt again, if the passphrase is wrong.
+			return nil, errWrongPassphrase
+		}
+		tried = true
+		return passphrase, nil
+	}
+	md, err := openpgp.ReadMessage(r, openpgp.EntityList{}, prompt, nil)
+	if err != nil {
+		if errors.Is(err, pgperrors.ErrKeyIncorrect) {
+			return errNotSymmetric
+		}
+		return err
+	}
+	if _, err := io.Copy(w, md.UnverifiedBody); err != nil {
+		return err
+	}
+	return nil
+}
+
+// armorHeader is the beginning of the armored OpenPGP data.
+const armorHeader = "-----BEGIN PGP"
+
 // parseArgs parses arguments and returns the input and output streams.
 //  1. if no arguments are given, input is stdin and output is stdout
 //  2. if one argument is given, and it is not a "-", the input is a file
@@ -1,10 +1,15 @@
 package diag
 
 import (
+	"bytes"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -82,3 +87,45 @@ func Test_parseArgs(t *testing.T) {
 		}
 	})
 }
+
+func Test_symmetric(t *testing.T) {
+	const plaintext = "my precious archive"
+	pass := []byte("correct horse battery staple")
+
+	var encrypted bytes.Buffer
+	require.NoError(t, encryptSymmetric(&encrypted, strings.NewReader(plaintext), pass))
+
+	t.Run("round trip", func(t *testing.T) {
+		var got bytes.Buffer
+		require.NoError(t, decryptSymmetric(&got, bytes.NewReader(encrypted.Bytes()), pass))
+		assert.Equal(t, plaintext, got.String())
+	})
+	t.Run("armored", func(t *testing.T) {
+		var armored bytes.Buffer
+		aw, err := armor.Encode(&armored, "PGP MESSAGE", nil)
+		require.NoError(t, err)
+		require.NoError(t, encryptSymmetric(aw, strings.NewReader(plaintext), pass))
+		require.NoError(t, aw.Close())
+
+		var got bytes.Buffer
+		require.NoError(t, decryptSymmetric(&got, &armored, pass))
+		assert.Equal(t, plaintext, got.String())
+	})
+	t.Run("wrong passphrase", func(t *testing.T) {
+		var got bytes.Buffer
+		err := decryptSymmetric(&got, bytes.NewReader(encrypted.Bytes()), []byte("wrong"))
+		assert.ErrorIs(t, err, errWrongPassphrase)
+		assert.Zero(t, got.Len())
+	})
+	t.Run("developer key", func(t *testing.T) {
+		var buf bytes.Buffer
+		cw, err := openpgp.Encrypt(&buf, []*openpgp.Entity{recipient}, nil, nil, nil)
+		require.NoError(t, err)
+		_, err = cw.Write([]byte(plaintext))
+		require.NoError(t, err)
+		require.NoError(t, cw.Close())
+
+		err = decryptSymmetric(io.Discard, &buf, pass)
+		assert.ErrorIs(t, err, errNotSymmetric)
+	})
+}
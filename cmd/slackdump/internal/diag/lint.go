@@ -0,0 +1,122 @@
+package diag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/lint"
+	"github.com/rusq/slackdump/v3/internal/source"
+)
+
+// cmdLint is the command to check the archive for the common fidelity
+// problems.
+var cmdLint = &base.Command{
+	UsageLine: "slackdump tools lint [flags] <archive>",
+	Short:     "check the archive for the common fidelity problems",
+	Long: `
+# Lint tool
+
+Lint tool checks the archive (the output of "slackdump archive", Slack export
+or dump, directory or ZIP file) for the common problems, that indicate that
+the archive is incomplete:
+
+- empty-channel: channels without messages.  It is an error, if Slack
+  reported the latest message of the channel within the requested range;
+- missing-user: message authors, that are not in the user list;
+- orphan-thread: thread replies without the parent message.  It's expected
+  for the threads started before the requested range;
+- out-of-range: messages outside of the requested time range, that is
+  specified with -time-from and -time-to flags;
+- zero-byte-file: downloaded attachments of zero size.
+
+Each issue has the severity: "error", "warning" or "info".  The tool exits
+with the non-zero status, if there are issues of the severity, set with
+-fail-on flag, or higher, so it can be used in the CI to check the backups:
+
+    slackdump tools lint -json -fail-on warning backup.zip > lint.json
+`,
+	FlagMask:    cfg.OmitAll,
+	PrintFlags:  true,
+	CustomFlags: true,
+}
+
+var lintFlags = struct {
+	json     bool
+	minLevel string
+	failOn   string
+	oldest   cfg.TimeValue
+	latest   cfg.TimeValue
+}{
+	minLevel: "info",
+	failOn:   "error",
+}
+
+func init() {
+	cmdLint.Run = runLint
+	cmdLint.Flag.BoolVar(&lintFlags.json, "json", false, "output the report in JSON format")
+	cmdLint.Flag.StringVar(&lintFlags.minLevel, "severity", lintFlags.minLevel, "report the issues of this `severity` or higher: info, warning or error")
+	cmdLint.Flag.StringVar(&lintFlags.failOn, "fail-on", lintFlags.failOn, "exit with the error, if there are issues of this `severity` or higher")
+	cmdLint.Flag.Var(&lintFlags.oldest, "time-from", "timestamp of the oldest message, that was requested (UTC timezone)")
+	cmdLint.Flag.Var(&lintFlags.latest, "time-to", "timestamp of the newest message, that was requested (UTC timezone)")
+}
+
+// errLint is returned, if the archive has issues of the -fail-on severity.
+var errLint = errors.New("archive has issues")
+
+func runLint(ctx context.Context, cmd *base.Command, args []string) error {
+	if err := cmd.Flag.Parse(args); err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	if cmd.Flag.NArg() != 1 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("expected exactly one archive")
+	}
+	minLevel, err := lint.ParseSeverity(lintFlags.minLevel)
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	failOn, err := lint.ParseSeverity(lintFlags.failOn)
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+
+	src, err := source.Load(ctx, cmd.Flag.Arg(0))
+	if err != nil {
+		base.SetExitStatus(base.SUserError)
+		return err
+	}
+	if cl, ok := src.(io.Closer); ok {
+		defer cl.Close()
+	}
+
+	rep, err := lint.Run(ctx, src, lint.Options{
+		Oldest: time.Time(lintFlags.oldest),
+		Latest: time.Time(lintFlags.latest),
+	})
+	if err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	write := rep.Filter(minLevel).WriteText
+	if lintFlags.json {
+		write = rep.Filter(minLevel).WriteJSON
+	}
+	if err := write(os.Stdout); err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	if n := rep.Count(failOn); n > 0 {
+		base.SetExitStatus(base.SUserError)
+		return fmt.Errorf("%w: %d issue(s) of severity %s or higher", errLint, n, failOn)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package diag
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/postproc"
+)
+
+// cmdPostprocess runs the post-processing pipeline on the existing export.
+var cmdPostprocess = &base.Command{
+	UsageLine: "slackdump tools postprocess [flags] <export>",
+	Short:     "compress, encrypt and upload the export",
+	Long: `
+# Postprocess tool
+
+Postprocess tool runs the post-processing pipeline on the existing export
+(directory or ZIP file): compresses it, encrypts the archive with age, and
+uploads it to the remote destination, as configured in the TOML file:
+
+    [compress]
+    format = "tar.gz"   # or "zip"
+
+    [encrypt]
+    recipients = ["age1..."]
+    # recipients_file = "recipients.txt"
+
+    [upload]
+    destination = "s3://bucket/backups"
+    retries = 3
+    remove_local = true
+
+All sections are optional, but at least one must be present.  The export
+directory is always compressed before encryption or upload.
+
+The same pipeline runs after the export with "slackdump export -post <file>".
+The progress is saved next to the export, if the pipeline is interrupted,
+i.e. due to the network error, run this tool with the same configuration to
+resume it: the completed steps are skipped, and the upload continues from
+the last uploaded part.
+
+The encrypted archive can be decrypted with the age tool:
+
+    age -d -i key.txt export.tar.gz.age > export.tar.gz
+`,
+	FlagMask:    cfg.OmitAll,
+	PrintFlags:  true,
+	CustomFlags: true,
+}
+
+var postprocConfig string
+
+func init() {
+	cmdPostprocess.Run = runPostprocess
+	cmdPostprocess.Flag.StringVar(&postprocConfig, "config", "", "post-processing configuration TOML `file`")
+}
+
+func runPostprocess(ctx context.Context, cmd *base.Command, args []string) error {
+	if err := cmd.Flag.Parse(args); err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	if cmd.Flag.NArg() != 1 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("expected exactly one export location")
+	}
+	if postprocConfig == "" {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("use -config to set the post-processing configuration file")
+	}
+	pcfg, err := postproc.Load(postprocConfig)
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	p, err := postproc.New(pcfg, postproc.WithLogger(cfg.Log))
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	if err := p.Run(ctx, cmd.Flag.Arg(0)); err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	return nil
+}
@@ -12,7 +12,9 @@ import (
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
 	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/osext"
 	"github.com/rusq/slackdump/v3/internal/structures"
+	"github.com/rusq/slackdump/v3/processor"
 )
 
 var cmdRecord = &base.Command{
@@ -30,6 +32,26 @@ var cmdRecordStream = &base.Command{
 
 Records the data from a channel in a chunk record format.
 
+The recording can be converted to the Slack Export format with
+"slackdump convert".
+
+To get the converted copy in the same run, use the "-tee" flag with the
+format and the output location, separated by the colon:
+
+- "export:<dir or zip>" writes the Slack Export layout, once the recording
+  is complete;
+- "ndjson:<file>" writes the messages in the newline delimited JSON format,
+  one message per line, as they are received.
+
+For example:
+
+	slackdump tools record stream -output rec.jsonl -tee ndjson:rec.ndjson C12401724
+
+The recording is compressed, if the output file name ends with ".gz" or
+".zst", or if the "-compress" flag is set, in which case the suffix is
+appended to the output file name.  The compressed recordings can be read by
+"slackdump tools record state" and other tools directly.
+
 See also: slackdump tool obfuscate
 `,
 	FlagMask:    cfg.OmitOutputFlag | cfg.OmitDownloadFlag,
@@ -50,7 +72,15 @@ func init() {
 	cmdRecordStream.Run = runRecord
 }
 
-var output = cmdRecordStream.Flag.String("output", "", "output file")
+var (
+	output   = cmdRecordStream.Flag.String("output", "", "output file")
+	compress = cmdRecordStream.Flag.String("compress", "", "compress the recording with `algorithm`: gzip or zstd,\nif not set, it is detected from the output file suffix (.gz or .zst)")
+	teeFlag  teeTarget
+)
+
+func init() {
+	cmdRecordStream.Flag.Var(&teeFlag, "tee", "also convert the recording on the fly, `format:path`, where\nformat is one of: export, ndjson")
+}
 
 func runRecord(ctx context.Context, _ *base.Command, args []string) error {
 	if len(args) == 0 {
@@ -64,40 +94,55 @@ func runRecord(ctx context.Context, _ *base.Command, args []string) error {
 		return err
 	}
 
+	comp, err := osext.ParseCompression(*compress)
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+
 	var w io.Writer
 	if *output == "" {
-		w = os.Stdout
+		cw, err := comp.NewWriter(os.Stdout)
+		if err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			return err
+		}
+		defer cw.Close()
+		w = cw
 	} else {
-		if f, err := os.Create(*output); err != nil {
+		*output = comp.WithExt(*output)
+		f, err := osext.CreateFile(*output)
+		if err != nil {
 			base.SetExitStatus(base.SApplicationError)
 			return err
-		} else {
-			defer f.Close()
-			w = f
 		}
+		defer f.Close()
+		w = f
 	}
 
-	rec := chunk.NewRecorder(w)
-	for _, ch := range args {
-		lg := cfg.Log.With("channel_id", ch)
-		lg.InfoContext(ctx, "streaming")
-		if err := sess.Stream().SyncConversations(ctx, rec, structures.EntityItem{Id: ch}); err != nil {
-			if err2 := rec.Close(); err2 != nil {
-				base.SetExitStatus(base.SApplicationError)
-				return fmt.Errorf("error streaming channel %q: %w; error closing recorder: %v", ch, err, err2)
-			}
+	var t tee
+	if teeFlag.format != "" {
+		if t, err = teeFlag.open(); err != nil {
+			base.SetExitStatus(base.SApplicationError)
 			return err
 		}
+		w = t.Writer(w)
 	}
-	if err := rec.Close(); err != nil {
+
+	rec, err := record(ctx, sess.Stream(), w, t, args)
+	if err != nil {
 		base.SetExitStatus(base.SApplicationError)
 		return err
 	}
+	if t != nil {
+		cfg.Log.InfoContext(ctx, "converted copy written", "tee", teeFlag.String())
+	}
 	st, err := rec.State()
 	if err != nil {
 		base.SetExitStatus(base.SApplicationError)
 		return err
 	}
+	st.SetIsCompressed(osext.CompressionOf(*output) != osext.CompressNone)
 	if err := st.Save(*output + ".state"); err != nil {
 		base.SetExitStatus(base.SApplicationError)
 		return err
@@ -105,6 +150,41 @@ func runRecord(ctx context.Context, _ *base.Command, args []string) error {
 	return nil
 }
 
+// conversationSyncer is the conversation streamer.
+type conversationSyncer interface {
+	SyncConversations(ctx context.Context, proc processor.Conversations, items ...structures.EntityItem) error
+}
+
+// record streams the channels to the chunk recorder, that writes to w.  If t
+// is not nil, it receives the recording as well, and is finished once the
+// recorder is closed.  It returns the closed recorder.
+func record(ctx context.Context, s conversationSyncer, w io.Writer, t tee, channels []string) (*chunk.Recorder, error) {
+	rec := chunk.NewRecorder(w)
+	var proc processor.Conversations = rec
+	if t != nil {
+		proc = t.Processor(rec)
+	}
+	for _, ch := range channels {
+		lg := cfg.Log.With("channel_id", ch)
+		lg.InfoContext(ctx, "streaming")
+		if err := s.SyncConversations(ctx, proc, structures.EntityItem{Id: ch}); err != nil {
+			if err2 := rec.Close(); err2 != nil {
+				return nil, fmt.Errorf("error streaming channel %q: %w; error closing recorder: %v", ch, err, err2)
+			}
+			return nil, err
+		}
+	}
+	if err := rec.Close(); err != nil {
+		return nil, err
+	}
+	if t != nil {
+		if err := t.Finish(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return rec, nil
+}
+
 func init() {
 	// break init cycle
 	cmdRecordState.Run = runRecordState
@@ -115,7 +195,7 @@ func runRecordState(ctx context.Context, _ *base.Command, args []string) error {
 		base.SetExitStatus(base.SInvalidParameters)
 		return errors.New("missing record file argument")
 	}
-	f, err := os.Open(args[0])
+	f, err := osext.OpenFile(args[0])
 	if err != nil {
 		base.SetExitStatus(base.SApplicationError)
 		return err
@@ -0,0 +1,191 @@
+package diag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/convert"
+	"github.com/rusq/slackdump/v3/internal/format"
+	"github.com/rusq/slackdump/v3/internal/remotefs"
+	"github.com/rusq/slackdump/v3/processor"
+	"github.com/rusq/slackdump/v3/types"
+)
+
+// teeFormat is the format of the converted copy of the recording.
+type teeFormat string
+
+const (
+	teeExport teeFormat = "export"
+	teeNDJSON teeFormat = "ndjson"
+)
+
+// teeTarget is the "-tee" flag value, in form "format:path".
+type teeTarget struct {
+	format teeFormat
+	path   string
+}
+
+// String implements the flag.Value interface.
+func (t *teeTarget) String() string {
+	if t == nil || t.format == "" {
+		return ""
+	}
+	return string(t.format) + ":" + t.path
+}
+
+// Set implements the flag.Value interface.
+func (t *teeTarget) Set(s string) error {
+	f, path, ok := strings.Cut(s, ":")
+	if !ok || path == "" {
+		return fmt.Errorf("invalid tee target %q, must be in form format:path", s)
+	}
+	switch tf := teeFormat(strings.ToLower(f)); tf {
+	case teeExport, teeNDJSON:
+		t.format, t.path = tf, path
+	default:
+		return fmt.Errorf("unknown tee format: %q, must be one of: %s, %s", f, teeExport, teeNDJSON)
+	}
+	return nil
+}
+
+// tee is the converter that receives the recording along with the recorder.
+type tee interface {
+	// Writer returns the writer for the recording.
+	Writer(w io.Writer) io.Writer
+	// Processor returns the processor for the stream.
+	Processor(rec processor.Conversations) processor.Conversations
+	// Finish is called once the recording is closed, it completes the
+	// conversion and releases the resources.
+	Finish(ctx context.Context) error
+}
+
+// open returns the tee for the target.
+func (t *teeTarget) open() (tee, error) {
+	switch t.format {
+	case teeNDJSON:
+		f, err := os.Create(t.path)
+		if err != nil {
+			return nil, err
+		}
+		return &ndjsonTee{
+			f:       f,
+			fmt:     format.NewNDJSON(),
+			names:   make(map[string]string),
+			parents: make(map[string]struct{}),
+		}, nil
+	case teeExport:
+		tmp, err := os.CreateTemp("", "slackdump-tee-*.jsonl")
+		if err != nil {
+			return nil, err
+		}
+		return &exportTee{path: t.path, tmp: tmp}, nil
+	}
+	return nil, errors.New("internal error: tee target is not set")
+}
+
+// exportTee copies the recording to the temporary file, and converts it to
+// the export layout once the recording is complete, as the export files are
+// per-day and require complete threads.
+type exportTee struct {
+	path string
+	tmp  *os.File
+}
+
+func (t *exportTee) Writer(w io.Writer) io.Writer {
+	return io.MultiWriter(w, t.tmp)
+}
+
+func (t *exportTee) Processor(rec processor.Conversations) processor.Conversations {
+	return rec
+}
+
+func (t *exportTee) Finish(ctx context.Context) error {
+	defer os.Remove(t.tmp.Name())
+	defer t.tmp.Close()
+	if _, err := t.tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	fsa, err := remotefs.New(t.path)
+	if err != nil {
+		return err
+	}
+	if err := convert.NewRecordingToExport(t.tmp, fsa).Convert(ctx); err != nil {
+		_ = fsa.Close()
+		return fmt.Errorf("error converting the recording to export: %w", err)
+	}
+	return fsa.Close()
+}
+
+// ndjsonTee writes the messages in the NDJSON format as they are received.
+type ndjsonTee struct {
+	processor.Conversations
+	f   *os.File
+	fmt format.Formatter
+
+	mu      sync.Mutex
+	names   map[string]string   // channel ID -> channel name
+	parents map[string]struct{} // thread parents written
+}
+
+func (t *ndjsonTee) Writer(w io.Writer) io.Writer {
+	return w
+}
+
+func (t *ndjsonTee) Processor(rec processor.Conversations) processor.Conversations {
+	t.Conversations = rec
+	return t
+}
+
+func (t *ndjsonTee) ChannelInfo(ctx context.Context, ci *slack.Channel, threadID string) error {
+	t.mu.Lock()
+	t.names[ci.ID] = ci.Name
+	t.mu.Unlock()
+	return t.Conversations.ChannelInfo(ctx, ci, threadID)
+}
+
+func (t *ndjsonTee) Messages(ctx context.Context, channelID string, numThreads int, isLast bool, mm []slack.Message) error {
+	if err := t.Conversations.Messages(ctx, channelID, numThreads, isLast, mm); err != nil {
+		return err
+	}
+	return t.write(ctx, channelID, mm)
+}
+
+func (t *ndjsonTee) ThreadMessages(ctx context.Context, channelID string, parent slack.Message, threadOnly, isLast bool, replies []slack.Message) error {
+	if err := t.Conversations.ThreadMessages(ctx, channelID, parent, threadOnly, isLast, replies); err != nil {
+		return err
+	}
+	if threadOnly {
+		// the parent is not in the channel messages, it is written once, as
+		// it comes with every chunk of replies.
+		key := channelID + ":" + parent.Timestamp
+		t.mu.Lock()
+		_, seen := t.parents[key]
+		t.parents[key] = struct{}{}
+		t.mu.Unlock()
+		if !seen {
+			replies = append([]slack.Message{parent}, replies...)
+		}
+	}
+	return t.write(ctx, channelID, replies)
+}
+
+func (t *ndjsonTee) write(ctx context.Context, channelID string, mm []slack.Message) error {
+	if len(mm) == 0 {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	conv := &types.Conversation{ID: channelID, Name: t.names[channelID], Messages: types.ConvertMsgs(mm)}
+	return t.fmt.Conversation(ctx, t.f, nil, conv)
+}
+
+func (t *ndjsonTee) Finish(context.Context) error {
+	return t.f.Close()
+}
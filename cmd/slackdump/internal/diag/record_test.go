@@ -0,0 +1,99 @@
+package diag
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/structures"
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+// fakeSyncer sends the fixed channel history to the processor.
+type fakeSyncer struct{}
+
+func (fakeSyncer) SyncConversations(ctx context.Context, proc processor.Conversations, items ...structures.EntityItem) error {
+	for _, it := range items {
+		ci := &slack.Channel{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: it.Id}}}
+		if err := proc.ChannelInfo(ctx, ci, ""); err != nil {
+			return err
+		}
+		parent := slack.Message{Msg: slack.Msg{Timestamp: "1700000001.000000", ThreadTimestamp: "1700000001.000000", Text: "parent", ReplyCount: 1}}
+		mm := []slack.Message{
+			parent,
+			{Msg: slack.Msg{Timestamp: "1700000002.000000", Text: "second"}},
+		}
+		if err := proc.Messages(ctx, it.Id, 1, true, mm); err != nil {
+			return err
+		}
+		reply := slack.Message{Msg: slack.Msg{Timestamp: "1700000003.000000", ThreadTimestamp: parent.Timestamp, Text: "reply"}}
+		if err := proc.ThreadMessages(ctx, it.Id, parent, false, true, []slack.Message{reply}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func Test_record_tee(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	recPath := filepath.Join(dir, "rec.jsonl")
+	teePath := filepath.Join(dir, "rec.ndjson")
+
+	var target teeTarget
+	require.NoError(t, target.Set("ndjson:"+teePath))
+	tt, err := target.open()
+	require.NoError(t, err)
+
+	f, err := os.Create(recPath)
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = record(ctx, fakeSyncer{}, tt.Writer(f), tt, []string{"C1", "C2"})
+	require.NoError(t, err)
+
+	// events in the recording.
+	_, err = f.Seek(0, 0)
+	require.NoError(t, err)
+	cf, err := chunk.FromReader(f)
+	require.NoError(t, err)
+	var recorded []string
+	err = cf.ForEach(func(c *chunk.Chunk) error {
+		if c.Type == chunk.CMessages || c.Type == chunk.CThreadMessages {
+			for _, m := range c.Messages {
+				recorded = append(recorded, c.ChannelID+":"+m.Timestamp)
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// events in the tee output.
+	tf, err := os.Open(teePath)
+	require.NoError(t, err)
+	defer tf.Close()
+	var teed []string
+	sc := bufio.NewScanner(tf)
+	for sc.Scan() {
+		var m struct {
+			ChannelID string `json:"channel_id"`
+			Channel   string `json:"channel"`
+			TS        string `json:"ts"`
+		}
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &m))
+		assert.Equal(t, "general", m.Channel)
+		teed = append(teed, m.ChannelID+":"+m.TS)
+	}
+	require.NoError(t, sc.Err())
+
+	assert.Len(t, recorded, 6)
+	assert.ElementsMatch(t, recorded, teed)
+}
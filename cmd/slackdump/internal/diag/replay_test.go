@@ -0,0 +1,59 @@
+package diag
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/fixtures"
+	"github.com/rusq/slackdump/v3/internal/golden"
+)
+
+// testRecording writes the fixture recording with the users to the
+// temporary file, and returns its name.
+func testRecording(t *testing.T) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "rec.jsonl")
+	f, err := os.Create(name)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = io.Copy(f, fixtures.ChunkFileJSONL())
+	require.NoError(t, err)
+	require.NoError(t, json.NewEncoder(f).Encode(chunk.Chunk{Type: chunk.CUsers, Users: fixtures.TestUsers}))
+	return name
+}
+
+func Test_replay(t *testing.T) {
+	rec := testRecording(t)
+	ctx := context.Background()
+
+	want := t.TempDir()
+	require.NoError(t, replay(ctx, rec, want))
+	_, err := os.Stat(filepath.Join(want, "channels.json"))
+	require.NoError(t, err, "export not produced")
+
+	t.Run("reproducible", func(t *testing.T) {
+		got := t.TempDir()
+		require.NoError(t, replay(ctx, rec, got))
+		diffs, err := golden.Compare(os.DirFS(got), os.DirFS(want))
+		require.NoError(t, err)
+		assert.Empty(t, diffs)
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(want, "users.json"), []byte(`[]`), 0o644))
+		got := t.TempDir()
+		require.NoError(t, replay(ctx, rec, got))
+		diffs, err := golden.Compare(os.DirFS(got), os.DirFS(want))
+		require.NoError(t, err)
+		require.Len(t, diffs, 1)
+		assert.Equal(t, "users.json", diffs[0].Path)
+		assert.Equal(t, golden.Changed, diffs[0].Kind)
+	})
+}
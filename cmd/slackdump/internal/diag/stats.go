@@ -0,0 +1,108 @@
+package diag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/emojistats"
+	"github.com/rusq/slackdump/v3/internal/source"
+)
+
+// cmdStats is the command to print the emoji usage statistics of the
+// archive.
+var cmdStats = &base.Command{
+	UsageLine: "slackdump tools stats [flags] <archive>",
+	Short:     "print the emoji and reaction usage statistics",
+	Long: `
+# Stats tool
+
+Stats tool computes how often each emoji is used in the archive (the output
+of "slackdump archive", Slack export or dump, directory or ZIP file), in
+total, per channel and per user, i.e. for the community health reporting.
+
+Both the reactions and the emoji in the message text, like ":tada:", are
+counted.  Reactions are attributed to the users who reacted, and the emoji
+in the text to the message author.  Slack lists only the first users of the
+popular reactions, so the per user numbers may be lower than the per
+channel ones.  Skin tone variations are counted as the base emoji.
+
+The statistics is printed in CSV format, one record per emoji for the
+totals, each channel and each user, with the "scope" column set to "total",
+"channel" or "user" respectively.  Use "-format json" to get the JSON
+report instead.
+`,
+	FlagMask:    cfg.OmitAll,
+	PrintFlags:  true,
+	CustomFlags: true,
+}
+
+var statsFlags = struct {
+	format string
+	output string
+}{
+	format: "csv",
+}
+
+func init() {
+	cmdStats.Run = runStats
+	cmdStats.Flag.StringVar(&statsFlags.format, "format", statsFlags.format, "output `format`: csv or json")
+	cmdStats.Flag.StringVar(&statsFlags.output, "o", "", "output `file`, by default the statistics is printed to stdout")
+}
+
+func runStats(ctx context.Context, cmd *base.Command, args []string) error {
+	if err := cmd.Flag.Parse(args); err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	if cmd.Flag.NArg() != 1 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("expected exactly one archive")
+	}
+	var write func(*emojistats.Report, io.Writer) error
+	switch strings.ToLower(statsFlags.format) {
+	case "csv":
+		write = (*emojistats.Report).WriteCSV
+	case "json":
+		write = (*emojistats.Report).WriteJSON
+	default:
+		base.SetExitStatus(base.SInvalidParameters)
+		return fmt.Errorf("unsupported format %q, must be csv or json", statsFlags.format)
+	}
+
+	src, err := source.Load(ctx, cmd.Flag.Arg(0))
+	if err != nil {
+		base.SetExitStatus(base.SUserError)
+		return err
+	}
+	if cl, ok := src.(io.Closer); ok {
+		defer cl.Close()
+	}
+
+	rep, err := emojistats.FromSource(ctx, src)
+	if err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if statsFlags.output != "" {
+		f, err := os.Create(statsFlags.output)
+		if err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := write(rep, w); err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	return nil
+}
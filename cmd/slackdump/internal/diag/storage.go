@@ -0,0 +1,81 @@
+package diag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/filestats"
+)
+
+// cmdStorage is the command to print the storage usage report of the
+// archive.
+var cmdStorage = &base.Command{
+	UsageLine: "slackdump tools storage [flags] <archive_dir>",
+	Short:     "print the storage usage report of the file attachments",
+	Long: `
+# Storage tool
+
+Storage tool prints the storage usage report of the file attachments in the
+archive (the output of "slackdump archive"): the total size of the files per
+channel and per uploader, and the list of the largest files, to help
+deciding what to clean up in the workspace.
+
+The sizes are taken from the file metadata, so the files do not need to be
+downloaded.  A file that was shared to several channels is accounted once,
+in the channel where it was posted first.
+
+Use -json flag to get the report in JSON format, i.e. to process it with
+other tools.  The report can also be generated during the export with
+"slackdump export -storage-report".
+`,
+	FlagMask:    cfg.OmitAll,
+	PrintFlags:  true,
+	CustomFlags: true,
+}
+
+var storageFlags = struct {
+	topN int
+	json bool
+}{
+	topN: filestats.DefTopN,
+}
+
+func init() {
+	cmdStorage.Run = runStorage
+	cmdStorage.Flag.IntVar(&storageFlags.topN, "top", filestats.DefTopN, "number of the largest files, channels and uploaders to list,\n0 lists all")
+	cmdStorage.Flag.BoolVar(&storageFlags.json, "json", false, "output the report in JSON format")
+}
+
+func runStorage(ctx context.Context, cmd *base.Command, args []string) error {
+	if err := cmd.Flag.Parse(args); err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	if cmd.Flag.NArg() != 1 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("expected exactly one archive directory")
+	}
+	cd, err := chunk.OpenDir(cmd.Flag.Arg(0))
+	if err != nil {
+		base.SetExitStatus(base.SUserError)
+		return err
+	}
+	defer cd.Close()
+
+	rep, err := filestats.FromChunks(ctx, cd, storageFlags.topN)
+	if err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	if storageFlags.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rep)
+	}
+	return rep.WriteText(os.Stdout, storageFlags.topN)
+}
@@ -21,14 +21,22 @@ Tools command contains different tools, running which may be requested if you op
 	RequireAuth: false,
 	Commands: []*base.Command{
 		// cmdEdge,
+		cmdAnonymize,
+		cmdChunk,
+		cmdCompact,
 		cmdEncrypt,
 		cmdEzTest,
 		cmdInfo,
+		cmdLint,
 		cmdObfuscate,
+		cmdPostprocess,
 		// cmdRawOutput,
+		cmdReplayVerify,
 		cmdUninstall,
 		// cmdRecord,
 		// cmdSearch,
+		cmdStats,
+		cmdStorage,
 		cmdThread,
 		// cmdWizDebug,
 	},
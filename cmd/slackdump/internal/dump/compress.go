@@ -0,0 +1,72 @@
+package dump
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rusq/fsadapter"
+
+	"github.com/rusq/slackdump/v3/internal/osext"
+)
+
+// compressFS compresses the conversation files, that are written to the
+// underlying filesystem.  Other files, i.e. attachments, are written as is.
+type compressFS struct {
+	fsadapter.FS
+	comp osext.Compression
+	// suffix is the suffix of the conversation file names.
+	suffix string
+}
+
+// newCompressFS returns fsa, that compresses the files with the names
+// ending with ext, with the compression comp.  If comp is none, fsa is
+// returned as is.
+func newCompressFS(fsa fsadapter.FS, comp osext.Compression, ext string) fsadapter.FS {
+	if comp == osext.CompressNone {
+		return fsa
+	}
+	return &compressFS{FS: fsa, comp: comp, suffix: comp.WithExt(ext)}
+}
+
+func (c *compressFS) Create(name string) (io.WriteCloser, error) {
+	wc, err := c.FS.Create(name)
+	if err != nil || !strings.HasSuffix(name, c.suffix) {
+		return wc, err
+	}
+	cw, err := c.comp.NewWriter(wc)
+	if err != nil {
+		wc.Close()
+		return nil, err
+	}
+	return &compressWriter{WriteCloser: cw, underlying: wc}, nil
+}
+
+func (c *compressFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	if !strings.HasSuffix(name, c.suffix) {
+		return c.FS.WriteFile(name, data, perm)
+	}
+	wc, err := c.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := wc.Write(data); err != nil {
+		wc.Close()
+		return err
+	}
+	return wc.Close()
+}
+
+// compressWriter closes the compressor, and then the underlying writer.
+type compressWriter struct {
+	io.WriteCloser
+	underlying io.Closer
+}
+
+func (w *compressWriter) Close() error {
+	if err := w.WriteCloser.Close(); err != nil {
+		w.underlying.Close()
+		return err
+	}
+	return w.underlying.Close()
+}
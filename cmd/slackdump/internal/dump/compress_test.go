@@ -0,0 +1,40 @@
+package dump
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rusq/fsadapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/osext"
+)
+
+func Test_compressFS(t *testing.T) {
+	dir := t.TempDir()
+	fsa := newCompressFS(fsadapter.NewDirectory(dir), osext.CompressZSTD, ".json")
+
+	// conversation file is compressed.
+	require.NoError(t, fsa.WriteFile("C1.json.zst", []byte(`{"id":"C1"}`), 0o644))
+	// attachment is written as is.
+	require.NoError(t, fsa.WriteFile("C1/F1-report.json", []byte(`{}`), 0o644))
+
+	r, err := osext.OpenFile(filepath.Join(dir, "C1.json.zst"))
+	require.NoError(t, err)
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"C1"}`, string(got))
+
+	raw, err := os.ReadFile(filepath.Join(dir, "C1", "F1-report.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(raw))
+
+	t.Run("no compression", func(t *testing.T) {
+		fsa := fsadapter.NewDirectory(dir)
+		assert.Equal(t, fsadapter.FS(fsa), newCompressFS(fsa, osext.CompressNone, ".json"))
+	})
+}
@@ -6,13 +6,16 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"runtime/trace"
 	"strings"
 	"text/template"
 	"time"
 
 	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
 
 	"github.com/rusq/slackdump/v3"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/bootstrap"
@@ -23,8 +26,13 @@ import (
 	"github.com/rusq/slackdump/v3/internal/chunk/dirproc"
 	"github.com/rusq/slackdump/v3/internal/chunk/transform"
 	"github.com/rusq/slackdump/v3/internal/chunk/transform/fileproc"
+	"github.com/rusq/slackdump/v3/internal/errreport"
+	"github.com/rusq/slackdump/v3/internal/format"
 	"github.com/rusq/slackdump/v3/internal/nametmpl"
+	"github.com/rusq/slackdump/v3/internal/osext"
+	"github.com/rusq/slackdump/v3/internal/remotefs"
 	"github.com/rusq/slackdump/v3/internal/structures"
+	"github.com/rusq/slackdump/v3/processor"
 	"github.com/rusq/slackdump/v3/stream"
 )
 
@@ -39,6 +47,7 @@ var CmdDump = &base.Command{
 	RequireAuth: true,
 	PrintFlags:  true,
 	FlagMask:    cfg.OmitMemberOnlyFlag,
+	Commands:    []*base.Command{cmdDumpThread, cmdDumpMessage},
 }
 
 func init() {
@@ -50,9 +59,28 @@ func init() {
 // ErrNothingToDo is returned if there are no links to dump.
 var ErrNothingToDo = errors.New("no conversations to dump, run \"slackdump help dump\"")
 
+// ErrZIPLines is returned if the JSON lines or CSV rows are requested to be
+// written to a ZIP file, which can't hold several open files.
+var ErrZIPLines = errors.New("jsonl and csv formats can't be written to a ZIP file, specify a directory, or \"-\" for stdout")
+
+// output formats.
+const (
+	fmtJSON  = "json"  // conversation as one JSON document
+	fmtJSONL = "jsonl" // messages as JSON lines, streamed as they are fetched
+	fmtCSV   = "csv"   // messages as flat CSV rows, streamed as they are fetched
+)
+
+// stdoutLocation is the output location, that makes the JSON lines or CSV
+// rows to be written to stdout.
+const stdoutLocation = "-"
+
 type options struct {
 	nameTemplate string // NameTemplate is the template for the output file name.
 	updateLinks  bool   // update file links to point to the downloaded files
+	format       string // output format, one of fmtJSON, fmtJSONL or fmtCSV.
+	compress     string // compression algorithm of the conversation files.
+	rfc3339      bool   // add the RFC 3339 times to the JSON lines and CSV rows.
+	fileTemplate string // naming template of the downloaded files.
 }
 
 var opts options
@@ -61,10 +89,18 @@ var opts options
 func initDumpFlagset(fs *flag.FlagSet) {
 	fs.StringVar(&opts.nameTemplate, "ft", nametmpl.Default, "output file naming template.\n")
 	fs.BoolVar(&opts.updateLinks, "update-links", false, "update file links to point to the downloaded files.")
+	fs.StringVar(&opts.format, "format", fmtJSON, "output `format`: \"json\" writes each conversation as a JSON document,\n\"jsonl\" streams the messages as JSON lines, as they are fetched,\n\"csv\" streams the messages as CSV rows for spreadsheets and BI tools,\nuse with \"-o -\" to write to stdout.")
+	fs.BoolVar(&opts.rfc3339, "rfc3339", false, "add the RFC 3339 time in UTC next to each message timestamp in the\n\"jsonl\" and \"csv\" formats.")
+	fs.StringVar(&opts.compress, "compress", "", "compress the conversation files with `algorithm`: gzip or zstd,\nthe \".gz\" or \".zst\" suffix is appended to the file names.")
+	fs.StringVar(&opts.fileTemplate, "file-template", "", "naming `template` of the downloaded files, i.e.\n\"{{.ChannelID}}/{{.Date}}/{{.ID}}-{{.Name}}\".  Available: ID, Name, Base,\nExt, Channel, ChannelID, User, Date, Year, Month, Day and Hash,\nID or Hash must be used.")
+	cfg.SetFilterFlags(fs)
+	cfg.SetRedactFlags(fs)
 }
 
 func init() {
 	initDumpFlagset(&CmdDump.Flag)
+	initDumpFlagset(&cmdDumpThread.Flag)
+	initDumpFlagset(&cmdDumpMessage.Flag)
 }
 
 // RunDump is the main entry point for the dump command.
@@ -74,8 +110,6 @@ func RunDump(ctx context.Context, _ *base.Command, args []string) error {
 		return ErrNothingToDo
 	}
 
-	lg := cfg.Log
-
 	// initialize the list of entities to dump.
 	list, err := structures.NewEntityList(args)
 	if err != nil {
@@ -86,39 +120,112 @@ func RunDump(ctx context.Context, _ *base.Command, args []string) error {
 		return ErrNothingToDo
 	}
 
+	return runDump(ctx, list)
+}
+
+// runDump dumps the entities in the list to the output location.
+func runDump(ctx context.Context, list *structures.EntityList) error {
+	return runDumpList(ctx, func(context.Context, *slackdump.Session) (*structures.EntityList, error) {
+		return list, nil
+	}, false)
+}
+
+// listFunc returns the list of the entities to dump, it is called once the
+// session is established.
+type listFunc func(ctx context.Context, sess *slackdump.Session) (*structures.EntityList, error)
+
+// runDumpList dumps the entities in the list returned by listFn to the
+// output location.  If authors is set, the profiles of the message authors
+// are written to the users file.
+func runDumpList(ctx context.Context, listFn listFunc, authors bool) error {
+	lg := cfg.Log
+
 	// initialize the file naming template.
 	if opts.nameTemplate == "" {
 		opts.nameTemplate = nametmpl.Default
 	}
-	tmpl, err := nametmpl.New(opts.nameTemplate + ".json")
+	switch opts.format {
+	case fmtJSON, "":
+	case fmtJSONL, fmtCSV:
+		if strings.EqualFold(filepath.Ext(cfg.Output), ".zip") {
+			base.SetExitStatus(base.SInvalidParameters)
+			return ErrZIPLines
+		}
+	default:
+		base.SetExitStatus(base.SInvalidParameters)
+		return fmt.Errorf("unknown output format: %q", opts.format)
+	}
+	comp, err := osext.ParseCompression(opts.compress)
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	tmpl, err := nametmpl.New(opts.nameTemplate + comp.WithExt(formatExt(opts.format)))
 	if err != nil {
 		base.SetExitStatus(base.SUserError)
 		return fmt.Errorf("file template error: %w", err)
 	}
+	var fileTmpl *nametmpl.FileTemplate
+	if opts.fileTemplate != "" {
+		if fileTmpl, err = nametmpl.NewFile(opts.fileTemplate); err != nil {
+			base.SetExitStatus(base.SInvalidParameters)
+			return fmt.Errorf("-file-template: %w", err)
+		}
+	}
 
-	fsa, err := fsadapter.New(cfg.Output)
-	if err != nil {
-		base.SetExitStatus(base.SApplicationError)
-		return err
+	p := dumpparams{
+		tmpl:          tmpl,
+		fileTmpl:      fileTmpl,
+		updatePath:    opts.updateLinks,
+		downloadFiles: cfg.DownloadFiles,
+		format:        opts.format,
+		rfc3339:       opts.rfc3339,
+		compress:      comp,
+		authors:       authors,
 	}
-	defer func() {
-		if err := fsa.Close(); err != nil {
-			lg.WarnContext(ctx, "warning: failed to close the filesystem", "error", err)
+
+	var fsa fsadapter.FSCloser
+	if isStreaming(opts.format) && cfg.Output == stdoutLocation {
+		if p.downloadFiles {
+			lg.WarnContext(ctx, "files are not downloaded, when writing to stdout")
+			p.downloadFiles = false
 		}
-	}()
+		cw, err := comp.NewWriter(os.Stdout)
+		if err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			return err
+		}
+		defer cw.Close()
+		p.out = cw
+	} else {
+		fsa, err = remotefs.New(cfg.Output)
+		if err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			return err
+		}
+		defer func() {
+			if err := fsa.Close(); err != nil {
+				lg.WarnContext(ctx, "warning: failed to close the filesystem", "error", err)
+			}
+		}()
+	}
 
 	sess, err := bootstrap.SlackdumpSession(ctx, slackdump.WithFilesystem(fsa))
 	if err != nil {
 		base.SetExitStatus(base.SInitializationError)
 		return err
 	}
-
-	p := dumpparams{
-		list:          list,
-		tmpl:          tmpl,
-		updatePath:    opts.updateLinks,
-		downloadFiles: cfg.DownloadFiles,
+	list, err := listFn(ctx, sess)
+	if err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	p.list = list
+	if err := bootstrap.ResolveChannelNames(ctx, sess, list); err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
 	}
+	bootstrap.Preflight(ctx, sess, time.Time(cfg.Oldest))
 
 	// leave the compatibility mode to the user, if the new version is playing
 	// tricks.
@@ -134,8 +241,18 @@ func RunDump(ctx context.Context, _ *base.Command, args []string) error {
 type dumpparams struct {
 	list          *structures.EntityList // list of entities to dump
 	tmpl          *nametmpl.Template     // file naming template
+	fileTmpl      *nametmpl.FileTemplate // downloaded files naming template, if set
 	updatePath    bool                   // update filepath to point to the downloaded file?
 	downloadFiles bool                   // download files?
+	format        string                 // output format
+	rfc3339       bool                   // add the RFC 3339 times to the lines
+	compress      osext.Compression      // compression of the conversation files
+	// out is the writer for the JSON lines or CSV rows of all
+	// conversations, if set, instead of the conversation files.
+	out io.Writer
+	// authors enables writing the profiles of the message authors to the
+	// users file, supported by the JSON format.
+	authors bool
 }
 
 func (p *dumpparams) validate() error {
@@ -155,28 +272,33 @@ func dump(ctx context.Context, sess *slackdump.Session, fsa fsadapter.FS, p dump
 	ctx, task := trace.NewTask(ctx, "dump")
 	defer task.End()
 
-	if fsa == nil {
-		return errors.New("no filesystem adapter")
-	}
 	if p.list.IsEmpty() {
 		return ErrNothingToDo
 	}
 	if err := p.validate(); err != nil {
 		return err
 	}
-
-	dir, err := os.MkdirTemp("", "slackdump-*")
-	if err != nil {
-		return fmt.Errorf("failed to create temp directory: %w", err)
+	if isStreaming(p.format) && p.out != nil {
+		enc, err := lineEncoderFor(ctx, sess, p.format, p.rfc3339, p.out)
+		if err != nil {
+			return err
+		}
+		return streamLines(ctx, sess, p, newLineProcessor(writerOpener(p.out), enc, fileproc.NewDumpSubproc(fileproc.NoopDownloader{}), nil), nil)
+	}
+	if fsa == nil {
+		return errors.New("no filesystem adapter")
 	}
 
 	lg := cfg.Log
-	lg.Debug("using directory", "dir", dir)
+
+	// the report is written after the downloader is stopped.
+	rep := errreport.New()
+	defer bootstrap.FinishReport(ctx, fsa, rep)
 
 	// files subprocessor
 	var sdl fileproc.Downloader
 	if p.downloadFiles {
-		dl := downloader.New(sess.Client(), fsa, downloader.WithLogger(lg))
+		dl := downloader.New(sess.Client(), fsa, append(sess.DownloadOptions(), downloader.WithLogger(lg), downloader.WithErrorFunc(rep.DownloadError))...)
 		if err := dl.Start(ctx); err != nil {
 			return err
 		}
@@ -187,6 +309,22 @@ func dump(ctx context.Context, sess *slackdump.Session, fsa fsadapter.FS, p dump
 	}
 
 	subproc := fileproc.NewDumpSubproc(sdl)
+	if p.fileTmpl != nil {
+		subproc = fileproc.NewSubprocessor(sdl, fileproc.TemplateFilepath(p.fileTmpl))
+	}
+
+	if isStreaming(p.format) {
+		enc, err := lineEncoderFor(ctx, sess, p.format, p.rfc3339, nil)
+		if err != nil {
+			return err
+		}
+		var update func(channelID, threadTS string, mm []slack.Message) error
+		if p.updatePath && p.downloadFiles {
+			update = subproc.PathUpdateFunc
+		}
+		convfs := newCompressFS(fsa, p.compress, formatExt(p.format))
+		return streamLines(ctx, sess, p, newLineProcessor(fsOpener(convfs, p.tmpl), enc, subproc, update), rep.StreamError)
+	}
 
 	opts := []transform.StdOption{
 		transform.StdWithTemplate(p.tmpl),
@@ -196,6 +334,12 @@ func dump(ctx context.Context, sess *slackdump.Session, fsa fsadapter.FS, p dump
 		opts = append(opts, transform.StdWithPipeline(subproc.PathUpdateFunc))
 	}
 
+	dir, err := os.MkdirTemp("", "slackdump-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	lg.Debug("using directory", "dir", dir)
+
 	// Initialise the standard transformer.
 	cd, err := chunk.OpenDir(dir)
 	if err != nil {
@@ -203,7 +347,7 @@ func dump(ctx context.Context, sess *slackdump.Session, fsa fsadapter.FS, p dump
 	}
 	defer cd.Close()
 
-	tf, err := transform.NewStandard(fsa, cd, opts...)
+	tf, err := transform.NewStandard(newCompressFS(fsa, p.compress, formatExt(p.format)), cd, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to create transform: %w", err)
 	}
@@ -220,10 +364,22 @@ func dump(ctx context.Context, sess *slackdump.Session, fsa fsadapter.FS, p dump
 			lg.WarnContext(ctx, "failed to close conversation processor", "error", err)
 		}
 	}()
+	var (
+		mw = cfg.Middlewares()
+		ac *authorCollector
+	)
+	if p.authors {
+		mw = append(mw, func(next processor.Conversations) processor.Conversations {
+			ac = newAuthorCollector(next)
+			return ac
+		})
+	}
 
 	if err := sess.Stream(
 		stream.OptOldest(time.Time(cfg.Oldest)),
 		stream.OptLatest(time.Time(cfg.Latest)),
+		stream.OptChannelSplit(cfg.ChannelSplit),
+		stream.OptPins(cfg.Pins),
 		stream.OptResultFn(func(sr stream.Result) error {
 			if sr.Err != nil {
 				return sr.Err
@@ -233,7 +389,8 @@ func dump(ctx context.Context, sess *slackdump.Session, fsa fsadapter.FS, p dump
 			}
 			return nil
 		}),
-	).Conversations(ctx, proc, p.list.C(ctx)); err != nil {
+		stream.OptErrorFn(rep.StreamError),
+	).Conversations(ctx, proc, p.list.C(ctx), mw...); err != nil {
 		return fmt.Errorf("failed to dump conversations: %w", err)
 	}
 
@@ -241,10 +398,73 @@ func dump(ctx context.Context, sess *slackdump.Session, fsa fsadapter.FS, p dump
 	if err := coord.Wait(); err != nil {
 		return err
 	}
+	if ac != nil {
+		users, err := sess.GetUsers(ctx)
+		if err != nil {
+			return fmt.Errorf("error fetching users: %w", err)
+		}
+		if err := ac.write(fsa, users); err != nil {
+			return fmt.Errorf("error writing the authors: %w", err)
+		}
+	}
 
 	return nil
 }
 
+// formatExt returns the file extension of the output format.
+func formatExt(format string) string {
+	switch format {
+	case fmtJSONL:
+		return ".jsonl"
+	case fmtCSV:
+		return ".csv"
+	default:
+		return ".json"
+	}
+}
+
+// isStreaming returns true if the messages of the format are written as they
+// are fetched.
+func isStreaming(format string) bool {
+	return format == fmtJSONL || format == fmtCSV
+}
+
+// lineEncoderFor returns the encoderFunc of the streaming format.  The CSV
+// rows need the users to resolve the user IDs, they are fetched from the
+// cache or the API.  If rfc3339 is true, the RFC 3339 times are added next to
+// the timestamps.  shared is the output of all conversations, if set.
+func lineEncoderFor(ctx context.Context, sess *slackdump.Session, fmtName string, rfc3339 bool, shared io.Writer) (encoderFunc, error) {
+	if fmtName != fmtCSV {
+		if rfc3339 {
+			return jsonEncoderRFC3339, nil
+		}
+		return jsonEncoder, nil
+	}
+	users, err := sess.GetUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching users: %w", err)
+	}
+	return csvEncoder(users, shared, format.WithRFC3339(rfc3339)), nil
+}
+
+// streamLines streams the conversations in the list to the line processor
+// proc.  errFn is called for the failed conversations, if it is
+// nil, any failure aborts the dump.
+func streamLines(ctx context.Context, sess *slackdump.Session, p dumpparams, proc *lineProcessor, errFn func(stream.Result) error) error {
+	sopts := []stream.Option{
+		stream.OptOldest(time.Time(cfg.Oldest)),
+		stream.OptLatest(time.Time(cfg.Latest)),
+		stream.OptChannelSplit(cfg.ChannelSplit),
+	}
+	if errFn != nil {
+		sopts = append(sopts, stream.OptErrorFn(errFn))
+	}
+	if err := sess.Stream(sopts...).Conversations(ctx, proc, p.list.C(ctx), cfg.Middlewares()...); err != nil {
+		return errors.Join(fmt.Errorf("failed to dump conversations: %w", err), proc.Close())
+	}
+	return proc.Close()
+}
+
 var helpTmpl = template.Must(template.New("dumphelp").Parse(dumpMd))
 
 // helpDump returns the help message for the dump command.
@@ -1 +1,37 @@
 package dump
+
+import (
+	"testing"
+
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+func Test_threadLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		channel  string
+		threadTS string
+		want     structures.SlackLink
+		wantErr  bool
+	}{
+		{"slack timestamp", "C051D4052", "1665917454.731419", structures.SlackLink{Channel: "C051D4052", ThreadTS: "1665917454.731419"}, false},
+		{"thread ID", "C051D4052", "p1665917454731419", structures.SlackLink{Channel: "C051D4052", ThreadTS: "1665917454.731419"}, false},
+		{"channel URL", "https://ora600.slack.com/archives/C051D4052", "1665917454.731419", structures.SlackLink{Channel: "C051D4052", ThreadTS: "1665917454.731419"}, false},
+		{"thread URL instead of channel", "https://ora600.slack.com/archives/C051D4052/p1665917454731419", "1665917454.731419", structures.SlackLink{}, true},
+		{"invalid channel", "#general", "1665917454.731419", structures.SlackLink{}, true},
+		{"invalid timestamp", "C051D4052", "yesterday", structures.SlackLink{}, true},
+		{"empty timestamp", "C051D4052", "", structures.SlackLink{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := threadLink(tt.channel, tt.threadTS)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("threadLink() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("threadLink() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package dump
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/format"
+	"github.com/rusq/slackdump/v3/internal/nametmpl"
+	"github.com/rusq/slackdump/v3/internal/structures"
+	"github.com/rusq/slackdump/v3/processor"
+	"github.com/rusq/slackdump/v3/types"
+)
+
+var _ processor.Conversations = (*lineProcessor)(nil)
+
+// lineProcessor is the conversation processor, that writes each message as
+// a line, i.e. a JSON line or a CSV row, as soon as it is fetched, instead of
+// collecting the whole conversation in memory.  The channel ID is set on each
+// message, so that the lines of different conversations can be told apart, if
+// they share the output.  Thread replies follow the pages of the channel
+// messages, as they are fetched.
+type lineProcessor struct {
+	processor.Filer
+	// open opens the output of the conversation.
+	open openFunc
+	// encoder returns the encoder of the conversation output.
+	encoder encoderFunc
+	// update is called on each message chunk before it is written, may be
+	// nil.
+	update func(channelID, threadTS string, mm []slack.Message) error
+
+	mu       sync.Mutex
+	channels map[string]*slack.Channel // channel info by ID.
+	convs    map[chunk.FileID]*lineConv
+}
+
+// openFunc opens the output of the conversation ch, threadTS is set for the
+// threads, requested directly.
+type openFunc func(ch *slack.Channel, threadTS string) (io.WriteCloser, error)
+
+// lineEncoder writes the message to the conversation output.
+type lineEncoder interface {
+	Encode(m *slack.Message) error
+}
+
+// encoderFunc returns the encoder of the conversation ch, that writes to w.
+type encoderFunc func(w io.Writer, ch *slack.Channel) lineEncoder
+
+// jsonEncoder is the encoderFunc, that writes the messages as JSON lines.
+func jsonEncoder(w io.Writer, _ *slack.Channel) lineEncoder {
+	return jsonLines{enc: json.NewEncoder(w)}
+}
+
+// jsonEncoderRFC3339 is the jsonEncoder, that adds the RFC 3339 times of the
+// message and thread timestamps to each message.
+func jsonEncoderRFC3339(w io.Writer, _ *slack.Channel) lineEncoder {
+	return jsonLines{enc: json.NewEncoder(w), rfc3339: true}
+}
+
+type jsonLines struct {
+	enc     *json.Encoder
+	rfc3339 bool
+}
+
+// rfc3339Message is the message with the RFC 3339 times.
+type rfc3339Message struct {
+	*slack.Message
+	TSRFC3339       string `json:"ts_rfc3339,omitempty"`
+	ThreadTSRFC3339 string `json:"thread_ts_rfc3339,omitempty"`
+}
+
+func (j jsonLines) Encode(m *slack.Message) error {
+	if j.rfc3339 {
+		return j.enc.Encode(rfc3339Message{
+			Message:         m,
+			TSRFC3339:       structures.SlackTSToRFC3339(m.Timestamp),
+			ThreadTSRFC3339: structures.SlackTSToRFC3339(m.ThreadTimestamp),
+		})
+	}
+	return j.enc.Encode(m)
+}
+
+// csvEncoder returns the encoderFunc, that writes the messages as CSV rows,
+// users are used to resolve the user IDs.  If shared is not nil, the rows
+// of all conversations are written to it, and the header is written once,
+// otherwise each conversation output gets its own header.
+func csvEncoder(users []slack.User, shared io.Writer, opts ...format.Option) encoderFunc {
+	var rows *format.MessageRows
+	if shared != nil {
+		rows = format.NewMessageRows(shared, users, opts...)
+	}
+	return func(w io.Writer, ch *slack.Channel) lineEncoder {
+		r := rows
+		if r == nil {
+			r = format.NewMessageRows(w, users, opts...)
+		}
+		return csvRows{rows: r, channel: format.NVL(ch.Name, ch.ID)}
+	}
+}
+
+type csvRows struct {
+	rows    *format.MessageRows
+	channel string
+}
+
+func (c csvRows) Encode(m *slack.Message) error {
+	return c.rows.Write(c.channel, *m)
+}
+
+// lineConv is the open conversation output.
+type lineConv struct {
+	wc  io.WriteCloser
+	enc lineEncoder
+	// refs is the number of the outstanding parts of the conversation: the
+	// channel messages, until the last page is received, and each of the
+	// threads.  The output is closed once it drops to zero.
+	refs int
+}
+
+// newLineProcessor returns the processor, that writes the conversations to
+// the outputs opened with open, encoded with the encoder returned by enc.
+// Files are passed to filer.
+func newLineProcessor(open openFunc, enc encoderFunc, filer processor.Filer, update func(channelID, threadTS string, mm []slack.Message) error) *lineProcessor {
+	return &lineProcessor{
+		Filer:    filer,
+		open:     open,
+		encoder:  enc,
+		update:   update,
+		channels: make(map[string]*slack.Channel),
+		convs:    make(map[chunk.FileID]*lineConv),
+	}
+}
+
+// fsOpener returns the openFunc, that creates the conversation file on fsa,
+// named with the template tmpl.
+func fsOpener(fsa fsadapter.FS, tmpl *nametmpl.Template) openFunc {
+	return func(ch *slack.Channel, threadTS string) (io.WriteCloser, error) {
+		return fsa.Create(tmpl.Execute(&types.Conversation{ID: ch.ID, Name: ch.Name, ThreadTS: threadTS}))
+	}
+}
+
+// writerOpener returns the openFunc, that writes all conversations to w.  w
+// is not closed.
+func writerOpener(w io.Writer) openFunc {
+	var mu sync.Mutex // serialises the writes of the encoders.
+	return func(*slack.Channel, string) (io.WriteCloser, error) {
+		return &syncWriter{w: w, mu: &mu}, nil
+	}
+}
+
+// syncWriter writes to the shared writer under the lock, Close is a no-op.
+type syncWriter struct {
+	w  io.Writer
+	mu *sync.Mutex
+}
+
+func (sw *syncWriter) Write(p []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	return sw.w.Write(p)
+}
+
+func (sw *syncWriter) Close() error { return nil }
+
+func (p *lineProcessor) ChannelInfo(ctx context.Context, ci *slack.Channel, threadID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.channels[ci.ID] = ci
+	return nil
+}
+
+func (p *lineProcessor) ChannelUsers(ctx context.Context, channelID string, threadTS string, users []string) error {
+	return nil
+}
+
+func (p *lineProcessor) Messages(ctx context.Context, channelID string, numThreads int, isLast bool, mm []slack.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := chunk.ToFileID(channelID, "", false)
+	cv, err := p.conv(id, channelID, "")
+	if err != nil {
+		return err
+	}
+	if err := p.write(cv, channelID, "", mm); err != nil {
+		return err
+	}
+	cv.refs += numThreads
+	if isLast {
+		return p.release(id, cv)
+	}
+	return nil
+}
+
+func (p *lineProcessor) ThreadMessages(ctx context.Context, channelID string, parent slack.Message, threadOnly, isLast bool, replies []slack.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var (
+		id       = chunk.ToFileID(channelID, "", false)
+		threadTS = parent.ThreadTimestamp
+	)
+	if threadOnly {
+		id = chunk.ToFileID(channelID, threadTS, false)
+		_, started := p.convs[id]
+		cv, err := p.conv(id, channelID, threadTS)
+		if err != nil {
+			return err
+		}
+		if !started {
+			// the parent message is not fetched with the channel, it is
+			// written once, before the first page of replies.
+			if err := p.write(cv, channelID, threadTS, []slack.Message{parent}); err != nil {
+				return err
+			}
+		}
+		if err := p.write(cv, channelID, threadTS, replies); err != nil {
+			return err
+		}
+		if isLast {
+			return p.release(id, cv)
+		}
+		return nil
+	}
+	cv, err := p.conv(id, channelID, "")
+	if err != nil {
+		return err
+	}
+	if err := p.write(cv, channelID, threadTS, replies); err != nil {
+		return err
+	}
+	if isLast {
+		return p.release(id, cv)
+	}
+	return nil
+}
+
+// conv returns the conversation output with the id, opening it, if it's not
+// open yet.  It must be called with the lock held.
+func (p *lineProcessor) conv(id chunk.FileID, channelID, threadTS string) (*lineConv, error) {
+	if cv, ok := p.convs[id]; ok {
+		return cv, nil
+	}
+	ch, ok := p.channels[channelID]
+	if !ok {
+		ch = &slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: channelID}}}
+	}
+	wc, err := p.open(ch, threadTS)
+	if err != nil {
+		return nil, err
+	}
+	cv := &lineConv{wc: wc, enc: p.encoder(wc, ch), refs: 1}
+	p.convs[id] = cv
+	return cv, nil
+}
+
+// write writes the messages mm to the conversation output.
+func (p *lineProcessor) write(cv *lineConv, channelID, threadTS string, mm []slack.Message) error {
+	if p.update != nil {
+		if err := p.update(channelID, threadTS, mm); err != nil {
+			return err
+		}
+	}
+	for i := range mm {
+		if mm[i].Channel == "" {
+			mm[i].Channel = channelID
+		}
+		if err := cv.enc.Encode(&mm[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// release decrements the reference count of the conversation, and closes it
+// once it is complete.  It must be called with the lock held.
+func (p *lineProcessor) release(id chunk.FileID, cv *lineConv) error {
+	if cv.refs--; cv.refs > 0 {
+		return nil
+	}
+	delete(p.convs, id)
+	return cv.wc.Close()
+}
+
+// Close closes the conversation outputs, that are still open, i.e. if some of
+// the threads had no replies within the requested time range.
+func (p *lineProcessor) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var errs error
+	for id, cv := range p.convs {
+		errs = errors.Join(errs, cv.wc.Close())
+		delete(p.convs, id)
+	}
+	return errs
+}
@@ -0,0 +1,180 @@
+package dump
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/chunk/transform/fileproc"
+)
+
+// bufCloser is the buffer, that records if it was closed.
+type bufCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *bufCloser) Close() error {
+	b.closed = true
+	return nil
+}
+
+// lines decodes the JSON lines in the buffer.
+func (b *bufCloser) lines(t *testing.T) []slack.Message {
+	t.Helper()
+	var mm []slack.Message
+	dec := json.NewDecoder(&b.Buffer)
+	for {
+		var m slack.Message
+		if err := dec.Decode(&m); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		mm = append(mm, m)
+	}
+	return mm
+}
+
+// bufOpener returns the openFunc, that opens the buffers, named after the
+// channel ID and thread timestamp.
+func bufOpener(bufs map[string]*bufCloser) openFunc {
+	return func(ch *slack.Channel, threadTS string) (io.WriteCloser, error) {
+		b := new(bufCloser)
+		bufs[strings.TrimSuffix(ch.ID+":"+threadTS, ":")] = b
+		return b, nil
+	}
+}
+
+func msg(ts, threadTS string) slack.Message {
+	return slack.Message{Msg: slack.Msg{Timestamp: ts, ThreadTimestamp: threadTS}}
+}
+
+func timestamps(mm []slack.Message) []string {
+	var ts []string
+	for _, m := range mm {
+		ts = append(ts, m.Timestamp)
+	}
+	return ts
+}
+
+func TestLineProcessor_conversation(t *testing.T) {
+	ctx := context.Background()
+	bufs := make(map[string]*bufCloser)
+	p := newLineProcessor(bufOpener(bufs), jsonEncoder, fileproc.NewDumpSubproc(fileproc.NoopDownloader{}), nil)
+
+	parent := msg("1.0", "1.0")
+	require.NoError(t, p.Messages(ctx, "C1", 1, false, []slack.Message{parent}))
+	require.NoError(t, p.Messages(ctx, "C1", 0, true, []slack.Message{msg("2.0", "")}))
+	b := bufs["C1"]
+	require.NotNil(t, b)
+	assert.False(t, b.closed, "closed before the thread is complete")
+
+	require.NoError(t, p.ThreadMessages(ctx, "C1", parent, false, false, []slack.Message{msg("1.1", "1.0")}))
+	require.NoError(t, p.ThreadMessages(ctx, "C1", parent, false, true, []slack.Message{msg("1.2", "1.0")}))
+	assert.True(t, b.closed, "not closed after the last thread")
+	assert.Empty(t, p.convs)
+
+	mm := b.lines(t)
+	assert.Equal(t, []string{"1.0", "2.0", "1.1", "1.2"}, timestamps(mm))
+	for _, m := range mm {
+		assert.Equal(t, "C1", m.Channel)
+	}
+	assert.NoError(t, p.Close())
+}
+
+func TestLineProcessor_threadOnly(t *testing.T) {
+	ctx := context.Background()
+	bufs := make(map[string]*bufCloser)
+	var updated []string
+	update := func(channelID, threadTS string, mm []slack.Message) error {
+		updated = append(updated, timestamps(mm)...)
+		return nil
+	}
+	p := newLineProcessor(bufOpener(bufs), jsonEncoder, fileproc.NewDumpSubproc(fileproc.NoopDownloader{}), update)
+
+	ch := &slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}}
+	require.NoError(t, p.ChannelInfo(ctx, ch, "1.0"))
+	parent := msg("1.0", "1.0")
+	require.NoError(t, p.ThreadMessages(ctx, "C1", parent, true, false, []slack.Message{msg("1.1", "1.0")}))
+	require.NoError(t, p.ThreadMessages(ctx, "C1", parent, true, true, []slack.Message{msg("1.2", "1.0")}))
+
+	b := bufs["C1:1.0"]
+	require.NotNil(t, b)
+	assert.True(t, b.closed)
+	assert.Equal(t, []string{"1.0", "1.1", "1.2"}, timestamps(b.lines(t)))
+	assert.Equal(t, []string{"1.0", "1.1", "1.2"}, updated)
+}
+
+func TestLineProcessor_Close(t *testing.T) {
+	ctx := context.Background()
+	bufs := make(map[string]*bufCloser)
+	p := newLineProcessor(bufOpener(bufs), jsonEncoder, fileproc.NewDumpSubproc(fileproc.NoopDownloader{}), nil)
+
+	// the thread with no replies is never reported.
+	require.NoError(t, p.Messages(ctx, "C1", 1, true, []slack.Message{msg("1.0", "1.0")}))
+	assert.False(t, bufs["C1"].closed)
+	require.NoError(t, p.Close())
+	assert.True(t, bufs["C1"].closed)
+}
+
+func Test_writerOpener(t *testing.T) {
+	var buf bytes.Buffer
+	open := writerOpener(&buf)
+	ch := &slack.Channel{}
+	w1, err := open(ch, "")
+	require.NoError(t, err)
+	w2, err := open(ch, "1.0")
+	require.NoError(t, err)
+	_, _ = io.WriteString(w1, "a\n")
+	_, _ = io.WriteString(w2, "b\n")
+	require.NoError(t, w1.Close())
+	require.NoError(t, w2.Close())
+	assert.Equal(t, "a\nb\n", buf.String())
+}
+
+func Test_csvEncoder(t *testing.T) {
+	ctx := context.Background()
+	users := []slack.User{{ID: "U1", Name: "bob"}}
+	general := &slack.Channel{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1"}}}
+	m := msg("1.0", "")
+	m.User = "U1"
+
+	t.Run("file per conversation", func(t *testing.T) {
+		bufs := make(map[string]*bufCloser)
+		p := newLineProcessor(bufOpener(bufs), csvEncoder(users, nil), fileproc.NewDumpSubproc(fileproc.NoopDownloader{}), nil)
+		require.NoError(t, p.ChannelInfo(ctx, general, ""))
+		require.NoError(t, p.Messages(ctx, "C1", 0, true, []slack.Message{m}))
+		require.NoError(t, p.Messages(ctx, "C2", 0, true, []slack.Message{m}))
+		header := "ts,channel,user,thread_ts,text,reaction_count,file_count\n"
+		assert.Equal(t, header+"1.0,general,bob,,,0,0\n", bufs["C1"].String())
+		assert.Equal(t, header+"1.0,C2,bob,,,0,0\n", bufs["C2"].String())
+	})
+	t.Run("shared output", func(t *testing.T) {
+		var buf bytes.Buffer
+		p := newLineProcessor(writerOpener(&buf), csvEncoder(users, &buf), fileproc.NewDumpSubproc(fileproc.NoopDownloader{}), nil)
+		require.NoError(t, p.ChannelInfo(ctx, general, ""))
+		require.NoError(t, p.Messages(ctx, "C1", 0, true, []slack.Message{m}))
+		require.NoError(t, p.Messages(ctx, "C2", 0, true, []slack.Message{m}))
+		assert.Equal(t, "ts,channel,user,thread_ts,text,reaction_count,file_count\n1.0,general,bob,,,0,0\n1.0,C2,bob,,,0,0\n", buf.String())
+	})
+}
+
+func TestJSONEncoderRFC3339(t *testing.T) {
+	var buf bytes.Buffer
+	m := msg("1700000001.000200", "1700000000.000100")
+	require.NoError(t, jsonEncoderRFC3339(&buf, &slack.Channel{}).Encode(&m))
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "1700000001.000200", got["ts"])
+	assert.Equal(t, "2023-11-14T22:13:21.000200Z", got["ts_rfc3339"])
+	assert.Equal(t, "2023-11-14T22:13:20.000100Z", got["thread_ts_rfc3339"])
+}
@@ -0,0 +1,354 @@
+package dump
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/fasttime"
+	"github.com/rusq/slackdump/v3/internal/structures"
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+var cmdDumpMessage = &base.Command{
+	UsageLine: "slackdump dump message [flags] <permalink>",
+	Short:     "dump a single message with the surrounding messages",
+	Long: `
+# Dump Message Command
+
+Dumps the message with the ` + "`-context`" + ` number of messages before and
+after it, the thread of the message, and, if the ` + "`-files`" + ` flag is
+set, the file attachments.  The profiles of the message authors are written
+to ` + "`" + authorsFile + "`" + `.  It is useful to preserve a specific exchange.
+
+The ` + "`<permalink>`" + ` is the link to the message, as copied with "Copy
+link" in Slack, or the channel ID and the message timestamp in the colon
+notation, i.e. ` + "`C051D4052:1665917454.731419`" + `.  If the message is a
+thread reply, the context is taken around the thread parent message.
+
+Example:
+
+    slackdump dump message -context 10 \
+      https://ora600.slack.com/archives/C051D4052/p1665917454731419
+`,
+	RequireAuth: true,
+	PrintFlags:  true,
+	FlagMask:    cfg.OmitMemberOnlyFlag,
+}
+
+// authorsFile is the name of the file with the profiles of the message
+// authors.
+const authorsFile = "users.json"
+
+// defContext is the default number of the context messages.
+const defContext = 20
+
+var msgContext int
+
+func init() {
+	cmdDumpMessage.Run = runDumpMessage
+	cmdDumpMessage.Flag.IntVar(&msgContext, "context", defContext, "number of `messages` before and after the target message to include")
+}
+
+// ErrMessageNotFound is returned if the target message does not exist.
+var ErrMessageNotFound = errors.New("message not found")
+
+func runDumpMessage(ctx context.Context, _ *base.Command, args []string) error {
+	if len(args) != 1 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("message link is required, run \"slackdump help dump message\"")
+	}
+	if msgContext < 0 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("-context must not be negative")
+	}
+	if opts.format != fmtJSON && opts.format != "" {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("dump message supports only the json format")
+	}
+	ref, err := parseMessageLink(args[0])
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	return runDumpList(ctx, func(ctx context.Context, sess *slackdump.Session) (*structures.EntityList, error) {
+		return messageList(ctx, sess.Client(), ref, msgContext)
+	}, true)
+}
+
+// messageRef is the reference to the message.
+type messageRef struct {
+	Channel string
+	TS      string // message timestamp
+	// ThreadTS is the timestamp of the thread parent, if the message is a
+	// thread reply.
+	ThreadTS string
+}
+
+// anchor returns the timestamp of the channel message, around which the
+// context is taken.
+func (r messageRef) anchor() string {
+	if r.ThreadTS != "" {
+		return r.ThreadTS
+	}
+	return r.TS
+}
+
+// parseMessageLink parses the message permalink, or the link in the colon
+// notation.  The permalink of the thread reply has the thread_ts query
+// parameter.
+func parseMessageLink(link string) (messageRef, error) {
+	var threadTS string
+	if structures.IsURL(link) {
+		u, err := url.Parse(link)
+		if err != nil {
+			return messageRef{}, fmt.Errorf("invalid message link: %w", err)
+		}
+		threadTS = u.Query().Get("thread_ts")
+		u.RawQuery, u.Fragment = "", ""
+		link = u.String()
+	}
+	sl, err := structures.ParseLink(link)
+	if err != nil {
+		return messageRef{}, fmt.Errorf("invalid message link: %w", err)
+	}
+	if !sl.IsThread() {
+		return messageRef{}, fmt.Errorf("expected the message link, got channel: %s", link)
+	}
+	ref := messageRef{Channel: sl.Channel, TS: sl.ThreadTS}
+	if threadTS != "" && threadTS != ref.TS {
+		if _, err := structures.ParseSlackTS(threadTS); err != nil {
+			return messageRef{}, fmt.Errorf("invalid thread timestamp %q: %w", threadTS, err)
+		}
+		ref.ThreadTS = threadTS
+	}
+	return ref, nil
+}
+
+// historian is the subset of the Slack API, that fetches the channel
+// history.
+type historian interface {
+	GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error)
+}
+
+// afterWindows are the time windows after the target message, where the
+// messages are looked up, until enough are found.  Zero means no limit.
+var afterWindows = []time.Duration{
+	time.Hour,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+	365 * 24 * time.Hour,
+	0,
+}
+
+// messageList returns the entity list, that covers n messages before and
+// after the referenced message, and the thread of the message.
+func messageList(ctx context.Context, cl historian, ref messageRef, n int) (*structures.EntityList, error) {
+	anchor := ref.anchor()
+	target, oldest, err := contextBefore(ctx, cl, ref.Channel, anchor, n)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := contextAfter(ctx, cl, ref.Channel, anchor, n)
+	if err != nil {
+		return nil, err
+	}
+	from, err := structures.ParseSlackTS(oldest)
+	if err != nil {
+		return nil, err
+	}
+	to, err := structures.ParseSlackTS(latest)
+	if err != nil {
+		return nil, err
+	}
+	// the range has the second precision.
+	items := []string{(&structures.EntityItem{
+		Id:      ref.Channel,
+		Oldest:  from.UTC().Truncate(time.Second),
+		Latest:  to.UTC().Truncate(time.Second).Add(time.Second),
+		Include: true,
+	}).String()}
+	// the thread replies within the channel are limited to the time range,
+	// so the thread of the message is requested separately.
+	if ref.ThreadTS != "" || target.ReplyCount > 0 {
+		items = append(items, structures.SlackLink{Channel: ref.Channel, ThreadTS: anchor}.String())
+	}
+	return structures.NewEntityList(items)
+}
+
+// contextBefore returns the message with the timestamp ts, and the timestamp
+// of the n-th message before it.
+func contextBefore(ctx context.Context, cl historian, channelID, ts string, n int) (*slack.Message, string, error) {
+	resp, err := cl.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+		ChannelID: channelID,
+		Latest:    ts,
+		Inclusive: true,
+		Limit:     n + 1,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if !resp.Ok {
+		return nil, "", fmt.Errorf("response not ok, slack error: %s", resp.Error)
+	}
+	var target *slack.Message
+	oldest := ts
+	for i := range resp.Messages {
+		if resp.Messages[i].Timestamp == ts {
+			target = &resp.Messages[i]
+		}
+		if tsLess(resp.Messages[i].Timestamp, oldest) {
+			oldest = resp.Messages[i].Timestamp
+		}
+	}
+	if target == nil {
+		return nil, "", fmt.Errorf("%w: %s:%s", ErrMessageNotFound, channelID, ts)
+	}
+	return target, oldest, nil
+}
+
+// contextAfter returns the timestamp of the n-th message after ts, or ts, if
+// there are no messages after it.  The messages are looked up in the growing
+// time windows, so that the result does not depend on the order, in which
+// the API returns the pages.
+func contextAfter(ctx context.Context, cl historian, channelID, ts string, n int) (string, error) {
+	if n == 0 {
+		return ts, nil
+	}
+	start, err := structures.ParseSlackTS(ts)
+	if err != nil {
+		return "", err
+	}
+	for _, w := range afterWindows {
+		var latest string
+		if w > 0 {
+			latest = structures.FormatSlackTS(start.Add(w))
+		}
+		tss, err := historyTS(ctx, cl, channelID, ts, latest)
+		if err != nil {
+			return "", err
+		}
+		if len(tss) >= n || w == 0 || start.Add(w).After(time.Now()) {
+			if len(tss) == 0 {
+				return ts, nil
+			}
+			sort.Slice(tss, func(i, j int) bool { return tsLess(tss[i], tss[j]) })
+			return tss[min(n, len(tss))-1], nil
+		}
+	}
+	return ts, nil
+}
+
+// historyTS returns the timestamps of all messages in the channel after
+// oldest and up to latest, empty latest means now.
+func historyTS(ctx context.Context, cl historian, channelID, oldest, latest string) ([]string, error) {
+	var (
+		tss    []string
+		cursor string
+	)
+	for {
+		resp, err := cl.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+			ChannelID: channelID,
+			Cursor:    cursor,
+			Oldest:    oldest,
+			Latest:    latest,
+			Limit:     200,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !resp.Ok {
+			return nil, fmt.Errorf("response not ok, slack error: %s", resp.Error)
+		}
+		for _, m := range resp.Messages {
+			if m.Timestamp != oldest {
+				tss = append(tss, m.Timestamp)
+			}
+		}
+		if !resp.HasMore || resp.ResponseMetaData.NextCursor == "" {
+			return tss, nil
+		}
+		cursor = resp.ResponseMetaData.NextCursor
+	}
+}
+
+// tsLess returns true if the Slack timestamp a is before b.
+func tsLess(a, b string) bool {
+	ai, err1 := fasttime.TS2int(a)
+	bi, err2 := fasttime.TS2int(b)
+	if err1 != nil || err2 != nil {
+		return a < b
+	}
+	return ai < bi
+}
+
+// authorCollector is the conversation processor middleware, that collects
+// the IDs of the message authors, and passes the messages to the underlying
+// processor.
+type authorCollector struct {
+	processor.Passthrough
+
+	mu  sync.Mutex
+	ids map[string]struct{}
+}
+
+func newAuthorCollector(p processor.Conversations) *authorCollector {
+	return &authorCollector{Passthrough: processor.Passthrough{Conversations: p}, ids: make(map[string]struct{})}
+}
+
+func (a *authorCollector) add(mm ...slack.Message) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i := range mm {
+		if mm[i].User != "" {
+			a.ids[mm[i].User] = struct{}{}
+		}
+	}
+}
+
+func (a *authorCollector) Messages(ctx context.Context, channelID string, numThreads int, isLast bool, mm []slack.Message) error {
+	a.add(mm...)
+	return a.Conversations.Messages(ctx, channelID, numThreads, isLast, mm)
+}
+
+func (a *authorCollector) ThreadMessages(ctx context.Context, channelID string, parent slack.Message, threadOnly, isLast bool, replies []slack.Message) error {
+	a.add(parent)
+	a.add(replies...)
+	return a.Conversations.ThreadMessages(ctx, channelID, parent, threadOnly, isLast, replies)
+}
+
+// write writes the profiles of the collected authors, found in users, to the
+// authors file.
+func (a *authorCollector) write(fsa fsadapter.FS, users []slack.User) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	authors := make([]slack.User, 0, len(a.ids))
+	for _, u := range users {
+		if _, ok := a.ids[u.ID]; ok {
+			authors = append(authors, u)
+		}
+	}
+	wc, err := fsa.Create(authorsFile)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(wc)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(authors); err != nil {
+		wc.Close()
+		return err
+	}
+	return wc.Close()
+}
@@ -0,0 +1,174 @@
+package dump
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/structures"
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+func Test_parseMessageLink(t *testing.T) {
+	tests := []struct {
+		name    string
+		link    string
+		want    messageRef
+		wantErr bool
+	}{
+		{
+			name: "permalink",
+			link: "https://ora600.slack.com/archives/C051D4052/p1665917454731419",
+			want: messageRef{Channel: "C051D4052", TS: "1665917454.731419"},
+		},
+		{
+			name: "thread reply permalink",
+			link: "https://ora600.slack.com/archives/C051D4052/p1665917454731419?thread_ts=1665917400.000100&cid=C051D4052",
+			want: messageRef{Channel: "C051D4052", TS: "1665917454.731419", ThreadTS: "1665917400.000100"},
+		},
+		{
+			name: "thread parent permalink",
+			link: "https://ora600.slack.com/archives/C051D4052/p1665917454731419?thread_ts=1665917454.731419&cid=C051D4052",
+			want: messageRef{Channel: "C051D4052", TS: "1665917454.731419"},
+		},
+		{
+			name: "colon notation",
+			link: "C051D4052:1665917454.731419",
+			want: messageRef{Channel: "C051D4052", TS: "1665917454.731419"},
+		},
+		{
+			name:    "channel link",
+			link:    "https://ora600.slack.com/archives/C051D4052",
+			wantErr: true,
+		},
+		{
+			name:    "channel ID",
+			link:    "C051D4052",
+			wantErr: true,
+		},
+		{
+			name:    "invalid thread_ts",
+			link:    "https://ora600.slack.com/archives/C051D4052/p1665917454731419?thread_ts=x",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMessageLink(tt.link)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMessageLink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// fakeHistory is the channel history, messages are in the ascending order.
+type fakeHistory []slack.Message
+
+func (h fakeHistory) GetConversationHistoryContext(_ context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+	var mm []slack.Message
+	for i := len(h) - 1; i >= 0; i-- { // newest first, as the API does.
+		ts := h[i].Timestamp
+		if params.Latest != "" && (tsLess(params.Latest, ts) || (!params.Inclusive && ts == params.Latest)) {
+			continue
+		}
+		if params.Oldest != "" && (tsLess(ts, params.Oldest) || (!params.Inclusive && ts == params.Oldest)) {
+			continue
+		}
+		mm = append(mm, h[i])
+	}
+	start := 0
+	if params.Cursor != "" {
+		start, _ = strconv.Atoi(params.Cursor)
+	}
+	mm = mm[start:]
+	resp := &slack.GetConversationHistoryResponse{SlackResponse: slack.SlackResponse{Ok: true}}
+	if len(mm) > params.Limit {
+		resp.HasMore = true
+		resp.ResponseMetaData.NextCursor = strconv.Itoa(start + params.Limit)
+		mm = mm[:params.Limit]
+	}
+	resp.Messages = mm
+	return resp, nil
+}
+
+func Test_messageList(t *testing.T) {
+	// ten messages, 10 minutes apart, the fifth one has replies.
+	var h fakeHistory
+	for i := range 10 {
+		m := slack.Message{Msg: slack.Msg{Timestamp: strconv.Itoa(1700000000+i*600) + ".000100"}}
+		if i == 4 {
+			m.ReplyCount = 2
+		}
+		h = append(h, m)
+	}
+	ctx := context.Background()
+	t.Run("message with the context", func(t *testing.T) {
+		l, err := messageList(ctx, h, messageRef{Channel: "C1", TS: h[6].Timestamp}, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"C1|2023-11-14T22:53:20|2023-11-14T23:33:21"}, includes(l))
+	})
+	t.Run("thread parent", func(t *testing.T) {
+		l, err := messageList(ctx, h, messageRef{Channel: "C1", TS: h[4].Timestamp}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"C1:1700002400.000100", "C1|2023-11-14T22:43:20|2023-11-14T23:03:21"}, includes(l))
+	})
+	t.Run("thread reply", func(t *testing.T) {
+		l, err := messageList(ctx, h, messageRef{Channel: "C1", TS: "1700002500.000100", ThreadTS: h[4].Timestamp}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"C1:1700002400.000100", "C1|2023-11-14T22:53:20|2023-11-14T22:53:21"}, includes(l))
+	})
+	t.Run("edges of the history", func(t *testing.T) {
+		l, err := messageList(ctx, h, messageRef{Channel: "C1", TS: h[9].Timestamp}, 20)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"C1|2023-11-14T22:13:20|2023-11-14T23:43:21"}, includes(l))
+	})
+	t.Run("message not found", func(t *testing.T) {
+		_, err := messageList(ctx, h, messageRef{Channel: "C1", TS: "1700000001.000100"}, 2)
+		assert.ErrorIs(t, err, ErrMessageNotFound)
+	})
+}
+
+func Test_authorCollector(t *testing.T) {
+	ctx := context.Background()
+	ac := newAuthorCollector(new(processor.Printer))
+	require.NoError(t, ac.Messages(ctx, "C1", 0, true, []slack.Message{
+		{Msg: slack.Msg{User: "U1", Timestamp: "1.1"}},
+		{Msg: slack.Msg{BotID: "B1", Timestamp: "1.2"}},
+	}))
+	require.NoError(t, ac.ThreadMessages(ctx, "C1", slack.Message{Msg: slack.Msg{User: "U2", Timestamp: "1.3"}}, true, true, []slack.Message{
+		{Msg: slack.Msg{User: "U3", Timestamp: "1.4"}},
+	}))
+
+	dir := t.TempDir()
+	users := []slack.User{{ID: "U1"}, {ID: "U2"}, {ID: "U3"}, {ID: "U4"}}
+	require.NoError(t, ac.write(fsadapter.NewDirectory(dir), users))
+
+	data, err := os.ReadFile(filepath.Join(dir, authorsFile))
+	require.NoError(t, err)
+	var got []slack.User
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, users[:3], got)
+}
+
+// includes returns the sorted included entities of the list.
+func includes(l *structures.EntityList) []string {
+	var ss []string
+	for _, it := range l.Index() {
+		if it.Include {
+			ss = append(ss, it.String())
+		}
+	}
+	sort.Strings(ss)
+	return ss
+}
@@ -0,0 +1,83 @@
+package dump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+var cmdDumpThread = &base.Command{
+	UsageLine: "slackdump dump thread [flags] <channel> <thread_ts>",
+	Short:     "dump a single thread with all replies",
+	Long: `
+# Dump Thread Command
+
+Dumps a single thread with all the replies, reactions and, if the ` + "`-files`" + `
+flag is set, the file attachments, without dumping the rest of the channel.
+
+The ` + "`<channel>`" + ` is the channel ID or URL, and the ` + "`<thread_ts>`" + ` is the
+timestamp of the thread parent message, either as a Slack timestamp, i.e.
+` + "`1665917454.731419`" + `, or as it appears in the message link, i.e.
+` + "`p1665917454731419`" + `.
+
+Example:
+
+    slackdump dump thread -files C051D4052 1665917454.731419
+`,
+	RequireAuth: true,
+	PrintFlags:  true,
+	FlagMask:    cfg.OmitMemberOnlyFlag,
+}
+
+func init() {
+	cmdDumpThread.Run = runDumpThread
+}
+
+func runDumpThread(ctx context.Context, _ *base.Command, args []string) error {
+	if len(args) != 2 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("channel and thread timestamp are required, run \"slackdump help dump thread\"")
+	}
+	sl, err := threadLink(args[0], args[1])
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	list, err := structures.NewEntityList([]string{sl.String()})
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	return runDump(ctx, list)
+}
+
+// threadLink returns the link to the thread threadTS in the channel.  channel
+// can be the channel ID or URL, threadTS can be the Slack timestamp or the
+// thread ID from the message URL ("p" followed by digits).
+func threadLink(channel, threadTS string) (structures.SlackLink, error) {
+	sl, err := structures.ParseLink(channel)
+	if err != nil {
+		return structures.SlackLink{}, fmt.Errorf("invalid channel: %w", err)
+	}
+	if sl.IsThread() {
+		return structures.SlackLink{}, fmt.Errorf("expected channel, got thread: %s", channel)
+	}
+	parseFn := structures.ParseSlackTS
+	if strings.HasPrefix(threadTS, "p") {
+		parseFn = structures.ParseThreadID
+	}
+	ts, err := parseFn(threadTS)
+	if err != nil {
+		return structures.SlackLink{}, fmt.Errorf("invalid thread timestamp %q: %w", threadTS, err)
+	}
+	sl.ThreadTS = structures.FormatSlackTS(ts)
+	if !sl.IsThread() {
+		return structures.SlackLink{}, fmt.Errorf("invalid thread timestamp: %q", threadTS)
+	}
+	return sl, nil
+}
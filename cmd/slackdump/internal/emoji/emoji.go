@@ -19,6 +19,7 @@ import (
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/emoji/emojidl"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
 	"github.com/rusq/slackdump/v3/internal/edge"
+	"github.com/rusq/slackdump/v3/internal/remotefs"
 )
 
 //go:embed assets/emoji.md
@@ -35,23 +36,30 @@ var CmdEmoji = &base.Command{
 }
 
 type options struct {
-	ignoreErrors bool
+	ignoreErrors bool // deprecated, inverse of failFast
+	failFast     bool
 	full         bool
 }
 
+// isFailFast returns true, if the download should stop on the first error.
+func (o *options) isFailFast() bool {
+	return o.failFast || !o.ignoreErrors
+}
+
 // emoji specific flags
 var cmdFlags = options{
-	ignoreErrors: false,
+	ignoreErrors: true,
 }
 
 func init() {
 	CmdEmoji.Wizard = wizard
-	CmdEmoji.Flag.BoolVar(&cmdFlags.ignoreErrors, "ignore-errors", true, "ignore download errors (skip failed emojis)")
+	CmdEmoji.Flag.BoolVar(&cmdFlags.failFast, "fail-fast", false, "stop on the first download error, by default failed emojis are\nskipped and logged")
+	CmdEmoji.Flag.BoolVar(&cmdFlags.ignoreErrors, "ignore-errors", true, "deprecated, use -fail-fast")
 	CmdEmoji.Flag.BoolVar(&cmdFlags.full, "full", false, "fetch emojis using Edge API to get full emoji information, including usernames")
 }
 
 func run(ctx context.Context, cmd *base.Command, args []string) error {
-	fsa, err := fsadapter.New(cfg.Output)
+	fsa, err := remotefs.New(cfg.Output)
 	if err != nil {
 		return err
 	}
@@ -103,7 +111,7 @@ func runLegacy(ctx context.Context, fsa fsadapter.FS, cb emojidl.StatusFunc) err
 		return err
 	}
 
-	return emojidl.DlFS(ctx, sess, fsa, cmdFlags.ignoreErrors, cb)
+	return emojidl.DlFS(ctx, sess, fsa, cmdFlags.isFailFast(), cb)
 }
 
 func runEdge(ctx context.Context, fsa fsadapter.FS, prov auth.Provider, cb emojidl.StatusFunc) error {
@@ -114,7 +122,7 @@ func runEdge(ctx context.Context, fsa fsadapter.FS, prov auth.Provider, cb emoji
 	}
 	defer sess.Close()
 
-	if err := emojidl.DlEdgeFS(ctx, sess, fsa, cmdFlags.ignoreErrors, cb); err != nil {
+	if err := emojidl.DlEdgeFS(ctx, sess, fsa, cmdFlags.isFailFast(), cb); err != nil {
 		base.SetExitStatus(base.SApplicationError)
 		return fmt.Errorf("application error: %s", err)
 	}
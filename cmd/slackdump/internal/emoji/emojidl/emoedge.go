@@ -1,24 +1,7 @@
-// Package emojidl provides functions to dump the all slack emojis for a workspace.
-// It skips the "alias" emojis, so only original an emoji with an original name
-// is present. If you need to find the alias - lookup the index.json. The
-// directory structure is the following:
-//
-//	.
-//	+- emojis
-//	|  +- foo.png
-//	|  +- bar.png
-//	:  :
-//	|  +- baz.png
-//	+- index.json
-//
-// Where index.json contains the emoji index, and *.png files under emojis
-// directory are individual emojis.
 package emojidl
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"iter"
 	"sync"
@@ -37,17 +20,18 @@ type StatusFunc func(name string, total, count int)
 
 // DlEdgeFS downloads the emojis and saves them to the fsa. It spawns numWorker
 // goroutines for getting the files. It will call fetchFn for each emoji.
+// Once all emojis are processed, it writes the index.
 func DlEdgeFS(ctx context.Context, sess EdgeEmojiLister, fsa fsadapter.FS, failFast bool, cb StatusFunc) error {
 	lg := cfg.Log
 	lg.DebugContext(ctx, "startup params", "dir", emojiDir, "numWorkers", numWorkers, "failFast", failFast)
-	if cb == nil {
-		cb = func(name string, total, count int) {}
-	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	var (
 		emojiC  = make(chan edge.Emoji)
 		totalC  = make(chan int)
-		genErrC = make(chan error)
+		genErrC = make(chan error, 1) // buffered, so that generator doesn't block on error
 		resultC = make(chan result)
 	)
 
@@ -93,48 +77,34 @@ func DlEdgeFS(ctx context.Context, sess EdgeEmojiLister, fsa fsadapter.FS, failF
 
 	// 4. Result processor, receives download results and logs any errors that
 	//    may have occurred.
-	var (
-		count = 0
-		total = <-totalC // if there's a generator error, this will receive 0.
-	)
-	var emojis = make(map[string]edge.Emoji, total)
+	var total = <-totalC // if there's a generator error, this will receive 0.
+	c := newCollector(failFast, cb, total)
 LOOP:
 	for {
 		select {
 		case genErr := <-genErrC:
-			// generator error.
-			if genErr != nil {
-				return fmt.Errorf("failed to get emoji list: %w", genErr)
-			}
+			cancel()
+			drain(resultC)
+			return fmt.Errorf("failed to get emoji list: %w", genErr)
 		case res, more := <-resultC:
 			if !more {
 				break LOOP
 			}
-			lg := lg.With("name", res.emoji.Name)
-			if res.err != nil {
-				if errors.Is(res.err, context.Canceled) {
-					return res.err
-				}
-				if failFast {
-					return fmt.Errorf("failed: %q: %w", res.emoji.Name, res.err)
-				}
-				lg.WarnContext(ctx, "failed", "error", res.err)
+			if err := c.add(ctx, res, total); err != nil {
+				cancel()
+				drain(resultC)
+				return err
 			}
-			emojis[res.emoji.Name] = res.emoji // to resemble the legacy code.
-			count++
-			cb(res.emoji.Name, total, count)
 		}
 	}
-	out, err := fsa.Create("index.json")
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-	if err := json.NewEncoder(out).Encode(emojis); err != nil {
-		return err
+	// generator might have failed after the last emoji was processed.
+	select {
+	case genErr := <-genErrC:
+		return fmt.Errorf("failed to get emoji list: %w", genErr)
+	default:
 	}
 
-	return nil
+	return writeIndex(fsa, buildIndex(c.emojis, c.downloaded))
 }
 
 type result struct {
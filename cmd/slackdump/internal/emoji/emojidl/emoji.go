@@ -1,28 +1,26 @@
 // Package emojidl provides functions to dump the all slack emojis for a workspace.
-// It skips the "alias" emojis, so only original an emoji with an original name
-// is present. If you need to find the alias - lookup the index.json. The
-// directory structure is the following:
+// The emoji images are downloaded concurrently.  Aliases are not downloaded,
+// as they point to the original emoji, the index.json maps them to the file
+// of the original emoji.  The directory structure is the following:
 //
 //	.
 //	+- emojis
 //	|  +- foo.png
-//	|  +- bar.png
+//	|  +- bar.gif
 //	:  :
 //	|  +- baz.png
 //	+- index.json
 //
-// Where index.json contains the emoji index, and *.png files under emojis
-// directory are individual emojis.
+// Where index.json contains the emoji index (see [Index]), and files under
+// emojis directory are individual emojis.
 package emojidl
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"path"
 	"strings"
 	"sync"
 
@@ -43,21 +41,13 @@ type EmojiDumper interface {
 	DumpEmojis(ctx context.Context) (map[string]string, error)
 }
 
-// DlFS downloads all emojis from the workspace and saves them to the fsa.
+// DlFS downloads all emojis from the workspace and saves them to the fsa,
+// along with the index.json.
 func DlFS(ctx context.Context, sess EmojiDumper, fsa fsadapter.FS, failFast bool, cb StatusFunc) error {
 	emojis, err := sess.DumpEmojis(ctx)
 	if err != nil {
 		return fmt.Errorf("error during emoji dump: %w", err)
 	}
-
-	bIndex, err := json.Marshal(emojis)
-	if err != nil {
-		return fmt.Errorf("error marshalling emoji index: %w", err)
-	}
-	if err := fsa.WriteFile("index.json", bIndex, 0644); err != nil {
-		return fmt.Errorf("failed writing emoji index: %w", err)
-	}
-
 	return fetch(ctx, fsa, emojis, failFast, cb)
 }
 
@@ -70,13 +60,13 @@ func ift[T any](cond bool, t, f T) T {
 
 // fetch downloads the emojis and saves them to the fsa. It spawns numWorker
 // goroutines for getting the files. It will call fetchFn for each emoji.
+// Once all emojis are processed, it writes the index.
 func fetch(ctx context.Context, fsa fsadapter.FS, emojis map[string]string, failFast bool, cb StatusFunc) error {
 	lg := cfg.Log
 	lg.DebugContext(ctx, "startup params", "dir", emojiDir, "numWorkers", numWorkers, "failFast", failFast)
 
-	if cb == nil {
-		cb = func(name string, total, count int) {}
-	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	var (
 		emojiC  = make(chan edge.Emoji)
@@ -127,31 +117,72 @@ func fetch(ctx context.Context, fsa fsadapter.FS, emojis map[string]string, fail
 
 	// 4. Result processor, receives download results and logs any errors that
 	//    may have occurred.
-	var (
-		total = len(emojis)
-		count = 0
-	)
-	lg = lg.With("total", total)
+	var total = len(emojis)
+	c := newCollector(failFast, cb, total)
 	for res := range resultC {
-		lg := lg.With("name", res.emoji.Name)
-		if res.err != nil {
-			if errors.Is(res.err, context.Canceled) {
-				return res.err
-			}
-			if failFast {
-				return fmt.Errorf("failed: %q: %w", res.emoji.Name, res.err)
-			}
-			lg.WarnContext(ctx, "failed", "error", res.err)
+		if err := c.add(ctx, res, total); err != nil {
+			cancel()
+			drain(resultC)
+			return err
 		}
-		count++
-		cb(res.emoji.Name, total, count)
 	}
 
+	return writeIndex(fsa, buildIndex(c.emojis, c.downloaded))
+}
+
+// collector collects the download results.
+type collector struct {
+	failFast   bool
+	cb         StatusFunc
+	count      int
+	emojis     map[string]edge.Emoji // all processed emojis
+	downloaded map[string]bool       // names of successfully downloaded emojis
+}
+
+func newCollector(failFast bool, cb StatusFunc, sizeHint int) *collector {
+	if cb == nil {
+		cb = func(name string, total, count int) {}
+	}
+	return &collector{
+		failFast:   failFast,
+		cb:         cb,
+		emojis:     make(map[string]edge.Emoji, sizeHint),
+		downloaded: make(map[string]bool, sizeHint),
+	}
+}
+
+// add processes the download result.  It returns an error, if the download
+// should be stopped, i.e. the context was cancelled, or in the fail-fast
+// mode, if the emoji download failed.  Otherwise, the errors are logged, and
+// the failed emoji is indexed without the file.
+func (c *collector) add(ctx context.Context, res result, total int) error {
+	if res.err != nil {
+		if errors.Is(res.err, context.Canceled) {
+			return res.err
+		}
+		if c.failFast {
+			return fmt.Errorf("failed: %q: %w", res.emoji.Name, res.err)
+		}
+		cfg.Log.WarnContext(ctx, "failed", "name", res.emoji.Name, "error", res.err)
+	} else if !res.skipped {
+		c.downloaded[res.emoji.Name] = true
+	}
+	c.emojis[res.emoji.Name] = res.emoji
+	c.count++
+	c.cb(res.emoji.Name, total, c.count)
 	return nil
 }
 
-// fetchEmoji downloads one emoji file from uri into the filename dir/name.png
-// within the filesystem adapter fsa.
+// drain discards the remaining results until all workers are finished.  It
+// is called after the context is cancelled, so that the workers are not
+// blocked on sending the results.
+func drain(resultC <-chan result) {
+	for range resultC {
+	}
+}
+
+// fetchEmoji downloads one emoji file from uri into the filename
+// dir/name.ext within the filesystem adapter fsa, see [emojiFile].
 func fetchEmoji(ctx context.Context, fsa fsadapter.FS, dir string, name, uri string) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
 	if err != nil {
@@ -164,7 +195,7 @@ func fetchEmoji(ctx context.Context, fsa fsadapter.FS, dir string, name, uri str
 	}
 	defer resp.Body.Close()
 
-	filename := path.Join(dir, name+".png")
+	filename := emojiFile(dir, name, uri)
 	wc, err := fsa.Create(filename)
 	if err != nil {
 		return err
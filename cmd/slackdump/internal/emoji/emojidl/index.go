@@ -0,0 +1,130 @@
+package emojidl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rusq/fsadapter"
+
+	"github.com/rusq/slackdump/v3/internal/edge"
+)
+
+const (
+	indexFile = "index.json" // emoji index file name.
+	defExt    = ".png"       // default emoji file extension.
+
+	// maxAliasDepth is the maximum number of hops when resolving an alias,
+	// guards against the alias loops.
+	maxAliasDepth = 8
+)
+
+// Index is the emoji index, that is saved to index.json.  It maps the emoji
+// name to its entry.
+type Index map[string]IndexEntry
+
+// IndexEntry is the entry of the emoji index.
+type IndexEntry struct {
+	edge.Emoji
+	// File is the path of the emoji image within the output location.  For
+	// aliases, it's the file of the emoji that the alias points to.  It is
+	// empty, if the image was not downloaded, or if the alias points to the
+	// standard emoji.
+	File string `json:"file,omitempty"`
+}
+
+// emojiFile returns the path of the emoji image file within the output
+// location.  The extension is taken from the uri, i.e. ".gif" for animated
+// emojis, and defaults to ".png".
+func emojiFile(dir, name, uri string) string {
+	ext := defExt
+	if u, err := url.Parse(uri); err == nil {
+		if e := strings.ToLower(path.Ext(u.Path)); e != "" && len(e) <= 5 {
+			ext = e
+		}
+	}
+	return path.Join(dir, name+ext)
+}
+
+// buildIndex creates the emoji index from the list of emojis.  downloaded
+// contains the names of the emojis, which images were successfully saved.
+// Aliases are resolved to the file of the original emoji.
+func buildIndex(emojis map[string]edge.Emoji, downloaded map[string]bool) Index {
+	idx := make(Index, len(emojis))
+	for name, em := range emojis {
+		entry := IndexEntry{Emoji: em}
+		if em.IsAlias == 0 {
+			if downloaded[name] {
+				entry.File = emojiFile(emojiDir, name, em.URL)
+			}
+		} else if orig, ok := resolveAlias(emojis, em); ok && downloaded[orig.Name] {
+			entry.File = emojiFile(emojiDir, orig.Name, orig.URL)
+		}
+		idx[name] = entry
+	}
+	return idx
+}
+
+// resolveAlias follows the alias chain and returns the original custom emoji.
+// It returns false, if the alias points to the emoji that is not in the list,
+// i.e. to one of the standard emojis.
+func resolveAlias(emojis map[string]edge.Emoji, em edge.Emoji) (edge.Emoji, bool) {
+	for range maxAliasDepth {
+		if em.IsAlias == 0 {
+			return em, true
+		}
+		next, ok := emojis[em.AliasFor]
+		if !ok {
+			return edge.Emoji{}, false
+		}
+		em = next
+	}
+	return edge.Emoji{}, false
+}
+
+// writeIndex writes the emoji index to the index.json within fsa.
+func writeIndex(fsa fsadapter.FS, idx Index) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling emoji index: %w", err)
+	}
+	if err := fsa.WriteFile(indexFile, data, 0644); err != nil {
+		return fmt.Errorf("failed writing emoji index: %w", err)
+	}
+	return nil
+}
+
+// ReadIndex reads the emoji index from the index.json file, or from the
+// index.json in the directory, if filename is a directory.
+func ReadIndex(filename string) (Index, error) {
+	if fi, err := os.Stat(filename); err == nil && fi.IsDir() {
+		filename = filepath.Join(filename, indexFile)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("error reading emoji index %s: %w", filename, err)
+	}
+	return idx, nil
+}
+
+// URLs returns the map of the emoji names to their image URLs, the aliases
+// are mapped to "alias:<name>", as the Slack API returns them.
+func (idx Index) URLs() map[string]string {
+	m := make(map[string]string, len(idx))
+	for name, e := range idx {
+		if e.IsAlias != 0 {
+			m[name] = "alias:" + e.AliasFor
+		} else {
+			m[name] = e.URL
+		}
+	}
+	return m
+}
@@ -0,0 +1,123 @@
+package emojidl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rusq/fsadapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/edge"
+)
+
+func Test_emojiFile(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		want string
+	}{
+		{"png", "https://emoji.slack-edge.com/T1/foo/abc.png", "emojis/foo.png"},
+		{"gif", "https://emoji.slack-edge.com/T1/foo/abc.GIF", "emojis/foo.gif"},
+		{"no extension", "https://emoji.slack-edge.com/T1/foo/abc", "emojis/foo.png"},
+		{"query", "https://example.com/img.jpg?v=2", "emojis/foo.jpg"},
+		{"alias", "alias:bar", "emojis/foo.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, emojiFile(emojiDir, "foo", tt.uri))
+		})
+	}
+}
+
+func Test_buildIndex(t *testing.T) {
+	emojis := map[string]edge.Emoji{
+		"parrot":      {Name: "parrot", URL: "https://example.com/parrot.gif"},
+		"party":       {Name: "party", URL: "alias:parrot", IsAlias: 1, AliasFor: "parrot"},
+		"party2":      {Name: "party2", URL: "alias:party", IsAlias: 1, AliasFor: "party"},
+		"thumbs":      {Name: "thumbs", URL: "alias:+1", IsAlias: 1, AliasFor: "+1"},
+		"broken":      {Name: "broken", URL: "https://example.com/broken.png"},
+		"broken_link": {Name: "broken_link", URL: "alias:broken", IsAlias: 1, AliasFor: "broken"},
+		"loop1":       {Name: "loop1", URL: "alias:loop2", IsAlias: 1, AliasFor: "loop2"},
+		"loop2":       {Name: "loop2", URL: "alias:loop1", IsAlias: 1, AliasFor: "loop1"},
+	}
+	downloaded := map[string]bool{"parrot": true}
+
+	idx := buildIndex(emojis, downloaded)
+	require.Len(t, idx, len(emojis))
+	wantFiles := map[string]string{
+		"parrot":      "emojis/parrot.gif",
+		"party":       "emojis/parrot.gif",
+		"party2":      "emojis/parrot.gif",
+		"thumbs":      "", // standard emoji
+		"broken":      "", // not downloaded
+		"broken_link": "",
+		"loop1":       "",
+		"loop2":       "",
+	}
+	for name, want := range wantFiles {
+		assert.Equal(t, want, idx[name].File, name)
+		assert.Equal(t, emojis[name], idx[name].Emoji, name)
+	}
+}
+
+func TestReadIndex(t *testing.T) {
+	dir := t.TempDir()
+	idx := buildIndex(map[string]edge.Emoji{
+		"parrot": {Name: "parrot", URL: "https://example.com/parrot.gif"},
+		"party":  {Name: "party", URL: "alias:parrot", IsAlias: 1, AliasFor: "parrot"},
+	}, map[string]bool{"parrot": true})
+	require.NoError(t, writeIndex(fsadapter.NewDirectory(dir), idx))
+
+	for _, name := range []string{dir, filepath.Join(dir, indexFile)} {
+		got, err := ReadIndex(name)
+		require.NoError(t, err)
+		assert.Equal(t, idx, got)
+		assert.Equal(t, map[string]string{
+			"parrot": "https://example.com/parrot.gif",
+			"party":  "alias:parrot",
+		}, got.URLs())
+	}
+	_, err := ReadIndex(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func Test_fetch_bestEffort(t *testing.T) {
+	emojis := map[string]string{
+		"ok":     "https://example.com/ok.png",
+		"failed": "https://example.com/failed.gif",
+		"alias":  "alias:ok",
+	}
+	setGlobalFetchFn(func(ctx context.Context, fsa fsadapter.FS, dir string, name string, uri string) error {
+		if name == "failed" {
+			return errors.New("not today")
+		}
+		return nil
+	})
+	defer setGlobalFetchFn(fetchEmoji)
+
+	t.Run("best effort", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, fetch(context.Background(), fsadapter.NewDirectory(dir), emojis, false, nil))
+
+		data, err := os.ReadFile(filepath.Join(dir, indexFile))
+		require.NoError(t, err)
+		var idx Index
+		require.NoError(t, json.Unmarshal(data, &idx))
+		require.Len(t, idx, 3)
+		assert.Equal(t, "emojis/ok.png", idx["ok"].File)
+		assert.Equal(t, "emojis/ok.png", idx["alias"].File)
+		assert.Equal(t, "ok", idx["alias"].AliasFor)
+		assert.Empty(t, idx["failed"].File)
+	})
+	t.Run("fail fast", func(t *testing.T) {
+		dir := t.TempDir()
+		err := fetch(context.Background(), fsadapter.NewDirectory(dir), emojis, true, nil)
+		assert.Error(t, err)
+		assert.NoFileExists(t, filepath.Join(dir, indexFile))
+	})
+}
@@ -36,10 +36,10 @@ func (o *options) configuration() cfgui.Configuration {
 			Name: "Download Options",
 			Params: []cfgui.Parameter{
 				{
-					Name:        "Ignore Download Errors",
-					Value:       cfgui.Checkbox(o.ignoreErrors),
-					Description: "Ignore download errors and continue",
-					Updater:     updaters.NewBool(&o.ignoreErrors),
+					Name:        "Fail Fast",
+					Value:       cfgui.Checkbox(o.failFast),
+					Description: "Stop on the first download error, instead of skipping the failed emoji",
+					Updater:     updaters.NewBool(&o.failFast),
 				},
 			},
 		},
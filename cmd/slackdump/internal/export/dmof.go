@@ -0,0 +1,110 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/internal/network"
+)
+
+// dmSource is the source of the direct messages and group direct messages
+// of the current user.
+type dmSource interface {
+	// StreamChannels should call cb for each conversation of chanTypes.
+	StreamChannels(ctx context.Context, chanTypes []string, cb func(ch slack.Channel) error) error
+	// Members should return the user IDs of the conversation members.
+	Members(ctx context.Context, channelID string) ([]string, error)
+	// CurrentUserID should return the ID of the current user.
+	CurrentUserID() string
+}
+
+var errNoDMs = errors.New("no direct messages found")
+
+// validUserID returns true if the id looks like the Slack user ID.
+func validUserID(id string) bool {
+	return len(id) > 1 && (id[0] == 'U' || id[0] == 'W') && strings.ToUpper(id) == id
+}
+
+// dmsOf returns the IDs of the direct messages and group direct messages of
+// the current user, that involve the user userID.  If userID is the current
+// user, all of them are returned.  Only the conversations the current user
+// is a member of are visible with the API.
+func dmsOf(ctx context.Context, src dmSource, userID string) ([]string, error) {
+	if !validUserID(userID) {
+		return nil, fmt.Errorf("invalid user ID: %q", userID)
+	}
+	lg := cfg.Log.With("in", "dmsOf", "user_id", userID)
+	self := userID == src.CurrentUserID()
+
+	var ids []string
+	if err := src.StreamChannels(ctx, []string{"im", "mpim"}, func(ch slack.Channel) error {
+		switch {
+		case self:
+			ids = append(ids, ch.ID)
+		case ch.IsIM:
+			if ch.User == userID {
+				ids = append(ids, ch.ID)
+			}
+		case ch.IsMpIM:
+			members, err := src.Members(ctx, ch.ID)
+			if err != nil {
+				return fmt.Errorf("error getting members of %s: %w", ch.ID, err)
+			}
+			for _, m := range members {
+				if m == userID {
+					ids = append(ids, ch.ID)
+					break
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w with user %s", errNoDMs, userID)
+	}
+	lg.InfoContext(ctx, "found direct messages", "count", len(ids))
+	return ids, nil
+}
+
+// apiDMs finds the direct messages using the Slack API.
+type apiDMs struct {
+	*slackdump.Session
+}
+
+// Members returns the members of the conversation.
+func (a apiDMs) Members(ctx context.Context, channelID string) ([]string, error) {
+	cl := a.Client()
+	lim := network.NewLimiter(network.Tier4, cfg.Limits.Tier4.Burst, int(cfg.Limits.Tier4.Boost))
+	params := &slack.GetUsersInConversationParameters{
+		ChannelID: channelID,
+		Limit:     1000,
+	}
+	var members []string
+	for {
+		var (
+			page   []string
+			cursor string
+		)
+		if err := network.WithRetry(ctx, lim, cfg.Limits.Tier4.Retries, func() error {
+			var err error
+			page, cursor, err = cl.GetUsersInConversationContext(ctx, params)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		members = append(members, page...)
+		if cursor == "" {
+			break
+		}
+		params.Cursor = cursor
+	}
+	return members, nil
+}
@@ -0,0 +1,102 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDMs struct {
+	channels []slack.Channel
+	members  map[string][]string
+	self     string
+
+	memberCalls []string
+}
+
+func (f *fakeDMs) StreamChannels(_ context.Context, chanTypes []string, cb func(ch slack.Channel) error) error {
+	if len(chanTypes) != 2 || chanTypes[0] != "im" || chanTypes[1] != "mpim" {
+		return errors.New("unexpected channel types")
+	}
+	for _, ch := range f.channels {
+		if err := cb(ch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeDMs) Members(_ context.Context, channelID string) ([]string, error) {
+	f.memberCalls = append(f.memberCalls, channelID)
+	m, ok := f.members[channelID]
+	if !ok {
+		return nil, errors.New("channel_not_found")
+	}
+	return m, nil
+}
+
+func (f *fakeDMs) CurrentUserID() string { return f.self }
+
+func testIM(id, user string) slack.Channel {
+	var ch slack.Channel
+	ch.ID, ch.IsIM, ch.User = id, true, user
+	return ch
+}
+
+func testMPIM(id string) slack.Channel {
+	var ch slack.Channel
+	ch.ID, ch.IsMpIM = id, true
+	return ch
+}
+
+func newFakeDMs() *fakeDMs {
+	return &fakeDMs{
+		channels: []slack.Channel{
+			testIM("D1", "U2"),
+			testIM("D2", "U3"),
+			testMPIM("G1"),
+			testMPIM("G2"),
+		},
+		members: map[string][]string{
+			"G1": {"U1", "U2", "U3"},
+			"G2": {"U1", "U3", "U4"},
+		},
+		self: "U1",
+	}
+}
+
+func Test_dmsOf(t *testing.T) {
+	ctx := context.Background()
+	t.Run("other user", func(t *testing.T) {
+		src := newFakeDMs()
+		got, err := dmsOf(ctx, src, "U2")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"D1", "G1"}, got)
+		assert.Equal(t, []string{"G1", "G2"}, src.memberCalls)
+	})
+	t.Run("current user", func(t *testing.T) {
+		src := newFakeDMs()
+		got, err := dmsOf(ctx, src, "U1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"D1", "D2", "G1", "G2"}, got)
+		assert.Empty(t, src.memberCalls)
+	})
+	t.Run("no dms", func(t *testing.T) {
+		_, err := dmsOf(ctx, newFakeDMs(), "U9")
+		assert.ErrorIs(t, err, errNoDMs)
+	})
+	t.Run("invalid id", func(t *testing.T) {
+		_, err := dmsOf(ctx, newFakeDMs(), "D1")
+		assert.Error(t, err)
+	})
+	t.Run("members error", func(t *testing.T) {
+		src := newFakeDMs()
+		delete(src.members, "G1")
+		_, err := dmsOf(ctx, src, "U2")
+		assert.Error(t, err)
+	})
+}
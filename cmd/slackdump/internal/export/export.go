@@ -5,16 +5,26 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rusq/fsadapter"
 
+	"github.com/rusq/slackdump/v3/auth"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/bootstrap"
 
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/chunk/state"
+	"github.com/rusq/slackdump/v3/internal/chunk/transform"
 	"github.com/rusq/slackdump/v3/internal/chunk/transform/fileproc"
+	"github.com/rusq/slackdump/v3/internal/manifest"
+	"github.com/rusq/slackdump/v3/internal/nametmpl"
+	"github.com/rusq/slackdump/v3/internal/osext"
+	"github.com/rusq/slackdump/v3/internal/postproc"
+	"github.com/rusq/slackdump/v3/internal/remotefs"
 	"github.com/rusq/slackdump/v3/internal/structures"
 )
 
@@ -36,21 +46,84 @@ var mdExport string
 type exportFlags struct {
 	ExportStorageType fileproc.StorageType
 	ExportToken       string
+	Resume            string
+	Incremental       bool
+	Personal          bool
+	StorageReport     bool
+	Members           bool
+	Participants      bool
+	ChannelUsers      bool
+	Layout            transform.Layout
+	DMOf              string
+	Post              string
+	SkipExistingFiles string
+	MaxMessages       int
+	UsersIndex        bool
+	SlackImport       bool
+	Governance        bool
+	UserGroups        bool
+	Workspaces        string
+	RFC3339           bool
+	FileTemplate      string
+	Compress          string
+
+	fileTmpl    *nametmpl.FileTemplate // compiled FileTemplate
+	compress    osext.Compression      // parsed Compress
+	resumeState *state.State           // loaded from the Resume file
+	inc         *incremental           // output of the incremental export
+	existing    *manifest.Manifest
+	existingDir string // local directory of the previous export, if any
+	replay      bool   // set by Replay, the interrupted replay is not resumable
 }
 
 var options = exportFlags{
 	ExportStorageType: fileproc.STmattermost,
+	Layout:            transform.LayoutFlat,
 }
 
 func init() {
-	CmdExport.Flag.Var(&options.ExportStorageType, "type", "export file storage type")
+	CmdExport.Flag.Var(&options.ExportStorageType, "type", "export file storage `type`: mattermost, standard, content or none")
 	CmdExport.Flag.StringVar(&options.ExportToken, "export-token", "", "file export token to append to each of the file URLs")
+	CmdExport.Flag.StringVar(&options.Resume, "resume", "", "resume the interrupted export using the state `file`")
+	CmdExport.Flag.BoolVar(&options.Incremental, "incremental", false, "fetch only the messages newer than the ones in the previous export\nat the output location, and merge them into it")
+	CmdExport.Flag.StringVar(&options.DMOf, "dm-of", "", "export all direct messages and group direct messages with the user `ID`,\nin addition to the conversations listed in the arguments")
+	CmdExport.Flag.BoolVar(&options.StorageReport, "storage-report", false, "write the storage usage report of the file attachments to\n\""+storageReportFile+"\" in the export")
+	CmdExport.Flag.Var(&options.Layout, "layout", "organisation of the conversation directories: flat, or by-type to group\nthem into channels, private, mpims and dms directories")
+	CmdExport.Flag.BoolVar(&options.Members, "members", false, "write the snapshot of the channel members to \""+transform.MembersFile+"\"\nin each channel directory")
+	CmdExport.Flag.BoolVar(&options.Participants, "participants", false, "write the summary of the users, that posted in the channel, with their\nmessage counts and the first and last activity times to \""+transform.ParticipantsFile+"\"\nin each channel directory")
+	CmdExport.Flag.BoolVar(&options.ChannelUsers, "channel-users", false, "populate users.json only with the users that appear in the exported\nconversations, instead of listing all users of the workspace")
+	CmdExport.Flag.StringVar(&options.Post, "post", "", "run the post-processing pipeline (compress, encrypt, upload) configured\nin the TOML `file` after the successful export")
+	CmdExport.Flag.StringVar(&options.SkipExistingFiles, "skip-existing-files", "", "do not download the files recorded in the manifest of the previous\nexport.  If `location` is the previous export directory, the files are\nhard-linked or copied from it, if it is the manifest file, they are skipped")
+	CmdExport.Flag.IntVar(&options.MaxMessages, "max-messages", 0, "split the day files with more than `n` messages into the part files,\nnamed YYYY-MM-DD-partN.json, 0 means no limit")
+	CmdExport.Flag.BoolVar(&options.UsersIndex, "users-index", false, "write the users cross-reference index with the channels and threads\neach user posted in to \""+usersIndexFile+"\", implies -participants")
+	CmdExport.Flag.BoolVar(&options.SlackImport, "slack-import", false, "produce the export in the Slack import format, to migrate the\nconversations to another Slack workspace")
+	CmdExport.Flag.BoolVar(&options.Governance, "governance", false, "write the governance report with the workspace admins, user groups\nand channel managers to \""+governanceFile+"\", where API access permits")
+	CmdExport.Flag.BoolVar(&options.UserGroups, "usergroups", false, "write the user groups of the workspace with their members to\n\""+transform.UserGroupsFile+"\", to resolve the @group mentions")
+	CmdExport.Flag.BoolVar(&options.RFC3339, "rfc3339", false, "add the RFC 3339 time in UTC next to the timestamps of each message,\nas \"ts_rfc3339\" and \"thread_ts_rfc3339\"")
+	CmdExport.Flag.StringVar(&options.FileTemplate, "file-template", "", "name the downloaded files with the Go `template`, i.e.\n\"{{.Year}}/{{.Month}}/{{.User}}/{{.ID}}-{{.Name}}\", requires -type standard.\nAvailable: ID, Name, Base, Ext, Channel, ChannelID, User, Date,\nYear, Month, Day and Hash, ID or Hash must be used")
+	CmdExport.Flag.StringVar(&options.Workspaces, "workspaces", "", "export each of the comma-separated `list` of workspaces, or \"all\" of\nthe saved workspaces, into a separate output location")
+	CmdExport.Flag.BoolVar(&options.Personal, "personal", false, "save scheduled messages and drafts of the current user to the\n\""+personalDir+"\" directory of the export, where API access permits")
+	CmdExport.Flag.StringVar(&options.Compress, "compress", "", "compress the temporary chunk files, that are retained to -resume the\ninterrupted export, with `algorithm`: gzip (default) or zstd")
+
+	cfg.SetAnnotationFlags(&CmdExport.Flag)
+	cfg.SetRetentionFlags(&CmdExport.Flag)
+	cfg.SetChannelFilterFlags(&CmdExport.Flag)
+	cfg.SetFilterFlags(&CmdExport.Flag)
+	cfg.SetRedactFlags(&CmdExport.Flag)
 
 	CmdExport.Run = runExport
 	CmdExport.Wizard = wizExport
 }
 
 func runExport(ctx context.Context, cmd *base.Command, args []string) error {
+	if options.Workspaces != "" {
+		return runWorkspaces(ctx, cmd, args)
+	}
+	return runWorkspaceExport(ctx, cmd, args)
+}
+
+// runWorkspaceExport runs the export of the workspace from the context.
+func runWorkspaceExport(ctx context.Context, cmd *base.Command, args []string) error {
 	start := time.Now()
 	if strings.TrimSpace(cfg.Output) == "" {
 		base.SetExitStatus(base.SInvalidParameters)
@@ -59,34 +132,250 @@ func runExport(ctx context.Context, cmd *base.Command, args []string) error {
 	if !cfg.DownloadFiles {
 		options.ExportStorageType = fileproc.STnone
 	}
-	list, err := structures.NewEntityList(args)
-	if err != nil {
-		base.SetExitStatus(base.SUserError)
-		return fmt.Errorf("error parsing the entity list: %w", err)
+	if options.Resume != "" && options.Incremental {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("-resume and -incremental can't be used together")
+	}
+	if remotefs.IsRemote(cfg.Output) && (options.Resume != "" || options.Incremental) {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("-resume and -incremental are not supported with the remote output location")
+	}
+	var post *postproc.Pipeline
+	if options.Post != "" {
+		if remotefs.IsRemote(cfg.Output) {
+			base.SetExitStatus(base.SInvalidParameters)
+			return errors.New("-post requires the local output location")
+		}
+		pcfg, err := postproc.Load(options.Post)
+		if err != nil {
+			base.SetExitStatus(base.SInvalidParameters)
+			return err
+		}
+		if post, err = postproc.New(pcfg, postproc.WithLogger(cfg.Log)); err != nil {
+			base.SetExitStatus(base.SInvalidParameters)
+			return err
+		}
+	}
+	if options.Incremental && options.Layout == transform.LayoutByType {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("-incremental supports only the flat layout")
+	}
+	if options.MaxMessages < 0 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("-max-messages must not be negative")
+	}
+	if options.Incremental && options.MaxMessages > 0 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("-max-messages can't be used with -incremental")
+	}
+	if options.Incremental && options.UsersIndex {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("-users-index can't be used with -incremental")
+	}
+	if options.Incremental && options.Participants {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("-participants can't be used with -incremental")
+	}
+	if options.ExportStorageType == fileproc.STcontent && (options.Incremental || options.Resume != "" || options.SkipExistingFiles != "") {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("-type content can't be used with -incremental, -resume or -skip-existing-files")
+	}
+	if options.FileTemplate != "" && options.ExportStorageType != fileproc.STnone {
+		if options.ExportStorageType != fileproc.STstandard {
+			base.SetExitStatus(base.SInvalidParameters)
+			return errors.New("-file-template requires -type standard")
+		}
+		t, err := nametmpl.NewFile(options.FileTemplate)
+		if err != nil {
+			base.SetExitStatus(base.SInvalidParameters)
+			return fmt.Errorf("-file-template: %w", err)
+		}
+		options.fileTmpl = t
+	}
+	if options.Compress != "" {
+		c, err := osext.ParseCompression(options.Compress)
+		if err == nil && c == osext.CompressNone {
+			err = errors.New("the chunk files are always compressed, use gzip or zstd")
+		}
+		if err != nil {
+			base.SetExitStatus(base.SInvalidParameters)
+			return fmt.Errorf("-compress: %w", err)
+		}
+		options.compress = c
+	}
+	if err := options.validateSlackImport(); err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	if options.Resume != "" {
+		st, err := loadResumeState(options.Resume)
+		if err != nil {
+			base.SetExitStatus(base.SUserError)
+			return err
+		}
+		if isZIP(st.FilesDir) {
+			base.SetExitStatus(base.SUserError)
+			return errors.New("resuming the export into a ZIP file is not supported")
+		}
+		// export is resumed into the same location.
+		cfg.Output = st.FilesDir
+		options.resumeState = st
+	}
+	if options.SkipExistingFiles != "" {
+		mf, dir, err := loadExisting(options.SkipExistingFiles)
+		if err != nil {
+			base.SetExitStatus(base.SUserError)
+			return err
+		}
+		options.existing, options.existingDir = mf, dir
 	}
-
 	sess, err := bootstrap.SlackdumpSession(ctx)
 	if err != nil {
 		base.SetExitStatus(base.SApplicationError)
 		return err
 	}
 
-	fsa, err := fsadapter.New(cfg.Output)
+	if options.DMOf != "" {
+		ids, err := dmsOf(ctx, apiDMs{sess}, options.DMOf)
+		if err != nil {
+			base.SetExitStatus(base.SUserError)
+			return err
+		}
+		args = append(args, ids...)
+	}
+	list, err := structures.NewEntityList(args)
 	if err != nil {
 		base.SetExitStatus(base.SUserError)
+		return fmt.Errorf("error parsing the entity list: %w", err)
+	}
+	if err := bootstrap.ResolveChannelNames(ctx, sess, list); err != nil {
+		base.SetExitStatus(base.SApplicationError)
 		return err
 	}
+	wd := bootstrap.Preflight(ctx, sess, time.Time(cfg.Oldest))
+
+	var fsa fsadapter.FSCloser
+	if options.Incremental {
+		inc, err := openIncremental(cfg.Output)
+		if err != nil {
+			base.SetExitStatus(base.SUserError)
+			return err
+		}
+		// the manifest records the history range, that was accessible.
+		inc.mf.SetAccessRange(wd.Plan, wd.AccessibleFrom(start))
+		fsa, options.inc = inc, inc
+	} else {
+		fsa, err = remotefs.New(cfg.Output)
+		if err != nil {
+			base.SetExitStatus(base.SUserError)
+			return err
+		}
+	}
 	lg := cfg.Log
-	defer func() {
+	closeFSA := sync.OnceValue(func() error {
 		lg.DebugContext(ctx, "closing the fsadapter")
-		fsa.Close()
+		return fsa.Close()
+	})
+	defer func() {
+		if err := closeFSA(); err != nil {
+			lg.ErrorContext(ctx, "error closing the output", "error", err)
+			base.SetExitStatus(base.SApplicationError)
+		}
 	}()
 
 	if err := export(ctx, sess, fsa, list, options); err != nil {
 		base.SetExitStatus(base.SApplicationError)
 		return fmt.Errorf("export failed: %w", err)
 	}
+	if options.Personal {
+		prov, err := auth.FromContext(ctx)
+		if err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			return err
+		}
+		if err := exportPersonal(ctx, apiPersonal{sess: sess, prov: prov}, fsa); err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			return fmt.Errorf("personal data export failed: %w", err)
+		}
+	}
 
 	lg.InfoContext(ctx, "export completed", "took", time.Since(start).String())
+	if post != nil {
+		// the output must be complete before post-processing, i.e. the ZIP
+		// file must be finalised.
+		if err := closeFSA(); err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			return fmt.Errorf("error closing the output: %w", err)
+		}
+		if err := postproc.ResetState(cfg.Output); err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			return err
+		}
+		if err := post.Run(ctx, cfg.Output); err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			lg.InfoContext(ctx, "post-processing interrupted, to resume, run: slackdump tools postprocess -config "+options.Post+" "+cfg.Output)
+			return fmt.Errorf("post-processing failed: %w", err)
+		}
+	}
 	return nil
 }
+
+// validateSlackImport checks that the flags do not add the files, or change
+// the directory structure, that Slack import does not accept.
+func (f *exportFlags) validateSlackImport() error {
+	if !f.SlackImport {
+		return nil
+	}
+	switch {
+	case f.Layout == transform.LayoutByType:
+		return errors.New("-slack-import supports only the flat layout")
+	case f.MaxMessages > 0:
+		return errors.New("-max-messages can't be used with -slack-import")
+	case f.Members:
+		return errors.New("-members can't be used with -slack-import")
+	case f.UsersIndex:
+		return errors.New("-users-index can't be used with -slack-import")
+	case f.Participants:
+		return errors.New("-participants can't be used with -slack-import")
+	case f.RFC3339:
+		return errors.New("-rfc3339 can't be used with -slack-import")
+	case cfg.Pins:
+		return errors.New("-pins can't be used with -slack-import")
+	case f.UserGroups:
+		return errors.New("-usergroups can't be used with -slack-import")
+	case f.ExportStorageType == fileproc.STcontent:
+		return errors.New("-type content can't be used with -slack-import")
+	case f.FileTemplate != "":
+		return errors.New("-file-template can't be used with -slack-import")
+	}
+	return nil
+}
+
+// loadExisting loads the manifest of the previous export for
+// -skip-existing-files.  location is either the manifest file, or the
+// directory of the previous export, in which case the directory is returned
+// as well, so that the files can be taken from it.
+func loadExisting(location string) (*manifest.Manifest, string, error) {
+	fi, err := os.Stat(location)
+	if err != nil {
+		return nil, "", err
+	}
+	if !fi.IsDir() {
+		f, err := os.Open(location)
+		if err != nil {
+			return nil, "", err
+		}
+		defer f.Close()
+		mf, err := manifest.Read(f)
+		return mf, "", err
+	}
+	mf, err := manifest.Load(os.DirFS(location))
+	if err != nil {
+		return nil, "", fmt.Errorf("error loading the manifest of the previous export: %w", err)
+	}
+	return mf, location, nil
+}
+
+func isZIP(filename string) bool {
+	return strings.HasSuffix(strings.ToLower(filename), ".zip")
+}
@@ -0,0 +1,32 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/rusq/slackdump/v3/internal/chunk/transform"
+	"github.com/rusq/slackdump/v3/internal/chunk/transform/fileproc"
+)
+
+func Test_exportFlags_validateSlackImport(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   exportFlags
+		wantErr bool
+	}{
+		{"disabled", exportFlags{Layout: transform.LayoutByType, Members: true}, false},
+		{"flat", exportFlags{SlackImport: true, Layout: transform.LayoutFlat}, false},
+		{"by type", exportFlags{SlackImport: true, Layout: transform.LayoutByType}, true},
+		{"max messages", exportFlags{SlackImport: true, MaxMessages: 10}, true},
+		{"members", exportFlags{SlackImport: true, Members: true}, true},
+		{"users index", exportFlags{SlackImport: true, UsersIndex: true}, true},
+		{"content storage", exportFlags{SlackImport: true, ExportStorageType: fileproc.STcontent}, true},
+		{"file template", exportFlags{SlackImport: true, FileTemplate: "{{.ID}}"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.flags.validateSlackImport(); (err != nil) != tt.wantErr {
+				t.Errorf("validateSlackImport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"sort"
+	"time"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3"
+	"github.com/rusq/slackdump/v3/auth"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/edge"
+	"github.com/rusq/slackdump/v3/internal/network"
+)
+
+// governanceFile is the name of the governance report file.
+const governanceFile = "governance.json"
+
+// Roles of the workspace administrators in the governance report.
+const (
+	rolePrimaryOwner = "primary_owner"
+	roleOwner        = "owner"
+	roleAdmin        = "admin"
+)
+
+// Sections of the governance report, that may be unavailable.
+const (
+	sectionUserGroups      = "user_groups"
+	sectionChannelManagers = "channel_managers"
+)
+
+// governanceFetcher is the source of the governance data, that is not
+// recorded in the chunk files.
+type governanceFetcher interface {
+	UserGroups(ctx context.Context) ([]slack.UserGroup, error)
+	ChannelManagers(ctx context.Context, channelIDs []string) ([]edge.RoleAssignment, error)
+}
+
+// governanceReport describes who controls the workspace and the exported
+// channels.
+type governanceReport struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	Admins      []govAdmin     `json:"admins"`
+	UserGroups  []govUserGroup `json:"user_groups"`
+	Channels    []govChannel   `json:"channels"`
+	// Unavailable has the reasons for the sections, that could not be
+	// fetched with the current token.
+	Unavailable map[string]string `json:"unavailable,omitempty"`
+}
+
+// govAdmin is the owner or the administrator of the workspace.
+type govAdmin struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name,omitempty"`
+	Role     string `json:"role"`
+}
+
+// govUserGroup is the user group with its members.
+type govUserGroup struct {
+	ID          string   `json:"id"`
+	Handle      string   `json:"handle"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	CreatedBy   string   `json:"created_by,omitempty"`
+	Disabled    bool     `json:"disabled,omitempty"`
+	Users       []string `json:"users"`
+}
+
+// govChannel is the exported channel with its creator and managers.
+type govChannel struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name,omitempty"`
+	Creator  string   `json:"creator,omitempty"`
+	Managers []string `json:"managers,omitempty"`
+}
+
+// buildGovernance builds the governance report of the channels and the
+// users.  The sections, that can't be fetched, are listed as unavailable in
+// the report, only the context errors are returned.
+func buildGovernance(ctx context.Context, gf governanceFetcher, channels []slack.Channel, users []slack.User) (*governanceReport, error) {
+	lg := cfg.Log.With("in", "buildGovernance")
+	rep := &governanceReport{
+		GeneratedAt: time.Now().UTC().Truncate(time.Second),
+		Admins:      []govAdmin{},
+		UserGroups:  []govUserGroup{},
+		Channels:    make([]govChannel, 0, len(channels)),
+	}
+	unavailable := func(section string, err error) {
+		lg.WarnContext(ctx, "unable to fetch, skipping", "section", section, "error", err)
+		if rep.Unavailable == nil {
+			rep.Unavailable = make(map[string]string)
+		}
+		rep.Unavailable[section] = err.Error()
+	}
+
+	for _, u := range users {
+		var role string
+		switch {
+		case u.Deleted:
+			continue
+		case u.IsPrimaryOwner:
+			role = rolePrimaryOwner
+		case u.IsOwner:
+			role = roleOwner
+		case u.IsAdmin:
+			role = roleAdmin
+		default:
+			continue
+		}
+		rep.Admins = append(rep.Admins, govAdmin{ID: u.ID, Name: u.Name, RealName: u.RealName, Role: role})
+	}
+	sort.Slice(rep.Admins, func(i, j int) bool { return rep.Admins[i].ID < rep.Admins[j].ID })
+
+	groups, err := gf.UserGroups(ctx)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		unavailable(sectionUserGroups, err)
+	}
+	for _, g := range groups {
+		rep.UserGroups = append(rep.UserGroups, govUserGroup{
+			ID:          g.ID,
+			Handle:      g.Handle,
+			Name:        g.Name,
+			Description: g.Description,
+			CreatedBy:   g.CreatedBy,
+			Disabled:    g.DateDelete != 0,
+			Users:       append([]string{}, g.Users...),
+		})
+	}
+	sort.Slice(rep.UserGroups, func(i, j int) bool { return rep.UserGroups[i].ID < rep.UserGroups[j].ID })
+
+	ids := make([]string, 0, len(channels))
+	for _, ch := range channels {
+		if ch.IsIM || ch.IsMpIM {
+			continue // direct messages have no managers.
+		}
+		ids = append(ids, ch.ID)
+	}
+	managers := make(map[string][]string)
+	if len(ids) > 0 {
+		ra, err := gf.ChannelManagers(ctx, ids)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err != nil {
+			unavailable(sectionChannelManagers, err)
+		}
+		for _, a := range ra {
+			managers[a.EntityID] = append(managers[a.EntityID], a.UserID)
+		}
+	}
+	for _, ch := range channels {
+		if ch.IsIM || ch.IsMpIM {
+			continue
+		}
+		mm := managers[ch.ID]
+		sort.Strings(mm)
+		rep.Channels = append(rep.Channels, govChannel{ID: ch.ID, Name: ch.Name, Creator: ch.Creator, Managers: mm})
+	}
+	sort.Slice(rep.Channels, func(i, j int) bool { return rep.Channels[i].ID < rep.Channels[j].ID })
+	return rep, nil
+}
+
+// writeGovernance writes the governance report of the channels and users in
+// the chunk directory to fsa.
+func writeGovernance(ctx context.Context, gf governanceFetcher, fsa fsadapter.FS, cd *chunk.Directory) error {
+	channels, err := cd.Channels()
+	if err != nil {
+		return err
+	}
+	users, err := cd.Users()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	rep, err := buildGovernance(ctx, gf, channels, users)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(fsa, governanceFile, rep); err != nil {
+		return err
+	}
+	cfg.Log.InfoContext(ctx, "governance report written", "file", governanceFile, "admins", len(rep.Admins), "user_groups", len(rep.UserGroups), "channels", len(rep.Channels))
+	return nil
+}
+
+// managersBatch is the number of channels, requested in one call.
+const managersBatch = 100
+
+// apiGovernance fetches the governance data using the Slack API.
+type apiGovernance struct {
+	sess *slackdump.Session
+	prov auth.Provider
+}
+
+// UserGroups returns all user groups of the workspace, including the
+// disabled ones, with their members.
+func (g apiGovernance) UserGroups(ctx context.Context) ([]slack.UserGroup, error) {
+	lim := network.NewLimiter(network.Tier2, cfg.Limits.Tier2.Burst, int(cfg.Limits.Tier2.Boost))
+	var groups []slack.UserGroup
+	err := network.WithRetry(ctx, lim, cfg.Limits.Tier2.Retries, func() error {
+		var err error
+		groups, err = g.sess.Client().GetUserGroupsContext(ctx,
+			slack.GetUserGroupsOptionIncludeUsers(true),
+			slack.GetUserGroupsOptionIncludeDisabled(true),
+		)
+		return err
+	})
+	return groups, err
+}
+
+// ChannelManagers returns the channel manager role assignments of the
+// channels, available only to the Enterprise Grid administrators.
+func (g apiGovernance) ChannelManagers(ctx context.Context, channelIDs []string) ([]edge.RoleAssignment, error) {
+	cl, err := edge.NewWithInfo(g.sess.Info(), g.prov)
+	if err != nil {
+		return nil, err
+	}
+	defer cl.Close()
+	var ra []edge.RoleAssignment
+	for i := 0; i < len(channelIDs); i += managersBatch {
+		batch := channelIDs[i:min(i+managersBatch, len(channelIDs))]
+		r, err := cl.AdminRoleAssignments(ctx, []string{edge.RoleChannelManager}, batch)
+		if err != nil {
+			return nil, err
+		}
+		ra = append(ra, r...)
+	}
+	return ra, nil
+}
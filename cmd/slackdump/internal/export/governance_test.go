@@ -0,0 +1,87 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/edge"
+)
+
+type fakeGovernance struct {
+	groups      []slack.UserGroup
+	groupsErr   error
+	managers    []edge.RoleAssignment
+	managersErr error
+	requested   []string
+}
+
+func (f *fakeGovernance) UserGroups(ctx context.Context) ([]slack.UserGroup, error) {
+	return f.groups, f.groupsErr
+}
+
+func (f *fakeGovernance) ChannelManagers(ctx context.Context, channelIDs []string) ([]edge.RoleAssignment, error) {
+	f.requested = channelIDs
+	return f.managers, f.managersErr
+}
+
+func Test_buildGovernance(t *testing.T) {
+	channels := []slack.Channel{
+		{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C2"}, Name: "random", Creator: "U2"}},
+		{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}, Name: "general", Creator: "U1"}},
+		{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "D1", IsIM: true}}},
+	}
+	users := []slack.User{
+		{ID: "U3", Name: "carol", IsAdmin: true},
+		{ID: "U1", Name: "alice", IsOwner: true, IsPrimaryOwner: true, IsAdmin: true},
+		{ID: "U2", Name: "bob"},
+		{ID: "U4", Name: "dave", IsAdmin: true, Deleted: true},
+	}
+	t.Run("all available", func(t *testing.T) {
+		gf := &fakeGovernance{
+			groups: []slack.UserGroup{{ID: "S1", Handle: "devs", Name: "Developers", Users: []string{"U1", "U2"}}},
+			managers: []edge.RoleAssignment{
+				{RoleID: edge.RoleChannelManager, EntityID: "C1", UserID: "U3"},
+				{RoleID: edge.RoleChannelManager, EntityID: "C1", UserID: "U2"},
+			},
+		}
+		rep, err := buildGovernance(context.Background(), gf, channels, users)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"C2", "C1"}, gf.requested, "direct messages must not be requested")
+		assert.Equal(t, []govAdmin{
+			{ID: "U1", Name: "alice", Role: rolePrimaryOwner},
+			{ID: "U3", Name: "carol", Role: roleAdmin},
+		}, rep.Admins)
+		assert.Equal(t, []govUserGroup{{ID: "S1", Handle: "devs", Name: "Developers", Users: []string{"U1", "U2"}}}, rep.UserGroups)
+		assert.Equal(t, []govChannel{
+			{ID: "C1", Name: "general", Creator: "U1", Managers: []string{"U2", "U3"}},
+			{ID: "C2", Name: "random", Creator: "U2"},
+		}, rep.Channels)
+		assert.Empty(t, rep.Unavailable)
+	})
+	t.Run("sections not permitted", func(t *testing.T) {
+		gf := &fakeGovernance{
+			groupsErr:   errors.New("missing_scope"),
+			managersErr: errors.New("not_an_enterprise"),
+		}
+		rep, err := buildGovernance(context.Background(), gf, channels, users)
+		require.NoError(t, err)
+		assert.Len(t, rep.Admins, 2)
+		assert.NotNil(t, rep.UserGroups)
+		assert.Len(t, rep.Channels, 2)
+		assert.Equal(t, map[string]string{
+			sectionUserGroups:      "missing_scope",
+			sectionChannelManagers: "not_an_enterprise",
+		}, rep.Unavailable)
+	})
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := buildGovernance(ctx, &fakeGovernance{}, channels, users)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
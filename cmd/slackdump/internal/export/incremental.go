@@ -0,0 +1,446 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rusq/fsadapter"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/chunk/control"
+	"github.com/rusq/slackdump/v3/internal/chunk/state"
+	"github.com/rusq/slackdump/v3/internal/fasttime"
+	"github.com/rusq/slackdump/v3/internal/manifest"
+	"github.com/rusq/slackdump/v3/internal/mtimefs"
+	"github.com/rusq/slackdump/v3/internal/runid"
+)
+
+// incremental is the filesystem adapter for the incremental export.  It
+// merges the files written by the export converter into the files of the
+// previous export in the same location:
+//   - conversation day files (<channel>/YYYY-MM-DD.json) are merged by the
+//     message timestamp, new versions of the messages replace the old ones;
+//   - index files (channels.json, users.json, etc.) are merged by ID;
+//   - all other files are written as is.
+//
+// The previous export in a directory is updated in place.  The previous ZIP
+// export is rewritten into a temporary file, which replaces the original on
+// Close, if the export was committed.  It also holds the export manifest
+// with the high-water marks of the channels.
+type incremental struct {
+	fsa  fsadapter.FSCloser
+	prev fs.FS // previous export, nil if there's none.
+	mf   *manifest.Manifest
+
+	// ZIP output only.
+	output string          // final output filename
+	tmp    string          // temporary output filename
+	zr     *zip.ReadCloser // previous export
+
+	mu        sync.Mutex
+	written   map[string]bool
+	committed bool
+	// err is the first merge error.  Callers of Create may ignore the
+	// errors returned by Close, so it is reported by commit.
+	err error
+}
+
+// dayFileRE matches the conversation day file name.
+var dayFileRE = regexp.MustCompile(`^[^/]+/\d{4}-\d{2}-\d{2}\.json$`)
+
+// indexFiles are the export index files, that are merged by ID.
+var indexFiles = map[string]bool{
+	"channels.json":   true,
+	"groups.json":     true,
+	"mpims.json":      true,
+	"dms.json":        true,
+	"users.json":      true,
+	"usergroups.json": true,
+}
+
+// openIncremental opens the output location for the incremental export.  If
+// there's no previous export at the location, the new one is created.
+func openIncremental(output string) (*incremental, error) {
+	inc := &incremental{written: make(map[string]bool)}
+	if isZIP(output) {
+		if err := inc.openZIP(output); err != nil {
+			return nil, err
+		}
+	} else {
+		if fi, err := os.Stat(output); err == nil && fi.IsDir() {
+			inc.prev = os.DirFS(output)
+		} else if err == nil {
+			return nil, fmt.Errorf("not a directory: %s", output)
+		}
+		inc.fsa = mtimefs.NewDirectory(output)
+	}
+
+	if inc.prev == nil {
+		inc.mf = manifest.New()
+		return inc, nil
+	}
+	mf, err := manifest.Load(inc.prev)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			inc.Close()
+			return nil, err
+		}
+		// previous export made without the incremental mode, all channels
+		// will be fetched in full, and merged into the existing files.
+		mf = manifest.New()
+	}
+	inc.mf = mf
+	return inc, nil
+}
+
+func (inc *incremental) openZIP(output string) error {
+	if _, err := os.Stat(output); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		// new export
+		fsa, err := mtimefs.NewZipFile(output)
+		if err != nil {
+			return err
+		}
+		inc.fsa = fsa
+		return nil
+	}
+	zr, err := zip.OpenReader(output)
+	if err != nil {
+		return fmt.Errorf("error opening the previous export: %w", err)
+	}
+	tf, err := os.CreateTemp(filepath.Dir(output), filepath.Base(output)+".*.tmp")
+	if err != nil {
+		zr.Close()
+		return err
+	}
+	tf.Close()
+	fsa, err := mtimefs.NewZipFile(tf.Name())
+	if err != nil {
+		zr.Close()
+		os.Remove(tf.Name())
+		return err
+	}
+	inc.fsa, inc.prev, inc.zr = fsa, zr, zr
+	inc.output, inc.tmp = output, tf.Name()
+	return nil
+}
+
+// highWater returns the function that returns the high-water mark of the
+// channel from the manifest.
+func (inc *incremental) highWater() control.HighWaterFunc {
+	return inc.mf.HighWater
+}
+
+// advance advances the high-water marks of the channels that were exported
+// completely to the newest message in the chunk files.
+func (inc *incremental) advance(cd *chunk.Directory) error {
+	ids, err := channelFileIDs(cd.Name())
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		channelID, _ := id.Split()
+		if err := inc.advanceChannel(cd, id, channelID); err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// annotate copies the operator notes recorded in the chunk directory to the
+// manifest.
+func (inc *incremental) annotate(cd *chunk.Directory) error {
+	aa, err := cd.Annotations()
+	if err != nil {
+		if errors.Is(err, chunk.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	for _, a := range aa {
+		inc.mf.AddAnnotation(state.Annotation{Time: a.Time, Author: a.Author, Text: a.Text})
+	}
+	return nil
+}
+
+func (inc *incremental) advanceChannel(cd *chunk.Directory, id chunk.FileID, channelID string) error {
+	f, err := cd.Open(id)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if ok, err := f.IsComplete(channelID); err != nil || !ok {
+		return err
+	}
+	st, err := f.State()
+	if err != nil {
+		return err
+	}
+	latest := st.LatestChannelTS(channelID)
+	if latest == "" {
+		return nil // no messages
+	}
+	_, err = inc.mf.Advance(channelID, latest)
+	return err
+}
+
+// commit saves the manifest and marks the export as successful, so that the
+// output replaces the previous export on Close.
+func (inc *incremental) commit() error {
+	inc.mu.Lock()
+	err := inc.err
+	inc.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	inc.mf.AddRun(runid.ID())
+	if err := inc.mf.Save(inc); err != nil {
+		return fmt.Errorf("error saving manifest: %w", err)
+	}
+	inc.mu.Lock()
+	inc.committed = true
+	inc.mu.Unlock()
+	return nil
+}
+
+// Create implements fsadapter.FS.
+func (inc *incremental) Create(name string) (io.WriteCloser, error) {
+	if !inc.isMergeable(name) {
+		inc.markWritten(name)
+		return inc.fsa.Create(name)
+	}
+	return &mergeWriter{name: name, inc: inc}, nil
+}
+
+// CreateAt implements mtimefs.Creator.
+func (inc *incremental) CreateAt(name string, modTime time.Time) (io.WriteCloser, error) {
+	if inc.isMergeable(name) {
+		return inc.Create(name)
+	}
+	inc.markWritten(name)
+	return mtimefs.Create(inc.fsa, name, modTime)
+}
+
+// WriteFile implements fsadapter.FS.
+func (inc *incremental) WriteFile(name string, data []byte, perm os.FileMode) error {
+	inc.markWritten(name)
+	if !inc.isMergeable(name) {
+		return inc.fsa.WriteFile(name, data, perm)
+	}
+	merged, err := inc.merge(name, data)
+	if err != nil {
+		return fmt.Errorf("error merging %s: %w", name, err)
+	}
+	return inc.fsa.WriteFile(name, merged, perm)
+}
+
+// Close closes the output.  For ZIP output, if the export was committed, the
+// files of the previous export that were not overwritten are copied into the
+// new one, and the previous export is replaced.  Otherwise, the previous
+// export is left intact.
+func (inc *incremental) Close() error {
+	if inc.zr == nil {
+		return inc.fsa.Close()
+	}
+	defer inc.zr.Close()
+	inc.mu.Lock()
+	committed := inc.committed
+	inc.mu.Unlock()
+	if !committed {
+		inc.fsa.Close()
+		return os.Remove(inc.tmp)
+	}
+	if err := inc.copyPrev(); err != nil {
+		inc.fsa.Close()
+		os.Remove(inc.tmp)
+		return fmt.Errorf("error copying the previous export: %w", err)
+	}
+	if err := inc.fsa.Close(); err != nil {
+		os.Remove(inc.tmp)
+		return err
+	}
+	inc.zr.Close()
+	return os.Rename(inc.tmp, inc.output)
+}
+
+// copyPrev copies the files of the previous ZIP export that were not written
+// in this run.
+func (inc *incremental) copyPrev() error {
+	for _, zf := range inc.zr.File {
+		if zf.FileInfo().IsDir() || inc.isWritten(zf.Name) {
+			continue
+		}
+		if err := inc.copyFile(zf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (inc *incremental) copyFile(zf *zip.File) error {
+	rc, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	wc, err := mtimefs.Create(inc.fsa, zf.Name, zf.Modified)
+	if err != nil {
+		return err
+	}
+	defer wc.Close()
+	if _, err := io.Copy(wc, rc); err != nil {
+		return fmt.Errorf("%s: %w", zf.Name, err)
+	}
+	return wc.Close()
+}
+
+func (inc *incremental) isMergeable(name string) bool {
+	name = filepath.ToSlash(filepath.Clean(name))
+	return indexFiles[name] || dayFileRE.MatchString(name)
+}
+
+func (inc *incremental) markWritten(name string) {
+	inc.mu.Lock()
+	defer inc.mu.Unlock()
+	inc.written[filepath.ToSlash(filepath.Clean(name))] = true
+}
+
+func (inc *incremental) isWritten(name string) bool {
+	inc.mu.Lock()
+	defer inc.mu.Unlock()
+	return inc.written[path.Clean(name)]
+}
+
+// merge merges data with the file name from the previous export, if it
+// exists.
+func (inc *incremental) merge(name string, data []byte) ([]byte, error) {
+	if inc.prev == nil {
+		return data, nil
+	}
+	old, err := fs.ReadFile(inc.prev, filepath.ToSlash(filepath.Clean(name)))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return data, nil
+		}
+		return nil, err
+	}
+	if indexFiles[filepath.ToSlash(filepath.Clean(name))] {
+		return mergeJSON(old, data, "id", false)
+	}
+	return mergeJSON(old, data, "ts", true)
+}
+
+// mergeJSON merges two JSON arrays of objects, identified by the key.
+// Objects from the newer array replace the objects with the same key in the
+// older array, the rest are appended.  If byTS is true, the result is sorted
+// by the key, that is expected to be the Slack timestamp.
+func mergeJSON(older, newer []byte, key string, byTS bool) ([]byte, error) {
+	var oldItems, newItems []json.RawMessage
+	if err := json.Unmarshal(older, &oldItems); err != nil {
+		return nil, fmt.Errorf("previous export: %w", err)
+	}
+	if err := json.Unmarshal(newer, &newItems); err != nil {
+		return nil, err
+	}
+	keyOf := func(raw json.RawMessage) string {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return ""
+		}
+		var k string
+		_ = json.Unmarshal(obj[key], &k)
+		return k
+	}
+	var (
+		result = make([]json.RawMessage, 0, len(oldItems)+len(newItems))
+		keys   = make([]string, 0, len(oldItems)+len(newItems))
+		idx    = make(map[string]int, len(oldItems))
+	)
+	add := func(raw json.RawMessage) {
+		k := keyOf(raw)
+		if i, ok := idx[k]; ok && k != "" {
+			result[i] = raw
+			return
+		}
+		idx[k] = len(result)
+		result = append(result, raw)
+		keys = append(keys, k)
+	}
+	for _, raw := range oldItems {
+		add(raw)
+	}
+	for _, raw := range newItems {
+		add(raw)
+	}
+	if byTS {
+		ts := make([]int64, len(keys))
+		for i, k := range keys {
+			ts[i], _ = fasttime.TS2int(k)
+		}
+		sort.Stable(byKey{result, ts})
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type byKey struct {
+	items []json.RawMessage
+	ts    []int64
+}
+
+func (b byKey) Len() int           { return len(b.items) }
+func (b byKey) Less(i, j int) bool { return b.ts[i] < b.ts[j] }
+func (b byKey) Swap(i, j int) {
+	b.items[i], b.items[j] = b.items[j], b.items[i]
+	b.ts[i], b.ts[j] = b.ts[j], b.ts[i]
+}
+
+// mergeWriter buffers the file contents and merges it into the output on
+// Close.
+type mergeWriter struct {
+	name   string
+	inc    *incremental
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (w *mergeWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *mergeWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	err := w.inc.WriteFile(w.name, w.buf.Bytes(), 0o644)
+	if err != nil {
+		w.inc.mu.Lock()
+		if w.inc.err == nil {
+			w.inc.err = err
+		}
+		w.inc.mu.Unlock()
+	}
+	return err
+}
+
+var _ fsadapter.FSCloser = (*incremental)(nil)
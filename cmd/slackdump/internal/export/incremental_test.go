@@ -0,0 +1,155 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v3/internal/manifest"
+)
+
+func Test_mergeJSON(t *testing.T) {
+	older := []byte(`[{"ts":"1700000002.000000","text":"b"},{"ts":"1700000001.000000","text":"a"}]`)
+	newer := []byte(`[{"ts":"1700000002.000000","text":"b edited"},{"ts":"1700000003.000000","text":"c"}]`)
+
+	got, err := mergeJSON(older, newer, "ts", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []struct{ TS, Text string }
+	if err := json.Unmarshal(got, &msgs); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []struct{ TS, Text string }{
+		{"1700000001.000000", "a"},
+		{"1700000002.000000", "b edited"},
+		{"1700000003.000000", "c"},
+	}, msgs)
+
+	if _, err := mergeJSON([]byte(`{`), newer, "ts", true); err == nil {
+		t.Error("expected error on invalid previous file")
+	}
+}
+
+// writeExport writes the files with the incremental adapter, commits and
+// closes it.
+func writeExport(t *testing.T, output string, files map[string]string) {
+	t.Helper()
+	inc, err := openIncremental(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		wc, err := inc.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wc.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		if err := wc.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := inc.mf.Advance("C1", "1700000001.000000"); err != nil {
+		t.Fatal(err)
+	}
+	if err := inc.commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := inc.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIncremental(t *testing.T) {
+	first := map[string]string{
+		"channels.json":           `[{"id":"C1","name":"general"}]`,
+		"general/2023-11-14.json": `[{"ts":"1700000001.000000","text":"a"}]`,
+		"attachments/F1-file.txt": "file",
+	}
+	second := map[string]string{
+		"channels.json":           `[{"id":"C2","name":"random"}]`,
+		"general/2023-11-14.json": `[{"ts":"1700000002.000000","text":"b"}]`,
+	}
+	check := func(t *testing.T, read func(name string) []byte) {
+		t.Helper()
+		var chans []struct{ ID string }
+		if err := json.Unmarshal(read("channels.json"), &chans); err != nil {
+			t.Fatal(err)
+		}
+		assert.Len(t, chans, 2)
+		var msgs []struct{ TS string }
+		if err := json.Unmarshal(read("general/2023-11-14.json"), &msgs); err != nil {
+			t.Fatal(err)
+		}
+		assert.Len(t, msgs, 2)
+		assert.Equal(t, "file", string(read("attachments/F1-file.txt")))
+		mf, err := manifest.Read(bytes.NewReader(read(manifest.Filename)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Len(t, mf.RunIDs, 2)
+		_, ok := mf.HighWater("C1")
+		assert.True(t, ok)
+	}
+
+	t.Run("directory", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "export")
+		writeExport(t, dir, first)
+		writeExport(t, dir, second)
+		check(t, func(name string) []byte {
+			data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return data
+		})
+	})
+	t.Run("zip", func(t *testing.T) {
+		zipname := filepath.Join(t.TempDir(), "export.zip")
+		writeExport(t, zipname, first)
+		writeExport(t, zipname, second)
+		zr, err := zip.OpenReader(zipname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer zr.Close()
+		check(t, func(name string) []byte {
+			f, err := zr.Open(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			var buf bytes.Buffer
+			if _, err := buf.ReadFrom(f); err != nil {
+				t.Fatal(err)
+			}
+			return buf.Bytes()
+		})
+		matches, _ := filepath.Glob(zipname + ".*.tmp")
+		assert.Empty(t, matches, "temporary files left")
+	})
+	t.Run("not committed", func(t *testing.T) {
+		zipname := filepath.Join(t.TempDir(), "export.zip")
+		writeExport(t, zipname, first)
+		before, _ := os.ReadFile(zipname)
+		inc, err := openIncremental(zipname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := inc.WriteFile("channels.json", []byte(`[]`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := inc.Close(); err != nil {
+			t.Fatal(err)
+		}
+		after, _ := os.ReadFile(zipname)
+		assert.Equal(t, before, after, "previous export must be left intact")
+	})
+}
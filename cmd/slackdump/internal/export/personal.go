@@ -0,0 +1,135 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3"
+	"github.com/rusq/slackdump/v3/auth"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/internal/edge"
+	"github.com/rusq/slackdump/v3/internal/network"
+)
+
+const (
+	// personalDir is the directory in the export, where the personal data of
+	// the current user is saved.
+	personalDir = "personal"
+
+	scheduledFile = "scheduled_messages.json"
+	draftsFile    = "drafts.json"
+)
+
+// personalFetcher is the source of the personal data of the current user.
+type personalFetcher interface {
+	ScheduledMessages(ctx context.Context) ([]slack.ScheduledMessage, error)
+	Drafts(ctx context.Context) ([]edge.Draft, error)
+}
+
+// exportPersonal saves the scheduled messages and drafts of the current user
+// to the personal directory of the export.  The data is not available with
+// all token types, so any of them that can't be fetched is skipped with a
+// warning, and only the context errors are returned.
+func exportPersonal(ctx context.Context, pf personalFetcher, fsa fsadapter.FS) error {
+	lg := cfg.Log.With("in", "exportPersonal")
+
+	scheduled, err := pf.ScheduledMessages(ctx)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err != nil {
+		lg.WarnContext(ctx, "unable to fetch scheduled messages, skipping", "error", err)
+	} else {
+		if scheduled == nil {
+			scheduled = []slack.ScheduledMessage{} // "[]" rather than "null"
+		}
+		if err := writeJSON(fsa, path.Join(personalDir, scheduledFile), scheduled); err != nil {
+			return err
+		}
+		lg.InfoContext(ctx, "scheduled messages saved", "count", len(scheduled))
+	}
+
+	drafts, err := pf.Drafts(ctx)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err != nil {
+		lg.WarnContext(ctx, "unable to fetch drafts, skipping", "error", err)
+	} else {
+		if drafts == nil {
+			drafts = []edge.Draft{}
+		}
+		if err := writeJSON(fsa, path.Join(personalDir, draftsFile), drafts); err != nil {
+			return err
+		}
+		lg.InfoContext(ctx, "drafts saved", "count", len(drafts))
+	}
+	return nil
+}
+
+func writeJSON(fsa fsadapter.FS, name string, v any) error {
+	wc, err := fsa.Create(name)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", name, err)
+	}
+	enc := json.NewEncoder(wc)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		wc.Close()
+		return fmt.Errorf("error encoding %s: %w", name, err)
+	}
+	return wc.Close()
+}
+
+// apiPersonal fetches the personal data using the Slack API.
+type apiPersonal struct {
+	sess *slackdump.Session
+	prov auth.Provider
+}
+
+// ScheduledMessages returns all scheduled messages of the current user in all
+// conversations.
+func (p apiPersonal) ScheduledMessages(ctx context.Context) ([]slack.ScheduledMessage, error) {
+	cl := p.sess.Client()
+	lim := network.NewLimiter(network.Tier3, cfg.Limits.Tier3.Burst, int(cfg.Limits.Tier3.Boost))
+	params := &slack.GetScheduledMessagesParameters{
+		TeamID: p.sess.Info().TeamID,
+		Limit:  100,
+	}
+	var mm []slack.ScheduledMessage
+	for {
+		var (
+			page   []slack.ScheduledMessage
+			cursor string
+		)
+		if err := network.WithRetry(ctx, lim, cfg.Limits.Tier3.Retries, func() error {
+			var err error
+			page, cursor, err = cl.GetScheduledMessagesContext(ctx, params)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		mm = append(mm, page...)
+		if cursor == "" {
+			break
+		}
+		params.Cursor = cursor
+	}
+	return mm, nil
+}
+
+// Drafts returns the drafts of the current user, the drafts are available
+// only through the client API.
+func (p apiPersonal) Drafts(ctx context.Context) ([]edge.Draft, error) {
+	cl, err := edge.NewWithInfo(p.sess.Info(), p.prov)
+	if err != nil {
+		return nil, err
+	}
+	defer cl.Close()
+	return cl.DraftsList(ctx)
+}
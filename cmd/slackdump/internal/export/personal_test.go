@@ -0,0 +1,80 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/edge"
+)
+
+type fakePersonal struct {
+	scheduled    []slack.ScheduledMessage
+	scheduledErr error
+	drafts       []edge.Draft
+	draftsErr    error
+}
+
+func (f fakePersonal) ScheduledMessages(ctx context.Context) ([]slack.ScheduledMessage, error) {
+	return f.scheduled, f.scheduledErr
+}
+
+func (f fakePersonal) Drafts(ctx context.Context) ([]edge.Draft, error) {
+	return f.drafts, f.draftsErr
+}
+
+func Test_exportPersonal(t *testing.T) {
+	t.Run("all available", func(t *testing.T) {
+		dir := t.TempDir()
+		pf := fakePersonal{
+			scheduled: []slack.ScheduledMessage{{ID: "Q1", Channel: "C1", PostAt: 1700000000, Text: "later"}},
+			drafts:    []edge.Draft{{ID: "Dr1", UserID: "U1", Destinations: []edge.DraftDestination{{ChannelID: "C2"}}}},
+		}
+		require.NoError(t, exportPersonal(context.Background(), pf, fsadapter.NewDirectory(dir)))
+
+		var gotScheduled []slack.ScheduledMessage
+		readJSON(t, filepath.Join(dir, personalDir, scheduledFile), &gotScheduled)
+		assert.Equal(t, pf.scheduled, gotScheduled)
+
+		var gotDrafts []edge.Draft
+		readJSON(t, filepath.Join(dir, personalDir, draftsFile), &gotDrafts)
+		assert.Equal(t, pf.drafts, gotDrafts)
+	})
+	t.Run("drafts not permitted", func(t *testing.T) {
+		dir := t.TempDir()
+		pf := fakePersonal{
+			draftsErr: errors.New("not_allowed_token_type"),
+		}
+		require.NoError(t, exportPersonal(context.Background(), pf, fsadapter.NewDirectory(dir)))
+
+		var gotScheduled []slack.ScheduledMessage
+		readJSON(t, filepath.Join(dir, personalDir, scheduledFile), &gotScheduled)
+		assert.NotNil(t, gotScheduled)
+		assert.Empty(t, gotScheduled)
+		assert.NoFileExists(t, filepath.Join(dir, personalDir, draftsFile))
+	})
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		pf := fakePersonal{
+			scheduledErr: context.Canceled,
+		}
+		err := exportPersonal(ctx, pf, fsadapter.NewDirectory(t.TempDir()))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func readJSON(t *testing.T, name string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, v))
+}
@@ -0,0 +1,24 @@
+package export
+
+import (
+	"context"
+
+	"github.com/rusq/fsadapter"
+
+	"github.com/rusq/slackdump/v3"
+	"github.com/rusq/slackdump/v3/internal/chunk/transform"
+	"github.com/rusq/slackdump/v3/internal/chunk/transform/fileproc"
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+// Replay runs the export of the conversations in the list with the default
+// options to fsa, without downloading the files.  It is used to reproduce
+// the export of the recorded conversations, served by the session sess, see
+// "slackdump tools replay-verify".
+func Replay(ctx context.Context, sess *slackdump.Session, fsa fsadapter.FS, list *structures.EntityList) error {
+	return export(ctx, sess, fsa, list, exportFlags{
+		ExportStorageType: fileproc.STnone,
+		Layout:            transform.LayoutFlat,
+		replay:            true,
+	})
+}
@@ -0,0 +1,213 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/chunk/control"
+	"github.com/rusq/slackdump/v3/internal/chunk/state"
+)
+
+// stateFilename is the name of the resume state file within the chunk
+// directory.
+const stateFilename = "export.state.json"
+
+// loadResumeState loads the state of the interrupted export.  The chunk
+// directory of the interrupted export is referenced by the state.
+func loadResumeState(filename string) (*state.State, error) {
+	st, err := state.Load(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error loading the resume state: %w", err)
+	}
+	if st.IsComplete {
+		return nil, errors.New("export is already complete, nothing to resume")
+	}
+	if fi, err := os.Stat(st.ChunkFilename); err != nil {
+		return nil, fmt.Errorf("chunk directory of the interrupted export: %w", err)
+	} else if !fi.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", st.ChunkFilename)
+	}
+	return st, nil
+}
+
+// saveResumeState saves the state of the interrupted export into the chunk
+// directory, and returns the path of the state file.  Only the channels that
+// were recorded completely are added to the state, all other channels will
+// be fetched again on resume.
+func saveResumeState(cd *chunk.Directory, dir string, output string) (string, error) {
+	st, err := resumeState(cd, dir, output)
+	if err != nil {
+		return "", err
+	}
+	filename := filepath.Join(dir, stateFilename)
+	if err := st.Save(filename); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// resumeState returns the resume state with the channels, that were
+// recorded completely in the chunk directory.
+func resumeState(cd *chunk.Directory, dir string, output string) (*state.State, error) {
+	st := state.New(dir).SetFilesDir(output)
+	ids, err := channelFileIDs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		channelID, _ := id.Split()
+		if err := addComplete(st, cd, id, channelID); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+	}
+	return st, nil
+}
+
+// resumeTracker keeps the resume state in the chunk directory up to date
+// while the export is running, adding each channel once it is recorded
+// completely.  This allows to resume the export, even if the process was
+// killed, and the state was not saved on exit.
+type resumeTracker struct {
+	control.ExportTransformer
+
+	cd       *chunk.Directory
+	st       *state.State
+	filename string
+	lg       *slog.Logger
+	mu       sync.Mutex // serialises the saves
+}
+
+// newResumeTracker saves the initial resume state into the chunk directory
+// dir, and returns the tracker, that wraps the transformer tf.
+func newResumeTracker(tf control.ExportTransformer, cd *chunk.Directory, dir string, output string, lg *slog.Logger) (*resumeTracker, error) {
+	st, err := resumeState(cd, dir, output)
+	if err != nil {
+		return nil, err
+	}
+	t := &resumeTracker{
+		ExportTransformer: tf,
+		cd:                cd,
+		st:                st,
+		filename:          filepath.Join(dir, stateFilename),
+		lg:                lg,
+	}
+	if err := st.Save(t.filename); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Transform transforms the channel or thread, and, if it was the channel,
+// adds it to the resume state.  Failing to update the state is not fatal,
+// the channel is fetched again on resume.
+func (t *resumeTracker) Transform(ctx context.Context, id chunk.FileID) error {
+	if err := t.ExportTransformer.Transform(ctx, id); err != nil {
+		return err
+	}
+	channelID, threadTS := id.Split()
+	if threadTS != "" {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := addComplete(t.st, t.cd, id, channelID); err != nil {
+		t.lg.WarnContext(ctx, "unable to update the resume state", "channel_id", channelID, "error", err)
+		return nil
+	}
+	if err := t.st.Save(t.filename); err != nil {
+		t.lg.WarnContext(ctx, "unable to save the resume state", "filename", t.filename, "error", err)
+	}
+	return nil
+}
+
+// Complete marks the export complete in the state file, so that it can't be
+// resumed, if the chunk directory is retained.
+func (t *resumeTracker) Complete() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.st.SetIsComplete(true).Save(t.filename)
+}
+
+// addComplete adds the channel to the state, if it was recorded completely.
+func addComplete(st *state.State, cd *chunk.Directory, id chunk.FileID, channelID string) error {
+	f, err := cd.Open(id)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	ok, err := f.IsComplete(channelID)
+	if err != nil || !ok {
+		return err
+	}
+	fst, err := f.State()
+	if err != nil {
+		return err
+	}
+	st.AddMessage(channelID, fst.LatestChannelTS(channelID))
+	return nil
+}
+
+// prepareResume removes all files from the chunk directory, except the chunk
+// files of the channels.  Removed files will be fetched again.  The channels
+// that are complete in the state are not fetched, the partial chunk files of
+// the rest are continued by the controller.
+func prepareResume(dir string, st *state.State) (complete int, partial int, err error) {
+	ids, err := channelFileIDs(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	keep := make(map[chunk.FileID]bool, len(ids))
+	for _, id := range ids {
+		keep[id] = true
+		if channelID, _ := id.Split(); st.HasChannel(channelID) {
+			complete++
+		} else {
+			partial++
+		}
+	}
+	des, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, de := range des {
+		name := de.Name()
+		if id, ok := chunk.FileIDOf(name); de.IsDir() || !ok || keep[id] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return 0, 0, err
+		}
+	}
+	return complete, partial, nil
+}
+
+// channelFileIDs returns the file IDs of all channel chunk files in the
+// directory.  Thread-only files are not included.
+func channelFileIDs(dir string) ([]chunk.FileID, error) {
+	des, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []chunk.FileID
+	for _, de := range des {
+		id, ok := chunk.FileIDOf(de.Name())
+		if de.IsDir() || !ok {
+			continue
+		}
+		switch id {
+		case chunk.FChannels, chunk.FUsers, chunk.FWorkspace, chunk.FSearch, chunk.FUserGroups:
+			continue
+		}
+		if _, threadTS := id.Split(); threadTS != "" {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
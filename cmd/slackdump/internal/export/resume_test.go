@@ -0,0 +1,66 @@
+package export
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/chunk/state"
+)
+
+// fakeTransformer records the transformed file IDs.
+type fakeTransformer struct {
+	ids []chunk.FileID
+}
+
+func (f *fakeTransformer) Transform(_ context.Context, id chunk.FileID) error {
+	f.ids = append(f.ids, id)
+	return nil
+}
+
+func (f *fakeTransformer) StartWithUsers(context.Context, []slack.User) error {
+	return nil
+}
+
+func TestResumeTracker(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cd, err := chunk.OpenDir(dir)
+	require.NoError(t, err)
+	defer cd.Close()
+
+	var ft fakeTransformer
+	rt, err := newResumeTracker(&ft, cd, dir, "out", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	stfile := filepath.Join(dir, stateFilename)
+	// the state is saved at the start.
+	st, err := state.Load(stfile)
+	require.NoError(t, err)
+	assert.False(t, st.HasChannel("C1"))
+
+	// record the complete channel.
+	id := chunk.ToFileID("C1", "", false)
+	wc, err := cd.Create(id)
+	require.NoError(t, err)
+	rec := chunk.NewRecorder(wc)
+	require.NoError(t, rec.Messages(ctx, "C1", 0, true, []slack.Message{{Msg: slack.Msg{Timestamp: "1700000001.000000", Text: "hello"}}}))
+	require.NoError(t, rec.Close())
+	require.NoError(t, wc.Close())
+
+	require.NoError(t, rt.Transform(ctx, id))
+	assert.Equal(t, []chunk.FileID{id}, ft.ids)
+	st, err = state.Load(stfile)
+	require.NoError(t, err)
+	assert.True(t, st.HasChannel("C1"), "complete channel is not in the state")
+
+	require.NoError(t, rt.Complete())
+	_, err = loadResumeState(stfile)
+	assert.Error(t, err, "complete export must not be resumable")
+}
@@ -2,6 +2,7 @@ package export
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"time"
@@ -11,35 +12,67 @@ import (
 	"github.com/schollz/progressbar/v3"
 
 	"github.com/rusq/slackdump/v3"
+	"github.com/rusq/slackdump/v3/auth"
 
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/bootstrap"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/downloader"
+	"github.com/rusq/slackdump/v3/internal/activity"
 	"github.com/rusq/slackdump/v3/internal/chunk"
 	"github.com/rusq/slackdump/v3/internal/chunk/control"
 	"github.com/rusq/slackdump/v3/internal/chunk/transform"
 	"github.com/rusq/slackdump/v3/internal/chunk/transform/fileproc"
+	"github.com/rusq/slackdump/v3/internal/errreport"
+	"github.com/rusq/slackdump/v3/internal/filestats"
+	"github.com/rusq/slackdump/v3/internal/manifest"
+	"github.com/rusq/slackdump/v3/internal/remotefs"
+	"github.com/rusq/slackdump/v3/internal/runid"
 	"github.com/rusq/slackdump/v3/internal/structures"
 	"github.com/rusq/slackdump/v3/stream"
 )
 
 // export runs the export v3.
-func export(ctx context.Context, sess *slackdump.Session, fsa fsadapter.FS, list *structures.EntityList, params exportFlags) error {
+func export(ctx context.Context, sess *slackdump.Session, fsa fsadapter.FS, list *structures.EntityList, params exportFlags) (err error) {
 	lg := cfg.Log
 
-	tmpdir, err := os.MkdirTemp("", "slackdump-*")
-	if err != nil {
-		return err
+	var tmpdir string
+	if st := params.resumeState; st != nil {
+		tmpdir = st.ChunkFilename
+		complete, partial, err := prepareResume(tmpdir, st)
+		if err != nil {
+			return err
+		}
+		lg.InfoContext(ctx, "resuming export", "tmpdir", tmpdir, "complete_channels", complete, "partial_channels", partial)
+	} else {
+		tmpdir, err = os.MkdirTemp("", "slackdump-*")
+		if err != nil {
+			return err
+		}
+		lg.InfoContext(ctx, "temporary directory in use", "tmpdir", tmpdir)
 	}
 
-	lg.InfoContext(ctx, "temporary directory in use", "tmpdir", tmpdir)
-	chunkdir, err := chunk.OpenDir(tmpdir)
+	chunkdir, err := chunk.OpenDir(tmpdir, chunk.WithCompression(params.compress))
 	if err != nil {
 		return err
 	}
 	defer chunkdir.Close()
-	if !lg.Enabled(ctx, slog.LevelDebug) {
-		defer func() { _ = chunkdir.RemoveAll() }()
-	}
+	// the incremental export is not resumed, the next run continues from the
+	// high-water marks.
+	resumable := params.inc == nil && !params.replay && !isZIP(cfg.Output) && !remotefs.IsRemote(cfg.Output)
+	defer func() {
+		if err != nil && resumable {
+			// retaining the chunk directory to be able to resume.
+			if stfile, serr := saveResumeState(chunkdir, tmpdir, cfg.Output); serr != nil {
+				lg.ErrorContext(ctx, "unable to save the resume state", "error", serr)
+			} else {
+				lg.InfoContext(ctx, "export interrupted, to resume, run: slackdump export -resume "+stfile+" [same arguments]", "state_file", stfile)
+				return
+			}
+		}
+		if !lg.Enabled(ctx, slog.LevelDebug) {
+			_ = chunkdir.RemoveAll()
+		}
+	}()
 	updFn := func() func(_ *slack.Channel, m *slack.Message) error {
 		// hack: wrapper around the message update function, which does not
 		// have the channel parameter.  TODO: fix this in the library.
@@ -48,54 +81,187 @@ func export(ctx context.Context, sess *slackdump.Session, fsa fsadapter.FS, list
 			return fn(m)
 		}
 	}
-	conv := transform.NewExpConverter(chunkdir, fsa, transform.ExpWithMsgUpdateFunc(updFn()))
+	// the manifest records the downloaded files, so that the later exports
+	// can skip them, and the day files split into parts.
+	mf := manifest.New()
+	if params.inc != nil {
+		mf = params.inc.mf
+	}
+	conv := transform.NewExpConverter(chunkdir, fsa,
+		transform.ExpWithMsgUpdateFunc(updFn()),
+		transform.ExpWithMembers(params.Members),
+		transform.ExpWithParticipants(params.Participants || params.UsersIndex),
+		transform.ExpWithRFC3339(params.RFC3339),
+		transform.ExpWithLayout(params.Layout),
+		transform.ExpWithMaxMessages(params.MaxMessages),
+		transform.ExpWithPartsFunc(mf.AddParts),
+		transform.ExpWithSlackImport(params.SlackImport),
+	)
 	tf := transform.NewExportCoordinator(ctx, conv, transform.WithBufferSize(1000))
 	defer tf.Close()
+	var ctrTf control.ExportTransformer = tf
+	var rt *resumeTracker
+	if resumable {
+		// the state is saved as the channels complete, and not only on
+		// exit, in case the process is killed.
+		if rt, err = newResumeTracker(tf, chunkdir, tmpdir, cfg.Output, lg); err != nil {
+			return fmt.Errorf("error saving the resume state: %w", err)
+		}
+		ctrTf = rt
+		lg.DebugContext(ctx, "resume state is saved as the channels complete", "state_file", rt.filename)
+	}
+	if cfg.Monitor != nil {
+		cfg.Monitor.Queue("export_transform", tf.Pending)
+		defer cfg.Monitor.Queue("export_transform", nil)
+	}
+
+	// the report is written after the downloader is stopped.
+	rep := errreport.New()
+	defer bootstrap.FinishReport(ctx, fsa, rep)
+	sched, err := bootstrap.RetentionScheduler(ctx, sess)
+	if err != nil {
+		return err
+	}
+	defer bootstrap.ReportRetention(ctx, sched, rep)
+	dlState, tracker := bootstrap.DownloadState(cfg.Output)
+	defer bootstrap.FinishDownloadState(ctx, fsa, dlState)
+
+	pb := bootstrap.ProgressBar(ctx, lg, progressbar.OptionShowCount()) // progress bar
+	pt := bootstrap.ProgressTracker(pb)
 
 	// starting the downloader
 	dlEnabled := cfg.DownloadFiles && params.ExportStorageType != fileproc.STnone
-	sdl, stop := fileproc.NewDownloader(ctx, dlEnabled, sess.Client(), fsa, lg)
+	mfTracker := fileproc.NewManifestTracker(mf)
+	dlOpts := append(sess.DownloadOptions(), downloader.WithErrorFunc(rep.DownloadError), tracker, downloader.WithTracker(pt.Downloads()), downloader.WithTracker(mfTracker))
+	// with the content storage, the files are saved once in the shared pool.
+	var refs *fileproc.ContentRefs
+	if params.ExportStorageType == fileproc.STcontent {
+		refs = fileproc.NewContentRefs()
+		dlOpts = append(dlOpts, downloader.WithContentPath(fileproc.ContentFilepath), downloader.WithTracker(refs))
+	}
+	sdl, stop := fileproc.NewDownloader(ctx, dlEnabled, sess.Client(), fsa, lg, dlOpts...)
 	defer stop()
-
-	pb := bootstrap.ProgressBar(ctx, lg, progressbar.OptionShowCount()) // progress bar
+	if dlEnabled && params.existing != nil {
+		opts := []fileproc.ExistingOption{fileproc.ExistingTracker(mfTracker), fileproc.ExistingLogger(lg)}
+		if params.existingDir != "" {
+			opts = append(opts, fileproc.ExistingFrom(params.existingDir))
+			if !isZIP(cfg.Output) && !remotefs.IsRemote(cfg.Output) {
+				opts = append(opts, fileproc.ExistingLinkTo(cfg.Output))
+			}
+		}
+		lg.InfoContext(ctx, "files of the previous export will not be downloaded", "count", params.existing.FileCount(), "location", params.SkipExistingFiles)
+		sdl = fileproc.NewExistingFiles(sdl, params.existing, fsa, opts...)
+	}
 
 	stream := sess.Stream(
 		stream.OptOldest(time.Time(cfg.Oldest)),
 		stream.OptLatest(time.Time(cfg.Latest)),
+		stream.OptChannelSplit(cfg.ChannelSplit),
+		stream.OptPins(cfg.Pins),
 		stream.OptResultFn(func(sr stream.Result) error {
 			lg.DebugContext(ctx, "conversations", "sr", sr.String())
-			pb.Describe(sr.String())
-			_ = pb.Add(1)
 			return nil
 		}),
+		stream.OptProgress(pt),
+		stream.OptErrorFn(rep.StreamError),
 	)
 
+	filer := fileproc.NewExportLayout(params.ExportStorageType, params.Layout, sdl)
+	if refs != nil {
+		filer = fileproc.NewContentExport(params.Layout, sdl, refs)
+	} else if params.fileTmpl != nil {
+		filer = fileproc.NewExportTemplate(params.fileTmpl, params.Layout, sdl)
+	}
 	flags := control.Flags{
-		MemberOnly: cfg.MemberOnly,
+		MemberOnly:      cfg.MemberOnly,
+		ChannelUsers:    params.ChannelUsers,
+		UserGroups:      params.UserGroups,
+		ExcludeArchived: !cfg.IncludeArchived,
+		ExcludeShared:   !cfg.IncludeShared,
 	}
-	ctr := control.New(
-		chunkdir,
-		stream,
-		control.WithFiler(fileproc.NewExport(params.ExportStorageType, sdl)),
+	opts := []control.Option{
+		control.WithFiler(filer),
 		control.WithLogger(lg),
 		control.WithFlags(flags),
-		control.WithTransformer(tf),
-	)
+		control.WithTransformer(ctrTf),
+		control.WithResumeState(params.resumeState),
+		control.WithAnnotations(cfg.Annotations()...),
+	}
+	if params.inc != nil {
+		opts = append(opts, control.WithHighWater(params.inc.highWater()))
+	}
+	if sched != nil {
+		opts = append(opts, control.WithRetention(sched))
+	}
+	if mw := cfg.Middlewares(); len(mw) > 0 {
+		opts = append(opts, control.WithMiddleware(mw...))
+	}
+	ctr := control.New(chunkdir, stream, opts...)
 
 	lg.InfoContext(ctx, "running export...")
 	if err := ctr.Run(ctx, list); err != nil {
 		_ = pb.Finish()
 		return err
 	}
+	// waiting for the downloads to complete, so that all files are recorded
+	// in the manifest.
+	stop()
+	pt.Finish()
 	_ = pb.Finish()
 	// at this point no goroutines are running, we are safe to assume that
 	// everything we need is in the chunk directory.
 	if err := conv.WriteIndex(); err != nil {
 		return err
 	}
+	if refs != nil {
+		if err := refs.Write(fsa); err != nil {
+			return fmt.Errorf("error writing the file references: %w", err)
+		}
+	}
 	if err := tf.Close(); err != nil {
 		return err
 	}
+	if params.StorageReport {
+		if err := writeStorageReport(ctx, fsa, chunkdir); err != nil {
+			return fmt.Errorf("error writing the storage report: %w", err)
+		}
+	}
+	if params.UsersIndex {
+		if err := writeUsersIndex(ctx, fsa, chunkdir); err != nil {
+			return fmt.Errorf("error writing the users index: %w", err)
+		}
+	}
+	if params.Governance {
+		prov, err := auth.FromContext(ctx)
+		if err != nil {
+			return err
+		}
+		if err := writeGovernance(ctx, apiGovernance{sess: sess, prov: prov}, fsa, chunkdir); err != nil {
+			return fmt.Errorf("error writing the governance report: %w", err)
+		}
+	}
+	if params.inc != nil {
+		if err := params.inc.advance(chunkdir); err != nil {
+			return fmt.Errorf("error updating high-water marks: %w", err)
+		}
+		if err := params.inc.annotate(chunkdir); err != nil {
+			return fmt.Errorf("error reading annotations: %w", err)
+		}
+		if err := params.inc.commit(); err != nil {
+			return err
+		}
+		lg.InfoContext(ctx, "incremental export manifest updated", "channels", len(params.inc.mf.ChannelIDs()))
+	} else if mf.FileCount() > 0 || mf.PartCount() > 0 {
+		mf.AddRun(runid.ID())
+		if err := mf.Save(fsa); err != nil {
+			return fmt.Errorf("error saving manifest: %w", err)
+		}
+	}
+	if rt != nil {
+		if err := rt.Complete(); err != nil {
+			lg.WarnContext(ctx, "unable to mark the resume state complete", "error", err)
+		}
+	}
 	pb.Describe("OK")
 	lg.Debug("index written")
 	lg.InfoContext(ctx, "conversations export finished")
@@ -103,6 +269,49 @@ func export(ctx context.Context, sess *slackdump.Session, fsa fsadapter.FS, list
 	return nil
 }
 
+// storageReportFile is the name of the storage usage report file.
+const storageReportFile = "storage_report.txt"
+
+// writeStorageReport writes the storage usage report of the files in the
+// chunk directory to fsa.
+func writeStorageReport(ctx context.Context, fsa fsadapter.FS, cd *chunk.Directory) error {
+	rep, err := filestats.FromChunks(ctx, cd, filestats.DefTopN)
+	if err != nil {
+		return err
+	}
+	wc, err := fsa.Create(storageReportFile)
+	if err != nil {
+		return err
+	}
+	if err := rep.WriteText(wc, filestats.DefTopN); err != nil {
+		wc.Close()
+		return err
+	}
+	if err := wc.Close(); err != nil {
+		return err
+	}
+	cfg.Log.InfoContext(ctx, "storage report written", "file", storageReportFile, "files", rep.Files, "bytes", rep.Bytes)
+	return nil
+}
+
+// usersIndexFile is the name of the users cross-reference index file.
+const usersIndexFile = "users_index.json"
+
+// writeUsersIndex writes the users cross-reference index, built from the
+// messages in the chunk directory.  The participants summaries of the
+// channels are written by the converter.
+func writeUsersIndex(ctx context.Context, fsa fsadapter.FS, cd *chunk.Directory) error {
+	idx, err := activity.FromChunks(ctx, cd)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(fsa, usersIndexFile, idx.Users); err != nil {
+		return err
+	}
+	cfg.Log.InfoContext(ctx, "users index written", "file", usersIndexFile, "users", len(idx.Users), "channels", len(idx.Channels))
+	return nil
+}
+
 // progresser is an interface for progress bars.
 type progresser interface {
 	RenderBlank() error
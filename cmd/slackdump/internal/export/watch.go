@@ -0,0 +1,294 @@
+package export
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rusq/slackdump/v3"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/bootstrap"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/chunk/transform"
+	"github.com/rusq/slackdump/v3/internal/chunk/transform/fileproc"
+	"github.com/rusq/slackdump/v3/internal/manifest"
+	"github.com/rusq/slackdump/v3/internal/remotefs"
+	"github.com/rusq/slackdump/v3/internal/schedule"
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+var CmdWatch = &base.Command{
+	UsageLine:   "slackdump watch [flags] [link1[ link 2[ link N]]]",
+	Short:       "runs the incremental export continuously on schedule",
+	FlagMask:    cfg.OmitUserCacheFlag | cfg.OmitTimeframeFlag,
+	Long:        mdWatch,
+	PrintFlags:  true,
+	RequireAuth: true,
+}
+
+//go:embed assets/watch.md
+var mdWatch string
+
+// watchJob is the name of the monitor job of a single export run.
+const watchJob = "watch_export"
+
+type watchFlags struct {
+	Every  time.Duration
+	Cron   string
+	Rotate rotation
+	State  string
+
+	export exportFlags
+}
+
+var watchOptions = watchFlags{
+	Every:  time.Hour,
+	Rotate: rotateNone,
+	export: exportFlags{
+		ExportStorageType: fileproc.STmattermost,
+		Layout:            transform.LayoutFlat,
+	},
+}
+
+func init() {
+	CmdWatch.Flag.DurationVar(&watchOptions.Every, "every", watchOptions.Every, "run the export with the `interval`")
+	CmdWatch.Flag.StringVar(&watchOptions.Cron, "cron", "", "run the export on the cron `schedule`, i.e. \"0 */6 * * *\", overrides -every")
+	CmdWatch.Flag.Var(&watchOptions.Rotate, "rotate", "start a new output location: none, daily or monthly")
+	CmdWatch.Flag.StringVar(&watchOptions.State, "state", "", "state `file` with the high-water marks of the channels\n(default: next to the output location)")
+	CmdWatch.Flag.Var(&watchOptions.export.ExportStorageType, "type", "export file storage type")
+	CmdWatch.Flag.StringVar(&watchOptions.export.ExportToken, "export-token", "", "file export token to append to each of the file URLs")
+	CmdWatch.Flag.BoolVar(&watchOptions.export.Members, "members", false, "write the snapshot of the channel members to \""+transform.MembersFile+"\"\nin each channel directory")
+	CmdWatch.Flag.BoolVar(&watchOptions.export.ChannelUsers, "channel-users", false, "populate users.json only with the users that appear in the exported\nconversations, instead of listing all users of the workspace")
+
+	CmdWatch.Run = runWatch
+}
+
+// rotation is the output rotation period.
+type rotation string
+
+const (
+	rotateNone    rotation = "none"
+	rotateDaily   rotation = "daily"
+	rotateMonthly rotation = "monthly"
+)
+
+var _ flag.Value = new(rotation)
+
+func (r *rotation) String() string {
+	return string(*r)
+}
+
+func (r *rotation) Set(s string) error {
+	switch v := rotation(strings.ToLower(s)); v {
+	case rotateNone, rotateDaily, rotateMonthly:
+		*r = v
+		return nil
+	}
+	return fmt.Errorf("invalid rotation %q, must be one of: none, daily, monthly", s)
+}
+
+// location returns the output location for the time t: the date is
+// appended to the name of the directory or the ZIP file, i.e.
+// "backup.zip" becomes "backup-2024-01-02.zip" with the daily rotation.
+func (r rotation) location(output string, t time.Time) string {
+	var layout string
+	switch r {
+	case rotateDaily:
+		layout = "2006-01-02"
+	case rotateMonthly:
+		layout = "2006-01"
+	default:
+		return output
+	}
+	base, ext := splitExt(output)
+	return base + "-" + t.Format(layout) + ext
+}
+
+// splitExt splits the output location into the name and the ".zip"
+// extension, if it is a ZIP file.
+func splitExt(output string) (string, string) {
+	output = filepath.Clean(output)
+	if !isZIP(output) {
+		return output, ""
+	}
+	ext := filepath.Ext(output)
+	return strings.TrimSuffix(output, ext), ext
+}
+
+func runWatch(ctx context.Context, cmd *base.Command, args []string) error {
+	if !isFlagSet(&cmd.Flag, "o") && os.Getenv("BASE_LOC") == "" {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("use -o to set the output location, it must not change between the runs")
+	}
+	if remotefs.IsRemote(cfg.Output) {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("watch requires the local output location")
+	}
+	sched, err := watchOptions.schedule()
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	if !cfg.DownloadFiles {
+		watchOptions.export.ExportStorageType = fileproc.STnone
+	}
+	if watchOptions.export.ExportStorageType == fileproc.STcontent {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("-type content can't be used with watch")
+	}
+	output := cfg.Output
+	stateFile := watchOptions.State
+	if stateFile == "" {
+		name, _ := splitExt(output)
+		stateFile = name + ".watch.json"
+	}
+	st, err := loadWatchState(stateFile)
+	if err != nil {
+		base.SetExitStatus(base.SUserError)
+		return err
+	}
+	list, err := structures.NewEntityList(args)
+	if err != nil {
+		base.SetExitStatus(base.SUserError)
+		return fmt.Errorf("error parsing the entity list: %w", err)
+	}
+	sess, err := bootstrap.SlackdumpSession(ctx)
+	if err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	if err := bootstrap.ResolveChannelNames(ctx, sess, list); err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+
+	// the daemon is usually stopped by the service manager with SIGTERM.
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM)
+	defer stop()
+
+	lg := cfg.Log
+	lg.InfoContext(ctx, "watching", "schedule", sched, "output", output, "rotate", watchOptions.Rotate, "state_file", stateFile)
+	for {
+		start := time.Now()
+		if err := watchRun(ctx, sess, list, st, watchOptions.Rotate.location(output, start)); err != nil {
+			if ctx.Err() != nil {
+				return context.Cause(ctx)
+			}
+			// the next run continues from the last successful one.
+			lg.ErrorContext(ctx, "export run failed", "error", err)
+		} else if err := saveWatchState(stateFile, st); err != nil {
+			base.SetExitStatus(base.SApplicationError)
+			return err
+		}
+		next := sched.Next(time.Now())
+		if next.IsZero() {
+			base.SetExitStatus(base.SApplicationError)
+			return errors.New("no more runs scheduled")
+		}
+		lg.InfoContext(ctx, "next run scheduled", "at", next.Format(time.RFC3339))
+		t := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return context.Cause(ctx)
+		case <-t.C:
+		}
+	}
+}
+
+// schedule returns the schedule of the runs.
+func (wf watchFlags) schedule() (schedule.Schedule, error) {
+	if wf.Cron != "" {
+		return schedule.ParseCron(wf.Cron)
+	}
+	if wf.Every < time.Minute {
+		return nil, errors.New("-every must be at least one minute")
+	}
+	return schedule.Every(wf.Every), nil
+}
+
+// watchRun runs the incremental export into the output location.  The
+// high-water marks of the state st are carried over to the output, so that
+// the new output after the rotation receives only the new messages.  On
+// success, st is advanced to the marks of the output.
+func watchRun(ctx context.Context, sess *slackdump.Session, list *structures.EntityList, st *manifest.Manifest, output string) (err error) {
+	if cfg.Monitor != nil {
+		cfg.Monitor.Start(watchJob)
+		defer func() { cfg.Monitor.Finish(watchJob, err) }()
+	}
+	start := time.Now()
+	// the export reads the output location and the end of the time frame
+	// from the configuration.
+	cfg.Output = output
+	cfg.Latest = cfg.TimeValue(start)
+
+	inc, err := openIncremental(output)
+	if err != nil {
+		return err
+	}
+	inc.mf.Merge(st)
+	params := watchOptions.export
+	params.inc = inc
+	if err := export(ctx, sess, inc, list, params); err != nil {
+		return errors.Join(fmt.Errorf("export failed: %w", err), inc.Close())
+	}
+	if err := inc.Close(); err != nil {
+		return fmt.Errorf("error closing the output: %w", err)
+	}
+	st.Merge(inc.mf)
+	cfg.Log.InfoContext(ctx, "export run completed", "output", output, "took", time.Since(start).String())
+	return nil
+}
+
+// loadWatchState loads the state from the file.  If the file does not
+// exist, the new state is returned.
+func loadWatchState(filename string) (*manifest.Manifest, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return manifest.New(), nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	st, err := manifest.Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("state file %s: %w", filename, err)
+	}
+	return st, nil
+}
+
+// saveWatchState atomically replaces the state file with st.
+func saveWatchState(filename string, st *manifest.Manifest) error {
+	st.AddRun("")
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// isFlagSet returns true if the flag was set on the command line.
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	var set bool
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -0,0 +1,63 @@
+package export
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_rotation_location(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		r      rotation
+		output string
+		want   string
+	}{
+		{rotateNone, "backup.zip", "backup.zip"},
+		{rotateDaily, "backup.zip", "backup-2024-01-02.zip"},
+		{rotateDaily, "dir/backup.ZIP", "dir/backup-2024-01-02.ZIP"},
+		{rotateMonthly, "backup.zip", "backup-2024-01.zip"},
+		{rotateDaily, "backup/", "backup-2024-01-02"},
+		{rotateMonthly, "my.backup", "my.backup-2024-01"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, filepath.FromSlash(tt.want), tt.r.location(filepath.FromSlash(tt.output), ts), tt.output)
+	}
+}
+
+func Test_rotation_Set(t *testing.T) {
+	var r rotation
+	assert.NoError(t, r.Set("Daily"))
+	assert.Equal(t, rotateDaily, r)
+	assert.Error(t, r.Set("weekly"))
+}
+
+func Test_watchFlags_schedule(t *testing.T) {
+	s, err := watchFlags{Every: time.Hour, Cron: "0 * * * *"}.schedule()
+	assert.NoError(t, err)
+	assert.Equal(t, "0 * * * *", s.(interface{ String() string }).String())
+
+	_, err = watchFlags{Every: time.Second}.schedule()
+	assert.Error(t, err)
+	_, err = watchFlags{Cron: "0 0 31 2 *"}.schedule()
+	assert.Error(t, err)
+}
+
+func Test_watchState(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "backup.watch.json")
+	st, err := loadWatchState(filename)
+	assert.NoError(t, err)
+	assert.Empty(t, st.ChannelIDs())
+
+	_, err = st.Advance("C1", "1700000001.000100")
+	assert.NoError(t, err)
+	assert.NoError(t, saveWatchState(filename, st))
+
+	got, err := loadWatchState(filename)
+	assert.NoError(t, err)
+	hw, ok := got.HighWater("C1")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1700000001), hw.Unix())
+}
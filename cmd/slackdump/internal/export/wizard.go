@@ -2,36 +2,168 @@ package export
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/huh"
 
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/ui"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/ui/cfgui"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/ui/dumpui"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/ui/updaters"
+	"github.com/rusq/slackdump/v3/internal/cache"
 	"github.com/rusq/slackdump/v3/internal/chunk/transform/fileproc"
 	"github.com/rusq/slackdump/v3/internal/structures"
 )
 
 func wizExport(ctx context.Context, cmd *base.Command, args []string) error {
+	m, err := cache.NewManager(cfg.CacheDir())
+	if err != nil {
+		base.SetExitStatus(base.SCacheError)
+		return err
+	}
+	run, err := choosePreset(m)
+	if err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			return nil
+		}
+		return err
+	}
+	if run {
+		return cmd.Run(ctx, cmd, entryArgs())
+	}
+
 	w := &dumpui.Wizard{
 		Title:       "Export Slack Workspace",
 		Name:        "Export",
 		Cmd:         cmd,
 		LocalConfig: options.configuration,
-		ArgsFn: func() []string {
-			if len(entryList) > 0 {
-				return structures.SplitEntryList(entryList)
-			}
-			return nil
+		ArgsFn:      entryArgs,
+		SaveFn: func() error {
+			return savePreset(m)
 		},
 	}
 	return w.Run(ctx)
 }
 
+// presetCmd is the command name the export presets are saved under.
+const presetCmd = "export"
+
+// choosePreset offers to run, edit or delete one of the saved presets, if
+// there are any.  It returns true, if the chosen preset should be run
+// straight away, otherwise the options are prefilled with the preset for
+// editing.
+func choosePreset(m *cache.Manager) (bool, error) {
+	for {
+		presets, err := m.Presets(presetCmd)
+		if err != nil {
+			return false, err
+		}
+		if len(presets) == 0 {
+			return false, nil
+		}
+		var idx = -1
+		opts := []huh.Option[int]{huh.NewOption("New export", -1)}
+		for i, p := range presets {
+			opts = append(opts, huh.NewOption(fmt.Sprintf("%s (%s)", p.Name, p.Output), i))
+		}
+		if err := huh.NewForm(huh.NewGroup(huh.NewSelect[int]().
+			Title("Export Presets").
+			Description("Choose the saved preset or start a new export").
+			Options(opts...).
+			Value(&idx))).WithTheme(ui.HuhTheme()).WithKeyMap(ui.DefaultHuhKeymap).Run(); err != nil {
+			return false, err
+		}
+		if idx == -1 {
+			return false, nil
+		}
+
+		var act string
+		if err := huh.NewForm(huh.NewGroup(huh.NewSelect[string]().
+			Title(presets[idx].Name).
+			Options(
+				huh.NewOption("Run", actPresetRun),
+				huh.NewOption("Edit", actPresetEdit),
+				huh.NewOption("Delete", actPresetDelete),
+			).
+			Value(&act))).WithTheme(ui.HuhTheme()).WithKeyMap(ui.DefaultHuhKeymap).Run(); err != nil {
+			return false, err
+		}
+		switch act {
+		case actPresetDelete:
+			if err := m.DeletePreset(presetCmd, presets[idx].Name); err != nil {
+				return false, err
+			}
+			continue // back to the list
+		case actPresetRun, actPresetEdit:
+			if err := options.applyPreset(presets[idx]); err != nil {
+				return false, err
+			}
+		}
+		return act == actPresetRun, nil
+	}
+}
+
+const (
+	actPresetRun    = "run"
+	actPresetEdit   = "edit"
+	actPresetDelete = "delete"
+)
+
+// savePreset asks for the preset name and saves the current options to it.
+func savePreset(m *cache.Manager) error {
+	name, err := ui.StringRequire("Preset name", "Existing preset with the same name is replaced")
+	if err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			return nil
+		}
+		return err
+	}
+	return m.SavePreset(presetCmd, options.preset(name))
+}
+
+// preset returns the preset with the current options.
+func (fl *exportFlags) preset(name string) cache.Preset {
+	return cache.Preset{
+		Name:     name,
+		Entities: strings.Fields(entryList),
+		Oldest:   time.Time(cfg.Oldest),
+		Latest:   time.Time(cfg.Latest),
+		Type:     fl.ExportStorageType.String(),
+		Output:   cfg.Output,
+	}
+}
+
+// applyPreset sets the options from the preset p.
+func (fl *exportFlags) applyPreset(p cache.Preset) error {
+	if p.Type != "" {
+		if err := fl.ExportStorageType.Set(p.Type); err != nil {
+			return fmt.Errorf("preset %q: %w", p.Name, err)
+		}
+	}
+	entryList = strings.Join(p.Entities, " ")
+	cfg.Oldest = cfg.TimeValue(p.Oldest)
+	cfg.Latest = cfg.TimeValue(p.Latest)
+	if p.Output != "" {
+		cfg.Output = p.Output
+	}
+	return nil
+}
+
 var entryList string
 
+// entryArgs returns the entry list as command arguments.
+func entryArgs() []string {
+	if len(entryList) > 0 {
+		return structures.SplitEntryList(entryList)
+	}
+	return nil
+}
+
 func (fl *exportFlags) configuration() cfgui.Configuration {
 	return cfgui.Configuration{
 		{
@@ -48,6 +180,7 @@ func (fl *exportFlags) configuration() cfgui.Configuration {
 						Options(
 							huh.NewOption("Mattermost", fileproc.STmattermost),
 							huh.NewOption("Standard", fileproc.STstandard),
+							huh.NewOption("Content-addressed", fileproc.STcontent),
 							huh.NewOption("Disable", fileproc.STnone),
 						)),
 				},
@@ -65,6 +198,13 @@ func (fl *exportFlags) configuration() cfgui.Configuration {
 					Inline:      true,
 					Updater:     updaters.NewString(&fl.ExportToken, "", false, structures.ValidateToken),
 				},
+				{
+					Name:        "Scheduled and Drafts",
+					Value:       cfgui.Checkbox(fl.Personal),
+					Description: "Save scheduled messages and drafts of the current user",
+					Inline:      true,
+					Updater:     updaters.NewBool(&fl.Personal),
+				},
 			},
 		},
 	}
@@ -0,0 +1,117 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rusq/slackdump/v3/auth"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/bootstrap"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/workspace"
+	"github.com/rusq/slackdump/v3/internal/cache"
+	"github.com/rusq/slackdump/v3/internal/remotefs"
+)
+
+// allWorkspaces is the value of the -workspaces flag, that selects all saved
+// workspaces.
+const allWorkspaces = "all"
+
+// runWorkspaces runs the export for each of the workspaces in the
+// -workspaces flag, authenticating to each, into the separate output
+// locations, see [wspOutput].  The export of the remaining workspaces
+// continues, if one fails.
+func runWorkspaces(ctx context.Context, cmd *base.Command, args []string) error {
+	if strings.TrimSpace(cfg.Output) == "" {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("use -base to set the base output location")
+	}
+	if options.Resume != "" {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("-resume can't be used with -workspaces")
+	}
+	m, err := cache.NewManager(cfg.CacheDir())
+	if err != nil {
+		base.SetExitStatus(base.SCacheError)
+		return err
+	}
+	wsps, err := selectWorkspaces(m, options.Workspaces)
+	if err != nil {
+		base.SetExitStatus(base.SUserError)
+		return err
+	}
+
+	lg := cfg.Log
+	output, current := cfg.Output, cfg.Workspace
+	defer func() {
+		cfg.Output, cfg.Workspace = output, current
+	}()
+	var errs []error
+	for i, wsp := range wsps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cfg.Output, cfg.Workspace = wspOutput(output, wsp), wsp
+		lg.InfoContext(ctx, "exporting workspace", "workspace", wsp, "n", i+1, "of", len(wsps), "output", cfg.Output)
+		prov, err := workspace.AuthCurrent(ctx, cfg.CacheDir(), wsp, cfg.LegacyBrowser)
+		if err != nil {
+			lg.ErrorContext(ctx, "unable to authenticate, skipping the workspace", "workspace", wsp, "error", err)
+			errs = append(errs, fmt.Errorf("workspace %q: auth error: %w", wsp, err))
+			continue
+		}
+		wctx := bootstrap.WithPenalties(auth.WithContext(ctx, prov), wsp)
+		// each run may append to args, i.e. with -dm-of.
+		if err := runWorkspaceExport(wctx, cmd, slices.Clip(args)); err != nil {
+			lg.ErrorContext(ctx, "workspace export failed", "workspace", wsp, "error", err)
+			errs = append(errs, fmt.Errorf("workspace %q: %w", wsp, err))
+		}
+	}
+	if len(errs) > 0 {
+		base.SetExitStatus(base.SApplicationError)
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// selectWorkspaces returns the workspaces from the comma-separated list, or
+// all saved workspaces, if the list is "all".  All listed workspaces must
+// exist.
+func selectWorkspaces(m *cache.Manager, list string) ([]string, error) {
+	if strings.TrimSpace(list) == allWorkspaces {
+		return m.List()
+	}
+	var wsps []string
+	for _, wsp := range strings.Split(list, ",") {
+		wsp = strings.TrimSpace(wsp)
+		if wsp == "" || slices.Contains(wsps, wsp) {
+			continue
+		}
+		if err := m.ExistsErr(wsp); err != nil {
+			return nil, err
+		}
+		wsps = append(wsps, wsp)
+	}
+	if len(wsps) == 0 {
+		return nil, errors.New("no workspaces to export")
+	}
+	return wsps, nil
+}
+
+// wspOutput returns the output location of the workspace wsp.  For the ZIP
+// file, the workspace name is added to the file name, i.e. "out-wsp.zip",
+// otherwise, the workspace gets the subdirectory of the output location.
+func wspOutput(output string, wsp string) string {
+	switch {
+	case isZIP(output):
+		ext := filepath.Ext(output)
+		return strings.TrimSuffix(output, ext) + "-" + wsp + ext
+	case remotefs.IsRemote(output):
+		return strings.TrimSuffix(output, "/") + "/" + wsp
+	default:
+		return filepath.Join(output, wsp)
+	}
+}
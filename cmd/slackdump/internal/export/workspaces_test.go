@@ -0,0 +1,61 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/cache"
+)
+
+func Test_selectWorkspaces(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"alpha.bin", "beta.bin"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+	m, err := cache.NewManager(dir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{"all", "all", []string{"alpha", "beta"}, false},
+		{"list", "beta, alpha,beta", []string{"beta", "alpha"}, false},
+		{"unknown", "alpha,gamma", nil, true},
+		{"empty", " , ", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectWorkspaces(m, tt.list)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_wspOutput(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"export.zip", "export-acme.zip"},
+		{"out/EXPORT.ZIP", "out/EXPORT-acme.ZIP"},
+		{"s3://bucket/exports/", "s3://bucket/exports/acme"},
+		{"exports", filepath.Join("exports", "acme")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			assert.Equal(t, tt.want, wspOutput(tt.output, "acme"))
+		})
+	}
+}
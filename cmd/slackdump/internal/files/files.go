@@ -0,0 +1,29 @@
+// Package files contains the commands to manage the downloaded files.
+package files
+
+import (
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+)
+
+const baseCommand = "slackdump files"
+
+var CmdFiles = &base.Command{
+	UsageLine: baseCommand,
+	Short:     "manage the downloaded files",
+	Long: `
+# Files Command
+
+Files command allows to manage the files downloaded by the archive, export
+and search commands.
+
+Each of these commands records the download state of every file in the
+` + "`downloads.state.json`" + ` file in the output directory.  If some of the
+files failed to download, they can be retried with the **retry** command,
+without dumping the messages again.
+`,
+	FlagMask: cfg.OmitAll,
+	Commands: []*base.Command{
+		CmdRetry,
+	},
+}
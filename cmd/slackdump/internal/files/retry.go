@@ -0,0 +1,134 @@
+package files
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/bootstrap"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/downloader"
+	"github.com/rusq/slackdump/v3/internal/chunk/state"
+	"github.com/rusq/slackdump/v3/internal/chunk/transform/fileproc"
+	"github.com/rusq/slackdump/v3/internal/mtimefs"
+)
+
+//go:embed assets/retry.md
+var retryMD string
+
+var CmdRetry = &base.Command{
+	UsageLine:   baseCommand + " retry [flags] <state-file>",
+	Short:       "retry the failed file downloads",
+	Long:        retryMD,
+	FlagMask:    cfg.OmitAll &^ cfg.OmitWorkspaceFlag,
+	RequireAuth: true,
+	PrintFlags:  true,
+}
+
+func init() {
+	CmdRetry.Run = runRetry
+}
+
+var retryFailedOnly = CmdRetry.Flag.Bool("failed-only", false, "retry only the failed downloads, by default the downloads that\nwere interrupted and left pending are retried as well")
+
+var (
+	errNoStateFile = errors.New("state file is required")
+	errNotDir      = errors.New("files directory is not a directory, files in the ZIP archive can't be retried")
+)
+
+func runRetry(ctx context.Context, cmd *base.Command, args []string) error {
+	if len(args) != 1 {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errNoStateFile
+	}
+	filename := args[0]
+	st, err := state.Load(filename)
+	if err != nil {
+		base.SetExitStatus(base.SUserError)
+		return fmt.Errorf("error loading the state file: %w", err)
+	}
+	if fi, err := os.Stat(st.FilesDir); err != nil {
+		base.SetExitStatus(base.SUserError)
+		return err
+	} else if !fi.IsDir() {
+		base.SetExitStatus(base.SUserError)
+		return fmt.Errorf("%w: %s", errNotDir, st.FilesDir)
+	}
+
+	sess, err := bootstrap.SlackdumpSession(ctx)
+	if err != nil {
+		base.SetExitStatus(base.SInitializationError)
+		return err
+	}
+
+	lg := cfg.Log
+	res, err := retry(ctx, sess.Client(), st, *retryFailedOnly)
+	// the state is saved even if the retry was interrupted, to retain the
+	// downloads that succeeded.
+	if serr := st.Save(filename); serr != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return errors.Join(err, serr)
+	}
+	if err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	lg.InfoContext(ctx, "retry complete", "retried", res.Retried, "complete", res.Retried-res.Failed, "failed", res.Failed)
+	if res.Failed > 0 {
+		base.SetExitStatus(base.SApplicationError)
+		return fmt.Errorf("%d file(s) failed to download, see %s for details", res.Failed, filename)
+	}
+	return nil
+}
+
+// retryResult is the result of the retry.
+type retryResult struct {
+	// Retried is the number of files that were retried.
+	Retried int
+	// Failed is the number of files that failed again.
+	Failed int
+}
+
+// retry downloads the failed, and unless failedOnly is set, the pending files
+// from the state st to st.FilesDir, and updates their download state.
+func retry(ctx context.Context, cl downloader.Downloader, st *state.State, failedOnly bool) (retryResult, error) {
+	paths := st.DownloadPaths(state.DSFailed)
+	if !failedOnly {
+		paths = append(paths, st.DownloadPaths(state.DSPending)...)
+	}
+	if len(paths) == 0 {
+		return retryResult{}, nil
+	}
+
+	dl := downloader.New(
+		cl,
+		mtimefs.NewDirectory(st.FilesDir),
+		downloader.WithLogger(cfg.Log),
+		downloader.WithTracker(fileproc.NewStateTracker(st)),
+	)
+	if err := dl.Start(ctx); err != nil {
+		return retryResult{}, err
+	}
+	for _, p := range paths {
+		d, _ := st.DownloadInfo(p)
+		if err := dl.Enqueue(downloader.Request{Fullpath: p, URL: d.URL, Size: d.Size}); err != nil {
+			dl.Stop()
+			return retryResult{}, err
+		}
+	}
+	dl.Stop()
+	if err := ctx.Err(); err != nil {
+		return retryResult{}, err
+	}
+
+	res := retryResult{Retried: len(paths)}
+	for _, p := range paths {
+		if d, _ := st.DownloadInfo(p); d.Status != state.DSComplete {
+			res.Failed++
+		}
+	}
+	return res, nil
+}
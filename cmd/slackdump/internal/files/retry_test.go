@@ -0,0 +1,93 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v3/internal/chunk/state"
+)
+
+// fakeGetter serves the files by URL, and fails for the unknown ones.
+type fakeGetter map[string]string
+
+func (g fakeGetter) GetFileContext(_ context.Context, downloadURL string, w io.Writer) error {
+	data, ok := g[downloadURL]
+	if !ok {
+		return errors.New("file not found")
+	}
+	_, err := io.WriteString(w, data)
+	return err
+}
+
+func testState(t *testing.T) *state.State {
+	t.Helper()
+	st := state.New("")
+	st.SetFilesDir(t.TempDir())
+	st.AddDownload("C1/F1-a.txt", "https://files/F1", 5)
+	st.SetDownloadComplete("C1/F1-a.txt")
+	st.AddDownload("C1/F2-b.txt", "https://files/F2", 5)
+	st.SetDownloadFailed("C1/F2-b.txt", "file size mismatch")
+	st.AddDownload("C2/F3-c.txt", "https://files/F3", 0)
+	st.AddDownload("C2/F4-d.txt", "https://files/F4", 0)
+	st.SetDownloadFailed("C2/F4-d.txt", "not found")
+	return st
+}
+
+func Test_retry(t *testing.T) {
+	cl := fakeGetter{
+		"https://files/F1": "hello",
+		"https://files/F2": "world",
+		"https://files/F3": "pending",
+	}
+	t.Run("failed and pending", func(t *testing.T) {
+		st := testState(t)
+		res, err := retry(context.Background(), cl, st, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, retryResult{Retried: 3, Failed: 1}, res)
+		assert.Equal(t, []string{"C1/F1-a.txt", "C1/F2-b.txt", "C2/F3-c.txt"}, st.DownloadPaths(state.DSComplete))
+		assert.Equal(t, []string{"C2/F4-d.txt"}, st.DownloadPaths(state.DSFailed))
+		d, _ := st.DownloadInfo("C2/F4-d.txt")
+		assert.Equal(t, 2, d.Attempts)
+
+		got, err := os.ReadFile(filepath.Join(st.FilesDir, "C1", "F2-b.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "world", string(got))
+		// complete files are not downloaded again.
+		assert.NoFileExists(t, filepath.Join(st.FilesDir, "C1", "F1-a.txt"))
+	})
+	t.Run("failed only", func(t *testing.T) {
+		st := testState(t)
+		res, err := retry(context.Background(), cl, st, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, retryResult{Retried: 2, Failed: 1}, res)
+		assert.Equal(t, []string{"C2/F3-c.txt"}, st.DownloadPaths(state.DSPending))
+	})
+	t.Run("size mismatch", func(t *testing.T) {
+		st := testState(t)
+		res, err := retry(context.Background(), fakeGetter{"https://files/F2": "truncated"}, st, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, retryResult{Retried: 2, Failed: 2}, res)
+		d, _ := st.DownloadInfo("C1/F2-b.txt")
+		assert.Contains(t, d.Error, "file size mismatch")
+	})
+	t.Run("nothing to retry", func(t *testing.T) {
+		st := state.New("")
+		res, err := retry(context.Background(), cl, st, false)
+		assert.NoError(t, err)
+		assert.Equal(t, retryResult{}, res)
+	})
+}
@@ -17,8 +17,10 @@ import (
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/bootstrap"
 
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/emoji/emojidl"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
 	"github.com/rusq/slackdump/v3/internal/cache"
+	"github.com/rusq/slackdump/v3/internal/emojitext"
 	"github.com/rusq/slackdump/v3/internal/format"
 	"github.com/rusq/slackdump/v3/types"
 )
@@ -29,7 +31,31 @@ var CmdFormat = &base.Command{
 	Short:     "converts the slackdump files to a human readable format",
 	Long: `
 # Format Command
-`, // TODO: add more info
+
+## Language Detection
+
+With the -lang flag, each message in the CSV and NDJSON output is tagged
+with the ISO 639-1 code of its language, i.e. "en" or "de".  The language is
+detected from the message text, and is left empty, if it can't be determined,
+for example, for short messages or messages consisting only of emojis.
+
+## Timestamps
+
+With the -rfc3339 flag, the CSV and NDJSON output gets the time of each
+message in the RFC 3339 format in UTC, i.e. "2023-11-14T22:13:20.000100Z",
+next to the Slack timestamp, i.e. "1700000000.000100", in the "ts_rfc3339"
+field, and, for the thread messages, the time of the thread in the
+"thread_ts_rfc3339" field.  In CSV, which has no header, the time column
+follows the timestamp column.
+
+## Emojis
+
+In the text and CSV output, the emoji shortcodes, i.e. ":tada:", are
+replaced with the Unicode characters.  Custom emojis of the workspace are
+left as shortcodes, to have them followed by the link to the emoji image,
+point the -emoji-index flag to the directory with the emojis, downloaded with
+"slackdump emoji", or to its index.json file.
+`,
 	CustomFlags: false,
 	FlagMask:    cfg.OmitAll & ^cfg.OmitWorkspaceFlag,
 	PrintFlags:  true,
@@ -49,14 +75,20 @@ const (
 var ErrUnknown = errors.New("unknown file type")
 
 var (
-	archive   string
-	online    bool
-	converter format.Formatter
+	archive    string
+	online     bool
+	detectLang bool
+	rfc3339    bool
+	emojiIndex string
+	converter  format.Formatter
 )
 
 func init() {
 	CmdFormat.Flag.StringVar(&archive, "archive", "", "access the file within the ZIP `archive.zip`")
 	CmdFormat.Flag.BoolVar(&online, "online", false, "get users from current workspace (workspace must be selected, or set with -w flag)")
+	CmdFormat.Flag.BoolVar(&detectLang, "lang", false, "tag messages with the detected language code (CSV and NDJSON formats only)")
+	CmdFormat.Flag.BoolVar(&rfc3339, "rfc3339", false, "add the RFC 3339 time in UTC next to each message timestamp (CSV and NDJSON formats only)")
+	CmdFormat.Flag.StringVar(&emojiIndex, "emoji-index", "", "custom emoji `index`, the directory with emojis downloaded by \"slackdump emoji\", or its index.json file")
 }
 
 func runFormat(ctx context.Context, cmd *base.Command, args []string) error {
@@ -77,7 +109,16 @@ func runFormat(ctx context.Context, cmd *base.Command, args []string) error {
 			base.SetExitStatus(base.SInvalidParameters)
 			return errors.New("unknown converter type")
 		}
-		converter = initConverter()
+		opts := []format.Option{format.WithLanguage(detectLang), format.WithRFC3339(rfc3339)}
+		if emojiIndex != "" {
+			idx, err := emojidl.ReadIndex(emojiIndex)
+			if err != nil {
+				base.SetExitStatus(base.SUserError)
+				return err
+			}
+			opts = append(opts, format.WithEmoji(emojitext.New(idx.URLs())))
+		}
+		converter = initConverter(opts...)
 	}
 
 	var filename string
@@ -2,8 +2,12 @@ package list
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"runtime/trace"
+	"sync"
 	"time"
 
 	"github.com/rusq/slack"
@@ -13,6 +17,8 @@ import (
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
 	"github.com/rusq/slackdump/v3/internal/cache"
+	"github.com/rusq/slackdump/v3/internal/format"
+	"github.com/rusq/slackdump/v3/internal/structures"
 	"github.com/rusq/slackdump/v3/types"
 )
 
@@ -27,13 +33,27 @@ var CmdListChannels = &base.Command{
 
 Lists all visible channels for the currently logged in user.  The list
 includes all public and private channels, groups, and private messages (DMs),
-including archived ones.
+including archived ones, and the channels shared with the external
+organisations (Slack Connect).  To leave them out, use -include-archived=false
+and -include-shared=false.  The cache always holds all channels.
 
 Please note that it may take a while to retrieve all channels, if your
 workspace has lots of them.
 
 The channels are cached, and the cache is valid for %s.  Use the -no-chan-cache
 and -chan-cache-retention flags to control the cache behavior.
+
+## Resuming
+
+The channels are printed on the screen page by page, as they are fetched, and
+are saved to the partial file in the cache directory, along with the cursor of
+the next page.  If the listing is interrupted, run the command with the
+-resume flag to continue from the last saved page.  The output file is written
+once the listing is complete.
+
+Use -limit to stop after the given number of channels (rounded up to the
+page).  The output file then contains the channels fetched so far, and the
+listing can be continued with -resume.
 `+sectListFormat, chanFlags.cache.Retention),
 
 	RequireAuth: true,
@@ -43,6 +63,11 @@ type (
 	channelOptions struct {
 		resolveUsers bool
 		cache        cacheOpts
+		resume       bool // resume the interrupted listing
+		limit        int  // stop after this many channels, 0 - no limit
+
+		excludeArchived bool // leave out the archived channels
+		excludeShared   bool // leave out the Slack Connect channels
 	}
 
 	cacheOpts struct {
@@ -67,18 +92,40 @@ func init() {
 	CmdListChannels.Flag.BoolVar(&chanFlags.cache.Enabled, "no-chan-cache", chanFlags.cache.Enabled, "disable channel cache")
 	CmdListChannels.Flag.DurationVar(&chanFlags.cache.Retention, "chan-cache-retention", chanFlags.cache.Retention, "channel cache retention time.  After this time, the cache is considered stale and will be refreshed.")
 	CmdListChannels.Flag.BoolVar(&chanFlags.resolveUsers, "resolve", chanFlags.resolveUsers, "resolve user IDs to names")
+	CmdListChannels.Flag.BoolVar(&chanFlags.resume, "resume", chanFlags.resume, "resume the interrupted listing from the last saved page")
+	CmdListChannels.Flag.IntVar(&chanFlags.limit, "limit", chanFlags.limit, "stop after `n` channels, rounded up to the page, 0 - no limit")
+	cfg.SetChannelFilterFlags(&CmdListChannels.Flag)
 }
 
 func runListChannels(ctx context.Context, cmd *base.Command, args []string) error {
+	cmp, err := channelSort.get(commonFlags.sortKey)
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	if _, err := format.ChannelColumns.Select(commonFlags.columnList()...); err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+
 	sess, err := bootstrap.SlackdumpSession(ctx)
 	if err != nil {
 		base.SetExitStatus(base.SInitializationError)
 		return err
 	}
 
+	opts := chanFlags
+	opts.excludeArchived = !cfg.IncludeArchived
+	opts.excludeShared = !cfg.IncludeShared
 	l := &channels{
-		opts:   chanFlags,
-		common: commonFlags,
+		opts:     opts,
+		common:   commonFlags,
+		spoolDir: cfg.CacheDir(),
+		cmp:      cmp,
+	}
+	// the sorted channels can be printed only when all of them are fetched.
+	if !commonFlags.quiet && cmp == nil {
+		l.w = os.Stdout
 	}
 
 	return list(ctx, sess, l, filename)
@@ -90,6 +137,11 @@ type channels struct {
 
 	opts   channelOptions
 	common commonOpts
+
+	spoolDir string                       // directory of the partial listing.
+	w        io.Writer                    // if set, the pages are printed to it as they arrive.
+	streamed bool                         // the pages were printed while retrieving.
+	cmp      func(a, b slack.Channel) int // if set, the channels are sorted with it.
 }
 
 func (l *channels) Type() string {
@@ -104,6 +156,10 @@ func (l *channels) Users() []slack.User {
 	return l.users
 }
 
+func (l *channels) Streamed() bool {
+	return l.streamed
+}
+
 func (l *channels) Retrieve(ctx context.Context, sess *slackdump.Session, m *cache.Manager) error {
 	ctx, task := trace.NewTask(ctx, "channels.List")
 	defer task.End()
@@ -125,22 +181,108 @@ func (l *channels) Retrieve(ctx context.Context, sess *slackdump.Session, m *cac
 		}
 	}()
 
-	if l.opts.cache.Enabled {
+	users := sync.OnceValue(func() []slack.User { return <-usersc })
+
+	if l.opts.cache.Enabled && !l.opts.resume {
 		var err error
-		l.channels, err = m.LoadChannels(teamID, l.opts.cache.Retention)
+		cc, err := m.LoadChannels(teamID, l.opts.cache.Retention)
 		if err == nil {
-			l.users = <-usersc
+			l.channels = sorted(l.filter(cc), l.cmp)
+			l.users = users()
 			return nil
 		}
 	}
-	cc, err := sess.GetChannels(ctx)
+	cc, complete, err := l.stream(ctx, sess, teamID, users)
 	if err != nil {
 		return fmt.Errorf("error getting channels: %w", err)
 	}
-	l.channels = cc
-	l.users = <-usersc
+	l.channels = sorted(l.filter(cc), l.cmp)
+	l.users = users()
+	if !complete {
+		return nil
+	}
 	if err := m.CacheChannels(teamID, cc); err != nil {
 		lg.WarnContext(ctx, "failed to cache channels (ignored)", "error", err)
 	}
 	return nil
 }
+
+// filter returns the channels from cc, leaving out the archived and the
+// shared channels, if requested.  cc is not modified.
+func (l *channels) filter(cc types.Channels) types.Channels {
+	if !l.opts.excludeArchived && !l.opts.excludeShared {
+		return cc
+	}
+	ret := make(types.Channels, 0, len(cc))
+	for i := range cc {
+		if l.opts.excludeArchived && cc[i].IsArchived {
+			continue
+		}
+		if l.opts.excludeShared && structures.IsExtShared(&cc[i]) {
+			continue
+		}
+		ret = append(ret, cc[i])
+	}
+	return ret
+}
+
+// errLimit is returned by the page callback to stop the listing, once the
+// limit is reached.
+var errLimit = errors.New("limit reached")
+
+// stream fetches the channels page by page, saving each page to the spool,
+// and printing it, if the writer is set.  It returns all channels in the
+// spool, and true, if the listing is complete.  The spool is kept, if the
+// listing is interrupted or stopped at the limit.
+func (l *channels) stream(ctx context.Context, sess *slackdump.Session, teamID string, users func() []slack.User) (types.Channels, bool, error) {
+	lg := cfg.Log
+
+	sp, err := openSpool(l.spoolDir, teamID, l.opts.resume)
+	if err != nil {
+		return nil, false, err
+	}
+	defer sp.Close()
+
+	if l.opts.resume && !sp.Resumed() {
+		lg.InfoContext(ctx, "nothing to resume, listing from the start")
+	}
+	if !sp.start.Done {
+		if sp.Resumed() {
+			lg.InfoContext(ctx, "resuming the channel listing")
+		}
+		var n int
+		err = sess.StreamChannelPages(ctx, nil, sp.start.Cursor, func(cc types.Channels, next string) error {
+			if err := sp.Write(cc, next); err != nil {
+				return err
+			}
+			if l.w != nil {
+				l.streamed = true
+				if err := fmtPrint(ctx, l.w, l.filter(cc), screenFormat(l.common.listType), users(), l.common.fmtOptions()...); err != nil {
+					return err
+				}
+			}
+			n += len(cc)
+			if l.opts.limit > 0 && n >= l.opts.limit && next != "" {
+				return errLimit
+			}
+			return nil
+		})
+		if errors.Is(err, errLimit) {
+			lg.InfoContext(ctx, "limit reached, use -resume to continue", "limit", l.opts.limit)
+			cc, err := sp.Channels()
+			return cc, false, err
+		}
+		if err != nil {
+			lg.WarnContext(ctx, "channel listing interrupted, use -resume to continue")
+			return nil, false, err
+		}
+	}
+	cc, err := sp.Channels()
+	if err != nil {
+		return nil, false, err
+	}
+	if err := sp.Remove(); err != nil {
+		lg.WarnContext(ctx, "failed to remove the partial listing (ignored)", "error", err)
+	}
+	return cc, true, nil
+}
@@ -0,0 +1,38 @@
+package list
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/types"
+)
+
+func Test_channels_filter(t *testing.T) {
+	var (
+		public   = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}}
+		archived = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C2"}, IsArchived: true}}
+		shared   = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C3", IsExtShared: true}}}
+		pending  = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C4", IsPendingExtShared: true}}}
+		all      = types.Channels{public, archived, shared, pending}
+	)
+	tests := []struct {
+		name string
+		opts channelOptions
+		want types.Channels
+	}{
+		{"all", channelOptions{}, all},
+		{"no archived", channelOptions{excludeArchived: true}, types.Channels{public, shared, pending}},
+		{"no shared", channelOptions{excludeShared: true}, types.Channels{public, archived}},
+		{"neither", channelOptions{excludeArchived: true, excludeShared: true}, types.Channels{public}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &channels{opts: tt.opts}
+			if got := l.filter(all); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/rusq/slack"
 
@@ -61,11 +62,36 @@ type lister[T any] interface {
 	Users() []slack.User
 }
 
+// streamer is implemented by the listers, that print the data on the screen
+// while retrieving it.
+type streamer interface {
+	// Streamed should return true, if the data was already printed.
+	Streamed() bool
+}
+
 // common flags
 type commonOpts struct {
 	listType format.Type
-	quiet    bool // quiet mode:  don't print anything on the screen, just save the file
-	nosave   bool // nosave mode:  don't save the data to a file, just print it to the screen
+	quiet    bool   // quiet mode:  don't print anything on the screen, just save the file
+	nosave   bool   // nosave mode:  don't save the data to a file, just print it to the screen
+	columns  string // comma-separated list of columns, for the text, csv and markdown formats
+	sortKey  string // sort key, prefixed with "-" for the descending order
+}
+
+// columnList returns the selected columns, or nil, if not set.
+func (o commonOpts) columnList() []string {
+	var ret []string
+	for _, c := range strings.Split(o.columns, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// fmtOptions returns the converter options for the common options.
+func (o commonOpts) fmtOptions() []format.Option {
+	return []format.Option{format.WithColumns(o.columnList()...)}
 }
 
 var commonFlags = commonOpts{
@@ -83,6 +109,8 @@ func addCommonFlags(fs *flag.FlagSet) {
 	fs.Var(&commonFlags.listType, "format", fmt.Sprintf("listing format, should be one of: %v", format.All()))
 	fs.BoolVar(&commonFlags.quiet, "q", false, "quiet mode:  don't print anything on the screen, just save the file")
 	fs.BoolVar(&commonFlags.nosave, "no-json", false, "don't save the data to a file, just print it to the screen")
+	fs.StringVar(&commonFlags.columns, "columns", "", "comma-separated `list` of columns for the text, csv and markdown formats")
+	fs.StringVar(&commonFlags.sortKey, "sort", "", "sort by the `key`, prefix it with \"-\" for the descending order")
 }
 
 func list[T any](ctx context.Context, sess *slackdump.Session, l lister[T], filename string) error {
@@ -95,8 +123,8 @@ func list[T any](ctx context.Context, sess *slackdump.Session, l lister[T], file
 		return err
 	}
 
-	if !commonFlags.quiet {
-		if err := fmtPrint(ctx, os.Stdout, l.Data(), commonFlags.listType, l.Users()); err != nil {
+	if s, ok := l.(streamer); !commonFlags.quiet && !(ok && s.Streamed()) {
+		if err := fmtPrint(ctx, os.Stdout, l.Data(), screenFormat(commonFlags.listType), l.Users(), commonFlags.fmtOptions()...); err != nil {
 			return err
 		}
 	}
@@ -105,13 +133,22 @@ func list[T any](ctx context.Context, sess *slackdump.Session, l lister[T], file
 		if filename == "" {
 			filename = makeFilename(l.Type(), sess.Info().TeamID, extForType(commonFlags.listType))
 		}
-		if err := saveData(ctx, l.Data(), filename, commonFlags.listType, l.Users()); err != nil {
+		if err := saveData(ctx, l.Data(), filename, commonFlags.listType, l.Users(), commonFlags.fmtOptions()...); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// screenFormat returns the format to print the data on the screen in.
+func screenFormat(typ format.Type) format.Type {
+	if typ == format.CSQLite {
+		// binary format, can't be printed on the screen.
+		return format.CText
+	}
+	return typ
+}
+
 func extForType(typ format.Type) string {
 	switch typ {
 	case format.CJSON:
@@ -120,20 +157,26 @@ func extForType(typ format.Type) string {
 		return ".txt"
 	case format.CCSV:
 		return ".csv"
+	case format.CSQLite:
+		return ".db"
+	case format.CNDJSON:
+		return ".jsonl"
+	case format.CMarkdown:
+		return ".md"
 	default:
 		return ".json"
 	}
 }
 
 // saveData saves the given data to the given filename.
-func saveData(ctx context.Context, data any, filename string, typ format.Type, users []slack.User) error {
+func saveData(ctx context.Context, data any, filename string, typ format.Type, users []slack.User, opts ...format.Option) error {
 	// save to a filesystem.
 	f, err := os.Create(filename)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
 	defer f.Close()
-	if err := fmtPrint(ctx, f, data, typ, users); err != nil {
+	if err := fmtPrint(ctx, f, data, typ, users, opts...); err != nil {
 		return err
 	}
 	cfg.Log.InfoContext(ctx, "Data saved", "filename", filename)
@@ -141,16 +184,16 @@ func saveData(ctx context.Context, data any, filename string, typ format.Type, u
 	return nil
 }
 
-// fmtPrint prints the given data to the given writer, using the given format.
-// It should be supplied with prepopulated users, as it may need to look up
-// users by ID.
-func fmtPrint(ctx context.Context, w io.Writer, a any, typ format.Type, u []slack.User) error {
+// fmtPrint prints the given data to the given writer, using the given format
+// and converter options.  It should be supplied with prepopulated users, as
+// it may need to look up users by ID.
+func fmtPrint(ctx context.Context, w io.Writer, a any, typ format.Type, u []slack.User, opts ...format.Option) error {
 	// get the converter
 	initFn, ok := format.Converters[typ]
 	if !ok {
 		return fmt.Errorf("unknown converter type: %s", typ)
 	}
-	cvt := initFn()
+	cvt := initFn(opts...)
 
 	// currently there's no list function for conversations, because it
 	// requires additional options, and I don't want to clutter the flags -
@@ -2,6 +2,7 @@ package list
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/rusq/slackdump/v3/internal/format"
 )
@@ -11,5 +12,21 @@ var (
 ## Listing format
 
 By default, the data is being output in TEXT format.  You can choose the listing
-format by specifying "-format X" flag, where X is one of: ` + fmt.Sprint(format.All())
+format by specifying "-format X" flag, where X is one of: ` + fmt.Sprint(format.All()) + `.
+"table" is the same as "text", and "md" is the same as "markdown".
+
+## Columns
+
+The text, CSV and Markdown listings can be limited to the given columns, in the
+given order, with "-columns", i.e. "-columns id,name,members".  The columns of
+the channels are: ` + strings.Join(format.ChannelColumns.Names(), ", ") + `.
+The columns of the users are: ` + strings.Join(format.UserColumns.Names(), ", ") + `.
+
+## Sorting
+
+Use "-sort KEY" to sort the listing, and "-sort -KEY" to sort it in the
+descending order.  The channels can be sorted by: ` + strings.Join(channelSort.keys(), ", ") + `,
+the users by: ` + strings.Join(userSort.keys(), ", ") + `.  The users are sorted by name
+by default.  The sorted channels are printed once all of them are fetched.
+`
 )
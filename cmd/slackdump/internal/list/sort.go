@@ -0,0 +1,65 @@
+package list
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/rusq/slack"
+)
+
+// sortFuncs maps the sort key to the comparison function.
+type sortFuncs[T any] map[string]func(a, b T) int
+
+// channelSort are the sort keys of the channels.
+var channelSort = sortFuncs[slack.Channel]{
+	"id":      func(a, b slack.Channel) int { return strings.Compare(a.ID, b.ID) },
+	"name":    func(a, b slack.Channel) int { return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)) },
+	"members": func(a, b slack.Channel) int { return cmp.Compare(a.NumMembers, b.NumMembers) },
+	"created": func(a, b slack.Channel) int { return cmp.Compare(a.Created, b.Created) },
+}
+
+// userSort are the sort keys of the users.
+var userSort = sortFuncs[slack.User]{
+	"id":   func(a, b slack.User) int { return strings.Compare(a.ID, b.ID) },
+	"name": func(a, b slack.User) int { return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)) },
+	"real_name": func(a, b slack.User) int {
+		return strings.Compare(strings.ToLower(a.RealName), strings.ToLower(b.RealName))
+	},
+	"updated": func(a, b slack.User) int { return cmp.Compare(a.Updated, b.Updated) },
+}
+
+// get returns the comparison function for the key, which is prefixed with
+// "-" for the descending order.  It returns nil, if the key is empty.
+func (sf sortFuncs[T]) get(key string) (func(a, b T) int, error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if key == "" {
+		return nil, nil
+	}
+	name, desc := strings.CutPrefix(key, "-")
+	fn, ok := sf[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort key %q, should be one of: %s", name, strings.Join(sf.keys(), ", "))
+	}
+	if desc {
+		return func(a, b T) int { return fn(b, a) }, nil
+	}
+	return fn, nil
+}
+
+// keys returns the sorted sort keys.
+func (sf sortFuncs[T]) keys() []string {
+	return slices.Sorted(maps.Keys(sf))
+}
+
+// sorted returns the sorted copy of vv, or vv, if cmp is nil.
+func sorted[S ~[]T, T any](vv S, cmp func(a, b T) int) S {
+	if cmp == nil {
+		return vv
+	}
+	ret := slices.Clone(vv)
+	slices.SortStableFunc(ret, cmp)
+	return ret
+}
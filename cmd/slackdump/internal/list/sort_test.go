@@ -0,0 +1,46 @@
+package list
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/types"
+)
+
+func Test_sortFuncs_get(t *testing.T) {
+	var (
+		a  = slack.Channel{GroupConversation: slack.GroupConversation{Name: "alpha", Conversation: slack.Conversation{ID: "C3", NumMembers: 5, Created: 300}}}
+		b  = slack.Channel{GroupConversation: slack.GroupConversation{Name: "Beta", Conversation: slack.Conversation{ID: "C1", NumMembers: 20, Created: 100}}}
+		c  = slack.Channel{GroupConversation: slack.GroupConversation{Name: "gamma", Conversation: slack.Conversation{ID: "C2", NumMembers: 1, Created: 200}}}
+		cc = types.Channels{a, b, c}
+	)
+	tests := []struct {
+		name    string
+		key     string
+		want    types.Channels
+		wantErr bool
+	}{
+		{"none", "", cc, false},
+		{"name", "name", types.Channels{a, b, c}, false},
+		{"id", "id", types.Channels{b, c, a}, false},
+		{"members desc", "-members", types.Channels{b, a, c}, false},
+		{"created", "created", types.Channels{b, c, a}, false},
+		{"unknown", "colour", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmp, err := channelSort.get(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := sorted(cc, cmp); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sorted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package list
+
+// In this file: the resumable spool of the channel listing.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/stream"
+	"github.com/rusq/slackdump/v3/types"
+)
+
+// spoolKey is the checkpointer key of the conversations.list cursor.
+const spoolKey = "conversations.list"
+
+// chanSpool is the file, that the channels are appended to page by page, as
+// they are fetched, and the checkpoint with the cursor of the next page, so
+// that the interrupted listing can be resumed.
+type chanSpool struct {
+	filename string
+	cpFile   string
+
+	f  *os.File
+	cp *stream.FileCheckpointer
+	// start is the checkpoint, that was loaded when the spool was opened.
+	start stream.Checkpoint
+}
+
+// openSpool opens the spool of the team teamID in the directory dir.  If
+// resume is false, the existing spool is discarded.
+func openSpool(dir string, teamID string, resume bool) (*chanSpool, error) {
+	sp := &chanSpool{
+		filename: filepath.Join(dir, "channels-"+teamID+".partial.jsonl"),
+		cpFile:   filepath.Join(dir, "channels-"+teamID+".checkpoint.json"),
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !resume {
+		flags |= os.O_TRUNC
+		if err := os.Remove(sp.cpFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	cp, err := stream.NewFileCheckpointer(sp.cpFile)
+	if err != nil {
+		return nil, err
+	}
+	sp.cp = cp
+	if sp.start, _, err = cp.Load(spoolKey); err != nil {
+		return nil, err
+	}
+	if sp.f, err = os.OpenFile(sp.filename, flags, 0o600); err != nil {
+		return nil, err
+	}
+	if resume {
+		if err := trimPartialLine(sp.filename); err != nil {
+			sp.f.Close()
+			return nil, err
+		}
+	}
+	return sp, nil
+}
+
+// trimPartialLine truncates the file after the last newline, removing the
+// record, that was being written when the process was interrupted.
+func trimPartialLine(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return nil
+	}
+	return os.Truncate(filename, int64(bytes.LastIndexByte(data, '\n')+1))
+}
+
+// Resumed returns true, if the spool has the checkpoint of the previous run.
+func (sp *chanSpool) Resumed() bool {
+	return sp.start.Cursor != "" || sp.start.Done
+}
+
+// Write appends the page of channels to the spool, and saves the cursor of
+// the next page.  The empty next cursor marks the listing as complete.
+func (sp *chanSpool) Write(cc []slack.Channel, next string) error {
+	bw := bufio.NewWriter(sp.f)
+	enc := json.NewEncoder(bw)
+	for i := range cc {
+		if err := enc.Encode(cc[i]); err != nil {
+			return err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return sp.cp.Save(spoolKey, stream.Checkpoint{Cursor: next, Done: next == ""})
+}
+
+// Channels reads all channels from the spool.  The page, that was written
+// before the process was interrupted, but not checkpointed, is fetched
+// again on resume, so the duplicates are removed, keeping the last copy.
+func (sp *chanSpool) Channels() (types.Channels, error) {
+	f, err := os.Open(sp.filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		cc  types.Channels
+		idx = make(map[string]int)
+	)
+	dec := json.NewDecoder(f)
+	for {
+		var ch slack.Channel
+		if err := dec.Decode(&ch); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("invalid spool file %q: %w", sp.filename, err)
+		}
+		if i, ok := idx[ch.ID]; ok {
+			cc[i] = ch
+			continue
+		}
+		idx[ch.ID] = len(cc)
+		cc = append(cc, ch)
+	}
+	return cc, nil
+}
+
+// Close closes the spool file.
+func (sp *chanSpool) Close() error {
+	return sp.f.Close()
+}
+
+// Remove closes and removes the spool and the checkpoint.
+func (sp *chanSpool) Remove() error {
+	sp.f.Close()
+	return errors.Join(os.Remove(sp.filename), os.Remove(sp.cpFile))
+}
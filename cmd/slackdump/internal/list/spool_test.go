@@ -0,0 +1,68 @@
+package list
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testChan(id, name string) slack.Channel {
+	var ch slack.Channel
+	ch.ID = id
+	ch.Name = name
+	return ch
+}
+
+func TestChanSpool_resume(t *testing.T) {
+	dir := t.TempDir()
+
+	sp, err := openSpool(dir, "T1", false)
+	require.NoError(t, err)
+	assert.False(t, sp.Resumed())
+	require.NoError(t, sp.Write([]slack.Channel{testChan("C1", "one"), testChan("C2", "two")}, "page2"))
+	require.NoError(t, sp.Close())
+
+	// interrupted while writing the next page.
+	f, err := os.OpenFile(sp.filename, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":"C3","na`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	sp, err = openSpool(dir, "T1", true)
+	require.NoError(t, err)
+	assert.True(t, sp.Resumed())
+	assert.Equal(t, "page2", sp.start.Cursor)
+	// C2 is fetched again, i.e. if the checkpoint was not saved.
+	require.NoError(t, sp.Write([]slack.Channel{testChan("C2", "two-renamed"), testChan("C3", "three")}, ""))
+
+	cc, err := sp.Channels()
+	require.NoError(t, err)
+	require.Len(t, cc, 3)
+	assert.Equal(t, []string{"C1", "C2", "C3"}, []string{cc[0].ID, cc[1].ID, cc[2].ID})
+	assert.Equal(t, "two-renamed", cc[1].Name)
+
+	require.NoError(t, sp.Remove())
+	assert.NoFileExists(t, sp.filename)
+	assert.NoFileExists(t, sp.cpFile)
+}
+
+func TestChanSpool_noResume(t *testing.T) {
+	dir := t.TempDir()
+
+	sp, err := openSpool(dir, "T1", false)
+	require.NoError(t, err)
+	require.NoError(t, sp.Write([]slack.Channel{testChan("C1", "one")}, "page2"))
+	require.NoError(t, sp.Close())
+
+	sp, err = openSpool(dir, "T1", false)
+	require.NoError(t, err)
+	defer sp.Close()
+	assert.False(t, sp.Resumed(), "previous listing must be discarded")
+	cc, err := sp.Channels()
+	require.NoError(t, err)
+	assert.Empty(t, cc)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
 	"github.com/rusq/slackdump/v3/internal/cache"
+	"github.com/rusq/slackdump/v3/internal/format"
 	"github.com/rusq/slackdump/v3/internal/osext"
 	"github.com/rusq/slackdump/v3/types"
 )
@@ -41,6 +42,21 @@ func init() {
 }
 
 func runListUsers(ctx context.Context, cmd *base.Command, args []string) error {
+	// users are listed by name, unless requested otherwise.
+	key := commonFlags.sortKey
+	if key == "" {
+		key = "name"
+	}
+	cmp, err := userSort.get(key)
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	if _, err := format.UserColumns.Select(commonFlags.columnList()...); err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+
 	sess, err := bootstrap.SlackdumpSession(ctx)
 	if err != nil {
 		base.SetExitStatus(base.SInitializationError)
@@ -49,6 +65,7 @@ func runListUsers(ctx context.Context, cmd *base.Command, args []string) error {
 
 	l := &users{
 		common: commonFlags,
+		cmp:    cmp,
 	}
 
 	return list(ctx, sess, l, filename)
@@ -58,6 +75,7 @@ type users struct {
 	data types.Users
 
 	common commonOpts
+	cmp    func(a, b slack.User) int // if set, the users are sorted with it.
 }
 
 func (u *users) Type() string {
@@ -77,7 +95,7 @@ func (u *users) Retrieve(ctx context.Context, sess *slackdump.Session, m *cache.
 	if err != nil {
 		return err
 	}
-	u.data = users
+	u.data = sorted(types.Users(users), u.cmp)
 	return nil
 }
 
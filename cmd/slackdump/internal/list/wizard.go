@@ -139,6 +139,9 @@ func (l *commonOpts) configuration() cfgui.Configuration {
 							huh.NewOption("Text", format.CText),
 							huh.NewOption("JSON", format.CJSON),
 							huh.NewOption("CSV", format.CCSV),
+							huh.NewOption("SQLite", format.CSQLite),
+							huh.NewOption("NDJSON", format.CNDJSON),
+							huh.NewOption("Markdown", format.CMarkdown),
 						)),
 				},
 				{
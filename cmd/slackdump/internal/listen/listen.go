@@ -0,0 +1,110 @@
+// Package listen contains the command that records the messages in real time
+// over the Slack Socket Mode connection.
+package listen
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/rusq/osenv/v2"
+	"github.com/rusq/slack"
+	"github.com/rusq/slack/socketmode"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/live"
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+//go:embed assets/listen.md
+var listenMD string
+
+var CmdListen = &base.Command{
+	UsageLine:   "slackdump listen [flags] [link1[ link 2[ link N]]]",
+	Short:       "record messages in real time over Socket Mode",
+	Long:        listenMD,
+	FlagMask:    cfg.OmitAll &^ cfg.OmitAuthFlags,
+	RequireAuth: false,
+	PrintFlags:  true,
+}
+
+// defRecording is the default recording file name.
+const defRecording = "slackdump_live.jsonl"
+
+type options struct {
+	appToken string
+	output   string
+}
+
+var cmdFlags options
+
+func init() {
+	CmdListen.Flag.StringVar(&cmdFlags.appToken, "app-token", osenv.Secret("SLACK_APP_TOKEN", ""), "Slack app-level `token` (xapp-...) with the connections:write scope\n(environment: SLACK_APP_TOKEN)")
+	CmdListen.Flag.StringVar(&cmdFlags.output, "o", defRecording, "recording `file` to append the messages to")
+
+	CmdListen.Run = runListen
+}
+
+func runListen(ctx context.Context, cmd *base.Command, args []string) error {
+	if !strings.HasPrefix(cmdFlags.appToken, "xapp-") {
+		base.SetExitStatus(base.SInvalidParameters)
+		return errors.New("use -app-token or SLACK_APP_TOKEN to set the app-level token, it must start with \"xapp-\"")
+	}
+	ids, err := channelIDs(args)
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+
+	// the recording is appended to, so that the restarted listener continues
+	// the same file.
+	f, err := os.OpenFile(cmdFlags.output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	defer f.Close()
+	rec := chunk.NewRecorder(f)
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM)
+	defer stop()
+
+	lg := cfg.Log
+	client := socketmode.New(slack.New("", slack.OptionAppLevelToken(cmdFlags.appToken)))
+	l := live.New(rec, live.WithChannels(ids...), live.WithLogger(lg))
+	lg.InfoContext(ctx, "recording messages", "output", cmdFlags.output, "channels", len(ids))
+	runErr := l.Run(ctx, client)
+	if err := rec.Close(); err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return fmt.Errorf("error closing the recording: %w", err)
+	}
+	if runErr != nil && !errors.Is(runErr, context.Canceled) {
+		base.SetExitStatus(base.SApplicationError)
+		return runErr
+	}
+	lg.InfoContext(ctx, "recording stopped", "output", cmdFlags.output)
+	return nil
+}
+
+// channelIDs returns the IDs of the conversations from the links.
+func channelIDs(links []string) ([]string, error) {
+	ids := make([]string, 0, len(links))
+	for _, link := range links {
+		sl, err := structures.ParseLink(link)
+		if err != nil {
+			return nil, err
+		}
+		if sl.IsThread() {
+			return nil, fmt.Errorf("%s: threads can not be listened to, use the channel link", link)
+		}
+		ids = append(ids, sl.Channel)
+	}
+	return ids, nil
+}
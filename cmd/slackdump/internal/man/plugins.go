@@ -0,0 +1,16 @@
+package man
+
+import (
+	_ "embed"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+)
+
+//go:embed assets/plugins.md
+var mdPlugins string
+
+var Plugins = &base.Command{
+	UsageLine: "slackdump plugins",
+	Short:     "extending slackdump with plugins and aliases",
+	Long:      mdPlugins,
+}
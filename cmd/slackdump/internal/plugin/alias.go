@@ -0,0 +1,48 @@
+package plugin
+
+import (
+	"os"
+	"strings"
+)
+
+// AliasEnvPrefix is the prefix of the environment variables that define
+// command aliases, i.e. SLACKDUMP_ALIAS_LS="list channels" defines the alias
+// "ls" for "list channels".
+const AliasEnvPrefix = "SLACKDUMP_ALIAS_"
+
+// Alias returns the expansion of the command alias name, or false if the alias
+// is not defined.  The expansion is split into arguments on white space.
+func Alias(name string) ([]string, bool) {
+	return alias(os.LookupEnv, name)
+}
+
+func alias(lookup func(string) (string, bool), name string) ([]string, bool) {
+	if name == "" {
+		return nil, false
+	}
+	key := AliasEnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	val, ok := lookup(key)
+	if !ok {
+		return nil, false
+	}
+	args := strings.Fields(val)
+	if len(args) == 0 {
+		return nil, false
+	}
+	return args, true
+}
+
+// Expand expands the alias in the first argument of args, returning the new
+// argument list.  isCommand reports whether the name is a built-in command;
+// built-in commands can't be redefined by aliases.  Aliases are not expanded
+// recursively.
+func Expand(args []string, isCommand func(string) bool) []string {
+	if len(args) == 0 || isCommand(args[0]) {
+		return args
+	}
+	exp, ok := Alias(args[0])
+	if !ok {
+		return args
+	}
+	return append(exp, args[1:]...)
+}
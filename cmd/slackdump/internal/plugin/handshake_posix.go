@@ -0,0 +1,33 @@
+//go:build !windows
+
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/rusq/slackdump/v3/auth"
+)
+
+// attachHandshake creates the handshake channel and attaches the plugin ends
+// of it to the cmd as the inherited file descriptors 3 (plugin reads) and 4
+// (plugin writes).  It must be called after cmd.Env is set.
+func attachHandshake(cmd *exec.Cmd) (*handshake, error) {
+	respR, respW, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create handshake channel: %w", err)
+	}
+	reqR, reqW, err := os.Pipe()
+	if err != nil {
+		respR.Close()
+		respW.Close()
+		return nil, fmt.Errorf("failed to create handshake channel: %w", err)
+	}
+	// ExtraFiles entry i becomes file descriptor 3+i in the plugin.
+	cmd.ExtraFiles = append(cmd.ExtraFiles, respR, reqW)
+	rfd, wfd := 3+len(cmd.ExtraFiles)-2, 3+len(cmd.ExtraFiles)-1
+	cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%d,%d", auth.EnvPluginFD, rfd, wfd))
+	return &handshake{r: reqR, w: respW, child: []io.Closer{respR, reqW}}, nil
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package plugin
+
+import "os/exec"
+
+// attachHandshake is not supported on Windows, as the child process can't
+// inherit additional file descriptors.  Plugins on Windows don't receive the
+// credentials and must authenticate themselves.
+func attachHandshake(*exec.Cmd) (*handshake, error) {
+	return nil, nil
+}
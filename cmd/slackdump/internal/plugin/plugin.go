@@ -0,0 +1,199 @@
+// Package plugin implements the external slackdump commands (plugins) and
+// command aliases.
+//
+// A plugin is any executable named "slackdump-<name>" found in the PATH.  When
+// slackdump is called with an unknown command "<name>", it runs the plugin,
+// passing it the rest of the command line arguments.  The plugin can obtain
+// the credentials of the current workspace with [auth.FromPlugin].
+package plugin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/rusq/slackdump/v3/auth"
+	"github.com/rusq/slackdump/v3/internal/runid"
+)
+
+// Prefix is the prefix of the plugin executable name.
+const Prefix = "slackdump-"
+
+// ErrNotFound is returned by [Lookup] if the plugin is not found.
+var ErrNotFound = errors.New("plugin not found")
+
+// ProviderFunc returns the auth provider for the plugin.
+type ProviderFunc func(ctx context.Context) (auth.Provider, error)
+
+// Lookup searches for the plugin executable with the given name in the PATH
+// and returns its path.
+func Lookup(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, "-") {
+		return "", ErrNotFound
+	}
+	path, err := exec.LookPath(Prefix + name)
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return path, nil
+}
+
+// List returns the names of all plugins found in the PATH, without the
+// prefix.
+func List() []string {
+	seen := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			dir = "."
+		}
+		des, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, de := range des {
+			name, ok := pluginName(de.Name())
+			if !ok || de.IsDir() || seen[name] {
+				continue
+			}
+			if _, err := exec.LookPath(filepath.Join(dir, de.Name())); err != nil {
+				continue // not an executable
+			}
+			seen[name] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pluginName returns the name of the plugin for the executable file name.
+func pluginName(filename string) (string, bool) {
+	if !strings.HasPrefix(filename, Prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(filename, Prefix)
+	if runtime.GOOS == "windows" {
+		ext := filepath.Ext(name)
+		if !strings.EqualFold(ext, ".exe") {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name, name != ""
+}
+
+// Run runs the plugin executable at path with the arguments args, connecting
+// it to the standard input and outputs of slackdump.  getProv is called only
+// if the plugin requests the credentials.  If the plugin exits with non-zero
+// status, the returned error is *exec.ExitError.
+func Run(ctx context.Context, path string, args []string, getProv ProviderFunc) error {
+	session, err := newSession()
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = environ(os.Environ())
+
+	hs, err := attachHandshake(cmd)
+	if err != nil {
+		return err
+	}
+	if hs != nil {
+		cmd.Env = append(cmd.Env, auth.EnvPluginSession+"="+session)
+	}
+	slog.Debug("running plugin", "path", path, "args", args, "handshake", hs != nil)
+	if err := cmd.Start(); err != nil {
+		if hs != nil {
+			hs.abort()
+		}
+		return fmt.Errorf("failed to start plugin: %w", err)
+	}
+
+	served := make(chan error, 1)
+	if hs != nil {
+		hs.started()
+		go func() {
+			defer hs.w.Close()
+			served <- auth.ServePlugin(ctx, hs.r, hs.w, session, getProv)
+		}()
+	} else {
+		close(served)
+	}
+	err = cmd.Wait()
+	if hs != nil {
+		// the plugin is gone, unblock the handshake, if it's still waiting.
+		hs.r.Close()
+	}
+	if herr := <-served; herr != nil {
+		slog.Warn("plugin credentials handoff failed", "error", herr)
+	}
+	return err
+}
+
+// handshake is the slackdump end of the handshake channel.
+type handshake struct {
+	// r is the end the plugin requests are read from.
+	r io.ReadCloser
+	// w is the end the responses are written to.
+	w io.WriteCloser
+	// child are the plugin ends, they must be closed in slackdump once
+	// the plugin is started.
+	child []io.Closer
+}
+
+// started closes the plugin ends of the handshake channel in this process.
+func (h *handshake) started() {
+	for _, c := range h.child {
+		c.Close()
+	}
+}
+
+// abort closes all ends of the handshake channel.
+func (h *handshake) abort() {
+	h.started()
+	h.r.Close()
+	h.w.Close()
+}
+
+// environ returns the environment for the plugin.  Handshake variables
+// inherited from the parent are removed, so that the plugin started by
+// another plugin doesn't receive the stale values, and the run ID of this
+// slackdump run is added, so that slackdump invocations from the plugin
+// share the same run ID.
+func environ(env []string) []string {
+	out := make([]string, 0, len(env)+2)
+	for _, kv := range env {
+		if strings.HasPrefix(kv, auth.EnvPluginSession+"=") || strings.HasPrefix(kv, auth.EnvPluginFD+"=") || strings.HasPrefix(kv, "RUN_ID=") {
+			continue
+		}
+		out = append(out, kv)
+	}
+	return append(out, "RUN_ID="+runid.ID())
+}
+
+func newSession() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate session key: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,74 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_alias(t *testing.T) {
+	env := map[string]string{
+		AliasEnvPrefix + "LS":        "list  channels",
+		AliasEnvPrefix + "MY_EXPORT": "export -o out.zip",
+		AliasEnvPrefix + "EMPTY":     " ",
+	}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+	tests := []struct {
+		name   string
+		want   []string
+		wantOk bool
+	}{
+		{"ls", []string{"list", "channels"}, true},
+		{"my-export", []string{"export", "-o", "out.zip"}, true},
+		{"empty", nil, false},
+		{"undefined", nil, false},
+		{"", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := alias(lookup, tt.name)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	t.Setenv(AliasEnvPrefix+"LS", "list channels")
+	t.Setenv(AliasEnvPrefix+"EXPORT", "dump")
+	isCommand := func(s string) bool { return s == "export" || s == "list" }
+
+	assert.Equal(t, []string{"list", "channels", "-v"}, Expand([]string{"ls", "-v"}, isCommand))
+	assert.Equal(t, []string{"export", "C123"}, Expand([]string{"export", "C123"}, isCommand), "built-in commands must not be redefined")
+	assert.Equal(t, []string{"foo"}, Expand([]string{"foo"}, isCommand))
+}
+
+func TestLookupList(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses shell scripts")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, Prefix+"hello"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, Prefix+"noexec"), []byte("#!/bin/sh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	got, err := Lookup("hello")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, Prefix+"hello"), got)
+
+	_, err = Lookup("noexec")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = Lookup("../hello")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	names := List()
+	assert.True(t, slices.Equal([]string{"hello"}, names), names)
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/rusq/rbubbles/display"
+
+	"github.com/rusq/slackdump/v3/internal/humanize"
 )
 
 type Model struct {
@@ -252,31 +254,6 @@ func selectedCmd(dir string, fi fs.FileInfo) tea.Cmd {
 	}
 }
 
-// humanizeSize returns a human-readable string representing a file size.
-// for example 240.4M or 2.3G
-func humanizeSize(size int64) string {
-	const (
-		K = 1 << 10
-		M = 1 << 20
-		G = 1 << 30
-		T = 1 << 40
-	)
-
-	switch {
-	case size < K:
-		return fmt.Sprintf("%5dB", size)
-	case size < M:
-		return fmt.Sprintf("%5.1fK", float64(size)/K)
-	case size < G:
-		return fmt.Sprintf("%5.1fM", float64(size)/M)
-	case size < T:
-		return fmt.Sprintf("%5.1fG", float64(size)/G)
-	default:
-		return fmt.Sprintf("%5.1fT", float64(size)/T)
-
-	}
-}
-
 const Width = 40
 
 func printFile(fi fs.FileInfo) string {
@@ -291,7 +268,7 @@ func printFile(fi fs.FileInfo) string {
 
 	var sz = dirMarker
 	if !fi.IsDir() {
-		sz = humanizeSize(fi.Size())
+		sz = humanize.Size(fi.Size())
 	}
 	return fmt.Sprintf("%-*s %*s %s", filenameSz, display.Trunc(fi.Name(), filenameSz), filesizeSz, sz, fi.ModTime().Format(dttmLayout))
 }
@@ -286,60 +286,6 @@ func TestModel_View(t *testing.T) {
 	}
 }
 
-func Test_humanizeSize(t *testing.T) {
-	type args struct {
-		size int64
-	}
-	tests := []struct {
-		name string
-		args args
-		want string
-	}{
-		{
-			name: "bytes",
-			args: args{
-				size: 3,
-			},
-			want: "    3B",
-		},
-		{
-			name: "kilobytes",
-			args: args{
-				size: 1024,
-			},
-			want: "  1.0K",
-		},
-		{
-			name: "megabytes",
-			args: args{
-				size: 1024 * 1024,
-			},
-			want: "  1.0M",
-		},
-		{
-			name: "gigabytes",
-			args: args{
-				size: 1024 * 1024 * 1024,
-			},
-			want: "  1.0G",
-		},
-		{
-			name: "terabytes",
-			args: args{
-				size: 1024 * 1024 * 1024 * 1024,
-			},
-			want: "  1.0T",
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := humanizeSize(tt.args.size); got != tt.want {
-				t.Errorf("humanizeSize() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestModel_printDebug(t *testing.T) {
 	type fields struct {
 		Globs     []string
@@ -26,12 +26,15 @@ type Wizard struct {
 	Cmd *base.Command
 	// Help is the markdown help text.
 	Help string
+	// SaveFn, if set, is called to save the current options as a preset.
+	SaveFn func() error
 }
 
 const (
 	actRun          = "run"
 	actGlobalConfig = "config"
 	actLocalConfig  = "localconfig"
+	actSave         = "save"
 	actExit         = "exit"
 )
 
@@ -39,6 +42,7 @@ var description = map[string]string{
 	actRun:          "Run the command",
 	actGlobalConfig: "Set global configuration options",
 	actLocalConfig:  "Set command specific configuration options",
+	actSave:         "Save the current options as a preset for the next launch",
 	actExit:         "Exit to main menu",
 }
 
@@ -69,6 +73,13 @@ func (w *Wizard) Run(ctx context.Context) error {
 				},
 			},
 		)
+		if w.SaveFn != nil {
+			items = append(items, menu.Item{
+				ID:   actSave,
+				Name: "Save as Preset...",
+				Help: description[actSave],
+			})
+		}
 		if w.Help != "" {
 			items = append(items, menu.Item{
 				ID:   "help",
@@ -115,6 +126,10 @@ LOOP:
 			if err := w.Cmd.Run(ctx, w.Cmd, args); err != nil {
 				return err
 			}
+		case actSave:
+			if err := w.SaveFn(); err != nil {
+				return err
+			}
 		case actExit:
 			break LOOP
 		}
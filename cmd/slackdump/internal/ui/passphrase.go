@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rusq/osenv/v2"
+	"golang.org/x/term"
+)
+
+// PassphraseEnv is the environment variable with the passphrase, that is
+// used when the terminal is not available.
+const PassphraseEnv = "SLACKDUMP_PASSPHRASE"
+
+// ErrNoPassphrase is returned by [Passphrase] if the passphrase is empty.
+var ErrNoPassphrase = errors.New("empty passphrase")
+
+// Passphrase reads the passphrase from the terminal, or, if the terminal is
+// not available, from the environment variable.  If confirm is true, the
+// passphrase must be entered twice.
+func Passphrase(confirm bool) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		if pass := osenv.Secret(PassphraseEnv, ""); pass != "" {
+			return []byte(pass), nil
+		}
+		return nil, fmt.Errorf("terminal is not available, set the passphrase in the %s environment variable", PassphraseEnv)
+	}
+	fmt.Fprint(os.Stderr, "Passphrase: ")
+	pass, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	if len(pass) == 0 {
+		return nil, ErrNoPassphrase
+	}
+	if confirm {
+		fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(pass, again) {
+			return nil, errors.New("passphrases do not match")
+		}
+	}
+	return pass, nil
+}
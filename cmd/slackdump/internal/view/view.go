@@ -1,25 +1,23 @@
 package view
 
 import (
-	"archive/zip"
 	"context"
 	_ "embed"
 	"errors"
 	"fmt"
 	"io"
-	"io/fs"
 	"net/http"
-	"os"
 	"path"
-	"strings"
 
 	br "github.com/pkg/browser"
+	"github.com/rusq/fsadapter"
 
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/bootstrap"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
-	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/hydrate"
+	"github.com/rusq/slackdump/v3/internal/source"
 	"github.com/rusq/slackdump/v3/internal/viewer"
-	"github.com/rusq/slackdump/v3/internal/viewer/source"
 )
 
 //go:embed assets/view.md
@@ -34,10 +32,18 @@ var CmdView = &base.Command{
 	Run:        RunView,
 }
 
-var listenAddr string
+var (
+	listenAddr string
+	theme      = viewer.ThemeAuto
+	staticDir  string
+	online     bool
+)
 
 func init() {
 	CmdView.Flag.StringVar(&listenAddr, "listen", "localhost:8080", "address to listen on")
+	CmdView.Flag.Var(&theme, "theme", "colour `theme`: auto, light or dark")
+	CmdView.Flag.StringVar(&staticDir, "static", "", "generate the static HTML site in the `location` (directory or ZIP file)\ninstead of starting the viewer")
+	CmdView.Flag.BoolVar(&online, "hydrate", false, "look up the users, channels and user groups that are referenced in the\nmessages, but are missing in the archive, with the API, if the credentials\nfor the current workspace are available")
 }
 
 func RunView(ctx context.Context, cmd *base.Command, args []string) error {
@@ -45,7 +51,7 @@ func RunView(ctx context.Context, cmd *base.Command, args []string) error {
 		base.SetExitStatus(base.SInvalidParameters)
 		return fmt.Errorf("viewing slackdump files requires at least one argument")
 	}
-	src, err := loadSource(ctx, args[0])
+	src, err := source.Load(ctx, args[0])
 	if err != nil {
 		base.SetExitStatus(base.SUserError)
 		return err
@@ -54,7 +60,18 @@ func RunView(ctx context.Context, cmd *base.Command, args []string) error {
 		defer cl.Close()
 	}
 
-	v, err := viewer.New(ctx, listenAddr, src)
+	h, err := hydrator(ctx, args[0], src)
+	if err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	opts := []viewer.Option{viewer.WithTheme(theme), viewer.WithHydrator(h)}
+
+	if staticDir != "" {
+		return generate(ctx, staticDir, src, opts...)
+	}
+
+	v, err := viewer.New(ctx, listenAddr, src, opts...)
 	if err != nil {
 		base.SetExitStatus(base.SApplicationError)
 		return err
@@ -85,82 +102,52 @@ func RunView(ctx context.Context, cmd *base.Command, args []string) error {
 	return nil
 }
 
-type sourceFlags int16
-
-const (
-	sfUnknown   sourceFlags = 0
-	sfDirectory sourceFlags = 1 << iota
-	sfZIP
-	sfChunk
-	sfExport
-	sfDump
-)
-
-func loadSource(ctx context.Context, src string) (viewer.Sourcer, error) {
-	lg := cfg.Log.With("source", src)
-	fi, err := os.Stat(src)
+// generate renders the source as the static HTML site in the location.
+func generate(ctx context.Context, location string, src source.Sourcer, opts ...viewer.Option) error {
+	fsa, err := fsadapter.New(location)
 	if err != nil {
-		return nil, err
+		base.SetExitStatus(base.SApplicationError)
+		return err
 	}
-	switch srcType(src, fi) {
-	case sfChunk | sfDirectory:
-		lg.DebugContext(ctx, "loading chunk directory")
-		dir, err := chunk.OpenDir(src)
-		if err != nil {
-			return nil, err
-		}
-		return source.NewChunkDir(dir), nil
-	case sfExport | sfZIP:
-		lg.DebugContext(ctx, "loading export zip")
-		f, err := zip.OpenReader(src)
-		if err != nil {
-			return nil, err
-		}
-		return source.NewExport(f, src)
-	case sfExport | sfDirectory:
-		lg.DebugContext(ctx, "loading export directory")
-		return source.NewExport(os.DirFS(src), src)
-	case sfDump | sfZIP:
-		lg.DebugContext(ctx, "loading dump zip")
-		f, err := zip.OpenReader(src)
-		if err != nil {
-			return nil, err
-		}
-		return source.NewDump(f, src)
-	case sfDump | sfDirectory:
-		lg.DebugContext(ctx, "loading dump directory")
-		return source.NewDump(os.DirFS(src), src)
-	default:
-		return nil, fmt.Errorf("unsupported source type: %s", src)
+	defer fsa.Close()
+
+	lg := cfg.Log
+	lg.InfoContext(ctx, "generating static site", "location", location)
+	if err := viewer.Generate(ctx, fsa, src, opts...); err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
 	}
+	if err := fsa.Close(); err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	lg.InfoContext(ctx, "static site generated", "location", location, "index", path.Join(location, "index.html"))
+	return nil
 }
 
-func srcType(src string, fi fs.FileInfo) sourceFlags {
-	var fsys fs.FS // this will be our media for accessing files
-	var flags sourceFlags
-	if fi.IsDir() {
-		fsys = os.DirFS(src)
-		flags |= sfDirectory
-	} else if fi.Mode().IsRegular() && strings.ToLower(path.Ext(src)) == ".zip" {
-		f, err := zip.OpenReader(src)
-		if err != nil {
-			return sfUnknown
-		}
-		defer f.Close()
-		fsys = f
-		flags |= sfZIP
+// hydrator returns the hydration service with the results cached in the
+// archive.  If the -hydrate flag is set, it looks up the unknown IDs
+// referenced in the messages of src, and saves the results to the cache.
+func hydrator(ctx context.Context, dir string, src source.Sourcer) (*hydrate.Service, error) {
+	h := bootstrap.Hydrator(ctx, dir, online)
+	if !h.Online() {
+		return h, nil
 	}
-	if ff, err := fs.Glob(fsys, "[CD]*.json"); err == nil && len(ff) > 0 {
-		return flags | sfDump
+	refs, err := hydrate.Scan(ctx, src)
+	if err != nil {
+		return nil, err
 	}
-	if _, err := fs.Stat(fsys, "workspace.json.gz"); err == nil {
-		if flags&sfZIP != 0 {
-			return sfUnknown // compressed chunk directories are not supported
-		}
-		return flags | sfChunk
+	users, err := src.Users()
+	if err != nil {
+		return nil, err
 	}
-	if _, err := fs.Stat(fsys, "channels.json"); err == nil {
-		return flags | sfExport
+	channels, err := src.Channels()
+	if err != nil {
+		return nil, err
+	}
+	if err := h.Hydrate(ctx, refs, users, channels); err != nil {
+		return nil, err
 	}
-	return sfUnknown
+	bootstrap.SaveHydrator(ctx, dir, h)
+	return h, nil
 }
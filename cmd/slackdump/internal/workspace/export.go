@@ -0,0 +1,92 @@
+package workspace
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/ui"
+	"github.com/rusq/slackdump/v3/internal/cache"
+)
+
+//go:embed assets/export.md
+var exportMd string
+
+var CmdWspExport = &base.Command{
+	UsageLine:   baseCommand + " export [flags] [workspace]",
+	Short:       "export workspace credentials to move them to another machine",
+	Long:        exportMd,
+	FlagMask:    flagmask,
+	PrintFlags:  true,
+	RequireAuth: false,
+}
+
+var exportOutput = CmdWspExport.Flag.String("o", "-", "output `filename`, \"-\" for stdout")
+
+func init() {
+	CmdWspExport.Run = runWspExport
+}
+
+// passphraseFn returns the passphrase for the credentials bundle.
+var passphraseFn = ui.Passphrase
+
+func runWspExport(ctx context.Context, cmd *base.Command, args []string) error {
+	m, err := cache.NewManager(cfg.CacheDir())
+	if err != nil {
+		base.SetExitStatus(base.SCacheError)
+		return err
+	}
+	wsp := argsWorkspace(args, cfg.Workspace)
+	if wsp == "" {
+		if wsp, err = m.Current(); err != nil {
+			base.SetExitStatus(base.SUserError)
+			return err
+		}
+	}
+	if !m.Exists(wsp) {
+		base.SetExitStatus(base.SUserError)
+		return fmt.Errorf("%w: %q", ErrNotExists, wsp)
+	}
+	pass, err := passphraseFn(true)
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+
+	if *exportOutput == "-" || *exportOutput == "" {
+		return exportWsp(m, wsp, os.Stdout, pass)
+	}
+	f, err := os.OpenFile(*exportOutput, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	if err := exportWsp(m, wsp, f, pass); err != nil {
+		f.Close()
+		os.Remove(*exportOutput)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		base.SetExitStatus(base.SApplicationError)
+		return err
+	}
+	cfg.Log.InfoContext(ctx, "Workspace exported", "workspace", wsp, "filename", *exportOutput)
+	return nil
+}
+
+func exportWsp(m *cache.Manager, wsp string, w io.Writer, pass []byte) error {
+	if err := m.ExportWorkspace(wsp, w, pass); err != nil {
+		if errors.Is(err, cache.ErrNoPassphrase) {
+			base.SetExitStatus(base.SInvalidParameters)
+		} else {
+			base.SetExitStatus(base.SCacheError)
+		}
+		return err
+	}
+	return nil
+}
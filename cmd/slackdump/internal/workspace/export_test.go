@@ -0,0 +1,65 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/internal/cache"
+)
+
+func Test_exportImport(t *testing.T) {
+	oldPass, oldDir := passphraseFn, cfg.LocalCacheDir
+	t.Cleanup(func() { passphraseFn, cfg.LocalCacheDir = oldPass, oldDir })
+	passphraseFn = func(bool) ([]byte, error) { return []byte("secret"), nil }
+
+	// the source workspace is created by importing the bundle, as the
+	// credentials can't be saved without testing them otherwise.
+	bundle := testBundle(t, "acme")
+
+	cfg.LocalCacheDir = t.TempDir()
+	filename := filepath.Join(t.TempDir(), "acme.creds")
+	require.NoError(t, os.WriteFile(filename, bundle, 0o600))
+	require.NoError(t, cmdRunImport(context.Background(), CmdImport, []string{filename}))
+
+	out := filepath.Join(t.TempDir(), "exported.creds")
+	*exportOutput = out
+	t.Cleanup(func() { *exportOutput = "-" })
+	require.NoError(t, runWspExport(context.Background(), CmdWspExport, []string{"acme"}))
+
+	cfg.LocalCacheDir = t.TempDir()
+	require.NoError(t, cmdRunImport(context.Background(), CmdImport, []string{out}))
+	m, err := cache.NewManager(cfg.LocalCacheDir)
+	require.NoError(t, err)
+	prov, err := m.LoadProvider("acme")
+	require.NoError(t, err)
+	assert.Equal(t, "xoxb-123", prov.SlackToken())
+
+	err = runWspExport(context.Background(), CmdWspExport, []string{"nope"})
+	assert.ErrorIs(t, err, ErrNotExists)
+}
+
+// testBundle returns the credentials bundle of the workspace name, encrypted
+// with the passphrase "secret".
+func testBundle(t *testing.T, name string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	aw, err := armor.Encode(&buf, "PGP MESSAGE", nil)
+	require.NoError(t, err)
+	cw, err := openpgp.SymmetricallyEncrypt(aw, []byte("secret"), nil, nil)
+	require.NoError(t, err)
+	_, err = fmt.Fprintf(cw, `{"version":1,"name":%q,"provider":{"Token":"xoxb-123"}}`, name)
+	require.NoError(t, err)
+	require.NoError(t, cw.Close())
+	require.NoError(t, aw.Close())
+	return buf.Bytes()
+}
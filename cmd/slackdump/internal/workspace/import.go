@@ -1,9 +1,12 @@
 package workspace
 
 import (
+	"bufio"
 	"context"
 	_ "embed"
 	"errors"
+	"io"
+	"os"
 
 	"github.com/rusq/slackdump/v3/auth"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
@@ -16,7 +19,7 @@ var importMd string
 
 var CmdImport = &base.Command{
 	UsageLine:   baseCommand + " import [flags] filename",
-	Short:       "import credentials from .env or secrets.txt file",
+	Short:       "import credentials from .env, secrets.txt or exported credentials file",
 	Long:        importMd,
 	FlagMask:    flagmask,
 	PrintFlags:  true,
@@ -31,6 +34,20 @@ func cmdRunImport(ctx context.Context, cmd *base.Command, args []string) error {
 	}
 
 	filename := args[0]
+	if filename == "-" {
+		return importBundle(ctx, os.Stdin)
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		base.SetExitStatus(base.SUserError)
+		return err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	if isBundle(br) {
+		return importBundle(ctx, br)
+	}
 
 	if err := importFile(ctx, filename); err != nil {
 		return err
@@ -38,6 +55,42 @@ func cmdRunImport(ctx context.Context, cmd *base.Command, args []string) error {
 	return nil
 }
 
+// bundleHeader is the beginning of the credentials bundle, created by the
+// "workspace export" command.
+const bundleHeader = "-----BEGIN PGP MESSAGE-----"
+
+// isBundle returns true if the data in br is the credentials bundle.
+func isBundle(br *bufio.Reader) bool {
+	hdr, err := br.Peek(len(bundleHeader))
+	return err == nil && string(hdr) == bundleHeader
+}
+
+// importBundle imports the credentials bundle, created by the "workspace
+// export" command, from r.
+func importBundle(ctx context.Context, r io.Reader) error {
+	m, err := cache.NewManager(cfg.CacheDir())
+	if err != nil {
+		base.SetExitStatus(base.SCacheError)
+		return err
+	}
+	pass, err := passphraseFn(false)
+	if err != nil {
+		base.SetExitStatus(base.SInvalidParameters)
+		return err
+	}
+	wsp, err := m.ImportWorkspace(r, pass)
+	if err != nil {
+		if errors.Is(err, cache.ErrPassphrase) || errors.Is(err, cache.ErrInvalidBundle) {
+			base.SetExitStatus(base.SUserError)
+		} else {
+			base.SetExitStatus(base.SCacheError)
+		}
+		return err
+	}
+	cfg.Log.InfoContext(ctx, "Workspace imported and selected", "workspace", wsp)
+	return nil
+}
+
 func importFile(ctx context.Context, filename string) error {
 	token, cookies, err := auth.ParseDotEnv(filename)
 	if err != nil {
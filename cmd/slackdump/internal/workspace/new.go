@@ -46,6 +46,7 @@ func runWspNew(ctx context.Context, cmd *base.Command, args []string) error {
 			auth.BrowserWithTimeout(cfg.LoginTimeout),
 			auth.RODWithRODHeadlessTimeout(cfg.HeadlessTimeout),
 			auth.RODWithUserAgent(cfg.RODUserAgent),
+			auth.BrowserWithProfileReader(readProfiles),
 		))
 	if err != nil {
 		base.SetExitStatus(base.SCacheError)
@@ -65,6 +66,12 @@ var canOverwrite = func(wsp string) bool {
 	return yesno(fmt.Sprintf("Workspace %q already exists. Overwrite", realname(wsp)))
 }
 
+// canReadProfile asks the user for the consent to read the cookies from the
+// browser profile, defined as a variable for testing purposes.
+var canReadProfile = func(browser string) bool {
+	return yesno(fmt.Sprintf("Slackdump will read the Slack cookies from the %q browser profiles on this machine. Continue", browser))
+}
+
 // createWsp creates a new workspace interactively.
 func createWsp(ctx context.Context, m manager, wsp string, confirm bool) error {
 	lg := cfg.Log
@@ -81,9 +88,23 @@ func createWsp(ctx context.Context, m manager, wsp string, confirm bool) error {
 
 	lg.DebugContext(ctx, "requesting authentication...")
 	ad := cache.AuthData{
-		Token:         cfg.SlackToken,
-		Cookie:        cfg.SlackCookie,
-		UsePlaywright: cfg.LegacyBrowser,
+		Token:          cfg.SlackToken,
+		Cookie:         cfg.SlackCookie,
+		RefreshToken:   cfg.SlackRefreshToken,
+		UsePlaywright:  cfg.LegacyBrowser,
+		OAuth:          cfg.OAuth(),
+		BrowserProfile: cfg.BrowserProfile,
+	}
+	switch authType, _ := ad.Type(ctx); authType {
+	case cache.ATOAuth:
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.LoginTimeout)
+		defer cancel()
+	case cache.ATBrowserProfile:
+		if !confirm && !canReadProfile(ad.BrowserProfile) {
+			base.SetExitStatus(base.SCancelled)
+			return ErrOpCancelled
+		}
 	}
 	prov, err := m.Auth(ctx, wsp, ad)
 	if err != nil {
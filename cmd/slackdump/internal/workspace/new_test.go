@@ -8,6 +8,7 @@ import (
 
 	"github.com/rusq/slackdump/v3/auth"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
+	"github.com/rusq/slackdump/v3/internal/cache"
 	"go.uber.org/mock/gomock"
 )
 
@@ -140,6 +141,36 @@ func Test_createWsp(t *testing.T) {
 	}
 }
 
+func Test_createWsp_browserProfile(t *testing.T) {
+	oldProfile, oldCanRead := cfg.BrowserProfile, canReadProfile
+	t.Cleanup(func() { cfg.BrowserProfile, canReadProfile = oldProfile, oldCanRead })
+	cfg.BrowserProfile = "firefox"
+
+	t.Run("declined", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		m := NewMockmanager(ctrl)
+		m.EXPECT().Exists("test").Return(false)
+		canReadProfile = func(string) bool { return false }
+		if err := createWsp(context.Background(), m, "test", false); !errors.Is(err, ErrOpCancelled) {
+			t.Errorf("createWsp() error = %v, want %v", err, ErrOpCancelled)
+		}
+	})
+	t.Run("confirmed with the flag", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		m := NewMockmanager(ctrl)
+		m.EXPECT().Exists("test").Return(false)
+		m.EXPECT().Auth(gomock.Any(), "test", cache.AuthData{BrowserProfile: "firefox", OAuth: cfg.OAuth()}).Return(nil, nil)
+		m.EXPECT().Select("test").Return(nil)
+		canReadProfile = func(string) bool {
+			t.Error("must not ask, if confirmed")
+			return false
+		}
+		if err := createWsp(context.Background(), m, "test", true); err != nil {
+			t.Errorf("createWsp() error = %v", err)
+		}
+	})
+}
+
 func Test_realname(t *testing.T) {
 	type args struct {
 		name string
@@ -0,0 +1,31 @@
+package workspace
+
+import (
+	"os"
+
+	"github.com/rusq/slackdump/v3/auth"
+	"github.com/rusq/slackdump/v3/auth/profile"
+)
+
+// readProfiles is the [auth.ProfileReader], that returns the cookie stores
+// of the browser profiles, or the store of the cookie database, if browser
+// is the existing file.
+func readProfiles(browser string) ([]auth.CookieStore, error) {
+	var stores []profile.Store
+	if fi, err := os.Stat(browser); err == nil && !fi.IsDir() {
+		s, err := profile.Open(browser)
+		if err != nil {
+			return nil, err
+		}
+		stores = []profile.Store{s}
+	} else {
+		if stores, err = profile.Find(browser); err != nil {
+			return nil, err
+		}
+	}
+	ret := make([]auth.CookieStore, len(stores))
+	for i := range stores {
+		ret[i] = stores[i]
+	}
+	return ret, nil
+}
@@ -32,7 +32,8 @@ due to some other reason).
 
 **Workspace** command allows to **add** a new Slack Workspace, **list** already 
 authenticated workspaces, **select** a workspace that you have previously
-logged in to, or **del**ete an existing workspace.
+logged in to, **del**ete an existing workspace, or **export** the workspace
+credentials to **import** them on another machine.
 
 To learn more about different login options, run:
 
@@ -49,6 +50,7 @@ automatically detected to be:
 	Commands: []*base.Command{
 		CmdWspNew,
 		CmdImport,
+		CmdWspExport,
 		CmdWspList,
 		CmdWspSelect,
 		CmdWspDel,
@@ -131,7 +133,7 @@ var yesno = base.YesNo
 // credentials in the cacheDir.  It returns ErrNotExists if the workspace
 // doesn't exist in the cacheDir.
 func authWsp(ctx context.Context, cacheDir string, wsp string, usePlaywright bool) (auth.Provider, error) {
-	m, err := cache.NewManager(cacheDir)
+	m, err := cache.NewManager(cacheDir, cache.WithAuthOpts(auth.BrowserWithProfileReader(readProfiles)))
 	if err != nil {
 		return nil, err
 	}
@@ -139,7 +141,7 @@ func authWsp(ctx context.Context, cacheDir string, wsp string, usePlaywright boo
 		return nil, err
 	}
 
-	prov, err := m.Auth(ctx, wsp, cache.AuthData{Token: cfg.SlackToken, Cookie: cfg.SlackCookie, UsePlaywright: usePlaywright})
+	prov, err := m.Auth(ctx, wsp, cache.AuthData{Token: cfg.SlackToken, Cookie: cfg.SlackCookie, RefreshToken: cfg.SlackRefreshToken, UsePlaywright: usePlaywright, OAuth: cfg.OAuth(), BrowserProfile: cfg.BrowserProfile})
 	if err != nil {
 		return nil, err
 	}
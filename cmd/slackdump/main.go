@@ -8,7 +8,9 @@ import (
 	"log"
 	"log/slog"
 	"os"
+	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"runtime/trace"
 	"strings"
 
@@ -17,24 +19,31 @@ import (
 	"github.com/rusq/tracer"
 	"golang.org/x/term"
 
+	"github.com/rusq/slackdump/v3/auth"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/apiconfig"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/archive"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/bootstrap"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cachecmd"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/cfg"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/convertcmd"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/diag"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/dump"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/emoji"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/export"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/files"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/format"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/base"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/golang/help"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/list"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/listen"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/man"
+	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/plugin"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/ui"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/view"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/wizard"
 	"github.com/rusq/slackdump/v3/cmd/slackdump/internal/workspace"
+	"github.com/rusq/slackdump/v3/internal/monitor"
+	"github.com/rusq/slackdump/v3/internal/runid"
 )
 
 func init() {
@@ -42,12 +51,16 @@ func init() {
 		workspace.CmdWorkspace,
 		archive.CmdArchive,
 		export.CmdExport,
+		export.CmdWatch,
+		listen.CmdListen,
 		dump.CmdDump,
 		archive.CmdSearch,
 		convertcmd.CmdConvert,
 		list.CmdList,
 		emoji.CmdEmoji,
+		files.CmdFiles,
 		diag.CmdDiag,
+		cachecmd.CmdCache,
 		apiconfig.CmdConfig,
 		format.CmdFormat,
 		view.CmdView,
@@ -58,6 +71,7 @@ func init() {
 		man.Syntax,
 		man.Login,
 		man.Chunk,
+		man.Plugins,
 	}
 }
 
@@ -91,6 +105,7 @@ func main() {
 			base.Usage()
 		}
 	}
+	args = plugin.Expand(args, isBuiltin)
 	base.CmdName = args[0]
 	if args[0] == "help" {
 		help.Help(os.Stdout, args[1:])
@@ -121,17 +136,23 @@ BigCmdLoop:
 			if !cmd.Runnable() {
 				continue
 			}
-			if err := invoke(cmd, args); err != nil {
-				if errors.Is(err, context.Canceled) {
-					slog.Info("operation cancelled")
-				} else {
-					msg := fmt.Sprintf("%03[1]d (%[1]s): %[2]s.", base.ExitStatus(), err)
-					slog.Error(msg)
-				}
-			}
-			base.Exit()
+			run(cmd, args)
 			return
 		}
+		if bigCmd != base.Slackdump && bigCmd.Runnable() {
+			// the command group is runnable itself, and the argument is not
+			// one of its subcommands, i.e. "slackdump search <query>".
+			base.CmdName = bigCmd.LongName()
+			run(bigCmd, append([]string{bigCmd.Name()}, args...))
+			return
+		}
+		if bigCmd == base.Slackdump {
+			if path, err := plugin.Lookup(args[0]); err == nil {
+				runPlugin(path, args[1:])
+				base.Exit()
+				return
+			}
+		}
 		helpArg := ""
 		if i := strings.LastIndex(base.CmdName, " "); i >= 0 {
 			helpArg = " " + base.CmdName[:i]
@@ -146,6 +167,53 @@ func init() {
 	base.Usage = mainUsage
 }
 
+// run invokes the command, logs the error, if any, and exits.
+func run(cmd *base.Command, args []string) {
+	if err := invoke(cmd, args); err != nil {
+		if errors.Is(err, context.Canceled) {
+			slog.Info("operation cancelled")
+		} else {
+			msg := fmt.Sprintf("%03[1]d (%[1]s): %[2]s.", base.ExitStatus(), err)
+			slog.Error(msg)
+		}
+	}
+	base.Exit()
+}
+
+// isBuiltin reports whether name is a top level slackdump command.
+func isBuiltin(name string) bool {
+	if name == "help" {
+		return true
+	}
+	for _, cmd := range base.Slackdump.Commands {
+		if cmd.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// runPlugin runs the external command at path, the plugin exit status becomes
+// the slackdump exit status.
+func runPlugin(path string, args []string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	runid.Set(os.Getenv("RUN_ID")) // keep the run ID of the pipeline, if set
+	getProv := func(ctx context.Context) (auth.Provider, error) {
+		return workspace.AuthCurrent(ctx, cfg.CacheDir(), os.Getenv("SLACK_WORKSPACE"), false)
+	}
+	if err := plugin.Run(ctx, path, args, getProv); err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) && ee.ExitCode() > 0 {
+			base.SetExitStatus(base.StatusCode(ee.ExitCode()))
+			return
+		}
+		base.SetExitStatus(base.SApplicationError)
+		slog.Error("plugin failed", "plugin", filepath.Base(path), "error", err)
+	}
+}
+
 func mainUsage() {
 	help.PrintUsage(os.Stderr, base.Slackdump)
 	os.Exit(2)
@@ -179,12 +247,21 @@ func invoke(cmd *base.Command, args []string) error {
 	defer task.End()
 
 	// initialise default logging.
+	runid.Set(cfg.RunID)
 	if lg, err := initLog(cfg.LogFile, cfg.JsonHandler, cfg.Verbose); err != nil {
 		return err
 	} else {
-		lg.With("command", cmd.Name())
+		lg = lg.With("command", cmd.Name(), "run_id", runid.ID())
+		slog.SetDefault(lg)
 		cfg.Log = lg
 	}
+	trace.Log(ctx, "run_id", runid.ID())
+	trace.Log(ctx, "version", cfg.Version.String())
+
+	if cmd != cachecmd.CmdCacheMigrate {
+		// upgrade the cache files of the previous versions, once.
+		cachecmd.AutoMigrate(ctx, cfg.CacheDir())
+	}
 
 	if cmd.RequireAuth {
 		trace.Logf(ctx, "invoke", "command %s requires auth", cmd.Name())
@@ -194,9 +271,40 @@ func invoke(cmd *base.Command, args []string) error {
 			base.SetExitStatus(base.SAuthError)
 			return fmt.Errorf("auth error: %w", err)
 		}
+		if wsp, err := workspace.Current(cfg.CacheDir(), cfg.Workspace); err == nil {
+			ctx = bootstrap.WithPenalties(ctx, wsp)
+		}
+	}
+	if cfg.MonitorAddr != "" {
+		if err := startMonitor(ctx, cfg.MonitorAddr); err != nil {
+			base.SetExitStatus(base.SInvalidParameters)
+			return err
+		}
+		cfg.Monitor.Start(cmd.Name())
 	}
 	trace.Log(ctx, "command", fmt.Sprint("Running ", cmd.Name(), " command"))
-	return cmd.Run(ctx, cmd, args)
+	err := cmd.Run(ctx, cmd, args)
+	if cfg.Monitor != nil {
+		cfg.Monitor.Finish(cmd.Name(), err)
+	}
+	return err
+}
+
+// startMonitor starts serving the health and metrics endpoints on addr in
+// the background, until the context is cancelled.
+func startMonitor(ctx context.Context, addr string) error {
+	ln, err := monitor.Listen(addr)
+	if err != nil {
+		return fmt.Errorf("monitoring: %w", err)
+	}
+	cfg.Monitor = monitor.New()
+	go func() {
+		if err := cfg.Monitor.Serve(ctx, ln); err != nil {
+			cfg.Log.ErrorContext(ctx, "monitoring server error", "error", err)
+		}
+	}()
+	cfg.Log.InfoContext(ctx, "monitoring endpoints are available", "healthz", "http://"+ln.Addr().String()+"/healthz", "metrics", "http://"+ln.Addr().String()+"/metrics")
+	return nil
 }
 
 func parseFlags(cmd *base.Command, args []string) ([]string, error) {
@@ -233,7 +341,7 @@ func initTrace(filename string) error {
 
 	trc := tracer.New(filename)
 	if err := trc.Start(); err != nil {
-		return nil
+		return err
 	}
 
 	stop := func() {
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/rusq/slackdump/v3/internal/network"
+	"github.com/rusq/slackdump/v3/progress"
+	"github.com/rusq/slackdump/v3/stream"
 )
 
 // Config is the option set for the Session.
@@ -14,6 +16,11 @@ type config struct {
 	dumpFiles       bool          // will we save the conversation files?
 	cacheRetention  time.Duration // how long to keep the cache (user, etc.)
 	forceEnterprise bool          // force enterprise workspace
+	progress        *progress.Tracker
+	infoCache       stream.ChannelInfoCache // persistent channel info cache
+	checkpointer    stream.Checkpointer     // conversation cursor checkpointer
+	fileWorkers     int                     // overrides the limits.Workers, if positive
+	bandwidth       int64                   // file download limit, bytes per second
 }
 
 // DefOptions is the default options used when initialising slackdump instance.
@@ -0,0 +1,47 @@
+package downloader
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// maxBandwidthBurst is the maximum number of bytes, that is written at once
+// by the throttled writer.
+const maxBandwidthBurst = 32 * 1024
+
+// NewBandwidthLimiter returns the limiter, that allows bps bytes per second,
+// or nil, if bps is not positive.  It can be shared by several clients with
+// [WithBandwidthLimiter], to limit their combined download rate.
+func NewBandwidthLimiter(bps int64) *rate.Limiter {
+	if bps <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(bps), int(min(bps, maxBandwidthBurst)))
+}
+
+// throttledWriter is the writer, that waits for the limiter before writing
+// each chunk of data, the chunk is not larger than the limiter burst.
+type throttledWriter struct {
+	ctx context.Context
+	w   io.Writer
+	l   *rate.Limiter
+}
+
+func (tw *throttledWriter) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		n := min(len(p), tw.l.Burst())
+		if err := tw.l.WaitN(tw.ctx, n); err != nil {
+			return written, err
+		}
+		m, err := tw.w.Write(p[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
@@ -0,0 +1,63 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// chunkWriter records the sizes of the writes.
+type chunkWriter struct {
+	bytes.Buffer
+	sizes []int
+}
+
+func (cw *chunkWriter) Write(p []byte) (int, error) {
+	cw.sizes = append(cw.sizes, len(p))
+	return cw.Buffer.Write(p)
+}
+
+func TestNewBandwidthLimiter(t *testing.T) {
+	assert.Nil(t, NewBandwidthLimiter(0))
+	assert.Nil(t, NewBandwidthLimiter(-1))
+	assert.Equal(t, 100, NewBandwidthLimiter(100).Burst())
+	assert.Equal(t, maxBandwidthBurst, NewBandwidthLimiter(1<<30).Burst())
+}
+
+func TestWithBandwidthLimiter(t *testing.T) {
+	l := NewBandwidthLimiter(100)
+	var a, b options
+	WithBandwidthLimiter(l)(&a)
+	WithBandwidthLimiter(l)(&b)
+	assert.Same(t, a.bandwidth, b.bandwidth, "limiter is not shared")
+	WithBandwidth(100)(&b)
+	assert.NotSame(t, a.bandwidth, b.bandwidth, "WithBandwidth must create a new limiter")
+}
+
+func Test_throttledWriter_Write(t *testing.T) {
+	t.Run("writes in chunks", func(t *testing.T) {
+		data := bytes.Repeat([]byte("0123456789"), 10_000)
+		var cw chunkWriter
+		tw := &throttledWriter{ctx: context.Background(), w: &cw, l: NewBandwidthLimiter(1 << 30)}
+		n, err := tw.Write(data)
+		assert.NoError(t, err)
+		assert.Equal(t, len(data), n)
+		assert.Equal(t, data, cw.Bytes())
+		assert.Equal(t, []int{maxBandwidthBurst, maxBandwidthBurst, maxBandwidthBurst, len(data) - 3*maxBandwidthBurst}, cw.sizes)
+	})
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var cw chunkWriter
+		tw := &throttledWriter{ctx: ctx, w: &cw, l: NewBandwidthLimiter(10)}
+		// the first chunk is within the burst.
+		n, err := tw.Write([]byte("0123456789"))
+		assert.NoError(t, err)
+		assert.Equal(t, 10, n)
+		cancel()
+		n, err = tw.Write([]byte("0123456789"))
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, n)
+	})
+}
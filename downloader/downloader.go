@@ -2,6 +2,8 @@ package downloader
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"hash/crc64"
@@ -12,12 +14,15 @@ import (
 	"runtime/trace"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/rusq/fsadapter"
 	"github.com/rusq/slack"
 	"golang.org/x/time/rate"
 
+	"github.com/rusq/slackdump/v3/internal/mtimefs"
 	"github.com/rusq/slackdump/v3/internal/network"
+	"github.com/rusq/slackdump/v3/internal/structures/files"
 )
 
 const (
@@ -31,6 +36,9 @@ var (
 	ErrNoFS           = errors.New("fs adapter not initialised")
 	ErrNotStarted     = errors.New("downloader not started")
 	ErrAlreadyStarted = errors.New("downloader already started")
+	// ErrSizeMismatch is returned when the size of the downloaded file
+	// does not match the expected size.
+	ErrSizeMismatch = errors.New("file size mismatch")
 )
 
 // Downloader is the file downloader interface.  It exists primarily for mocking
@@ -51,6 +59,9 @@ type Client struct {
 	mu      sync.Mutex // mutex prevents race condition when starting/stopping
 	started atomic.Bool
 
+	storedMu sync.Mutex
+	stored   map[string]struct{} // content paths, that were saved.
+
 	options
 }
 
@@ -63,6 +74,23 @@ type options struct {
 	workers   int
 	lg        *slog.Logger
 	chanBufSz int
+	errFn     func(req Request, err error)
+	tracker   Tracker
+	contentFn ContentPathFunc
+	bandwidth *rate.Limiter // shared by all workers, nil if unlimited.
+}
+
+// Tracker tracks the download state of the files.  Methods are called from
+// the download workers, so they must be safe for concurrent use.
+type Tracker interface {
+	// Pending is called when the file is placed in the download queue.
+	Pending(req Request)
+	// Complete is called when the file is downloaded, n is the number of
+	// bytes written.
+	Complete(req Request, n int64)
+	// Failed is called when the file download fails.  It is not called
+	// for the downloads that were cancelled, they remain pending.
+	Failed(req Request, err error)
 }
 
 // FilenameFunc is the file naming function that should return the output
@@ -112,6 +140,80 @@ func WithLogger(l *slog.Logger) Option {
 	}
 }
 
+// WithErrorFunc sets the function that is called for each file that failed
+// to download.  It is called from the download workers, so it must be safe
+// for concurrent use.
+func WithErrorFunc(fn func(req Request, err error)) Option {
+	return func(c *options) {
+		c.errFn = fn
+	}
+}
+
+// WithTracker sets the download state tracker.  If the option is given
+// several times, all trackers are called in the order they were given.
+func WithTracker(t Tracker) Option {
+	return func(c *options) {
+		if t == nil {
+			return
+		}
+		if c.tracker != nil {
+			c.tracker = multiTracker{c.tracker, t}
+			return
+		}
+		c.tracker = t
+	}
+}
+
+// WithBandwidth limits the combined download rate of all workers to bps
+// bytes per second.  Zero or negative value means no limit.
+func WithBandwidth(bps int64) Option {
+	return WithBandwidthLimiter(NewBandwidthLimiter(bps))
+}
+
+// WithBandwidthLimiter limits the download rate of all workers with the
+// limiter l, see [NewBandwidthLimiter].  Clients sharing the limiter are
+// limited to its rate combined.  If l is nil, the rate is not limited.
+func WithBandwidthLimiter(l *rate.Limiter) Option {
+	return func(c *options) {
+		c.bandwidth = l
+	}
+}
+
+// ContentPathFunc returns the path to save the file, downloaded for the
+// request req, which contents has the SHA-256 hash sum (hex-encoded).
+type ContentPathFunc func(req Request, sum string) string
+
+// WithContentPath enables the content-addressed storage: each downloaded
+// file is saved to the path, returned by fn, instead of the Fullpath of the
+// request, and the files with the same contents are saved once.  The path is
+// passed to the tracker in the Stored field of the request.
+func WithContentPath(fn ContentPathFunc) Option {
+	return func(c *options) {
+		c.contentFn = fn
+	}
+}
+
+// multiTracker calls all trackers in order.
+type multiTracker []Tracker
+
+func (mt multiTracker) Pending(req Request) {
+	for _, t := range mt {
+		t.Pending(req)
+	}
+}
+
+func (mt multiTracker) Complete(req Request, n int64) {
+	for _, t := range mt {
+		t.Complete(req, n)
+	}
+}
+
+func (mt multiTracker) Failed(req Request, err error) {
+	for _, t := range mt {
+		t.Failed(req, err)
+	}
+}
+
 // New initialises new file downloader.
 func New(sc Downloader, fs fsadapter.FS, opts ...Option) *Client {
 	if sc == nil {
@@ -135,9 +237,22 @@ func New(sc Downloader, fs fsadapter.FS, opts ...Option) *Client {
 	return c
 }
 
+// Request is the file download request.
 type Request struct {
 	Fullpath string
 	URL      string
+	// FileID is the Slack file ID, if known.
+	FileID string
+	// Size is the expected size of the file, if it is known.  If it is
+	// not zero, the size of the downloaded file is verified.
+	Size int64
+	// ModTime is the modification time to set on the downloaded file, if
+	// the filesystem supports it.  Zero value leaves the download time.
+	ModTime time.Time
+	// Stored is the path, the file was saved to, if it differs from the
+	// Fullpath, i.e. with the content-addressed storage, see
+	// [WithContentPath].  It is set by the downloader.
+	Stored string
 }
 
 // Start starts an async file downloader.  If the downloader is already
@@ -218,21 +333,35 @@ func (c *Client) worker(ctx context.Context, reqC <-chan Request) {
 	for req := range reqC {
 		lg := c.lg.With("filename", path.Base(req.URL), "destination", req.Fullpath)
 		lg.DebugContext(ctx, "saving file")
-		n, err := c.download(ctx, req.Fullpath, req.URL)
+		tctx, task := trace.NewTask(ctx, "download")
+		trace.Log(tctx, "destination", req.Fullpath)
+		n, err := c.download(tctx, &req)
+		task.End()
 		if err != nil {
 			if errors.Is(err, context.Canceled) {
 				lg.DebugContext(ctx, "download cancelled")
 			} else {
 				lg.ErrorContext(ctx, "error saving file", "error", err)
+				if c.errFn != nil {
+					c.errFn(req, err)
+				}
+				if c.tracker != nil {
+					c.tracker.Failed(req, err)
+				}
 			}
 		} else {
 			lg.DebugContext(ctx, "file saved", "bytes_written", n)
+			if c.tracker != nil {
+				c.tracker.Complete(req, n)
+			}
 		}
 	}
 }
 
-// saveFileWithLimiter saves the file to specified directory, it will use the provided limiter l for throttling.
-func (c *Client) download(ctx context.Context, fullpath string, url string) (int64, error) {
+// download saves the file to specified directory, it will use the limiter
+// for throttling.  If the request has the expected size, and the downloaded
+// file size does not match, the download is retried.
+func (c *Client) download(ctx context.Context, req *Request) (int64, error) {
 	if c.fsa == nil {
 		return 0, ErrNoFS
 	}
@@ -246,18 +375,7 @@ func (c *Client) download(ctx context.Context, fullpath string, url string) (int
 		os.Remove(tf.Name())
 	}()
 
-	if err := network.WithRetry(ctx, c.limiter, c.retries, func() error {
-		region := trace.StartRegion(ctx, "GetFile")
-		defer region.End()
-
-		if err := c.sc.GetFileContext(ctx, url, tf); err != nil {
-			if _, err := tf.Seek(0, io.SeekStart); err != nil {
-				c.lg.ErrorContext(ctx, "seek", "error", err)
-			}
-			return fmt.Errorf("download to %q failed, [src=%s]: %w", fullpath, url, err)
-		}
-		return nil
-	}); err != nil {
+	if err := c.fetch(ctx, tf, *req); err != nil {
 		return 0, err
 	}
 
@@ -267,7 +385,16 @@ func (c *Client) download(ctx context.Context, fullpath string, url string) (int
 		return 0, err
 	}
 
-	fsf, err := c.fsa.Create(fullpath)
+	if c.contentFn != nil {
+		return c.saveContent(ctx, tf, req)
+	}
+	return c.save(ctx, tf, req.Fullpath, req.ModTime)
+}
+
+// save copies the contents of the downloaded file tf to fullpath.
+func (c *Client) save(ctx context.Context, tf *os.File, fullpath string, modTime time.Time) (int64, error) {
+	defer trace.StartRegion(ctx, "saveFile").End()
+	fsf, err := mtimefs.Create(c.fsa, fullpath, modTime)
 	if err != nil {
 		return 0, err
 	}
@@ -281,6 +408,95 @@ func (c *Client) download(ctx context.Context, fullpath string, url string) (int
 	return int64(n), nil
 }
 
+// saveContent saves the downloaded file tf to the content path, unless the
+// file with the same contents was already saved.  It sets the Stored path of
+// the request.
+func (c *Client) saveContent(ctx context.Context, tf *os.File, req *Request) (int64, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, tf)
+	if err != nil {
+		return 0, err
+	}
+	req.Stored = c.contentFn(*req, hex.EncodeToString(h.Sum(nil)))
+
+	c.storedMu.Lock()
+	if _, ok := c.stored[req.Stored]; ok {
+		c.storedMu.Unlock()
+		c.lg.DebugContext(ctx, "file with the same contents is already saved", "destination", req.Fullpath, "stored", req.Stored)
+		return n, nil
+	}
+	if c.stored == nil {
+		c.stored = make(map[string]struct{})
+	}
+	c.stored[req.Stored] = struct{}{}
+	c.storedMu.Unlock()
+
+	if _, err := tf.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err = c.save(ctx, tf, req.Stored, req.ModTime)
+	if err != nil {
+		// the next file with the same contents retries the save.
+		c.storedMu.Lock()
+		delete(c.stored, req.Stored)
+		c.storedMu.Unlock()
+		return 0, err
+	}
+	return n, nil
+}
+
+// fetch downloads the file into the temporary file tf, overwriting its
+// contents.  The download is retried if the size of the downloaded file does
+// not match the expected size.
+func (c *Client) fetch(ctx context.Context, tf *os.File, req Request) error {
+	return network.WithRetry(ctx, c.limiter, c.retries, func() error {
+		region := trace.StartRegion(ctx, "GetFile")
+		defer region.End()
+
+		if err := resetFile(tf); err != nil {
+			return err
+		}
+		var w io.Writer = tf
+		if c.bandwidth != nil {
+			w = &throttledWriter{ctx: ctx, w: tf, l: c.bandwidth}
+		}
+		if err := c.sc.GetFileContext(ctx, req.URL, w); err != nil {
+			return fmt.Errorf("download to %q failed, [src=%s]: %w", req.Fullpath, req.URL, err)
+		}
+		if err := checkSize(tf, req.Size); err != nil {
+			// the transfer was interrupted, retrying.
+			return &network.TransientError{Err: fmt.Errorf("download to %q failed, [src=%s]: %w", req.Fullpath, req.URL, err)}
+		}
+		return nil
+	})
+}
+
+// resetFile truncates the file and rewinds it to the beginning, so that the
+// partial data from the failed attempt does not remain in the file.
+func resetFile(f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := f.Seek(0, io.SeekStart)
+	return err
+}
+
+// checkSize verifies that the size of the file f matches the expected size.
+// Zero expected size means that the size is unknown and is not verified.
+func checkSize(f *os.File, expected int64) error {
+	if expected == 0 {
+		return nil
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size() != expected {
+		return fmt.Errorf("%w: expected %d, got %d", ErrSizeMismatch, expected, fi.Size())
+	}
+	return nil
+}
+
 // Stop waits for all transfers to finish, and stops the downloader.
 func (c *Client) Stop() {
 	if !c.started.CompareAndSwap(true, false) {
@@ -304,13 +520,22 @@ func (c *Client) Stop() {
 // Download requires a started downloader, otherwise it will return
 // ErrNotStarted. Will place the file to the download queue.
 func (c *Client) Download(fullpath string, url string) error {
+	return c.Enqueue(Request{Fullpath: fullpath, URL: url})
+}
+
+// Enqueue places the request to the download queue.  It requires a started
+// downloader, otherwise it will return ErrNotStarted.
+func (c *Client) Enqueue(req Request) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if !c.started.Load() {
 		return ErrNotStarted
 	}
 
-	c.requests <- Request{Fullpath: fullpath, URL: url}
+	if c.tracker != nil {
+		c.tracker.Pending(req)
+	}
+	c.requests <- req
 
 	return nil
 }
@@ -324,7 +549,7 @@ func (c *Client) AsyncDownloader(ctx context.Context, queueC <-chan Request) (<-
 	go func() {
 		defer close(done)
 		for r := range queueC {
-			if err := c.Download(r.Fullpath, r.URL); err != nil {
+			if err := c.Enqueue(r); err != nil {
 				c.lg.Error("download error", "url", r.URL, "error", err)
 			}
 		}
@@ -335,5 +560,14 @@ func (c *Client) AsyncDownloader(ctx context.Context, queueC <-chan Request) (<-
 }
 
 func stdFilenameFn(f *slack.File) string {
-	return fmt.Sprintf("%s-%s", f.ID, f.Name)
+	return fmt.Sprintf("%s-%s", f.ID, files.Name(f))
+}
+
+// ModTime returns the time when the file was uploaded to Slack, or zero
+// time, if it is not known.
+func ModTime(f *slack.File) time.Time {
+	if f.Created == 0 {
+		return time.Time{}
+	}
+	return f.Created.Time()
 }
@@ -2,14 +2,21 @@ package downloader
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/rusq/slackdump/v3/internal/fixtures"
+	"github.com/rusq/slackdump/v3/internal/mtimefs"
 )
 
 func init() {
@@ -178,3 +185,238 @@ func TestStart(t *testing.T) {
 		assert.True(t, c.started.Load(), "expected started to be true")
 	})
 }
+
+type failingGetter struct{}
+
+func (failingGetter) GetFileContext(ctx context.Context, downloadURL string, writer io.Writer) error {
+	return errors.New("not today")
+}
+
+func TestWithErrorFunc(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		failed []Request
+	)
+	c := New(failingGetter{}, fsadapter.NewDirectory(t.TempDir()), WithErrorFunc(func(req Request, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Error(t, err)
+		failed = append(failed, req)
+	}))
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Download("x/file", "http://example.com/file"); err != nil {
+		t.Fatal(err)
+	}
+	c.Stop()
+	assert.Equal(t, []Request{{Fullpath: "x/file", URL: "http://example.com/file"}}, failed)
+}
+
+// sizedGetter returns the data, but the first short responses are cut in
+// half, to emulate the interrupted transfers.
+type sizedGetter struct {
+	mu    sync.Mutex
+	data  []byte
+	short int
+	calls int
+}
+
+func (g *sizedGetter) GetFileContext(ctx context.Context, downloadURL string, writer io.Writer) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.calls++
+	data := g.data
+	if g.calls <= g.short {
+		data = data[:len(data)/2]
+	}
+	_, err := writer.Write(data)
+	return err
+}
+
+type testTracker struct {
+	mu       sync.Mutex
+	pending  []string
+	complete map[string]int64
+	failed   map[string]error
+}
+
+func newTestTracker() *testTracker {
+	return &testTracker{complete: make(map[string]int64), failed: make(map[string]error)}
+}
+
+func (t *testTracker) Pending(req Request) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending = append(t.pending, req.Fullpath)
+}
+
+func (t *testTracker) Complete(req Request, n int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.complete[req.Fullpath] = n
+}
+
+func (t *testTracker) Failed(req Request, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failed[req.Fullpath] = err
+}
+
+func TestClient_sizeVerification(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+	tests := []struct {
+		name      string
+		short     int
+		size      int64
+		wantErr   bool
+		wantCalls int
+	}{
+		{"size matches", 0, int64(len(data)), false, 1},
+		{"size unknown", 1, 0, false, 1},
+		{"recovers after retry", 2, int64(len(data)), false, 3},
+		{"retries exhausted", 3, int64(len(data)), true, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			g := &sizedGetter{data: data, short: tt.short}
+			tr := newTestTracker()
+			c := New(g, fsadapter.NewDirectory(dir), Retries(3), WithTracker(tr))
+			if err := c.Start(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Enqueue(Request{Fullpath: "x/file", URL: "http://example.com/file", Size: tt.size}); err != nil {
+				t.Fatal(err)
+			}
+			c.Stop()
+
+			assert.Equal(t, tt.wantCalls, g.calls)
+			assert.Equal(t, []string{"x/file"}, tr.pending)
+			if tt.wantErr {
+				assert.ErrorIs(t, tr.failed["x/file"], ErrSizeMismatch)
+				assert.Empty(t, tr.complete)
+				return
+			}
+			assert.Empty(t, tr.failed)
+			if tt.size != 0 {
+				assert.Equal(t, tt.size, tr.complete["x/file"])
+				got, err := os.ReadFile(filepath.Join(dir, "x", "file"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, data, got)
+			}
+		})
+	}
+}
+
+func TestClient_download_modTime(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte("0123456789")
+	modTime := time.Date(2021, 3, 14, 15, 9, 26, 0, time.UTC)
+
+	c := New(&sizedGetter{data: data}, mtimefs.NewDirectory(dir))
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Enqueue(Request{Fullpath: "x/file", URL: "http://example.com/file", ModTime: modTime}); err != nil {
+		t.Fatal(err)
+	}
+	c.Stop()
+
+	fi, err := os.Stat(filepath.Join(dir, "x", "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, fi.ModTime().Equal(modTime), "got mod time %s", fi.ModTime())
+}
+
+func TestModTime(t *testing.T) {
+	assert.True(t, ModTime(&slack.File{}).IsZero())
+	assert.Equal(t, time.Unix(1615734566, 0), ModTime(&slack.File{Created: 1615734566}))
+}
+
+func TestWithTracker_multiple(t *testing.T) {
+	tr1, tr2 := newTestTracker(), newTestTracker()
+	c := New(&sizedGetter{data: []byte("data")}, fsadapter.NewDirectory(t.TempDir()), WithTracker(tr1), WithTracker(nil), WithTracker(tr2))
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Enqueue(Request{Fullpath: "x/file", URL: "http://example.com/file"}); err != nil {
+		t.Fatal(err)
+	}
+	c.Stop()
+
+	for _, tr := range []*testTracker{tr1, tr2} {
+		assert.Equal(t, []string{"x/file"}, tr.pending)
+		assert.Equal(t, int64(4), tr.complete["x/file"])
+	}
+}
+
+// urlGetter returns the data for the URL.
+type urlGetter struct {
+	mu    sync.Mutex
+	data  map[string][]byte
+	calls int
+}
+
+func (g *urlGetter) GetFileContext(_ context.Context, downloadURL string, writer io.Writer) error {
+	g.mu.Lock()
+	g.calls++
+	g.mu.Unlock()
+	_, err := writer.Write(g.data[downloadURL])
+	return err
+}
+
+type storedTracker struct {
+	testTracker
+	stored map[string]string
+}
+
+func (t *storedTracker) Complete(req Request, n int64) {
+	t.testTracker.Complete(req, n)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stored[req.Fullpath] = req.Stored
+}
+
+func TestWithContentPath(t *testing.T) {
+	dir := t.TempDir()
+	g := &urlGetter{data: map[string][]byte{
+		"http://example.com/1": []byte("same"),
+		"http://example.com/2": []byte("same"),
+		"http://example.com/3": []byte("different"),
+	}}
+	tr := &storedTracker{testTracker: *newTestTracker(), stored: make(map[string]string)}
+	c := New(g, fsadapter.NewDirectory(dir), Workers(1), WithTracker(tr), WithContentPath(func(req Request, sum string) string {
+		return filepath.Join("pool", sum[:8]+filepath.Ext(req.Fullpath))
+	}))
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"a/one.txt", "b/two.txt", "c/three.txt"} {
+		if err := c.Enqueue(Request{Fullpath: name, URL: "http://example.com/" + string(rune('1'+i))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.Stop()
+
+	assert.Equal(t, 3, g.calls)
+	assert.Equal(t, map[string]string{
+		"a/one.txt":   filepath.Join("pool", "0967115f.txt"),
+		"b/two.txt":   filepath.Join("pool", "0967115f.txt"),
+		"c/three.txt": filepath.Join("pool", "9d6f965a.txt"),
+	}, tr.stored)
+	assert.Equal(t, map[string]int64{"a/one.txt": 4, "b/two.txt": 4, "c/three.txt": 9}, tr.complete)
+
+	entries, err := os.ReadDir(filepath.Join(dir, "pool"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, entries, 2)
+	for _, name := range []string{"a", "b", "c"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.ErrorIs(t, err, os.ErrNotExist, "file is not saved under the request path")
+	}
+}
@@ -19,7 +19,15 @@ type ExportMessage struct {
 	SourceTeam      string             `json:"source_team,omitempty"`
 	UserProfile     *ExportUserProfile `json:"user_profile,omitempty"`
 	ReplyUsersCount int                `json:"reply_users_count,omitempty"`
-	slackdumpTime   time.Time          `json:"-"` // to speedup sorting
+	// SubMessage and PreviousMessage are the nested messages of the
+	// message_changed and similar subtypes, they are kept as is.
+	SubMessage      *slack.Msg `json:"message,omitempty"`
+	PreviousMessage *slack.Msg `json:"previous_message,omitempty"`
+	// TSRFC3339 and ThreadTSRFC3339 are the RFC 3339 times of the message
+	// and thread timestamps, they are set only if requested.
+	TSRFC3339       string    `json:"ts_rfc3339,omitempty"`
+	ThreadTSRFC3339 string    `json:"thread_ts_rfc3339,omitempty"`
+	slackdumpTime   time.Time `json:"-"` // to speedup sorting
 }
 
 type ExportUserProfile struct {
@@ -34,6 +42,15 @@ type ExportUserProfile struct {
 	IsUltraRestricted bool   `json:"is_ultra_restricted"`
 }
 
+// Message returns the slack message with the nested messages.  It returns nil
+// if the message is empty.
+func (em ExportMessage) Message() *slack.Message {
+	if em.Msg == nil {
+		return nil
+	}
+	return &slack.Message{Msg: *em.Msg, SubMessage: em.SubMessage, PreviousMessage: em.PreviousMessage}
+}
+
 func (em ExportMessage) Time() time.Time {
 	if em.slackdumpTime.IsZero() {
 		ts, _ := structures.ParseSlackTS(em.Timestamp)
@@ -0,0 +1,244 @@
+// Package activity builds the cross-reference index of the user activity:
+// for each user, the channels and threads they posted in, with the message
+// counts, and for each channel, the summary of its participants.
+package activity
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"sort"
+	"time"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+// Thread is the activity of the user in the thread.
+type Thread struct {
+	ThreadTS string `json:"thread_ts"`
+	Messages int    `json:"messages"`
+}
+
+// Channel is the activity of the user in the channel.
+type Channel struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Messages int    `json:"messages"`
+	// Threads are the threads the user posted in, sorted by the thread
+	// timestamp.
+	Threads []Thread `json:"threads,omitempty"`
+}
+
+// User is the activity of the user.
+type User struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Messages int    `json:"messages"`
+	// Channels are sorted by the number of messages, most active first.
+	Channels []Channel `json:"channels"`
+}
+
+// Participant is the user in the channel participants summary.
+type Participant struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Messages int    `json:"messages"`
+	// Threads is the number of the threads the user posted in.
+	Threads int `json:"threads"`
+	// FirstActivity and LastActivity are the times of the first and the
+	// last message of the user in the channel.
+	FirstActivity time.Time `json:"first_activity"`
+	LastActivity  time.Time `json:"last_activity"`
+}
+
+// Participants is the summary of the users, that posted in the channel.
+type Participants struct {
+	ChannelID string `json:"channel_id"`
+	Name      string `json:"name,omitempty"`
+	Messages  int    `json:"messages"`
+	// Participants are sorted by the number of messages, most active first.
+	Participants []Participant `json:"participants"`
+}
+
+// Index is the user activity index.
+type Index struct {
+	// Users are sorted by ID.
+	Users []User
+	// Channels are the participant summaries, sorted by channel ID.
+	Channels []Participants
+}
+
+// counter is the message count of the user in the channel.
+type counter struct {
+	messages    int
+	threads     map[string]int // thread_ts -> messages
+	first, last time.Time
+}
+
+// Collector counts the messages of the users.  Each message is counted once,
+// even if it appears both in the channel and in the thread, i.e. if it was
+// also sent to the channel.  Zero value is ready to use.
+type Collector struct {
+	seen   map[string]map[string]struct{} // channel ID -> message ts
+	counts map[string]map[string]*counter // user ID -> channel ID -> count
+}
+
+// AddMessage counts the message m, posted in the channel channelID.  The
+// messages without the user, i.e. bot messages, are ignored.
+func (c *Collector) AddMessage(channelID string, m *slack.Message) {
+	if m.User == "" || m.Timestamp == "" {
+		return
+	}
+	if c.seen == nil {
+		c.seen = make(map[string]map[string]struct{})
+		c.counts = make(map[string]map[string]*counter)
+	}
+	seen, ok := c.seen[channelID]
+	if !ok {
+		seen = make(map[string]struct{})
+		c.seen[channelID] = seen
+	}
+	if _, ok := seen[m.Timestamp]; ok {
+		return
+	}
+	seen[m.Timestamp] = struct{}{}
+
+	byChan, ok := c.counts[m.User]
+	if !ok {
+		byChan = make(map[string]*counter)
+		c.counts[m.User] = byChan
+	}
+	cnt, ok := byChan[channelID]
+	if !ok {
+		cnt = &counter{threads: make(map[string]int)}
+		byChan[channelID] = cnt
+	}
+	cnt.messages++
+	if ts, err := structures.ParseSlackTS(m.Timestamp); err == nil {
+		if cnt.first.IsZero() || ts.Before(cnt.first) {
+			cnt.first = ts
+		}
+		if ts.After(cnt.last) {
+			cnt.last = ts
+		}
+	}
+	if m.ThreadTimestamp != "" {
+		cnt.threads[m.ThreadTimestamp]++
+	}
+}
+
+// AddChunk counts the messages from the message and thread chunks.
+func (c *Collector) AddChunk(ch *chunk.Chunk) {
+	switch ch.Type {
+	case chunk.CMessages, chunk.CThreadMessages:
+		for i := range ch.Messages {
+			c.AddMessage(ch.ChannelID, &ch.Messages[i])
+		}
+	}
+}
+
+// Index returns the activity index.  Channel and user names are resolved from
+// channels and users, if they are present there.
+func (c *Collector) Index(channels []slack.Channel, users []slack.User) *Index {
+	chanNames := make(map[string]string, len(channels))
+	for _, ch := range channels {
+		chanNames[ch.ID] = ch.Name
+	}
+	userNames := make(map[string]string, len(users))
+	for _, u := range users {
+		userNames[u.ID] = u.Name
+	}
+
+	var idx Index
+	byChan := make(map[string]*Participants)
+	for userID, chans := range c.counts {
+		u := User{ID: userID, Name: userNames[userID]}
+		for channelID, cnt := range chans {
+			ch := Channel{ID: channelID, Name: chanNames[channelID], Messages: cnt.messages}
+			for ts, n := range cnt.threads {
+				ch.Threads = append(ch.Threads, Thread{ThreadTS: ts, Messages: n})
+			}
+			sort.Slice(ch.Threads, func(i, j int) bool {
+				return ch.Threads[i].ThreadTS < ch.Threads[j].ThreadTS
+			})
+			u.Channels = append(u.Channels, ch)
+			u.Messages += cnt.messages
+
+			p, ok := byChan[channelID]
+			if !ok {
+				p = &Participants{ChannelID: channelID, Name: chanNames[channelID]}
+				byChan[channelID] = p
+			}
+			p.Messages += cnt.messages
+			p.Participants = append(p.Participants, Participant{
+				ID:            userID,
+				Name:          u.Name,
+				Messages:      cnt.messages,
+				Threads:       len(cnt.threads),
+				FirstActivity: cnt.first,
+				LastActivity:  cnt.last,
+			})
+		}
+		sort.Slice(u.Channels, func(i, j int) bool {
+			if u.Channels[i].Messages != u.Channels[j].Messages {
+				return u.Channels[i].Messages > u.Channels[j].Messages
+			}
+			return u.Channels[i].ID < u.Channels[j].ID
+		})
+		idx.Users = append(idx.Users, u)
+	}
+	sort.Slice(idx.Users, func(i, j int) bool {
+		return idx.Users[i].ID < idx.Users[j].ID
+	})
+	for _, p := range byChan {
+		sort.Slice(p.Participants, func(i, j int) bool {
+			if p.Participants[i].Messages != p.Participants[j].Messages {
+				return p.Participants[i].Messages > p.Participants[j].Messages
+			}
+			return p.Participants[i].ID < p.Participants[j].ID
+		})
+		idx.Channels = append(idx.Channels, *p)
+	}
+	sort.Slice(idx.Channels, func(i, j int) bool {
+		return idx.Channels[i].ChannelID < idx.Channels[j].ChannelID
+	})
+	return &idx
+}
+
+// FromChunks counts the messages of all channels in the chunk directory cd,
+// and returns the activity index.
+func FromChunks(ctx context.Context, cd *chunk.Directory) (*Index, error) {
+	channels, err := cd.Channels()
+	if err != nil {
+		return nil, err
+	}
+	var c Collector
+	for _, ch := range channels {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		f, err := cd.Open(chunk.ToFileID(ch.ID, "", false))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		err = f.ForEach(func(ch *chunk.Chunk) error {
+			c.AddChunk(ch)
+			return nil
+		})
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	users, err := cd.Users()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return c.Index(channels, users), nil
+}
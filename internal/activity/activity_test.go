@@ -0,0 +1,78 @@
+package activity
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+)
+
+func msg(user, ts, threadTS string) slack.Message {
+	return slack.Message{Msg: slack.Msg{User: user, Timestamp: ts, ThreadTimestamp: threadTS}}
+}
+
+// ts returns the time of the slack timestamp "sec.frac".
+func ts(sec, frac int64) time.Time {
+	return time.Unix(sec, frac).UTC()
+}
+
+func TestCollector_Index(t *testing.T) {
+	var c Collector
+	c.AddChunk(&chunk.Chunk{
+		Type:      chunk.CMessages,
+		ChannelID: "C1",
+		Messages: []slack.Message{
+			msg("U1", "1.0", "1.0"),
+			msg("U2", "2.0", ""),
+			msg("U2", "1.2", "1.0"), // also sent to the channel.
+			{Msg: slack.Msg{BotID: "B1", Timestamp: "3.0"}},
+		},
+	})
+	c.AddChunk(&chunk.Chunk{
+		Type:      chunk.CThreadMessages,
+		ChannelID: "C1",
+		Parent:    &slack.Message{Msg: slack.Msg{User: "U1", Timestamp: "1.0", ThreadTimestamp: "1.0"}},
+		Messages: []slack.Message{
+			msg("U2", "1.1", "1.0"),
+			msg("U2", "1.2", "1.0"),
+			msg("U1", "1.3", "1.0"),
+		},
+	})
+	c.AddChunk(&chunk.Chunk{
+		Type:      chunk.CMessages,
+		ChannelID: "C2",
+		Messages:  []slack.Message{msg("U1", "1.0", "")},
+	})
+
+	idx := c.Index(
+		[]slack.Channel{{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1"}}}},
+		[]slack.User{{ID: "U1", Name: "alice"}},
+	)
+	wantUsers := []User{
+		{ID: "U1", Name: "alice", Messages: 3, Channels: []Channel{
+			{ID: "C1", Name: "general", Messages: 2, Threads: []Thread{{ThreadTS: "1.0", Messages: 2}}},
+			{ID: "C2", Messages: 1},
+		}},
+		{ID: "U2", Messages: 3, Channels: []Channel{
+			{ID: "C1", Name: "general", Messages: 3, Threads: []Thread{{ThreadTS: "1.0", Messages: 2}}},
+		}},
+	}
+	if !reflect.DeepEqual(idx.Users, wantUsers) {
+		t.Errorf("Users = %+v, want %+v", idx.Users, wantUsers)
+	}
+	wantChans := []Participants{
+		{ChannelID: "C1", Name: "general", Messages: 5, Participants: []Participant{
+			{ID: "U2", Messages: 3, Threads: 1, FirstActivity: ts(1, 1), LastActivity: ts(2, 0)},
+			{ID: "U1", Name: "alice", Messages: 2, Threads: 1, FirstActivity: ts(1, 0), LastActivity: ts(1, 3)},
+		}},
+		{ChannelID: "C2", Messages: 1, Participants: []Participant{
+			{ID: "U1", Name: "alice", Messages: 1, FirstActivity: ts(1, 0), LastActivity: ts(1, 0)},
+		}},
+	}
+	if !reflect.DeepEqual(idx.Channels, wantChans) {
+		t.Errorf("Channels = %+v, want %+v", idx.Channels, wantChans)
+	}
+}
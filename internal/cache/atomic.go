@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"io"
+
+	"github.com/rusq/encio"
+
+	"github.com/rusq/slackdump/v3/internal/osext"
+)
+
+// encryptedAtomic is the encrypted file writer, that replaces the target
+// file only on Close.  Until then, the concurrent readers see the previous
+// version of the file.
+type encryptedAtomic struct {
+	enc io.WriteCloser
+	af  *osext.AtomicFile
+}
+
+// createEncrypted creates the encrypted writer for the file filename.  Caller
+// must call Close to replace the file, or Abort to discard the data.
+func createEncrypted(filename string) (*encryptedAtomic, error) {
+	af, err := osext.CreateAtomic(filename)
+	if err != nil {
+		return nil, err
+	}
+	// the file is hidden from the encrypting writer, so that closing it does
+	// not replace the target file prematurely.
+	enc, err := encio.NewWriter(struct{ io.Writer }{af})
+	if err != nil {
+		af.Abort()
+		return nil, err
+	}
+	return &encryptedAtomic{enc: enc, af: af}, nil
+}
+
+func (w *encryptedAtomic) Write(p []byte) (int, error) {
+	return w.enc.Write(p)
+}
+
+// Close flushes the encrypted data and replaces the target file.
+func (w *encryptedAtomic) Close() error {
+	if err := w.enc.Close(); err != nil {
+		w.af.Abort()
+		return err
+	}
+	return w.af.Close()
+}
+
+// Abort discards the written data, the target file is left intact.  It is a
+// no-op after Close.
+func (w *encryptedAtomic) Abort() error {
+	return w.af.Abort()
+}
+
+// aborter is implemented by the writers that can discard the written data.
+type aborter interface {
+	Abort() error
+}
+
+// discard discards the data written to wc, if it supports it, otherwise
+// closes it.
+func discard(wc io.WriteCloser) {
+	if a, ok := wc.(aborter); ok {
+		_ = a.Abort()
+		return
+	}
+	_ = wc.Close()
+}
@@ -30,6 +30,16 @@ type AuthData struct {
 	Token         string
 	Cookie        string
 	UsePlaywright bool
+	// RefreshToken is the refresh token of the rotating Token, it is
+	// refreshed with the client ID and secret from OAuth.
+	RefreshToken string
+	// OAuth is the configuration of the Slack app.  If the client ID is set,
+	// the OAuth flow is used instead of the browser login.
+	OAuth auth.OAuthConfig
+	// BrowserProfile is the browser, which profiles the Slack cookies are
+	// read from, "auto" for all browsers, or the path to the cookie
+	// database.  The user must consent to it.
+	BrowserProfile string
 }
 
 var (
@@ -45,6 +55,9 @@ const (
 	ATCookieFile
 	ATRod
 	ATPlaywright
+	ATOAuth
+	ATRotating
+	ATBrowserProfile
 )
 
 // Type returns the authentication type that should be used for the current
@@ -58,6 +71,15 @@ func (c AuthData) Type(context.Context) (AuthType, error) {
 	if c.UsePlaywright {
 		ez = ATPlaywright
 	}
+	if c.Token != "" && c.RefreshToken != "" {
+		return ATRotating, nil
+	}
+	if c.OAuth.ClientID != "" {
+		return ATOAuth, nil
+	}
+	if c.BrowserProfile != "" {
+		return ATBrowserProfile, nil
+	}
 	if !c.IsEmpty() {
 		if isExistingFile(c.Cookie) {
 			return ATCookieFile, nil
@@ -98,6 +120,12 @@ func (c AuthData) AuthProvider(ctx context.Context, workspace string, opts ...au
 		return auth.NewRODAuth(ctx, opts...)
 	case ATPlaywright:
 		return auth.NewPlaywrightAuth(ctx, opts...)
+	case ATOAuth:
+		return auth.NewOAuthAuth(ctx, c.OAuth)
+	case ATRotating:
+		return auth.NewRotatingAuth(c.Token, c.RefreshToken, c.OAuth.ClientID, c.OAuth.ClientSecret)
+	case ATBrowserProfile:
+		return auth.NewBrowserProfileAuth(ctx, c.BrowserProfile, opts...)
 	}
 	return nil, errors.New("internal error: unsupported auth type")
 }
@@ -168,6 +196,7 @@ func initProvider(ctx context.Context, cacheDir string, filename string, workspa
 			msg := "loaded saved credentials"
 			lg.Debug(msg)
 			trace.Log(ctx, "info", msg)
+			persistRefreshed(prov, credsFile)
 			return prov, nil
 		}
 	}
@@ -182,17 +211,42 @@ func initProvider(ctx context.Context, cacheDir string, filename string, workspa
 	if err := saveCreds(filer, credsFile, provider); err != nil {
 		trace.Logf(ctx, "error", "failed to save credentials to: %s", credsFile)
 	}
+	persistRefreshed(provider, credsFile)
 
 	return provider, nil
 }
 
-var authTester = (auth.Provider).Test
+// persistRefreshed makes the provider save the credentials to credsFile each
+// time its token is refreshed, so that the rotated refresh token, that
+// invalidates the previous one, is not lost.
+func persistRefreshed(prov auth.Provider, credsFile string) {
+	auth.OnRefresh(prov, func(p auth.Provider) error {
+		return saveCreds(filer, credsFile, p)
+	})
+}
+
+var (
+	authTester    = (auth.Provider).Test
+	authRefresher = auth.RefreshIfExpired
+)
 
+// tryLoad loads the credentials from the file and tests them.  If the
+// credentials were obtained with the OAuth flow and are about to expire, they
+// are refreshed and saved to the file.
 func tryLoad(ctx context.Context, filename string) (auth.Provider, error) {
 	prov, err := loadCreds(filer, filename)
 	if err != nil {
 		return nil, err
 	}
+	prov, refreshed, err := authRefresher(ctx, prov)
+	if err != nil {
+		return nil, err
+	}
+	if refreshed {
+		if err := saveCreds(filer, filename, prov); err != nil {
+			return nil, fmt.Errorf("failed to save the refreshed credentials: %w", err)
+		}
+	}
 	// test the loaded credentials
 	if _, err := authTester(prov, ctx); err != nil {
 		return nil, err
@@ -211,15 +265,18 @@ func loadCreds(ct container, filename string) (auth.Provider, error) {
 	return auth.Load(f)
 }
 
-// saveCreds encrypts and saves the credentials.
+// saveCreds encrypts and saves the credentials.  If the container supports
+// it, the partially written credentials are discarded on error.
 func saveCreds(ct container, filename string, p auth.Provider) error {
 	f, err := ct.Create(filename)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-
-	return auth.Save(f, p)
+	if err := auth.Save(f, p); err != nil {
+		discard(f)
+		return err
+	}
+	return f.Close()
 }
 
 // AuthReset removes the cached credentials.
@@ -240,8 +297,11 @@ func (encryptedFile) Open(filename string) (io.ReadCloser, error) {
 	return encio.Open(filename)
 }
 
+// Create returns the writer that replaces the file atomically, once closed,
+// so that a concurrent slackdump process never reads partially written
+// credentials.
 func (encryptedFile) Create(filename string) (io.WriteCloser, error) {
-	return encio.Create(filename)
+	return createEncrypted(filename)
 }
 
 // EZLoginFlags is a diagnostic function that returns the map of flags that
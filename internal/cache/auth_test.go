@@ -52,9 +52,12 @@ func TestAuthData_Type(t *testing.T) {
 		t.Fatal(err)
 	}
 	type fields struct {
-		Token         string
-		Cookie        string
-		UsePlaywright bool
+		Token          string
+		Cookie         string
+		UsePlaywright  bool
+		OAuth          auth.OAuthConfig
+		RefreshToken   string
+		BrowserProfile string
 	}
 	type args struct {
 		ctx context.Context
@@ -69,6 +72,9 @@ func TestAuthData_Type(t *testing.T) {
 	tests := []test{
 		{"value", fields{Token: "t", Cookie: "c"}, args{context.Background()}, ATValue, false},
 		{"cookie file", fields{Token: "t", Cookie: testFile}, args{context.Background()}, ATCookieFile, false},
+		{"oauth", fields{OAuth: auth.OAuthConfig{ClientID: "id"}}, args{context.Background()}, ATOAuth, false},
+		{"rotating", fields{Token: "xoxe.xoxp-1", RefreshToken: "xoxe-1", OAuth: auth.OAuthConfig{ClientID: "id"}}, args{context.Background()}, ATRotating, false},
+		{"browser profile", fields{BrowserProfile: "firefox"}, args{context.Background()}, ATBrowserProfile, false},
 	}
 	if !isWSL {
 		tests = append(tests, test{"rod", fields{Token: "", Cookie: ""}, args{context.Background()}, ATRod, false})
@@ -77,9 +83,12 @@ func TestAuthData_Type(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := AuthData{
-				Token:         tt.fields.Token,
-				Cookie:        tt.fields.Cookie,
-				UsePlaywright: tt.fields.UsePlaywright,
+				Token:          tt.fields.Token,
+				Cookie:         tt.fields.Cookie,
+				UsePlaywright:  tt.fields.UsePlaywright,
+				OAuth:          tt.fields.OAuth,
+				RefreshToken:   tt.fields.RefreshToken,
+				BrowserProfile: tt.fields.BrowserProfile,
 			}
 			got, err := c.Type(tt.args.ctx)
 			if (err != nil) != tt.wantErr {
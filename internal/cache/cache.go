@@ -81,20 +81,21 @@ func writeSlice[T any](w io.Writer, tt []T) error {
 }
 
 // save saves the users to a file, naming the file based on the filename
-// and the suffix. The file will be saved in the cache directory.
+// and the suffix. The file will be saved in the cache directory.  The file is
+// replaced atomically, the previous version is retained, if writing fails.
 func save[T any](cacheDir, filename string, suffix string, uu []T) error {
 	filename = makeCacheFilename(cacheDir, filename, suffix)
 
-	f, err := encio.Create(filename)
+	f, err := createEncrypted(filename)
 	if err != nil {
 		return fmt.Errorf("failed to create file %s: %w", filename, err)
 	}
-	defer f.Close()
+	defer f.Abort()
 
 	if err := writeSlice(f, uu); err != nil {
 		return fmt.Errorf("file: %s, error: %w", filename, err)
 	}
-	return nil
+	return f.Close()
 }
 
 // read reads the data from the reader r until it reaches the EOF and returns
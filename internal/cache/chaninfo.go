@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rusq/slack"
+)
+
+// DefChannelInfoSize is the default maximum number of entries in the
+// channel info cache.
+const DefChannelInfoSize = 1000
+
+// timeNow is the time function, replaced in tests.
+var timeNow = time.Now
+
+// ChannelInfoCache is the on-disk cache of the conversations.info results,
+// i.e. the topic, purpose and the member count of the channels, that is
+// shared between the commands.  Entries expire after the retention period,
+// and once the cache is full, the least recently used entries are evicted
+// on save.  It is safe for concurrent use.
+type ChannelInfoCache struct {
+	dir      string
+	filename string
+	suffix   string
+	ttl      time.Duration
+	size     int
+
+	mu      sync.Mutex
+	entries map[string]*chanInfoEntry
+	dirty   bool
+}
+
+// chanInfoEntry is the cached channel information.
+type chanInfoEntry struct {
+	Channel *slack.Channel `json:"channel"`
+	// Fetched is the time when the information was received from the API.
+	Fetched time.Time `json:"fetched"`
+	// Used is the time when the entry was last accessed.
+	Used time.Time `json:"used"`
+}
+
+// ChannelInfoCache returns the channel info cache of the workspace, the
+// entries are valid for ttl.  The cached entries are loaded from the cache
+// directory, if the cache file can't be read, the cache starts empty.
+func (m *Manager) ChannelInfoCache(workspace string, ttl time.Duration) *ChannelInfoCache {
+	c := &ChannelInfoCache{
+		dir:      m.dir,
+		filename: m.chanInfoFile,
+		suffix:   workspace,
+		ttl:      ttl,
+		size:     DefChannelInfoSize,
+		entries:  make(map[string]*chanInfoEntry),
+	}
+	// the file is older than any of its entries, so it is loaded only if
+	// it's not expired.
+	ee, err := load[chanInfoEntry](c.dir, c.filename, c.suffix, ttl)
+	if err != nil {
+		return c
+	}
+	now := timeNow()
+	for i := range ee {
+		if ee[i].Channel == nil || now.Sub(ee[i].Fetched) > ttl {
+			continue
+		}
+		c.entries[ee[i].Channel.ID] = &ee[i]
+	}
+	return c
+}
+
+// Get returns the cached channel information.
+func (c *ChannelInfoCache) Get(channelID string) (*slack.Channel, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[channelID]
+	if !ok {
+		return nil, false
+	}
+	now := timeNow()
+	if now.Sub(e.Fetched) > c.ttl {
+		delete(c.entries, channelID)
+		c.dirty = true
+		return nil, false
+	}
+	e.Used = now
+	c.dirty = true
+	return e.Channel, true
+}
+
+// Put adds the channel information to the cache.
+func (c *ChannelInfoCache) Put(ch *slack.Channel) {
+	if ch == nil || ch.ID == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := timeNow()
+	c.entries[ch.ID] = &chanInfoEntry{Channel: ch, Fetched: now, Used: now}
+	c.dirty = true
+}
+
+// Len returns the number of the cached entries.
+func (c *ChannelInfoCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Save evicts the expired and the least recently used entries over the
+// maximum size, and saves the cache to the cache directory, if it was
+// modified.
+func (c *ChannelInfoCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	now := timeNow()
+	ee := make([]chanInfoEntry, 0, len(c.entries))
+	for id, e := range c.entries {
+		if now.Sub(e.Fetched) > c.ttl {
+			delete(c.entries, id)
+			continue
+		}
+		ee = append(ee, *e)
+	}
+	sort.Slice(ee, func(i, j int) bool {
+		return ee[i].Used.After(ee[j].Used)
+	})
+	if len(ee) > c.size {
+		for _, e := range ee[c.size:] {
+			delete(c.entries, e.Channel.ID)
+		}
+		ee = ee[:c.size]
+	}
+	if err := save(c.dir, c.filename, c.suffix, ee); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func testChannel(id string) *slack.Channel {
+	return &slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: id}}}
+}
+
+func TestChannelInfoCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+
+	m, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := m.ChannelInfoCache("test", time.Hour)
+	assert.Equal(t, 0, c.Len())
+	if _, ok := c.Get("C1"); ok {
+		t.Fatal("unexpected entry in the empty cache")
+	}
+	c.Put(testChannel("C1"))
+	c.Put(testChannel("C2"))
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("reloaded", func(t *testing.T) {
+		c := m.ChannelInfoCache("test", time.Hour)
+		assert.Equal(t, 2, c.Len())
+		ch, ok := c.Get("C1")
+		assert.True(t, ok)
+		assert.Equal(t, "C1", ch.ID)
+	})
+	t.Run("other workspace", func(t *testing.T) {
+		c := m.ChannelInfoCache("other", time.Hour)
+		assert.Equal(t, 0, c.Len())
+	})
+	t.Run("expired", func(t *testing.T) {
+		now = now.Add(2 * time.Hour)
+		_, ok := c.Get("C1")
+		assert.False(t, ok)
+	})
+}
+
+func TestChannelInfoCache_Save_evict(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+
+	m, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := m.ChannelInfoCache("test", time.Hour)
+	c.size = 2
+	for _, id := range []string{"C1", "C2", "C3"} {
+		c.Put(testChannel(id))
+		now = now.Add(time.Minute)
+	}
+	// C1 is used, so C2 is the least recently used.
+	c.Get("C1")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, c.Len())
+	_, ok := c.Get("C2")
+	assert.False(t, ok)
+	_, ok = c.Get("C1")
+	assert.True(t, ok)
+}
@@ -34,12 +34,18 @@ import (
 //   - "workspace.txt" - a pointer to the current workspace, it contains the
 //     current workspace name.
 //   - "*.cache" - cache files, they contain the cache for users and channels.
+//   - ".lock" - the lock file, that serialises the changes to the workspaces
+//     between the concurrently running slackdump processes.
+//
+// All files are replaced atomically, so a concurrent process sees either the
+// previous or the new version of the file.
 type Manager struct {
 	dir         string
 	authOptions []auth.Option
 
-	userFile    string
-	channelFile string
+	userFile     string
+	channelFile  string
+	chanInfoFile string
 }
 
 const (
@@ -47,6 +53,7 @@ const (
 	defCredsFile   = "provider" + wspExt // default creds file
 	defName        = "default"           // name that will be shown for "provider.bin"
 	currentWspFile = "workspace.txt"
+	lockFile       = ".lock"
 )
 
 var (
@@ -105,9 +112,10 @@ func maybeAppendExt(filename string, ext string) string {
 // TODO: test with empty dir.
 func NewManager(dir string, opts ...Option) (*Manager, error) {
 	m := &Manager{
-		dir:         dir,
-		userFile:    "users.cache",
-		channelFile: "channels.cache",
+		dir:          dir,
+		userFile:     "users.cache",
+		channelFile:  "channels.cache",
+		chanInfoFile: "chaninfo.cache",
 	}
 	for _, opt := range opts {
 		opt(m)
@@ -175,13 +183,26 @@ func newErrNoWorkspace(name string) *ErrWorkspace {
 
 // Delete deletes the workspace file.
 func (m *Manager) Delete(name string) error {
-	if !m.Exists(name) {
-		return newErrNoWorkspace(name)
-	}
-	if err := os.Remove(m.filepath(name)); err != nil {
-		return &ErrWorkspace{Workspace: name, Message: "failed to delete", Err: err}
+	return m.locked(func() error {
+		if !m.Exists(name) {
+			return newErrNoWorkspace(name)
+		}
+		if err := os.Remove(m.filepath(name)); err != nil {
+			return &ErrWorkspace{Workspace: name, Message: "failed to delete", Err: err}
+		}
+		return nil
+	})
+}
+
+// locked calls fn while holding the lock on the cache directory.  fn must
+// not call other locking methods of the Manager.
+func (m *Manager) locked(fn func() error) error {
+	lock, err := osext.LockFile(filepath.Join(m.dir, lockFile))
+	if err != nil {
+		return fmt.Errorf("failed to lock the cache directory: %w", err)
 	}
-	return nil
+	defer lock.Unlock()
+	return fn()
 }
 
 func (m *Manager) List() ([]string, error) {
@@ -213,8 +234,29 @@ func (m *Manager) listFiles() ([]string, error) {
 	return files, nil
 }
 
-// Current returns the current workspace name.
+// Current returns the current workspace name.  If the current workspace is
+// not set, or does not exist, the default workspace is selected.
 func (m *Manager) Current() (string, error) {
+	wsp, err := m.current()
+	if !errors.Is(err, errNoCurrent) {
+		return wsp, err
+	}
+	// the current workspace is selected under lock, as another process
+	// might be selecting it at the same time.
+	err = m.locked(func() error {
+		var err error
+		if wsp, err = m.current(); errors.Is(err, errNoCurrent) {
+			wsp, err = m.selectDefault()
+		}
+		return err
+	})
+	return wsp, err
+}
+
+// errNoCurrent is returned by current, if the current workspace is not set.
+var errNoCurrent = errors.New("current workspace not set")
+
+func (m *Manager) current() (string, error) {
 	workspaces, err := m.List()
 	if err != nil {
 		return "", err
@@ -225,13 +267,13 @@ func (m *Manager) Current() (string, error) {
 		if !os.IsNotExist(err) {
 			return "", err
 		}
-		return m.selectDefault()
+		return "", errNoCurrent
 	}
 	defer f.Close()
 	wf := m.readWsp(f)
 
 	if !slices.Contains(workspaces, wf) {
-		return m.selectDefault()
+		return "", errNoCurrent
 	}
 
 	return wf, nil
@@ -248,7 +290,7 @@ func (m *Manager) selectDefault() (string, error) {
 		}
 		wsp = w
 	}
-	if err := m.Select(wsp); err != nil {
+	if err := m.selectWsp(wsp); err != nil {
 		return "", err
 	}
 	return wsp, nil
@@ -271,16 +313,25 @@ func (m *Manager) firstAvailable() (string, error) {
 
 // Select selects the existing workspace with "name".
 func (m *Manager) Select(name string) error {
+	return m.locked(func() error {
+		return m.selectWsp(name)
+	})
+}
+
+func (m *Manager) selectWsp(name string) error {
 	if !m.Exists(name) {
 		return newErrNoWorkspace(name)
 	}
 
-	f, err := os.Create(filepath.Join(m.dir, currentWspFile))
+	f, err := osext.CreateAtomic(filepath.Join(m.dir, currentWspFile))
 	if err != nil {
 		return &ErrWorkspace{Workspace: name, Message: "failed to create workspace file", Err: err}
 	}
-	defer f.Close()
-	return m.writeWsp(f, name)
+	defer f.Abort()
+	if err := m.writeWsp(f, name); err != nil {
+		return err
+	}
+	return f.Close()
 }
 
 // FileInfo returns the container file information for the workspace.
@@ -424,10 +475,12 @@ func (m *Manager) CreateAndSelect(ctx context.Context, prov auth.Provider) (stri
 	if wsp == "" {
 		return "", errors.New("workspace name is empty")
 	}
-	if err := m.saveProvider(wsp, prov); err != nil {
-		return "", err
-	}
-	if err := m.Select(wsp); err != nil {
+	if err := m.locked(func() error {
+		if err := m.saveProvider(wsp, prov); err != nil {
+			return err
+		}
+		return m.selectWsp(wsp)
+	}); err != nil {
 		return "", err
 	}
 	return wsp, nil
@@ -5,9 +5,12 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/rusq/slack"
 	"github.com/rusq/slackdump/v3/auth"
@@ -251,3 +254,33 @@ func TestManager_CreateAndSelect(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_concurrentAccess(t *testing.T) {
+	dir := t.TempDir()
+	prepareDir(t, dir)
+	m, err := NewManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workspaces := []string{"foo", "bar", "sdump"}
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, m.Select(workspaces[i%len(workspaces)]))
+			assert.NoError(t, m.CacheUsers("T1", testUsers))
+			// readers never see the partially written files.
+			cur, err := m.Current()
+			assert.NoError(t, err)
+			assert.Contains(t, workspaces, cur)
+			uu, err := m.LoadUsers("T1", time.Hour)
+			assert.NoError(t, err)
+			assert.Len(t, uu, len(testUsers))
+		}()
+	}
+	wg.Wait()
+	tmp, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	assert.NoError(t, err)
+	assert.Empty(t, tmp, "temporary files are not removed")
+}
@@ -0,0 +1,291 @@
+package cache
+
+// In this file: migration of the legacy files in the cache directory.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rusq/slackdump/v3/auth"
+)
+
+const (
+	// layoutVersion is the version of the cache directory layout.  It is
+	// written to the migratedFile once the directory is migrated.
+	layoutVersion = 3
+	migratedFile  = ".migrated"
+	legacyCache   = ".json" // extension of the unencrypted v1 caches.
+)
+
+// Migration actions.
+const (
+	// ActEncryptCreds encrypts the unencrypted credentials file.
+	ActEncryptCreds = "encrypt credentials"
+	// ActFixCurrent replaces the file name in the current workspace pointer
+	// with the workspace name.
+	ActFixCurrent = "fix current workspace"
+	// ActEncryptCache encrypts the unencrypted cache file.
+	ActEncryptCache = "encrypt cache"
+	// ActConvertCache converts the unencrypted JSON cache to the encrypted
+	// cache file.
+	ActConvertCache = "convert cache"
+)
+
+// Step is the change, that the migration makes to the file in the cache
+// directory.
+type Step struct {
+	Action string
+	// File is the name of the file in the cache directory.
+	File string
+	// Target is the new name of the file or the new value, if any.
+	Target string
+}
+
+func (s Step) String() string {
+	if s.Target == "" {
+		return s.Action + ": " + s.File
+	}
+	return s.Action + ": " + s.File + " -> " + s.Target
+}
+
+// Migrated returns true, if the cache directory was migrated to the current
+// layout.
+func (m *Manager) Migrated() bool {
+	data, err := os.ReadFile(filepath.Join(m.dir, migratedFile))
+	if err != nil {
+		return false
+	}
+	v, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return err == nil && v >= layoutVersion
+}
+
+// Plan returns the steps, that are necessary to migrate the files, created
+// by the previous versions of slackdump, to the current format, without
+// changing anything:
+//   - credentials files, that are not encrypted;
+//   - current workspace pointer, that contains the file name, instead of the
+//     workspace name;
+//   - user and channel caches, that are not encrypted, including the JSON
+//     caches of v1.
+func (m *Manager) Plan() ([]Step, error) {
+	var steps []Step
+
+	files, err := m.listFiles()
+	if err != nil && !errors.Is(err, ErrNoWorkspaces) {
+		return nil, err
+	}
+	for _, f := range files {
+		if _, ok := plainCreds(f); ok {
+			steps = append(steps, Step{Action: ActEncryptCreds, File: filepath.Base(f)})
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(m.dir, currentWspFile)); err == nil {
+		if cur := strings.TrimSpace(string(data)); filepath.Ext(cur) == wspExt {
+			steps = append(steps, Step{Action: ActFixCurrent, File: currentWspFile, Target: wspName(cur)})
+		}
+	}
+
+	for _, base := range []string{m.userFile, m.channelFile, m.chanInfoFile} {
+		ss, err := m.planCache(base)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, ss...)
+	}
+	return steps, nil
+}
+
+// planCache returns the steps to migrate the caches with the base name.
+func (m *Manager) planCache(base string) ([]Step, error) {
+	ne := filenameSplit(base)
+	var steps []Step
+	cached, err := filepath.Glob(filepath.Join(m.dir, ne[0]+"-*"+ne[1]))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range cached {
+		if _, ok := plainValues(f); ok {
+			steps = append(steps, Step{Action: ActEncryptCache, File: filepath.Base(f)})
+		}
+	}
+	legacy, err := filepath.Glob(filepath.Join(m.dir, ne[0]+"-*"+legacyCache))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range legacy {
+		suffix := strings.TrimPrefix(strings.TrimSuffix(filepath.Base(f), legacyCache), ne[0]+"-")
+		target := makeCacheFilename(m.dir, base, suffix)
+		if _, err := os.Stat(target); err == nil {
+			// the current cache is newer.
+			continue
+		}
+		if _, ok := plainValues(f); ok {
+			steps = append(steps, Step{Action: ActConvertCache, File: filepath.Base(f), Target: filepath.Base(target)})
+		}
+	}
+	return steps, nil
+}
+
+// Migrate migrates the legacy files in the cache directory to the current
+// format (see [Manager.Plan]), and marks the directory as migrated.  The
+// original files are copied to the backup directory next to the cache
+// directory first.  It returns the steps, that were applied, and the backup
+// directory, if any files were changed.
+func (m *Manager) Migrate() ([]Step, string, error) {
+	var (
+		steps  []Step
+		backup string
+	)
+	err := m.locked(func() error {
+		var err error
+		if steps, err = m.Plan(); err != nil {
+			return err
+		}
+		if len(steps) > 0 {
+			backup = filepath.Join(filepath.Dir(m.dir), filepath.Base(m.dir)+"-backup-"+time.Now().Format("20060102-150405"))
+			if err := m.backup(backup, steps); err != nil {
+				return fmt.Errorf("backup failed, nothing was changed: %w", err)
+			}
+		}
+		for _, s := range steps {
+			if err := m.apply(s); err != nil {
+				return fmt.Errorf("%s: %w", s, err)
+			}
+		}
+		return os.WriteFile(filepath.Join(m.dir, migratedFile), []byte(strconv.Itoa(layoutVersion)+"\n"), 0o600)
+	})
+	if err != nil {
+		return nil, backup, err
+	}
+	return steps, backup, nil
+}
+
+// backup copies the files of the steps to the directory dir.
+func (m *Manager) backup(dir string, steps []Step) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	for _, s := range steps {
+		if err := copyFile(filepath.Join(dir, s.File), filepath.Join(m.dir, s.File)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply applies the migration step.
+func (m *Manager) apply(s Step) error {
+	path := filepath.Join(m.dir, s.File)
+	switch s.Action {
+	case ActEncryptCreds:
+		prov, ok := plainCreds(path)
+		if !ok {
+			return errors.New("not a plain credentials file")
+		}
+		return saveCreds(filer, path, prov)
+	case ActFixCurrent:
+		if !m.Exists(s.Target) {
+			// the default workspace is selected on the next run.
+			return os.Remove(path)
+		}
+		return m.selectWsp(s.Target)
+	case ActEncryptCache:
+		return encryptCache(path, path)
+	case ActConvertCache:
+		if err := encryptCache(filepath.Join(m.dir, s.Target), path); err != nil {
+			return err
+		}
+		return os.Remove(path)
+	}
+	return fmt.Errorf("unknown action %q", s.Action)
+}
+
+// plainCreds returns the credentials from the file, if it is not encrypted.
+func plainCreds(filename string) (auth.Provider, bool) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, false
+	}
+	prov, err := auth.Load(bytes.NewReader(data))
+	if err != nil {
+		return nil, false
+	}
+	return prov, true
+}
+
+// plainValues returns the JSON values from the file, if it is not encrypted.
+// The file is either the JSON array, or the JSON values, one per line.
+func plainValues(filename string) ([]json.RawMessage, bool) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, false
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, false
+	}
+	var vv []json.RawMessage
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &vv); err != nil {
+			return nil, false
+		}
+		return vv, true
+	}
+	if data[0] != '{' {
+		return nil, false
+	}
+	vv, err = read[json.RawMessage](bytes.NewReader(data))
+	return vv, err == nil
+}
+
+// encryptCache writes the values from the unencrypted cache file src to the
+// encrypted cache file dst, keeping the modification time of src, so that
+// the cache expires as it would.
+func encryptCache(dst, src string) error {
+	vv, ok := plainValues(src)
+	if !ok {
+		return errors.New("not a plain cache file")
+	}
+	fi, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	f, err := createEncrypted(dst)
+	if err != nil {
+		return err
+	}
+	defer f.Abort()
+	if err := writeSlice(f, vv); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, fi.ModTime(), fi.ModTime())
+}
+
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/auth"
+)
+
+func TestManager_Migrate(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewManager(dir)
+	require.NoError(t, err)
+
+	// legacy layout: plain credentials, file name in the pointer, plain
+	// caches.
+	prov, err := auth.NewValueAuth("xoxc-123", "xoxd-456")
+	require.NoError(t, err)
+	f, err := os.Create(filepath.Join(dir, "acme.bin"))
+	require.NoError(t, err)
+	require.NoError(t, auth.Save(f, prov))
+	require.NoError(t, f.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, currentWspFile), []byte("acme.bin\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "users-T1.json"), []byte(`[{"id":"U1","name":"bob"}]`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "channels-T1.cache"), []byte(`{"id":"C1","name":"general"}`+"\n"), 0o600))
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "users-T1.json"), old, old))
+	// current layout is left as is.
+	require.NoError(t, m.saveProvider("other", prov))
+	require.NoError(t, m.CacheUsers("T2", []slack.User{{ID: "U2"}}))
+
+	want := []Step{
+		{Action: ActEncryptCreds, File: "acme.bin"},
+		{Action: ActFixCurrent, File: currentWspFile, Target: "acme"},
+		{Action: ActConvertCache, File: "users-T1.json", Target: "users-T1.cache"},
+		{Action: ActEncryptCache, File: "channels-T1.cache"},
+	}
+	plan, err := m.Plan()
+	require.NoError(t, err)
+	assert.Equal(t, want, plan)
+	assert.FileExists(t, filepath.Join(dir, "users-T1.json"), "plan must not change anything")
+	assert.False(t, m.Migrated())
+
+	steps, backup, err := m.Migrate()
+	require.NoError(t, err)
+	assert.Equal(t, want, steps)
+	assert.True(t, m.Migrated())
+
+	got, err := m.LoadProvider("acme")
+	require.NoError(t, err)
+	assert.Equal(t, "xoxc-123", got.SlackToken())
+	cur, err := m.Current()
+	require.NoError(t, err)
+	assert.Equal(t, "acme", cur)
+
+	uu, err := m.LoadUsers("T1", 2*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", uu[0].Name)
+	_, err = m.LoadUsers("T1", 30*time.Minute)
+	assert.Error(t, err, "converted cache must keep its age")
+	assert.NoFileExists(t, filepath.Join(dir, "users-T1.json"))
+	cc, err := m.LoadChannels("T1", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "general", cc[0].Name)
+
+	for _, s := range want {
+		assert.FileExists(t, filepath.Join(backup, s.File))
+	}
+	data, err := os.ReadFile(filepath.Join(backup, "acme.bin"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "xoxc-123", "backup must have the original")
+
+	plan, err = m.Plan()
+	require.NoError(t, err)
+	assert.Empty(t, plan)
+}
+
+func TestManager_Migrate_nothing(t *testing.T) {
+	m, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+	steps, backup, err := m.Migrate()
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+	assert.Empty(t, backup, "no backup if nothing is changed")
+	assert.True(t, m.Migrated())
+}
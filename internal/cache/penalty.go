@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rusq/slackdump/v3/internal/network"
+	"github.com/rusq/slackdump/v3/internal/osext"
+)
+
+// penaltyFile is the base name of the rate limit penalties file, the
+// workspace name is appended to it as a suffix, i.e.
+// "ratelimit-myworkspace.json".
+const penaltyFile = "ratelimit.json"
+
+// LoadPenalties loads the rate limit penalties recorded for the workspace by
+// the previous run.  If there are none, it returns an empty PenaltyBox.
+func (m *Manager) LoadPenalties(workspace string) (*network.PenaltyBox, error) {
+	filename := makeCacheFilename(m.dir, penaltyFile, workspace)
+	f, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return network.NewPenaltyBox(), nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	pb, err := network.LoadPenaltyBox(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load penalties from %s: %w", filename, err)
+	}
+	return pb, nil
+}
+
+// SavePenalties saves the rate limit penalties of the workspace, replacing
+// the file atomically.
+func (m *Manager) SavePenalties(workspace string, pb *network.PenaltyBox) error {
+	filename := makeCacheFilename(m.dir, penaltyFile, workspace)
+	return m.locked(func() error {
+		f, err := osext.CreateAtomic(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Abort()
+		if err := pb.Save(f); err != nil {
+			return err
+		}
+		return f.Close()
+	})
+}
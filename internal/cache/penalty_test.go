@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rusq/slackdump/v3/internal/network"
+)
+
+func TestManager_Penalties(t *testing.T) {
+	m, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb, err := m.LoadPenalties("one")
+	if err != nil {
+		t.Fatalf("LoadPenalties() on empty directory: %s", err)
+	}
+	if n := len(pb.Penalties()); n != 0 {
+		t.Errorf("got %d penalties, want 0", n)
+	}
+
+	pb = network.NewPenaltyBox()
+	pb.Record("conversations.history", time.Minute)
+	if err := m.SavePenalties("one", pb); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := m.LoadPenalties("one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := got.Remaining("conversations.history"); d <= 0 {
+		t.Errorf("Remaining() = %s, want > 0", d)
+	}
+	// penalties of other workspaces are separate
+	other, err := m.LoadPenalties("two")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := other.Remaining("conversations.history"); d != 0 {
+		t.Errorf("Remaining() for other workspace = %s, want 0", d)
+	}
+}
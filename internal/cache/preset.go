@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rusq/encio"
+)
+
+// presetFile is the base name of the presets file, the command name is
+// appended to it as a suffix, i.e. "presets-export.cache".
+const presetFile = "presets.cache"
+
+var ErrNoPreset = errors.New("no such preset")
+
+// Preset is the named set of answers to the command wizard, that can be
+// rerun or edited on the next launch.
+type Preset struct {
+	// Name is the name of the preset, unique within the command.
+	Name string `json:"name"`
+	// Entities is the list of the channels to run the command for, empty
+	// for all.
+	Entities []string `json:"entities,omitempty"`
+	// Oldest and Latest is the time range of the messages.
+	Oldest time.Time `json:"oldest,omitempty"`
+	Latest time.Time `json:"latest,omitempty"`
+	// Type is the command specific output type, i.e. the file storage type
+	// for the export.
+	Type string `json:"type,omitempty"`
+	// Output is the destination of the command output.
+	Output string `json:"output,omitempty"`
+	// Saved is the time when the preset was saved.
+	Saved time.Time `json:"saved"`
+}
+
+// Presets returns the presets saved for the command, sorted by name.  If
+// there are no presets, it returns an empty slice and no error.
+func (m *Manager) Presets(command string) ([]Preset, error) {
+	pp, err := m.loadPresets(command)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(pp, func(a, b Preset) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return pp, nil
+}
+
+// Preset returns the preset with the name for the command.  If it does not
+// exist, it returns ErrNoPreset.
+func (m *Manager) Preset(command, name string) (Preset, error) {
+	pp, err := m.loadPresets(command)
+	if err != nil {
+		return Preset{}, err
+	}
+	idx := slices.IndexFunc(pp, func(p Preset) bool { return p.Name == name })
+	if idx == -1 {
+		return Preset{}, fmt.Errorf("%s: %w", name, ErrNoPreset)
+	}
+	return pp[idx], nil
+}
+
+// SavePreset saves the preset for the command, replacing the existing
+// preset with the same name.
+func (m *Manager) SavePreset(command string, p Preset) error {
+	if p.Name == "" {
+		return errors.New("preset name is required")
+	}
+	if p.Saved.IsZero() {
+		p.Saved = time.Now()
+	}
+	return m.locked(func() error {
+		pp, err := m.loadPresets(command)
+		if err != nil {
+			return err
+		}
+		pp = slices.DeleteFunc(pp, func(e Preset) bool { return e.Name == p.Name })
+		return save(m.dir, presetFile, command, append(pp, p))
+	})
+}
+
+// DeletePreset deletes the preset with the name for the command.  If it does
+// not exist, it returns ErrNoPreset.
+func (m *Manager) DeletePreset(command, name string) error {
+	return m.locked(func() error {
+		pp, err := m.loadPresets(command)
+		if err != nil {
+			return err
+		}
+		n := len(pp)
+		pp = slices.DeleteFunc(pp, func(e Preset) bool { return e.Name == name })
+		if len(pp) == n {
+			return fmt.Errorf("%s: %w", name, ErrNoPreset)
+		}
+		return save(m.dir, presetFile, command, pp)
+	})
+}
+
+// loadPresets loads all presets for the command.  Presets do not expire,
+// therefore it does not use load.
+func (m *Manager) loadPresets(command string) ([]Preset, error) {
+	filename := makeCacheFilename(m.dir, presetFile, command)
+	f, err := encio.Open(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Preset{}, nil
+		}
+		return nil, fmt.Errorf("failed to open %s: %w", filename, err)
+	}
+	defer f.Close()
+	pp, err := read[Preset](f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode presets from %s: %w", filename, err)
+	}
+	return pp, nil
+}
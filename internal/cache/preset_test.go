@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_Presets(t *testing.T) {
+	m, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pp, err := m.Presets("export")
+	if err != nil {
+		t.Fatalf("Presets() on empty directory: %s", err)
+	}
+	assert.Empty(t, pp)
+
+	saved := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	weekly := Preset{Name: "weekly", Entities: []string{"C1", "C2"}, Type: "standard", Output: "weekly.zip", Saved: saved}
+	if err := m.SavePreset("export", weekly); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SavePreset("export", Preset{Name: "all", Output: "all.zip", Saved: saved}); err != nil {
+		t.Fatal(err)
+	}
+	// replaces the existing preset
+	weekly.Output = "weekly2.zip"
+	if err := m.SavePreset("export", weekly); err != nil {
+		t.Fatal(err)
+	}
+	// presets of other commands are separate
+	if err := m.SavePreset("dump", Preset{Name: "other"}); err != nil {
+		t.Fatal(err)
+	}
+
+	pp, err = m.Presets("export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Preset{{Name: "all", Output: "all.zip", Saved: saved}, weekly}, pp)
+
+	got, err := m.Preset("export", "weekly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, weekly, got)
+
+	if err := m.DeletePreset("export", "all"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.DeletePreset("export", "all"); !errors.Is(err, ErrNoPreset) {
+		t.Errorf("DeletePreset() error = %v, want %v", err, ErrNoPreset)
+	}
+	if _, err := m.Preset("export", "all"); !errors.Is(err, ErrNoPreset) {
+		t.Errorf("Preset() error = %v, want %v", err, ErrNoPreset)
+	}
+	pp, err = m.Presets("export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Preset{weekly}, pp)
+
+	if err := m.SavePreset("export", Preset{}); err == nil {
+		t.Error("SavePreset() with empty name: expected error")
+	}
+}
@@ -0,0 +1,172 @@
+package cache
+
+// In this file: export and import of the workspace credentials, to move them
+// between machines.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+
+	"github.com/rusq/slackdump/v3/auth"
+)
+
+const (
+	bundleVersion   = 1
+	bundleArmorType = "PGP MESSAGE"
+	bundleComment   = "slackdump workspace credentials"
+)
+
+var (
+	// ErrPassphrase is returned by [Manager.ImportWorkspace] if the bundle
+	// can't be decrypted with the passphrase.
+	ErrPassphrase = errors.New("wrong passphrase")
+	// ErrNoPassphrase is returned if the passphrase is empty.
+	ErrNoPassphrase = errors.New("passphrase is required")
+	// ErrInvalidBundle is returned by [Manager.ImportWorkspace] if the
+	// decrypted data is not a credentials bundle.
+	ErrInvalidBundle = errors.New("invalid credentials bundle")
+)
+
+// bundle is the portable credentials bundle.  Unlike the workspace files,
+// that are encrypted with the machine ID, the bundle is encrypted with the
+// passphrase, so that it can be imported on another machine.
+type bundle struct {
+	Version  int             `json:"version"`
+	Name     string          `json:"name"`
+	Provider json.RawMessage `json:"provider"`
+}
+
+// ExportWorkspace writes the credentials of the workspace name to w, as the
+// ASCII-armored OpenPGP message, encrypted with the passphrase (same as "gpg
+// -c -a").  The bundle can be imported with [Manager.ImportWorkspace].
+func (m *Manager) ExportWorkspace(name string, w io.Writer, passphrase []byte) error {
+	if len(passphrase) == 0 {
+		return ErrNoPassphrase
+	}
+	if err := m.ExistsErr(name); err != nil {
+		return err
+	}
+	prov, err := m.LoadProvider(name)
+	if err != nil {
+		return &ErrWorkspace{Workspace: name, Message: "failed to load credentials", Err: err}
+	}
+	var buf bytes.Buffer
+	if err := auth.Save(&buf, prov); err != nil {
+		return &ErrWorkspace{Workspace: name, Message: "invalid credentials", Err: err}
+	}
+	data, err := json.Marshal(bundle{Version: bundleVersion, Name: name, Provider: buf.Bytes()})
+	if err != nil {
+		return err
+	}
+
+	aw, err := armor.Encode(w, bundleArmorType, map[string]string{"Comment": bundleComment})
+	if err != nil {
+		return err
+	}
+	cw, err := openpgp.SymmetricallyEncrypt(aw, passphrase, &openpgp.FileHints{IsBinary: true}, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := cw.Write(data); err != nil {
+		return err
+	}
+	if err := cw.Close(); err != nil {
+		return err
+	}
+	return aw.Close()
+}
+
+// ImportWorkspace reads the credentials bundle, created by
+// [Manager.ExportWorkspace], from r, decrypts it with the passphrase, and
+// saves the credentials under the workspace name from the bundle, then
+// selects it.  It returns the name of the imported workspace.  The existing
+// workspace is not overwritten.
+//
+// The credentials are not tested, so that they can be imported on the
+// machine without the access to Slack.
+func (m *Manager) ImportWorkspace(r io.Reader, passphrase []byte) (string, error) {
+	if len(passphrase) == 0 {
+		return "", ErrNoPassphrase
+	}
+	b, err := readBundle(r, passphrase)
+	if err != nil {
+		return "", err
+	}
+	if !validName(b.Name) {
+		return "", fmt.Errorf("%w: invalid workspace name %q", ErrInvalidBundle, b.Name)
+	}
+	prov, err := auth.Load(bytes.NewReader(b.Provider))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidBundle, err)
+	}
+	if err := m.locked(func() error {
+		if m.Exists(b.Name) {
+			return &ErrWorkspace{Workspace: b.Name, Message: "already exists"}
+		}
+		if err := m.saveProvider(b.Name, prov); err != nil {
+			return err
+		}
+		return m.selectWsp(b.Name)
+	}); err != nil {
+		return "", err
+	}
+	return b.Name, nil
+}
+
+// readBundle decrypts and decodes the bundle from r.
+func readBundle(r io.Reader, passphrase []byte) (*bundle, error) {
+	block, err := armor.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBundle, err)
+	}
+	if block.Type != bundleArmorType {
+		return nil, fmt.Errorf("%w: unexpected block type %q", ErrInvalidBundle, block.Type)
+	}
+
+	tried := false
+	prompt := func(keys []openpgp.Key, symmetric bool) ([]byte, error) {
+		if !symmetric {
+			return nil, ErrInvalidBundle
+		}
+		if tried {
+			// openpgp calls the prompt again, if the passphrase is wrong.
+			return nil, ErrPassphrase
+		}
+		tried = true
+		return passphrase, nil
+	}
+	md, err := openpgp.ReadMessage(block.Body, openpgp.EntityList{}, prompt, nil)
+	if err != nil {
+		if errors.Is(err, pgperrors.ErrKeyIncorrect) {
+			return nil, ErrInvalidBundle
+		}
+		return nil, err
+	}
+	var b bundle
+	if err := json.NewDecoder(md.UnverifiedBody).Decode(&b); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBundle, err)
+	}
+	// reading to EOF verifies the integrity of the message.
+	if _, err := io.Copy(io.Discard, md.UnverifiedBody); err != nil {
+		return nil, err
+	}
+	if b.Version != bundleVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidBundle, b.Version)
+	}
+	return &b, nil
+}
+
+// validName returns true if name can be used as the workspace file name.
+func validName(name string) bool {
+	return name != "" && name != "." && name != ".." &&
+		filepath.Base(name) == name && !strings.ContainsAny(name, `/\:`)
+}
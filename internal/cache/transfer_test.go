@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/auth"
+)
+
+func TestManager_ExportImportWorkspace(t *testing.T) {
+	pass := []byte("correct horse")
+	prov, err := auth.NewValueAuth("xoxc-123", "xoxd-456")
+	require.NoError(t, err)
+
+	src, err := NewManager(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, src.saveProvider("acme", prov))
+
+	var buf bytes.Buffer
+	require.NoError(t, src.ExportWorkspace("acme", &buf, pass))
+	bundle := buf.String()
+	assert.True(t, strings.HasPrefix(bundle, "-----BEGIN PGP MESSAGE-----"), "bundle must be armored")
+	assert.NotContains(t, bundle, "xoxc-123", "bundle must be encrypted")
+
+	t.Run("round trip", func(t *testing.T) {
+		dst, err := NewManager(t.TempDir())
+		require.NoError(t, err)
+		name, err := dst.ImportWorkspace(strings.NewReader(bundle), pass)
+		require.NoError(t, err)
+		assert.Equal(t, "acme", name)
+
+		got, err := dst.LoadProvider("acme")
+		require.NoError(t, err)
+		assert.Equal(t, "xoxc-123", got.SlackToken())
+		assert.Equal(t, prov.Cookies(), got.Cookies())
+		cur, err := dst.Current()
+		require.NoError(t, err)
+		assert.Equal(t, "acme", cur, "imported workspace must be selected")
+
+		_, err = dst.ImportWorkspace(strings.NewReader(bundle), pass)
+		assert.ErrorContains(t, err, "already exists")
+	})
+	t.Run("wrong passphrase", func(t *testing.T) {
+		dst, err := NewManager(t.TempDir())
+		require.NoError(t, err)
+		_, err = dst.ImportWorkspace(strings.NewReader(bundle), []byte("wrong"))
+		assert.ErrorIs(t, err, ErrPassphrase)
+		assert.False(t, dst.Exists("acme"))
+	})
+	t.Run("not a bundle", func(t *testing.T) {
+		dst, err := NewManager(t.TempDir())
+		require.NoError(t, err)
+		_, err = dst.ImportWorkspace(strings.NewReader("SLACK_TOKEN=xoxc-123\n"), pass)
+		assert.ErrorIs(t, err, ErrInvalidBundle)
+	})
+	t.Run("empty passphrase", func(t *testing.T) {
+		err := src.ExportWorkspace("acme", &bytes.Buffer{}, nil)
+		assert.ErrorIs(t, err, ErrNoPassphrase)
+	})
+	t.Run("no such workspace", func(t *testing.T) {
+		err := src.ExportWorkspace("nope", &bytes.Buffer{}, pass)
+		var ew *ErrWorkspace
+		assert.True(t, errors.As(err, &ew))
+	})
+}
+
+func Test_validName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"acme":      true,
+		"default":   true,
+		"":          false,
+		"..":        false,
+		"../acme":   false,
+		`..\acme`:   false,
+		"/etc/acme": false,
+	} {
+		assert.Equal(t, want, validName(name), name)
+	}
+}
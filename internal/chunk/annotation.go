@@ -0,0 +1,84 @@
+package chunk
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// Annotation is a free-form note of the operator, that is recorded alongside
+// the Slack data, i.e. the case reference, or the name of the person who
+// initiated the recording.  It does not originate from the API.
+type Annotation struct {
+	// Time is the time when the annotation was made.
+	Time time.Time `json:"t"`
+	// Author is the name of the operator, who made the annotation.  It may
+	// be empty.
+	Author string `json:"a,omitempty"`
+	// Text is the annotation text.
+	Text string `json:"x"`
+}
+
+func (a Annotation) String() string {
+	var sb strings.Builder
+	sb.WriteString(a.Time.UTC().Format(time.RFC3339))
+	if a.Author != "" {
+		sb.WriteString(" " + a.Author)
+	}
+	sb.WriteString(": " + a.Text)
+	return sb.String()
+}
+
+// ErrEmptyAnnotation is returned by Annotate, if the annotation text is empty.
+var ErrEmptyAnnotation = errors.New("empty annotation")
+
+// Annotate records the annotation.  If the annotation time is not set, it is
+// set to the current time.
+func (rec *Recorder) Annotate(ctx context.Context, a Annotation) error {
+	if strings.TrimSpace(a.Text) == "" {
+		return ErrEmptyAnnotation
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if a.Time.IsZero() {
+		a.Time = time.Now()
+	}
+	a.Time = a.Time.UTC()
+	chunk := Chunk{
+		Type:       CAnnotation,
+		Timestamp:  time.Now().UnixNano(),
+		Annotation: &a,
+	}
+	if err := rec.encode(chunk); err != nil {
+		return err
+	}
+	rec.state.AddAnnotation(a.Time, a.Author, a.Text)
+	return nil
+}
+
+// Annotations returns all annotations recorded in the file, in the order
+// they were recorded.  It returns ErrNotFound, if there are no annotations.
+func (f *File) Annotations() ([]Annotation, error) {
+	return allForID(f, annotChunkID, func(c *Chunk) []Annotation {
+		if c.Annotation == nil {
+			return nil
+		}
+		return []Annotation{*c.Annotation}
+	})
+}
+
+// Annotations returns the annotations from the workspace file of the
+// directory.  It returns ErrNotFound, if there are no annotations.
+func (d *Directory) Annotations() ([]Annotation, error) {
+	f, err := d.Open(FWorkspace)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return f.Annotations()
+}
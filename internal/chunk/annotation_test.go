@@ -0,0 +1,84 @@
+package chunk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder_Annotate(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf)
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	if err := rec.Annotate(ctx, Annotation{Time: at, Author: "jdoe", Text: "legal hold case #123"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Messages(ctx, TestChannelID, 0, true, []slack.Message{testMsg("U1", "1700000001.000000")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Annotate(ctx, Annotation{Text: "second note"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Annotate(ctx, Annotation{Text: "  "}); !errors.Is(err, ErrEmptyAnnotation) {
+		t.Errorf("Annotate() error = %v, want %v", err, ErrEmptyAnnotation)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := FromReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.Annotations()
+	if err != nil {
+		t.Fatalf("Annotations() error = %v", err)
+	}
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, Annotation{Time: at, Author: "jdoe", Text: "legal hold case #123"}, got[0])
+		assert.Equal(t, "second note", got[1].Text)
+		assert.False(t, got[1].Time.IsZero())
+	}
+
+	st, err := f.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, st.Annotations, 2)
+	recSt, _ := rec.State()
+	assert.Equal(t, st.Annotations, recSt.Annotations)
+
+	// the chain is not broken by the annotations.
+	r, err := Audit(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, r.OK(), "breaks: %v", r.Breaks)
+}
+
+func TestFile_Annotations_none(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf)
+	if err := rec.Messages(context.Background(), TestChannelID, 0, true, []slack.Message{testMsg("U1", "1700000001.000000")}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := FromReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Annotations(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Annotations() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestAnnotation_String(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2024-05-01T10:00:00Z jdoe: case #123", Annotation{Time: at, Author: "jdoe", Text: "case #123"}.String())
+	assert.Equal(t, "2024-05-01T10:00:00Z: case #123", Annotation{Time: at, Text: "case #123"}.String())
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/rusq/slack"
 	"github.com/rusq/slackdump/v3/internal/fasttime"
+	"github.com/rusq/slackdump/v3/types"
 )
 
 // ChunkType is the type of chunk that was recorded..
@@ -25,6 +26,12 @@ const (
 	CBookmarks
 	CSearchMessages
 	CSearchFiles
+	CTrailer
+	CAnnotation
+	CMessageEdit
+	CMessageDeleted
+	CPins
+	CUserGroups
 )
 
 var ErrUnsupChunkType = fmt.Errorf("unsupported chunk type")
@@ -41,6 +48,12 @@ type Chunk struct {
 	ChannelID string `json:"id,omitempty"`
 	// Count is the count of elements in the chunk, i.e. messages or files.
 	Count int `json:"n,omitempty"`
+	// Seq is the sequence number of the chunk within the recording, starting
+	// with 1.  It is set by the Recorder.
+	Seq int64 `json:"q,omitempty"`
+	// Prev is the hex-encoded SHA-256 hash of the previous chunk in the
+	// recording.  It is empty for the first chunk.
+	Prev string `json:"h,omitempty"`
 
 	// ThreadTS is populated if the chunk contains thread related data.  It
 	// is Slack's thread_ts.
@@ -80,16 +93,30 @@ type Chunk struct {
 	// WorkspaceInfo contains the workspace information as returned by the
 	// API.  Populated by WorkspaceInfo.
 	WorkspaceInfo *slack.AuthTestResponse `json:"w,omitempty"`
+	// Workspace contains the extended workspace information, that was
+	// available to the user.  Populated by WorkspaceDetails.
+	Workspace *types.Workspace `json:"wd,omitempty"`
 	// StarredItems contains the starred items.
 	StarredItems []slack.StarredItem `json:"st,omitempty"` // Populated by StarredItems
 	// Bookmarks contains the bookmarks.
 	Bookmarks []slack.Bookmark `json:"b,omitempty"` // Populated by Bookmarks
+	// Pins contains the pinned items of the channel.
+	Pins []slack.Item `json:"pn,omitempty"` // Populated by Pins
+	// UserGroups contains the user groups of the workspace with their
+	// members.
+	UserGroups []slack.UserGroup `json:"ug,omitempty"` // Populated by UserGroups
 	// SearchQuery contains the search query.
 	SearchQuery string `json:"sq,omitempty"` // Populated by SearchMessages and SearchFiles.
 	// SearchMessages contains the search results.
 	SearchMessages []slack.SearchMessage `json:"sm,omitempty"` // Populated by SearchMessages
 	// SearchFiles contains the search results.
 	SearchFiles []slack.File `json:"sf,omitempty"` // Populated by SearchFiles
+	// Trailer contains the aggregated statistics of the chunk file, it is
+	// written by the Recorder on Close.
+	Trailer *Trailer `json:"tr,omitempty"` // Populated by Recorder.Close
+	// Annotation contains the operator note, that is not a part of the
+	// Slack data.
+	Annotation *Annotation `json:"an,omitempty"` // Populated by Annotate
 }
 
 // GroupID is a unique ID for a chunk group.  It is used to group chunks of
@@ -104,6 +131,9 @@ const (
 	wspInfoChunkID  GroupID = "iw"   // info workspace
 	srchMsgChunkID  GroupID = "sm"   // search messages results
 	srchFileChunkID GroupID = "sf"   // search file results
+	trailerChunkID  GroupID = "itr"  // info trailer
+	annotChunkID    GroupID = "ian"  // info annotation
+	userGroupID     GroupID = "lug"  // list user groups
 )
 
 const (
@@ -111,7 +141,10 @@ const (
 	filePrefix      = "f"
 	chanInfoPrefix  = "ic"
 	bookmarkPrefix  = "lb"
+	pinPrefix       = "lp"
 	chanUsersPrefix = "lcu"
+	editPrefix      = "he" // history edits
+	deletedPrefix   = "hd" // history deletions
 )
 
 // Chunk ID categories
@@ -120,6 +153,7 @@ const (
 	catInfo   = 'i'
 	catList   = 'l'
 	catSearch = 's'
+	catHist   = 'h'
 )
 
 // ID returns a Group ID for the chunk.
@@ -149,6 +183,18 @@ func (c *Chunk) ID() GroupID {
 		return srchMsgChunkID
 	case CSearchFiles:
 		return srchFileChunkID
+	case CTrailer:
+		return trailerChunkID // static
+	case CAnnotation:
+		return annotChunkID // static
+	case CMessageEdit:
+		return id(editPrefix, c.ChannelID)
+	case CMessageDeleted:
+		return id(deletedPrefix, c.ChannelID)
+	case CPins:
+		return id(pinPrefix, c.ChannelID)
+	case CUserGroups:
+		return userGroupID // static
 	}
 	return GroupID(fmt.Sprintf("<unknown:%s>", c.Type))
 }
@@ -211,3 +257,8 @@ func (g GroupID) isList() bool {
 func (g GroupID) isSearch() bool {
 	return g[0] == catSearch
 }
+
+// isHistory returns true, if the chunk is a message history chunk.
+func (g GroupID) isHistory() bool {
+	return g[0] == catHist
+}
@@ -47,6 +47,8 @@ func (s *DirServer) dirRouter() *http.ServeMux {
 
 	mux.Handle("/api/conversations.list", s.chunkfileWrapper(chunk.FChannels, handleConversationsList))
 	mux.Handle("/api/users.list", s.chunkfileWrapper(chunk.FUsers, handleUsersList))
+	mux.Handle("/api/users.info", s.chunkfileWrapper(chunk.FUsers, handleUsersInfo))
+	mux.Handle("/api/files.list", s.chunkWrapper(handleFilesList))
 	mux.Handle("/api/auth.test", s.chunkfileWrapper(chunk.FWorkspace, handleAuthTest))
 
 	return mux
@@ -0,0 +1,166 @@
+package chunktest
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithRateLimit makes the server respond to every n-th API request with HTTP
+// 429 Too Many Requests and the Retry-After header set to retryAfter,
+// rounded up to the whole seconds, as Slack does.
+func WithRateLimit(n int, retryAfter time.Duration) Option {
+	return func(o *serverOptions) {
+		o.fi.rateLimitN = n
+		o.fi.retryAfter = retryAfter
+	}
+}
+
+// WithServerErrors makes the server respond to every n-th API request with
+// the HTTP status code, which should be one of 5xx, to emulate intermittent
+// server errors.
+func WithServerErrors(n int, code int) Option {
+	return func(o *serverOptions) {
+		o.fi.serverErrN = n
+		o.fi.serverErrCode = code
+	}
+}
+
+// WithTruncatedBodies makes the server drop the connection halfway through
+// the response body on every n-th API request.
+func WithTruncatedBodies(n int) Option {
+	return func(o *serverOptions) {
+		o.fi.truncateN = n
+	}
+}
+
+// WithFaultEndpoints limits the fault injection to the API endpoints, i.e.
+// "conversations.history".  By default, faults are injected into all
+// endpoints.
+func WithFaultEndpoints(endpoints ...string) Option {
+	return func(o *serverOptions) {
+		o.fi.endpoints = make(map[string]bool, len(endpoints))
+		for _, ep := range endpoints {
+			o.fi.endpoints[ep] = true
+		}
+	}
+}
+
+// FaultStats contains the number of requests that were served with the
+// injected faults.
+type FaultStats struct {
+	// Requests is the number of requests to the endpoints subject to the
+	// fault injection.
+	Requests     int
+	RateLimited  int
+	ServerErrors int
+	Truncated    int
+}
+
+// faultInjector is the middleware that injects faults into the API
+// responses.  Faults are injected deterministically, based on the request
+// counter, so that the tests are reproducible.  If several faults fall on
+// the same request, the rate limit takes precedence over the server error,
+// and the server error takes precedence over the truncation.
+type faultInjector struct {
+	rateLimitN    int
+	retryAfter    time.Duration
+	serverErrN    int
+	serverErrCode int
+	truncateN     int
+	endpoints     map[string]bool
+
+	mu    sync.Mutex
+	stats FaultStats
+}
+
+func newFaultInjector() *faultInjector {
+	return &faultInjector{serverErrCode: http.StatusInternalServerError}
+}
+
+func (fi *faultInjector) enabled() bool {
+	return fi.rateLimitN > 0 || fi.serverErrN > 0 || fi.truncateN > 0
+}
+
+// every returns true if the n is set and num is a multiple of n.
+func every(n, num int) bool {
+	return n > 0 && num%n == 0
+}
+
+type fault int
+
+const (
+	fNone fault = iota
+	fRateLimit
+	fServerError
+	fTruncate
+)
+
+// next accounts for the request to the endpoint, and returns the fault to
+// inject.
+func (fi *faultInjector) next(endpoint string) fault {
+	if fi.endpoints != nil && !fi.endpoints[endpoint] {
+		return fNone
+	}
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fi.stats.Requests++
+	num := fi.stats.Requests
+	switch {
+	case every(fi.rateLimitN, num):
+		fi.stats.RateLimited++
+		return fRateLimit
+	case every(fi.serverErrN, num):
+		fi.stats.ServerErrors++
+		return fServerError
+	case every(fi.truncateN, num):
+		fi.stats.Truncated++
+		return fTruncate
+	}
+	return fNone
+}
+
+// Stats returns the fault statistics.
+func (fi *faultInjector) Stats() FaultStats {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	return fi.stats
+}
+
+// truncatedBody is the beginning of the response that is sent before the
+// connection is dropped.
+const truncatedBody = `{"ok":true,"has_more":true,"messages":[{"type":"message","text":"`
+
+// wrap returns the handler that injects the faults into the responses of h.
+func (fi *faultInjector) wrap(h http.Handler) http.Handler {
+	if !fi.enabled() {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint := strings.TrimPrefix(r.URL.Path, "/api/")
+		switch fi.next(endpoint) {
+		case fRateLimit:
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(fi.retryAfter.Seconds()))))
+			http.Error(w, `{"ok":false,"error":"ratelimited"}`, http.StatusTooManyRequests)
+		case fServerError:
+			http.Error(w, http.StatusText(fi.serverErrCode), fi.serverErrCode)
+		case fTruncate:
+			// the handler is not called, so that the replay does not advance
+			// and the retried request gets the same data.
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Length", strconv.Itoa(2*len(truncatedBody)))
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(truncatedBody))
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+			// drops the connection without logging.
+			panic(http.ErrAbortHandler)
+		default:
+			h.ServeHTTP(w, r)
+		}
+	})
+}
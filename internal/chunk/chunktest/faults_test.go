@@ -0,0 +1,83 @@
+package chunktest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/fixtures"
+)
+
+func TestServer_faults(t *testing.T) {
+	get := func(t *testing.T, srv *Server, endpoint string) (*http.Response, []byte, error) {
+		t.Helper()
+		resp, err := http.Get(srv.URL() + endpoint)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return resp, body, err
+	}
+	t.Run("rate limit", func(t *testing.T) {
+		srv := NewServer(fixtures.ChunkFileJSONL(), "U123", WithRateLimit(2, 1500*time.Millisecond))
+		defer srv.Close()
+		resp, _, err := get(t, srv, "auth.test")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp, _, err = get(t, srv, "auth.test")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+
+		// the slack client reports the rate limit error
+		sd := slack.New("test", slack.OptionAPIURL(srv.URL()))
+		_, err = sd.AuthTest() // 3rd
+		require.NoError(t, err)
+		_, err = sd.AuthTest() // 4th
+		var rle *slack.RateLimitedError
+		require.True(t, errors.As(err, &rle), "got %v", err)
+		assert.Equal(t, 2*time.Second, rle.RetryAfter)
+		assert.Equal(t, FaultStats{Requests: 4, RateLimited: 2}, srv.FaultStats())
+	})
+	t.Run("server errors", func(t *testing.T) {
+		srv := NewServer(fixtures.ChunkFileJSONL(), "U123", WithServerErrors(1, http.StatusServiceUnavailable))
+		defer srv.Close()
+		resp, _, err := get(t, srv, "auth.test")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, FaultStats{Requests: 1, ServerErrors: 1}, srv.FaultStats())
+	})
+	t.Run("truncated body", func(t *testing.T) {
+		srv := NewServer(fixtures.ChunkFileJSONL(), "U123", WithTruncatedBodies(1))
+		defer srv.Close()
+		resp, body, err := get(t, srv, "auth.test")
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, truncatedBody, string(body))
+	})
+	t.Run("endpoints", func(t *testing.T) {
+		srv := NewServer(fixtures.ChunkFileJSONL(), "U123", WithServerErrors(1, http.StatusBadGateway), WithFaultEndpoints("conversations.history"))
+		defer srv.Close()
+		resp, _, err := get(t, srv, "auth.test")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp, _, err = get(t, srv, "conversations.history?channel="+fixtures.ChunkFileChannelID)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+		assert.Equal(t, FaultStats{Requests: 1, ServerErrors: 1}, srv.FaultStats())
+	})
+	t.Run("precedence", func(t *testing.T) {
+		srv := NewServer(fixtures.ChunkFileJSONL(), "U123", WithRateLimit(2, 0), WithServerErrors(2, http.StatusInternalServerError))
+		defer srv.Close()
+		for range 4 {
+			_, _, err := get(t, srv, "auth.test")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, FaultStats{Requests: 4, RateLimited: 2}, srv.FaultStats())
+	})
+}
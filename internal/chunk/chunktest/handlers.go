@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"runtime/trace"
 	"strconv"
+	"strings"
 
 	"github.com/rusq/slack"
 	"github.com/rusq/slackdump/v3/internal/chunk"
@@ -23,6 +24,11 @@ func router(p *chunk.Player, userID string) *http.ServeMux {
 	mux.HandleFunc("/api/conversations.replies", handleConversationsReplies(p))
 	mux.HandleFunc("/api/conversations.list", handleConversationsList(p))
 	mux.HandleFunc("/api/users.list", handleUsersList(p))
+	mux.HandleFunc("/api/users.info", handleUsersInfo(p))
+	mux.HandleFunc("/api/files.list", handleFilesList(p))
+	mux.HandleFunc("/api/files.info", handleFilesInfo(p))
+	mux.HandleFunc("/api/bookmarks.list", handleBookmarksList(p))
+	mux.HandleFunc("/api/stars.list", handleStarsList(p))
 	return mux
 }
 
@@ -356,3 +362,209 @@ func handleConversationsMembers(p *chunk.Player) http.HandlerFunc {
 		}
 	}
 }
+
+type filesResponseFull struct {
+	Files    []slack.File `json:"files,omitempty"`
+	File     *slack.File  `json:"file,omitempty"`
+	Comments []any        `json:"comments"`
+	Paging   slack.Paging `json:"paging"`
+	slack.SlackResponse
+	Metadata slack.ResponseMetadata `json:"response_metadata"`
+}
+
+// onePage returns the paging for the single page of n items.
+func onePage(n int) slack.Paging {
+	return slack.Paging{Count: n, Total: n, Page: 1, Pages: 1}
+}
+
+func handleFilesList(p *chunk.Player) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, task := trace.NewTask(r.Context(), "files.list")
+		defer task.End()
+
+		channel := r.FormValue("channel")
+		user := r.FormValue("user")
+		lg.Printf("files.list: channel: %q, user: %q", channel, user)
+
+		resp := filesResponseFull{
+			Files: []slack.File{},
+			SlackResponse: slack.SlackResponse{
+				Ok: true,
+			},
+		}
+		ff, err := p.Files(channel)
+		if err != nil && !errors.Is(err, chunk.ErrNotFound) {
+			lg.Printf("error processing files.list: %s", err)
+			resp.Ok = false
+			resp.Error = err.Error()
+		}
+		for _, f := range ff {
+			if user == "" || f.User == user {
+				resp.Files = append(resp.Files, f)
+			}
+		}
+		resp.Paging = onePage(len(resp.Files))
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			lg.Printf("error encoding files.list response: %s", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func handleFilesInfo(p *chunk.Player) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, task := trace.NewTask(r.Context(), "files.info")
+		defer task.End()
+
+		fileID := r.FormValue("file")
+		if fileID == "" {
+			http.Error(w, "file is required", http.StatusBadRequest)
+			return
+		}
+		lg.Printf("files.info: file: %s", fileID)
+
+		resp := filesResponseFull{
+			SlackResponse: slack.SlackResponse{
+				Ok: true,
+			},
+		}
+		f, err := p.FileInfo(fileID)
+		if err != nil {
+			resp.Ok = false
+			if errors.Is(err, chunk.ErrNotFound) {
+				resp.Error = "file_not_found"
+			} else {
+				resp.Error = fmt.Sprintf("files.info: file: %s, unexpected error: %s", fileID, err)
+			}
+		} else {
+			resp.File = f
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			lg.Printf("error encoding files.info response: %s", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+type bookmarksResponse struct {
+	Bookmarks []slack.Bookmark `json:"bookmarks"`
+	slack.SlackResponse
+}
+
+func handleBookmarksList(p *chunk.Player) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, task := trace.NewTask(r.Context(), "bookmarks.list")
+		defer task.End()
+
+		channel := r.FormValue("channel_id")
+		if channel == "" {
+			http.Error(w, "channel_id is required", http.StatusBadRequest)
+			return
+		}
+		lg.Printf("bookmarks.list: channel: %s", channel)
+
+		resp := bookmarksResponse{
+			Bookmarks: []slack.Bookmark{},
+			SlackResponse: slack.SlackResponse{
+				Ok: true,
+			},
+		}
+		bb, err := p.Bookmarks(channel)
+		if err != nil {
+			if !errors.Is(err, chunk.ErrNotFound) {
+				lg.Printf("error processing bookmarks.list: %s", err)
+				resp.Ok = false
+				resp.Error = err.Error()
+			}
+		} else {
+			resp.Bookmarks = bb
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			lg.Printf("error encoding bookmarks.list response: %s", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+type starsResponse struct {
+	Items  []slack.StarredItem `json:"items"`
+	Paging slack.Paging        `json:"paging"`
+	slack.SlackResponse
+}
+
+func handleStarsList(p *chunk.Player) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, task := trace.NewTask(r.Context(), "stars.list")
+		defer task.End()
+
+		resp := starsResponse{
+			Items: []slack.StarredItem{},
+			SlackResponse: slack.SlackResponse{
+				Ok: true,
+			},
+		}
+		items, err := p.StarredItems()
+		if err != nil {
+			if !errors.Is(err, chunk.ErrNotFound) {
+				lg.Printf("error processing stars.list: %s", err)
+				resp.Ok = false
+				resp.Error = err.Error()
+			}
+		} else {
+			resp.Items = items
+		}
+		resp.Paging = onePage(len(resp.Items))
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			lg.Printf("error encoding stars.list response: %s", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// handleUsersInfo serves both the single user ("user" parameter) and the
+// batch ("users" parameter, comma-separated) forms of users.info.
+func handleUsersInfo(p *chunk.Player) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, task := trace.NewTask(r.Context(), "users.info")
+		defer task.End()
+
+		single := r.FormValue("user")
+		ids := strings.Split(r.FormValue("users"), ",")
+		if single != "" {
+			ids = []string{single}
+		}
+		if len(ids) == 1 && ids[0] == "" {
+			http.Error(w, "user is required", http.StatusBadRequest)
+			return
+		}
+		lg.Printf("users.info: users: %v", ids)
+
+		resp := userResponseFull{
+			SlackResponse: slack.SlackResponse{
+				Ok: true,
+			},
+		}
+		uu, err := p.UsersInfo(ids...)
+		if err != nil {
+			resp.Ok = false
+			if errors.Is(err, chunk.ErrNotFound) {
+				resp.Error = "user_not_found"
+			} else {
+				resp.Error = fmt.Sprintf("users.info: unexpected error: %s", err)
+			}
+		} else if single != "" {
+			resp.User = uu[0]
+		} else {
+			resp.Users = uu
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			lg.Printf("error encoding users.info response: %s", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
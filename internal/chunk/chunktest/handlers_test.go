@@ -1,11 +1,15 @@
 package chunktest
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/rusq/slack"
+
 	"github.com/rusq/slackdump/v3/internal/chunk"
 	"github.com/rusq/slackdump/v3/internal/fixtures"
 )
@@ -64,3 +68,93 @@ func tRequest[T any](uri string) (T, int, error) {
 	}
 	return ret, resp.StatusCode, err
 }
+
+func Test_exportEndpoints(t *testing.T) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, c := range []chunk.Chunk{
+		{Type: chunk.CUsers, Users: []slack.User{{ID: "U1", Name: "alice"}, {ID: "U2", Name: "bob"}}},
+		{Type: chunk.CFiles, ChannelID: "C1", Parent: &slack.Message{Msg: slack.Msg{Timestamp: "1.1"}}, Files: []slack.File{{ID: "F1", User: "U1"}, {ID: "F2", User: "U2"}}},
+		{Type: chunk.CFiles, ChannelID: "C2", Parent: &slack.Message{Msg: slack.Msg{Timestamp: "2.1"}}, Files: []slack.File{{ID: "F3", User: "U1"}}},
+		{Type: chunk.CBookmarks, ChannelID: "C1", Bookmarks: []slack.Bookmark{{ID: "Bk1", Title: "docs"}}},
+		{Type: chunk.CStarredItems, StarredItems: []slack.StarredItem{{Type: "file", File: &slack.File{ID: "F1"}}}},
+	} {
+		if err := enc.Encode(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := NewServer(bytes.NewReader(buf.Bytes()), "U1")
+	defer srv.Close()
+	cl := slack.New("xoxp-test", slack.OptionAPIURL(srv.URL()))
+	ctx := context.Background()
+
+	t.Run("files.list", func(t *testing.T) {
+		ff, paging, err := cl.GetFilesContext(ctx, slack.GetFilesParameters{Channel: "C1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ff) != 2 || paging.Total != 2 {
+			t.Errorf("got %d files, total %d, want 2", len(ff), paging.Total)
+		}
+		ff, _, err = cl.GetFilesContext(ctx, slack.GetFilesParameters{User: "U1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ff) != 2 || ff[0].ID != "F1" || ff[1].ID != "F3" {
+			t.Errorf("unexpected files: %v", ff)
+		}
+	})
+	t.Run("files.info", func(t *testing.T) {
+		f, _, _, err := cl.GetFileInfoContext(ctx, "F3", 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.ID != "F3" {
+			t.Errorf("got %q, want F3", f.ID)
+		}
+		if _, _, _, err := cl.GetFileInfoContext(ctx, "F9", 0, 0); err == nil || err.Error() != "file_not_found" {
+			t.Errorf("got %v, want file_not_found", err)
+		}
+	})
+	t.Run("bookmarks.list", func(t *testing.T) {
+		bb, err := cl.ListBookmarksContext(ctx, "C1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bb) != 1 || bb[0].Title != "docs" {
+			t.Errorf("unexpected bookmarks: %v", bb)
+		}
+		bb, err = cl.ListBookmarksContext(ctx, "C2")
+		if err != nil || len(bb) != 0 {
+			t.Errorf("got %v, %v, want no bookmarks", bb, err)
+		}
+	})
+	t.Run("stars.list", func(t *testing.T) {
+		items, _, err := cl.GetStarredContext(ctx, slack.StarsParameters{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 1 || items[0].File.ID != "F1" {
+			t.Errorf("unexpected items: %v", items)
+		}
+	})
+	t.Run("users.info", func(t *testing.T) {
+		u, err := cl.GetUserInfoContext(ctx, "U2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Name != "bob" {
+			t.Errorf("got %q, want bob", u.Name)
+		}
+		uu, err := cl.GetUsersInfoContext(ctx, "U1", "U2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(*uu) != 2 {
+			t.Errorf("got %d users, want 2", len(*uu))
+		}
+		if _, err := cl.GetUserInfoContext(ctx, "U9"); err == nil || err.Error() != "user_not_found" {
+			t.Errorf("got %v, want user_not_found", err)
+		}
+	})
+}
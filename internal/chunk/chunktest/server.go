@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http/httptest"
 	"os"
+	"time"
 
 	"github.com/rusq/slackdump/v3/internal/chunk"
 )
@@ -16,18 +17,72 @@ var lg = log.New(os.Stderr, "chunktest: ", log.LstdFlags)
 // from a single chunk file.
 type Server struct {
 	baseServer
-	p *chunk.Player
+	p  *chunk.Player
+	fi *faultInjector
+}
+
+// Option configures the Server.
+type Option func(*serverOptions)
+
+type serverOptions struct {
+	fi         *faultInjector
+	playerOpts []chunk.PlayerOption
+	verify     bool
+}
+
+// WithReplaySpeed makes the server replay the chunks with the simulated
+// timing, speed times faster than they were recorded, with the delays between
+// the chunks capped at maxDelay, if it's not zero.  It is useful for
+// load-testing the consumers and demoing the follow mode.  See
+// [chunk.WithReplaySpeed] and [chunk.WithMaxDelay].
+func WithReplaySpeed(speed float64, maxDelay time.Duration) Option {
+	return func(o *serverOptions) {
+		o.playerOpts = append(o.playerOpts, chunk.WithReplaySpeed(speed), chunk.WithMaxDelay(maxDelay))
+	}
+}
+
+// WithVerify enables or disables the integrity check of the chunk file on
+// the server creation, see [chunk.WithVerify].  It is enabled by default.
+func WithVerify(b bool) Option {
+	return func(o *serverOptions) {
+		o.verify = b
+	}
 }
 
 // NewServer returns a new Server, it requires the chunk file handle in rs, and
 // an ID of the user that will be returned by AuthTest in currentUserID.
-func NewServer(rs io.ReadSeeker, currentUserID string) *Server {
-	p, err := chunk.NewPlayer(rs)
+// Options allow to inject faults, such as rate limiting or server errors, to
+// test the retry logic of the clients, and to slow down the replay.  It
+// panics, if the chunk file fails the integrity check.
+func NewServer(rs io.ReadSeeker, currentUserID string, opts ...Option) *Server {
+	so := serverOptions{fi: newFaultInjector(), verify: true}
+	for _, opt := range opts {
+		opt(&so)
+	}
+	p, err := chunk.NewPlayer(rs, append(so.playerOpts, chunk.WithVerify(so.verify))...)
 	if err != nil {
 		panic(err)
 	}
 	return &Server{
-		baseServer: baseServer{Server: httptest.NewServer(router(p, currentUserID))},
+		baseServer: baseServer{Server: httptest.NewServer(so.fi.wrap(router(p, currentUserID)))},
 		p:          p,
+		fi:         so.fi,
 	}
 }
+
+// FaultStats returns the number of faults injected so far.
+func (s *Server) FaultStats() FaultStats {
+	return s.fi.Stats()
+}
+
+// SeekTo positions the replay of the channel messages to the message with
+// timestamp ts, see [chunk.Player.SeekTo].
+func (s *Server) SeekTo(channelID, ts string) error {
+	return s.p.SeekTo(channelID, ts)
+}
+
+// ReplayFrom positions the replay to the chunk at the offset in the chunk
+// file, see [chunk.Player.ReplayFrom].
+func (s *Server) ReplayFrom(offset int64) error {
+	return s.p.ReplayFrom(offset)
+}
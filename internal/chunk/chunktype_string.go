@@ -20,11 +20,17 @@ func _() {
 	_ = x[CBookmarks-9]
 	_ = x[CSearchMessages-10]
 	_ = x[CSearchFiles-11]
+	_ = x[CTrailer-12]
+	_ = x[CAnnotation-13]
+	_ = x[CMessageEdit-14]
+	_ = x[CMessageDeleted-15]
+	_ = x[CPins-16]
+	_ = x[CUserGroups-17]
 }
 
-const _ChunkType_name = "MessagesThreadMessagesFilesUsersChannelsChannelInfoWorkspaceInfoChannelUsersStarredItemsBookmarksSearchMessagesSearchFiles"
+const _ChunkType_name = "MessagesThreadMessagesFilesUsersChannelsChannelInfoWorkspaceInfoChannelUsersStarredItemsBookmarksSearchMessagesSearchFilesTrailerAnnotationMessageEditMessageDeletedPinsUserGroups"
 
-var _ChunkType_index = [...]uint8{0, 8, 22, 27, 32, 40, 51, 64, 76, 88, 97, 111, 122}
+var _ChunkType_index = [...]uint8{0, 8, 22, 27, 32, 40, 51, 64, 76, 88, 97, 111, 122, 129, 139, 150, 164, 168, 178}
 
 func (i ChunkType) String() string {
 	if i >= ChunkType(len(_ChunkType_index)-1) {
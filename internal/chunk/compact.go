@@ -0,0 +1,135 @@
+package chunk
+
+import (
+	"io"
+	"time"
+
+	"github.com/rusq/slack"
+)
+
+// msgKey uniquely identifies the message within the chunk file.  Thread
+// messages are keyed separately from the channel messages, as the thread
+// parent appears in both.
+type msgKey struct {
+	channelID string
+	threadTS  string
+	ts        string
+}
+
+// msgVersion is the position of the message version within the chunk file.
+type msgVersion struct {
+	recorded int64 // chunk timestamp
+	ordinal  int   // message ordinal within the file
+}
+
+func (v msgVersion) after(other msgVersion) bool {
+	if v.recorded == other.recorded {
+		return v.ordinal > other.ordinal
+	}
+	return v.recorded > other.recorded
+}
+
+// CompactStats contains the results of the compaction.
+type CompactStats struct {
+	// Chunks is the number of chunks written.
+	Chunks int
+	// ChunksRemoved is the number of chunks that were removed.
+	ChunksRemoved int
+	// MessagesRemoved is the number of duplicate messages that were removed.
+	MessagesRemoved int
+}
+
+// Compact writes the compacted chunk file to w.  Compaction removes the
+// duplicate messages for the same channel or thread that appear in the file
+// as the result of resumed or repeated runs, keeping only the latest recorded
+// version of each message.  Message chunks that have no messages left are
+// dropped, unless they mark the end of the channel or thread.  Existing
+// trailers are replaced with a single trailer for the compacted data, that
+// retains the run IDs of the original trailers.  The order of the remaining
+// chunks is preserved.
+func (f *File) Compact(w io.Writer) (CompactStats, error) {
+	// pass 1: find the latest version of each message.
+	latest := make(map[msgKey]msgVersion)
+	var n int
+	if err := f.ForEach(func(c *Chunk) error {
+		if c == nil || !isMessageChunk(c) {
+			return nil
+		}
+		for i := range c.Messages {
+			k := msgKey{c.ChannelID, c.ThreadTS, c.Messages[i].Timestamp}
+			v := msgVersion{recorded: c.Timestamp, ordinal: n}
+			if prev, ok := latest[k]; !ok || v.after(prev) {
+				latest[k] = v
+			}
+			n++
+		}
+		return nil
+	}); err != nil {
+		return CompactStats{}, err
+	}
+
+	// pass 2: write out chunks, leaving only the latest versions.
+	var (
+		st     CompactStats
+		enc    = newChainEncoder(w)
+		stats  = newStatsAggregator()
+		runIDs []string
+	)
+	n = 0
+	if err := f.ForEach(func(c *Chunk) error {
+		if c == nil {
+			return nil
+		}
+		if c.Type == CTrailer {
+			if c.Trailer != nil {
+				runIDs = mergeSorted(runIDs, c.Trailer.RunIDs)
+			}
+			st.ChunksRemoved++
+			return nil
+		}
+		if isMessageChunk(c) {
+			keep := make([]slack.Message, 0, len(c.Messages))
+			for i := range c.Messages {
+				k := msgKey{c.ChannelID, c.ThreadTS, c.Messages[i].Timestamp}
+				if latest[k] == (msgVersion{recorded: c.Timestamp, ordinal: n}) {
+					keep = append(keep, c.Messages[i])
+				} else {
+					st.MessagesRemoved++
+				}
+				n++
+			}
+			if len(keep) == 0 && len(c.Messages) > 0 && !c.IsLast {
+				st.ChunksRemoved++
+				return nil
+			}
+			c.Messages = keep
+			c.Count = len(keep)
+			stats.add(c.ChannelID, keep)
+		}
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+		st.Chunks++
+		return nil
+	}); err != nil {
+		return st, err
+	}
+	// the trailer marks the end of the recording.
+	tr := stats.trailer()
+	tr.RunIDs = runIDs
+	trailer := Chunk{
+		Type:      CTrailer,
+		Timestamp: time.Now().UnixNano(),
+		Count:     len(stats.channels),
+		Trailer:   tr,
+	}
+	if err := enc.Encode(trailer); err != nil {
+		return st, err
+	}
+	st.Chunks++
+	return st, nil
+}
+
+func isMessageChunk(c *Chunk) bool {
+	return c.Type == CMessages || c.Type == CThreadMessages
+}
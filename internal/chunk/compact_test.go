@@ -0,0 +1,58 @@
+package chunk
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFile_Compact(t *testing.T) {
+	msg := func(ts, text string) slack.Message {
+		return slack.Message{Msg: slack.Msg{User: "U1", Timestamp: ts, Text: text}}
+	}
+	parent := msg("1.0", "old")
+	parent.ThreadTimestamp = "1.0"
+	chunks := []Chunk{
+		{Type: CMessages, Timestamp: 1, ChannelID: TestChannelID, Count: 2, Messages: []slack.Message{msg("1.0", "old"), msg("2.0", "two")}},
+		{Type: CThreadMessages, Timestamp: 2, ChannelID: TestChannelID, ThreadTS: "1.0", Parent: &parent, Count: 1, Messages: []slack.Message{msg("1.1", "reply")}},
+		{Type: CTrailer, Timestamp: 3, Trailer: &Trailer{Channels: map[string]ChannelStats{TestChannelID: {MessageCount: 3}}}},
+		// resumed run
+		{Type: CMessages, Timestamp: 4, ChannelID: TestChannelID, Count: 1, Messages: []slack.Message{msg("1.0", "edited")}},
+		{Type: CThreadMessages, Timestamp: 5, ChannelID: TestChannelID, ThreadTS: "1.0", Parent: &parent, Count: 1, Messages: []slack.Message{msg("1.1", "reply")}},
+		{Type: CThreadMessages, Timestamp: 5, ChannelID: TestChannelID, ThreadTS: "1.0", Parent: &parent, IsLast: true, Count: 1, Messages: []slack.Message{msg("1.2", "reply2")}},
+		{Type: CTrailer, Timestamp: 6, Trailer: &Trailer{Channels: map[string]ChannelStats{TestChannelID: {MessageCount: 3}}}},
+	}
+	f, err := FromReader(marshalChunks(chunks...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	st, err := f.Compact(&buf)
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	assert.Equal(t, CompactStats{Chunks: 5, ChunksRemoved: 3, MessagesRemoved: 2}, st)
+
+	cf, err := FromReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mm, err := cf.AllMessages(TestChannelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []slack.Message{msg("2.0", "two"), msg("1.0", "edited")}, mm)
+	tm, err := cf.AllThreadMessages(TestChannelID, "1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []slack.Message{msg("1.1", "reply"), msg("1.2", "reply2")}, tm)
+
+	tr, err := cf.Trailer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 4, tr.Channels[TestChannelID].MessageCount)
+}
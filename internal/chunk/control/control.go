@@ -12,12 +12,15 @@ import (
 	"log/slog"
 	"runtime/trace"
 	"sync"
+	"time"
 
 	"github.com/rusq/slack"
 
 	"github.com/rusq/slackdump/v3"
 	"github.com/rusq/slackdump/v3/internal/chunk"
 	"github.com/rusq/slackdump/v3/internal/chunk/dirproc"
+	"github.com/rusq/slackdump/v3/internal/chunk/state"
+	"github.com/rusq/slackdump/v3/internal/retention"
 	"github.com/rusq/slackdump/v3/internal/structures"
 	"github.com/rusq/slackdump/v3/processor"
 )
@@ -39,6 +42,20 @@ type Controller struct {
 	lg *slog.Logger
 	// flags
 	flags Flags
+	// resume is the state of the previous run, if the run is resumed.
+	resume *state.State
+	// highWater returns the high-water mark of the channel, if the run is
+	// incremental.
+	highWater HighWaterFunc
+	// annotations are the operator notes, that are recorded in the
+	// workspace file.
+	annotations []chunk.Annotation
+	// sched orders the conversations by the risk of deletion, if the run is
+	// retention-aware.
+	sched *retention.Scheduler
+	// mw are the conversation processor middlewares, i.e. the message
+	// filters.
+	mw []processor.Middleware
 }
 
 // Option is a functional option for the Controller.
@@ -76,6 +93,56 @@ func WithLogger(lg *slog.Logger) Option {
 	}
 }
 
+// WithResumeState configures the controller to resume the previous run.
+// Channels that are present in the state are considered complete, they are
+// not fetched again, and are passed to the transformer directly, as their
+// chunk files are expected to be in the chunk directory.  Chunk files of the
+// other channels, if present, are appended to, see [Controller.resumeChannels].
+func WithResumeState(st *state.State) Option {
+	return func(c *Controller) {
+		c.resume = st
+	}
+}
+
+// HighWaterFunc returns the high-water mark for the channel: the timestamp of
+// the newest message that was fetched previously, and true, or false if the
+// channel was not fetched before.
+type HighWaterFunc func(channelID string) (time.Time, bool)
+
+// WithHighWater configures the controller to run incrementally: each channel
+// for which fn returns a high-water mark is fetched starting from the mark,
+// unless the oldest timestamp requested for the channel is newer.
+func WithHighWater(fn HighWaterFunc) Option {
+	return func(c *Controller) {
+		c.highWater = fn
+	}
+}
+
+// WithAnnotations configures the controller to record the operator notes
+// into the workspace file of the chunk directory.
+func WithAnnotations(aa ...chunk.Annotation) Option {
+	return func(c *Controller) {
+		c.annotations = append(c.annotations, aa...)
+	}
+}
+
+// WithRetention configures the controller to fetch the conversations at risk
+// of deletion by the message retention policy first, and to record the time
+// each conversation is saved in the scheduler s.
+func WithRetention(s *retention.Scheduler) Option {
+	return func(c *Controller) {
+		c.sched = s
+	}
+}
+
+// WithMiddleware adds the conversation processor middlewares, that receive
+// the conversations before they are recorded, see [processor.Chain].
+func WithMiddleware(mw ...processor.Middleware) Option {
+	return func(c *Controller) {
+		c.mw = append(c.mw, mw...)
+	}
+}
+
 // New creates a new [Controller].
 func New(cd *chunk.Directory, s Streamer, opts ...Option) *Controller {
 	c := &Controller{
@@ -94,6 +161,23 @@ func New(cd *chunk.Directory, s Streamer, opts ...Option) *Controller {
 // Flags are the controller flags.
 type Flags struct {
 	MemberOnly bool
+	// ChannelUsers makes the controller fetch only the users that appear in
+	// the fetched conversations, i.e. message authors and channel members,
+	// instead of listing all users of the workspace.  The transformation
+	// starts once all conversations are fetched.
+	ChannelUsers bool
+	// UserGroups makes the controller fetch the user groups of the
+	// workspace with their members.
+	UserGroups bool
+	// ExcludeArchived excludes the archived channels from the channel
+	// enumeration in the exclusive mode.  The explicitly listed channels
+	// are fetched regardless.
+	ExcludeArchived bool
+	// ExcludeShared excludes the channels shared with the external
+	// organisations (Slack Connect) from the channel enumeration in the
+	// exclusive mode.  The explicitly listed channels are fetched
+	// regardless.
+	ExcludeShared bool
 }
 
 // Error is a controller error.
@@ -124,7 +208,24 @@ func (c *Controller) Run(ctx context.Context, list *structures.EntityList) error
 		wg    sync.WaitGroup
 		errC  = make(chan error, 1)
 		linkC = make(chan structures.EntityItem)
+		// convOK receives true, once the conversations are fetched
+		// successfully.
+		convOK = make(chan bool, 1)
+	)
+	// tf is the transformer that receives the finalised conversations.  If
+	// only the channel users are fetched, the transformer can't be started
+	// until all conversations are fetched, so they are queued.
+	var (
+		tf      dirproc.Transformer = c.tf
+		pending *pendingTransformer
 	)
+	if c.flags.ChannelUsers {
+		pending = new(pendingTransformer)
+		tf = pending
+	}
+	if c.sched != nil {
+		tf = &doneTransformer{Transformer: tf, sched: c.sched}
+	}
 	// Generator of channel IDs.
 	{
 		var generator linkFeederFunc
@@ -133,7 +234,16 @@ func (c *Controller) Run(ctx context.Context, list *structures.EntityList) error
 			generator = genChFromList
 		} else {
 			// exclusive export (process only excludes, if any)
-			generator = genChFromAPI(c.s, c.cd, c.flags.MemberOnly)
+			generator = genChFromAPI(c.s, c.cd, c.flags)
+		}
+		if c.sched != nil {
+			generator = c.byRisk(generator)
+		}
+		if c.resume != nil {
+			generator = c.resumeChannels(generator, tf)
+		}
+		if c.highWater != nil {
+			generator = c.fromHighWater(generator)
 		}
 
 		wg.Add(1)
@@ -154,18 +264,38 @@ func (c *Controller) Run(ctx context.Context, list *structures.EntityList) error
 		go func() {
 			defer wg.Done()
 			defer lg.DebugContext(ctx, "workspace info done")
-			if err := workspaceWorker(ctx, c.s, c.cd); err != nil {
+			if err := workspaceWorker(ctx, c.s, c.cd, c.annotations...); err != nil {
 				errC <- Error{"workspace", "worker", err}
 				return
 			}
 		}()
 	}
+	if c.flags.UserGroups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer lg.DebugContext(ctx, "user groups done")
+			if err := userGroupWorker(ctx, c.s, c.cd); err != nil {
+				errC <- Error{"usergroups", "worker", err}
+				return
+			}
+		}()
+	}
 	// user goroutine
 	// once all users are fetched, it triggers the transformer to start.
 	{
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			if pending != nil {
+				if ok := <-convOK; !ok {
+					return
+				}
+				if err := channelUserWorker(ctx, c.s, c.cd, c.tf, pending.IDs()); err != nil {
+					errC <- Error{"user", "worker", err}
+				}
+				return
+			}
 			if err := userWorker(ctx, c.s, c.cd, c.tf); err != nil {
 				errC <- Error{"user", "worker", err}
 				return
@@ -174,7 +304,7 @@ func (c *Controller) Run(ctx context.Context, list *structures.EntityList) error
 	}
 	// conversations goroutine
 	{
-		conv, err := dirproc.NewConversation(c.cd, c.filer, c.tf)
+		conv, err := dirproc.NewConversation(c.cd, c.filer, tf, dirproc.WithAppend(c.resume != nil))
 		if err != nil {
 			return fmt.Errorf("error initialising conversation processor: %w", err)
 		}
@@ -182,15 +312,19 @@ func (c *Controller) Run(ctx context.Context, list *structures.EntityList) error
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			ok := false
+			defer func() { convOK <- ok }()
 			defer func() {
 				if err := conv.Close(); err != nil {
+					ok = false
 					errC <- Error{"conversations", "close", err}
 				}
 			}()
-			if err := conversationWorker(ctx, c.s, conv, linkC); err != nil {
+			if err := conversationWorker(ctx, c.s, conv, linkC, c.mw...); err != nil {
 				errC <- Error{"conversations", "worker", err}
 				return
 			}
+			ok = true
 		}()
 	}
 	// sentinel
@@ -212,6 +346,107 @@ func (c *Controller) Run(ctx context.Context, list *structures.EntityList) error
 
 type linkFeederFunc func(ctx context.Context, links chan<- structures.EntityItem, list *structures.EntityList) error
 
+// byRisk wraps the generator, reordering the generated items, so that the
+// conversations at risk of deletion by the retention policy are sent first.
+// It has to wait for the generator to finish, before sending any items.
+func (c *Controller) byRisk(gen linkFeederFunc) linkFeederFunc {
+	return func(ctx context.Context, links chan<- structures.EntityItem, list *structures.EntityList) error {
+		var (
+			genC = make(chan structures.EntityItem)
+			errC = make(chan error, 1)
+		)
+		go func() {
+			defer close(genC)
+			errC <- gen(ctx, genC, list)
+		}()
+		var items []structures.EntityItem
+		for item := range genC {
+			items = append(items, item)
+		}
+		if err := <-errC; err != nil {
+			return err
+		}
+		c.sched.Order(items, c.knownChannels())
+		for _, item := range items {
+			select {
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			case links <- item:
+			}
+		}
+		return nil
+	}
+}
+
+// knownChannels returns the index of the channels from the channel list,
+// recorded in the chunk directory, or nil, if there's none.  It does not use
+// [chunk.Directory.Channels], as it caches the result, and the conversations
+// are not fetched yet.
+func (c *Controller) knownChannels() map[string]*slack.Channel {
+	f, err := c.cd.Open(chunk.FChannels)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	chans, err := f.AllChannels()
+	if err != nil || len(chans) == 0 {
+		return nil
+	}
+	idx := make(map[string]*slack.Channel, len(chans))
+	for i := range chans {
+		idx[chans[i].ID] = &chans[i]
+	}
+	return idx
+}
+
+// fromHighWater wraps the generator, setting the oldest timestamp of each
+// channel to its high-water mark, so that only newer messages are fetched.
+func (c *Controller) fromHighWater(gen linkFeederFunc) linkFeederFunc {
+	return mapGen(gen, func(ctx context.Context, item structures.EntityItem) (structures.EntityItem, bool, error) {
+		sl, err := structures.ParseLink(item.Id)
+		if err != nil || sl.IsThread() {
+			return item, true, nil
+		}
+		if hw, ok := c.highWater(sl.Channel); ok && hw.After(item.Oldest) {
+			c.lg.DebugContext(ctx, "fetching from the high-water mark", "channel_id", sl.Channel, "oldest", hw)
+			item.Oldest = hw
+		}
+		return item, true, nil
+	})
+}
+
+// mapGen wraps the generator, calling fn for each generated item.  fn may
+// modify the item, and decides whether the item is sent to the links
+// channel.  Once fn returns an error, the rest of the items are drained.
+func mapGen(gen linkFeederFunc, fn func(ctx context.Context, item structures.EntityItem) (structures.EntityItem, bool, error)) linkFeederFunc {
+	return func(ctx context.Context, links chan<- structures.EntityItem, list *structures.EntityList) error {
+		var (
+			genC = make(chan structures.EntityItem)
+			errC = make(chan error, 1)
+		)
+		go func() {
+			defer close(genC)
+			errC <- gen(ctx, genC, list)
+		}()
+		var err error
+		for item := range genC {
+			if err != nil {
+				continue // drain
+			}
+			var send bool
+			if item, send, err = fn(ctx, item); err != nil || !send {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				err = context.Cause(ctx)
+			case links <- item:
+			}
+		}
+		return errors.Join(err, <-errC)
+	}
+}
+
 // genChFromList feeds the channel IDs that it gets from the list to
 // the links channel.  It does not fetch the channel list from the api, so
 // it's blazing fast in comparison to apiChannelFeeder.  When needed, get the
@@ -234,13 +469,14 @@ func genChFromList(ctx context.Context, links chan<- structures.EntityItem, list
 // links channel.  It also filters out channels that are excluded in the list.
 // It does not account for "included".  It ignores the thread links in the
 // list.  It writes the channels to the tmpdir.
-func genChFromAPI(s Streamer, cd *chunk.Directory, memberOnly bool) linkFeederFunc {
+func genChFromAPI(s Streamer, cd *chunk.Directory, flags Flags) linkFeederFunc {
 	return func(ctx context.Context, links chan<- structures.EntityItem, list *structures.EntityList) error {
 		chIdx := list.Index()
 		chanproc, err := dirproc.NewChannels(cd, func(c []slack.Channel) error {
 		LOOP:
 			for _, ch := range c {
-				if memberOnly && !ch.IsMember {
+				if skip, reason := flags.skipChannel(&ch); skip {
+					slog.DebugContext(ctx, "skipping channel", "channel_id", ch.ID, "reason", reason)
 					continue
 				}
 				for _, entry := range chIdx {
@@ -263,7 +499,8 @@ func genChFromAPI(s Streamer, cd *chunk.Directory, memberOnly bool) linkFeederFu
 			return err
 		}
 
-		if err := s.ListChannels(ctx, chanproc, &slack.GetConversationsParameters{Types: slackdump.AllChanTypes}); err != nil {
+		params := &slack.GetConversationsParameters{Types: slackdump.AllChanTypes, ExcludeArchived: flags.ExcludeArchived}
+		if err := s.ListChannels(ctx, chanproc, params); err != nil {
 			return fmt.Errorf("error listing channels: %w", err)
 		}
 		if err := chanproc.Close(); err != nil {
@@ -273,3 +510,17 @@ func genChFromAPI(s Streamer, cd *chunk.Directory, memberOnly bool) linkFeederFu
 		return nil
 	}
 }
+
+// skipChannel returns true and the reason, if the channel ch should not be
+// fetched in the exclusive mode.
+func (f Flags) skipChannel(ch *slack.Channel) (bool, string) {
+	switch {
+	case f.MemberOnly && !ch.IsMember:
+		return true, "not a member"
+	case f.ExcludeArchived && ch.IsArchived:
+		return true, "archived"
+	case f.ExcludeShared && structures.IsExtShared(ch):
+		return true, "shared with external organisation"
+	}
+	return false, ""
+}
@@ -12,10 +12,12 @@ import (
 
 // Streamer is the interface for the API scraper.
 type Streamer interface {
-	Conversations(ctx context.Context, proc processor.Conversations, links <-chan structures.EntityItem) error
+	Conversations(ctx context.Context, proc processor.Conversations, links <-chan structures.EntityItem, mw ...processor.Middleware) error
 	ListChannels(ctx context.Context, proc processor.Channels, p *slack.GetConversationsParameters) error
 	Users(ctx context.Context, proc processor.Users, opt ...slack.GetUsersOption) error
+	UsersByID(ctx context.Context, proc processor.Users, ids []string) error
 	WorkspaceInfo(ctx context.Context, proc processor.WorkspaceInfo) error
+	UserGroups(ctx context.Context, proc processor.UserGroups) error
 	SearchMessages(ctx context.Context, proc processor.MessageSearcher, query string) error
 	SearchFiles(ctx context.Context, proc processor.FileSearcher, query string) error
 }
@@ -0,0 +1,131 @@
+package control
+
+// In this file: resuming the interrupted run.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/chunk/dirproc"
+	"github.com/rusq/slackdump/v3/internal/structures"
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+// resumeChannels wraps the generator, continuing the channels of the
+// interrupted run from where it stopped:
+//
+//   - channels that are complete in the resume state are not fetched, and
+//     are sent to the transformer tf;
+//   - threads, whose replies were not recorded completely, are fetched
+//     again, and appended to the partial channel chunk file;
+//   - channel messages are fetched from the earliest recorded message, as
+//     the messages are fetched newest first, and appended to the partial
+//     channel chunk file.
+//
+// Channels without the chunk file are fetched as usual.
+func (c *Controller) resumeChannels(gen linkFeederFunc, tf dirproc.Transformer) linkFeederFunc {
+	return mapGen(gen, func(ctx context.Context, item structures.EntityItem) (structures.EntityItem, bool, error) {
+		sl, err := structures.ParseLink(item.Id)
+		if err != nil || sl.IsThread() {
+			return item, true, nil
+		}
+		id := chunk.ToFileID(sl.Channel, "", false)
+		lg := c.lg.With("channel_id", sl.Channel)
+		if c.resume.HasChannel(sl.Channel) {
+			lg.DebugContext(ctx, "channel is complete, skipping")
+			return item, false, tf.Transform(ctx, id)
+		}
+		p, err := c.progress(id, sl.Channel)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return item, true, nil
+			}
+			return item, false, fmt.Errorf("error reading the partial chunk file of %s: %w", sl.Channel, err)
+		}
+		if len(p.Threads) > 0 {
+			lg.InfoContext(ctx, "fetching incomplete threads", "count", len(p.Threads))
+			if err := c.resumeThreads(ctx, sl.Channel, p.Threads); err != nil {
+				return item, false, err
+			}
+		}
+		if p.Done {
+			lg.DebugContext(ctx, "channel messages are complete")
+			return item, false, tf.Transform(ctx, id)
+		}
+		if p.Earliest != "" {
+			earliest, err := structures.ParseSlackTS(p.Earliest)
+			if err != nil {
+				return item, false, fmt.Errorf("channel %s: %w", sl.Channel, err)
+			}
+			// the bounds are inclusive, the recorded message is excluded.
+			item.Latest = earliest.Add(-time.Microsecond)
+			lg.InfoContext(ctx, "resuming channel", "latest", item.Latest)
+		}
+		return item, true, nil
+	})
+}
+
+// progress returns the recording progress of the channel in the chunk file
+// id.
+func (c *Controller) progress(id chunk.FileID, channelID string) (*chunk.Progress, error) {
+	f, err := c.cd.Open(id)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Progress(channelID)
+}
+
+// resumeThreads fetches the threads of the channel, and appends them to
+// the channel chunk file.
+func (c *Controller) resumeThreads(ctx context.Context, channelID string, threads []string) (err error) {
+	wc, err := c.cd.Append(chunk.ToFileID(channelID, "", false))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, wc.Close())
+	}()
+	proc := &threadAppender{Recorder: chunk.NewRecorder(wc), filer: c.filer}
+	defer func() {
+		err = errors.Join(err, proc.Close())
+	}()
+
+	links := make(chan structures.EntityItem, len(threads))
+	for _, ts := range threads {
+		links <- structures.EntityItem{Id: structures.SlackLink{Channel: channelID, ThreadTS: ts}.String(), Include: true}
+	}
+	close(links)
+	if err := c.s.Conversations(ctx, proc, links, c.mw...); err != nil {
+		return fmt.Errorf("error fetching threads of %s: %w", channelID, err)
+	}
+	return nil
+}
+
+// threadAppender records the thread replies, requested by the thread links,
+// as the threads of the channel messages, so that they are found along with
+// the channel messages recorded by the interrupted run.
+type threadAppender struct {
+	*chunk.Recorder
+	filer processor.Filer
+}
+
+// ChannelInfo does nothing, the channel information is recorded by the
+// interrupted run.
+func (a *threadAppender) ChannelInfo(context.Context, *slack.Channel, string) error {
+	return nil
+}
+
+func (a *threadAppender) ThreadMessages(ctx context.Context, channelID string, parent slack.Message, _ bool, isLast bool, replies []slack.Message) error {
+	return a.Recorder.ThreadMessages(ctx, channelID, parent, false, isLast, replies)
+}
+
+func (a *threadAppender) Files(ctx context.Context, channel *slack.Channel, parent slack.Message, ff []slack.File) error {
+	return a.filer.Files(ctx, channel, parent, ff)
+}
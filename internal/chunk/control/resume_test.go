@@ -0,0 +1,114 @@
+package control
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/chunk/state"
+	"github.com/rusq/slackdump/v3/internal/structures"
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+// threadStreamer replies to the thread links with a single reply.
+type threadStreamer struct {
+	Streamer
+	mu      sync.Mutex
+	threads []string
+}
+
+func (s *threadStreamer) Conversations(ctx context.Context, proc processor.Conversations, links <-chan structures.EntityItem, _ ...processor.Middleware) error {
+	for link := range links {
+		sl, err := structures.ParseLink(link.Id)
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.threads = append(s.threads, sl.ThreadTS)
+		s.mu.Unlock()
+		if err := proc.ChannelInfo(ctx, &slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: sl.Channel}}}, sl.ThreadTS); err != nil {
+			return err
+		}
+		parent := slack.Message{Msg: slack.Msg{Timestamp: sl.ThreadTS, ThreadTimestamp: sl.ThreadTS}}
+		reply := slack.Message{Msg: slack.Msg{Timestamp: sl.ThreadTS + "1", ThreadTimestamp: sl.ThreadTS}}
+		if err := proc.ThreadMessages(ctx, sl.Channel, parent, true, true, []slack.Message{parent, reply}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// idTransformer records the transformed file IDs.
+type idTransformer struct {
+	ids []chunk.FileID
+}
+
+func (t *idTransformer) Transform(_ context.Context, id chunk.FileID) error {
+	t.ids = append(t.ids, id)
+	return nil
+}
+
+func TestController_resumeChannels(t *testing.T) {
+	const (
+		complete = "C1"
+		partial  = "C2"
+		missing  = "C3"
+	)
+	ctx := context.Background()
+	cd, err := chunk.OpenDir(t.TempDir())
+	require.NoError(t, err)
+	defer cd.Close()
+
+	// partial recording of C2: the first page with a complete and an
+	// incomplete thread.
+	wc, err := cd.Create(chunk.ToFileID(partial, "", false))
+	require.NoError(t, err)
+	rec := chunk.NewRecorder(wc)
+	thread := func(ts string) slack.Message {
+		return slack.Message{Msg: slack.Msg{Timestamp: ts, ThreadTimestamp: ts, LatestReply: ts + "1"}}
+	}
+	require.NoError(t, rec.Messages(ctx, partial, 2, false, []slack.Message{thread("1700000003.000000"), thread("1700000002.000000")}))
+	require.NoError(t, rec.ThreadMessages(ctx, partial, thread("1700000003.000000"), false, true, []slack.Message{{Msg: slack.Msg{Timestamp: "1700000003.0000001"}}}))
+	require.NoError(t, rec.Close())
+	require.NoError(t, wc.Close())
+
+	st := state.New(cd.Name())
+	st.AddMessage(complete, "1700000001.000000")
+	s := &threadStreamer{}
+	c := New(cd, s, WithResumeState(st), WithLogger(slog.Default()))
+
+	list, err := structures.NewEntityList([]string{complete, partial, missing})
+	require.NoError(t, err)
+	var tf idTransformer
+	gen := c.resumeChannels(genChFromList, &tf)
+	links := make(chan structures.EntityItem, 3)
+	require.NoError(t, gen(ctx, links, list))
+	close(links)
+	got := make(map[string]structures.EntityItem)
+	for item := range links {
+		got[item.Id] = item
+	}
+
+	assert.Equal(t, []chunk.FileID{chunk.ToFileID(complete, "", false)}, tf.ids, "complete channel must be transformed")
+	if assert.Len(t, got, 2) {
+		want, _ := structures.ParseSlackTS("1700000002.000000")
+		assert.Equal(t, want.Add(-time.Microsecond), got[partial].Latest, "partial channel must continue from the earliest message")
+		assert.True(t, got[missing].Latest.IsZero())
+	}
+	assert.Equal(t, []string{"1700000002.000000"}, s.threads, "only the incomplete thread must be fetched")
+
+	f, err := cd.Open(chunk.ToFileID(partial, "", false))
+	require.NoError(t, err)
+	defer f.Close()
+	p, err := f.Progress(partial)
+	require.NoError(t, err)
+	assert.Empty(t, p.Threads)
+	assert.False(t, p.Done)
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log/slog"
 	"runtime/trace"
+	"sort"
+	"sync"
 
 	"github.com/rusq/slackdump/v3/internal/structures"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/rusq/slackdump/v3/internal/chunk"
 	"github.com/rusq/slackdump/v3/internal/chunk/dirproc"
 	"github.com/rusq/slackdump/v3/internal/chunk/transform"
+	"github.com/rusq/slackdump/v3/internal/retention"
 	"github.com/rusq/slackdump/v3/processor"
 )
 
@@ -47,9 +50,113 @@ func userWorker(ctx context.Context, s Streamer, chunkdir *chunk.Directory, tf T
 	return nil
 }
 
-func conversationWorker(ctx context.Context, s Streamer, proc processor.Conversations, links <-chan structures.EntityItem) error {
+// channelUserWorker fetches the users that appear in the conversations
+// with the given file IDs, and starts the transformer, passing it the
+// conversations.  It must be called once all conversations are fetched.
+func channelUserWorker(ctx context.Context, s Streamer, chunkdir *chunk.Directory, tf ExportTransformer, ids []chunk.FileID) error {
+	ctx, task := trace.NewTask(ctx, "channelUserWorker")
+	defer task.End()
+
+	userIDs, err := collectUserIDs(chunkdir, ids)
+	if err != nil {
+		return err
+	}
+	slog.DebugContext(ctx, "fetching channel users", "conversations", len(ids), "users", len(userIDs))
+	var users = make([]slack.User, 0, len(userIDs))
+	userproc, err := dirproc.NewUsers(chunkdir, dirproc.WithUsers(func(us []slack.User) error {
+		users = append(users, us...)
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+	if err := s.UsersByID(ctx, userproc, userIDs); err != nil {
+		return errors.Join(fmt.Errorf("error fetching users: %w", err), userproc.Close())
+	}
+	if err := userproc.Close(); err != nil {
+		return fmt.Errorf("error closing user processor: %w", err)
+	}
+	slog.DebugContext(ctx, "users done")
+	if len(users) == 0 {
+		return fmt.Errorf("unable to proceed, no users found")
+	}
+	if err := tf.StartWithUsers(ctx, users); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return fmt.Errorf("error starting the transformer: %w", err)
+	}
+	for _, id := range ids {
+		if err := tf.Transform(ctx, id); err != nil {
+			return fmt.Errorf("error transforming %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// collectUserIDs returns the sorted unique IDs of users that appear in the
+// chunk files with the given IDs.
+func collectUserIDs(chunkdir *chunk.Directory, ids []chunk.FileID) ([]string, error) {
+	seen := make(map[string]struct{})
+	for _, id := range ids {
+		f, err := chunkdir.Open(id)
+		if err != nil {
+			return nil, fmt.Errorf("error opening %s: %w", id, err)
+		}
+		uids, err := f.UserIDs()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", id, err)
+		}
+		for _, uid := range uids {
+			seen[uid] = struct{}{}
+		}
+	}
+	ret := make([]string, 0, len(seen))
+	for uid := range seen {
+		ret = append(ret, uid)
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+// doneTransformer records the finalised conversations as saved in the
+// retention scheduler, before passing them to the transformer.
+type doneTransformer struct {
+	dirproc.Transformer
+	sched *retention.Scheduler
+}
+
+func (t *doneTransformer) Transform(ctx context.Context, id chunk.FileID) error {
+	channelID, _ := id.Split()
+	t.sched.Done(channelID)
+	return t.Transformer.Transform(ctx, id)
+}
+
+// pendingTransformer queues the IDs of the finalised conversations, until
+// the real transformer can be started.  It never blocks.
+type pendingTransformer struct {
+	mu  sync.Mutex
+	ids []chunk.FileID
+}
+
+func (p *pendingTransformer) Transform(ctx context.Context, id chunk.FileID) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ids = append(p.ids, id)
+	return nil
+}
+
+// IDs returns the queued IDs.
+func (p *pendingTransformer) IDs() []chunk.FileID {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]chunk.FileID(nil), p.ids...)
+}
+
+func conversationWorker(ctx context.Context, s Streamer, proc processor.Conversations, links <-chan structures.EntityItem, mw ...processor.Middleware) error {
 	lg := slog.Default()
-	if err := s.Conversations(ctx, proc, links); err != nil {
+	if err := s.Conversations(ctx, proc, links, mw...); err != nil {
 		if errors.Is(err, transform.ErrClosed) {
 			return fmt.Errorf("upstream error: %w", err)
 		}
@@ -59,7 +166,7 @@ func conversationWorker(ctx context.Context, s Streamer, proc processor.Conversa
 	return nil
 }
 
-func workspaceWorker(ctx context.Context, s Streamer, cd *chunk.Directory) error {
+func workspaceWorker(ctx context.Context, s Streamer, cd *chunk.Directory, aa ...chunk.Annotation) error {
 	lg := slog.Default()
 	lg.Debug("workspaceWorker started")
 	wsproc, err := dirproc.NewWorkspace(cd)
@@ -70,10 +177,33 @@ func workspaceWorker(ctx context.Context, s Streamer, cd *chunk.Directory) error
 	if err := s.WorkspaceInfo(ctx, wsproc); err != nil {
 		return err
 	}
+	for _, a := range aa {
+		if err := wsproc.Annotate(ctx, a); err != nil {
+			return err
+		}
+	}
 	lg.Debug("workspaceWorker done")
 	return nil
 }
 
+// userGroupWorker records the user groups of the workspace.  The API errors
+// are logged, and not returned, as the user groups are not available on all
+// plans, and the export can proceed without them.
+func userGroupWorker(ctx context.Context, s Streamer, cd *chunk.Directory) error {
+	ugproc, err := dirproc.NewUserGroups(cd)
+	if err != nil {
+		return err
+	}
+	defer ugproc.Close()
+	if err := s.UserGroups(ctx, ugproc); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		slog.WarnContext(ctx, "unable to get the user groups", "error", err)
+	}
+	return nil
+}
+
 func searchMsgWorker(ctx context.Context, s Streamer, filer processor.Filer, cd *chunk.Directory, query string) error {
 	ctx, task := trace.NewTask(ctx, "searchMsgWorker")
 	defer task.End()
@@ -1,7 +1,6 @@
 package chunk
 
 import (
-	"compress/gzip"
 	"encoding/gob"
 	"errors"
 	"fmt"
@@ -14,16 +13,43 @@ import (
 
 	"github.com/rusq/slack"
 	"github.com/rusq/slackdump/v3/internal/osext"
+	"github.com/rusq/slackdump/v3/types"
 )
 
-const chunkExt = ".json.gz"
+// chunk file extensions, gzip is the default.
+const (
+	chunkExt     = ".json.gz"
+	chunkExtZSTD = ".json.zst"
+)
+
+// FileIDOf returns the FileID of the chunk file with the name, and true, if
+// the name has the chunk file extension (".json.gz" or ".json.zst").
+func FileIDOf(name string) (FileID, bool) {
+	base := filepath.Base(name)
+	for _, ext := range []string{chunkExt, chunkExtZSTD} {
+		if id, ok := strings.CutSuffix(base, ext); ok {
+			return FileID(id), true
+		}
+	}
+	return "", false
+}
+
+// compressionOf returns the compression of the chunk file name.  Chunk files
+// without the known suffix are expected to be gzip-compressed.
+func compressionOf(name string) osext.Compression {
+	if c := osext.CompressionOf(name); c != osext.CompressNone {
+		return c
+	}
+	return osext.CompressGZIP
+}
 
 // common filenames
 const (
-	FChannels  FileID = "channels"
-	FUsers     FileID = "users"
-	FWorkspace FileID = "workspace"
-	FSearch    FileID = "search"
+	FChannels   FileID = "channels"
+	FUsers      FileID = "users"
+	FWorkspace  FileID = "workspace"
+	FSearch     FileID = "search"
+	FUserGroups FileID = "usergroups"
 )
 
 const uploadsDir = "__uploads" // for serving files
@@ -34,12 +60,14 @@ const uploadsDir = "__uploads" // for serving files
 // functions with suffix RAW, will append an extension to the name
 // automatically (".json.gz").  *RAW functions expect the full name of the
 // file with the extension.  All files created by this package will be
-// compressed with GZIP, unless stated otherwise.
+// compressed with GZIP, unless stated otherwise, see [WithCompression].
 type Directory struct {
 	// dir is a path to a physical directory on the filesystem with chunks and
 	// uploads.
 	dir   string
 	cache dcache
+	// comp is the compression of the created chunk files.
+	comp osext.Compression
 
 	wantCache bool
 	fm        *filemgr
@@ -57,6 +85,19 @@ func WithCache(enabled bool) DirOption {
 	}
 }
 
+// WithCompression sets the compression of the chunk files created in the
+// directory, the extension of the files is ".json.gz" for gzip, and
+// ".json.zst" for zstd.  Chunk files are always compressed, so the empty
+// value means gzip.  The existing files are read and appended to with their
+// own compression.
+func WithCompression(c osext.Compression) DirOption {
+	return func(d *Directory) {
+		if c != osext.CompressNone {
+			d.comp = c
+		}
+	}
+}
+
 // OpenDir "opens" an existing directory for read and write operations.
 // It expects the directory to exist and to be a directory, otherwise it will
 // return an error.
@@ -68,6 +109,7 @@ func OpenDir(dir string, opt ...DirOption) (*Directory, error) {
 	}
 	d := &Directory{
 		dir:       dir,
+		comp:      osext.CompressGZIP,
 		wantCache: true,
 	}
 	for _, o := range opt {
@@ -124,7 +166,7 @@ func (d *Directory) Channels() ([]slack.Channel, error) {
 		if err != nil {
 			return err
 		}
-		if !strings.HasSuffix(path, chunkExt) {
+		if _, ok := FileIDOf(path); !ok {
 			return nil
 		} else if de.IsDir() {
 			return nil
@@ -207,6 +249,20 @@ func (d *Directory) Users() ([]slack.User, error) {
 	return users, nil
 }
 
+// UserGroups returns the user groups from the directory.  It returns
+// ErrNotFound, if the user groups were not recorded.
+func (d *Directory) UserGroups() ([]slack.UserGroup, error) {
+	f, err := d.Open(FUserGroups)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return f.AllUserGroups()
+}
+
 // Open opens a chunk file with the given name.  Extension is appended
 // automatically.
 func (d *Directory) Open(id FileID) (*File, error) {
@@ -232,7 +288,8 @@ func (d *Directory) openRAW(filename string) (osext.ReadSeekCloseNamer, error) {
 }
 
 // openChunks opens an existing chunk file and returns a ReadSeekCloser.  It
-// expects a chunkfile to be a gzip-compressed file.
+// expects a chunkfile to be a gzip or zstd-compressed file, see
+// [compressionOf].
 func openChunks(filename string) (osext.ReadSeekCloseNamer, error) {
 	f, err := openfile(filename)
 	if err != nil {
@@ -240,7 +297,11 @@ func openChunks(filename string) (osext.ReadSeekCloseNamer, error) {
 	}
 	defer f.Close()
 
-	tf, err := osext.UnGZIP(f)
+	unpack := osext.UnGZIP
+	if compressionOf(filename) == osext.CompressZSTD {
+		unpack = osext.UnZSTD
+	}
+	tf, err := unpack(f)
 	if err != nil {
 		return nil, err
 	}
@@ -260,8 +321,30 @@ func openfile(filename string) (*os.File, error) {
 }
 
 // filename returns the full path of the chunk file with the given fileID.
+// If the file exists with any of the chunk file extensions, its name is
+// returned, otherwise, the extension is chosen by the compression of the
+// directory.
 func (d *Directory) filename(id FileID) string {
-	return filepath.Join(d.dir, string(id)+chunkExt)
+	ext := chunkExt
+	if d.comp == osext.CompressZSTD {
+		ext = chunkExtZSTD
+	}
+	name := filepath.Join(d.dir, string(id)+ext)
+	for _, e := range []string{chunkExt, chunkExtZSTD} {
+		if e == ext {
+			continue
+		}
+		if other := filepath.Join(d.dir, string(id)+e); fileExists(other) {
+			return other
+		}
+	}
+	return name
+}
+
+// fileExists returns true if the file with the name exists.
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
 }
 
 // Create creates the chunk file with the given name.  Extension is appended
@@ -288,20 +371,57 @@ func (d *Directory) Create(fileID FileID) (io.WriteCloser, error) {
 	if err != nil {
 		return nil, err
 	}
-	gz := gzip.NewWriter(f)
-	return &closewrapper{WriteCloser: gz, underlying: f}, nil
+	cw, err := compressionOf(filename).NewWriter(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &closewrapper{WriteCloser: cw, underlying: f}, nil
+}
+
+// Append opens the chunk file with the given name for appending, creating
+// it, if it does not exist.  The chunks are written as a new gzip member, or
+// zstd frame, so that the existing chunks are retained, and the file is read
+// as a whole.
+func (d *Directory) Append(fileID FileID) (io.WriteCloser, error) {
+	filename := d.filename(fileID)
+	if fi, err := os.Stat(filename); err == nil && fi.IsDir() {
+		return nil, fmt.Errorf("is a directory: %s", filename)
+	}
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	zw, err := compressionOf(filename).NewWriter(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	cw := &closewrapper{WriteCloser: zw, underlying: f}
+	if d.fm != nil {
+		// the cached copy is stale once the file is appended to.
+		cw.onClose = func() { d.fm.Forget(filename) }
+	}
+	return cw, nil
 }
 
 type closewrapper struct {
 	io.WriteCloser
 	underlying io.Closer
+	onClose    func() // called once the file is closed, if set.
 }
 
 func (c *closewrapper) Close() error {
 	if err := c.WriteCloser.Close(); err != nil {
 		return err
 	}
-	return c.underlying.Close()
+	if err := c.underlying.Close(); err != nil {
+		return err
+	}
+	if c.onClose != nil {
+		c.onClose()
+	}
+	return nil
 }
 
 // WorkspaceInfo returns the workspace info from the directory.
@@ -323,6 +443,20 @@ func (d *Directory) WorkspaceInfo() (*slack.AuthTestResponse, error) {
 	return nil, errors.New("no workspace info found")
 }
 
+// WorkspaceDetails returns the extended workspace information from the
+// workspace file.  It returns ErrNotFound, if it was not recorded.
+func (d *Directory) WorkspaceDetails() (*types.Workspace, error) {
+	f, err := d.Open(FWorkspace)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return f.WorkspaceDetails()
+}
+
 const extIdx = ".idx"
 
 func cachedFromReader(wf osext.ReadSeekCloseNamer, wantCache bool) (*File, error) {
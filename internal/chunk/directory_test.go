@@ -1,6 +1,7 @@
 package chunk
 
 import (
+	"context"
 	"io"
 	"path/filepath"
 	"reflect"
@@ -154,3 +155,88 @@ func (n nopCloser) Name() string { return n.name }
 func TestOpenDir(t *testing.T) {
 
 }
+
+func TestDirectory_Append(t *testing.T) {
+	tests := []struct {
+		name    string
+		comp    osext.Compression
+		wantExt string
+	}{
+		{"default", osext.CompressNone, chunkExt},
+		{"gzip", osext.CompressGZIP, chunkExt},
+		{"zstd", osext.CompressZSTD, chunkExtZSTD},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testDirectoryAppend(t, tt.comp, tt.wantExt)
+		})
+	}
+}
+
+func testDirectoryAppend(t *testing.T, comp osext.Compression, wantExt string) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cd, err := OpenDir(dir, WithCompression(comp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cd.Close()
+	id := ToFileID(TestChannelID, "", false)
+	for _, ts := range []string{"1700000002.000000", "1700000001.000000"} {
+		wc, err := cd.Append(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := NewRecorder(wc)
+		if err := rec.Messages(ctx, TestChannelID, 0, false, []slack.Message{testMsg("U1", ts)}); err != nil {
+			t.Fatal(err)
+		}
+		if err := rec.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := wc.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !fileExists(filepath.Join(dir, string(id)+wantExt)) {
+		t.Errorf("chunk file %s%s was not created", id, wantExt)
+	}
+	f, err := cd.Open(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	mm, err := f.AllMessages(TestChannelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mm) != 2 {
+		t.Errorf("AllMessages() returned %d messages, want 2", len(mm))
+	}
+	r, err := f.Audit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.OK() || r.Segments != 2 {
+		t.Errorf("Audit() = %+v, want 2 segments without breaks", r)
+	}
+}
+
+func TestFileIDOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   FileID
+		wantOK bool
+	}{
+		{"C123.json.gz", "C123", true},
+		{"/tmp/dir/C123-1700000001.000000.json.zst", "C123-1700000001.000000", true},
+		{"C123.json", "", false},
+		{"export.state.json", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := FileIDOf(tt.name)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("FileIDOf(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -39,6 +39,8 @@ type Conversations struct {
 	// transform to download the files.
 	subproc     processor.Filer // files sub-processor
 	recordFiles bool
+	// appendFiles makes the processor append to the existing chunk files.
+	appendFiles bool
 
 	// tf is the channel transformer that is called for each channel.
 	tf Transformer
@@ -58,6 +60,7 @@ type datahandler interface {
 	processor.ChannelInformer
 	processor.Messenger
 	processor.Filer
+	processor.Pinner
 	counter
 	io.Closer
 }
@@ -79,6 +82,15 @@ func WithLogger(lg *slog.Logger) ConvOption {
 	}
 }
 
+// WithAppend sets whether the conversations should be appended to the
+// existing chunk files, i.e. when the interrupted run is resumed.  By
+// default, the chunk files must not exist.
+func WithAppend(b bool) ConvOption {
+	return func(cv *Conversations) {
+		cv.appendFiles = b
+	}
+}
+
 // WithRecordFiles sets whether the files should be recorded in the chunk file.
 func WithRecordFiles(b bool) ConvOption {
 	return func(cv *Conversations) {
@@ -104,7 +116,6 @@ func NewConversation(cd *chunk.Directory, filesSubproc processor.Filer, tf Trans
 	}
 
 	c := &Conversations{
-		t:       newFileTracker(cd),
 		lg:      slog.Default(),
 		subproc: filesSubproc,
 		tf:      tf,
@@ -112,6 +123,9 @@ func NewConversation(cd *chunk.Directory, filesSubproc processor.Filer, tf Trans
 	for _, opt := range opts {
 		opt(c)
 	}
+	ft := newFileTracker(cd)
+	ft.append = c.appendFiles
+	c.t = ft
 	return c, nil
 }
 
@@ -211,7 +225,7 @@ func (cv *Conversations) Files(ctx context.Context, channel *slack.Channel, pare
 	if !cv.recordFiles {
 		return nil
 	}
-	id := chunk.ToFileID(channel.ID, parent.ThreadTimestamp, false) // we don't do files for threads in export
+	id := cv.filesID(channel.ID, parent.ThreadTimestamp)
 	r, err := cv.t.Recorder(id)
 	if err != nil {
 		return err
@@ -222,6 +236,19 @@ func (cv *Conversations) Files(ctx context.Context, channel *slack.Channel, pare
 	return nil
 }
 
+// filesID returns the file ID for the files of the message in the thread
+// threadTS.  Files are recorded in the channel file, unless only the thread
+// was requested, in which case they go to the thread file, along with the
+// thread messages.
+func (cv *Conversations) filesID(channelID, threadTS string) chunk.FileID {
+	if threadTS != "" {
+		if tid := chunk.ToFileID(channelID, threadTS, true); cv.t.RefCount(tid) > 0 {
+			return tid
+		}
+	}
+	return chunk.ToFileID(channelID, "", false)
+}
+
 func (cv *Conversations) ChannelUsers(ctx context.Context, channelID string, threadTS string, cu []string) error {
 	r, err := cv.t.Recorder(chunk.ToFileID(channelID, threadTS, threadTS != ""))
 	if err != nil {
@@ -230,6 +257,24 @@ func (cv *Conversations) ChannelUsers(ctx context.Context, channelID string, thr
 	return r.ChannelUsers(ctx, channelID, threadTS, cu)
 }
 
+// Pins records the pinned items of the channel.
+func (cv *Conversations) Pins(ctx context.Context, channelID string, items []slack.Item) error {
+	r, err := cv.t.Recorder(chunk.ToFileID(channelID, "", false))
+	if err != nil {
+		return err
+	}
+	return r.Pins(ctx, channelID, items)
+}
+
+// Bookmarks records the bookmarks of the channel.
+func (cv *Conversations) Bookmarks(ctx context.Context, channelID string, bookmarks []slack.Bookmark) error {
+	r, err := cv.t.Recorder(chunk.ToFileID(channelID, "", false))
+	if err != nil {
+		return err
+	}
+	return r.Bookmarks(ctx, channelID, bookmarks)
+}
+
 func (cv *Conversations) Close() error {
 	return cv.t.CloseAll()
 }
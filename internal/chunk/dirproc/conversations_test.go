@@ -616,6 +616,46 @@ func TestConversations_Files(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "thread reply files go to the thread file, if only the thread is processed",
+			fields: fields{
+				recordFiles: true,
+				tf:          nil,
+			},
+			args: args{
+				ctx:     testCtx,
+				channel: fixtures.DummyChannel("channelID"),
+				parent:  slack.Message{Msg: slack.Msg{Timestamp: "124", ThreadTimestamp: "123"}},
+				ff:      []slack.File{},
+			},
+			expectFn: func(mt *Mocktracker, mdh *Mockdatahandler, mf *mock_processor.MockFiler) {
+				mf.EXPECT().Files(gomock.Any(), fixtures.DummyChannel("channelID"), slack.Message{Msg: slack.Msg{Timestamp: "124", ThreadTimestamp: "123"}}, []slack.File{}).Return(nil)
+				mt.EXPECT().RefCount(chunk.ToFileID("channelID", "123", true)).Return(1)
+				mt.EXPECT().Recorder(chunk.ToFileID("channelID", "123", true)).Return(mdh, nil)
+				mdh.EXPECT().Files(gomock.Any(), fixtures.DummyChannel("channelID"), slack.Message{Msg: slack.Msg{Timestamp: "124", ThreadTimestamp: "123"}}, []slack.File{}).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "thread reply files go to the channel file",
+			fields: fields{
+				recordFiles: true,
+				tf:          nil,
+			},
+			args: args{
+				ctx:     testCtx,
+				channel: fixtures.DummyChannel("channelID"),
+				parent:  slack.Message{Msg: slack.Msg{Timestamp: "124", ThreadTimestamp: "123"}},
+				ff:      []slack.File{},
+			},
+			expectFn: func(mt *Mocktracker, mdh *Mockdatahandler, mf *mock_processor.MockFiler) {
+				mf.EXPECT().Files(gomock.Any(), fixtures.DummyChannel("channelID"), slack.Message{Msg: slack.Msg{Timestamp: "124", ThreadTimestamp: "123"}}, []slack.File{}).Return(nil)
+				mt.EXPECT().RefCount(chunk.ToFileID("channelID", "123", true)).Return(0)
+				mt.EXPECT().Recorder(chunk.ToFileID("channelID", "", false)).Return(mdh, nil)
+				mdh.EXPECT().Files(gomock.Any(), fixtures.DummyChannel("channelID"), slack.Message{Msg: slack.Msg{Timestamp: "124", ThreadTimestamp: "123"}}, []slack.File{}).Return(nil)
+			},
+			wantErr: false,
+		},
 		{
 			name: "subprocessor files returns error",
 			fields: fields{
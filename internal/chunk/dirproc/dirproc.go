@@ -27,12 +27,24 @@ func newDirProc(cd *chunk.Directory, name chunk.FileID) (*dirproc, error) {
 	if err != nil {
 		return nil, err
 	}
+	return wrapDirProc(wc), nil
+}
 
-	r := chunk.NewRecorder(wc)
+// appendDirProc initialises the directory processor, that appends to the
+// chunk file in a directory cd, the existing chunks are retained.
+func appendDirProc(cd *chunk.Directory, name chunk.FileID) (*dirproc, error) {
+	wc, err := cd.Append(name)
+	if err != nil {
+		return nil, err
+	}
+	return wrapDirProc(wc), nil
+}
+
+func wrapDirProc(wc io.WriteCloser) *dirproc {
 	return &dirproc{
 		wc:       wc,
-		Recorder: r,
-	}, nil
+		Recorder: chunk.NewRecorder(wc),
+	}
 }
 
 // Close closes the processor and the underlying chunk file.
@@ -175,6 +175,20 @@ func (mr *MockdatahandlerMockRecorder) Add(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*Mockdatahandler)(nil).Add), arg0)
 }
 
+// Bookmarks mocks base method.
+func (m *Mockdatahandler) Bookmarks(ctx context.Context, channelID string, bookmarks []slack.Bookmark) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Bookmarks", ctx, channelID, bookmarks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Bookmarks indicates an expected call of Bookmarks.
+func (mr *MockdatahandlerMockRecorder) Bookmarks(ctx, channelID, bookmarks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bookmarks", reflect.TypeOf((*Mockdatahandler)(nil).Bookmarks), ctx, channelID, bookmarks)
+}
+
 // ChannelInfo mocks base method.
 func (m *Mockdatahandler) ChannelInfo(ctx context.Context, ci *slack.Channel, threadID string) error {
 	m.ctrl.T.Helper()
@@ -287,6 +301,20 @@ func (mr *MockdatahandlerMockRecorder) N() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "N", reflect.TypeOf((*Mockdatahandler)(nil).N))
 }
 
+// Pins mocks base method.
+func (m *Mockdatahandler) Pins(ctx context.Context, channelID string, items []slack.Item) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pins", ctx, channelID, items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Pins indicates an expected call of Pins.
+func (mr *MockdatahandlerMockRecorder) Pins(ctx, channelID, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pins", reflect.TypeOf((*Mockdatahandler)(nil).Pins), ctx, channelID, items)
+}
+
 // ThreadMessages mocks base method.
 func (m *Mockdatahandler) ThreadMessages(ctx context.Context, channelID string, parent slack.Message, threadOnly, isLast bool, replies []slack.Message) error {
 	m.ctrl.T.Helper()
@@ -14,6 +14,10 @@ type filetracker struct {
 
 	mu    sync.RWMutex                 // guards map operations
 	files map[chunk.FileID]*entityproc // files holds open files along with their processors
+
+	// append makes the tracker append to the existing files, instead of
+	// creating new ones.
+	append bool
 }
 
 // entityproc is a processor for a single entity, which can be a thread or
@@ -41,7 +45,11 @@ func (t *filetracker) create(id chunk.FileID) error {
 		// already exists
 		return nil
 	}
-	bp, err := newDirProc(t.dir, id)
+	newProc := newDirProc
+	if t.append {
+		newProc = appendDirProc
+	}
+	bp, err := newProc(t.dir, id)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,18 @@
+package dirproc
+
+import "github.com/rusq/slackdump/v3/internal/chunk"
+
+// UserGroups is a processor that writes the user groups into the user
+// groups file.
+type UserGroups struct {
+	*dirproc
+}
+
+// NewUserGroups creates a new user groups processor.
+func NewUserGroups(cd *chunk.Directory) (*UserGroups, error) {
+	p, err := newDirProc(cd, chunk.FUserGroups)
+	if err != nil {
+		return nil, err
+	}
+	return &UserGroups{dirproc: p}, nil
+}
@@ -20,6 +20,8 @@ import (
 	"github.com/rusq/slackdump/v3/internal/chunk/state"
 	"github.com/rusq/slackdump/v3/internal/fasttime"
 	"github.com/rusq/slackdump/v3/internal/osext"
+	"github.com/rusq/slackdump/v3/internal/structures"
+	"github.com/rusq/slackdump/v3/types"
 )
 
 var (
@@ -61,8 +63,13 @@ func (idx index) offsetsWithPrefix(prefix string) []int64 {
 	return offsets
 }
 
-// FromReader creates a new chunk File from the io.ReadSeeker.
+// FromReader creates a new chunk File from the io.ReadSeeker.  If the file
+// ends with the index (see [FormatVersion]), it is used, otherwise the file
+// is scanned to build one.
 func FromReader(rs io.ReadSeeker) (*File, error) {
+	if idx, ok := footerIndex(rs); ok {
+		return fromReaderWithIndex(rs, idx)
+	}
 	if _, err := rs.Seek(0, io.SeekStart); err != nil { // reset offset
 		return nil, err
 	}
@@ -172,6 +179,9 @@ func (f *File) ForEach(fn func(ev *Chunk) error) error {
 	// reading from the reader, and any unexpected Seek may cause issues.
 	f.rsMu.Lock()
 	defer f.rsMu.Unlock()
+	if _, err := f.rs.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
 	dec := json.NewDecoder(f.rs)
 	for {
 		var chunk *Chunk
@@ -215,6 +225,10 @@ func (f *File) State() (*state.State, error) {
 			for _, m := range ev.Messages {
 				s.AddMessage(ev.ChannelID, m.Timestamp)
 			}
+		case CAnnotation:
+			if a := ev.Annotation; a != nil {
+				s.AddAnnotation(a.Time, a.Author, a.Text)
+			}
 		}
 		return nil
 	}); err != nil {
@@ -223,6 +237,79 @@ func (f *File) State() (*state.State, error) {
 	return s, nil
 }
 
+// IsComplete returns true if the channel was recorded completely: the last
+// chunk of the channel messages is present, and all threads of the channel
+// messages were recorded up to their last chunk.
+func (f *File) IsComplete(channelID string) (bool, error) {
+	p, err := f.Progress(channelID)
+	if err != nil {
+		return false, err
+	}
+	return p.Complete(), nil
+}
+
+// Progress is the recording progress of the channel, see [File.Progress].
+type Progress struct {
+	// Done is true, if the last chunk of the channel messages was recorded.
+	Done bool
+	// Earliest is the timestamp of the earliest recorded channel message, or
+	// an empty string, if there are none.  The channel messages are fetched
+	// newest first, so, if the recording is not Done, this is where it
+	// stopped.
+	Earliest string
+	// Threads are the timestamps of the threads of the channel messages,
+	// whose replies were not recorded up to the last chunk, sorted.
+	Threads []string
+}
+
+// Complete returns true, if the channel messages and all their threads were
+// recorded completely.
+func (p *Progress) Complete() bool {
+	return p.Done && len(p.Threads) == 0
+}
+
+// Progress returns the recording progress of the channel, it allows to
+// resume the interrupted recording.
+func (f *File) Progress(channelID string) (*Progress, error) {
+	var (
+		p        Progress
+		earliest int64
+		threads  = make(map[string]bool) // thread_ts -> is complete
+	)
+	if err := f.ForEach(func(c *Chunk) error {
+		if c == nil || c.ChannelID != channelID {
+			return nil
+		}
+		switch c.Type {
+		case CMessages:
+			p.Done = p.Done || c.IsLast
+			for i := range c.Messages {
+				m := &c.Messages[i]
+				if ts, err := fasttime.TS2int(m.Timestamp); err == nil && (earliest == 0 || ts < earliest) {
+					earliest, p.Earliest = ts, m.Timestamp
+				}
+				if m.ThreadTimestamp != "" && m.SubType != structures.SubTypeThreadBroadcast && m.LatestReply != structures.LatestReplyNoReplies {
+					if _, ok := threads[m.ThreadTimestamp]; !ok {
+						threads[m.ThreadTimestamp] = false
+					}
+				}
+			}
+		case CThreadMessages:
+			threads[c.ThreadTS] = threads[c.ThreadTS] || c.IsLast
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	for ts, done := range threads {
+		if !done {
+			p.Threads = append(p.Threads, ts)
+		}
+	}
+	sort.Strings(p.Threads)
+	return &p, nil
+}
+
 // AllMessages returns all the messages for the given channel posted to it (no
 // thread).  The messages are in the order as they appear in the file.
 func (f *File) AllMessages(channelID string) ([]slack.Message, error) {
@@ -240,6 +327,71 @@ func (f *File) AllThreadMessages(channelID, threadTS string) ([]slack.Message, e
 	return f.allMessagesForID(threadID(channelID, threadTS))
 }
 
+// AllMessagesMerged returns all the messages of the channel with the thread
+// replies merged inline, sorted by timestamp, oldest first.  Messages that
+// appear both in the channel and in the thread, i.e. thread parents and
+// broadcast replies, are returned once, the channel copy taking precedence.
+// It returns ErrNotFound if there are neither channel nor thread messages.
+func (f *File) AllMessagesMerged(channelID string) ([]slack.Message, error) {
+	f.ensure()
+	// thread IDs are in form "t<channelID>:<threadTS>"
+	threads := f.idx.offsetsWithPrefix(string(threadID(channelID, "")))
+	sort.Slice(threads, func(i, j int) bool { return threads[i] < threads[j] })
+	// channel chunks go first, so that their copies of the messages win.
+	chunks := append(append([]int64{}, f.idx[GroupID(channelID)]...), threads...)
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("messages for %s: %w", channelID, ErrNotFound)
+	}
+
+	type tsmsg struct {
+		ts int64
+		m  slack.Message
+	}
+	var (
+		msgs []tsmsg
+		seen = make(map[string]bool)
+	)
+	add := func(m slack.Message) error {
+		if seen[m.Timestamp] {
+			return nil
+		}
+		ts, err := fasttime.TS2int(m.Timestamp)
+		if err != nil {
+			return fmt.Errorf("message %s:%s: %w", channelID, m.Timestamp, err)
+		}
+		seen[m.Timestamp] = true
+		msgs = append(msgs, tsmsg{ts: ts, m: m})
+		return nil
+	}
+	var parents []slack.Message
+	for _, off := range chunks {
+		c, err := f.chunkAt(off)
+		if err != nil {
+			return nil, err
+		}
+		if c.Type == CThreadMessages && c.Parent != nil {
+			parents = append(parents, *c.Parent)
+		}
+		for _, m := range c.Messages {
+			if err := add(m); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// parents of the threads that were not recorded in the channel.
+	for _, m := range parents {
+		if err := add(m); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(msgs, func(i, j int) bool { return msgs[i].ts < msgs[j].ts })
+	ret := make([]slack.Message, len(msgs))
+	for i := range msgs {
+		ret[i] = msgs[i].m
+	}
+	return ret, nil
+}
+
 // ThreadParent returns the thread parent message for the given thread.  It
 // returns ErrNotFound if the thread is not found.
 func (f *File) ThreadParent(channelID, threadTS string) (*slack.Message, error) {
@@ -257,6 +409,13 @@ func (p *File) AllUsers() ([]slack.User, error) {
 	})
 }
 
+// AllUserGroups returns all user groups in the dump file.
+func (p *File) AllUserGroups() ([]slack.UserGroup, error) {
+	return allForID(p, userGroupID, func(c *Chunk) []slack.UserGroup {
+		return c.UserGroups
+	})
+}
+
 // AllChannels returns all channels collected by listing channels in the dump
 // file.
 func (p *File) AllChannels() ([]slack.Channel, error) {
@@ -265,6 +424,50 @@ func (p *File) AllChannels() ([]slack.Channel, error) {
 	})
 }
 
+// AllFiles returns all files attached to the messages of the channel.  If
+// the channelID is empty, it returns the files for all channels.  Files are
+// returned in the order they were recorded, without duplicates.
+func (f *File) AllFiles(channelID string) ([]slack.File, error) {
+	prefix := filePrefix
+	if channelID != "" {
+		prefix = string(id(filePrefix, channelID, ""))
+	}
+	offsets := f.idx.offsetsWithPrefix(prefix)
+	if len(offsets) == 0 {
+		return nil, ErrNotFound
+	}
+	files, err := allForOffsets(f, offsets, func(c *Chunk) []slack.File {
+		return c.Files
+	})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{}, len(files))
+	ret := files[:0]
+	for _, fl := range files {
+		if _, ok := seen[fl.ID]; ok {
+			continue
+		}
+		seen[fl.ID] = struct{}{}
+		ret = append(ret, fl)
+	}
+	return ret, nil
+}
+
+// AllBookmarks returns all bookmarks of the channel.
+func (f *File) AllBookmarks(channelID string) ([]slack.Bookmark, error) {
+	return allForID(f, id(bookmarkPrefix, channelID), func(c *Chunk) []slack.Bookmark {
+		return c.Bookmarks
+	})
+}
+
+// AllStarredItems returns all starred items in the dump file.
+func (f *File) AllStarredItems() ([]slack.StarredItem, error) {
+	return allForID(f, starredChunkID, func(c *Chunk) []slack.StarredItem {
+		return c.StarredItems
+	})
+}
+
 // AllChannelInfos returns all the channel information collected by the channel
 // info API.
 func (f *File) AllChannelInfos() ([]slack.Channel, error) {
@@ -403,7 +606,7 @@ func (p *File) AllChannelIDs() []string {
 	var ids = make([]string, 0, 1)
 	for gid := range p.idx {
 		id := string(gid)
-		if !strings.Contains(id, ":") && !gid.isInfo() && !gid.isList() && !gid.isSearch() {
+		if !strings.Contains(id, ":") && !gid.isInfo() && !gid.isList() && !gid.isSearch() && !gid.isHistory() {
 			ids = append(ids, id)
 		}
 	}
@@ -411,6 +614,42 @@ func (p *File) AllChannelIDs() []string {
 	return ids
 }
 
+// UserIDs returns the sorted IDs of the users that appear in the chunk
+// file: the authors of the messages, including the thread messages and
+// parents, and the channel members.
+func (f *File) UserIDs() ([]string, error) {
+	seen := make(map[string]struct{})
+	add := func(id string) {
+		if id != "" {
+			seen[id] = struct{}{}
+		}
+	}
+	if err := f.ForEach(func(c *Chunk) error {
+		switch c.Type {
+		case CMessages, CThreadMessages:
+			if c.Parent != nil {
+				add(c.Parent.User)
+			}
+			for i := range c.Messages {
+				add(c.Messages[i].User)
+			}
+		case CChannelUsers:
+			for _, id := range c.ChannelUsers {
+				add(id)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
 // offts is a mapping of chunk offset to the message timestamps it contains,
 // along with some chunk medatata.
 type offts map[int64]offsetInfo
@@ -476,11 +715,18 @@ type Addr struct {
 // timeOffsets returns a map of the timestamp to the chunk offset and index of
 // the message with this timestamp within the message slice.  It converts the
 // string timestamp to an int64 timestamp using structures.TS2int, but the
-// original string timestamp returned in the TimeOffset struct.
+// original string timestamp returned in the TimeOffset struct.  If the
+// message with the same timestamp appears in several chunks, the latest
+// recorded one wins, so that the result does not depend on the map order.
 func timeOffsets(ots offts) map[int64]Addr {
+	offsets := make([]int64, 0, len(ots))
+	for offset := range ots {
+		offsets = append(offsets, offset)
+	}
+	sort.Sort(int64s(offsets))
 	var ret = make(map[int64]Addr, len(ots))
-	for offset, info := range ots {
-		for i, ts := range info.Timestamps {
+	for _, offset := range offsets {
+		for i, ts := range ots[offset].Timestamps {
 			ret[ts] = Addr{
 				Offset: offset,
 				Index:  int16(i),
@@ -543,8 +789,13 @@ func (f *File) Sorted(ctx context.Context, desc bool, fn func(ts time.Time, m *s
 	return nil
 }
 
-// chunkAt returns the chunk at the given offset.
+// chunkAt returns the chunk at the given offset.  If the underlying reader
+// implements io.ReaderAt, i.e. *os.File, the chunks are read concurrently,
+// without seeking.
 func (f *File) chunkAt(offset int64) (*Chunk, error) {
+	if ra, ok := f.rs.(io.ReaderAt); ok {
+		return readChunkAt(ra, offset)
+	}
 	f.rsMu.Lock()
 	defer f.rsMu.Unlock()
 	_, err := f.rs.Seek(offset, io.SeekStart)
@@ -568,3 +819,17 @@ func (f *File) WorkspaceInfo() (*slack.AuthTestResponse, error) {
 
 	return chunk.WorkspaceInfo, nil
 }
+
+// WorkspaceDetails returns the extended workspace information from the
+// chunkfile.  It returns ErrNotFound, if it was not recorded, i.e. the
+// chunkfile was created by an older version.
+func (f *File) WorkspaceDetails() (*types.Workspace, error) {
+	chunk, err := f.firstChunkForID(wspInfoChunkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the workspace details: %w", err)
+	}
+	if chunk.Workspace == nil {
+		return nil, ErrNotFound
+	}
+	return chunk.Workspace, nil
+}
@@ -523,10 +523,27 @@ func Test_timeOffsets(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "duplicate timestamps, latest chunk wins",
+			args: args{
+				ots: offts{
+					2048: offsetInfo{ID: TestChannelID, Timestamps: []int64{1234567890200000, 1234567890300000}},
+					596:  offsetInfo{ID: TestChannelID, Timestamps: []int64{1234567890100000, 1234567890200000}},
+					9000: offsetInfo{ID: TestChannelID, Timestamps: []int64{1234567890200000}},
+				},
+			},
+			want: map[int64]Addr{
+				1234567890100000: {Offset: 596, Index: 0},
+				1234567890200000: {Offset: 9000, Index: 0},
+				1234567890300000: {Offset: 2048, Index: 1},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.want, timeOffsets(tt.args.ots))
+			for range 10 { // map iteration order is random
+				assert.Equal(t, tt.want, timeOffsets(tt.args.ots))
+			}
 		})
 	}
 }
@@ -727,6 +744,32 @@ func TestFile_channelUsers(t *testing.T) {
 	}
 }
 
+func TestFile_UserIDs(t *testing.T) {
+	rs := marshalChunks(
+		Chunk{Type: CChannelUsers, ChannelID: TestChannelID, ChannelUsers: []string{"U3", "U1"}},
+		Chunk{Type: CMessages, ChannelID: TestChannelID, Messages: []slack.Message{
+			{Msg: slack.Msg{Timestamp: "1.0", User: "U2"}},
+			{Msg: slack.Msg{Timestamp: "2.0", BotID: "B1"}},
+		}},
+		Chunk{Type: CThreadMessages, ChannelID: TestChannelID, ThreadTS: "1.0",
+			Parent: &slack.Message{Msg: slack.Msg{Timestamp: "1.0", User: "U2"}},
+			Messages: []slack.Message{
+				{Msg: slack.Msg{Timestamp: "3.0", ThreadTimestamp: "1.0", User: "U4"}},
+			},
+		},
+		Chunk{Type: CUsers, Users: []slack.User{{ID: "U5"}}},
+	)
+	f := &File{rs: rs, idx: mkindex(rs)}
+	got, err := f.UserIDs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"U1", "U2", "U3", "U4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("File.UserIDs() = %v, want %v", got, want)
+	}
+}
+
 func mkindex(rs io.ReadSeeker) index {
 	idx, err := indexChunks(json.NewDecoder(rs))
 	if err != nil {
@@ -870,3 +913,70 @@ func TestFile_ChannelInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestFile_IsComplete(t *testing.T) {
+	parent := slack.Message{Msg: slack.Msg{Timestamp: "1.0", ThreadTimestamp: "1.0", LatestReply: "1.2"}}
+	var (
+		msgs = Chunk{Type: CMessages, ChannelID: TestChannelID, Messages: []slack.Message{parent}}
+		last = Chunk{Type: CMessages, ChannelID: TestChannelID, IsLast: true, Messages: []slack.Message{{Msg: slack.Msg{Timestamp: "0.5"}}}}
+		thr  = Chunk{Type: CThreadMessages, ChannelID: TestChannelID, Parent: &parent, ThreadTS: "1.0", Messages: []slack.Message{{Msg: slack.Msg{Timestamp: "1.1"}}}}
+		thrL = Chunk{Type: CThreadMessages, ChannelID: TestChannelID, Parent: &parent, ThreadTS: "1.0", IsLast: true, Messages: []slack.Message{{Msg: slack.Msg{Timestamp: "1.2"}}}}
+	)
+	tests := []struct {
+		name   string
+		chunks []Chunk
+		want   bool
+	}{
+		{"complete", []Chunk{msgs, thr, last, thrL}, true},
+		{"no last message chunk", []Chunk{msgs, thr, thrL}, false},
+		{"incomplete thread", []Chunk{msgs, thr, last}, false},
+		{"missing thread", []Chunk{msgs, last}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := FromReader(marshalChunks(tt.chunks...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := f.IsComplete(TestChannelID)
+			if err != nil {
+				t.Fatalf("IsComplete() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsComplete() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFile_Progress(t *testing.T) {
+	parent := slack.Message{Msg: slack.Msg{Timestamp: "1700000003.000000", ThreadTimestamp: "1700000003.000000", LatestReply: "1700000004.000000"}}
+	other := slack.Message{Msg: slack.Msg{Timestamp: "1700000002.000000", ThreadTimestamp: "1700000002.000000", LatestReply: "1700000005.000000"}}
+	var (
+		msgs = Chunk{Type: CMessages, ChannelID: TestChannelID, Messages: []slack.Message{parent, other}}
+		thr  = Chunk{Type: CThreadMessages, ChannelID: TestChannelID, Parent: &parent, ThreadTS: parent.Timestamp, IsLast: true, Messages: []slack.Message{{Msg: slack.Msg{Timestamp: "1700000004.000000"}}}}
+		last = Chunk{Type: CMessages, ChannelID: TestChannelID, IsLast: true, Messages: []slack.Message{{Msg: slack.Msg{Timestamp: "1700000001.000000"}}}}
+	)
+	tests := []struct {
+		name   string
+		chunks []Chunk
+		want   *Progress
+	}{
+		{"interrupted", []Chunk{msgs, thr}, &Progress{Earliest: other.Timestamp, Threads: []string{other.Timestamp}}},
+		{"messages done", []Chunk{msgs, thr, last}, &Progress{Done: true, Earliest: "1700000001.000000", Threads: []string{other.Timestamp}}},
+		{"no messages", []Chunk{{Type: CChannelInfo, ChannelID: TestChannelID, Channel: &slack.Channel{}}}, &Progress{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := FromReader(marshalChunks(tt.chunks...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := f.Progress(TestChannelID)
+			if err != nil {
+				t.Fatalf("Progress() error = %v", err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
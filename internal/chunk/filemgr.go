@@ -1,7 +1,6 @@
 package chunk
 
 import (
-	"compress/gzip"
 	"crypto/sha1"
 	"encoding/hex"
 	"errors"
@@ -68,7 +67,8 @@ func (dp *filemgr) Destroy() error {
 // Open opens the file with the given name. If the file is already open, it
 // returns the existing handle. If the file is not open, it opens the
 // compressed file, unpacks it into a temporary file, and returns the handle.
-// The file is expected to be a gzip-compressed file.
+// The file is expected to be a gzip or zstd-compressed file, see
+// [compressionOf].
 func (dp *filemgr) Open(name string) (*wrappedfile, error) {
 	// create the directory if it doesn't exist
 	if err := os.MkdirAll(dp.tmpdir, 0o755); err != nil {
@@ -94,17 +94,17 @@ func (dp *filemgr) Open(name string) (*wrappedfile, error) {
 		return nil, err
 	}
 	defer cf.Close()
-	gz, err := gzip.NewReader(cf)
+	zr, err := compressionOf(name).NewReader(cf)
 	if err != nil {
 		return nil, err
 	}
-	defer gz.Close()
+	defer zr.Close()
 	// create a temporary file
 	tf, err := os.CreateTemp(dp.tmpdir, "filemgr-*")
 	if err != nil {
 		return nil, err
 	}
-	if _, err = io.Copy(tf, gz); err != nil {
+	if _, err = io.Copy(tf, zr); err != nil {
 		return nil, err
 	}
 	if err := tf.Sync(); err != nil {
@@ -122,6 +122,15 @@ func (dp *filemgr) Open(name string) (*wrappedfile, error) {
 	}, nil
 }
 
+// Forget removes the unpacked copy of the file with the given name from the
+// cache, so that it is unpacked again on the next Open, i.e. once the
+// compressed file is modified.  The open handles are not affected.
+func (dp *filemgr) Forget(name string) {
+	dp.mu.Lock()
+	defer dp.mu.Unlock()
+	delete(dp.known, hash(name))
+}
+
 // wrappedfile is a struct that wraps an os.File and holds a reference to the
 // file manager.
 type wrappedfile struct {
@@ -0,0 +1,126 @@
+package chunk
+
+// In this file: the index in the trailer of the chunk file (format v2).
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+)
+
+// FormatVersion is the current version of the chunk file format.
+//
+// Version 1 is the JSONL stream of chunks, that must be scanned and decoded
+// in full to build the index of the file.
+//
+// Version 2 adds the index (group ID → chunk offsets) to the trailer chunk,
+// that ends the recording, so that the file can be opened by reading only
+// the last line.  The trailer records its own offset, the index is used only
+// if it matches the actual offset of the trailer in the file, otherwise, i.e.
+// if several recordings were concatenated, or the file was edited, the file
+// is scanned as version 1.  Version 1 readers ignore the index.
+const FormatVersion = 2
+
+const (
+	footerBlockSize = 64 << 10
+	// maxFooterSize is the maximum size of the trailer, that is read from
+	// the end of the file.
+	maxFooterSize = 256 << 20
+)
+
+var errFooterSize = errors.New("trailer is too large")
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// footerIndex returns the index from the trailer at the end of the file, if
+// the file is in format v2, and the index matches the file.
+func footerIndex(rs io.ReadSeeker) (index, bool) {
+	lg := slog.Default()
+	line, offset, err := lastLine(rs)
+	if err != nil {
+		lg.Debug("unable to read the chunk file trailer", "error", err)
+		return nil, false
+	}
+	var c Chunk
+	if err := json.Unmarshal(line, &c); err != nil || c.Type != CTrailer || c.Trailer == nil {
+		return nil, false // v1 or not closed.
+	}
+	tr := c.Trailer
+	if tr.Version < FormatVersion || tr.Index == nil {
+		return nil, false
+	}
+	if tr.Offset != offset {
+		lg.Debug("chunk file index does not match the file, scanning", "trailer_offset", tr.Offset, "offset", offset)
+		return nil, false
+	}
+	idx := make(index, len(tr.Index)+1)
+	for id, offsets := range tr.Index {
+		for _, off := range offsets {
+			if off < 0 || off >= offset {
+				lg.Debug("chunk file index is invalid, scanning", "id", id, "offset", off)
+				return nil, false
+			}
+		}
+		idx[id] = offsets
+	}
+	idx[trailerChunkID] = append(idx[trailerChunkID], offset)
+	return idx, true
+}
+
+// lastLine returns the last non-empty line of the file, and its offset.
+func lastLine(rs io.ReadSeeker) ([]byte, int64, error) {
+	size, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, 0, err
+	}
+	var (
+		blocks [][]byte // in reverse order
+		total  int64
+		pos    = size
+	)
+	for pos > 0 {
+		n := min(int64(footerBlockSize), pos)
+		pos -= n
+		b := make([]byte, n)
+		if _, err := rs.Seek(pos, io.SeekStart); err != nil {
+			return nil, 0, err
+		}
+		if _, err := io.ReadFull(rs, b); err != nil {
+			return nil, 0, err
+		}
+		search := b
+		if pos+n == size && b[n-1] == '\n' {
+			search = b[:n-1] // the line terminator
+		}
+		i := bytes.LastIndexByte(search, '\n')
+		if i >= 0 {
+			b = b[i+1:]
+			pos += int64(i) + 1
+		}
+		blocks = append(blocks, b)
+		total += int64(len(b))
+		if i >= 0 {
+			break
+		}
+		if total > maxFooterSize {
+			return nil, 0, errFooterSize
+		}
+	}
+	line := make([]byte, 0, total)
+	for i := len(blocks) - 1; i >= 0; i-- {
+		line = append(line, blocks[i]...)
+	}
+	return line, pos, nil
+}
@@ -0,0 +1,151 @@
+package chunk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRecording returns the recording with the chunks of different types.
+func testRecording(t *testing.T) []byte {
+	t.Helper()
+	ctx := context.Background()
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf)
+	require.NoError(t, rec.Users(ctx, []slack.User{{ID: "U1"}}))
+	require.NoError(t, rec.Channels(ctx, []slack.Channel{{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: TestChannelID}}}}))
+	require.NoError(t, rec.Messages(ctx, TestChannelID, 1, false, []slack.Message{testMsg("U1", "1700000001.000000")}))
+	parent := testMsg("U1", "1700000001.000000")
+	parent.ThreadTimestamp = parent.Timestamp
+	require.NoError(t, rec.ThreadMessages(ctx, TestChannelID, parent, false, true, []slack.Message{testMsg("U1", "1700000002.000000")}))
+	require.NoError(t, rec.Messages(ctx, TestChannelID, 0, true, []slack.Message{testMsg("U1", "1700000003.000000")}))
+	require.NoError(t, rec.Close())
+	return buf.Bytes()
+}
+
+// scanIndex returns the index of the data built by the full scan.
+func scanIndex(t *testing.T, data []byte) index {
+	t.Helper()
+	idx, err := indexChunks(json.NewDecoder(bytes.NewReader(data)))
+	require.NoError(t, err)
+	return idx
+}
+
+// lineIndex returns the index of the data built by the full scan, with the
+// offsets of the line starts.  The decoder offsets point to the newline
+// before the chunk.
+func lineIndex(t *testing.T, data []byte) index {
+	t.Helper()
+	idx := scanIndex(t, data)
+	for _, offsets := range idx {
+		for i := range offsets {
+			for data[offsets[i]] == '\n' {
+				offsets[i]++
+			}
+		}
+	}
+	return idx
+}
+
+func TestFromReader_footerIndex(t *testing.T) {
+	data := testRecording(t)
+
+	idx, ok := footerIndex(bytes.NewReader(data))
+	require.True(t, ok, "recording must have the index")
+	assert.Equal(t, lineIndex(t, data), idx)
+
+	f, err := FromReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	mm, err := f.AllMessages(TestChannelID)
+	require.NoError(t, err)
+	assert.Len(t, mm, 2)
+	tm, err := f.AllThreadMessages(TestChannelID, "1700000001.000000")
+	require.NoError(t, err)
+	assert.Len(t, tm, 1)
+	_, err = f.Trailer()
+	assert.NoError(t, err)
+
+	r, err := Audit(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.True(t, r.OK(), "index must not break the chain: %v", r.Breaks)
+}
+
+func TestFromReader_v1(t *testing.T) {
+	// v1 recording has no index in the trailer.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	dec := json.NewDecoder(bytes.NewReader(testRecording(t)))
+	for {
+		var c Chunk
+		if err := dec.Decode(&c); err != nil {
+			break
+		}
+		if c.Trailer != nil {
+			c.Trailer.Version, c.Trailer.Offset, c.Trailer.Index = 0, 0, nil
+		}
+		require.NoError(t, enc.Encode(c))
+	}
+	data := buf.Bytes()
+	_, ok := footerIndex(bytes.NewReader(data))
+	assert.False(t, ok)
+
+	f, err := FromReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, scanIndex(t, data), f.idx)
+}
+
+func TestFromReader_concatenated(t *testing.T) {
+	// the index of the second recording does not cover the first one.
+	rec := testRecording(t)
+	data := append(append([]byte{}, rec...), rec...)
+	_, ok := footerIndex(bytes.NewReader(data))
+	assert.False(t, ok)
+
+	f, err := FromReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, scanIndex(t, data), f.idx)
+	mm, err := f.AllMessages(TestChannelID)
+	require.NoError(t, err)
+	assert.Len(t, mm, 4)
+}
+
+func TestFromReader_notClosed(t *testing.T) {
+	rec := testRecording(t)
+	data := rec[:bytes.LastIndexByte(rec[:len(rec)-1], '\n')+1] // no trailer
+	_, ok := footerIndex(bytes.NewReader(data))
+	assert.False(t, ok)
+	f, err := FromReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, scanIndex(t, data), f.idx)
+}
+
+func Test_lastLine(t *testing.T) {
+	long := strings.Repeat("x", 3*footerBlockSize+10)
+	tests := []struct {
+		name       string
+		data       string
+		wantLine   string
+		wantOffset int64
+	}{
+		{"empty", "", "", 0},
+		{"single line", "abc\n", "abc", 0},
+		{"no terminator", "abc\ndef", "def", 4},
+		{"two lines", "abc\ndef\n", "def", 4},
+		{"long line", "abc\n" + long + "\n", long, 4},
+		{"long first line", long + "\ndef\n", "def", int64(len(long) + 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, off, err := lastLine(strings.NewReader(tt.data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLine, strings.TrimSuffix(string(line), "\n"))
+			assert.Equal(t, tt.wantOffset, off)
+		})
+	}
+}
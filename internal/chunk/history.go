@@ -0,0 +1,52 @@
+package chunk
+
+import (
+	"context"
+	"time"
+
+	"github.com/rusq/slack"
+)
+
+// MessageEdited records the version of the message before the edit, so that
+// the recording retains the history of the edits.
+func (rec *Recorder) MessageEdited(ctx context.Context, channelID string, prev slack.Message) error {
+	return rec.history(CMessageEdit, channelID, prev)
+}
+
+// MessageDeleted records the tombstone of the deleted message, with the last
+// known version of the message.
+func (rec *Recorder) MessageDeleted(ctx context.Context, channelID string, prev slack.Message) error {
+	return rec.history(CMessageDeleted, channelID, prev)
+}
+
+func (rec *Recorder) history(typ ChunkType, channelID string, m slack.Message) error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	chunk := Chunk{
+		Type:      typ,
+		Timestamp: time.Now().UnixNano(),
+		ChannelID: channelID,
+		ThreadTS:  m.ThreadTimestamp,
+		Count:     1,
+		Messages:  []slack.Message{m},
+	}
+	return rec.encode(chunk)
+}
+
+// MessageEdits returns the prior versions of the edited messages of the
+// channel, in the order the edits were recorded.  It returns ErrNotFound, if
+// there are none.
+func (f *File) MessageEdits(channelID string) ([]slack.Message, error) {
+	return allForID(f, id(editPrefix, channelID), func(c *Chunk) []slack.Message {
+		return c.Messages
+	})
+}
+
+// DeletedMessages returns the last known versions of the deleted messages
+// of the channel, in the order the deletions were recorded.  It returns
+// ErrNotFound, if there are none.
+func (f *File) DeletedMessages(channelID string) ([]slack.Message, error) {
+	return allForID(f, id(deletedPrefix, channelID), func(c *Chunk) []slack.Message {
+		return c.Messages
+	})
+}
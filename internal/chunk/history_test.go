@@ -0,0 +1,54 @@
+package chunk
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder_history(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf)
+	orig := testMsg("U1", "1700000001.000000")
+	if err := rec.Messages(ctx, TestChannelID, 0, true, []slack.Message{orig}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.MessageEdited(ctx, TestChannelID, orig); err != nil {
+		t.Fatal(err)
+	}
+	deleted := testMsg("U2", "1700000002.000000")
+	if err := rec.MessageDeleted(ctx, TestChannelID, deleted); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := FromReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	edits, err := f.MessageEdits(TestChannelID)
+	if err != nil {
+		t.Fatalf("MessageEdits() error = %v", err)
+	}
+	assert.Equal(t, []slack.Message{orig}, edits)
+	dd, err := f.DeletedMessages(TestChannelID)
+	if err != nil {
+		t.Fatalf("DeletedMessages() error = %v", err)
+	}
+	assert.Equal(t, []slack.Message{deleted}, dd)
+
+	// history chunks must not be mistaken for the channel messages.
+	mm, err := f.AllMessages(TestChannelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []slack.Message{orig}, mm)
+	ids := f.AllChannelIDs()
+	assert.Equal(t, []string{TestChannelID}, ids)
+}
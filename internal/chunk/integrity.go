@@ -0,0 +1,240 @@
+package chunk
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	gohash "hash"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrIntegrity is returned when the chunk file fails the integrity check.
+var ErrIntegrity = errors.New("chunk file integrity check failed")
+
+// chainEncoder encodes chunks linking each chunk to the previous one: it sets
+// the sequence number of the chunk and the hash of the previous chunk line.
+// This allows to detect truncated or manually edited recordings.  Values
+// other than Chunk are encoded as is.
+//
+// It also indexes the chunks, and writes the index into the trailer chunk,
+// see [FormatVersion].
+type chainEncoder struct {
+	h    gohash.Hash
+	cw   *countingWriter
+	enc  *json.Encoder
+	seq  int64
+	prev string
+	idx  index // nil, if the index is not valid.
+}
+
+func newChainEncoder(w io.Writer) *chainEncoder {
+	h := sha256.New()
+	cw := &countingWriter{w: w}
+	return &chainEncoder{
+		h:   h,
+		cw:  cw,
+		enc: json.NewEncoder(io.MultiWriter(cw, h)),
+		idx: make(index),
+	}
+}
+
+// Encode implements the Encoder interface.
+func (ce *chainEncoder) Encode(v interface{}) error {
+	var c *Chunk
+	switch cv := v.(type) {
+	case Chunk:
+		c = &cv
+	case *Chunk:
+		cc := *cv // don't modify the caller's chunk
+		c = &cc
+	default:
+		return ce.enc.Encode(v)
+	}
+	c.Seq = ce.seq + 1
+	c.Prev = ce.prev
+	offset := ce.cw.n
+	if c.Type == CTrailer && c.Trailer != nil && ce.idx != nil {
+		tr := *c.Trailer // don't modify the caller's trailer
+		tr.Version, tr.Offset, tr.Index = FormatVersion, offset, ce.idx
+		c.Trailer = &tr
+	}
+	ce.h.Reset()
+	if err := ce.enc.Encode(c); err != nil {
+		ce.idx = nil // offsets of the following chunks are unknown.
+		return err
+	}
+	if ce.idx != nil {
+		id := c.ID()
+		ce.idx[id] = append(ce.idx[id], offset)
+	}
+	ce.seq = c.Seq
+	ce.prev = hex.EncodeToString(ce.h.Sum(nil))
+	return nil
+}
+
+// Break describes the break in the chain of chunks.
+type Break struct {
+	// Offset is the offset of the chunk in the file.
+	Offset int64
+	// Seq is the sequence number of the chunk, if known.
+	Seq int64
+	// Reason is the human readable description of the break.
+	Reason string
+}
+
+func (b Break) String() string {
+	return fmt.Sprintf("offset %d, seq %d: %s", b.Offset, b.Seq, b.Reason)
+}
+
+// AuditReport is the result of the chunk file integrity check.
+type AuditReport struct {
+	// Chunks is the total number of chunks in the file.
+	Chunks int
+	// Segments is the number of recordings in the file.  A file may contain
+	// several recordings, if it was appended to.
+	Segments int
+	// Unchained is the number of chunks that don't have the sequence
+	// number, i.e. recorded by the older version.  They are not verified.
+	Unchained int
+	// Breaks is the list of integrity breaks found.
+	Breaks []Break
+}
+
+// OK returns true if no breaks were found.
+func (r *AuditReport) OK() bool {
+	return len(r.Breaks) == 0
+}
+
+// Audit verifies the continuity of the chunk chain in the file, see [Audit].
+func (f *File) Audit() (*AuditReport, error) {
+	f.rsMu.Lock()
+	defer f.rsMu.Unlock()
+	if _, err := f.rs.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return Audit(f.rs)
+}
+
+// Audit verifies the continuity of the chunk chain in the reader: that
+// sequence numbers are consecutive, that each chunk references the hash of
+// the previous one, and that each recording ends with a trailer, which the
+// Recorder writes on Close.  Breaks in the chain indicate truncation or manual
+// edits of the recording.  Unlike [FromReader], it does not fail on malformed
+// chunks, reporting them as breaks instead.
+func Audit(rd io.Reader) (*AuditReport, error) {
+	var (
+		r        AuditReport
+		br       = bufio.NewReader(rd)
+		offset   int64
+		prevSeq  int64
+		prevHash string
+		lastType ChunkType
+	)
+	unclosed := func(off int64) {
+		if prevSeq > 0 && lastType != CTrailer {
+			r.Breaks = append(r.Breaks, Break{Offset: off, Seq: prevSeq, Reason: "recording is not closed, it may be truncated"})
+		}
+	}
+	for {
+		line, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			var hdr struct {
+				Type ChunkType `json:"t"`
+				Seq  int64     `json:"q"`
+				Prev string    `json:"h"`
+			}
+			r.Chunks++
+			if jerr := json.Unmarshal(line, &hdr); jerr != nil {
+				r.Breaks = append(r.Breaks, Break{Offset: offset, Seq: prevSeq + 1, Reason: "invalid chunk: " + jerr.Error()})
+				// assume that this is the chunk that we expected, so that
+				// the next one is checked against it.
+				hdr.Seq, hdr.Type = prevSeq+1, CMessages
+			} else {
+				switch {
+				case hdr.Seq == 0:
+					r.Unchained++
+				case hdr.Seq == 1 && hdr.Prev == "":
+					unclosed(offset)
+					r.Segments++
+				default:
+					if hdr.Seq != prevSeq+1 {
+						r.Breaks = append(r.Breaks, Break{Offset: offset, Seq: hdr.Seq, Reason: fmt.Sprintf("sequence gap: expected %d", prevSeq+1)})
+					}
+					if hdr.Prev != prevHash {
+						r.Breaks = append(r.Breaks, Break{Offset: offset, Seq: hdr.Seq, Reason: "previous chunk hash mismatch"})
+					}
+				}
+			}
+			if hdr.Seq > 0 {
+				sum := sha256.Sum256(line)
+				prevSeq, prevHash, lastType = hdr.Seq, hex.EncodeToString(sum[:]), hdr.Type
+			}
+		}
+		offset += int64(len(line))
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+	unclosed(offset)
+	return &r, nil
+}
+
+// Verify checks the integrity of the chunk file that the Player is reading
+// from.  It returns an error wrapping ErrIntegrity with the first break
+// found.  [NewPlayer] verifies the file on creation, if [WithVerify] is given.
+func (p *Player) Verify() error {
+	return verify(p.f)
+}
+
+// Verify checks the integrity of the chunk file, see [Player.Verify].
+func (f *File) Verify() error {
+	return verify(f)
+}
+
+// verify checks the integrity of the chunk file f, see [Player.Verify].
+func verify(f *File) error {
+	r, err := f.Audit()
+	if err != nil {
+		return err
+	}
+	if !r.OK() {
+		return fmt.Errorf("%w: %s (%d breaks total)", ErrIntegrity, r.Breaks[0], len(r.Breaks))
+	}
+	return nil
+}
+
+// Verify checks the integrity of all chunk files in the directory.  It
+// returns an error wrapping ErrIntegrity with the first break found, and the
+// name of the file.
+func (d *Directory) Verify() error {
+	des, err := os.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+	for _, de := range des {
+		id, ok := FileIDOf(de.Name())
+		if !ok || de.IsDir() {
+			continue
+		}
+		name := filepath.Join(d.dir, de.Name())
+		f, err := d.Open(id)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		err = verify(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package chunk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+// recording returns a closed recording with a few chunks.
+func recording(t *testing.T) []byte {
+	t.Helper()
+	ctx := context.Background()
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf)
+	for _, ts := range []string{"1700000001.000000", "1700000002.000000", "1700000003.000000"} {
+		if err := rec.Messages(ctx, TestChannelID, 0, false, []slack.Message{testMsg("U1", ts)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func audit(t *testing.T, data []byte) *AuditReport {
+	t.Helper()
+	f, err := FromReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := f.Audit()
+	if err != nil {
+		t.Fatalf("Audit() error = %v", err)
+	}
+	return r
+}
+
+func TestFile_Audit(t *testing.T) {
+	data := recording(t)
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	lines = lines[:len(lines)-1] // last one is empty
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 chunks, got %d", len(lines))
+	}
+	join := func(ll ...[]byte) []byte { return bytes.Join(ll, nil) }
+
+	t.Run("intact", func(t *testing.T) {
+		r := audit(t, data)
+		assert.True(t, r.OK(), r.Breaks)
+		assert.Equal(t, 4, r.Chunks)
+		assert.Equal(t, 1, r.Segments)
+	})
+	t.Run("appended recording", func(t *testing.T) {
+		r := audit(t, join(data, data))
+		assert.True(t, r.OK(), r.Breaks)
+		assert.Equal(t, 2, r.Segments)
+	})
+	t.Run("edited", func(t *testing.T) {
+		edited := bytes.Replace(lines[1], []byte("U1"), []byte("U2"), 1)
+		r := audit(t, join(lines[0], edited, lines[2], lines[3]))
+		if assert.Len(t, r.Breaks, 1) {
+			assert.Equal(t, int64(3), r.Breaks[0].Seq)
+		}
+	})
+	t.Run("removed chunk", func(t *testing.T) {
+		r := audit(t, join(lines[0], lines[2], lines[3]))
+		assert.Len(t, r.Breaks, 2) // sequence gap and hash mismatch
+	})
+	t.Run("truncated", func(t *testing.T) {
+		r := audit(t, join(lines[0], lines[1], lines[2]))
+		if assert.Len(t, r.Breaks, 1) {
+			assert.Contains(t, r.Breaks[0].Reason, "not closed")
+		}
+	})
+	t.Run("player", func(t *testing.T) {
+		if _, err := NewPlayer(bytes.NewReader(join(lines[0], lines[2], lines[3])), WithVerify(true)); !errors.Is(err, ErrIntegrity) {
+			t.Errorf("NewPlayer() error = %v, want %v", err, ErrIntegrity)
+		}
+		// without the trailer, i.e. an interrupted recording.
+		p, err := NewPlayer(bytes.NewReader(join(lines[:len(lines)-1]...)))
+		if err != nil {
+			t.Fatalf("NewPlayer() error = %v, want nil", err)
+		}
+		if err := p.Verify(); !errors.Is(err, ErrIntegrity) {
+			t.Errorf("Verify() error = %v, want %v", err, ErrIntegrity)
+		}
+		p, err = NewPlayer(bytes.NewReader(join(lines...)), WithVerify(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Verify(); err != nil {
+			t.Errorf("Verify() error = %v, want nil", err)
+		}
+	})
+}
+
+func TestDirectory_Verify(t *testing.T) {
+	data := recording(t)
+	cd, err := CreateDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cd.Close()
+	write := func(id FileID, data []byte) {
+		t.Helper()
+		w, err := cd.Create(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("C1", data)
+	if err := cd.Verify(); err != nil {
+		t.Errorf("Verify() error = %v, want nil", err)
+	}
+	write("C2", bytes.Replace(data, []byte("U1"), []byte("U2"), 1))
+	if err := cd.Verify(); !errors.Is(err, ErrIntegrity) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrIntegrity)
+	}
+}
+
+func TestRecorder_Close_empty(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf)
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Close() wrote %q on the empty recording, want nothing", buf.String())
+	}
+}
@@ -1,18 +1,17 @@
 package obfuscate
 
 import (
-	"compress/gzip"
 	"context"
 	"fmt"
 	"log/slog"
 	"math/rand"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/osext"
 )
 
 // DoDir obfuscates all files in the directory src, placing obfuscated
@@ -34,14 +33,16 @@ func DoDir(ctx context.Context, src string, trg string, options ...Option) error
 
 	rng := rand.New(rand.NewSource(opts.seed))
 	var obf = newObfuscator(rng)
+	obf.text = opts.text
 
 	var once sync.Once
 	for _, f := range files {
 		if f.IsDir() {
 			continue
 		}
-		if !strings.HasSuffix(f.Name(), ".json.gz") {
+		if _, ok := chunk.FileIDOf(f.Name()); !ok {
 			lg.DebugContext(ctx, "skipping", "filename", f.Name())
+			continue
 		}
 		lg.DebugContext(ctx, "processing %s", "filename", f.Name())
 		once.Do(func() {
@@ -57,20 +58,22 @@ func DoDir(ctx context.Context, src string, trg string, options ...Option) error
 	return nil
 }
 
-// doFile obfuscates the file src, placing the obfuscated file in trg.
+// doFile obfuscates the file src, placing the obfuscated file in trg.  The
+// obfuscated file has the same compression as src.
 func doFile(ctx context.Context, obf obfuscator, trgDir string, src string) error {
 	f, err := os.Open(src)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
-	in, err := gzip.NewReader(f)
+	comp := osext.CompressionOf(src)
+	in, err := comp.NewReader(f)
 	if err != nil {
 		return err
 	}
 	defer in.Close()
 
-	fileid := chunk.FileID(strings.TrimSuffix(filepath.Base(src), ".json.gz"))
+	fileid, _ := chunk.FileIDOf(src)
 	switch fileid {
 	case "users", "channels", "workspace":
 	default:
@@ -78,12 +81,15 @@ func doFile(ctx context.Context, obf obfuscator, trgDir string, src string) erro
 		channel, thread := fileid.Split()
 		fileid = chunk.ToFileID(obf.ChannelID(channel), thread, len(thread) > 0) // export won't have a thread
 	}
-	w, err := os.Create(filepath.Join(trgDir, string(fileid)+".json.gz"))
+	w, err := os.Create(filepath.Join(trgDir, comp.WithExt(string(fileid)+".json")))
 	if err != nil {
 		return err
 	}
 	defer w.Close()
-	out := gzip.NewWriter(w)
+	out, err := comp.NewWriter(w)
+	if err != nil {
+		return err
+	}
 	defer out.Close()
 
 	return obfuscate(ctx, obf, out, in)
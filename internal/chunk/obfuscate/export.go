@@ -0,0 +1,211 @@
+package obfuscate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math/rand"
+	"path"
+	"runtime/trace"
+	"strings"
+	"time"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/export"
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+// channel index files of the export.
+var exportChannelFiles = []string{"channels.json", "groups.json", "mpims.json"}
+
+const (
+	exportUsersFile = "users.json"
+	exportDMsFile   = "dms.json"
+)
+
+// DoExport obfuscates the Slack export in fsys, writing the obfuscated export
+// to trg.  The channel directories are renamed according to the obfuscated
+// channel names.  File attachments and other files that are not part of the
+// export structure are not copied.
+func DoExport(ctx context.Context, fsys fs.FS, trg fsadapter.FS, options ...Option) error {
+	ctx, task := trace.NewTask(ctx, "obfuscate.DoExport")
+	defer task.End()
+
+	var opts = doOpts{
+		seed: time.Now().UnixNano(),
+	}
+	for _, optFn := range options {
+		optFn(&opts)
+	}
+	rng := rand.New(rand.NewSource(opts.seed))
+	obf := newObfuscator(rng)
+	obf.text = opts.text
+
+	lg := slog.Default()
+
+	// dirs maps the original channel directory name to the obfuscated one.
+	dirs := make(map[string]string)
+	for _, name := range exportChannelFiles {
+		var cc []slack.Channel
+		if err := readJSON(fsys, name, &cc); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		orig := make([]string, len(cc))
+		for i := range cc {
+			orig[i] = exportDir(&cc[i])
+		}
+		obf.Channels(cc...)
+		for i := range cc {
+			dirs[orig[i]] = exportDir(&cc[i])
+		}
+		if err := writeJSON(trg, name, cc); err != nil {
+			return err
+		}
+	}
+	var dms []structures.DM
+	if err := readJSON(fsys, exportDMsFile, &dms); err == nil {
+		for i := range dms {
+			id := obf.ChannelID(dms[i].ID)
+			dirs[dms[i].ID] = id
+			dms[i].ID = id
+			obf.ChannelUsers(dms[i].Members)
+		}
+		if err := writeJSON(trg, exportDMsFile, dms); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	var users []slack.User
+	if err := readJSON(fsys, exportUsersFile, &users); err == nil {
+		obf.Users(users...)
+		if err := writeJSON(trg, exportUsersFile, users); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return fs.WalkDir(fsys, ".", func(pth string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		dir, name := path.Split(pth)
+		dir = strings.TrimSuffix(dir, "/")
+		if dir == "" || strings.Contains(dir, "/") || path.Ext(name) != ".json" {
+			// index files are already processed, attachments are skipped.
+			if dir != "" {
+				lg.DebugContext(ctx, "skipping", "filename", pth)
+			}
+			return nil
+		}
+		newDir, ok := dirs[dir]
+		if !ok {
+			newDir = obf.ID("", dir)
+		}
+		if err := doExportDay(obf, fsys, trg, pth, path.Join(newDir, name)); err != nil {
+			return fmt.Errorf("error on file %s: %w", pth, err)
+		}
+		return nil
+	})
+}
+
+// exportDir returns the name of the channel directory in the export.
+func exportDir(ch *slack.Channel) string {
+	if ch.IsIM {
+		return ch.ID
+	}
+	return ch.Name
+}
+
+// doExportDay obfuscates the messages of one day of the channel.
+func doExportDay(obf obfuscator, fsys fs.FS, trg fsadapter.FS, src, dst string) error {
+	var mm []export.ExportMessage
+	if err := readJSON(fsys, src, &mm); err != nil {
+		return err
+	}
+	for i := range mm {
+		obf.ExportMessage(&mm[i])
+	}
+	return writeJSON(trg, dst, mm)
+}
+
+// ExportMessage obfuscates the export message, including the user profile.
+func (o obfuscator) ExportMessage(em *export.ExportMessage) {
+	var userID string // original user ID, to generate the consistent names
+	if em.Msg != nil {
+		userID = em.User
+		m := slack.Message{Msg: *em.Msg}
+		o.OneMessage(&m)
+		*em.Msg = m.Msg
+	}
+	for _, nested := range []*slack.Msg{em.SubMessage, em.PreviousMessage} {
+		if nested == nil {
+			continue
+		}
+		m := slack.Message{Msg: *nested}
+		o.OneMessage(&m)
+		*nested = m.Msg
+	}
+	em.UserTeam = o.TeamID(em.UserTeam)
+	em.SourceTeam = o.TeamID(em.SourceTeam)
+	up := em.UserProfile
+	if up == nil {
+		return
+	}
+	if o.random() {
+		up.FirstName = o.randomString(len(up.FirstName))
+		up.RealName = o.randomStringExact(len(up.RealName))
+		up.DisplayName = o.randomStringExact(len(up.DisplayName))
+	} else {
+		up.FirstName = o.personal(userID, up.FirstName, func(p person) string { return p.First })
+		up.RealName = o.personal(userID, up.RealName, person.RealName)
+		up.DisplayName = o.personal(userID, up.DisplayName, func(p person) string { return p.First })
+	}
+	if o.text == TMFake {
+		up.Name = notNilFn(up.Name, func(string) string { return o.fakePerson(userID).Username() })
+	} else {
+		up.Name = o.ID("", up.Name)
+	}
+	up.AvatarHash = ""
+	up.Image72 = ""
+	up.Team = o.TeamID(up.Team)
+}
+
+func readJSON(fsys fs.FS, name string, v any) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewDecoder(f).Decode(v)
+}
+
+func writeJSON(trg fsadapter.FS, name string, v any) error {
+	wc, err := trg.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(wc)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		wc.Close()
+		return err
+	}
+	return wc.Close()
+}
@@ -0,0 +1,120 @@
+package obfuscate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/export"
+)
+
+var testExport = fstest.MapFS{
+	"channels.json": {Data: []byte(`[{"id":"C01234567","name":"general","creator":"U01234567"}]`)},
+	"dms.json":      {Data: []byte(`[{"id":"D01234567","created":1,"members":["U01234567","U07654321"]}]`)},
+	"users.json": {Data: []byte(`[{"id":"U01234567","name":"john","real_name":"John Smith",` +
+		`"profile":{"email":"john@example.com","real_name":"John Smith","first_name":"John"}}]`)},
+	"general/2024-01-01.json": {Data: []byte(`[{"type":"message","user":"U01234567","ts":"1.0",` +
+		`"text":"hi <@U07654321> see <#C01234567|general>","user_profile":{"real_name":"John Smith","name":"john"}}]`)},
+	"D01234567/2024-01-01.json": {Data: []byte(`[{"type":"message","user":"U07654321","text":"secret","ts":"2.0"}]`)},
+	"__uploads/F1/file.txt":     {Data: []byte("attachment")},
+}
+
+func readTestJSON(t *testing.T, name string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDoExport(t *testing.T) {
+	for _, mode := range []TextMode{TMRandom, TMHash, TMRedact, TMFake} {
+		t.Run(string(mode), func(t *testing.T) {
+			dir := t.TempDir()
+			if err := DoExport(context.Background(), testExport, fsadapter.NewDirectory(dir), WithSeed(1), WithTextMode(mode)); err != nil {
+				t.Fatal(err)
+			}
+			var (
+				channels []slack.Channel
+				users    []slack.User
+			)
+			readTestJSON(t, filepath.Join(dir, "channels.json"), &channels)
+			readTestJSON(t, filepath.Join(dir, "users.json"), &users)
+			if len(channels) != 1 || len(users) != 1 {
+				t.Fatalf("unexpected number of channels or users: %d, %d", len(channels), len(users))
+			}
+			var mm []export.ExportMessage
+			readTestJSON(t, filepath.Join(dir, channels[0].Name, "2024-01-01.json"), &mm)
+			if len(mm) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(mm))
+			}
+			m := mm[0]
+			if m.User != users[0].ID {
+				t.Errorf("message user %q does not match the user %q", m.User, users[0].ID)
+			}
+			if m.UserProfile.RealName != users[0].RealName && mode != TMRandom {
+				t.Errorf("profile name %q does not match the user name %q", m.UserProfile.RealName, users[0].RealName)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "__uploads")); err == nil {
+				t.Error("attachments must not be copied")
+			}
+			if strings.Contains(m.Text, "hi") {
+				t.Errorf("text is not replaced: %q", m.Text)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, "users.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, pii := range []string{"John", "john@example.com", "U01234567"} {
+				if strings.Contains(string(data), pii) {
+					t.Errorf("users.json contains %q", pii)
+				}
+			}
+			if mode == TMFake {
+				if !strings.Contains(m.Text, "<@"+userPrefix) || !strings.Contains(m.Text, "<#"+channels[0].ID+">") {
+					t.Errorf("mentions are not preserved: %q", m.Text)
+				}
+			}
+			var dms []struct{ ID string }
+			readTestJSON(t, filepath.Join(dir, "dms.json"), &dms)
+			if _, err := os.Stat(filepath.Join(dir, dms[0].ID, "2024-01-01.json")); err != nil {
+				t.Errorf("DM directory is not renamed: %v", err)
+			}
+		})
+	}
+}
+
+func TestTextMode_Set(t *testing.T) {
+	var m TextMode
+	if m.String() != "random" {
+		t.Errorf("zero value = %q, want random", m)
+	}
+	if err := m.Set("FAKE"); err != nil || m != TMFake {
+		t.Errorf("Set(FAKE) = %v, %q", err, m)
+	}
+	if err := m.Set("lol"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func Test_obfuscator_fakePerson(t *testing.T) {
+	o := newObfuscator(testRNG())
+	o.text = TMFake
+	p1, p2 := o.fakePerson("U1"), o.fakePerson("U1")
+	if p1 != p2 {
+		t.Errorf("fake person is not consistent: %v != %v", p1, p2)
+	}
+	if got := o.email("U1", "x@y.z"); got != p1.Email() {
+		t.Errorf("email = %q, want %q", got, p1.Email())
+	}
+}
@@ -13,6 +13,7 @@ const (
 	appPrefix  = "AO"
 	botPrefix  = "BO"
 	entPrefix  = "EO"
+	grpPrefix  = "SO"
 )
 
 // ID obfuscates an ID.
@@ -34,3 +35,4 @@ func (o obfuscator) TeamID(g string) string        { return o.ID(teamPrefix, g)
 func (o *obfuscator) BotID(b string) string        { return o.ID(botPrefix, b) }
 func (o *obfuscator) AppID(a string) string        { return o.ID(appPrefix, a) }
 func (o *obfuscator) EnterpriseID(e string) string { return o.ID(entPrefix, e) }
+func (o obfuscator) UserGroupID(g string) string   { return o.ID(grpPrefix, g) }
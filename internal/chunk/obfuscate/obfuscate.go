@@ -2,7 +2,9 @@
 // deterministic obfuscation of IDs, so that the users within the obfuscated
 // file will have a consistent IDs. But the same file obfuscated multiple
 // times will have different IDs.  The text is replaced with the randomness of
-// the same size + a random addition.
+// the same size + a random addition, or, depending on the [TextMode], with
+// the hash, the placeholder or the generated text.  It can also obfuscate
+// the Slack export, see [DoExport].
 package obfuscate
 
 import (
@@ -14,16 +16,20 @@ import (
 	"io"
 	"log"
 	"math/rand"
+	"path"
 	"runtime/trace"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/rusq/slack"
 	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/types"
 )
 
 type doOpts struct {
 	seed int64
+	text TextMode
 }
 
 type Option func(*doOpts)
@@ -58,6 +64,7 @@ func Do(ctx context.Context, w io.Writer, r io.Reader, options ...Option) error
 	}
 	rng := rand.New(rand.NewSource(opts.seed))
 	obf := newObfuscator(rng)
+	obf.text = opts.text
 	return obfuscate(ctx, obf, w, r)
 }
 
@@ -89,12 +96,19 @@ type obfuscator struct {
 	hasher func() hash.Hash
 	salt   string
 	rng    *rand.Rand
+	text   TextMode
+}
+
+// random returns true if the text and personal data are replaced with the
+// random strings.
+func (o obfuscator) random() bool {
+	return o.text == "" || o.text == TMRandom
 }
 
 func (o obfuscator) Chunk(c *chunk.Chunk) {
 	c.ChannelID = o.ChannelID(c.ChannelID)
 	switch c.Type {
-	case chunk.CMessages:
+	case chunk.CMessages, chunk.CMessageEdit, chunk.CMessageDeleted:
 		o.Messages(c.Messages...)
 	case chunk.CThreadMessages:
 		o.OneMessage(c.Parent)
@@ -113,6 +127,17 @@ func (o obfuscator) Chunk(c *chunk.Chunk) {
 		o.Channels(c.Channels...)
 	case chunk.CWorkspaceInfo:
 		o.WorkspaceInfo(c.WorkspaceInfo)
+		o.Workspace(c.Workspace)
+	case chunk.CTrailer:
+		o.Trailer(c.Trailer)
+	case chunk.CAnnotation:
+		o.Annotation(c.Annotation)
+	case chunk.CPins:
+		o.Items(c.Pins...)
+	case chunk.CBookmarks:
+		o.Bookmarks(c.Bookmarks...)
+	case chunk.CUserGroups:
+		o.UserGroups(c.UserGroups...)
 	default:
 		log.Panicf("unknown chunk type: %s", c.Type)
 	}
@@ -143,9 +168,7 @@ func (o obfuscator) OneMessage(m *slack.Message) {
 	m.Team = o.TeamID(m.Team)
 	m.Channel = o.UserID(m.Channel)
 	m.User = o.UserID(m.User)
-	if m.Text != "" {
-		m.Text = o.randomString(len(m.Text))
-	}
+	m.Text = o.Text(m.Text)
 	if m.Edited != nil {
 		m.Edited.User = o.UserID(m.Edited.User)
 	}
@@ -158,9 +181,7 @@ func (o obfuscator) OneMessage(m *slack.Message) {
 	if len(m.Attachments) > 0 {
 		m.Attachments = nil // too much hassle to obfuscate
 	}
-	if m.Topic != "" {
-		m.Topic = o.randomString(len(m.Topic))
-	}
+	m.Topic = o.Text(m.Topic)
 	m.Metadata = slack.SlackMetadata{}
 	m.ParentUserId = o.UserID(m.ParentUserId)
 	m.Team = o.TeamID(m.Team)
@@ -213,8 +234,13 @@ func (o obfuscator) OneFile(f *slack.File) {
 	for i := range fields {
 		*fields[i] = ifnotnil(*fields[i])
 	}
-	f.Title = ifnotnil(f.Title)
-	f.Name = ifnotnil(f.Name)
+	if o.random() {
+		f.Title = ifnotnil(f.Title)
+		f.Name = ifnotnil(f.Name)
+	} else {
+		f.Title = o.exactText(f.Title)
+		f.Name = notNilFn(f.Name, func(s string) string { return o.hash(s) + path.Ext(s) })
+	}
 	f.Thumb360W = 0
 	f.Thumb360H = 0
 	f.Thumb480W = 0
@@ -290,10 +316,10 @@ func (o obfuscator) Channel(c *slack.Channel) {
 	c.NameNormalized = o.ID("", c.NameNormalized)
 	o.OneMessage(c.Latest)
 
-	c.Purpose.Value = o.randomStringExact(len(c.Purpose.Value))
+	c.Purpose.Value = o.exactText(c.Purpose.Value)
 	c.Purpose.Creator = o.UserID(c.Purpose.Creator)
 
-	c.Topic.Value = o.randomStringExact(len(c.Topic.Value))
+	c.Topic.Value = o.exactText(c.Topic.Value)
 	c.Topic.Creator = o.UserID(c.Topic.Creator)
 
 	for i := range c.Members {
@@ -312,6 +338,10 @@ func (o obfuscator) User(u *slack.User) {
 	if u == nil {
 		return
 	}
+	if !o.random() {
+		o.userPersonal(u)
+		return
+	}
 	u.ID = o.UserID(u.ID)
 	u.Name = o.ID("", u.Name)
 	u.RealName = o.randomStringExact(len(u.RealName))
@@ -319,6 +349,42 @@ func (o obfuscator) User(u *slack.User) {
 	o.Profile(&u.Profile)
 }
 
+// userPersonal replaces the personal data of the user according to the
+// text mode.
+func (o obfuscator) userPersonal(u *slack.User) {
+	id := u.ID
+	u.ID = o.UserID(u.ID)
+	if o.text == TMFake {
+		u.Name = notNilFn(u.Name, func(string) string { return o.fakePerson(id).Username() })
+	} else {
+		u.Name = o.ID("", u.Name)
+	}
+	u.RealName = o.personal(id, u.RealName, person.RealName)
+	u.TeamID = o.TeamID(u.TeamID)
+
+	p := &u.Profile
+	p.DisplayName = o.personal(id, p.DisplayName, func(p person) string { return p.First })
+	p.DisplayNameNormalized = o.personal(id, p.DisplayNameNormalized, func(p person) string { return p.First })
+	p.RealName = o.personal(id, p.RealName, person.RealName)
+	p.RealNameNormalized = o.personal(id, p.RealNameNormalized, person.RealName)
+	p.FirstName = o.personal(id, p.FirstName, func(p person) string { return p.First })
+	p.LastName = o.personal(id, p.LastName, func(p person) string { return p.Last })
+	p.Email = o.email(id, p.Email)
+	p.Skype = ""
+	p.Phone = ""
+	p.Image24 = ""
+	p.Image32 = ""
+	p.Image48 = ""
+	p.Image72 = ""
+	p.Image192 = ""
+	p.Image512 = ""
+	p.ImageOriginal = ""
+	p.StatusText = o.exactText(p.StatusText)
+	p.StatusEmoji = ""
+	p.StatusExpiration = 0
+	p.Team = o.TeamID(p.Team)
+}
+
 func (o obfuscator) Profile(p *slack.UserProfile) {
 	if p == nil {
 		return
@@ -381,3 +447,110 @@ func (o obfuscator) WorkspaceInfo(wi *slack.AuthTestResponse) {
 	wi.User = o.randomString(len(wi.User))
 	wi.EnterpriseID = o.EnterpriseID(wi.EnterpriseID)
 }
+
+// Workspace obfuscates the extended workspace information, the plan is
+// kept as is.
+func (o obfuscator) Workspace(wd *types.Workspace) {
+	if wd == nil {
+		return
+	}
+	if ti := wd.TeamInfo; ti != nil {
+		ti.ID = o.TeamID(ti.ID)
+		ti.Name = o.randomString(len(ti.Name))
+		ti.Domain = o.randomString(len(ti.Domain))
+		ti.EmailDomain = o.randomString(len(ti.EmailDomain))
+		ti.Icon = nil
+	}
+	if ent := wd.Enterprise; ent != nil {
+		ent.ID = o.EnterpriseID(ent.ID)
+		ent.Name = o.randomString(len(ent.Name))
+		for i := range ent.Workspaces {
+			ws := &ent.Workspaces[i]
+			ws.ID = o.TeamID(ws.ID)
+			ws.Name = o.randomString(len(ws.Name))
+			ws.Domain = o.randomString(len(ws.Domain))
+			ws.URL = o.randomString(len(ws.URL))
+		}
+	}
+	if len(wd.BillableInfo) > 0 {
+		bi := make(map[string]slack.BillingActive, len(wd.BillableInfo))
+		for id, v := range wd.BillableInfo {
+			bi[o.UserID(id)] = v
+		}
+		wd.BillableInfo = bi
+	}
+}
+
+func (o obfuscator) Trailer(t *chunk.Trailer) {
+	if t == nil {
+		return
+	}
+	channels := make(map[string]chunk.ChannelStats, len(t.Channels))
+	for id, cs := range t.Channels {
+		for i := range cs.Senders {
+			cs.Senders[i] = o.UserID(cs.Senders[i])
+		}
+		sort.Strings(cs.Senders)
+		channels[o.ChannelID(id)] = cs
+	}
+	t.Channels = channels
+	// the index has the original IDs and offsets, the obfuscated file is
+	// indexed on open.
+	t.Version, t.Offset, t.Index = 0, 0, nil
+}
+
+// Annotation obfuscates the operator note, as it may contain the case
+// details and the name of the operator.
+func (o obfuscator) Annotation(a *chunk.Annotation) {
+	if a == nil {
+		return
+	}
+	if a.Author != "" {
+		a.Author = o.randomString(len(a.Author))
+	}
+	a.Text = o.Text(a.Text)
+}
+
+func (o obfuscator) Items(it ...slack.Item) {
+	for i := range it {
+		it[i].Channel = o.ChannelID(it[i].Channel)
+		o.OneMessage(it[i].Message)
+		o.OneFile(it[i].File)
+		it[i].Comment = nil // not used by Slack anymore
+	}
+}
+
+func (o obfuscator) Bookmarks(bb ...slack.Bookmark) {
+	for i := range bb {
+		bb[i].ChannelID = o.ChannelID(bb[i].ChannelID)
+		bb[i].Title = o.Text(bb[i].Title)
+		if bb[i].Link != "" {
+			bb[i].Link = "https://example.com/" + o.randomString(10)
+		}
+		bb[i].LastUpdatedByUserID = o.UserID(bb[i].LastUpdatedByUserID)
+		bb[i].LastUpdatedByTeamID = o.TeamID(bb[i].LastUpdatedByTeamID)
+	}
+}
+
+func (o obfuscator) UserGroups(gg ...slack.UserGroup) {
+	for i := range gg {
+		g := &gg[i]
+		g.ID = o.UserGroupID(g.ID)
+		g.TeamID = o.TeamID(g.TeamID)
+		g.Name = o.randomStringExact(len(g.Name))
+		g.Handle = o.ID("", g.Handle)
+		g.Description = o.Text(g.Description)
+		g.CreatedBy = o.UserID(g.CreatedBy)
+		g.UpdatedBy = o.UserID(g.UpdatedBy)
+		g.DeletedBy = o.UserID(g.DeletedBy)
+		for j := range g.Users {
+			g.Users[j] = o.UserID(g.Users[j])
+		}
+		for j := range g.Prefs.Channels {
+			g.Prefs.Channels[j] = o.ChannelID(g.Prefs.Channels[j])
+		}
+		for j := range g.Prefs.Groups {
+			g.Prefs.Groups[j] = o.ChannelID(g.Prefs.Groups[j])
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package obfuscate
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TextMode is the mode of replacing the text and the personal data, such as
+// names and emails.
+type TextMode string
+
+const (
+	// TMRandom replaces the text with the random characters, this is the
+	// default.
+	TMRandom TextMode = "random"
+	// TMHash replaces the text with the salted hash, so that the same text
+	// produces the same hash within the file or directory.
+	TMHash TextMode = "hash"
+	// TMRedact replaces the text with the placeholder.
+	TMRedact TextMode = "redact"
+	// TMFake replaces the text with the generated words, and the names and
+	// emails with the generated ones, that are consistent for each user.
+	// The user and channel mentions in the text are preserved.
+	TMFake TextMode = "fake"
+)
+
+// String implements the flag.Value interface.
+func (m TextMode) String() string {
+	if m == "" {
+		return string(TMRandom)
+	}
+	return string(m)
+}
+
+// Set implements the flag.Value interface.
+func (m *TextMode) Set(s string) error {
+	switch tm := TextMode(strings.ToLower(s)); tm {
+	case TMRandom, TMHash, TMRedact, TMFake:
+		*m = tm
+	default:
+		return fmt.Errorf("unknown text mode: %q, must be one of: %s, %s, %s, %s", s, TMRandom, TMHash, TMRedact, TMFake)
+	}
+	return nil
+}
+
+// WithTextMode sets the mode of replacing the text and the personal data.
+// Default is [TMRandom].
+func WithTextMode(m TextMode) Option {
+	return func(opts *doOpts) {
+		opts.text = m
+	}
+}
+
+const (
+	redacted      = "[redacted]"
+	redactedEmail = "redacted@example.invalid"
+	emailDomain   = "example.invalid"
+)
+
+// hash returns the salted hash of s, truncated to 16 characters.
+func (o obfuscator) hash(s string) string {
+	h := o.hasher()
+	if _, err := h.Write([]byte(o.salt + s)); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Text replaces the message text.  In the random mode the text is replaced
+// with the random string of the same length plus a random addition.
+func (o obfuscator) Text(s string) string {
+	if s == "" {
+		return ""
+	}
+	switch o.text {
+	case TMHash:
+		return o.hash(s)
+	case TMRedact:
+		return redacted
+	case TMFake:
+		return o.fakeText(s)
+	default:
+		return o.randomString(len(s))
+	}
+}
+
+// exactText replaces the short texts, such as topics and names.  In the
+// random mode the text is replaced with the random string of the same
+// length.
+func (o obfuscator) exactText(s string) string {
+	if s == "" {
+		return ""
+	}
+	switch o.text {
+	case TMHash, TMRedact, TMFake:
+		return o.Text(s)
+	default:
+		return o.randomStringExact(len(s))
+	}
+}
+
+var reMention = regexp.MustCompile(`^<([@#])([A-Z0-9]+)(?:\|[^>]*)?>`)
+
+// fakeText replaces each word of s with the generated one.  The user and
+// channel mentions are preserved with the obfuscated IDs, so that the
+// references between the messages and users remain intact.
+func (o obfuscator) fakeText(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		if sm := reMention.FindStringSubmatch(w); sm != nil {
+			if sm[1] == "@" {
+				words[i] = "<@" + o.UserID(sm[2]) + ">"
+			} else {
+				words[i] = "<#" + o.ChannelID(sm[2]) + ">"
+			}
+			continue
+		}
+		words[i] = loremWords[o.rng.Intn(len(loremWords))]
+	}
+	return strings.Join(words, " ")
+}
+
+// person is the generated identity of the user.
+type person struct {
+	First, Last string
+}
+
+// fakePerson returns the generated identity for the user ID, it is the same
+// for the same ID within the file or directory.
+func (o obfuscator) fakePerson(userID string) person {
+	h := o.hasher()
+	if _, err := h.Write([]byte(o.salt + userID)); err != nil {
+		panic(err)
+	}
+	sum := h.Sum(nil)
+	return person{
+		First: firstNames[int(sum[0])%len(firstNames)],
+		Last:  lastNames[int(sum[1])%len(lastNames)] + "-" + strings.ToUpper(hex.EncodeToString(sum[2:4])),
+	}
+}
+
+func (p person) RealName() string { return p.First + " " + p.Last }
+func (p person) Username() string { return strings.ToLower(p.First + "." + p.Last) }
+func (p person) Email() string    { return p.Username() + "@" + emailDomain }
+
+// personal replaces the name or the email field of the user userID.
+func (o obfuscator) personal(userID string, s string, fn func(person) string) string {
+	if s == "" {
+		return ""
+	}
+	switch o.text {
+	case TMFake:
+		return fn(o.fakePerson(userID))
+	case TMHash:
+		return o.hash(s)
+	default:
+		return redacted
+	}
+}
+
+// email replaces the email.
+func (o obfuscator) email(userID string, s string) string {
+	if s == "" {
+		return ""
+	}
+	switch o.text {
+	case TMFake:
+		return o.fakePerson(userID).Email()
+	case TMHash:
+		return o.hash(s) + "@" + emailDomain
+	default:
+		return redactedEmail
+	}
+}
+
+var (
+	firstNames = []string{
+		"Alex", "Blake", "Casey", "Dana", "Eli", "Frankie", "Gray", "Harper",
+		"Indy", "Jamie", "Kai", "Logan", "Morgan", "Noel", "Oakley", "Parker",
+		"Quinn", "Riley", "Sage", "Taylor", "Uri", "Val", "Wren", "Yael",
+	}
+	lastNames = []string{
+		"Adams", "Brooks", "Carter", "Dalton", "Ellis", "Foster", "Garcia",
+		"Hayes", "Irving", "Jensen", "Keller", "Lopez", "Mercer", "Nolan",
+		"Owens", "Porter", "Reyes", "Sutton", "Tran", "Vance", "Walsh", "Young",
+	}
+	loremWords = []string{
+		"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing",
+		"elit", "sed", "do", "eiusmod", "tempor", "incididunt", "ut", "labore",
+		"et", "dolore", "magna", "aliqua", "enim", "ad", "minim", "veniam",
+		"quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi",
+		"aliquip", "ex", "ea", "commodo", "consequat", "duis", "aute", "irure",
+		"in", "reprehenderit", "voluptate", "velit", "esse", "cillum", "eu",
+		"fugiat", "nulla", "pariatur", "excepteur", "sint", "occaecat",
+		"cupidatat", "non", "proident", "sunt", "culpa", "qui", "officia",
+		"deserunt", "mollit", "anim", "id", "est", "laborum",
+	}
+)
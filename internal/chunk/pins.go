@@ -0,0 +1,65 @@
+package chunk
+
+import (
+	"context"
+	"time"
+
+	"github.com/rusq/slack"
+)
+
+// Pins records the pinned items of the channel.
+func (rec *Recorder) Pins(ctx context.Context, channelID string, items []slack.Item) error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	chunk := Chunk{
+		Type:      CPins,
+		Timestamp: time.Now().UnixNano(),
+		ChannelID: channelID,
+		Count:     len(items),
+		Pins:      items,
+	}
+	return rec.encode(chunk)
+}
+
+// Bookmarks records the bookmarks of the channel.
+func (rec *Recorder) Bookmarks(ctx context.Context, channelID string, bookmarks []slack.Bookmark) error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	chunk := Chunk{
+		Type:      CBookmarks,
+		Timestamp: time.Now().UnixNano(),
+		ChannelID: channelID,
+		Count:     len(bookmarks),
+		Bookmarks: bookmarks,
+	}
+	return rec.encode(chunk)
+}
+
+// ChannelPins returns the pinned items of the channel, as they were last
+// recorded.  It returns ErrNotFound, if the pins were not recorded.
+func (f *File) ChannelPins(channelID string) ([]slack.Item, error) {
+	c, err := f.lastChunkForID(id(pinPrefix, channelID))
+	if err != nil {
+		return nil, err
+	}
+	return c.Pins, nil
+}
+
+// ChannelBookmarks returns the bookmarks of the channel, as they were last
+// recorded.  It returns ErrNotFound, if the bookmarks were not recorded.
+func (f *File) ChannelBookmarks(channelID string) ([]slack.Bookmark, error) {
+	c, err := f.lastChunkForID(id(bookmarkPrefix, channelID))
+	if err != nil {
+		return nil, err
+	}
+	return c.Bookmarks, nil
+}
+
+// lastChunkForID returns the last chunk in the file for the given id.
+func (f *File) lastChunkForID(id GroupID) (*Chunk, error) {
+	ofs, ok := f.Offsets(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return f.chunkAt(ofs[len(ofs)-1])
+}
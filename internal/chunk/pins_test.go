@@ -0,0 +1,55 @@
+package chunk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder_Pins(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf)
+	msg := testMsg("U1", "1700000001.000000")
+	if err := rec.Pins(ctx, TestChannelID, []slack.Item{{Type: "message", Channel: TestChannelID}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Pins(ctx, TestChannelID, []slack.Item{{Type: "message", Channel: TestChannelID, Message: &msg}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Bookmarks(ctx, TestChannelID, []slack.Bookmark{{ID: "Bk1", ChannelID: TestChannelID, Title: "Runbook"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := FromReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pins, err := f.ChannelPins(TestChannelID)
+	if err != nil {
+		t.Fatalf("ChannelPins() error = %v", err)
+	}
+	// the last snapshot wins.
+	if assert.Len(t, pins, 1) && assert.NotNil(t, pins[0].Message) {
+		assert.Equal(t, msg.Timestamp, pins[0].Message.Timestamp)
+	}
+	bookmarks, err := f.ChannelBookmarks(TestChannelID)
+	if err != nil {
+		t.Fatalf("ChannelBookmarks() error = %v", err)
+	}
+	assert.Equal(t, []slack.Bookmark{{ID: "Bk1", ChannelID: TestChannelID, Title: "Runbook"}}, bookmarks)
+
+	if _, err := f.ChannelPins("C_OTHER"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ChannelPins() error = %v, want %v", err, ErrNotFound)
+	}
+	if _, err := f.ChannelBookmarks("C_OTHER"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ChannelBookmarks() error = %v, want %v", err, ErrNotFound)
+	}
+}
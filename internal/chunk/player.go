@@ -1,11 +1,17 @@
 package chunk
 
 import (
+	"errors"
+	"fmt"
 	"io"
+	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/fasttime"
 )
 
 // offsets holds the pointer to the current offset in the File offset index
@@ -20,21 +26,84 @@ type Player struct {
 	lastOffset atomic.Int64
 	pointer    offsets      // current chunk pointers
 	ptrMu      sync.RWMutex // pointer mutex
+
+	statsOnce sync.Once               // ensures stats are loaded once
+	stats     map[string]ChannelStats // channel statistics
+	statsErr  error                   // error loading statistics
+
+	speed    float64             // replay speed, zero disables the delays
+	maxDelay time.Duration       // maximum delay between the chunks
+	sleep    func(time.Duration) // replaced in tests
+	clockMu  sync.Mutex
+	lastTS   int64 // recording time of the latest chunk replayed so far
+
+	verify bool // verify the chunk chain in NewPlayer
+}
+
+// PlayerOption is the option for the Player.
+type PlayerOption func(*Player)
+
+// WithReplaySpeed makes the Player replay the chunks with the simulated
+// timing.  Before returning a chunk, the Player waits for the time, that
+// passed between the recording of the previously returned chunk and this
+// one, divided by speed, i.e. 2 replays twice as fast as recorded, and 0.5
+// twice as slow.  Zero disables the delays, which is the default.
+func WithReplaySpeed(speed float64) PlayerOption {
+	return func(p *Player) {
+		if speed > 0 {
+			p.speed = speed
+		}
+	}
+}
+
+// WithMaxDelay caps the delay between the chunks, so that the long pauses
+// in the recording are skipped.  Zero means no cap.
+func WithMaxDelay(d time.Duration) PlayerOption {
+	return func(p *Player) {
+		if d > 0 {
+			p.maxDelay = d
+		}
+	}
+}
+
+// WithVerify makes [NewPlayer] verify the continuity of the chunk chain
+// before returning the Player.  It requires a full pass over the file, so it
+// is off by default.
+func WithVerify(b bool) PlayerOption {
+	return func(p *Player) {
+		p.verify = b
+	}
 }
 
-func NewPlayerFromFile(cf *File) *Player {
-	return &Player{
+// NewPlayerFromFile returns the Player for the chunk file cf.  It does not
+// verify the integrity of the file, use [Player.Verify] for that.
+func NewPlayerFromFile(cf *File, opts ...PlayerOption) *Player {
+	p := &Player{
 		f:       cf,
 		pointer: make(offsets),
+		sleep:   time.Sleep,
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
-func NewPlayer(rs io.ReadSeeker) (*Player, error) {
+// NewPlayer returns the Player for the chunk file in rs.  If [WithVerify] is
+// given, it verifies the continuity of the chunk chain, and returns an error
+// wrapping [ErrIntegrity], if the recording is truncated or was edited.
+func NewPlayer(rs io.ReadSeeker, opts ...PlayerOption) (*Player, error) {
 	cf, err := FromReader(rs)
 	if err != nil {
 		return nil, err
 	}
-	return NewPlayerFromFile(cf), nil
+	p := NewPlayerFromFile(cf, opts...)
+	if p.verify {
+		if err := p.Verify(); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
 }
 
 // Offset returns the last read offset of the record in ReadSeeker.
@@ -55,8 +124,46 @@ func (p *Player) SetState(ptrs map[GroupID]int) {
 }
 
 // next tries to get the next chunk for the given id.  It returns
-// io.EOF if there are no more chunks for the given id.
+// io.EOF if there are no more chunks for the given id.  If the replay speed
+// is set, it waits for the simulated delay before returning the chunk.
 func (p *Player) next(id GroupID) (*Chunk, error) {
+	chunk, err := p.nextChunk(id)
+	if err != nil {
+		return nil, err
+	}
+	if d := p.delay(chunk); d > 0 {
+		p.sleep(d)
+	}
+	return chunk, nil
+}
+
+// delay returns the simulated delay before replaying the chunk, and advances
+// the replay clock.  The chunks recorded earlier than the latest replayed one
+// are returned immediately.
+func (p *Player) delay(c *Chunk) time.Duration {
+	if p.speed <= 0 {
+		return 0
+	}
+	p.clockMu.Lock()
+	defer p.clockMu.Unlock()
+	last := p.lastTS
+	if c.Timestamp <= last {
+		return 0
+	}
+	p.lastTS = c.Timestamp
+	if last == 0 {
+		return 0
+	}
+	d := time.Duration(float64(c.Timestamp-last) / p.speed)
+	if p.maxDelay > 0 && d > p.maxDelay {
+		d = p.maxDelay
+	}
+	return d
+}
+
+// nextChunk returns the next chunk for the given id, and advances the
+// pointer.
+func (p *Player) nextChunk(id GroupID) (*Chunk, error) {
 	p.ptrMu.Lock()
 	defer p.ptrMu.Unlock()
 	offsets, ok := p.f.Offsets(id)
@@ -157,11 +264,87 @@ func (p *Player) Thread(channelID string, threadTS string) ([]slack.Message, err
 	return append([]slack.Message{*chunk.Parent}, chunk.Messages...), nil
 }
 
+// MergedMessages returns all messages of the channel with the thread replies
+// merged inline, sorted by timestamp, for the consumers that do not need to
+// distinguish between the channel and thread messages.  It does not affect
+// the replay state.  See [File.AllMessagesMerged].
+func (p *Player) MergedMessages(channelID string) ([]slack.Message, error) {
+	return p.f.AllMessagesMerged(channelID)
+}
+
 // Reset resets the state of the Player.
 func (p *Player) Reset() error {
 	p.ptrMu.Lock()
 	p.pointer = make(offsets)
 	p.ptrMu.Unlock()
+	p.clockMu.Lock()
+	p.lastTS = 0
+	p.clockMu.Unlock()
+	return nil
+}
+
+// SeekTo positions the player so that the next call to Messages for the
+// channel returns the chunk containing the message with timestamp ts, or, if
+// there's no such message, the first chunk with a message older than ts.  As
+// the API returns messages newest first, the replay continues from ts
+// towards the older messages.  It returns ErrNotFound if the channel has no
+// messages at or before ts.  Pointers of other channels are not affected.
+func (p *Player) SeekTo(channelID, ts string) error {
+	target, err := fasttime.TS2int(ts)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q: %w", ts, err)
+	}
+	id := GroupID(channelID)
+
+	p.ptrMu.Lock()
+	defer p.ptrMu.Unlock()
+	offsets, ok := p.f.Offsets(id)
+	if !ok {
+		return ErrNotFound
+	}
+	for i, off := range offsets {
+		chunk, err := p.f.chunkAt(off)
+		if err != nil {
+			return err
+		}
+		tss, err := chunk.Timestamps()
+		if err != nil {
+			return err
+		}
+		for _, t := range tss {
+			if t <= target {
+				p.pointer[id] = i
+				return nil
+			}
+		}
+	}
+	return ErrNotFound
+}
+
+// ReplayFrom positions the player so that the replay continues from the
+// chunk at the given offset in the file, i.e. one previously returned by
+// Offset.  For message, thread, file and search chunks, the next chunk
+// returned is the first one at or after offset.  The info and list chunks,
+// such as channel info, users and channels, are rewound to the start, so
+// that they are available for the replay.
+func (p *Player) ReplayFrom(offset int64) error {
+	if offset < 0 {
+		return errors.New("negative offset")
+	}
+	p.f.ensure()
+
+	p.ptrMu.Lock()
+	defer p.ptrMu.Unlock()
+	ptrs := make(offsets, len(p.f.idx))
+	for id, offs := range p.f.idx {
+		if len(id) > 0 && (id.isInfo() || id.isList()) {
+			ptrs[id] = 0
+			continue
+		}
+		ptrs[id] = sort.Search(len(offs), func(i int) bool { return offs[i] >= offset })
+	}
+	p.pointer = ptrs
+	p.lastOffset.Store(offset)
 	return nil
 }
 
@@ -195,6 +378,59 @@ func (p *Player) channelInfo(id string) (*slack.Channel, error) {
 	return chunk.Channel, nil
 }
 
+// Files returns all files of the channel, or of all channels, if channelID
+// is empty.  Unlike the other methods, it does not advance the player.
+func (p *Player) Files(channelID string) ([]slack.File, error) {
+	return p.f.AllFiles(channelID)
+}
+
+// FileInfo returns the file with the given ID.  It returns ErrNotFound if
+// the file is not in the chunkfile.
+func (p *Player) FileInfo(fileID string) (*slack.File, error) {
+	files, err := p.f.AllFiles("")
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		if files[i].ID == fileID {
+			return &files[i], nil
+		}
+	}
+	return nil, fmt.Errorf("file %q: %w", fileID, ErrNotFound)
+}
+
+// Bookmarks returns all bookmarks of the channel.
+func (p *Player) Bookmarks(channelID string) ([]slack.Bookmark, error) {
+	return p.f.AllBookmarks(channelID)
+}
+
+// StarredItems returns all starred items.
+func (p *Player) StarredItems() ([]slack.StarredItem, error) {
+	return p.f.AllStarredItems()
+}
+
+// UsersInfo returns the users with the given IDs.  It returns ErrNotFound if
+// any of the users is not in the chunkfile.
+func (p *Player) UsersInfo(userIDs ...string) ([]slack.User, error) {
+	users, err := p.f.AllUsers()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]int, len(users))
+	for i := range users {
+		byID[users[i].ID] = i
+	}
+	ret := make([]slack.User, 0, len(userIDs))
+	for _, id := range userIDs {
+		i, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("user %q: %w", id, ErrNotFound)
+		}
+		ret = append(ret, users[i])
+	}
+	return ret, nil
+}
+
 func (p *Player) WorkspaceInfo() (*slack.AuthTestResponse, error) {
 	return p.f.WorkspaceInfo()
 }
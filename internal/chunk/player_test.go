@@ -3,7 +3,11 @@ package chunk
 import (
 	"errors"
 	"io"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/rusq/slack"
 )
 
 func TestPlayer_Thread(t *testing.T) {
@@ -38,3 +42,210 @@ func TestPlayer_Thread(t *testing.T) {
 		t.Fatalf("expected 0 messages, got %d", len(m))
 	}
 }
+
+func msgChunk(channelID string, tss ...string) Chunk {
+	c := Chunk{Type: CMessages, ChannelID: channelID}
+	for _, ts := range tss {
+		c.Messages = append(c.Messages, slack.Message{Msg: slack.Msg{Timestamp: ts}})
+	}
+	return c
+}
+
+func seekTestPlayer(t *testing.T) *Player {
+	t.Helper()
+	p, err := NewPlayer(marshalChunks(
+		Chunk{Type: CChannelInfo, ChannelID: "C1", Channel: &slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}}},
+		Chunk{Type: CUsers, Users: []slack.User{{ID: "U1"}}},
+		msgChunk("C1", "1700000005.000000", "1700000004.000000"),
+		msgChunk("C2", "1700000003.000000"),
+		msgChunk("C1", "1700000003.000000", "1700000002.000000"),
+		msgChunk("C1", "1700000001.000000"),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestPlayer_SeekTo(t *testing.T) {
+	tests := []struct {
+		name    string
+		ts      string
+		wantTS  string
+		wantErr error
+	}{
+		{"exact", "1700000003.000000", "1700000003.000000", nil},
+		{"in the middle", "1700000004.500000", "1700000005.000000", nil},
+		{"between chunks", "1700000003.500000", "1700000003.000000", nil},
+		{"newer than all", "1800000000.000000", "1700000005.000000", nil},
+		{"older than all", "1600000000.000000", "", ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := seekTestPlayer(t)
+			err := p.SeekTo("C1", tt.ts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SeekTo() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			mm, err := p.Messages("C1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mm[0].Timestamp != tt.wantTS {
+				t.Errorf("first message ts = %s, want %s", mm[0].Timestamp, tt.wantTS)
+			}
+		})
+	}
+	t.Run("unknown channel", func(t *testing.T) {
+		p := seekTestPlayer(t)
+		if err := p.SeekTo("C3", "1700000003.000000"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+	t.Run("invalid timestamp", func(t *testing.T) {
+		p := seekTestPlayer(t)
+		if err := p.SeekTo("C1", "yesterday"); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestPlayer_ReplayFrom(t *testing.T) {
+	p := seekTestPlayer(t)
+	// read everything, remembering the offset of the second C1 chunk.
+	if _, err := p.Messages("C1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Messages("C1"); err != nil {
+		t.Fatal(err)
+	}
+	offset := p.Offset()
+	for p.HasMoreMessages("C1") {
+		if _, err := p.Messages("C1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := p.ChannelInfo("C1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.ReplayFrom(offset); err != nil {
+		t.Fatal(err)
+	}
+	mm, err := p.Messages("C1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mm[0].Timestamp != "1700000003.000000" {
+		t.Errorf("first message ts = %s, want 1700000003.000000", mm[0].Timestamp)
+	}
+	// C2 chunk precedes the offset.
+	if p.HasMoreMessages("C2") {
+		t.Error("expected no more messages for C2")
+	}
+	// info and lists are rewound.
+	if _, err := p.ChannelInfo("C1"); err != nil {
+		t.Errorf("ChannelInfo: %v", err)
+	}
+	if !p.HasUsers() {
+		t.Error("expected users to be available")
+	}
+	if err := p.ReplayFrom(-1); err == nil {
+		t.Error("expected error for negative offset")
+	}
+}
+
+func TestPlayer_MergedMessages(t *testing.T) {
+	thread := func(parent string, tss ...string) Chunk {
+		c := msgChunk("C1", tss...)
+		c.Type = CThreadMessages
+		c.ThreadTS = parent
+		c.Parent = &slack.Message{Msg: slack.Msg{Timestamp: parent, ThreadTimestamp: parent, Text: "from thread"}}
+		return c
+	}
+	p, err := NewPlayer(marshalChunks(
+		thread("1700000002.000000", "1700000004.000000", "1700000006.000000"),
+		msgChunk("C1", "1700000005.000000", "1700000004.000000", "1700000002.000000"),
+		msgChunk("C12", "1700000003.000000"),
+		thread("1700000001.000000", "1700000003.500000"), // parent not in channel
+		msgChunk("C1", "1700000000.000000"),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mm, err := p.MergedMessages("C1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"1700000000.000000",
+		"1700000001.000000",
+		"1700000002.000000",
+		"1700000003.500000",
+		"1700000004.000000",
+		"1700000005.000000",
+		"1700000006.000000",
+	}
+	if len(mm) != len(want) {
+		t.Fatalf("got %d messages, want %d", len(mm), len(want))
+	}
+	for i := range want {
+		if mm[i].Timestamp != want[i] {
+			t.Errorf("message %d ts = %s, want %s", i, mm[i].Timestamp, want[i])
+		}
+	}
+	if mm[2].Text == "from thread" {
+		t.Error("channel copy of the thread parent should take precedence")
+	}
+	if mm[1].Text != "from thread" {
+		t.Error("thread parent missing from the channel should be included")
+	}
+	// replay state is not affected.
+	if !p.HasMoreMessages("C1") {
+		t.Error("replay state changed")
+	}
+
+	if _, err := p.MergedMessages("C3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestPlayer_replaySpeed(t *testing.T) {
+	const sec = int64(time.Second)
+	chunks := []Chunk{
+		{Type: CMessages, ChannelID: "C1", Timestamp: 10 * sec, Messages: []slack.Message{{Msg: slack.Msg{Timestamp: "3.0"}}}},
+		{Type: CMessages, ChannelID: "C1", Timestamp: 14 * sec, Messages: []slack.Message{{Msg: slack.Msg{Timestamp: "2.0"}}}},
+		{Type: CMessages, ChannelID: "C2", Timestamp: 12 * sec, Messages: []slack.Message{{Msg: slack.Msg{Timestamp: "1.0"}}}},
+		{Type: CMessages, ChannelID: "C1", Timestamp: 74 * sec, Messages: []slack.Message{{Msg: slack.Msg{Timestamp: "0.5"}}}},
+	}
+	tests := []struct {
+		name string
+		opts []PlayerOption
+		want []time.Duration
+	}{
+		{"no delays by default", nil, nil},
+		{"double speed", []PlayerOption{WithReplaySpeed(2)}, []time.Duration{2 * time.Second, 30 * time.Second}},
+		{"capped", []PlayerOption{WithReplaySpeed(1), WithMaxDelay(10 * time.Second)}, []time.Duration{4 * time.Second, 10 * time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPlayer(marshalChunks(chunks...), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []time.Duration
+			p.sleep = func(d time.Duration) { got = append(got, d) }
+			for _, ch := range []string{"C1", "C1", "C2", "C1"} {
+				if _, err := p.Messages(ch); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("delays = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package chunk
+
+// In this file: the buffered random access to the chunks.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+const (
+	readBufSize = 64 << 10
+	// maxPooledLine is the maximum capacity of the line buffer, that is
+	// returned to the pool, so that the pool does not hold on to the buffers
+	// of the occasional huge chunks.
+	maxPooledLine = 4 << 20
+)
+
+var (
+	readerPool = sync.Pool{New: func() any { return bufio.NewReaderSize(nil, readBufSize) }}
+	linePool   = sync.Pool{New: func() any { b := make([]byte, 0, readBufSize); return &b }}
+)
+
+// readChunkAt reads the chunk at the offset from ra.  The chunk is expected
+// to be on a single line, as the Recorder writes them, the offset may point
+// to the newline, that precedes it.  Chunks spanning several lines are
+// decoded with the json.Decoder.
+func readChunkAt(ra io.ReaderAt, offset int64) (*Chunk, error) {
+	sr := io.NewSectionReader(ra, offset, math.MaxInt64-offset)
+
+	br := readerPool.Get().(*bufio.Reader)
+	br.Reset(sr)
+	bufp := linePool.Get().(*[]byte)
+	defer func() {
+		br.Reset(nil)
+		readerPool.Put(br)
+		if cap(*bufp) <= maxPooledLine {
+			*bufp = (*bufp)[:0]
+			linePool.Put(bufp)
+		}
+	}()
+
+	line, err := readLine(br, (*bufp)[:0])
+	*bufp = line
+	if err != nil && !(errors.Is(err, io.EOF) && len(line) > 0) {
+		return nil, fmt.Errorf("read error: offset %d: %w", offset, err)
+	}
+	var chunk *Chunk
+	if err := json.Unmarshal(line, &chunk); err != nil {
+		// not a single line chunk.
+		if _, err := sr.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if err := json.NewDecoder(sr).Decode(&chunk); err != nil {
+			return nil, fmt.Errorf("decode error: offset %d: %w", offset, err)
+		}
+	}
+	return chunk, nil
+}
+
+// readLine appends the first non-empty line from br to buf.
+func readLine(br *bufio.Reader, buf []byte) ([]byte, error) {
+	for {
+		frag, err := br.ReadSlice('\n')
+		buf = append(buf, frag...)
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if len(bytes.TrimSpace(buf)) == 0 && err == nil {
+			buf = buf[:0] // the newline before the chunk.
+			continue
+		}
+		return buf, err
+	}
+}
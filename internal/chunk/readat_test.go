@@ -0,0 +1,97 @@
+package chunk
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_readChunkAt(t *testing.T) {
+	small := Chunk{Type: CMessages, ChannelID: "C1", Messages: []slack.Message{testMsg("U1", "1.0")}}
+	large := Chunk{Type: CMessages, ChannelID: "C2", Messages: []slack.Message{
+		{Msg: slack.Msg{Timestamp: "2.0", Text: strings.Repeat("x", 3*readBufSize)}},
+	}}
+	line := func(c Chunk) string {
+		b, err := json.Marshal(c)
+		require.NoError(t, err)
+		return string(b) + "\n"
+	}
+	pretty, err := json.MarshalIndent(small, "", "  ")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		data   string
+		offset int64
+		want   Chunk
+	}{
+		{"first", line(small) + line(large), 0, small},
+		{"second", line(small) + line(large), int64(len(line(small))), large},
+		{"offset at newline", line(small) + line(large), int64(len(line(small)) - 1), large},
+		{"no final newline", strings.TrimSuffix(line(small), "\n"), 0, small},
+		{"multi-line", string(pretty) + "\n" + line(large), 0, small},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readChunkAt(strings.NewReader(tt.data), tt.offset)
+			require.NoError(t, err)
+			assert.Equal(t, &tt.want, got)
+		})
+	}
+	t.Run("past the end", func(t *testing.T) {
+		_, err := readChunkAt(strings.NewReader(line(small)), int64(len(line(small))))
+		assert.Error(t, err)
+	})
+}
+
+func TestFile_chunkAt_concurrent(t *testing.T) {
+	data := testRecording(t)
+	f, err := FromReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	want, err := f.AllMessages(TestChannelID)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := f.AllMessages(TestChannelID)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkFile_chunkAt(b *testing.B) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := 0; i < 100; i++ {
+		mm := make([]slack.Message, 100)
+		for j := range mm {
+			mm[j] = testMsg("U1", "1700000000.000000")
+		}
+		if err := enc.Encode(Chunk{Type: CMessages, ChannelID: "C1", Messages: mm}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	f, err := FromReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		b.Fatal(err)
+	}
+	offsets, _ := f.Offsets("C1")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.chunkAt(offsets[i%len(offsets)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package chunk
 
 import (
 	"context"
-	"encoding/json"
 	"io"
 	"sync"
 	"time"
@@ -11,20 +10,26 @@ import (
 
 	"github.com/rusq/slackdump/v3/internal/chunk/state"
 	"github.com/rusq/slackdump/v3/internal/osext"
+	"github.com/rusq/slackdump/v3/internal/runid"
+	"github.com/rusq/slackdump/v3/types"
 )
 
 // Recorder records all the data it receives into a writer.
 type Recorder struct {
-	mu    sync.Mutex
-	enc   Encoder // encoder to use for the chunks
-	state *state.State
+	mu     sync.Mutex
+	enc    Encoder // encoder to use for the chunks
+	state  *state.State
+	stats  *statsAggregator // channel statistics for the trailer
+	n      int              // number of chunks written
+	closed bool
 }
 
 // Option is a function that configures the Recorder.
 type Option func(r *Recorder)
 
 // WithEncoder allows you to specify a custom encoder to use for the chunks.
-// By default [json.Encoder] is used.
+// By default, chunks are encoded as JSON, and linked into a chain, see
+// [File.Audit].
 func WithEncoder(enc Encoder) Option {
 	return func(r *Recorder) {
 		r.enc = enc
@@ -38,8 +43,9 @@ func NewRecorder(w io.Writer, options ...Option) *Recorder {
 		filename = f.Name()
 	}
 	rec := &Recorder{
-		enc:   json.NewEncoder(w),
-		state: state.New(filename),
+		enc:   newChainEncoder(w),
+		state: state.New(filename).SetRunID(runid.ID()),
+		stats: newStatsAggregator(),
 	}
 	for _, opt := range options {
 		opt(rec)
@@ -52,6 +58,16 @@ type Encoder interface {
 	Encode(chunk interface{}) error
 }
 
+// encode encodes the chunk and counts it.  It must be called with the lock
+// held.
+func (rec *Recorder) encode(chunk interface{}) error {
+	if err := rec.enc.Encode(chunk); err != nil {
+		return err
+	}
+	rec.n++
+	return nil
+}
+
 // Messages is called for each message chunk that is retrieved.
 func (rec *Recorder) Messages(ctx context.Context, channelID string, numThreads int, isLast bool, m []slack.Message) error {
 	rec.mu.Lock()
@@ -64,12 +80,13 @@ func (rec *Recorder) Messages(ctx context.Context, channelID string, numThreads
 		Count:     len(m),
 		Messages:  m,
 	}
-	if err := rec.enc.Encode(chunk); err != nil {
+	if err := rec.encode(chunk); err != nil {
 		return err
 	}
 	for i := range m {
 		rec.state.AddMessage(channelID, m[i].Timestamp)
 	}
+	rec.stats.add(channelID, m)
 	return nil
 }
 
@@ -88,7 +105,7 @@ func (rec *Recorder) Files(ctx context.Context, channel *slack.Channel, parent s
 		Count:     len(f),
 		Files:     f,
 	}
-	if err := rec.enc.Encode(chunk); err != nil {
+	if err := rec.encode(chunk); err != nil {
 		return err
 	}
 	for i := range f {
@@ -112,12 +129,13 @@ func (rec *Recorder) ThreadMessages(ctx context.Context, channelID string, paren
 		Count:     len(tm),
 		Messages:  tm,
 	}
-	if err := rec.enc.Encode(chunks); err != nil {
+	if err := rec.encode(chunks); err != nil {
 		return err
 	}
 	for i := range tm {
 		rec.state.AddThread(channelID, parent.ThreadTimestamp, tm[i].Timestamp)
 	}
+	rec.stats.add(channelID, tm)
 	return nil
 }
 
@@ -135,7 +153,7 @@ func (rec *Recorder) ChannelInfo(ctx context.Context, channel *slack.Channel, th
 		ThreadTS:  threadTS,
 		Channel:   channel,
 	}
-	if err := rec.enc.Encode(chunk); err != nil {
+	if err := rec.encode(chunk); err != nil {
 		return err
 	}
 	rec.state.AddChannel(channel.ID)
@@ -144,18 +162,33 @@ func (rec *Recorder) ChannelInfo(ctx context.Context, channel *slack.Channel, th
 
 // Users records a slice of users.
 func (rec *Recorder) Users(ctx context.Context, users []slack.User) error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
 	chunk := Chunk{
 		Type:      CUsers,
 		Timestamp: time.Now().UnixNano(),
 		Count:     len(users),
 		Users:     users,
 	}
-	if err := rec.enc.Encode(chunk); err != nil {
+	if err := rec.encode(chunk); err != nil {
 		return err
 	}
 	return nil
 }
 
+// UserGroups records a slice of user groups.
+func (rec *Recorder) UserGroups(ctx context.Context, groups []slack.UserGroup) error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	chunk := Chunk{
+		Type:       CUserGroups,
+		Timestamp:  time.Now().UnixNano(),
+		Count:      len(groups),
+		UserGroups: groups,
+	}
+	return rec.encode(chunk)
+}
+
 // Channel records a slice of channels.
 func (rec *Recorder) Channels(ctx context.Context, channels []slack.Channel) error {
 	rec.mu.Lock()
@@ -166,7 +199,7 @@ func (rec *Recorder) Channels(ctx context.Context, channels []slack.Channel) err
 		Count:     len(channels),
 		Channels:  channels,
 	}
-	if err := rec.enc.Encode(chunk); err != nil {
+	if err := rec.encode(chunk); err != nil {
 		return err
 	}
 	return nil
@@ -180,9 +213,28 @@ func (rec *Recorder) State() (*state.State, error) {
 	return rec.state, nil
 }
 
-// Close closes the recorder (it's a noop for now).
+// Close closes the recorder.  It writes the trailer chunk with the channel
+// statistics, that also marks the end of the recording, unless no chunks were
+// written.  It does not close the underlying writer.
 func (rec *Recorder) Close() error {
-	return nil
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.closed {
+		return nil
+	}
+	rec.closed = true
+	if rec.stats == nil || rec.n == 0 {
+		return nil
+	}
+	trailer := rec.stats.trailer()
+	trailer.RunIDs = []string{runid.ID()}
+	chunk := Chunk{
+		Type:      CTrailer,
+		Timestamp: time.Now().UnixNano(),
+		Count:     len(rec.stats.channels),
+		Trailer:   trailer,
+	}
+	return rec.enc.Encode(chunk)
 }
 
 // WorkspaceInfo is called when workspace info is retrieved.
@@ -195,7 +247,25 @@ func (rec *Recorder) WorkspaceInfo(ctx context.Context, atr *slack.AuthTestRespo
 		Timestamp:     time.Now().UnixNano(),
 		WorkspaceInfo: atr,
 	}
-	if err := rec.enc.Encode(chunk); err != nil {
+	if err := rec.encode(chunk); err != nil {
+		return err
+	}
+	return nil
+}
+
+// WorkspaceDetails is called when the workspace info and the extended
+// workspace information are retrieved, they are recorded in the same chunk.
+func (rec *Recorder) WorkspaceDetails(ctx context.Context, atr *slack.AuthTestResponse, wd *types.Workspace) error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	chunk := Chunk{
+		Type:          CWorkspaceInfo,
+		Timestamp:     time.Now().UnixNano(),
+		WorkspaceInfo: atr,
+		Workspace:     wd,
+	}
+	if err := rec.encode(chunk); err != nil {
 		return err
 	}
 	return nil
@@ -212,7 +282,7 @@ func (rec *Recorder) ChannelUsers(ctx context.Context, channelID string, threadT
 		Timestamp:    time.Now().UnixNano(),
 		ChannelUsers: users,
 	}
-	if err := rec.enc.Encode(chunk); err != nil {
+	if err := rec.encode(chunk); err != nil {
 		return err
 	}
 
@@ -230,7 +300,7 @@ func (rec *Recorder) SearchMessages(ctx context.Context, query string, sm []slac
 		SearchQuery:    query,
 		SearchMessages: sm,
 	}
-	if err := rec.enc.Encode(chunk); err != nil {
+	if err := rec.encode(chunk); err != nil {
 		return err
 	}
 	return nil
@@ -247,7 +317,7 @@ func (rec *Recorder) SearchFiles(ctx context.Context, query string, sf []slack.F
 		SearchQuery: query,
 		SearchFiles: sf,
 	}
-	if err := rec.enc.Encode(chunk); err != nil {
+	if err := rec.encode(chunk); err != nil {
 		return err
 	}
 	return nil
@@ -0,0 +1,76 @@
+package chunk
+
+// In this file: rewriting the chunk recording chunk by chunk.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// RewriteFunc is the function that is called for each chunk of the
+// recording by [Rewrite].  It returns the chunks that should be written in
+// place of c, that can be none, if the chunk should be dropped.
+type RewriteFunc func(c *Chunk) ([]Chunk, error)
+
+// Rewrite reads the chunk recording from r, calls fn for each chunk, and
+// writes the chunks it returns to w, linked into a new chain.  The count of
+// messages and files in the output chunks is updated.  Trailers are not
+// passed to fn, the recording is closed with the regenerated trailer, that
+// retains the run IDs of the input.
+func Rewrite(ctx context.Context, w io.Writer, r io.Reader, fn RewriteFunc) error {
+	var (
+		dec    = json.NewDecoder(r)
+		enc    = newChainEncoder(w)
+		stats  = newStatsAggregator()
+		runIDs []string
+	)
+	for n := 1; ; n++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var c Chunk
+		if err := dec.Decode(&c); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("chunk %d: %w", n, err)
+		}
+		if c.Type == CTrailer {
+			if c.Trailer != nil {
+				runIDs = mergeSorted(runIDs, c.Trailer.RunIDs)
+			}
+			continue
+		}
+		out, err := fn(&c)
+		if err != nil {
+			return fmt.Errorf("chunk %d: %w", n, err)
+		}
+		for i := range out {
+			if out[i].Type == CTrailer {
+				continue
+			}
+			switch {
+			case isMessageChunk(&out[i]):
+				out[i].Count = len(out[i].Messages)
+				stats.add(out[i].ChannelID, out[i].Messages)
+			case out[i].Type == CFiles:
+				out[i].Count = len(out[i].Files)
+			}
+			if err := enc.Encode(&out[i]); err != nil {
+				return err
+			}
+		}
+	}
+	tr := stats.trailer()
+	tr.RunIDs = runIDs
+	return enc.Encode(Chunk{
+		Type:      CTrailer,
+		Timestamp: time.Now().UnixNano(),
+		Count:     len(stats.channels),
+		Trailer:   tr,
+	})
+}
@@ -0,0 +1,66 @@
+package chunk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewrite(t *testing.T) {
+	msg := func(user, ts string) slack.Message {
+		return slack.Message{Msg: slack.Msg{User: user, Timestamp: ts}}
+	}
+	in := marshalChunks(
+		Chunk{Type: CUsers, Timestamp: 1, Users: []slack.User{{ID: "U1"}}},
+		Chunk{Type: CMessages, Timestamp: 2, ChannelID: "C1", Messages: []slack.Message{msg("U1", "1.0"), msg("U2", "2.0")}},
+		Chunk{Type: CMessages, Timestamp: 3, ChannelID: "C2", Messages: []slack.Message{msg("U1", "3.0")}},
+		Chunk{Type: CTrailer, Timestamp: 4, Trailer: &Trailer{RunIDs: []string{"run-1"}}},
+	)
+	var seen []ChunkType
+	var buf bytes.Buffer
+	err := Rewrite(context.Background(), &buf, in, func(c *Chunk) ([]Chunk, error) {
+		seen = append(seen, c.Type)
+		switch {
+		case c.Type == CUsers:
+			return nil, nil // dropped
+		case c.ChannelID == "C1":
+			// drop the messages of U2
+			c.Messages = c.Messages[:1]
+		}
+		return []Chunk{*c}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []ChunkType{CUsers, CMessages, CMessages}, seen, "trailer must not be passed")
+
+	rep, err := Audit(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.True(t, rep.OK(), rep.Breaks)
+	assert.Equal(t, 3, rep.Chunks)
+	assert.Contains(t, buf.String(), `"id":"C1","n":1,`, "count must be updated")
+
+	f, err := FromReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	tr, err := f.Trailer()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"run-1"}, tr.RunIDs)
+	assert.Equal(t, ChannelStats{Senders: []string{"U1"}, MessageCount: 1, First: "1.0", Last: "1.0"}, tr.Channels["C1"])
+	assert.Equal(t, 1, tr.Channels["C2"].MessageCount)
+
+	t.Run("error", func(t *testing.T) {
+		in := marshalChunks(Chunk{Type: CUsers}, Chunk{Type: CChannels})
+		errTest := errors.New("test")
+		err := Rewrite(context.Background(), &bytes.Buffer{}, in, func(c *Chunk) ([]Chunk, error) {
+			if c.Type == CChannels {
+				return nil, errTest
+			}
+			return []Chunk{*c}, nil
+		})
+		assert.ErrorIs(t, err, errTest)
+		assert.EqualError(t, err, "chunk 2: test")
+	})
+}
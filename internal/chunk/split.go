@@ -0,0 +1,199 @@
+package chunk
+
+// In this file: splitting the single file recording into the chunk directory.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/rusq/slack"
+)
+
+// SplitRecording reads the chunk recording from r, for example, the one
+// produced by "slackdump tools record stream", and writes its chunks into the
+// chunk files in the directory cd, laid out the same way as the archive
+// command does it:  all chunks related to a channel, including its threads
+// and files, go to the channel file, and users, channels and workspace
+// information go to their respective files.
+//
+// The recording is read sequentially, so r does not need to be seekable.
+// Trailers of the recording are replaced with a trailer in each of the output
+// files, that retains the run IDs of the recording.
+//
+// If only a thread was recorded for a channel, the thread parent is written
+// as the channel message, so that the thread can be found, when the channel
+// is read.
+func SplitRecording(ctx context.Context, cd *Directory, r io.Reader) error {
+	sp := splitter{cd: cd, files: make(map[FileID]*splitFile), channels: make(map[string]*splitChannel)}
+	dec := json.NewDecoder(r)
+	var err error
+	for n := 1; ; n++ {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		var c Chunk
+		if err = dec.Decode(&c); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			} else {
+				err = fmt.Errorf("chunk %d: %w", n, err)
+			}
+			break
+		}
+		if err = sp.add(&c); err != nil {
+			err = fmt.Errorf("chunk %d: %w", n, err)
+			break
+		}
+	}
+	if err == nil {
+		err = sp.addThreadParents()
+	}
+	return errors.Join(err, sp.close())
+}
+
+// splitter distributes the chunks between the chunk files.
+type splitter struct {
+	cd       *Directory
+	files    map[FileID]*splitFile
+	channels map[string]*splitChannel
+	runIDs   []string
+}
+
+// splitChannel tracks the messages of the channel, to detect threads
+// recorded without the channel.
+type splitChannel struct {
+	hasMessages bool
+	parents     map[string]slack.Message // thread_ts -> thread parent
+}
+
+// splitFile is the output chunk file.
+type splitFile struct {
+	wc    io.WriteCloser
+	enc   *chainEncoder
+	stats *statsAggregator
+}
+
+// splitFileID returns the ID of the file for the chunk.  It returns false, if
+// the chunk should not be written.
+func splitFileID(c *Chunk) (FileID, bool) {
+	switch c.Type {
+	case CMessages, CThreadMessages, CFiles, CChannelInfo, CChannelUsers, CBookmarks, CMessageEdit, CMessageDeleted, CPins:
+		return ToFileID(c.ChannelID, "", false), c.ChannelID != ""
+	case CUsers:
+		return FUsers, true
+	case CChannels:
+		return FChannels, true
+	case CWorkspaceInfo, CAnnotation:
+		return FWorkspace, true
+	case CSearchMessages, CSearchFiles:
+		return FSearch, true
+	case CUserGroups:
+		return FUserGroups, true
+	}
+	// trailers are regenerated, starred items are not stored in the
+	// directory.
+	return "", false
+}
+
+func (sp *splitter) add(c *Chunk) error {
+	if c.Type == CTrailer && c.Trailer != nil {
+		sp.runIDs = mergeSorted(sp.runIDs, c.Trailer.RunIDs)
+	}
+	id, ok := splitFileID(c)
+	if !ok {
+		return nil
+	}
+	switch c.Type {
+	case CMessages:
+		sp.channel(c.ChannelID).hasMessages = true
+	case CThreadMessages:
+		if c.Parent != nil {
+			ch := sp.channel(c.ChannelID)
+			if _, seen := ch.parents[c.ThreadTS]; !seen {
+				ch.parents[c.ThreadTS] = *c.Parent
+			}
+		}
+	}
+	return sp.write(id, c)
+}
+
+func (sp *splitter) channel(channelID string) *splitChannel {
+	ch, ok := sp.channels[channelID]
+	if !ok {
+		ch = &splitChannel{parents: make(map[string]slack.Message)}
+		sp.channels[channelID] = ch
+	}
+	return ch
+}
+
+func (sp *splitter) write(id FileID, c *Chunk) error {
+	f, ok := sp.files[id]
+	if !ok {
+		wc, err := sp.cd.Create(id)
+		if err != nil {
+			return err
+		}
+		f = &splitFile{wc: wc, enc: newChainEncoder(wc), stats: newStatsAggregator()}
+		sp.files[id] = f
+	}
+	if isMessageChunk(c) {
+		f.stats.add(c.ChannelID, c.Messages)
+	}
+	return f.enc.Encode(c)
+}
+
+// addThreadParents writes the thread parents as channel messages for the
+// channels that have only threads recorded.
+func (sp *splitter) addThreadParents() error {
+	for channelID, ch := range sp.channels {
+		if ch.hasMessages || len(ch.parents) == 0 {
+			continue
+		}
+		mm := make([]slack.Message, 0, len(ch.parents))
+		for _, m := range ch.parents {
+			mm = append(mm, m)
+		}
+		// newest first, as returned by the API.
+		sort.Slice(mm, func(i, j int) bool { return tsLess(mm[j].Timestamp, mm[i].Timestamp) })
+		c := Chunk{
+			Type:       CMessages,
+			Timestamp:  time.Now().UnixNano(),
+			ChannelID:  channelID,
+			IsLast:     true,
+			NumThreads: len(mm),
+			Count:      len(mm),
+			Messages:   mm,
+		}
+		if err := sp.write(ToFileID(channelID, "", false), &c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close writes the trailers and closes all output files.
+func (sp *splitter) close() error {
+	var errs error
+	for id, f := range sp.files {
+		tr := f.stats.trailer()
+		tr.RunIDs = sp.runIDs
+		trailer := Chunk{
+			Type:      CTrailer,
+			Timestamp: time.Now().UnixNano(),
+			Count:     len(f.stats.channels),
+			Trailer:   tr,
+		}
+		if err := f.enc.Encode(trailer); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%s: %w", id, err))
+		}
+		if err := f.wc.Close(); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%s: %w", id, err))
+		}
+	}
+	return errs
+}
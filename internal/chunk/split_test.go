@@ -0,0 +1,103 @@
+package chunk
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitRecording(t *testing.T) {
+	msg := func(ts, threadTS string) slack.Message {
+		return slack.Message{Msg: slack.Msg{User: "U1", Timestamp: ts, ThreadTimestamp: threadTS, Text: ts}}
+	}
+	var (
+		parent   = msg("1.0", "1.0")
+		thParent = msg("5.0", "5.0")
+	)
+	chunks := []Chunk{
+		{Type: CWorkspaceInfo, Timestamp: 1, WorkspaceInfo: &slack.AuthTestResponse{UserID: "U1"}},
+		{Type: CChannelInfo, Timestamp: 2, ChannelID: "C1", Channel: &slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}}},
+		{Type: CMessages, Timestamp: 3, ChannelID: "C1", IsLast: true, NumThreads: 1, Count: 2, Messages: []slack.Message{msg("2.0", ""), parent}},
+		{Type: CFiles, Timestamp: 4, ChannelID: "C1", ThreadTS: "1.0", Parent: &parent, Count: 1, Files: []slack.File{{ID: "F1"}}},
+		// thread-only recording of the other channel
+		{Type: CChannelInfo, Timestamp: 5, ChannelID: "C2", ThreadTS: "5.0", Channel: &slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C2"}}}},
+		{Type: CThreadMessages, Timestamp: 6, ChannelID: "C2", ThreadTS: "5.0", Parent: &thParent, IsLast: true, Count: 1, Messages: []slack.Message{msg("5.1", "5.0")}},
+		{Type: CThreadMessages, Timestamp: 7, ChannelID: "C1", ThreadTS: "1.0", Parent: &parent, IsLast: true, Count: 1, Messages: []slack.Message{msg("1.1", "1.0")}},
+		{Type: CUsers, Timestamp: 8, Count: 1, Users: []slack.User{{ID: "U1"}}},
+		{Type: CAnnotation, Timestamp: 8, Annotation: &Annotation{Text: "case #1"}},
+		{Type: CTrailer, Timestamp: 9, Trailer: &Trailer{RunIDs: []string{"run-1"}}},
+	}
+	cd, err := CreateDir(t.TempDir())
+	require.NoError(t, err)
+	defer cd.Close()
+
+	require.NoError(t, SplitRecording(context.Background(), cd, marshalChunks(chunks...)))
+
+	users, err := cd.Users()
+	require.NoError(t, err)
+	assert.Equal(t, []slack.User{{ID: "U1"}}, users)
+	wi, err := cd.WorkspaceInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "U1", wi.UserID)
+	aa, err := cd.Annotations()
+	require.NoError(t, err)
+	assert.Equal(t, []Annotation{{Text: "case #1"}}, aa)
+
+	open := func(id FileID) *File {
+		t.Helper()
+		f, err := cd.Open(id)
+		require.NoError(t, err)
+		t.Cleanup(func() { f.Close() })
+		return f
+	}
+	t.Run("channel", func(t *testing.T) {
+		f := open("C1")
+		mm, err := f.AllMessages("C1")
+		require.NoError(t, err)
+		assert.Len(t, mm, 2)
+		tm, err := f.AllThreadMessages("C1", "1.0")
+		require.NoError(t, err)
+		assert.Equal(t, []slack.Message{msg("1.1", "1.0")}, tm)
+		ok, err := f.IsComplete("C1")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		tr, err := f.Trailer()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"run-1"}, tr.RunIDs)
+		assert.Equal(t, 3, tr.Channels["C1"].MessageCount)
+		rep, err := f.Audit()
+		require.NoError(t, err)
+		assert.True(t, rep.OK(), "chunk chain is broken")
+	})
+	t.Run("thread only", func(t *testing.T) {
+		f := open("C2")
+		mm, err := f.AllMessages("C2")
+		require.NoError(t, err)
+		assert.Equal(t, []slack.Message{thParent}, mm)
+		ok, err := f.IsComplete("C2")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+	t.Run("no trailer file is created", func(t *testing.T) {
+		_, err := cd.Stat(FileID(trailerChunkID))
+		assert.Error(t, err)
+	})
+}
+
+func TestSplitRecording_invalid(t *testing.T) {
+	cd, err := CreateDir(t.TempDir())
+	require.NoError(t, err)
+	defer cd.Close()
+	r := io.MultiReader(marshalChunks(Chunk{Type: CUsers, Users: []slack.User{{ID: "U1"}}}), strings.NewReader("{garbage"))
+	err = SplitRecording(context.Background(), cd, r)
+	assert.ErrorContains(t, err, "chunk 2")
+	// the valid part is written.
+	users, err := cd.Users()
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+}
@@ -6,9 +6,11 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/rusq/fsadapter"
 	"github.com/rusq/slackdump/v3/internal/fasttime"
@@ -56,10 +58,51 @@ type State struct {
 	Files map[_id]_id `json:"files,omitempty"`
 	// ChannelInfos contains the list of all channels in the state file.
 	ChannelInfos []string `json:"channel_infos,omitempty"`
+	// RunID is the ID of the run that produced the state.
+	RunID string `json:"run_id,omitempty"`
+	// Downloads is a map of the file path, relative to FilesDir, to the
+	// download state of the file.
+	Downloads map[string]*Download `json:"downloads,omitempty"`
+	// Annotations are the operator notes recorded with the chunks, in the
+	// order they were recorded.
+	Annotations []Annotation `json:"annotations,omitempty"`
 
 	mu sync.RWMutex
 }
 
+// Annotation is the operator note.
+type Annotation struct {
+	// Time is the time when the annotation was made.
+	Time time.Time `json:"time"`
+	// Author is the name of the operator, if known.
+	Author string `json:"author,omitempty"`
+	// Text is the annotation text.
+	Text string `json:"text"`
+}
+
+// DownloadStatus is the status of the file download.
+type DownloadStatus string
+
+const (
+	DSPending  DownloadStatus = "pending"  // queued, but not downloaded yet
+	DSComplete DownloadStatus = "complete" // downloaded successfully
+	DSFailed   DownloadStatus = "failed"   // download failed
+)
+
+// Download is the download state of a single file.
+type Download struct {
+	// URL is the download URL of the file.
+	URL string `json:"url"`
+	// Size is the expected size of the file, 0 if unknown.
+	Size int64 `json:"size,omitempty"`
+	// Status is the status of the download.
+	Status DownloadStatus `json:"status"`
+	// Attempts is the number of times the download was attempted.
+	Attempts int `json:"attempts,omitempty"`
+	// Error is the last download error, if the download failed.
+	Error string `json:"error,omitempty"`
+}
+
 // Stater is an interface for types that can return a State.
 type Stater interface {
 	// State should return the State of the underlying type.
@@ -83,9 +126,9 @@ func New(filename string) *State {
 var ErrNoChunkFile = errors.New("no linked chunk file")
 
 // OpenChunks attempts to open the chunk file linked in the State. If the
-// chunk is compressed, it will be decompressed and a temporary file will be
-// created. The temporary file will be removed when the OpenChunks is
-// closed.
+// chunk is compressed (gzip, or zstd, if the file has the ".zst" suffix), it
+// will be decompressed and a temporary file will be created. The temporary
+// file will be removed when the OpenChunks is closed.
 func (st *State) OpenChunks(basePath string) (io.ReadSeekCloser, error) {
 	if st.ChunkFilename == "" {
 		return nil, ErrNoChunkFile
@@ -95,7 +138,12 @@ func (st *State) OpenChunks(basePath string) (io.ReadSeekCloser, error) {
 		return nil, err
 	}
 	if st.IsCompressed {
-		tf, err := osext.UnGZIP(f)
+		defer f.Close()
+		unpack := osext.UnGZIP
+		if osext.CompressionOf(st.ChunkFilename) == osext.CompressZSTD {
+			unpack = osext.UnZSTD
+		}
+		tf, err := unpack(f)
 		if err != nil {
 			return nil, err
 		}
@@ -138,6 +186,81 @@ func (s *State) AddFile(channelID, fileID string, path string) {
 	s.Files[channelID+":"+fileID] = path
 }
 
+// AddDownload adds the file at path to the download index as pending.  If
+// the file is already complete, it is not changed.
+func (s *State) AddDownload(path, url string, size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Downloads == nil {
+		s.Downloads = make(map[string]*Download)
+	}
+	d, ok := s.Downloads[path]
+	if !ok {
+		d = &Download{}
+		s.Downloads[path] = d
+	} else if d.Status == DSComplete {
+		return
+	}
+	d.URL, d.Size, d.Status, d.Error = url, size, DSPending, ""
+}
+
+// SetDownloadComplete marks the download of the file at path as complete.
+func (s *State) SetDownloadComplete(path string) {
+	s.setDownloadStatus(path, DSComplete, "")
+}
+
+// SetDownloadFailed marks the download of the file at path as failed with
+// the reason.
+func (s *State) SetDownloadFailed(path string, reason string) {
+	s.setDownloadStatus(path, DSFailed, reason)
+}
+
+func (s *State) setDownloadStatus(path string, status DownloadStatus, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Downloads == nil {
+		s.Downloads = make(map[string]*Download)
+	}
+	d, ok := s.Downloads[path]
+	if !ok {
+		d = &Download{}
+		s.Downloads[path] = d
+	}
+	d.Status = status
+	d.Error = reason
+	d.Attempts++
+}
+
+// DownloadPaths returns the sorted paths of the files with the download
+// status.
+func (s *State) DownloadPaths(status DownloadStatus) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var paths []string
+	for path, d := range s.Downloads {
+		if d.Status == status {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// DownloadInfo returns the download state of the file at path.
+func (s *State) DownloadInfo(path string) (Download, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	d, ok := s.Downloads[path]
+	if !ok {
+		return Download{}, false
+	}
+	return *d, true
+}
+
 // AllFiles returns all saved files for the given channel.
 func (s *State) AllFiles(channelID string) []string {
 	s.mu.RLock()
@@ -161,6 +284,14 @@ func (s *State) AddChannel(info string) {
 	s.ChannelInfos = append(s.ChannelInfos, info)
 }
 
+// AddAnnotation adds the operator note to the state.
+func (s *State) AddAnnotation(t time.Time, author, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Annotations = append(s.Annotations, Annotation{Time: t, Author: author, Text: text})
+}
+
 // FilePath returns the file path for the given file ID in the given channel.
 func (s *State) FilePath(channelID, fileID string) string {
 	s.mu.RLock()
@@ -278,6 +409,14 @@ func (s *State) SetIsCompressed(isCompressed bool) *State {
 	return s
 }
 
+func (s *State) SetRunID(id string) *State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.RunID = id
+	return s
+}
+
 func (s *State) SetIsComplete(isComplete bool) *State {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -286,17 +425,22 @@ func (s *State) SetIsComplete(isComplete bool) *State {
 	return s
 }
 
-// Save saves the state to the given file.
+// Save saves the state to the given file.  The file is replaced atomically,
+// so that the state saved earlier is not lost, if the process is killed
+// while saving.
 func (s *State) Save(filename string) error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	f, err := os.Create(filename)
+	f, err := osext.CreateAtomic(filename)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	return json.NewEncoder(f).Encode(s)
+	defer f.Abort()
+	if err := json.NewEncoder(f).Encode(s); err != nil {
+		return err
+	}
+	return f.Close()
 }
 
 // SaveFSA saves the state to the given file in the filesystem adapter.
@@ -1039,3 +1039,43 @@ func TestState_FileChannelID(t *testing.T) {
 		})
 	}
 }
+
+func TestState_downloads(t *testing.T) {
+	s := New("")
+	s.AddDownload("C1/F1-a.txt", "https://files/F1", 10)
+	s.AddDownload("C1/F2-b.txt", "https://files/F2", 20)
+	s.AddDownload("C2/F3-c.txt", "https://files/F3", 0)
+	assert.Equal(t, []string{"C1/F1-a.txt", "C1/F2-b.txt", "C2/F3-c.txt"}, s.DownloadPaths(DSPending))
+
+	s.SetDownloadComplete("C1/F1-a.txt")
+	s.SetDownloadFailed("C1/F2-b.txt", "file size mismatch")
+	assert.Equal(t, []string{"C1/F1-a.txt"}, s.DownloadPaths(DSComplete))
+	assert.Equal(t, []string{"C1/F2-b.txt"}, s.DownloadPaths(DSFailed))
+	assert.Equal(t, []string{"C2/F3-c.txt"}, s.DownloadPaths(DSPending))
+
+	// complete downloads are not reset by the repeated request.
+	s.AddDownload("C1/F1-a.txt", "https://files/F1", 10)
+	d, ok := s.DownloadInfo("C1/F1-a.txt")
+	assert.True(t, ok)
+	assert.Equal(t, Download{URL: "https://files/F1", Size: 10, Status: DSComplete, Attempts: 1}, d)
+
+	// failed downloads are requeued, and the attempts are retained.
+	s.AddDownload("C1/F2-b.txt", "https://files/F2", 20)
+	s.SetDownloadComplete("C1/F2-b.txt")
+	d, _ = s.DownloadInfo("C1/F2-b.txt")
+	assert.Equal(t, Download{URL: "https://files/F2", Size: 20, Status: DSComplete, Attempts: 2}, d)
+
+	// survives the round trip.
+	filename := filepath.Join(t.TempDir(), "state.json")
+	if err := s.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, s.Downloads, got.Downloads)
+
+	_, ok = s.DownloadInfo("missing")
+	assert.False(t, ok)
+}
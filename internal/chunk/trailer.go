@@ -0,0 +1,229 @@
+package chunk
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/fasttime"
+)
+
+// Trailer contains the aggregated per-channel statistics of the chunk file.
+// Recorder appends it as the last chunk of the file when it is closed, so
+// that the statistics are available without the full scan of the file.
+type Trailer struct {
+	// Channels is the mapping of the channel ID to the channel statistics.
+	Channels map[string]ChannelStats `json:"c"`
+	// RunIDs is the sorted list of IDs of the runs that recorded the data.
+	RunIDs []string `json:"r,omitempty"`
+
+	// Version is the chunk file format version, it is set to FormatVersion,
+	// if the trailer has the index.
+	Version int `json:"v,omitempty"`
+	// Offset is the offset of the trailer chunk in the recording.
+	Offset int64 `json:"o,omitempty"`
+	// Index maps the group ID to the offsets of the chunks in the recording,
+	// that precede the trailer.  It is not merged.
+	Index map[GroupID][]int64 `json:"x,omitempty"`
+}
+
+// ChannelStats contains aggregated statistics for a single channel.  Thread
+// messages are accounted for in the statistics of the channel they belong
+// to.
+type ChannelStats struct {
+	// Senders is the sorted list of user IDs of message senders.
+	Senders []string `json:"s,omitempty"`
+	// MessageCount is the total number of messages, including thread
+	// messages.
+	MessageCount int `json:"n"`
+	// First is the timestamp of the oldest message.
+	First string `json:"f,omitempty"`
+	// Last is the timestamp of the newest message.
+	Last string `json:"l,omitempty"`
+}
+
+// statsAggregator accumulates channel statistics while messages are being
+// recorded or scanned.
+type statsAggregator struct {
+	channels map[string]*channelAgg
+}
+
+type channelAgg struct {
+	senders     map[string]struct{}
+	count       int
+	first, last int64
+	firstTS     string
+	lastTS      string
+}
+
+func newStatsAggregator() *statsAggregator {
+	return &statsAggregator{channels: make(map[string]*channelAgg)}
+}
+
+// add accounts for the messages in the channel.
+func (sa *statsAggregator) add(channelID string, mm []slack.Message) {
+	if len(mm) == 0 {
+		return
+	}
+	ca, ok := sa.channels[channelID]
+	if !ok {
+		ca = &channelAgg{senders: make(map[string]struct{})}
+		sa.channels[channelID] = ca
+	}
+	for i := range mm {
+		if u := mm[i].User; u != "" {
+			ca.senders[u] = struct{}{}
+		} else if b := mm[i].BotID; b != "" {
+			ca.senders[b] = struct{}{}
+		}
+		ca.count++
+		ts, err := fasttime.TS2int(mm[i].Timestamp)
+		if err != nil {
+			continue
+		}
+		if ca.firstTS == "" || ts < ca.first {
+			ca.first, ca.firstTS = ts, mm[i].Timestamp
+		}
+		if ca.lastTS == "" || ts > ca.last {
+			ca.last, ca.lastTS = ts, mm[i].Timestamp
+		}
+	}
+}
+
+// isEmpty returns true if no messages were accounted for.
+func (sa *statsAggregator) isEmpty() bool {
+	return len(sa.channels) == 0
+}
+
+// trailer returns the Trailer with the accumulated statistics.
+func (sa *statsAggregator) trailer() *Trailer {
+	t := &Trailer{Channels: make(map[string]ChannelStats, len(sa.channels))}
+	for id, ca := range sa.channels {
+		senders := make([]string, 0, len(ca.senders))
+		for s := range ca.senders {
+			senders = append(senders, s)
+		}
+		sort.Strings(senders)
+		t.Channels[id] = ChannelStats{
+			Senders:      senders,
+			MessageCount: ca.count,
+			First:        ca.firstTS,
+			Last:         ca.lastTS,
+		}
+	}
+	return t
+}
+
+// Merge merges the statistics from other into t.
+func (t *Trailer) Merge(other *Trailer) {
+	if other == nil {
+		return
+	}
+	t.RunIDs = mergeSorted(t.RunIDs, other.RunIDs)
+	if t.Channels == nil {
+		t.Channels = make(map[string]ChannelStats, len(other.Channels))
+	}
+	for id, oc := range other.Channels {
+		cs, ok := t.Channels[id]
+		if !ok {
+			t.Channels[id] = oc
+			continue
+		}
+		cs.MessageCount += oc.MessageCount
+		cs.Senders = mergeSorted(cs.Senders, oc.Senders)
+		if oc.First != "" && (cs.First == "" || tsLess(oc.First, cs.First)) {
+			cs.First = oc.First
+		}
+		if oc.Last != "" && (cs.Last == "" || tsLess(cs.Last, oc.Last)) {
+			cs.Last = oc.Last
+		}
+		t.Channels[id] = cs
+	}
+}
+
+// tsLess returns true if Slack timestamp a is before b.
+func tsLess(a, b string) bool {
+	ia, errA := fasttime.TS2int(a)
+	ib, errB := fasttime.TS2int(b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return ia < ib
+}
+
+// mergeSorted merges two sorted string slices removing duplicates.
+func mergeSorted(a, b []string) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	ret := make([]string, 0, len(a)+len(b))
+	for _, s := range append(append([]string{}, a...), b...) {
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		ret = append(ret, s)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// Trailer returns the trailer of the chunk file.  If the file contains
+// several trailers (i.e. it is a concatenation of several recordings), they
+// are merged.  It returns ErrNotFound if the file has no trailer.
+func (f *File) Trailer() (*Trailer, error) {
+	trailers, err := allForID(f, trailerChunkID, func(c *Chunk) []*Trailer {
+		return []*Trailer{c.Trailer}
+	})
+	if err != nil {
+		return nil, err
+	}
+	var t Trailer
+	for _, tt := range trailers {
+		t.Merge(tt)
+	}
+	return &t, nil
+}
+
+// ChannelStats returns the per-channel statistics of the file.  If the file
+// has a trailer, the statistics are taken from it, otherwise the file is
+// scanned.
+func (f *File) ChannelStats() (map[string]ChannelStats, error) {
+	t, err := f.Trailer()
+	if err == nil {
+		return t.Channels, nil
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	// no trailer, doing it the hard way.
+	sa := newStatsAggregator()
+	if err := f.ForEach(func(c *Chunk) error {
+		if c == nil {
+			return nil
+		}
+		switch c.Type {
+		case CMessages, CThreadMessages:
+			sa.add(c.ChannelID, c.Messages)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return sa.trailer().Channels, nil
+}
+
+// ChannelStats returns the statistics for the channel.  It uses the trailer
+// of the chunk file, if it's present.  It returns ErrNotFound if there are no
+// messages for the channel.
+func (p *Player) ChannelStats(channelID string) (ChannelStats, error) {
+	p.statsOnce.Do(func() {
+		p.stats, p.statsErr = p.f.ChannelStats()
+	})
+	if p.statsErr != nil {
+		return ChannelStats{}, p.statsErr
+	}
+	cs, ok := p.stats[channelID]
+	if !ok {
+		return ChannelStats{}, ErrNotFound
+	}
+	return cs, nil
+}
@@ -0,0 +1,127 @@
+package chunk
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v3/internal/runid"
+)
+
+func testMsg(user, ts string) slack.Message {
+	return slack.Message{Msg: slack.Msg{User: user, Timestamp: ts}}
+}
+
+func TestRecorder_Close_writesTrailer(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf)
+	if err := rec.Messages(ctx, TestChannelID, 1, false, []slack.Message{
+		testMsg("U1", "1700000002.000000"),
+		testMsg("U2", "1700000001.000000"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.ThreadMessages(ctx, TestChannelID, testMsg("U2", "1700000001.000000"), false, true, []slack.Message{
+		testMsg("U3", "1700000003.000000"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil { // second close is a noop
+		t.Fatal(err)
+	}
+
+	f, err := FromReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := f.Trailer()
+	if err != nil {
+		t.Fatalf("Trailer() error = %v", err)
+	}
+	want := ChannelStats{
+		Senders:      []string{"U1", "U2", "U3"},
+		MessageCount: 3,
+		First:        "1700000001.000000",
+		Last:         "1700000003.000000",
+	}
+	assert.Equal(t, map[string]ChannelStats{TestChannelID: want}, tr.Channels)
+	assert.Equal(t, []string{runid.ID()}, tr.RunIDs)
+
+	p := NewPlayerFromFile(f)
+	got, err := p.ChannelStats(TestChannelID)
+	if err != nil {
+		t.Fatalf("ChannelStats() error = %v", err)
+	}
+	assert.Equal(t, want, got)
+	if _, err := p.ChannelStats("CUNKNOWN"); err != ErrNotFound {
+		t.Errorf("ChannelStats() error = %v, want %v", err, ErrNotFound)
+	}
+	// trailer must not leak into the channel list
+	assert.Equal(t, []string{TestChannelID}, f.AllChannelIDs())
+}
+
+func TestRecorder_Close_concurrentUsers(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf)
+	if err := rec.Messages(ctx, TestChannelID, 0, true, []slack.Message{testMsg("U1", "1700000001.000000")}); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := rec.Users(ctx, []slack.User{{ID: "U1"}}); err != nil {
+			t.Error(err)
+		}
+	}()
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	f, err := FromReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Trailer(); err != nil {
+		t.Fatalf("Trailer() error = %v", err)
+	}
+}
+
+func TestFile_ChannelStats_noTrailer(t *testing.T) {
+	f, err := FromReader(marshalChunks(testThreads...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.ChannelStats()
+	if err != nil {
+		t.Fatalf("ChannelStats() error = %v", err)
+	}
+	cs := got[TestChannelID]
+	assert.Equal(t, 6, cs.MessageCount)
+	assert.Equal(t, "1234567890.123456", cs.First)
+}
+
+func TestTrailer_Merge(t *testing.T) {
+	tr := &Trailer{Channels: map[string]ChannelStats{
+		"C1": {Senders: []string{"U1"}, MessageCount: 1, First: "2.0", Last: "2.0"},
+	}, RunIDs: []string{"run-b"}}
+	tr.Merge(&Trailer{Channels: map[string]ChannelStats{
+		"C1": {Senders: []string{"U0", "U1"}, MessageCount: 2, First: "1.0", Last: "3.0"},
+		"C2": {Senders: []string{"U2"}, MessageCount: 1, First: "5.0", Last: "5.0"},
+	}, RunIDs: []string{"run-a", "run-b"}})
+	assert.Equal(t, []string{"run-a", "run-b"}, tr.RunIDs)
+	assert.Equal(t, map[string]ChannelStats{
+		"C1": {Senders: []string{"U0", "U1"}, MessageCount: 3, First: "1.0", Last: "3.0"},
+		"C2": {Senders: []string{"U2"}, MessageCount: 1, First: "5.0", Last: "5.0"},
+	}, tr.Channels)
+}
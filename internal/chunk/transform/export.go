@@ -15,6 +15,7 @@ import (
 	"github.com/rusq/slack"
 
 	"github.com/rusq/slackdump/v3/export"
+	"github.com/rusq/slackdump/v3/internal/activity"
 	"github.com/rusq/slackdump/v3/internal/chunk"
 	"github.com/rusq/slackdump/v3/internal/fasttime"
 	"github.com/rusq/slackdump/v3/internal/structures"
@@ -37,17 +38,81 @@ func ExpWithUsers(users []slack.User) ExpCvtOption {
 	}
 }
 
+// ExpWithMembers enables writing of the channel membership snapshot to
+// the [MembersFile] in each channel directory.
+func ExpWithMembers(enabled bool) ExpCvtOption {
+	return func(t *ExpConverter) {
+		t.members = enabled
+	}
+}
+
+// ExpWithParticipants enables writing of the participants summary of the
+// channel to the [ParticipantsFile] in each channel directory.
+func ExpWithParticipants(enabled bool) ExpCvtOption {
+	return func(t *ExpConverter) {
+		t.participants = enabled
+	}
+}
+
+// ExpWithRFC3339 enables adding the RFC 3339 times of the message and thread
+// timestamps to each exported message.
+func ExpWithRFC3339(enabled bool) ExpCvtOption {
+	return func(t *ExpConverter) {
+		t.rfc3339 = enabled
+	}
+}
+
+// ExpWithLayout sets the layout of the conversation directories.  Default is
+// [LayoutFlat].
+func ExpWithLayout(l Layout) ExpCvtOption {
+	return func(t *ExpConverter) {
+		if l != "" {
+			t.layout = l
+		}
+	}
+}
+
+// ExpWithMaxMessages sets the maximum number of messages in the day file.
+// The days with more messages are split into the part files, named
+// YYYY-MM-DD-partN.json.  Zero means no limit.
+func ExpWithMaxMessages(n int) ExpCvtOption {
+	return func(t *ExpConverter) {
+		t.maxMessages = n
+	}
+}
+
+// ExpWithPartsFunc sets the function, that is called for each day file, that
+// was split into the part files.  It may be called concurrently.
+func ExpWithPartsFunc(fn func(day string, parts []string)) ExpCvtOption {
+	return func(t *ExpConverter) {
+		t.partsFn = fn
+	}
+}
+
 type ExpConverter struct {
 	cd      *chunk.Directory
 	fsa     fsadapter.FS
 	users   []slack.User
 	msgFunc []msgUpdFunc
+	members bool // write members.json for each channel
+	// participants enables writing of participants.json for each channel.
+	participants bool
+	// rfc3339 enables the RFC 3339 times in the messages.
+	rfc3339 bool
+	layout  Layout
+	// maxMessages is the maximum number of messages in the day file, zero
+	// means no limit.
+	maxMessages int
+	partsFn     func(day string, parts []string)
+	// slackImport enables the Slack import format.
+	slackImport bool
 }
 
 func NewExpConverter(cd *chunk.Directory, fsa fsadapter.FS, opt ...ExpCvtOption) *ExpConverter {
 	e := &ExpConverter{
-		cd:  cd,
-		fsa: fsa,
+		cd:     cd,
+		fsa:    fsa,
+		layout: LayoutFlat,
 	}
 	for _, o := range opt {
 		o(e)
@@ -90,23 +155,128 @@ func (e *ExpConverter) Convert(ctx context.Context, id chunk.FileID) error {
 	if err := e.writeMessages(ctx, cf, ci); err != nil {
 		return err
 	}
+	if e.members {
+		if err := e.writeMembers(cf, ci); err != nil {
+			return err
+		}
+	}
+	if err := e.writePins(cf, ci); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const (
+	// PinsFile is the name of the pinned items file within the channel
+	// directory.
+	PinsFile = "pins.json"
+	// BookmarksFile is the name of the bookmarks file within the channel
+	// directory.
+	BookmarksFile = "bookmarks.json"
+)
+
+// writePins writes the pinned items and the bookmarks of the channel ci to
+// the channel directory, if they were recorded in the chunk file.
+func (e *ExpConverter) writePins(cf *chunk.File, ci *slack.Channel) error {
+	pins, err := cf.ChannelPins(ci.ID)
+	switch {
+	case errors.Is(err, chunk.ErrNotFound):
+	case err != nil:
+		return fmt.Errorf("error reading pins of %q: %w", ci.ID, err)
+	default:
+		if err := e.writeJSON(filepath.Join(e.layout.Dir(ci), PinsFile), append([]slack.Item{}, pins...)); err != nil {
+			return fmt.Errorf("error writing pins: %w", err)
+		}
+	}
+	bookmarks, err := cf.ChannelBookmarks(ci.ID)
+	switch {
+	case errors.Is(err, chunk.ErrNotFound):
+	case err != nil:
+		return fmt.Errorf("error reading bookmarks of %q: %w", ci.ID, err)
+	default:
+		if err := e.writeJSON(filepath.Join(e.layout.Dir(ci), BookmarksFile), append([]slack.Bookmark{}, bookmarks...)); err != nil {
+			return fmt.Errorf("error writing bookmarks: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeJSON writes the indented JSON representation of v to the file name.
+func (e *ExpConverter) writeJSON(name string, v any) error {
+	wc, err := e.fsa.Create(name)
+	if err != nil {
+		return fmt.Errorf("error creating file in adapter: %w", err)
+	}
+	defer wc.Close()
+	enc := json.NewEncoder(wc)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// MembersFile is the name of the channel membership snapshot file within
+// the channel directory.
+const MembersFile = "members.json"
+
+// ChannelMembers is the channel membership snapshot, as it was at the time of
+// the export.
+type ChannelMembers struct {
+	ChannelID  string    `json:"channel_id"`
+	Name       string    `json:"name,omitempty"`
+	SnapshotAt time.Time `json:"snapshot_at"`
+	Members    []string  `json:"members"`
+}
 
+// writeMembers writes the membership snapshot of the channel ci, recorded in
+// the chunk file, to the channel directory.
+func (e *ExpConverter) writeMembers(cf *chunk.File, ci *slack.Channel) error {
+	users, err := cf.ChannelUsers(ci.ID)
+	if err != nil {
+		if !errors.Is(err, chunk.ErrNotFound) {
+			return fmt.Errorf("error reading members of %q: %w", ci.ID, err)
+		}
+		slog.Warn("channel members were not recorded", "channel", ci.ID)
+	}
+	makeUniqueStrings(&users)
+	cm := ChannelMembers{
+		ChannelID:  ci.ID,
+		Name:       ci.Name,
+		SnapshotAt: time.Now().UTC().Truncate(time.Second),
+		Members:    append([]string{}, users...),
+	}
+	wc, err := e.fsa.Create(filepath.Join(e.layout.Dir(ci), MembersFile))
+	if err != nil {
+		return fmt.Errorf("error creating file in adapter: %w", err)
+	}
+	defer wc.Close()
+	enc := json.NewEncoder(wc)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(cm); err != nil {
+		return fmt.Errorf("error encoding members: %w", err)
+	}
 	return nil
 }
 
 func (e *ExpConverter) writeMessages(ctx context.Context, pl *chunk.File, ci *slack.Channel) error {
 	lg := slog.With("in", "writeMessages", "channel", ci.ID)
 	uidx := types.Users(e.users).IndexByID()
-	trgdir := ExportChanName(ci)
+	trgdir := e.layout.Dir(ci)
 
 	var mm []export.ExportMessage = make([]export.ExportMessage, 0, 100)
 	var prevDt string
 	var currDt string
+	var act activity.Collector
 	if err := pl.Sorted(ctx, false, func(ts time.Time, m *slack.Message) error {
+		if e.slackImport {
+			if !importable(m) {
+				return nil
+			}
+			toImportMessage(m)
+		}
 		currDt = ts.Format("2006-01-02")
 		if currDt != prevDt || prevDt == "" {
 			if prevDt != "" {
-				if err := e.writeout(filepath.Join(trgdir, prevDt+".json"), mm); err != nil {
+				if err := e.writeDay(trgdir, prevDt, mm); err != nil {
 					return err
 				}
 			}
@@ -139,7 +309,15 @@ func (e *ExpConverter) writeMessages(ctx context.Context, pl *chunk.File, ci *sl
 			}
 		}
 
-		mm = append(mm, *toExportMessage(m, thread, uidx[m.User]))
+		if e.participants {
+			act.AddMessage(ci.ID, m)
+		}
+		em := toExportMessage(m, thread, uidx[m.User])
+		if e.rfc3339 {
+			em.TSRFC3339 = structures.SlackTSToRFC3339(m.Timestamp)
+			em.ThreadTSRFC3339 = structures.SlackTSToRFC3339(m.ThreadTimestamp)
+		}
+		mm = append(mm, *em)
 		return nil
 	}); err != nil {
 		return fmt.Errorf("sorted callback error: %w", err)
@@ -147,7 +325,12 @@ func (e *ExpConverter) writeMessages(ctx context.Context, pl *chunk.File, ci *sl
 
 	// flush the last day.
 	if len(mm) > 0 {
-		if err := e.writeout(filepath.Join(trgdir, prevDt+".json"), mm); err != nil {
+		if err := e.writeDay(trgdir, prevDt, mm); err != nil {
+			return err
+		}
+	}
+	if e.participants {
+		if err := e.writeParticipants(ci, &act); err != nil {
 			return err
 		}
 	}
@@ -155,6 +338,52 @@ func (e *ExpConverter) writeMessages(ctx context.Context, pl *chunk.File, ci *sl
 	return nil
 }
 
+// ParticipantsFile is the name of the participants summary file within the
+// channel directory.
+const ParticipantsFile = "participants.json"
+
+// writeParticipants writes the summary of the users, that posted in the
+// channel ci, counted by the collector act, to the channel directory.
+func (e *ExpConverter) writeParticipants(ci *slack.Channel, act *activity.Collector) error {
+	p := activity.Participants{ChannelID: ci.ID, Name: ci.Name, Participants: []activity.Participant{}}
+	if idx := act.Index([]slack.Channel{*ci}, e.users); len(idx.Channels) > 0 {
+		p = idx.Channels[0]
+	}
+	wc, err := e.fsa.Create(filepath.Join(e.layout.Dir(ci), ParticipantsFile))
+	if err != nil {
+		return fmt.Errorf("error creating file in adapter: %w", err)
+	}
+	defer wc.Close()
+	enc := json.NewEncoder(wc)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(p); err != nil {
+		return fmt.Errorf("error encoding participants: %w", err)
+	}
+	return nil
+}
+
+// writeDay writes the messages of the day to the day file in the directory
+// dir.  If there are more messages than the limit, they are split into the
+// part files.
+func (e *ExpConverter) writeDay(dir, day string, mm []export.ExportMessage) error {
+	filename := filepath.Join(dir, day+".json")
+	if e.maxMessages <= 0 || len(mm) <= e.maxMessages {
+		return e.writeout(filename, mm)
+	}
+	var parts []string
+	for i, n := 0, 1; i < len(mm); i, n = i+e.maxMessages, n+1 {
+		part := filepath.Join(dir, fmt.Sprintf("%s-part%d.json", day, n))
+		if err := e.writeout(part, mm[i:min(i+e.maxMessages, len(mm))]); err != nil {
+			return err
+		}
+		parts = append(parts, part)
+	}
+	if e.partsFn != nil {
+		e.partsFn(filename, parts)
+	}
+	return nil
+}
+
 func (e *ExpConverter) writeout(filename string, mm []export.ExportMessage) error {
 	wc, err := e.fsa.Create(filename)
 	if err != nil {
@@ -171,6 +400,24 @@ func (e *ExpConverter) writeout(filename string, mm []export.ExportMessage) erro
 
 type msgUpdFunc func(*slack.Channel, *slack.Message) error
 
+// normaliseBlocks replaces the missing blocks of the message and its
+// attachments with empty lists.  The slack library encodes the missing blocks
+// as null, and the export viewers, such as slack-export-viewer, fail to
+// iterate over them.
+func normaliseBlocks(m *slack.Msg) {
+	if m == nil {
+		return
+	}
+	if m.Blocks.BlockSet == nil {
+		m.Blocks.BlockSet = []slack.Block{}
+	}
+	for i := range m.Attachments {
+		if m.Attachments[i].Blocks.BlockSet == nil {
+			m.Attachments[i].Blocks.BlockSet = []slack.Block{}
+		}
+	}
+}
+
 // toExportMessage converts a slack message m to an export message, populating
 // the fields that are not present in the original message.  To populate the
 // count of replies and reply users on a lead message of a thread, it needs
@@ -180,11 +427,16 @@ type msgUpdFunc func(*slack.Channel, *slack.Message) error
 // Export adds the "profile" field on each message with basic profile
 // information about the poster.
 func toExportMessage(m *slack.Message, thread []slack.Message, user *slack.User) *export.ExportMessage {
+	for _, msg := range []*slack.Msg{&m.Msg, m.SubMessage, m.PreviousMessage} {
+		normaliseBlocks(msg)
+	}
 	// export message
 	em := export.ExportMessage{
-		Msg:        &m.Msg,
-		UserTeam:   m.Team,
-		SourceTeam: m.Team,
+		Msg:             &m.Msg,
+		UserTeam:        m.Team,
+		SourceTeam:      m.Team,
+		SubMessage:      m.SubMessage,
+		PreviousMessage: m.PreviousMessage,
 	}
 
 	// add user profile
@@ -261,10 +513,24 @@ func (t *ExpConverter) WriteIndex() error {
 	if err != nil {
 		return fmt.Errorf("failed to get the workspace info: %w", err)
 	}
+	if err := t.writeWorkspace(wsp); err != nil {
+		return err
+	}
+	if err := t.writeUserGroups(); err != nil {
+		return err
+	}
+	if t.slackImport {
+		if err := t.writeIntegrationLogs(); err != nil {
+			return err
+		}
+	}
 	chans, err := t.cd.Channels() // this might read the channel files if it doesn't find the channels list chunks.
 	if err != nil {
 		return fmt.Errorf("error indexing channels: %w", err)
 	}
+	if t.layout == LayoutByType {
+		return t.writeIndexByType(chans, wsp.UserID)
+	}
 	eidx, err := structures.MakeExportIndex(chans, t.users, wsp.UserID)
 	if err != nil {
 		return fmt.Errorf("error creating export index: %w", err)
@@ -275,6 +541,83 @@ func (t *ExpConverter) WriteIndex() error {
 	return nil
 }
 
+// WorkspaceFile is the name of the workspace description file in the root
+// of the export.
+const WorkspaceFile = "workspace.json"
+
+// WorkspaceDescription is the content of the [WorkspaceFile], it describes
+// the workspace the export came from.  The extended workspace information is
+// only present, if it was recorded.
+type WorkspaceDescription struct {
+	*slack.AuthTestResponse
+	*types.Workspace
+}
+
+// writeWorkspace writes the [WorkspaceFile].
+func (t *ExpConverter) writeWorkspace(wsp *slack.AuthTestResponse) error {
+	wd, err := t.cd.WorkspaceDetails()
+	if err != nil && !errors.Is(err, chunk.ErrNotFound) {
+		return fmt.Errorf("error reading the workspace details: %w", err)
+	}
+	wc, err := t.fsa.Create(WorkspaceFile)
+	if err != nil {
+		return fmt.Errorf("error creating file in adapter: %w", err)
+	}
+	defer wc.Close()
+	enc := json.NewEncoder(wc)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(WorkspaceDescription{AuthTestResponse: wsp, Workspace: wd}); err != nil {
+		return fmt.Errorf("error encoding the workspace description: %w", err)
+	}
+	return nil
+}
+
+// UserGroupsFile is the name of the user groups file in the root of the
+// export.
+const UserGroupsFile = "usergroups.json"
+
+// writeUserGroups writes the [UserGroupsFile], if the user groups were
+// recorded.
+func (t *ExpConverter) writeUserGroups() error {
+	groups, err := t.cd.UserGroups()
+	if err != nil {
+		if errors.Is(err, chunk.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("error reading the user groups: %w", err)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+	if err := t.writeJSON(UserGroupsFile, append([]slack.UserGroup{}, groups...)); err != nil {
+		return fmt.Errorf("error writing the user groups: %w", err)
+	}
+	return nil
+}
+
+// writeIndexByType writes the export index files for each of the
+// conversation type directories of the [LayoutByType].  Each directory gets
+// the full list of users, as the messages may reference the users that are
+// not members of the conversations.
+func (t *ExpConverter) writeIndexByType(chans []slack.Channel, currentUserID string) error {
+	byType := make(map[string][]slack.Channel, 4)
+	for _, ch := range chans {
+		dir := ConvTypeDir(&ch)
+		byType[dir] = append(byType[dir], ch)
+	}
+	for _, dir := range []string{DirChannels, DirPrivate, DirMPIMs, DirDMs} {
+		if len(byType[dir]) == 0 {
+			continue
+		}
+		eidx, err := structures.MakeExportIndex(byType[dir], t.users, currentUserID)
+		if err != nil {
+			return fmt.Errorf("error creating export index for %s: %w", dir, err)
+		}
+		if err := eidx.Marshal(subdirFS{fs: t.fsa, dir: dir}); err != nil {
+			return fmt.Errorf("error writing export index for %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
 func (t *ExpConverter) HasUsers() bool {
 	return len(t.users) > 0
 }
@@ -0,0 +1,155 @@
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+)
+
+// compatMessages are the messages, that exercise the fields rendered by
+// slack-export-viewer: user profiles, rich text and section blocks, link
+// unfurls, bot messages, edited messages and threads.
+const compatMessages = `[
+{"type":"message","user":"U1","text":"see <https://example.com>","ts":"1700000000.000100","team":"T1",
+ "blocks":[
+  {"type":"rich_text","block_id":"b1","elements":[
+   {"type":"rich_text_section","elements":[
+    {"type":"text","text":"see ","style":{"bold":true}},
+    {"type":"link","url":"https://example.com","text":"example"},
+    {"type":"emoji","name":"smile","unicode":"1f604"},
+    {"type":"user","user_id":"U2"},
+    {"type":"channel","channel_id":"C1"},
+    {"type":"broadcast","range":"here"}
+   ]},
+   {"type":"rich_text_list","style":"bullet","indent":1,"elements":[{"type":"rich_text_section","elements":[{"type":"text","text":"item"}]}]},
+   {"type":"rich_text_quote","elements":[{"type":"text","text":"quote"}]},
+   {"type":"rich_text_preformatted","border":0,"elements":[{"type":"text","text":"code"}]}
+  ]},
+  {"type":"section","block_id":"b2","text":{"type":"mrkdwn","text":"*hi*"},"accessory":{"type":"image","image_url":"https://example.com/a.png","alt_text":"alt"}},
+  {"type":"context","block_id":"b3","elements":[{"type":"mrkdwn","text":"ctx"}]},
+  {"type":"divider","block_id":"b4"}
+ ],
+ "attachments":[{"id":1,"color":"36a64f","fallback":"Example: Title","from_url":"https://example.com","original_url":"https://example.com","service_name":"Example","service_icon":"https://example.com/favicon.ico","title":"Title","title_link":"https://example.com","text":"Description","pretext":"pre","image_url":"https://example.com/i.png","thumb_url":"https://example.com/t.png","author_name":"Author","author_link":"https://example.com/a","author_icon":"https://example.com/ai.png","fields":[{"title":"F","value":"V","short":true}],"footer":"Footer","footer_icon":"https://example.com/f.png","mrkdwn_in":["text"],"blocks":[],"ts":1700000000}],
+ "reactions":[{"name":"thumbsup","users":["U2"],"count":1}]
+},
+{"type":"message","subtype":"bot_message","bot_id":"B1","username":"robot","icons":{"image_48":"https://example.com/b.png"},"text":"beep","ts":"1700000000.000200"},
+{"type":"message","subtype":"message_changed","hidden":true,"ts":"1700000000.000300",
+ "message":{"type":"message","user":"U1","text":"edited","ts":"1700000000.000100","edited":{"user":"U1","ts":"1700000000.000300"}},
+ "previous_message":{"type":"message","user":"U1","text":"original","ts":"1700000000.000100"}},
+{"type":"message","user":"U1","text":"parent","ts":"1700000000.000400","thread_ts":"1700000000.000400","reply_count":1,"latest_reply":"1700000000.000500"}
+]`
+
+const compatReplies = `[
+{"type":"message","user":"U1","text":"parent","ts":"1700000000.000400","thread_ts":"1700000000.000400","reply_count":1,"latest_reply":"1700000000.000500"},
+{"type":"message","user":"U2","text":"reply","ts":"1700000000.000500","thread_ts":"1700000000.000400","parent_user_id":"U1"}
+]`
+
+// TestExpConverter_viewerCompat verifies that the export output has the
+// fields that slack-export-viewer reads, and that the blocks and attachments
+// are written exactly as they were recorded.
+func TestExpConverter_viewerCompat(t *testing.T) {
+	ctx := context.Background()
+	var mm, replies []slack.Message
+	require.NoError(t, json.Unmarshal([]byte(compatMessages), &mm))
+	require.NoError(t, json.Unmarshal([]byte(compatReplies), &replies))
+
+	cd, err := chunk.CreateDir(t.TempDir())
+	require.NoError(t, err)
+	defer cd.Close()
+	wc, err := cd.Create(chunk.ToFileID("C1", "", false))
+	require.NoError(t, err)
+	rec := chunk.NewRecorder(wc)
+	ch := &slack.Channel{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1"}}}
+	require.NoError(t, rec.ChannelInfo(ctx, ch, ""))
+	require.NoError(t, rec.ChannelUsers(ctx, "C1", "", []string{"U1", "U2"}))
+	require.NoError(t, rec.Messages(ctx, "C1", 1, true, mm))
+	require.NoError(t, rec.ThreadMessages(ctx, "C1", replies[0], false, true, replies[1:]))
+	require.NoError(t, rec.Close())
+	require.NoError(t, wc.Close())
+
+	users := []slack.User{
+		{ID: "U1", Name: "alice", Profile: slack.UserProfile{RealName: "Alice A", DisplayName: "alice", Image72: "https://example.com/u1.png"}},
+		{ID: "U2", Name: "bob", Profile: slack.UserProfile{RealName: "Bob B", DisplayName: "bob", Image72: "https://example.com/u2.png"}},
+	}
+	outdir := t.TempDir()
+	cvt := NewExpConverter(cd, fsadapter.NewDirectory(outdir), ExpWithUsers(users))
+	require.NoError(t, cvt.Convert(ctx, chunk.ToFileID("C1", "", false)))
+
+	files, err := filepath.Glob(filepath.Join(outdir, "general", "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	var got []map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+	byTS := make(map[string]map[string]any, len(got))
+	for _, m := range got {
+		byTS[m["ts"].(string)] = m
+	}
+	require.Len(t, byTS, 5)
+
+	t.Run("user message", func(t *testing.T) {
+		m := byTS["1700000000.000100"]
+		for _, key := range []string{"type", "user", "text", "ts", "blocks", "attachments", "reactions", "user_profile", "user_team", "source_team"} {
+			assert.Contains(t, m, key)
+		}
+		profile := m["user_profile"].(map[string]any)
+		for _, key := range []string{"real_name", "display_name", "name", "image_72"} {
+			assert.Contains(t, profile, key)
+		}
+		assert.Equal(t, "Alice A", profile["real_name"])
+
+		var src []map[string]any
+		require.NoError(t, json.Unmarshal([]byte(compatMessages), &src))
+		assertJSONEqual(t, src[0]["attachments"], m["attachments"])
+		assertJSONEqual(t, src[0]["blocks"], m["blocks"])
+	})
+	t.Run("bot message", func(t *testing.T) {
+		m := byTS["1700000000.000200"]
+		assert.Equal(t, "bot_message", m["subtype"])
+		assert.Equal(t, "B1", m["bot_id"])
+		assert.Equal(t, "robot", m["username"])
+		assert.Contains(t, m, "icons")
+		assert.NotContains(t, m, "user_profile")
+		// the viewers iterate over blocks, they must not be null.
+		assert.Equal(t, []any{}, m["blocks"])
+	})
+	t.Run("edited message", func(t *testing.T) {
+		m := byTS["1700000000.000300"]
+		assert.Equal(t, "message_changed", m["subtype"])
+		require.Contains(t, m, "message")
+		assert.Equal(t, "edited", m["message"].(map[string]any)["text"])
+		require.Contains(t, m, "previous_message")
+		assert.Equal(t, "original", m["previous_message"].(map[string]any)["text"])
+	})
+	t.Run("thread", func(t *testing.T) {
+		m := byTS["1700000000.000400"]
+		assert.Equal(t, m["ts"], m["thread_ts"])
+		assert.EqualValues(t, 1, m["reply_count"])
+		assert.Equal(t, []any{"U2"}, m["reply_users"])
+		assert.EqualValues(t, 1, m["reply_users_count"])
+		assert.Len(t, m["replies"], 1)
+		r := byTS["1700000000.000500"]
+		assert.Equal(t, "1700000000.000400", r["thread_ts"])
+		assert.Equal(t, "U1", r["parent_user_id"])
+	})
+}
+
+// assertJSONEqual asserts that want and got are equal, once encoded as JSON.
+func assertJSONEqual(t *testing.T, want, got any) {
+	t.Helper()
+	w, err := json.Marshal(want)
+	require.NoError(t, err)
+	g, err := json.Marshal(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(w), string(g))
+}
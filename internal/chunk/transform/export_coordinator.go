@@ -148,6 +148,12 @@ func (t *ExportCoordinator) Transform(ctx context.Context, id chunk.FileID) erro
 	return nil
 }
 
+// Pending returns the number of the channels (files) waiting in the queue
+// for the transformation.
+func (t *ExportCoordinator) Pending() int {
+	return len(t.ids)
+}
+
 func (t *ExportCoordinator) worker(ctx context.Context) {
 	defer close(t.err)
 
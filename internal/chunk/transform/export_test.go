@@ -2,9 +2,18 @@ package transform
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/export"
+	"github.com/rusq/slackdump/v3/internal/activity"
 	"github.com/rusq/slackdump/v3/internal/chunk"
 	"github.com/rusq/slackdump/v3/internal/fixtures"
 )
@@ -58,3 +67,362 @@ func Test_transform(t *testing.T) {
 		})
 	}
 }
+
+func TestExpConverter_members(t *testing.T) {
+	ctx := context.Background()
+	cd, err := chunk.CreateDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cd.Close()
+	wc, err := cd.Create(chunk.ToFileID("C1", "", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := chunk.NewRecorder(wc)
+	ch := &slack.Channel{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1"}}}
+	if err := rec.ChannelInfo(ctx, ch, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.ChannelUsers(ctx, "C1", "", []string{"U2", "U1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.ChannelUsers(ctx, "C1", "", []string{"U3", "U1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Messages(ctx, "C1", 0, true, []slack.Message{{Msg: slack.Msg{Timestamp: "1700000000.000000", User: "U1"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	outdir := t.TempDir()
+	cvt := NewExpConverter(cd, fsadapter.NewDirectory(outdir), ExpWithMembers(true))
+	if err := cvt.Convert(ctx, chunk.ToFileID("C1", "", false)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(outdir, "general", MembersFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ChannelMembers
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ChannelID != "C1" || got.Name != "general" || got.SnapshotAt.IsZero() {
+		t.Errorf("unexpected snapshot header: %+v", got)
+	}
+	want := []string{"U1", "U2", "U3"}
+	if len(got.Members) != len(want) {
+		t.Fatalf("members = %v, want %v", got.Members, want)
+	}
+	for i := range want {
+		if got.Members[i] != want[i] {
+			t.Errorf("members = %v, want %v", got.Members, want)
+			break
+		}
+	}
+}
+
+func TestExpConverter_pins(t *testing.T) {
+	ctx := context.Background()
+	cd, err := chunk.CreateDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cd.Close()
+	for _, id := range []string{"C1", "C2"} {
+		wc, err := cd.Create(chunk.ToFileID(id, "", false))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := chunk.NewRecorder(wc)
+		ch := &slack.Channel{GroupConversation: slack.GroupConversation{Name: "chan-" + id, Conversation: slack.Conversation{ID: id}}}
+		if err := rec.ChannelInfo(ctx, ch, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := rec.ChannelUsers(ctx, id, "", []string{"U1"}); err != nil {
+			t.Fatal(err)
+		}
+		if id == "C1" {
+			if err := rec.Pins(ctx, id, []slack.Item{{Type: "message", Channel: id, Message: &slack.Message{Msg: slack.Msg{Timestamp: "1700000000.000000"}}}}); err != nil {
+				t.Fatal(err)
+			}
+			if err := rec.Bookmarks(ctx, id, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := rec.Messages(ctx, id, 0, true, []slack.Message{{Msg: slack.Msg{Timestamp: "1700000000.000000", User: "U1"}}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := rec.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := wc.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outdir := t.TempDir()
+	cvt := NewExpConverter(cd, fsadapter.NewDirectory(outdir))
+	for _, id := range []string{"C1", "C2"} {
+		if err := cvt.Convert(ctx, chunk.ToFileID(id, "", false)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(outdir, "chan-C1", PinsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pins []slack.Item
+	if err := json.Unmarshal(data, &pins); err != nil {
+		t.Fatal(err)
+	}
+	if len(pins) != 1 || pins[0].Message == nil || pins[0].Message.Timestamp != "1700000000.000000" {
+		t.Errorf("unexpected pins: %s", data)
+	}
+	// recorded, but empty bookmarks are written as an empty list.
+	data, err = os.ReadFile(filepath.Join(outdir, "chan-C1", BookmarksFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != "[]\n" {
+		t.Errorf("bookmarks = %q, want %q", got, "[]\n")
+	}
+	// not recorded, not written.
+	for _, name := range []string{PinsFile, BookmarksFile} {
+		if _, err := os.Stat(filepath.Join(outdir, "chan-C2", name)); !os.IsNotExist(err) {
+			t.Errorf("%s: expected not to exist, got error %v", name, err)
+		}
+	}
+}
+
+func TestExpConverter_writeUserGroups(t *testing.T) {
+	cd, err := chunk.CreateDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cd.Close()
+
+	outdir := t.TempDir()
+	cvt := NewExpConverter(cd, fsadapter.NewDirectory(outdir))
+	// not recorded, not written.
+	if err := cvt.writeUserGroups(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(outdir, UserGroupsFile)); !os.IsNotExist(err) {
+		t.Fatalf("expected %s not to exist, got error %v", UserGroupsFile, err)
+	}
+
+	wc, err := cd.Create(chunk.FUserGroups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := chunk.NewRecorder(wc)
+	if err := rec.UserGroups(context.Background(), []slack.UserGroup{{ID: "S2", Handle: "ops"}, {ID: "S1", Handle: "devs", Users: []string{"U1"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cvt.writeUserGroups(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(outdir, UserGroupsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []slack.UserGroup
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "S1" || got[1].ID != "S2" || !reflect.DeepEqual(got[0].Users, []string{"U1"}) {
+		t.Errorf("unexpected user groups: %s", data)
+	}
+}
+
+func TestExpConverter_participants(t *testing.T) {
+	ctx := context.Background()
+	cd, err := chunk.CreateDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cd.Close()
+	wc, err := cd.Create(chunk.ToFileID("C1", "", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := chunk.NewRecorder(wc)
+	ch := &slack.Channel{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1"}}}
+	if err := rec.ChannelInfo(ctx, ch, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.ChannelUsers(ctx, "C1", "", []string{"U1", "U2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Messages(ctx, "C1", 0, true, []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000000.000000", User: "U1"}},
+		{Msg: slack.Msg{Timestamp: "1700000100.000000", User: "U2"}},
+		{Msg: slack.Msg{Timestamp: "1700086400.000000", User: "U1"}},
+		{Msg: slack.Msg{Timestamp: "1700090000.000000", BotID: "B1"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	outdir := t.TempDir()
+	cvt := NewExpConverter(cd, fsadapter.NewDirectory(outdir), ExpWithParticipants(true), ExpWithUsers([]slack.User{{ID: "U1", Name: "alice"}}))
+	if err := cvt.Convert(ctx, chunk.ToFileID("C1", "", false)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(outdir, "general", ParticipantsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got activity.Participants
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := activity.Participants{ChannelID: "C1", Name: "general", Messages: 3, Participants: []activity.Participant{
+		{ID: "U1", Name: "alice", Messages: 2, FirstActivity: time.Unix(1700000000, 0).UTC(), LastActivity: time.Unix(1700086400, 0).UTC()},
+		{ID: "U2", Messages: 1, FirstActivity: time.Unix(1700000100, 0).UTC(), LastActivity: time.Unix(1700000100, 0).UTC()},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("participants = %+v, want %+v", got, want)
+	}
+}
+
+func TestExpConverter_maxMessages(t *testing.T) {
+	ctx := context.Background()
+	cd, err := chunk.CreateDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cd.Close()
+	wc, err := cd.Create(chunk.ToFileID("C1", "", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := chunk.NewRecorder(wc)
+	ch := &slack.Channel{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1"}}}
+	if err := rec.ChannelInfo(ctx, ch, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.ChannelUsers(ctx, "C1", "", []string{"U1"}); err != nil {
+		t.Fatal(err)
+	}
+	// 5 messages on 2023-11-14, 1 message on 2023-11-15.
+	mm := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1699963200.000000", User: "U1"}},
+		{Msg: slack.Msg{Timestamp: "1699963201.000000", User: "U1"}},
+		{Msg: slack.Msg{Timestamp: "1699963202.000000", User: "U1"}},
+		{Msg: slack.Msg{Timestamp: "1699963203.000000", User: "U1"}},
+		{Msg: slack.Msg{Timestamp: "1699963204.000000", User: "U1"}},
+		{Msg: slack.Msg{Timestamp: "1700049600.000000", User: "U1"}},
+	}
+	if err := rec.Messages(ctx, "C1", 0, true, mm); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	outdir := t.TempDir()
+	split := make(map[string][]string)
+	cvt := NewExpConverter(cd, fsadapter.NewDirectory(outdir), ExpWithMaxMessages(2), ExpWithPartsFunc(func(day string, parts []string) {
+		split[day] = parts
+	}))
+	if err := cvt.Convert(ctx, chunk.ToFileID("C1", "", false)); err != nil {
+		t.Fatal(err)
+	}
+	day := filepath.Join("general", "2023-11-14.json")
+	wantParts := []string{
+		filepath.Join("general", "2023-11-14-part1.json"),
+		filepath.Join("general", "2023-11-14-part2.json"),
+		filepath.Join("general", "2023-11-14-part3.json"),
+	}
+	if !reflect.DeepEqual(split, map[string][]string{day: wantParts}) {
+		t.Errorf("parts = %v, want %v", split, wantParts)
+	}
+	if _, err := os.Stat(filepath.Join(outdir, day)); !os.IsNotExist(err) {
+		t.Errorf("day file %s exists, err = %v", day, err)
+	}
+	for i, want := range []int{2, 2, 1} {
+		data, err := os.ReadFile(filepath.Join(outdir, wantParts[i]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []export.ExportMessage
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != want {
+			t.Errorf("%s: got %d messages, want %d", wantParts[i], len(got), want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outdir, "general", "2023-11-15.json")); err != nil {
+		t.Errorf("day file is not written: %v", err)
+	}
+}
+
+func TestExpConverter_rfc3339(t *testing.T) {
+	ctx := context.Background()
+	cd, err := chunk.CreateDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cd.Close()
+	wc, err := cd.Create(chunk.ToFileID("C1", "", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := chunk.NewRecorder(wc)
+	ch := &slack.Channel{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1"}}}
+	if err := rec.ChannelInfo(ctx, ch, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.ChannelUsers(ctx, "C1", "", []string{"U1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Messages(ctx, "C1", 0, true, []slack.Message{{Msg: slack.Msg{Timestamp: "1700000000.000100", User: "U1"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	outdir := t.TempDir()
+	cvt := NewExpConverter(cd, fsadapter.NewDirectory(outdir), ExpWithRFC3339(true))
+	if err := cvt.Convert(ctx, chunk.ToFileID("C1", "", false)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(outdir, "general", "2023-11-14.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []export.ExportMessage
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].TSRFC3339 != "2023-11-14T22:13:20.000100Z" || got[0].ThreadTSRFC3339 != "" {
+		t.Errorf("unexpected messages: %+v", got)
+	}
+}
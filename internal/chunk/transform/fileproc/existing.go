@@ -0,0 +1,176 @@
+package fileproc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/rusq/fsadapter"
+
+	"github.com/rusq/slackdump/v3/downloader"
+	"github.com/rusq/slackdump/v3/internal/manifest"
+	"github.com/rusq/slackdump/v3/internal/mtimefs"
+)
+
+// ExistingFiles is the downloader, that skips the files recorded in the
+// manifest of the previous export.  If the previous export is available
+// locally, the files are copied or hard-linked from it, otherwise they are
+// not downloaded at all, as they are archived elsewhere.  All other files are
+// passed to the underlying downloader.
+type ExistingFiles struct {
+	dl  Downloader
+	mf  *manifest.Manifest
+	fsa fsadapter.FS
+
+	srcdir  string // local directory of the previous export.
+	linkdir string // local output directory, if files can be hard-linked.
+	tracker downloader.Tracker
+	lg      *slog.Logger
+}
+
+// ExistingOption is the option for [NewExistingFiles].
+type ExistingOption func(*ExistingFiles)
+
+// ExistingFrom sets the local directory of the previous export, the files
+// are copied from it.
+func ExistingFrom(dir string) ExistingOption {
+	return func(e *ExistingFiles) {
+		e.srcdir = dir
+	}
+}
+
+// ExistingLinkTo sets the local output directory, the files from the
+// previous export are hard-linked into it, instead of being copied.  If the
+// link fails, i.e. the directories are on different devices, the file is
+// copied.
+func ExistingLinkTo(dir string) ExistingOption {
+	return func(e *ExistingFiles) {
+		e.linkdir = dir
+	}
+}
+
+// ExistingTracker sets the tracker, that is notified of the files copied or
+// linked from the previous export.
+func ExistingTracker(t downloader.Tracker) ExistingOption {
+	return func(e *ExistingFiles) {
+		e.tracker = t
+	}
+}
+
+// ExistingLogger sets the logger.
+func ExistingLogger(lg *slog.Logger) ExistingOption {
+	return func(e *ExistingFiles) {
+		if lg != nil {
+			e.lg = lg
+		}
+	}
+}
+
+// NewExistingFiles returns the downloader, that skips the files in the
+// manifest mf of the previous export, and writes the copied files to fsa.
+// Other files are downloaded with dl.
+func NewExistingFiles(dl Downloader, mf *manifest.Manifest, fsa fsadapter.FS, opts ...ExistingOption) *ExistingFiles {
+	e := &ExistingFiles{
+		dl:  dl,
+		mf:  mf,
+		fsa: fsa,
+		lg:  slog.Default(),
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	return e
+}
+
+// Download passes the download to the underlying downloader, as the file ID
+// is not known.
+func (e *ExistingFiles) Download(fullpath string, url string) error {
+	return e.dl.Download(fullpath, url)
+}
+
+// Enqueue skips, copies or links the file, if it is in the manifest,
+// otherwise it passes the request to the underlying downloader.
+func (e *ExistingFiles) Enqueue(req downloader.Request) error {
+	f, ok := e.mf.File(req.FileID)
+	if req.FileID == "" || !ok {
+		return e.enqueue(req)
+	}
+	lg := e.lg.With("file_id", req.FileID, "destination", req.Fullpath)
+	if e.srcdir == "" {
+		lg.Debug("file exists in the previous export, skipping")
+		return nil
+	}
+	src := filepath.Join(e.srcdir, filepath.FromSlash(f.Path))
+	if fi, err := os.Stat(src); err != nil || (f.Size > 0 && fi.Size() != f.Size) {
+		lg.Debug("file in the previous export is missing or has changed, downloading", "source", src)
+		return e.enqueue(req)
+	}
+	n, err := e.place(src, req)
+	if err != nil {
+		return fmt.Errorf("error copying %q from the previous export: %w", src, err)
+	}
+	lg.Debug("file copied from the previous export", "source", src)
+	if e.tracker != nil {
+		e.tracker.Complete(req, n)
+	}
+	return nil
+}
+
+func (e *ExistingFiles) enqueue(req downloader.Request) error {
+	if eq, ok := e.dl.(enqueuer); ok {
+		return eq.Enqueue(req)
+	}
+	return e.dl.Download(req.Fullpath, req.URL)
+}
+
+// place hard-links or copies the file src to the output.  It returns the
+// number of bytes in the file.
+func (e *ExistingFiles) place(src string, req downloader.Request) (int64, error) {
+	if e.linkdir != "" {
+		n, err := link(src, filepath.Join(e.linkdir, req.Fullpath))
+		if err == nil {
+			return n, nil
+		}
+		e.lg.Debug("unable to link the file, copying", "source", src, "error", err)
+	}
+	sf, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer sf.Close()
+	wc, err := mtimefs.Create(e.fsa, req.Fullpath, req.ModTime)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(wc, sf)
+	if err != nil {
+		wc.Close()
+		return 0, err
+	}
+	return n, wc.Close()
+}
+
+// link hard-links src to dst, creating the directories of dst.  An existing
+// dst is replaced, unless it is already the same file.
+func link(src, dst string) (int64, error) {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return 0, err
+	}
+	if di, err := os.Stat(dst); err == nil && os.SameFile(fi, di) {
+		return fi.Size(), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return 0, err
+	}
+	if err := os.Remove(dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+	if err := os.Link(src, dst); err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
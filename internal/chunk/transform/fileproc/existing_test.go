@@ -0,0 +1,91 @@
+package fileproc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rusq/fsadapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/downloader"
+	"github.com/rusq/slackdump/v3/internal/manifest"
+)
+
+func TestManifestTracker(t *testing.T) {
+	mf := manifest.New()
+	tr := NewManifestTracker(mf)
+	tr.Complete(downloader.Request{Fullpath: "C1/F1", FileID: "F1"}, 42)
+	tr.Complete(downloader.Request{Fullpath: "C1/x"}, 1) // no ID
+	tr.Failed(downloader.Request{Fullpath: "C1/F2", FileID: "F2"}, assert.AnError)
+	assert.Equal(t, 1, mf.FileCount())
+	f, ok := mf.File("F1")
+	assert.True(t, ok)
+	assert.Equal(t, manifest.File{Path: "C1/F1", Size: 42}, f)
+}
+
+func TestExistingFiles_Enqueue(t *testing.T) {
+	// previous export
+	srcdir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(srcdir, "__uploads", "F1"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcdir, "__uploads", "F1", "a.txt"), []byte("hello"), 0o644))
+	mf := manifest.New()
+	mf.AddFile("F1", manifest.File{Path: "__uploads/F1/a.txt", Size: 5})
+	mf.AddFile("F2", manifest.File{Path: "__uploads/F2/gone.txt", Size: 3}) // missing locally
+
+	reqs := []downloader.Request{
+		{Fullpath: "__uploads/F1/a.txt", URL: "https://files/F1", FileID: "F1"},
+		{Fullpath: "__uploads/F2/gone.txt", URL: "https://files/F2", FileID: "F2"},
+		{Fullpath: "__uploads/F3/new.txt", URL: "https://files/F3", FileID: "F3"},
+	}
+	enqueueAll := func(t *testing.T, e *ExistingFiles) {
+		t.Helper()
+		for _, r := range reqs {
+			require.NoError(t, e.Enqueue(r))
+		}
+	}
+
+	t.Run("copy", func(t *testing.T) {
+		outdir := t.TempDir()
+		var d recordingEnqueuer
+		tr := NewManifestTracker(manifest.New())
+		e := NewExistingFiles(&d, mf, fsadapter.NewDirectory(outdir), ExistingFrom(srcdir), ExistingTracker(tr))
+		enqueueAll(t, e)
+		assert.Equal(t, []downloader.Request{reqs[1], reqs[2]}, d.requests)
+		data, err := os.ReadFile(filepath.Join(outdir, "__uploads", "F1", "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+		_, ok := tr.mf.File("F1")
+		assert.True(t, ok, "copied file is not tracked")
+	})
+	t.Run("link", func(t *testing.T) {
+		outdir := t.TempDir()
+		var d recordingEnqueuer
+		e := NewExistingFiles(&d, mf, fsadapter.NewDirectory(outdir), ExistingFrom(srcdir), ExistingLinkTo(outdir))
+		enqueueAll(t, e)
+		assert.Equal(t, []downloader.Request{reqs[1], reqs[2]}, d.requests)
+		src, err := os.Stat(filepath.Join(srcdir, "__uploads", "F1", "a.txt"))
+		require.NoError(t, err)
+		dst, err := os.Stat(filepath.Join(outdir, "__uploads", "F1", "a.txt"))
+		require.NoError(t, err)
+		assert.True(t, os.SameFile(src, dst), "not linked")
+	})
+	t.Run("archived elsewhere", func(t *testing.T) {
+		outdir := t.TempDir()
+		var d recordingEnqueuer
+		e := NewExistingFiles(&d, mf, fsadapter.NewDirectory(outdir))
+		enqueueAll(t, e)
+		assert.Equal(t, []downloader.Request{reqs[2]}, d.requests)
+		_, err := os.Stat(filepath.Join(outdir, "__uploads", "F1", "a.txt"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+	t.Run("same directory", func(t *testing.T) {
+		var d recordingEnqueuer
+		e := NewExistingFiles(&d, mf, fsadapter.NewDirectory(srcdir), ExistingFrom(srcdir), ExistingLinkTo(srcdir))
+		require.NoError(t, e.Enqueue(reqs[0]))
+		data, err := os.ReadFile(filepath.Join(srcdir, "__uploads", "F1", "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+	})
+}
@@ -44,18 +44,39 @@ func NewSubprocessor(dl Downloader, fp func(ci *slack.Channel, f *slack.File) st
 	}
 }
 
+// enqueuer is the downloader that accepts the full download request, which
+// allows to pass the expected file size for verification.
+type enqueuer interface {
+	Enqueue(req downloader.Request) error
+}
+
 func (b Subprocessor) Files(ctx context.Context, channel *slack.Channel, msg slack.Message, ff []slack.File) error {
 	for _, f := range ff {
 		if !IsValid(&f) {
 			continue
 		}
-		if err := b.dcl.Download(b.filepath(channel, &f), f.URLPrivateDownload); err != nil {
+		if err := b.download(channel, &f); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+func (b Subprocessor) download(channel *slack.Channel, f *slack.File) error {
+	fullpath := b.filepath(channel, f)
+	url := files.DownloadURL(f)
+	if e, ok := b.dcl.(enqueuer); ok {
+		size := int64(f.Size)
+		if files.Document(f) != files.DocNone {
+			// the size of the rendered document differs from the size of
+			// its contents.
+			size = 0
+		}
+		return e.Enqueue(downloader.Request{Fullpath: fullpath, URL: url, FileID: f.ID, Size: size, ModTime: downloader.ModTime(f)})
+	}
+	return b.dcl.Download(fullpath, url)
+}
+
 // PathUpdateFunc updates the path in URLDownload and URLPrivateDownload of every
 // file in the given message slice to point to the physical downloaded file
 // location.  It can be plugged in the pipeline of Dump.
@@ -105,9 +126,12 @@ func IsValidWithReason(f *slack.File) error {
 	if _, ok := invalidModes[f.Mode]; ok {
 		return fmt.Errorf("invalid file mode %q", f.Mode)
 	}
-	if !f.IsExternal && f.Name == "" {
+	if !f.IsExternal && files.Name(f) == "" {
 		return fmt.Errorf("invalid file: external=%v, name=%q", f.IsExternal, f.Name)
 	}
+	if files.Document(f) != files.DocNone && files.DownloadURL(f) == "" {
+		return fmt.Errorf("document %q has no download URL", f.ID)
+	}
 	return nil
 }
 
@@ -123,12 +147,13 @@ type FileGetter interface {
 }
 
 // NewDownloader initializes the downloader and returns it, along with a
-// function that should be called to stop it.
-func NewDownloader(ctx context.Context, gEnabled bool, cl FileGetter, fsa fsadapter.FS, lg *slog.Logger) (sdl Downloader, stop func()) {
+// function that should be called to stop it.  Additional downloader options
+// can be passed in opts.
+func NewDownloader(ctx context.Context, gEnabled bool, cl FileGetter, fsa fsadapter.FS, lg *slog.Logger, opts ...downloader.Option) (sdl Downloader, stop func()) {
 	if !gEnabled {
 		return NoopDownloader{}, func() {}
 	} else {
-		dl := downloader.New(cl, fsa, downloader.WithLogger(lg))
+		dl := downloader.New(cl, fsa, append([]downloader.Option{downloader.WithLogger(lg)}, opts...)...)
 		if err := dl.Start(ctx); err != nil {
 			lg.Error("failed to start downloader", "error", err)
 			return NoopDownloader{}, func() {}
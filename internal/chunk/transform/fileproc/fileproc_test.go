@@ -5,9 +5,15 @@
 package fileproc
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v3/downloader"
+	"github.com/rusq/slackdump/v3/internal/chunk/state"
 	"github.com/rusq/slackdump/v3/internal/fixtures"
 )
 
@@ -74,3 +80,76 @@ func TestIsValid(t *testing.T) {
 		})
 	}
 }
+
+type recordingDownloader struct {
+	downloads []string
+	requests  []downloader.Request
+}
+
+func (d *recordingDownloader) Download(fullpath string, url string) error {
+	d.downloads = append(d.downloads, fullpath)
+	return nil
+}
+
+type recordingEnqueuer struct {
+	recordingDownloader
+}
+
+func (d *recordingEnqueuer) Enqueue(req downloader.Request) error {
+	d.requests = append(d.requests, req)
+	return nil
+}
+
+func TestSubprocessor_Files(t *testing.T) {
+	ch := &slack.Channel{}
+	ch.ID = "C1"
+	ff := []slack.File{
+		{ID: "F1", Name: "a.txt", Size: 42, URLPrivateDownload: "https://files/F1"},
+		{ID: "F2", Name: "b.txt", Mode: "tombstone"},
+	}
+	fp := func(ci *slack.Channel, f *slack.File) string { return ci.ID + "/" + f.ID }
+	t.Run("download", func(t *testing.T) {
+		var d recordingDownloader
+		if err := NewSubprocessor(&d, fp).Files(context.Background(), ch, slack.Message{}, ff); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, []string{"C1/F1"}, d.downloads)
+	})
+	t.Run("enqueue passes the size", func(t *testing.T) {
+		var d recordingEnqueuer
+		if err := NewSubprocessor(&d, fp).Files(context.Background(), ch, slack.Message{}, ff); err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, d.downloads)
+		assert.Equal(t, []downloader.Request{{Fullpath: "C1/F1", URL: "https://files/F1", FileID: "F1", Size: 42}}, d.requests)
+	})
+	t.Run("documents", func(t *testing.T) {
+		docs := []slack.File{
+			{ID: "F3", Title: "Plan", Filetype: "quip", Mode: "quip", Size: 100, URLPrivateDownload: "https://files/F3/download"},
+			{ID: "F4", Title: "Notes", Filetype: "space", Mode: "space", Size: 200, URLPrivate: "https://files/F4"},
+			{ID: "F5", Title: "Broken", Filetype: "space", Mode: "space"},
+		}
+		var d recordingEnqueuer
+		if err := NewSubprocessor(&d, MattermostFilepath).Files(context.Background(), ch, slack.Message{}, docs); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, []downloader.Request{
+			{Fullpath: "__uploads/F3/Plan.html", URL: "https://files/F3/download", FileID: "F3"},
+			{Fullpath: "__uploads/F4/Notes.html", URL: "https://files/F4", FileID: "F4"},
+		}, d.requests)
+	})
+}
+
+func TestStateTracker(t *testing.T) {
+	st := state.New("")
+	tr := NewStateTracker(st)
+	ok := downloader.Request{Fullpath: "C1/F1", URL: "https://files/F1", Size: 42}
+	bad := downloader.Request{Fullpath: "C1/F2", URL: "https://files/F2"}
+	tr.Pending(ok)
+	tr.Pending(bad)
+	tr.Complete(ok, 42)
+	tr.Failed(bad, errors.New("not today"))
+	assert.Equal(t, []string{"C1/F1"}, st.DownloadPaths(state.DSComplete))
+	d, _ := st.DownloadInfo("C1/F2")
+	assert.Equal(t, state.Download{URL: "https://files/F2", Status: state.DSFailed, Attempts: 1, Error: "not today"}, d)
+}
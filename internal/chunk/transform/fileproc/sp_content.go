@@ -0,0 +1,161 @@
+package fileproc
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/downloader"
+	"github.com/rusq/slackdump/v3/internal/chunk/transform"
+	"github.com/rusq/slackdump/v3/internal/structures/files"
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+const (
+	// ContentDir is the directory of the content-addressed file pool.
+	ContentDir = "files"
+	// ContentRefsFile is the name of the file in each conversation
+	// directory, that lists the files of the conversation in the pool.
+	ContentRefsFile = "files.json"
+)
+
+// ContentFilepath returns the path of the file in the content-addressed pool
+// for the SHA-256 sum of its contents, it keeps the extension of the file
+// name.  It is the [downloader.ContentPathFunc].
+func ContentFilepath(req downloader.Request, sum string) string {
+	return path.Join(ContentDir, sum[:2], sum+path.Ext(filepath.ToSlash(req.Fullpath)))
+}
+
+// ContentRef is the reference to the file in the pool.
+type ContentRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Path is the path of the file in the pool, relative to the export root.
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// ContentRefs collects the references of the conversation files to the
+// pool, as they are downloaded.  It must be registered as the downloader
+// tracker.
+type ContentRefs struct {
+	mu sync.Mutex
+	// files maps the download path of the file to its conversation directory
+	// and the reference.
+	files map[string]*contentFile
+}
+
+type contentFile struct {
+	dir string
+	ref ContentRef
+}
+
+var _ downloader.Tracker = (*ContentRefs)(nil)
+
+// NewContentRefs returns the empty references.
+func NewContentRefs() *ContentRefs {
+	return &ContentRefs{files: make(map[string]*contentFile)}
+}
+
+// add registers the file f of the conversation in the directory dir, that is
+// downloaded to fullpath.
+func (r *ContentRefs) add(dir, fullpath string, f *slack.File) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.files[fullpath]; ok {
+		return
+	}
+	r.files[fullpath] = &contentFile{dir: dir, ref: ContentRef{ID: f.ID, Name: files.Name(f)}}
+}
+
+func (r *ContentRefs) Pending(downloader.Request) {}
+
+func (r *ContentRefs) Complete(req downloader.Request, n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cf, ok := r.files[req.Fullpath]
+	if !ok || req.Stored == "" {
+		return
+	}
+	cf.ref.Path = filepath.ToSlash(req.Stored)
+	cf.ref.Size = n
+}
+
+func (r *ContentRefs) Failed(downloader.Request, error) {}
+
+// Write writes the references file to each conversation directory, that has
+// the downloaded files.  It must be called after the downloads are complete.
+func (r *ContentRefs) Write(fsa fsadapter.FS) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byDir := make(map[string][]ContentRef)
+	for _, cf := range r.files {
+		if cf.ref.Path == "" {
+			// not downloaded.
+			continue
+		}
+		byDir[cf.dir] = append(byDir[cf.dir], cf.ref)
+	}
+	for dir, refs := range byDir {
+		sort.Slice(refs, func(i, j int) bool { return refs[i].ID < refs[j].ID })
+		data, err := json.MarshalIndent(refs, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := fsa.WriteFile(path.Join(dir, ContentRefsFile), data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contentSubprocessor downloads the files for the content-addressed pool,
+// and registers them in the references.
+type contentSubprocessor struct {
+	Subprocessor
+	refs *ContentRefs
+	dir  func(*slack.Channel) string
+}
+
+// NewContentExport returns the export file subprocessor for the
+// content-addressed storage.  The downloader dl must be started with the
+// [downloader.WithContentPath] option set to [ContentFilepath], and refs as
+// the tracker.
+func NewContentExport(layout transform.Layout, dl Downloader, refs *ContentRefs) processor.Filer {
+	dir := transform.ExportChanName
+	if layout == transform.LayoutByType {
+		dir = func(ci *slack.Channel) string {
+			return path.Join(transform.ConvTypeDir(ci), transform.ExportChanName(ci))
+		}
+	}
+	return contentSubprocessor{
+		Subprocessor: Subprocessor{
+			dcl: dl,
+			filepath: func(ci *slack.Channel, f *slack.File) string {
+				return path.Join(dir(ci), f.ID+"-"+files.Name(f))
+			},
+		},
+		refs: refs,
+		dir:  dir,
+	}
+}
+
+func (s contentSubprocessor) Files(ctx context.Context, channel *slack.Channel, _ slack.Message, ff []slack.File) error {
+	for _, f := range ff {
+		if !IsValid(&f) {
+			continue
+		}
+		s.refs.add(s.dir(channel), s.filepath(channel, &f), &f)
+		if err := s.download(channel, &f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package fileproc
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/downloader"
+	"github.com/rusq/slackdump/v3/internal/chunk/transform"
+)
+
+func TestContentFilepath(t *testing.T) {
+	const sum = "0967115f2813a3541eaef77de9d9d5773f1c0c04314b0bbfe4ff3b3b1c55b5d5"
+	assert.Equal(t, "files/09/"+sum+".jpg", ContentFilepath(downloader.Request{Fullpath: "general/F1-cat.jpg"}, sum))
+	assert.Equal(t, "files/09/"+sum, ContentFilepath(downloader.Request{Fullpath: "general/F2-README"}, sum))
+}
+
+func TestContentRefs(t *testing.T) {
+	general := &slack.Channel{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1"}}}
+	random := &slack.Channel{GroupConversation: slack.GroupConversation{Name: "random", Conversation: slack.Conversation{ID: "C2"}}}
+	cat := slack.File{ID: "F1", Name: "cat.jpg", URLPrivateDownload: "https://files/F1"}
+	dog := slack.File{ID: "F2", Name: "dog.jpg", URLPrivateDownload: "https://files/F2"}
+	failed := slack.File{ID: "F3", Name: "gone.jpg", URLPrivateDownload: "https://files/F3"}
+
+	refs := NewContentRefs()
+	var d recordingEnqueuer
+	sp := NewContentExport(transform.LayoutFlat, &d, refs)
+	ctx := context.Background()
+	require.NoError(t, sp.Files(ctx, general, slack.Message{}, []slack.File{cat, failed}))
+	require.NoError(t, sp.Files(ctx, random, slack.Message{}, []slack.File{cat, dog}))
+
+	// the downloader reports the pool paths, the same file in both channels
+	// is stored once.
+	for _, req := range d.requests {
+		switch req.FileID {
+		case "F1":
+			req.Stored = "files/aa/aa.jpg"
+		case "F2":
+			req.Stored = "files/bb/bb.jpg"
+		default:
+			refs.Failed(req, os.ErrNotExist)
+			continue
+		}
+		refs.Complete(req, 3)
+	}
+	assert.Equal(t, []string{"general/F1-cat.jpg", "general/F3-gone.jpg", "random/F1-cat.jpg", "random/F2-dog.jpg"}, fullpaths(d.requests))
+
+	dir := t.TempDir()
+	require.NoError(t, refs.Write(fsadapter.NewDirectory(dir)))
+	assert.Equal(t, []ContentRef{
+		{ID: "F1", Name: "cat.jpg", Path: "files/aa/aa.jpg", Size: 3},
+	}, readRefs(t, filepath.Join(dir, "general", ContentRefsFile)))
+	assert.Equal(t, []ContentRef{
+		{ID: "F1", Name: "cat.jpg", Path: "files/aa/aa.jpg", Size: 3},
+		{ID: "F2", Name: "dog.jpg", Path: "files/bb/bb.jpg", Size: 3},
+	}, readRefs(t, filepath.Join(dir, "random", ContentRefsFile)))
+}
+
+func fullpaths(reqs []downloader.Request) []string {
+	var ret []string
+	for _, r := range reqs {
+		ret = append(ret, r.Fullpath)
+	}
+	return ret
+}
+
+func readRefs(t *testing.T, name string) []ContentRef {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	var refs []ContentRef
+	require.NoError(t, json.Unmarshal(data, &refs))
+	return refs
+}
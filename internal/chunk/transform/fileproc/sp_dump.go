@@ -5,6 +5,7 @@ import (
 
 	"github.com/rusq/slack"
 	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/structures/files"
 )
 
 // NewDumpSubproc returns a new Dump File Subprocessor.
@@ -16,5 +17,5 @@ func NewDumpSubproc(dl Downloader) Subprocessor {
 }
 
 func DumpFilepath(ci *slack.Channel, f *slack.File) string {
-	return filepath.Join(chunk.ToFileID(ci.ID, "", false).String(), f.ID+"-"+f.Name)
+	return filepath.Join(chunk.ToFileID(ci.ID, "", false).String(), f.ID+"-"+files.Name(f))
 }
@@ -7,6 +7,8 @@ import (
 
 	"github.com/rusq/slack"
 	"github.com/rusq/slackdump/v3/internal/chunk/transform"
+	"github.com/rusq/slackdump/v3/internal/nametmpl"
+	"github.com/rusq/slackdump/v3/internal/structures/files"
 	"github.com/rusq/slackdump/v3/processor"
 )
 
@@ -14,38 +16,74 @@ import (
 // type.  This subprocessor can be later plugged into the
 // [expproc.Conversations] processor.
 func NewExport(typ StorageType, dl Downloader) processor.Filer {
+	return NewExportLayout(typ, transform.LayoutFlat, dl)
+}
+
+// NewExportLayout is [NewExport] for the export with the given layout of the
+// conversation directories.  With [transform.LayoutByType], the files are
+// stored within the conversation type directory.
+func NewExportLayout(typ StorageType, layout transform.Layout, dl Downloader) processor.Filer {
+	var fn func(*slack.Channel, *slack.File) string
 	switch typ {
 	case STstandard:
-		return Subprocessor{
-			dcl:      dl,
-			filepath: StdFilepath,
-		}
+		fn = StdFilepath
 	case STmattermost:
-		return Subprocessor{
-			dcl:      dl,
-			filepath: MattermostFilepath,
-		}
+		fn = MattermostFilepath
 	default:
 		return nopsubproc{}
 	}
+	if layout == transform.LayoutByType {
+		fn = byTypeFilepath(fn)
+	}
+	return Subprocessor{
+		dcl:      dl,
+		filepath: fn,
+	}
+}
+
+// NewExportTemplate is [NewExportLayout] for the standard export, that names
+// the files with the file naming template t.
+func NewExportTemplate(t *nametmpl.FileTemplate, layout transform.Layout, dl Downloader) processor.Filer {
+	fn := TemplateFilepath(t)
+	if layout == transform.LayoutByType {
+		fn = byTypeFilepath(fn)
+	}
+	return Subprocessor{
+		dcl:      dl,
+		filepath: fn,
+	}
+}
+
+// TemplateFilepath returns the function, that returns the path to the file
+// generated by the file naming template t.
+func TemplateFilepath(t *nametmpl.FileTemplate) func(*slack.Channel, *slack.File) string {
+	return t.Execute
+}
+
+// byTypeFilepath prefixes the file path returned by fn with the conversation
+// type directory.
+func byTypeFilepath(fn func(*slack.Channel, *slack.File) string) func(*slack.Channel, *slack.File) string {
+	return func(ci *slack.Channel, f *slack.File) string {
+		return filepath.Join(transform.ConvTypeDir(ci), fn(ci, f))
+	}
 }
 
 // MattermostFilepath returns the path to the file within the __uploads
 // directory.
 func MattermostFilepath(_ *slack.Channel, f *slack.File) string {
-	return filepath.Join("__uploads", f.ID, f.Name)
+	return filepath.Join("__uploads", f.ID, files.Name(f))
 }
 
 // MattermostFilepathWithDir returns the path to the file within the given
 // directory, but it follows the mattermost naming pattern.
 func MattermostFilepathWithDir(dir string) func(*slack.Channel, *slack.File) string {
 	return func(_ *slack.Channel, f *slack.File) string {
-		return filepath.Join(dir, f.ID, f.Name)
+		return filepath.Join(dir, f.ID, files.Name(f))
 	}
 }
 
 func StdFilepath(ci *slack.Channel, f *slack.File) string {
-	return filepath.Join(transform.ExportChanName(ci), "attachments", fmt.Sprintf("%s-%s", f.ID, f.Name))
+	return filepath.Join(transform.ExportChanName(ci), "attachments", fmt.Sprintf("%s-%s", f.ID, files.Name(f)))
 }
 
 // nopsubproc is the no-op subprocessor.
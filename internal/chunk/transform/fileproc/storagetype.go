@@ -14,6 +14,9 @@ const (
 	STnone StorageType = iota
 	STstandard
 	STmattermost
+	// STcontent stores the files once in the content-addressed pool, see
+	// [NewContentExport].
+	STcontent
 )
 
 // Set translates the string value into the ExportType, satisfies flag.Value
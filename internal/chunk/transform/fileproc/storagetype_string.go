@@ -11,11 +11,12 @@ func _() {
 	_ = x[STnone-0]
 	_ = x[STstandard-1]
 	_ = x[STmattermost-2]
+	_ = x[STcontent-3]
 }
 
-const _StorageType_name = "nonestandardmattermost"
+const _StorageType_name = "nonestandardmattermostcontent"
 
-var _StorageType_index = [...]uint8{0, 4, 12, 22}
+var _StorageType_index = [...]uint8{0, 4, 12, 22, 29}
 
 func (i StorageType) String() string {
 	if i >= StorageType(len(_StorageType_index)-1) {
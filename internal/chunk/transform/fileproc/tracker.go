@@ -0,0 +1,62 @@
+package fileproc
+
+import (
+	"github.com/rusq/slackdump/v3/downloader"
+	"github.com/rusq/slackdump/v3/internal/chunk/state"
+	"github.com/rusq/slackdump/v3/internal/manifest"
+)
+
+// StateTracker records the file download state in the chunk state, so that
+// the failed downloads can be retried later.
+type StateTracker struct {
+	st *state.State
+}
+
+var _ downloader.Tracker = (*StateTracker)(nil)
+
+// NewStateTracker returns the download tracker that records the downloads
+// in st.  Download paths are relative to st.FilesDir.
+func NewStateTracker(st *state.State) *StateTracker {
+	return &StateTracker{st: st}
+}
+
+func (t *StateTracker) Pending(req downloader.Request) {
+	t.st.AddDownload(req.Fullpath, req.URL, req.Size)
+}
+
+func (t *StateTracker) Complete(req downloader.Request, _ int64) {
+	t.st.SetDownloadComplete(req.Fullpath)
+}
+
+func (t *StateTracker) Failed(req downloader.Request, err error) {
+	t.st.SetDownloadFailed(req.Fullpath, err.Error())
+}
+
+// ManifestTracker records the downloaded files in the export manifest, so
+// that the later exports can skip them.
+type ManifestTracker struct {
+	mf *manifest.Manifest
+}
+
+var _ downloader.Tracker = (*ManifestTracker)(nil)
+
+// NewManifestTracker returns the download tracker that records the
+// downloaded files in mf.
+func NewManifestTracker(mf *manifest.Manifest) *ManifestTracker {
+	return &ManifestTracker{mf: mf}
+}
+
+func (t *ManifestTracker) Pending(downloader.Request) {}
+
+func (t *ManifestTracker) Complete(req downloader.Request, n int64) {
+	if req.FileID == "" {
+		return
+	}
+	path := req.Fullpath
+	if req.Stored != "" {
+		path = req.Stored
+	}
+	t.mf.AddFile(req.FileID, manifest.File{Path: path, Size: n})
+}
+
+func (t *ManifestTracker) Failed(downloader.Request, error) {}
@@ -0,0 +1,91 @@
+package transform
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+)
+
+// Layout is the organisation of the conversation directories in the export.
+type Layout string
+
+const (
+	// LayoutFlat puts all conversation directories at the top level of the
+	// export, as Slack does.
+	LayoutFlat Layout = "flat"
+	// LayoutByType groups the conversations by type in the top level
+	// directories, see [ConvTypeDir].  Each of the type directories has its
+	// own channel and user listings, so that it can be ingested as a
+	// separate export.
+	LayoutByType Layout = "by-type"
+)
+
+// Conversation type directories of the [LayoutByType].
+const (
+	DirChannels = "channels" // public channels
+	DirPrivate  = "private"  // private channels
+	DirMPIMs    = "mpims"    // group direct messages
+	DirDMs      = "dms"      // direct messages
+)
+
+// String implements the flag.Value interface.
+func (l *Layout) String() string {
+	if l == nil || *l == "" {
+		return string(LayoutFlat)
+	}
+	return string(*l)
+}
+
+// Set implements the flag.Value interface.
+func (l *Layout) Set(s string) error {
+	switch ly := Layout(strings.ToLower(s)); ly {
+	case LayoutFlat, LayoutByType:
+		*l = ly
+	default:
+		return fmt.Errorf("unknown layout: %q, must be one of: %s, %s", s, LayoutFlat, LayoutByType)
+	}
+	return nil
+}
+
+// Dir returns the directory of the conversation within the export.
+func (l Layout) Dir(ch *slack.Channel) string {
+	if l == LayoutByType {
+		return path.Join(ConvTypeDir(ch), ExportChanName(ch))
+	}
+	return ExportChanName(ch)
+}
+
+// ConvTypeDir returns the conversation type directory of the [LayoutByType]
+// for the channel.
+func ConvTypeDir(ch *slack.Channel) string {
+	switch {
+	case ch.IsIM:
+		return DirDMs
+	case ch.IsMpIM:
+		return DirMPIMs
+	case ch.IsGroup || ch.IsPrivate:
+		return DirPrivate
+	default:
+		return DirChannels
+	}
+}
+
+// subdirFS is the filesystem adapter that writes files within the
+// subdirectory of the underlying adapter.
+type subdirFS struct {
+	fs  fsadapter.FS
+	dir string
+}
+
+func (s subdirFS) Create(name string) (io.WriteCloser, error) {
+	return s.fs.Create(path.Join(s.dir, name))
+}
+
+func (s subdirFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return s.fs.WriteFile(path.Join(s.dir, name), data, perm)
+}
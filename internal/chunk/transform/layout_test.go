@@ -0,0 +1,54 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/rusq/slack"
+)
+
+func TestLayout_Dir(t *testing.T) {
+	mkch := func(id, name string, fn func(*slack.Channel)) *slack.Channel {
+		ch := &slack.Channel{GroupConversation: slack.GroupConversation{Name: name, Conversation: slack.Conversation{ID: id}}}
+		if fn != nil {
+			fn(ch)
+		}
+		return ch
+	}
+	tests := []struct {
+		name   string
+		layout Layout
+		ch     *slack.Channel
+		want   string
+	}{
+		{"flat public", LayoutFlat, mkch("C1", "general", nil), "general"},
+		{"flat dm", LayoutFlat, mkch("D1", "", func(c *slack.Channel) { c.IsIM = true }), "D1"},
+		{"public", LayoutByType, mkch("C1", "general", nil), "channels/general"},
+		{"private", LayoutByType, mkch("C2", "secret", func(c *slack.Channel) { c.IsPrivate = true }), "private/secret"},
+		{"group", LayoutByType, mkch("G1", "old-private", func(c *slack.Channel) { c.IsGroup = true }), "private/old-private"},
+		{"mpim", LayoutByType, mkch("C3", "mpdm-a--b-1", func(c *slack.Channel) { c.IsMpIM = true; c.IsPrivate = true }), "mpims/mpdm-a--b-1"},
+		{"dm", LayoutByType, mkch("D1", "", func(c *slack.Channel) { c.IsIM = true }), "dms/D1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.layout.Dir(tt.ch); got != tt.want {
+				t.Errorf("Dir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayout_Set(t *testing.T) {
+	var l Layout
+	if got := l.String(); got != string(LayoutFlat) {
+		t.Errorf("zero value String() = %q, want %q", got, LayoutFlat)
+	}
+	if err := l.Set("BY-TYPE"); err != nil {
+		t.Fatal(err)
+	}
+	if l != LayoutByType {
+		t.Errorf("got %q, want %q", l, LayoutByType)
+	}
+	if err := l.Set("nested"); err == nil {
+		t.Error("expected error")
+	}
+}
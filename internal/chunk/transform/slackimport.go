@@ -0,0 +1,71 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rusq/slack"
+)
+
+// ExpWithSlackImport enables the Slack import format of the export, that
+// can be imported into another Slack workspace: the messages that Slack
+// import does not accept are skipped, and the missing subtypes are set.  It
+// requires the [LayoutFlat].
+func ExpWithSlackImport(enabled bool) ExpCvtOption {
+	return func(t *ExpConverter) {
+		t.slackImport = enabled
+	}
+}
+
+// IntegrationLogsFile is the name of the integration logs file, that Slack
+// import expects in the root of the export.
+const IntegrationLogsFile = "integration_logs.json"
+
+// Message subtypes, that are the change events of other messages.  They are
+// hidden in the conversation history, and Slack import rejects them.
+const (
+	stMessageChanged = "message_changed"
+	stMessageDeleted = "message_deleted"
+	stMessageReplied = "message_replied"
+	stBotMessage     = "bot_message"
+)
+
+// importable returns true if the message m can be imported by Slack import.
+// The hidden messages, i.e. the edit and delete events, and the messages
+// without a poster are not importable.
+func importable(m *slack.Message) bool {
+	if m.Hidden || m.Timestamp == "" {
+		return false
+	}
+	switch m.SubType {
+	case stMessageChanged, stMessageDeleted, stMessageReplied:
+		return false
+	}
+	return m.User != "" || m.BotID != "" || m.Username != ""
+}
+
+// toImportMessage sets the fields of m, that Slack import requires: the
+// message type, and the bot_message subtype on the messages posted by bots
+// and integrations.
+func toImportMessage(m *slack.Message) {
+	if m.Type == "" {
+		m.Type = slack.TYPE_MESSAGE
+	}
+	if m.User == "" && m.SubType == "" {
+		m.SubType = stBotMessage
+	}
+}
+
+// writeIntegrationLogs writes the empty integration logs file, as the
+// integration logs are not available through the API.
+func (t *ExpConverter) writeIntegrationLogs() error {
+	wc, err := t.fsa.Create(IntegrationLogsFile)
+	if err != nil {
+		return fmt.Errorf("error creating file in adapter: %w", err)
+	}
+	defer wc.Close()
+	if err := json.NewEncoder(wc).Encode([]any{}); err != nil {
+		return fmt.Errorf("error encoding the integration logs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+)
+
+const importMessages = `[
+{"type":"message","user":"U1","text":"hello","ts":"1700000000.000100"},
+{"type":"message","subtype":"message_changed","hidden":true,"ts":"1700000000.000200",
+ "message":{"type":"message","user":"U1","text":"hello!","ts":"1700000000.000100","edited":{"user":"U1","ts":"1700000000.000200"}}},
+{"type":"message","subtype":"message_deleted","hidden":true,"ts":"1700000000.000300","deleted_ts":"1700000000.000050"},
+{"type":"message","bot_id":"B1","username":"robot","text":"beep","ts":"1700000000.000400"},
+{"type":"message","subtype":"channel_join","user":"U2","text":"<@U2> has joined the channel","ts":"1700000000.000500"},
+{"ts":"1700000000.000600","user":"U2","text":"no type"}
+]`
+
+func TestExpConverter_slackImport(t *testing.T) {
+	ctx := context.Background()
+	var mm []slack.Message
+	require.NoError(t, json.Unmarshal([]byte(importMessages), &mm))
+
+	cd, err := chunk.CreateDir(t.TempDir())
+	require.NoError(t, err)
+	defer cd.Close()
+	wc, err := cd.Create(chunk.ToFileID("C1", "", false))
+	require.NoError(t, err)
+	rec := chunk.NewRecorder(wc)
+	ch := &slack.Channel{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1"}}}
+	require.NoError(t, rec.ChannelInfo(ctx, ch, ""))
+	require.NoError(t, rec.ChannelUsers(ctx, "C1", "", []string{"U1", "U2"}))
+	require.NoError(t, rec.Messages(ctx, "C1", 0, true, mm))
+	require.NoError(t, rec.Close())
+	require.NoError(t, wc.Close())
+
+	wc, err = cd.Create(chunk.FWorkspace)
+	require.NoError(t, err)
+	rec = chunk.NewRecorder(wc)
+	require.NoError(t, rec.WorkspaceInfo(ctx, &slack.AuthTestResponse{UserID: "U1", TeamID: "T1"}))
+	require.NoError(t, rec.Close())
+	require.NoError(t, wc.Close())
+
+	users := []slack.User{{ID: "U1", Name: "alice"}, {ID: "U2", Name: "bob"}}
+	outdir := t.TempDir()
+	cvt := NewExpConverter(cd, fsadapter.NewDirectory(outdir), ExpWithUsers(users), ExpWithSlackImport(true))
+	require.NoError(t, cvt.Convert(ctx, chunk.ToFileID("C1", "", false)))
+	require.NoError(t, cvt.WriteIndex())
+
+	t.Run("messages", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join(outdir, "general", "2023-11-14.json"))
+		require.NoError(t, err)
+		var got []map[string]any
+		require.NoError(t, json.Unmarshal(data, &got))
+		var tss []string
+		for _, m := range got {
+			tss = append(tss, m["ts"].(string))
+			assert.Equal(t, "message", m["type"])
+			assert.NotContains(t, m, "hidden")
+			if _, ok := m["user"]; !ok {
+				assert.Equal(t, "bot_message", m["subtype"])
+			}
+		}
+		assert.Equal(t, []string{"1700000000.000100", "1700000000.000400", "1700000000.000500", "1700000000.000600"}, tss)
+	})
+	t.Run("index files", func(t *testing.T) {
+		for _, name := range []string{"channels.json", "groups.json", "mpims.json", "dms.json", "users.json", IntegrationLogsFile} {
+			assert.FileExists(t, filepath.Join(outdir, name))
+		}
+		data, err := os.ReadFile(filepath.Join(outdir, IntegrationLogsFile))
+		require.NoError(t, err)
+		assert.JSONEq(t, "[]", string(data))
+	})
+}
+
+func Test_importable(t *testing.T) {
+	tests := []struct {
+		name string
+		m    slack.Msg
+		want bool
+	}{
+		{"user message", slack.Msg{User: "U1", Timestamp: "1.1"}, true},
+		{"bot message", slack.Msg{BotID: "B1", SubType: "bot_message", Timestamp: "1.1"}, true},
+		{"integration", slack.Msg{Username: "hook", Timestamp: "1.1"}, true},
+		{"edit", slack.Msg{SubType: "message_changed", Timestamp: "1.1"}, false},
+		{"hidden", slack.Msg{User: "U1", Hidden: true, Timestamp: "1.1"}, false},
+		{"reply event", slack.Msg{SubType: "message_replied", User: "U1", Timestamp: "1.1"}, false},
+		{"no poster", slack.Msg{Timestamp: "1.1"}, false},
+		{"no timestamp", slack.Msg{User: "U1"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, importable(&slack.Message{Msg: tt.m}))
+		})
+	}
+}
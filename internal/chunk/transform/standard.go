@@ -3,6 +3,7 @@ package transform
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sort"
@@ -109,10 +110,20 @@ func (s *StdConverter) Convert(ctx context.Context, id chunk.FileID) error {
 		return err
 	}
 	conv := &types.Conversation{
-		ID:       ci.ID,
-		Name:     ci.Name,
-		ThreadTS: threadID,
-		Messages: msgs,
+		ID:          ci.ID,
+		Name:        ci.Name,
+		ThreadTS:    threadID,
+		IsArchived:  ci.IsArchived,
+		IsExtShared: structures.IsExtShared(ci),
+		Messages:    msgs,
+	}
+	if threadID == "" {
+		if conv.Pins, err = cf.ChannelPins(channelID); err != nil && !errors.Is(err, chunk.ErrNotFound) {
+			return err
+		}
+		if conv.Bookmarks, err = cf.ChannelBookmarks(channelID); err != nil && !errors.Is(err, chunk.ErrNotFound) {
+			return err
+		}
 	}
 
 	f, err := s.fsa.Create(s.tmpl.Execute(conv))
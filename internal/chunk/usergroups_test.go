@@ -0,0 +1,46 @@
+package chunk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectory_UserGroups(t *testing.T) {
+	cd, err := CreateDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cd.Close()
+	if _, err := cd.UserGroups(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("UserGroups() error = %v, want %v", err, ErrNotFound)
+	}
+
+	wc, err := cd.Create(FUserGroups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := NewRecorder(wc)
+	want := []slack.UserGroup{
+		{ID: "S1", Handle: "devs", Users: []string{"U1", "U2"}},
+		{ID: "S2", Handle: "ops", Users: []string{"U3"}},
+	}
+	if err := rec.UserGroups(context.Background(), want); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := cd.UserGroups()
+	if err != nil {
+		t.Fatalf("UserGroups() error = %v", err)
+	}
+	assert.Equal(t, want, got)
+}
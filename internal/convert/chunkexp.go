@@ -5,17 +5,22 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime/trace"
 	"sync"
+	"time"
 
 	"github.com/rusq/fsadapter"
 	"github.com/rusq/slack"
+	"github.com/rusq/slackdump/v3/downloader"
 	"github.com/rusq/slackdump/v3/internal/chunk"
 	"github.com/rusq/slackdump/v3/internal/chunk/transform"
 	"github.com/rusq/slackdump/v3/internal/chunk/transform/fileproc"
+	"github.com/rusq/slackdump/v3/internal/hydrate"
+	"github.com/rusq/slackdump/v3/internal/mtimefs"
 )
 
 const (
@@ -41,6 +46,9 @@ type ChunkToExport struct {
 	trgFileLoc func(*slack.Channel, *slack.File) string
 
 	lg *slog.Logger
+	// hy resolves the users that are referenced in messages, but are not
+	// in the archive.
+	hy *hydrate.Service
 
 	workers int // number of workers to use to convert channels
 
@@ -84,6 +92,15 @@ func WithLogger(lg *slog.Logger) C2EOption {
 	}
 }
 
+// WithHydrator sets the hydration service, that is used to resolve the users
+// referenced in the messages, that are missing in the archive, i.e. the ones
+// that left the workspace.  Resolved users are added to the export.
+func WithHydrator(h *hydrate.Service) C2EOption {
+	return func(c *ChunkToExport) {
+		c.hy = h
+	}
+}
+
 func NewChunkToExport(src *chunk.Directory, trg fsadapter.FS, opt ...C2EOption) *ChunkToExport {
 	c := &ChunkToExport{
 		src:          src,
@@ -151,6 +168,18 @@ func (c *ChunkToExport) Convert(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if c.hy != nil {
+		if c.hy.Online() {
+			refs, err := hydrate.ScanChunks(ctx, c.src, channels)
+			if err != nil {
+				return fmt.Errorf("hydrate: %w", err)
+			}
+			if err := c.hy.Hydrate(ctx, refs, users, channels); err != nil {
+				return fmt.Errorf("hydrate: %w", err)
+			}
+		}
+		users = c.hy.Users(users)
+	}
 	var tfopts = []transform.ExpCvtOption{
 		transform.ExpWithUsers(users),
 	}
@@ -165,8 +194,10 @@ func (c *ChunkToExport) Convert(ctx context.Context) error {
 			}
 			return nil
 		}))
-		go c.copyworker(c.result, c.request)
 	}
+	// copyworker closes the result channel, once all workers are done, even
+	// if there are no files to copy.
+	go c.copyworker(c.result, c.request)
 
 	// 1. generator
 	var chC = make(chan slack.Channel)
@@ -177,7 +208,7 @@ func (c *ChunkToExport) Convert(ctx context.Context) error {
 		}
 	}()
 
-	errC := make(chan error, c.workers)
+	errC := make(chan error, c.workers+1) // workers and the index writer
 	{
 		// 2. workers
 		// 2.1 converter
@@ -232,6 +263,13 @@ LOOP:
 			}
 		}
 	}
+	// results are closed after all workers are done, report the error, if
+	// any of them failed.
+	select {
+	case err := <-errC:
+		return err
+	default:
+	}
 
 	return nil
 }
@@ -281,7 +319,7 @@ func (c *ChunkToExport) fileCopy(ch *slack.Channel, msg *slack.Message) error {
 			return &copyerror{f.ID, err}
 		}
 		c.lg.Debug("copying", "srcpath", srcpath, "trgpath", trgpath)
-		if err := copy2trg(c.trg, trgpath, srcpath); err != nil {
+		if err := copy2trg(c.trg, trgpath, srcpath, downloader.ModTime(&f)); err != nil {
 			return &copyerror{f.ID, err}
 		}
 	}
@@ -290,21 +328,44 @@ func (c *ChunkToExport) fileCopy(ch *slack.Channel, msg *slack.Message) error {
 
 // copy2trg copies the file from the source path to the target path.  Source
 // path is absolute, target path is relative to the target FS adapter root.
-func copy2trg(trgfs fsadapter.FS, trgpath, srcpath string) error {
+// The target file gets modTime as the modification time, if the target FS
+// adapter supports it.
+func copy2trg(trgfs fsadapter.FS, trgpath, srcpath string, modTime time.Time) error {
 	in, err := os.Open(srcpath)
 	if err != nil {
 		return err
 	}
 	defer in.Close()
 
-	out, err := trgfs.Create(trgpath)
+	out, err := mtimefs.Create(trgfs, trgpath, modTime)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// copyFS2trg copies the file srcpath within srcfs to the target path, see
+// [copy2trg].
+func copyFS2trg(trgfs fsadapter.FS, trgpath string, srcfs fs.FS, srcpath string, modTime time.Time) error {
+	in, err := srcfs.Open(srcpath)
 	if err != nil {
 		return err
 	}
-	defer out.Close()
+	defer in.Close()
 
-	_, err = io.Copy(out, in)
-	return err
+	out, err := mtimefs.Create(trgfs, trgpath, modTime)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
 }
 
 type copyrequest struct {
@@ -6,11 +6,13 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/rusq/fsadapter"
 	"github.com/rusq/slack"
 	"github.com/rusq/slackdump/v3/internal/chunk"
 	"github.com/rusq/slackdump/v3/internal/fixtures"
+	"github.com/rusq/slackdump/v3/internal/mtimefs"
 )
 
 const (
@@ -132,7 +134,7 @@ func Test_copy2trg(t *testing.T) {
 		}
 		trgfs := fsadapter.NewDirectory(trgdir)
 
-		if err := copy2trg(trgfs, "test-copy.txt", filepath.Join(srcdir, "test.txt")); err != nil {
+		if err := copy2trg(trgfs, "test-copy.txt", filepath.Join(srcdir, "test.txt"), time.Time{}); err != nil {
 			t.Fatal(err)
 		}
 		// validate
@@ -144,13 +146,32 @@ func Test_copy2trg(t *testing.T) {
 			t.Fatal("unexpected data")
 		}
 	})
+	t.Run("sets the modification time", func(t *testing.T) {
+		srcdir := t.TempDir()
+		trgdir := t.TempDir()
+
+		if err := os.WriteFile(filepath.Join(srcdir, "test.txt"), []byte("test"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		modTime := time.Date(2021, 3, 14, 15, 9, 26, 0, time.UTC)
+		if err := copy2trg(mtimefs.NewDirectory(trgdir), "test-copy.txt", filepath.Join(srcdir, "test.txt"), modTime); err != nil {
+			t.Fatal(err)
+		}
+		fi, err := os.Stat(filepath.Join(trgdir, "test-copy.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !fi.ModTime().Equal(modTime) {
+			t.Errorf("mod time = %s, want %s", fi.ModTime(), modTime)
+		}
+	})
 	t.Run("copy fails", func(t *testing.T) {
 		srcdir := t.TempDir()
 		trgdir := t.TempDir()
 
 		trgfs := fsadapter.NewDirectory(trgdir)
 		// source file does not exist.
-		if err := copy2trg(trgfs, "test-copy.txt", filepath.Join(srcdir, "test.txt")); err == nil {
+		if err := copy2trg(trgfs, "test-copy.txt", filepath.Join(srcdir, "test.txt"), time.Time{}); err == nil {
 			t.Fatal("expected error, but got nil")
 		}
 	})
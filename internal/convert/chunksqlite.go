@@ -0,0 +1,124 @@
+package convert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"runtime/trace"
+	"time"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/sqlite"
+)
+
+// sqliteBatchSize is the number of messages inserted in one transaction.
+const sqliteBatchSize = 1000
+
+// ChunkToSQLite converts the chunk directory contents into the SQLite
+// database.
+type ChunkToSQLite struct {
+	src *chunk.Directory
+	trg *sqlite.DB
+	lg  *slog.Logger
+}
+
+// NewChunkToSQLite creates a new converter from the chunk directory src to
+// the database trg.
+func NewChunkToSQLite(src *chunk.Directory, trg *sqlite.DB, lg *slog.Logger) *ChunkToSQLite {
+	if lg == nil {
+		lg = slog.Default()
+	}
+	return &ChunkToSQLite{src: src, trg: trg, lg: lg}
+}
+
+// Convert writes users, channels and all messages from the chunk directory
+// into the database.
+func (c *ChunkToSQLite) Convert(ctx context.Context) error {
+	ctx, task := trace.NewTask(ctx, "convert.ChunkToSQLite")
+	defer task.End()
+
+	users, err := c.src.Users()
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		c.lg.WarnContext(ctx, "no users in the chunk directory")
+	}
+	if err := c.trg.InsertUsers(ctx, users); err != nil {
+		return fmt.Errorf("error inserting users: %w", err)
+	}
+	channels, err := c.src.Channels()
+	if err != nil {
+		return err
+	}
+	if err := c.trg.InsertChannels(ctx, channels); err != nil {
+		return fmt.Errorf("error inserting channels: %w", err)
+	}
+
+	ids, err := c.conversationIDs()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := c.convertFile(ctx, id); err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+	}
+	c.lg.InfoContext(ctx, "converted", "users", len(users), "channels", len(channels), "conversation_files", len(ids))
+	return nil
+}
+
+// conversationIDs returns the IDs of the conversation files in the chunk
+// directory.
+func (c *ChunkToSQLite) conversationIDs() ([]chunk.FileID, error) {
+	des, err := os.ReadDir(c.src.Name())
+	if err != nil {
+		return nil, err
+	}
+	var ids []chunk.FileID
+	for _, de := range des {
+		id, ok := chunk.FileIDOf(de.Name())
+		if de.IsDir() || !ok {
+			continue
+		}
+		switch id {
+		case chunk.FChannels, chunk.FUsers, chunk.FWorkspace, chunk.FSearch:
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (c *ChunkToSQLite) convertFile(ctx context.Context, id chunk.FileID) error {
+	f, err := c.src.Open(id)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	channelID, _ := id.Split()
+	batch := make([]slack.Message, 0, sqliteBatchSize)
+	if err := f.Sorted(ctx, false, func(_ time.Time, m *slack.Message) error {
+		batch = append(batch, *m)
+		if len(batch) < sqliteBatchSize {
+			return nil
+		}
+		if err := c.trg.InsertMessages(ctx, channelID, batch); err != nil {
+			return err
+		}
+		batch = batch[:0]
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return c.trg.InsertMessages(ctx, channelID, batch)
+	}
+	return nil
+}
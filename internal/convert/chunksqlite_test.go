@@ -0,0 +1,77 @@
+package convert
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/sqlite"
+)
+
+// record records the chunk file with id in the directory cd.
+func record(t *testing.T, cd *chunk.Directory, id chunk.FileID, fn func(rec *chunk.Recorder) error) {
+	t.Helper()
+	wc, err := cd.Create(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := chunk.NewRecorder(wc)
+	if err := fn(rec); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wc.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChunkToSQLite_Convert(t *testing.T) {
+	ctx := context.Background()
+	cd, err := chunk.CreateDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cd.Close()
+
+	ch := slack.Channel{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1"}}}
+	parent := slack.Message{Msg: slack.Msg{Timestamp: "1700000001.000000", ThreadTimestamp: "1700000001.000000", User: "U1", LatestReply: "1700000002.000000"}}
+	record(t, cd, chunk.FUsers, func(rec *chunk.Recorder) error {
+		return rec.Users(ctx, []slack.User{{ID: "U1"}})
+	})
+	record(t, cd, chunk.ToFileID("C1", "", false), func(rec *chunk.Recorder) error {
+		if err := rec.ChannelInfo(ctx, &ch, ""); err != nil {
+			return err
+		}
+		if err := rec.Messages(ctx, "C1", 1, true, []slack.Message{parent, {Msg: slack.Msg{Timestamp: "1700000003.000000", User: "U1"}}}); err != nil {
+			return err
+		}
+		return rec.ThreadMessages(ctx, "C1", parent, false, true, []slack.Message{{Msg: slack.Msg{Timestamp: "1700000002.000000", ThreadTimestamp: "1700000001.000000", User: "U1"}}})
+	})
+
+	db, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := NewChunkToSQLite(cd, db, testLogger).Convert(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for query, want := range map[string]int{
+		"SELECT COUNT(*) FROM users":                                          1,
+		"SELECT COUNT(*) FROM channels":                                       1,
+		"SELECT COUNT(*) FROM messages WHERE channel_id = 'C1'":               3,
+		"SELECT COUNT(*) FROM messages WHERE thread_ts = '1700000001.000000'": 2,
+	} {
+		var got int
+		if err := db.DB().QueryRowContext(ctx, query).Scan(&got); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, want, got, query)
+	}
+}
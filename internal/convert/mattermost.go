@@ -0,0 +1,557 @@
+package convert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"runtime/trace"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/downloader"
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/chunk/transform/fileproc"
+	"github.com/rusq/slackdump/v3/internal/fasttime"
+	"github.com/rusq/slackdump/v3/internal/source"
+	"github.com/rusq/slackdump/v3/internal/structures"
+	"github.com/rusq/slackdump/v3/internal/structures/files"
+)
+
+const (
+	// MattermostImportFile is the name of the Mattermost bulk import file in
+	// the target.
+	MattermostImportFile = "mattermost_import.jsonl"
+	// mmAttachDir is the directory of the attachments, relative to the
+	// "data" directory of the import archive.
+	mmAttachDir = "bulk-export-attachments"
+	// mmDefaultTeam is the team name used if the workspace information is
+	// not available.
+	mmDefaultTeam = "slackdump"
+)
+
+// ChunkToMattermost converts the archive contents into the Mattermost bulk
+// import format:  the JSONL file with the team, channels,
+// users and posts, and, optionally, the attachments in the
+// "data/bulk-export-attachments" directory.  If the target is a ZIP file, it
+// can be imported with "mmctl import upload" without modifications.  Zero
+// value is not usable.
+type ChunkToMattermost struct {
+	src          source.Sourcer
+	trg          fsadapter.FS
+	team         string
+	includeFiles bool
+	lg           *slog.Logger
+}
+
+// C2MOption is the option for the [ChunkToMattermost] converter.
+type C2MOption func(*ChunkToMattermost)
+
+// MattermostTeam sets the name of the Mattermost team.  By default, the name
+// of the Slack workspace is used.
+func MattermostTeam(name string) C2MOption {
+	return func(c *ChunkToMattermost) {
+		c.team = name
+	}
+}
+
+// MattermostIncludeFiles enables copying the attachments into the target.
+func MattermostIncludeFiles(b bool) C2MOption {
+	return func(c *ChunkToMattermost) {
+		c.includeFiles = b
+	}
+}
+
+// MattermostLogger sets the logger.
+func MattermostLogger(lg *slog.Logger) C2MOption {
+	return func(c *ChunkToMattermost) {
+		if lg != nil {
+			c.lg = lg
+		}
+	}
+}
+
+// NewChunkToMattermost creates a new converter from the chunk directory src
+// to the Mattermost bulk import in trg.
+func NewChunkToMattermost(src *chunk.Directory, trg fsadapter.FS, opt ...C2MOption) *ChunkToMattermost {
+	return NewSourceToMattermost(source.NewChunkDir(src), trg, opt...)
+}
+
+// NewSourceToMattermost creates a new converter from any source, i.e. the
+// Slack export or the dump, to the Mattermost bulk import in trg.
+func NewSourceToMattermost(src source.Sourcer, trg fsadapter.FS, opt ...C2MOption) *ChunkToMattermost {
+	c := &ChunkToMattermost{
+		src: src,
+		trg: trg,
+		lg:  slog.Default(),
+	}
+	for _, o := range opt {
+		o(c)
+	}
+	return c
+}
+
+// Convert writes the bulk import file.  Messages of the users that are not
+// in the users list (i.e. bot messages) are skipped, as Mattermost requires
+// the post author to exist.
+func (c *ChunkToMattermost) Convert(ctx context.Context) error {
+	ctx, task := trace.NewTask(ctx, "convert.ChunkToMattermost")
+	defer task.End()
+
+	users, err := c.src.Users()
+	if err != nil {
+		return err
+	}
+	channels, err := c.src.Channels()
+	if err != nil {
+		return err
+	}
+	var me string
+	if wi, ok := c.src.(workspaceInfoer); ok {
+		if wsp, err := wi.WorkspaceInfo(); err == nil {
+			me = wsp.UserID
+			if c.team == "" {
+				c.team = wsp.Team
+			}
+		}
+	}
+	team := mmName(c.team)
+	if team == "" {
+		team = mmDefaultTeam
+	}
+
+	wc, err := c.trg.Create(MattermostImportFile)
+	if err != nil {
+		return err
+	}
+	defer wc.Close()
+	mw := &mmWriter{
+		enc:      json.NewEncoder(wc),
+		team:     team,
+		me:       me,
+		users:    make(map[string]string, len(users)),
+		channels: make(map[string]string, len(channels)),
+	}
+	for _, u := range users {
+		mw.users[u.ID] = mmName(u.Name)
+		if mw.users[u.ID] == "" {
+			mw.users[u.ID] = mmName(u.ID)
+		}
+	}
+	// direct messages are written after the posts, as required by the
+	// import format.
+	sort.SliceStable(channels, func(i, j int) bool {
+		return !isDirect(&channels[i]) && isDirect(&channels[j])
+	})
+	for _, ch := range channels {
+		if !isDirect(&ch) {
+			mw.channels[ch.ID] = mmName(ch.Name)
+		}
+	}
+
+	if err := mw.header(c.team, channels); err != nil {
+		return err
+	}
+	if err := mw.userLines(users, channels); err != nil {
+		return err
+	}
+	for _, ch := range channels {
+		if err := c.convertChannel(ctx, mw, &ch); err != nil {
+			return fmt.Errorf("%s: %w", ch.ID, err)
+		}
+	}
+	if mw.skipped > 0 {
+		c.lg.WarnContext(ctx, "messages without a known author were skipped", "count", mw.skipped)
+	}
+	c.lg.InfoContext(ctx, "converted", "users", len(users), "channels", len(channels), "posts", mw.posts)
+	return wc.Close()
+}
+
+// workspaceInfoer is implemented by the sources, that have the workspace
+// information.
+type workspaceInfoer interface {
+	WorkspaceInfo() (*slack.AuthTestResponse, error)
+}
+
+// notFound returns true if err means that the conversation or thread is not
+// in the source.
+func notFound(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, chunk.ErrNotFound)
+}
+
+func isDirect(ch *slack.Channel) bool {
+	return ch.IsIM || ch.IsMpIM
+}
+
+func (c *ChunkToMattermost) convertChannel(ctx context.Context, mw *mmWriter, ch *slack.Channel) error {
+	var members []string
+	if isDirect(ch) {
+		members = mw.dmMembers(ch)
+		if len(members) < 2 {
+			c.lg.WarnContext(ctx, "skipping direct conversation with unknown members", "channel_id", ch.ID)
+			return nil
+		}
+		if err := mw.line(mmLine{Type: "direct_channel", DirectChannel: &mmDirectChannel{Members: members}}); err != nil {
+			return err
+		}
+	}
+
+	msgs, err := c.src.AllMessages(ch.ID)
+	if err != nil {
+		if notFound(err) {
+			return nil
+		}
+		return err
+	}
+	msgs = uniqSorted(msgs)
+	for i := range msgs {
+		m := &msgs[i]
+		post, ok := mw.post(ch, m)
+		if !ok {
+			continue
+		}
+		if err := c.attachments(ch, m, &post.Attachments); err != nil {
+			return err
+		}
+		if structures.IsThreadStart(m) && m.LatestReply != structures.LatestReplyNoReplies {
+			replies, err := c.src.AllThreadMessages(ch.ID, m.ThreadTimestamp)
+			if err != nil && !notFound(err) {
+				return err
+			}
+			for _, r := range uniqSorted(replies) {
+				if r.Timestamp == m.Timestamp {
+					continue
+				}
+				reply, ok := mw.post(ch, &r)
+				if !ok {
+					continue
+				}
+				if err := c.attachments(ch, &r, &reply.Attachments); err != nil {
+					return err
+				}
+				post.Replies = append(post.Replies, mmReply{
+					User:        reply.User,
+					Message:     reply.Message,
+					CreateAt:    reply.CreateAt,
+					Attachments: reply.Attachments,
+				})
+			}
+		}
+		l := mmLine{Type: "post", Post: post}
+		if isDirect(ch) {
+			l = mmLine{Type: "direct_post", DirectPost: &mmDirectPost{ChannelMembers: members, mmPost: *post}}
+			l.DirectPost.Team, l.DirectPost.Channel = "", ""
+		}
+		if err := mw.line(l); err != nil {
+			return err
+		}
+		mw.posts++
+	}
+	return nil
+}
+
+// uniqSorted returns messages sorted by timestamp, with duplicates removed.
+func uniqSorted(mm []slack.Message) []slack.Message {
+	sort.SliceStable(mm, func(i, j int) bool {
+		return slackTSLess(mm[i].Timestamp, mm[j].Timestamp)
+	})
+	out := mm[:0]
+	for i := range mm {
+		if i > 0 && mm[i].Timestamp == mm[i-1].Timestamp {
+			out[len(out)-1] = mm[i] // the latest version wins
+			continue
+		}
+		out = append(out, mm[i])
+	}
+	return out
+}
+
+func slackTSLess(a, b string) bool {
+	ai, errA := fasttime.TS2int(a)
+	bi, errB := fasttime.TS2int(b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return ai < bi
+}
+
+// attachments copies the message files to the target, if enabled, and adds
+// the references to them to aa.
+func (c *ChunkToMattermost) attachments(ch *slack.Channel, m *slack.Message, aa *[]mmAttachment) error {
+	if !c.includeFiles {
+		return nil
+	}
+	for _, f := range m.Files {
+		if err := fileproc.IsValidWithReason(&f); err != nil {
+			c.lg.Warn("skipping", "file", f.ID, "error", err)
+			continue
+		}
+		srcpath, err := c.src.File(f.ID, files.Name(&f))
+		if err != nil {
+			c.lg.Warn("skipping missing file", "file", f.ID, "error", err)
+			continue
+		}
+		ref := path.Join(mmAttachDir, f.ID+"_"+files.Name(&f))
+		if err := copyFS2trg(c.trg, path.Join("data", ref), c.src.FS(), srcpath, downloader.ModTime(&f)); err != nil {
+			return &copyerror{f.ID, err}
+		}
+		*aa = append(*aa, mmAttachment{Path: ref})
+	}
+	return nil
+}
+
+// mmWriter writes the lines of the bulk import file.
+type mmWriter struct {
+	enc      *json.Encoder
+	team     string
+	me       string            // current user ID, member of all IMs
+	users    map[string]string // user ID -> username
+	channels map[string]string // channel ID -> channel name
+	posts    int
+	skipped  int
+}
+
+func (mw *mmWriter) line(l mmLine) error {
+	return mw.enc.Encode(l)
+}
+
+// header writes the version, team and channels lines.
+func (mw *mmWriter) header(displayName string, channels []slack.Channel) error {
+	if err := mw.line(mmLine{Type: "version", Version: 1}); err != nil {
+		return err
+	}
+	if err := mw.line(mmLine{Type: "team", Team: &mmTeam{
+		Name:        mw.team,
+		DisplayName: structures.NVL(displayName, mw.team),
+		Type:        "O",
+	}}); err != nil {
+		return err
+	}
+	for _, ch := range channels {
+		if isDirect(&ch) {
+			continue
+		}
+		typ := "O"
+		if ch.IsPrivate {
+			typ = "P"
+		}
+		if err := mw.line(mmLine{Type: "channel", Channel: &mmChannel{
+			Team:        mw.team,
+			Name:        mw.channels[ch.ID],
+			DisplayName: ch.Name,
+			Type:        typ,
+			Header:      ch.Topic.Value,
+			Purpose:     ch.Purpose.Value,
+		}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userLines writes the users with their team and channel memberships.
+func (mw *mmWriter) userLines(users []slack.User, channels []slack.Channel) error {
+	membership := make(map[string][]mmChannelMember)
+	for _, ch := range channels {
+		if isDirect(&ch) {
+			continue
+		}
+		for _, id := range ch.Members {
+			membership[id] = append(membership[id], mmChannelMember{Name: mw.channels[ch.ID], Roles: "channel_user"})
+		}
+	}
+	for _, u := range users {
+		email := u.Profile.Email
+		if email == "" {
+			// email is required by Mattermost.
+			email = mw.users[u.ID] + "@localhost"
+		}
+		if err := mw.line(mmLine{Type: "user", User: &mmUser{
+			Username:  mw.users[u.ID],
+			Email:     email,
+			FirstName: u.Profile.FirstName,
+			LastName:  u.Profile.LastName,
+			Nickname:  u.Profile.DisplayName,
+			Position:  u.Profile.Title,
+			Teams: []mmTeamMember{{
+				Name:     mw.team,
+				Roles:    "team_user",
+				Channels: membership[u.ID],
+			}},
+		}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dmMembers returns the usernames of the direct conversation members.
+func (mw *mmWriter) dmMembers(ch *slack.Channel) []string {
+	ids := ch.Members
+	if ch.IsIM && len(ids) == 0 {
+		ids = []string{ch.User, mw.me}
+	}
+	var members []string
+	for _, id := range ids {
+		if name, ok := mw.users[id]; ok && !slices.Contains(members, name) {
+			members = append(members, name)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// post converts the message to the post.  It returns false, if the message
+// should be skipped.
+func (mw *mmWriter) post(ch *slack.Channel, m *slack.Message) (*mmPost, bool) {
+	user, ok := mw.users[m.User]
+	if !ok {
+		mw.skipped++
+		return nil, false
+	}
+	ts, err := fasttime.TS2int(m.Timestamp)
+	if err != nil {
+		mw.skipped++
+		return nil, false
+	}
+	return &mmPost{
+		Team:     mw.team,
+		Channel:  mw.channels[ch.ID],
+		User:     user,
+		Message:  mw.text(m.Text),
+		CreateAt: ts / 1000, // milliseconds
+	}, true
+}
+
+var slackRefRe = regexp.MustCompile(`<([@#!]?)([^>|]*)(?:\|([^>]*))?>`)
+
+// text converts the slack markup in the message text to the Mattermost
+// markdown.
+func (mw *mmWriter) text(s string) string {
+	s = slackRefRe.ReplaceAllStringFunc(s, func(ref string) string {
+		sm := slackRefRe.FindStringSubmatch(ref)
+		kind, target, label := sm[1], sm[2], sm[3]
+		switch kind {
+		case "@":
+			if name, ok := mw.users[target]; ok {
+				return "@" + name
+			}
+			return "@" + structures.NVL(label, target)
+		case "#":
+			if name, ok := mw.channels[target]; ok {
+				return "~" + name
+			}
+			return "~" + structures.NVL(label, target)
+		case "!":
+			switch target {
+			case "here", "channel":
+				return "@" + target
+			case "everyone":
+				return "@all"
+			}
+			return structures.NVL(label, target)
+		}
+		if label != "" {
+			return "[" + label + "](" + target + ")"
+		}
+		return target
+	})
+	return html.UnescapeString(s)
+}
+
+var mmInvalidRe = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// mmName returns the name converted to the form acceptable by Mattermost
+// for the team, channel and user names.
+func mmName(s string) string {
+	return strings.Trim(mmInvalidRe.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// Bulk import format types, see
+// https://docs.mattermost.com/onboard/bulk-loading-data.html
+
+type mmLine struct {
+	Type          string           `json:"type"`
+	Version       int              `json:"version,omitempty"`
+	Team          *mmTeam          `json:"team,omitempty"`
+	Channel       *mmChannel       `json:"channel,omitempty"`
+	User          *mmUser          `json:"user,omitempty"`
+	Post          *mmPost          `json:"post,omitempty"`
+	DirectChannel *mmDirectChannel `json:"direct_channel,omitempty"`
+	DirectPost    *mmDirectPost    `json:"direct_post,omitempty"`
+}
+
+type mmTeam struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Type        string `json:"type"`
+}
+
+type mmChannel struct {
+	Team        string `json:"team"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Type        string `json:"type"`
+	Header      string `json:"header,omitempty"`
+	Purpose     string `json:"purpose,omitempty"`
+}
+
+type mmUser struct {
+	Username  string         `json:"username"`
+	Email     string         `json:"email"`
+	FirstName string         `json:"first_name,omitempty"`
+	LastName  string         `json:"last_name,omitempty"`
+	Nickname  string         `json:"nickname,omitempty"`
+	Position  string         `json:"position,omitempty"`
+	Teams     []mmTeamMember `json:"teams"`
+}
+
+type mmTeamMember struct {
+	Name     string            `json:"name"`
+	Roles    string            `json:"roles"`
+	Channels []mmChannelMember `json:"channels,omitempty"`
+}
+
+type mmChannelMember struct {
+	Name  string `json:"name"`
+	Roles string `json:"roles"`
+}
+
+type mmPost struct {
+	Team        string         `json:"team,omitempty"`
+	Channel     string         `json:"channel,omitempty"`
+	User        string         `json:"user"`
+	Message     string         `json:"message"`
+	CreateAt    int64          `json:"create_at"`
+	Replies     []mmReply      `json:"replies,omitempty"`
+	Attachments []mmAttachment `json:"attachments,omitempty"`
+}
+
+type mmReply struct {
+	User        string         `json:"user"`
+	Message     string         `json:"message"`
+	CreateAt    int64          `json:"create_at"`
+	Attachments []mmAttachment `json:"attachments,omitempty"`
+}
+
+type mmAttachment struct {
+	Path string `json:"path"`
+}
+
+type mmDirectChannel struct {
+	Members []string `json:"members"`
+}
+
+type mmDirectPost struct {
+	ChannelMembers []string `json:"channel_members"`
+	mmPost
+}
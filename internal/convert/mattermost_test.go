@@ -0,0 +1,121 @@
+package convert
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+)
+
+func TestChunkToMattermost_Convert(t *testing.T) {
+	ctx := context.Background()
+	cd, err := chunk.CreateDir(t.TempDir())
+	require.NoError(t, err)
+	defer cd.Close()
+
+	var (
+		general = slack.Channel{GroupConversation: slack.GroupConversation{Name: "General", Conversation: slack.Conversation{ID: "C1"}}}
+		im      = slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "D1", IsIM: true, User: "U2"}}}
+		file    = slack.File{ID: "F1", Name: "report.txt", URLPrivateDownload: "https://files.slack.com/F1/report.txt"}
+		parent  = slack.Message{Msg: slack.Msg{Timestamp: "1700000001.000000", ThreadTimestamp: "1700000001.000000", User: "U1", Text: "hi <@U2>, see <https://example.com|this>", LatestReply: "1700000002.000000", Files: []slack.File{file}}}
+	)
+	record(t, cd, chunk.FUsers, func(rec *chunk.Recorder) error {
+		return rec.Users(ctx, []slack.User{{ID: "U1", Name: "Alice"}, {ID: "U2", Name: "bob", Profile: slack.UserProfile{Email: "bob@example.com"}}})
+	})
+	record(t, cd, chunk.FWorkspace, func(rec *chunk.Recorder) error {
+		return rec.WorkspaceInfo(ctx, &slack.AuthTestResponse{UserID: "U1", Team: "Test Team"})
+	})
+	record(t, cd, chunk.ToFileID("C1", "", false), func(rec *chunk.Recorder) error {
+		if err := rec.ChannelInfo(ctx, &general, ""); err != nil {
+			return err
+		}
+		if err := rec.ChannelUsers(ctx, "C1", "", []string{"U1", "U2"}); err != nil {
+			return err
+		}
+		if err := rec.Messages(ctx, "C1", 1, true, []slack.Message{
+			{Msg: slack.Msg{Timestamp: "1700000003.000000", BotID: "B1", Text: "bot message"}},
+			parent,
+		}); err != nil {
+			return err
+		}
+		return rec.ThreadMessages(ctx, "C1", parent, false, true, []slack.Message{{Msg: slack.Msg{Timestamp: "1700000002.000000", ThreadTimestamp: "1700000001.000000", User: "U2", Text: "&lt;3 <!here>"}}})
+	})
+	record(t, cd, chunk.ToFileID("D1", "", false), func(rec *chunk.Recorder) error {
+		if err := rec.ChannelInfo(ctx, &im, ""); err != nil {
+			return err
+		}
+		if err := rec.ChannelUsers(ctx, "D1", "", []string{"U1", "U2"}); err != nil {
+			return err
+		}
+		return rec.Messages(ctx, "D1", 0, true, []slack.Message{{Msg: slack.Msg{Timestamp: "1700000004.000000", User: "U2", Text: "psst"}}})
+	})
+	// the attachment
+	fpath := filepath.Join(cd.Name(), "__uploads", "F1", "report.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(fpath), 0o755))
+	require.NoError(t, os.WriteFile(fpath, []byte("report"), 0o644))
+
+	dir := t.TempDir()
+	fsa := fsadapter.NewDirectory(dir)
+	cvt := NewChunkToMattermost(cd, fsa, MattermostIncludeFiles(true), MattermostLogger(testLogger))
+	require.NoError(t, cvt.Convert(ctx))
+	require.NoError(t, fsa.Close())
+
+	lines := readLines(t, filepath.Join(dir, MattermostImportFile))
+	var types []string
+	for _, l := range lines {
+		types = append(types, l["type"].(string))
+	}
+	assert.Equal(t, []string{"version", "team", "channel", "user", "user", "post", "direct_channel", "direct_post"}, types)
+
+	assert.Equal(t, map[string]any{"name": "test-team", "display_name": "Test Team", "type": "O"}, lines[1]["team"])
+	assert.Equal(t, "general", lines[2]["channel"].(map[string]any)["name"])
+	alice := lines[3]["user"].(map[string]any)
+	assert.Equal(t, "alice", alice["username"])
+	assert.Equal(t, "alice@localhost", alice["email"])
+	assert.Equal(t, []any{map[string]any{
+		"name":     "test-team",
+		"roles":    "team_user",
+		"channels": []any{map[string]any{"name": "general", "roles": "channel_user"}},
+	}}, alice["teams"])
+
+	post := lines[5]["post"].(map[string]any)
+	assert.Equal(t, "alice", post["user"])
+	assert.Equal(t, "hi @bob, see [this](https://example.com)", post["message"])
+	assert.Equal(t, float64(1700000001000), post["create_at"])
+	assert.Equal(t, []any{map[string]any{"path": "bulk-export-attachments/F1_report.txt"}}, post["attachments"])
+	assert.Equal(t, []any{map[string]any{"user": "bob", "message": "<3 @here", "create_at": float64(1700000002000)}}, post["replies"])
+	data, err := os.ReadFile(filepath.Join(dir, "data", "bulk-export-attachments", "F1_report.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "report", string(data))
+
+	assert.Equal(t, map[string]any{"members": []any{"alice", "bob"}}, lines[6]["direct_channel"])
+	dp := lines[7]["direct_post"].(map[string]any)
+	assert.Equal(t, []any{"alice", "bob"}, dp["channel_members"])
+	assert.Equal(t, "psst", dp["message"])
+	assert.NotContains(t, dp, "channel")
+}
+
+func readLines(t *testing.T, name string) []map[string]any {
+	t.Helper()
+	f, err := os.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+	var lines []map[string]any
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var l map[string]any
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &l))
+		lines = append(lines, l)
+	}
+	require.NoError(t, sc.Err())
+	return lines
+}
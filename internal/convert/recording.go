@@ -0,0 +1,172 @@
+package convert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"runtime/trace"
+	"sort"
+	"time"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+)
+
+// RecordingToExport converts the single file chunk recording, i.e. the output
+// of "tools record stream", to the export format.  The recording is split
+// into the temporary chunk directory, which is then converted with
+// [ChunkToExport].  Files are not included, as the recording does not have
+// them.  Zero value is not usable.
+type RecordingToExport struct {
+	src io.Reader
+	cvt *ChunkToExport
+}
+
+// NewRecordingToExport creates a new converter of the recording read from src
+// into the export in trg.
+func NewRecordingToExport(src io.Reader, trg fsadapter.FS, opt ...C2EOption) *RecordingToExport {
+	cvt := NewChunkToExport(nil, trg, opt...)
+	cvt.includeFiles = false
+	return &RecordingToExport{src: src, cvt: cvt}
+}
+
+// Convert converts the recording.  If the recording has no users or workspace
+// information, they are reconstructed from the recorded conversations.
+func (c *RecordingToExport) Convert(ctx context.Context) error {
+	ctx, task := trace.NewTask(ctx, "convert.RecordingToExport")
+	defer task.End()
+
+	dir, err := os.MkdirTemp("", "slackdump-record-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	cd, err := chunk.OpenDir(dir)
+	if err != nil {
+		return err
+	}
+	defer cd.Close()
+
+	start := time.Now()
+	if err := chunk.SplitRecording(ctx, cd, c.src); err != nil {
+		return fmt.Errorf("error reading the recording: %w", err)
+	}
+	c.cvt.lg.DebugContext(ctx, "recording split", "dir", dir, "took", time.Since(start))
+	if err := c.ensureUsers(ctx, cd); err != nil {
+		return err
+	}
+	if err := c.ensureWorkspace(ctx, cd); err != nil {
+		return err
+	}
+	c.cvt.src = cd
+	return c.cvt.Convert(ctx)
+}
+
+// ensureUsers writes the users file with the placeholder users for all
+// message authors, if the recording has no users.
+func (c *RecordingToExport) ensureUsers(ctx context.Context, cd *chunk.Directory) error {
+	if _, err := cd.Stat(chunk.FUsers); !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	channels, err := cd.Channels()
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, ch := range channels {
+		if err := authors(ctx, cd, ch.ID, seen); err != nil {
+			return fmt.Errorf("%s: %w", ch.ID, err)
+		}
+	}
+	users := make([]slack.User, 0, len(seen))
+	for id := range seen {
+		users = append(users, slack.User{ID: id, Name: id})
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	c.cvt.lg.WarnContext(ctx, "no users in the recording, user IDs will be used as names", "user_count", len(users))
+
+	return writeChunks(cd, chunk.FUsers, func(rec *chunk.Recorder) error {
+		return rec.Users(ctx, users)
+	})
+}
+
+// authors adds the IDs of the authors of the messages in the channel file to
+// seen.
+func authors(ctx context.Context, cd *chunk.Directory, channelID string, seen map[string]bool) error {
+	f, err := cd.Open(chunk.ToFileID(channelID, "", false))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sorted(ctx, false, func(_ time.Time, m *slack.Message) error {
+		if m.User != "" {
+			seen[m.User] = true
+		}
+		return nil
+	})
+}
+
+// ensureWorkspace writes the workspace file, if the recording has no
+// workspace information.  The current user is only needed for the direct
+// messages, it is taken from the members of the direct message
+// conversations, or, if there are none, the first user is used.
+func (c *RecordingToExport) ensureWorkspace(ctx context.Context, cd *chunk.Directory) error {
+	if _, err := cd.WorkspaceInfo(); err == nil {
+		return nil
+	}
+	channels, err := cd.Channels()
+	if err != nil {
+		return err
+	}
+	users, err := cd.Users()
+	if err != nil {
+		return err
+	}
+	var me string
+	for _, ch := range channels {
+		if !ch.IsIM {
+			continue
+		}
+		for _, m := range ch.Members {
+			if m != ch.User {
+				me = m
+				break
+			}
+		}
+		if me != "" {
+			break
+		}
+	}
+	if me == "" && len(users) > 0 {
+		me = users[0].ID
+	}
+	c.cvt.lg.WarnContext(ctx, "no workspace information in the recording", "assumed_user_id", me)
+
+	return writeChunks(cd, chunk.FWorkspace, func(rec *chunk.Recorder) error {
+		return rec.WorkspaceInfo(ctx, &slack.AuthTestResponse{UserID: me})
+	})
+}
+
+// writeChunks creates the chunk file id in the directory and calls fn with the
+// recorder for it.
+func writeChunks(cd *chunk.Directory, id chunk.FileID, fn func(rec *chunk.Recorder) error) error {
+	wc, err := cd.Create(id)
+	if err != nil {
+		return err
+	}
+	rec := chunk.NewRecorder(wc)
+	if err := fn(rec); err != nil {
+		wc.Close()
+		return err
+	}
+	if err := rec.Close(); err != nil {
+		wc.Close()
+		return err
+	}
+	return wc.Close()
+}
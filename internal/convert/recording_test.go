@@ -0,0 +1,64 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+)
+
+func TestRecordingToExport_Convert(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	rec := chunk.NewRecorder(&buf)
+	ch := slack.Channel{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1"}}}
+	parent := slack.Message{Msg: slack.Msg{Timestamp: "1700000001.000000", ThreadTimestamp: "1700000001.000000", User: "U1", LatestReply: "1700000002.000000", ReplyCount: 1}}
+	require.NoError(t, rec.ChannelInfo(ctx, &ch, ""))
+	require.NoError(t, rec.ChannelUsers(ctx, "C1", "", []string{"U1", "U2", "U3"}))
+	require.NoError(t, rec.Messages(ctx, "C1", 1, true, []slack.Message{{Msg: slack.Msg{Timestamp: "1700000003.000000", User: "U2"}}, parent}))
+	require.NoError(t, rec.ThreadMessages(ctx, "C1", parent, false, true, []slack.Message{{Msg: slack.Msg{Timestamp: "1700000002.000000", ThreadTimestamp: "1700000001.000000", User: "U3"}}}))
+	require.NoError(t, rec.Close())
+
+	dir := t.TempDir()
+	fsa := fsadapter.NewDirectory(dir)
+	cvt := NewRecordingToExport(&buf, fsa, WithLogger(testLogger), WithIncludeFiles(true))
+	require.NoError(t, cvt.Convert(ctx))
+	require.NoError(t, fsa.Close())
+
+	var users []slack.User
+	readJSON(t, filepath.Join(dir, "users.json"), &users)
+	var ids []string
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	assert.Equal(t, []string{"U1", "U2", "U3"}, ids, "placeholder users are created for all authors")
+
+	var channels []slack.Channel
+	readJSON(t, filepath.Join(dir, "channels.json"), &channels)
+	require.Len(t, channels, 1)
+	assert.Equal(t, "general", channels[0].Name)
+
+	var msgs []map[string]any
+	readJSON(t, filepath.Join(dir, "general", "2023-11-14.json"), &msgs)
+	var tss []string
+	for _, m := range msgs {
+		tss = append(tss, m["ts"].(string))
+	}
+	assert.Equal(t, []string{"1700000001.000000", "1700000002.000000", "1700000003.000000"}, tss)
+}
+
+func readJSON(t *testing.T, name string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, v))
+}
@@ -0,0 +1,87 @@
+package edge
+
+import (
+	"context"
+	"encoding/json"
+	"runtime/trace"
+)
+
+// drafts.* API
+
+type draftsListForm struct {
+	BaseRequest
+	IsActive bool   `json:"is_active"`
+	Limit    int    `json:"limit"`
+	Cursor   string `json:"cursor,omitempty"`
+	WebClientFields
+}
+
+type draftsListResponse struct {
+	baseResponse
+	Drafts []Draft `json:"drafts"`
+}
+
+// Draft is the unsent message draft of the current user, including the
+// messages scheduled from the composer.
+type Draft struct {
+	ID                string             `json:"id"`
+	ClientMsgID       string             `json:"client_msg_id,omitempty"`
+	UserID            string             `json:"user_id"`
+	TeamID            string             `json:"team_id,omitempty"`
+	DateCreated       int64              `json:"date_created"`
+	DateScheduled     int64              `json:"date_scheduled,omitempty"`
+	LastUpdatedTS     string             `json:"last_updated_ts,omitempty"`
+	LastUpdatedClient string             `json:"last_updated_client,omitempty"`
+	Blocks            json.RawMessage    `json:"blocks,omitempty"`
+	FileIDs           []string           `json:"file_ids,omitempty"`
+	IsFromComposer    bool               `json:"is_from_composer,omitempty"`
+	IsDeleted         bool               `json:"is_deleted,omitempty"`
+	IsSent            bool               `json:"is_sent,omitempty"`
+	Destinations      []DraftDestination `json:"destinations,omitempty"`
+}
+
+// DraftDestination is the conversation or the thread the draft is addressed
+// to.
+type DraftDestination struct {
+	ChannelID string   `json:"channel_id,omitempty"`
+	UserIDs   []string `json:"user_ids,omitempty"`
+	ThreadTS  string   `json:"thread_ts,omitempty"`
+	Broadcast bool     `json:"broadcast,omitempty"`
+}
+
+// DraftsList returns the active drafts of the current user.
+func (cl *Client) DraftsList(ctx context.Context) ([]Draft, error) {
+	ctx, task := trace.NewTask(ctx, "DraftsList")
+	defer task.End()
+
+	form := draftsListForm{
+		BaseRequest:     BaseRequest{Token: cl.token},
+		IsActive:        true,
+		Limit:           100,
+		WebClientFields: webclientReason("drafts-list"),
+	}
+	lim := tier2boost.limiter()
+	var drafts []Draft
+	for {
+		resp, err := cl.PostForm(ctx, "drafts.list", values(form, true))
+		if err != nil {
+			return nil, err
+		}
+		r := draftsListResponse{}
+		if err := cl.ParseResponse(&r, resp); err != nil {
+			return nil, err
+		}
+		if err := r.validate("drafts.list"); err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, r.Drafts...)
+		if r.ResponseMetadata.NextCursor == "" {
+			break
+		}
+		form.Cursor = r.ResponseMetadata.NextCursor
+		if err := lim.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return drafts, nil
+}
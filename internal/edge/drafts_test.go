@@ -0,0 +1,57 @@
+package edge
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_DraftsList(t *testing.T) {
+	pages := map[string]string{
+		"":      `{"ok":true,"drafts":[{"id":"Dr1","user_id":"U1","blocks":[{"type":"rich_text"}],"destinations":[{"channel_id":"C1"}]}],"response_metadata":{"next_cursor":"page2"}}`,
+		"page2": `{"ok":true,"drafts":[{"id":"Dr2","user_id":"U1","date_scheduled":1700000000,"destinations":[{"channel_id":"C2","thread_ts":"1699999999.000100"}]}]}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/drafts.list" {
+			http.NotFound(w, r)
+			return
+		}
+		page, ok := pages[r.FormValue("cursor")]
+		if !ok {
+			http.Error(w, "unexpected cursor", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	cl := Client{
+		cl:           http.DefaultClient,
+		edgeAPI:      srv.URL + "/",
+		webclientAPI: srv.URL + "/",
+	}
+	got, err := cl.DraftsList(context.Background())
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "Dr1", got[0].ID)
+	assert.JSONEq(t, `[{"type":"rich_text"}]`, string(got[0].Blocks))
+	assert.Equal(t, int64(1700000000), got[1].DateScheduled)
+	assert.Equal(t, "1699999999.000100", got[1].Destinations[0].ThreadTS)
+}
+
+func TestClient_DraftsList_error(t *testing.T) {
+	srv := testServer(http.StatusOK, []byte(`{"ok":false,"error":"not_allowed_token_type"}`))
+	defer srv.Close()
+
+	cl := Client{
+		cl:           http.DefaultClient,
+		edgeAPI:      srv.URL + "/",
+		webclientAPI: srv.URL + "/",
+	}
+	_, err := cl.DraftsList(context.Background())
+	assert.Error(t, err)
+}
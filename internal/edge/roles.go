@@ -0,0 +1,75 @@
+package edge
+
+import (
+	"context"
+	"runtime/trace"
+	"strings"
+)
+
+// admin.roles.* API
+
+// RoleChannelManager is the ID of the channel manager role.
+const RoleChannelManager = "Rl0A"
+
+type adminRolesListAssignmentsForm struct {
+	BaseRequest
+	RoleIDs   string `json:"role_ids,omitempty"`
+	EntityIDs string `json:"entity_ids,omitempty"`
+	Limit     int    `json:"limit"`
+	Cursor    string `json:"cursor,omitempty"`
+	WebClientFields
+}
+
+type adminRolesListAssignmentsResponse struct {
+	baseResponse
+	RoleAssignments []RoleAssignment `json:"role_assignments"`
+}
+
+// RoleAssignment is the assignment of the role to the user within the
+// entity, i.e. the channel.
+type RoleAssignment struct {
+	RoleID     string `json:"role_id"`
+	EntityID   string `json:"entity_id"`
+	UserID     string `json:"user_id"`
+	DateCreate int64  `json:"date_create,omitempty"`
+}
+
+// AdminRoleAssignments returns the assignments of the roles roleIDs within
+// the entities entityIDs.  Empty roleIDs or entityIDs mean all.  It requires
+// the admin privileges on the Enterprise Grid organisation.
+func (cl *Client) AdminRoleAssignments(ctx context.Context, roleIDs []string, entityIDs []string) ([]RoleAssignment, error) {
+	ctx, task := trace.NewTask(ctx, "AdminRoleAssignments")
+	defer task.End()
+
+	form := adminRolesListAssignmentsForm{
+		BaseRequest:     BaseRequest{Token: cl.token},
+		RoleIDs:         strings.Join(roleIDs, ","),
+		EntityIDs:       strings.Join(entityIDs, ","),
+		Limit:           200,
+		WebClientFields: webclientReason("admin-roles-list-assignments"),
+	}
+	lim := tier2boost.limiter()
+	var ra []RoleAssignment
+	for {
+		resp, err := cl.PostForm(ctx, "admin.roles.listAssignments", values(form, true))
+		if err != nil {
+			return nil, err
+		}
+		r := adminRolesListAssignmentsResponse{}
+		if err := cl.ParseResponse(&r, resp); err != nil {
+			return nil, err
+		}
+		if err := r.validate("admin.roles.listAssignments"); err != nil {
+			return nil, err
+		}
+		ra = append(ra, r.RoleAssignments...)
+		if r.ResponseMetadata.NextCursor == "" {
+			break
+		}
+		form.Cursor = r.ResponseMetadata.NextCursor
+		if err := lim.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return ra, nil
+}
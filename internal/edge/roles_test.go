@@ -0,0 +1,60 @@
+package edge
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_AdminRoleAssignments(t *testing.T) {
+	pages := map[string]string{
+		"":      `{"ok":true,"role_assignments":[{"role_id":"Rl0A","entity_id":"C1","user_id":"U1","date_create":1700000000}],"response_metadata":{"next_cursor":"page2"}}`,
+		"page2": `{"ok":true,"role_assignments":[{"role_id":"Rl0A","entity_id":"C2","user_id":"U2"}]}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/admin.roles.listAssignments" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.FormValue("role_ids") != RoleChannelManager || r.FormValue("entity_ids") != "C1,C2" {
+			http.Error(w, "unexpected parameters", http.StatusBadRequest)
+			return
+		}
+		page, ok := pages[r.FormValue("cursor")]
+		if !ok {
+			http.Error(w, "unexpected cursor", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	cl := Client{
+		cl:           http.DefaultClient,
+		edgeAPI:      srv.URL + "/",
+		webclientAPI: srv.URL + "/",
+	}
+	got, err := cl.AdminRoleAssignments(context.Background(), []string{RoleChannelManager}, []string{"C1", "C2"})
+	require.NoError(t, err)
+	assert.Equal(t, []RoleAssignment{
+		{RoleID: "Rl0A", EntityID: "C1", UserID: "U1", DateCreate: 1700000000},
+		{RoleID: "Rl0A", EntityID: "C2", UserID: "U2"},
+	}, got)
+}
+
+func TestClient_AdminRoleAssignments_error(t *testing.T) {
+	srv := testServer(http.StatusOK, []byte(`{"ok":false,"error":"not_an_enterprise"}`))
+	defer srv.Close()
+
+	cl := Client{
+		cl:           http.DefaultClient,
+		edgeAPI:      srv.URL + "/",
+		webclientAPI: srv.URL + "/",
+	}
+	_, err := cl.AdminRoleAssignments(context.Background(), []string{RoleChannelManager}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package edge
+
+import (
+	"context"
+	"runtime/trace"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/types"
+)
+
+// team.* API
+
+type teamInfoForm struct {
+	BaseRequest
+	WebClientFields
+}
+
+type teamInfoResponse struct {
+	baseResponse
+	Team TeamInfo `json:"team"`
+}
+
+// TeamInfo is the subset of the team.info response, that has the fields
+// returned to the web client only.
+type TeamInfo struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Domain         string `json:"domain"`
+	Plan           string `json:"plan,omitempty"`
+	EnterpriseID   string `json:"enterprise_id,omitempty"`
+	EnterpriseName string `json:"enterprise_name,omitempty"`
+}
+
+// TeamInfo calls the team.info API.
+func (cl *Client) TeamInfo(ctx context.Context) (*TeamInfo, error) {
+	ctx, task := trace.NewTask(ctx, "TeamInfo")
+	defer task.End()
+
+	form := teamInfoForm{
+		BaseRequest:     BaseRequest{Token: cl.token},
+		WebClientFields: webclientReason("team-info"),
+	}
+	resp, err := cl.PostForm(ctx, "team.info", values(form, true))
+	if err != nil {
+		return nil, err
+	}
+	var r teamInfoResponse
+	if err := cl.ParseResponse(&r, resp); err != nil {
+		return nil, err
+	}
+	if err := r.validate("team.info"); err != nil {
+		return nil, err
+	}
+	return &r.Team, nil
+}
+
+// DescribeWorkspace populates the workspace plan and the Enterprise Grid
+// organisation of wd, using the information available to the web client.
+func (cl *Client) DescribeWorkspace(ctx context.Context, atr *slack.AuthTestResponse, wd *types.Workspace) error {
+	ub, err := cl.ClientUserBoot(ctx)
+	if err != nil {
+		return err
+	}
+	wd.Plan = ub.Team.Plan
+	ti, err := cl.TeamInfo(ctx)
+	if err != nil {
+		return err
+	}
+	if wd.Plan == "" {
+		wd.Plan = ti.Plan
+	}
+	entID := ti.EnterpriseID
+	if entID == "" && atr != nil {
+		entID = atr.EnterpriseID
+	}
+	if entID == "" {
+		return nil
+	}
+	ent := &types.Enterprise{ID: entID, Name: ti.EnterpriseName}
+	for _, ws := range ub.Workspaces {
+		ent.Workspaces = append(ent.Workspaces, types.EnterpriseWorkspace{
+			ID:     ws.ID,
+			Name:   ws.Name,
+			Domain: ws.Domain,
+			URL:    ws.URL,
+		})
+	}
+	wd.Enterprise = ent
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"io"
 
 	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/types"
 )
 
 type Wrapper struct {
@@ -50,6 +52,18 @@ func (w *Wrapper) ListBookmarks(channelID string) ([]slack.Bookmark, error) {
 	return w.cl.ListBookmarks(channelID)
 }
 
+func (w *Wrapper) ListPinsContext(ctx context.Context, channel string) ([]slack.Item, *slack.Paging, error) {
+	return w.cl.ListPinsContext(ctx, channel)
+}
+
+func (w *Wrapper) GetUserGroupsContext(ctx context.Context, options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	return w.cl.GetUserGroupsContext(ctx, options...)
+}
+
+func (w *Wrapper) GetUserGroupMembersContext(ctx context.Context, userGroup string) ([]string, error) {
+	return w.cl.GetUserGroupMembersContext(ctx, userGroup)
+}
+
 func (w *Wrapper) GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) (channels []slack.Channel, nextCursor string, err error) {
 	return w.edge.GetConversationsContext(ctx, params)
 }
@@ -70,6 +84,10 @@ func (w *Wrapper) GetUsersContext(ctx context.Context, options ...slack.GetUsers
 	return w.cl.GetUsersContext(ctx, options...)
 }
 
+func (w *Wrapper) GetUsersInfoContext(ctx context.Context, users ...string) (*[]slack.User, error) {
+	return w.cl.GetUsersInfoContext(ctx, users...)
+}
+
 func (w *Wrapper) GetEmojiContext(ctx context.Context) (map[string]string, error) {
 	return w.cl.GetEmojiContext(ctx)
 }
@@ -81,3 +99,19 @@ func (w *Wrapper) SearchMessagesContext(ctx context.Context, query string, param
 func (w *Wrapper) SearchFilesContext(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchFiles, error) {
 	return w.cl.SearchFilesContext(ctx, query, params)
 }
+
+func (w *Wrapper) GetBotInfoContext(ctx context.Context, parameters slack.GetBotInfoParameters) (*slack.Bot, error) {
+	return w.cl.GetBotInfoContext(ctx, parameters)
+}
+
+func (w *Wrapper) GetTeamInfoContext(ctx context.Context) (*slack.TeamInfo, error) {
+	return w.cl.GetTeamInfoContext(ctx)
+}
+
+func (w *Wrapper) GetBillableInfoContext(ctx context.Context, params slack.GetBillableInfoParams) (map[string]slack.BillingActive, error) {
+	return w.cl.GetBillableInfoContext(ctx, params)
+}
+
+func (w *Wrapper) DescribeWorkspace(ctx context.Context, atr *slack.AuthTestResponse, wd *types.Workspace) error {
+	return w.edge.DescribeWorkspace(ctx, atr, wd)
+}
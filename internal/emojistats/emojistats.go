@@ -0,0 +1,307 @@
+// Package emojistats collects the emoji usage statistics of the archive: how
+// often each emoji is used in reactions and in the message text, per
+// channel and per user, for the community health reporting.
+package emojistats
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/source"
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+// Count is the usage count of a single emoji.
+type Count struct {
+	Emoji string `json:"emoji"`
+	// Reactions is the number of reactions with the emoji.
+	Reactions int `json:"reactions"`
+	// Messages is the number of times the emoji is used in the message
+	// text.
+	Messages int `json:"messages"`
+}
+
+// Total returns the total number of uses.
+func (c Count) Total() int {
+	return c.Reactions + c.Messages
+}
+
+// Usage is the emoji usage in the channel or by the user.
+type Usage struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// Emoji is sorted by the total number of uses, most used first.
+	Emoji []Count `json:"emoji"`
+}
+
+// Report is the emoji usage report.
+type Report struct {
+	// Emoji is the usage of each emoji in the archive, most used first.
+	Emoji []Count `json:"emoji"`
+	// Channels and Users are sorted by ID.
+	Channels []Usage `json:"channels"`
+	Users    []Usage `json:"users"`
+}
+
+// message is the emoji usage in a single message.
+type message struct {
+	channelID string
+	userID    string
+	text      []string // emoji used in the text
+	reactions []slack.ItemReaction
+}
+
+// Collector collects the emoji usage of the messages.  Messages are
+// identified by the channel ID and the timestamp, if the message is added
+// more than once, i.e. if it was recorded by several runs, the last version
+// is accounted.  Zero value is ready to use.
+type Collector struct {
+	idx  map[string]int
+	msgs []message
+}
+
+// AddMessage adds the message m, posted in the channel channelID.
+func (c *Collector) AddMessage(channelID string, m *slack.Message) {
+	c.add(channelID, m, true)
+}
+
+func (c *Collector) add(channelID string, m *slack.Message, replace bool) {
+	if m.Timestamp == "" {
+		return
+	}
+	if c.idx == nil {
+		c.idx = make(map[string]int)
+	}
+	key := channelID + ":" + m.Timestamp
+	i, seen := c.idx[key]
+	if seen && !replace {
+		return
+	}
+	msg := message{
+		channelID: channelID,
+		userID:    m.User,
+		text:      TextEmoji(m.Text),
+		reactions: m.Reactions,
+	}
+	if seen {
+		c.msgs[i] = msg
+		return
+	}
+	c.idx[key] = len(c.msgs)
+	c.msgs = append(c.msgs, msg)
+}
+
+// AddChunk adds the messages from the message chunks.  Thread parents are
+// added only if the message was not seen in the channel, as the parents in
+// the thread chunks may be incomplete.
+func (c *Collector) AddChunk(ch *chunk.Chunk) {
+	switch ch.Type {
+	case chunk.CMessages, chunk.CThreadMessages:
+		if ch.Parent != nil {
+			c.add(ch.ChannelID, ch.Parent, false)
+		}
+		for i := range ch.Messages {
+			c.AddMessage(ch.ChannelID, &ch.Messages[i])
+		}
+	}
+}
+
+// Report returns the report.  Channel and user names are resolved from
+// channels and users, if they are present there.
+//
+// Reactions are attributed to the users who reacted.  Slack lists only the
+// first users for the popular reactions, so the per user numbers may be
+// lower than the per channel ones.  Emoji in the text are attributed to the
+// message author.
+func (c *Collector) Report(channels []slack.Channel, users []slack.User) *Report {
+	chanNames := make(map[string]string, len(channels))
+	for _, ch := range channels {
+		chanNames[ch.ID] = channelName(&ch)
+	}
+	userNames := make(map[string]string, len(users))
+	for _, u := range users {
+		userNames[u.ID] = u.Name
+	}
+
+	var (
+		total  = make(counter)
+		byChan = make(map[string]counter)
+		byUser = make(map[string]counter)
+	)
+	for _, m := range c.msgs {
+		for _, name := range m.text {
+			total.get(name).Messages++
+			account(byChan, m.channelID, name).Messages++
+			if m.userID != "" {
+				account(byUser, m.userID, name).Messages++
+			}
+		}
+		for _, r := range m.reactions {
+			name := normalise(r.Name)
+			n := r.Count
+			if n < len(r.Users) {
+				n = len(r.Users)
+			}
+			total.get(name).Reactions += n
+			account(byChan, m.channelID, name).Reactions += n
+			for _, uid := range r.Users {
+				account(byUser, uid, name).Reactions++
+			}
+		}
+	}
+	return &Report{
+		Emoji:    total.sorted(),
+		Channels: usages(byChan, chanNames),
+		Users:    usages(byUser, userNames),
+	}
+}
+
+// counter counts the uses of each emoji.
+type counter map[string]*Count
+
+func (c counter) get(name string) *Count {
+	cnt, ok := c[name]
+	if !ok {
+		cnt = &Count{Emoji: name}
+		c[name] = cnt
+	}
+	return cnt
+}
+
+// sorted returns the counts sorted by the total number of uses, most used
+// first.
+func (c counter) sorted() []Count {
+	out := make([]Count, 0, len(c))
+	for _, cnt := range c {
+		out = append(out, *cnt)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Total() != out[j].Total() {
+			return out[i].Total() > out[j].Total()
+		}
+		return out[i].Emoji < out[j].Emoji
+	})
+	return out
+}
+
+func account(m map[string]counter, id string, name string) *Count {
+	cnt, ok := m[id]
+	if !ok {
+		cnt = make(counter)
+		m[id] = cnt
+	}
+	return cnt.get(name)
+}
+
+func usages(m map[string]counter, names map[string]string) []Usage {
+	out := make([]Usage, 0, len(m))
+	for id, cnt := range m {
+		out = append(out, Usage{ID: id, Name: names[id], Emoji: cnt.sorted()})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+func channelName(ch *slack.Channel) string {
+	switch {
+	case ch.Name != "":
+		return "#" + ch.Name
+	case ch.IsIM && ch.User != "":
+		return "@" + ch.User
+	}
+	return ""
+}
+
+// emojiRe matches the emoji codes in the message text, i.e. ":smile:".
+var emojiRe = regexp.MustCompile(`:([a-z0-9_+'-]+):`)
+
+// numericEmoji are the standard emoji, whose names consist of digits only.
+// Other numbers between the colons, i.e. in "10:30:00", are not emoji.
+var numericEmoji = map[string]bool{"100": true, "1234": true}
+
+// TextEmoji returns the names of the emoji used in the text, in the order of
+// appearance.  Skin tone modifiers are dropped, so that ":+1::skin-tone-2:"
+// is accounted as "+1".
+func TextEmoji(text string) []string {
+	if !strings.Contains(text, ":") {
+		return nil
+	}
+	var names []string
+	for _, m := range emojiRe.FindAllStringSubmatch(text, -1) {
+		name := m[1]
+		if strings.HasPrefix(name, "skin-tone-") {
+			continue
+		}
+		if strings.Trim(name, "0123456789") == "" && !numericEmoji[name] {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// normalise drops the skin tone modifier from the reaction name, i.e.
+// "+1::skin-tone-2" becomes "+1".
+func normalise(name string) string {
+	name, _, _ = strings.Cut(name, "::")
+	return name
+}
+
+// FromSource collects the emoji usage of all channels in the source src,
+// i.e. the chunk directory, the export or the dump, including the thread
+// replies.
+func FromSource(ctx context.Context, src source.Sourcer) (*Report, error) {
+	channels, err := src.Channels()
+	if err != nil {
+		return nil, err
+	}
+	var c Collector
+	for _, ch := range channels {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		msgs, err := src.AllMessages(ch.ID)
+		if err != nil {
+			if notFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		for i := range msgs {
+			m := &msgs[i]
+			c.AddMessage(ch.ID, m)
+			if !structures.IsThreadStart(m) || m.LatestReply == structures.LatestReplyNoReplies {
+				continue
+			}
+			replies, err := src.AllThreadMessages(ch.ID, m.ThreadTimestamp)
+			if err != nil {
+				if notFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			// the parent in the channel is the complete version.
+			for j := range replies {
+				c.add(ch.ID, &replies[j], false)
+			}
+		}
+	}
+	users, err := src.Users()
+	if err != nil && !notFound(err) {
+		return nil, err
+	}
+	return c.Report(channels, users), nil
+}
+
+func notFound(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, chunk.ErrNotFound)
+}
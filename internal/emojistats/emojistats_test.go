@@ -0,0 +1,145 @@
+package emojistats
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/source"
+)
+
+func TestTextEmoji(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"no emoji here", nil},
+		{"hello :wave: and :tada::tada:", []string{"wave", "tada", "tada"}},
+		{"thanks :+1::skin-tone-3:", []string{"+1"}},
+		{"meet at 10:30:00 :100:", []string{"100"}},
+		{"https://example.com/a:b", nil},
+	}
+	for _, tt := range tests {
+		if got := TextEmoji(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("TextEmoji(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func testCollector() *Collector {
+	var c Collector
+	c.AddChunk(&chunk.Chunk{
+		Type:      chunk.CMessages,
+		ChannelID: "C1",
+		Messages: []slack.Message{
+			{Msg: slack.Msg{Timestamp: "1.0", User: "U1", Text: "hi :wave:", Reactions: []slack.ItemReaction{
+				{Name: "wave", Count: 1, Users: []string{"U2"}},
+			}}},
+			{Msg: slack.Msg{Timestamp: "2.0", User: "U2", Text: "old version"}},
+		},
+	})
+	// the later version of the message replaces the earlier one.
+	c.AddChunk(&chunk.Chunk{
+		Type:      chunk.CMessages,
+		ChannelID: "C1",
+		Messages: []slack.Message{
+			{Msg: slack.Msg{Timestamp: "2.0", User: "U2", Text: "new :tada:", Reactions: []slack.ItemReaction{
+				{Name: "+1::skin-tone-2", Count: 3, Users: []string{"U1", "U3"}},
+			}}},
+		},
+	})
+	// incomplete parent must not replace the message.
+	c.AddChunk(&chunk.Chunk{
+		Type:      chunk.CThreadMessages,
+		ChannelID: "C1",
+		Parent:    &slack.Message{Msg: slack.Msg{Timestamp: "1.0"}},
+		Messages: []slack.Message{
+			{Msg: slack.Msg{Timestamp: "3.0", ThreadTimestamp: "1.0", User: "U1", Text: ":wave:"}},
+		},
+	})
+	return &c
+}
+
+func TestCollector_Report(t *testing.T) {
+	c := testCollector()
+	channels := []slack.Channel{{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}, Name: "general"}}}
+	users := []slack.User{{ID: "U1", Name: "alice"}}
+
+	r := c.Report(channels, users)
+	wantTotal := []Count{
+		{Emoji: "+1", Reactions: 3},
+		{Emoji: "wave", Reactions: 1, Messages: 2},
+		{Emoji: "tada", Messages: 1},
+	}
+	if !reflect.DeepEqual(r.Emoji, wantTotal) {
+		t.Errorf("emoji = %+v, want %+v", r.Emoji, wantTotal)
+	}
+	wantChans := []Usage{{ID: "C1", Name: "#general", Emoji: wantTotal}}
+	if !reflect.DeepEqual(r.Channels, wantChans) {
+		t.Errorf("channels = %+v, want %+v", r.Channels, wantChans)
+	}
+	wantUsers := []Usage{
+		{ID: "U1", Name: "alice", Emoji: []Count{{Emoji: "wave", Messages: 2}, {Emoji: "+1", Reactions: 1}}},
+		{ID: "U2", Emoji: []Count{{Emoji: "tada", Messages: 1}, {Emoji: "wave", Reactions: 1}}},
+		{ID: "U3", Emoji: []Count{{Emoji: "+1", Reactions: 1}}},
+	}
+	if !reflect.DeepEqual(r.Users, wantUsers) {
+		t.Errorf("users = %+v, want %+v", r.Users, wantUsers)
+	}
+}
+
+func TestReport_WriteCSV(t *testing.T) {
+	r := &Report{
+		Emoji:    []Count{{Emoji: "wave", Reactions: 1, Messages: 2}},
+		Channels: []Usage{{ID: "C1", Name: "#general", Emoji: []Count{{Emoji: "wave", Reactions: 1, Messages: 2}}}},
+		Users:    []Usage{{ID: "U1", Name: "alice", Emoji: []Count{{Emoji: "wave", Messages: 2}}}},
+	}
+	var buf bytes.Buffer
+	if err := r.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"scope,id,name,emoji,reactions,messages,total",
+		"total,,,wave,1,2,3",
+		"channel,C1,#general,wave,1,2,3",
+		"user,U1,alice,wave,0,2,2",
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCSV() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFromSource(t *testing.T) {
+	fsys := fstest.MapFS{
+		"C1.json": &fstest.MapFile{Data: []byte(`{"channel_id":"C1","name":"general","messages":[
+			{"ts":"1.0","thread_ts":"1.0","latest_reply":"2.0","user":"U1","text":":wave:","slackdump_thread_replies":[
+				{"ts":"2.0","thread_ts":"1.0","user":"U2","text":":tada:"}
+			]},
+			{"ts":"3.0","user":"U2","reactions":[{"name":"+1","users":["U1"],"count":1}]}
+		]}`)},
+	}
+	src, err := source.NewDump(fsys, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := FromSource(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Count{
+		{Emoji: "+1", Reactions: 1},
+		{Emoji: "tada", Messages: 1},
+		{Emoji: "wave", Messages: 1},
+	}
+	if !reflect.DeepEqual(r.Emoji, want) {
+		t.Errorf("emoji = %+v, want %+v", r.Emoji, want)
+	}
+}
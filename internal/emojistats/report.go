@@ -0,0 +1,58 @@
+package emojistats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// Scopes of the CSV records.
+const (
+	ScopeTotal   = "total"
+	ScopeChannel = "channel"
+	ScopeUser    = "user"
+)
+
+// WriteCSV writes the report to w as CSV, one record per emoji and scope:
+// the totals first, then the channels, then the users.  The ID and name of
+// the total records are empty.
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"scope", "id", "name", "emoji", "reactions", "messages", "total"}); err != nil {
+		return err
+	}
+	write := func(scope, id, name string, cc []Count) error {
+		for _, c := range cc {
+			if err := cw.Write([]string{
+				scope, id, name, c.Emoji,
+				strconv.Itoa(c.Reactions), strconv.Itoa(c.Messages), strconv.Itoa(c.Total()),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := write(ScopeTotal, "", "", r.Emoji); err != nil {
+		return err
+	}
+	for _, u := range r.Channels {
+		if err := write(ScopeChannel, u.ID, u.Name, u.Emoji); err != nil {
+			return err
+		}
+	}
+	for _, u := range r.Users {
+		if err := write(ScopeUser, u.ID, u.Name, u.Emoji); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the report to w as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
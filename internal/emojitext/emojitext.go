@@ -0,0 +1,256 @@
+// Package emojitext converts the Slack emoji shortcodes, i.e. ":tada:", to
+// the Unicode characters in the human-readable output formats.  Custom
+// emojis of the workspace can't be represented in Unicode, they are left as
+// shortcodes, followed by the link to the emoji image.
+package emojitext
+
+import (
+	"html"
+	"strconv"
+	"strings"
+
+	emj "github.com/enescakir/emoji"
+)
+
+const (
+	// aliasPrefix is the prefix of the custom emoji value, that is an alias
+	// of another emoji, as returned by the Slack API.
+	aliasPrefix = "alias:"
+	// maxAliasDepth is the maximum number of hops when resolving an alias,
+	// guards against the alias loops.
+	maxAliasDepth = 8
+	// maxNameLen is the maximum length of the emoji name.
+	maxNameLen = 100
+	// skinTonePrefix is the prefix of the skin tone modifier shortcode, that
+	// follows the emoji, i.e. ":wave::skin-tone-3:".
+	skinTonePrefix = "skin-tone-"
+)
+
+// slackNames are the names of the emojis, that Slack uses and the emoji
+// table does not have.
+var slackNames = map[string]string{
+	"simple_smile": "\U0001F642",
+	"squirrel":     "\U0001F43F\uFE0F",
+	"white_square": "\u25FB\uFE0F",
+	"black_square": "\u25FC\uFE0F",
+}
+
+// skinTones are the Fitzpatrick modifiers for the Slack skin tones 2-6.
+var skinTones = [...]rune{2: 0x1F3FB, 3: 0x1F3FC, 4: 0x1F3FD, 5: 0x1F3FE, 6: 0x1F3FF}
+
+// Converter converts the emoji shortcodes in the text.  Zero value converts
+// the standard emojis only.
+type Converter struct {
+	// custom maps the custom emoji name to the image URL, or to the
+	// "alias:<name>" for the aliases.
+	custom map[string]string
+}
+
+// Standard is the converter of the standard emojis.
+var Standard = New(nil)
+
+// New returns the converter, custom maps the names of the custom emojis of
+// the workspace to their image URLs, the aliases should have the
+// "alias:<name>" value, same as the Slack API returns.
+func New(custom map[string]string) *Converter {
+	return &Converter{custom: custom}
+}
+
+// Lookup returns the Unicode representation of the standard emoji name, the
+// name must not include the colons.
+func Lookup(name string) (string, bool) {
+	if s, ok := slackNames[name]; ok {
+		return s, true
+	}
+	if s, ok := emj.Find(":" + name + ":"); ok {
+		return s, true
+	}
+	// slack uses dashes in some names, where the table has underscores, i.e.
+	// "woman-shrugging".
+	if strings.Contains(name, "-") {
+		return emj.Find(":" + strings.ReplaceAll(name, "-", "_") + ":")
+	}
+	return "", false
+}
+
+// FromCodepoints returns the string for the dash separated hexadecimal code
+// points, i.e. "1f469-200d-1f4bb", as it appears in the "unicode" field of the
+// emoji elements of the rich text blocks.
+func FromCodepoints(s string) (string, bool) {
+	if s == "" {
+		return "", false
+	}
+	var buf strings.Builder
+	for _, cp := range strings.Split(s, "-") {
+		r, err := strconv.ParseUint(cp, 16, 32)
+		if err != nil || r > 0x10FFFF {
+			return "", false
+		}
+		buf.WriteRune(rune(r))
+	}
+	return buf.String(), true
+}
+
+// WithSkinTone applies the Slack skin tone (2-6) to the emoji e.  The emoji
+// is returned unchanged, if the tone is out of range.
+func WithSkinTone(e string, tone int) string {
+	if tone < 2 || len(skinTones) <= tone || e == "" {
+		return e
+	}
+	rr := []rune(e)
+	rest := rr[1:]
+	if len(rest) > 0 && rest[0] == '\uFE0F' {
+		// the modifier replaces the presentation selector.
+		rest = rest[1:]
+	}
+	return string(rr[0]) + string(skinTones[tone]) + string(rest)
+}
+
+// Text replaces the emoji shortcodes in the plain text s.  The custom
+// emojis are rendered as ":name: (url)", unknown shortcodes are left as is.
+func (c *Converter) Text(s string) string {
+	return c.replace(s, c.textEmoji, noEscape)
+}
+
+// HTML escapes the text s and replaces the emoji shortcodes.  The custom
+// emojis are rendered as the images.
+func (c *Converter) HTML(s string) string {
+	return c.replace(s, c.htmlEmoji, html.EscapeString)
+}
+
+// TextEmoji returns the plain text representation of the emoji name, with
+// the skin tone applied.
+func (c *Converter) TextEmoji(name string, tone int) string {
+	s, _ := c.textEmoji(name, tone)
+	return s
+}
+
+// HTMLEmoji returns the HTML representation of the emoji name, with the
+// skin tone applied.
+func (c *Converter) HTMLEmoji(name string, tone int) string {
+	s, _ := c.htmlEmoji(name, tone)
+	return s
+}
+
+func noEscape(s string) string { return s }
+
+// textEmoji returns the text for the emoji name, and true, if the name is
+// known.
+func (c *Converter) textEmoji(name string, tone int) (string, bool) {
+	e, url, ok := c.resolve(name)
+	if !ok {
+		return ":" + name + ":", false
+	}
+	if url != "" {
+		return ":" + name + ": (" + url + ")", true
+	}
+	return WithSkinTone(e, tone), true
+}
+
+// htmlEmoji returns the HTML for the emoji name, and true, if the name is
+// known.
+func (c *Converter) htmlEmoji(name string, tone int) (string, bool) {
+	e, url, ok := c.resolve(name)
+	if !ok {
+		return html.EscapeString(":" + name + ":"), false
+	}
+	if url != "" {
+		code := html.EscapeString(":" + name + ":")
+		return `<img class="slack-emoji" src="` + html.EscapeString(url) + `" alt="` + code + `" title="` + code + `">`, true
+	}
+	return WithSkinTone(e, tone), true
+}
+
+// resolve returns the Unicode string of the emoji name, or, for the custom
+// emojis, the image URL.
+func (c *Converter) resolve(name string) (e string, url string, ok bool) {
+	for range maxAliasDepth {
+		if c != nil {
+			if v, ok := c.custom[name]; ok {
+				if target, isAlias := strings.CutPrefix(v, aliasPrefix); isAlias {
+					name = target
+					continue
+				}
+				return "", v, v != ""
+			}
+		}
+		e, ok := Lookup(name)
+		return e, "", ok
+	}
+	return "", "", false
+}
+
+// replace finds the shortcodes in s and replaces them with the output of
+// fn, the text between the shortcodes is passed through escape.
+func (c *Converter) replace(s string, fn func(name string, tone int) (string, bool), escape func(string) string) string {
+	if !strings.Contains(s, ":") {
+		return escape(s)
+	}
+	var (
+		buf  strings.Builder
+		last int // end of the text written to buf.
+	)
+	for i := 0; i < len(s); {
+		start := strings.IndexByte(s[i:], ':')
+		if start < 0 {
+			break
+		}
+		start += i
+		end := strings.IndexByte(s[start+1:], ':')
+		if end < 0 {
+			break
+		}
+		end += start + 1
+		name := s[start+1 : end]
+		if !isName(name) {
+			// the closing colon may open the next shortcode.
+			i = end
+			continue
+		}
+		next := end + 1
+		tone := 0
+		if t, n, ok := skinTone(s[next:]); ok {
+			tone = t
+			next += n
+		}
+		out, ok := fn(name, tone)
+		if !ok {
+			i = end
+			continue
+		}
+		buf.WriteString(escape(s[last:start]))
+		buf.WriteString(out)
+		last, i = next, next
+	}
+	buf.WriteString(escape(s[last:]))
+	return buf.String()
+}
+
+// skinTone parses the skin tone modifier shortcode at the start of s, i.e.
+// ":skin-tone-3:", it returns the tone and the length of the modifier.
+func skinTone(s string) (tone int, n int, ok bool) {
+	const l = len(":" + skinTonePrefix + "2:")
+	if len(s) < l || !strings.HasPrefix(s[1:], skinTonePrefix) || s[0] != ':' || s[l-1] != ':' {
+		return 0, 0, false
+	}
+	d := s[l-2]
+	if d < '2' || '6' < d {
+		return 0, 0, false
+	}
+	return int(d - '0'), l, true
+}
+
+// isName returns true if s can be the emoji name.
+func isName(s string) bool {
+	if s == "" || len(s) > maxNameLen {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case 'a' <= r && r <= 'z', '0' <= r && r <= '9', r == '_', r == '-', r == '+', r == '\'':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package emojitext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testCustom = map[string]string{
+	"partyparrot": "https://emoji.slack-edge.com/T1/partyparrot/1.gif",
+	"parrot":      "alias:partyparrot",
+	"yes":         "alias:white_check_mark",
+	"loop":        "alias:loop",
+}
+
+func TestConverter_Text(t *testing.T) {
+	c := New(testCustom)
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"no emoji", "hello world", "hello world"},
+		{"standard", "done :tada:", "done \U0001F389"},
+		{"several", ":+1::thumbsup: :white_check_mark:", "\U0001F44D\U0001F44D ✅"},
+		{"slack name", ":simple_smile:", "\U0001F642"},
+		{"skin tone", ":wave::skin-tone-3:", "\U0001F44B\U0001F3FC"},
+		{"invalid skin tone", ":wave::skin-tone-9:", "\U0001F44B:skin-tone-9:"},
+		{"custom", "yay :partyparrot:", "yay :partyparrot: (https://emoji.slack-edge.com/T1/partyparrot/1.gif)"},
+		{"custom alias", ":parrot:", ":parrot: (https://emoji.slack-edge.com/T1/partyparrot/1.gif)"},
+		{"alias of standard", ":yes:", "✅"},
+		{"alias loop", ":loop:", ":loop:"},
+		{"unknown", ":not_an_emoji:", ":not_an_emoji:"},
+		{"time", "at 10:30:45 :tada:", "at 10:30:45 \U0001F389"},
+		{"colon before emoji", "note: :tada:", "note: \U0001F389"},
+		{"adjacent unknown", "a:xyz:tada:", "a:xyz\U0001F389"},
+		{"unterminated", "see :tada", "see :tada"},
+		{"url", "https://example.com:8080/", "https://example.com:8080/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.Text(tt.s))
+		})
+	}
+}
+
+func TestConverter_HTML(t *testing.T) {
+	c := New(testCustom)
+	assert.Equal(t, "&lt;b&gt; \U0001F389 &amp; :nope:", c.HTML("<b> :tada: & :nope:"))
+	assert.Equal(t,
+		`hi <img class="slack-emoji" src="https://emoji.slack-edge.com/T1/partyparrot/1.gif" alt=":parrot:" title=":parrot:">`,
+		c.HTML("hi :parrot:"),
+	)
+}
+
+func TestStandard(t *testing.T) {
+	assert.Equal(t, ":partyparrot:", Standard.Text(":partyparrot:"))
+	assert.Equal(t, "\U0001F389", Standard.TextEmoji("tada", 0))
+	assert.Equal(t, ":partyparrot:", Standard.TextEmoji("partyparrot", 0))
+	assert.Equal(t, "\U0001F389", (*Converter)(nil).Text(":tada:"))
+}
+
+func TestFromCodepoints(t *testing.T) {
+	tests := []struct {
+		s      string
+		want   string
+		wantOk bool
+	}{
+		{"1f389", "\U0001F389", true},
+		{"1f469-200d-1f4bb", "\U0001F469‍\U0001F4BB", true},
+		{"", "", false},
+		{"xyz", "", false},
+		{"110000", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := FromCodepoints(tt.s)
+		assert.Equal(t, tt.wantOk, ok, tt.s)
+		assert.Equal(t, tt.want, got, tt.s)
+	}
+}
+
+func TestWithSkinTone(t *testing.T) {
+	assert.Equal(t, "\U0001F44D\U0001F3FF", WithSkinTone("\U0001F44D", 6))
+	assert.Equal(t, "☝\U0001F3FB", WithSkinTone("☝️", 2), "presentation selector is replaced")
+	assert.Equal(t, "\U0001F44D", WithSkinTone("\U0001F44D", 1))
+	assert.Equal(t, "", WithSkinTone("", 3))
+}
+
+func TestLookup(t *testing.T) {
+	got, ok := Lookup("woman-shrugging")
+	assert.True(t, ok)
+	assert.NotEmpty(t, got)
+	_, ok = Lookup("not_an_emoji")
+	assert.False(t, ok)
+}
@@ -0,0 +1,208 @@
+// Package errreport collects the channels, threads and files that were skipped
+// or failed during the run, and produces the errors.jsonl report and the
+// summary table, so that the post-mortem of long runs does not require
+// grepping the logs.
+package errreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rusq/fsadapter"
+
+	"github.com/rusq/slackdump/v3/downloader"
+	"github.com/rusq/slackdump/v3/internal/network"
+	"github.com/rusq/slackdump/v3/stream"
+)
+
+// Filename is the name of the report file in the output base.
+const Filename = "errors.jsonl"
+
+// maxSummaryRows is the maximum number of entries printed in the summary
+// table, the rest is only available in the report file.
+const maxSummaryRows = 20
+
+// Kind is the kind of the failed entity.
+type Kind string
+
+const (
+	KChannel   Kind = "channel"   // channel was skipped
+	KThread    Kind = "thread"    // thread was skipped
+	KFile      Kind = "file"      // file failed to download
+	KRetention Kind = "retention" // channel might have lost messages to the retention
+)
+
+// Entry is the report entry, one per failed entity.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Kind      Kind      `json:"kind"`
+	ChannelID string    `json:"channel_id,omitempty"`
+	ThreadTS  string    `json:"thread_ts,omitempty"`
+	// Path is the path of the file within the output, for files.
+	Path string `json:"path,omitempty"`
+	// URL is the download URL, for files.
+	URL string `json:"url,omitempty"`
+	// Reason is the error message.
+	Reason string `json:"reason"`
+	// Retries is the number of retries made before giving up.
+	Retries int `json:"retries"`
+}
+
+// Report is the report of the skipped and failed entities.  It is safe for
+// concurrent use.
+type Report struct {
+	mu      sync.Mutex
+	entries []Entry
+	nowFn   func() time.Time
+}
+
+// New returns a new empty report.
+func New() *Report {
+	return &Report{nowFn: time.Now}
+}
+
+// Add adds the entry to the report, if the entry time is not set, it is set
+// to the current time.
+func (r *Report) Add(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e.Time.IsZero() {
+		e.Time = r.nowFn()
+	}
+	r.entries = append(r.entries, e)
+}
+
+// StreamError records the failed channel or thread.  It always returns nil,
+// so that the stream skips the failed entity, and can be used with
+// [stream.OptErrorFn].
+func (r *Report) StreamError(sr stream.Result) error {
+	kind := KChannel
+	if sr.Type == stream.RTThread || sr.ThreadTS != "" {
+		kind = KThread
+	}
+	r.Add(Entry{
+		Kind:      kind,
+		ChannelID: sr.ChannelID,
+		ThreadTS:  sr.ThreadTS,
+		Reason:    sr.Err.Error(),
+		Retries:   network.Attempts(sr.Err) - 1,
+	})
+	return nil
+}
+
+// DownloadError records the failed file download, it can be used with
+// [downloader.WithErrorFunc].
+func (r *Report) DownloadError(req downloader.Request, err error) {
+	r.Add(Entry{
+		Kind:    KFile,
+		Path:    req.Fullpath,
+		URL:     req.URL,
+		Reason:  err.Error(),
+		Retries: network.Attempts(err) - 1,
+	})
+}
+
+// Len returns the number of entries in the report.
+func (r *Report) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// Entries returns the copy of the report entries in the order they were
+// added.
+func (r *Report) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Entry(nil), r.entries...)
+}
+
+// Write writes the report to the errors.jsonl file within fsa.  It does
+// nothing if the report is empty.
+func (r *Report) Write(fsa fsadapter.FS) error {
+	entries := r.Entries()
+	if len(entries) == 0 {
+		return nil
+	}
+	wc, err := fsa.Create(Filename)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", Filename, err)
+	}
+	if err := writeEntries(wc, entries); err != nil {
+		wc.Close()
+		return fmt.Errorf("error writing %s: %w", Filename, err)
+	}
+	return wc.Close()
+}
+
+func writeEntries(w io.Writer, entries []Entry) error {
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Summary prints the summary table of the report to w.  It prints nothing if
+// the report is empty.
+func (r *Report) Summary(w io.Writer) error {
+	entries := r.Entries()
+	if len(entries) == 0 {
+		return nil
+	}
+	counts := make(map[Kind]int, 3)
+	for _, e := range entries {
+		counts[e.Kind]++
+	}
+	fmt.Fprintf(w, "Skipped channels: %d, failed threads: %d, failed files: %d", counts[KChannel], counts[KThread], counts[KFile])
+	if n := counts[KRetention]; n > 0 {
+		fmt.Fprintf(w, ", at risk of retention: %d", n)
+	}
+	fmt.Fprintf(w, " (see %s)\n\n", Filename)
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return kindOrder(entries[i].Kind) < kindOrder(entries[j].Kind)
+	})
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tCHANNEL\tTHREAD/FILE\tRETRIES\tREASON")
+	for _, e := range entries[:min(len(entries), maxSummaryRows)] {
+		item := e.ThreadTS
+		if e.Kind == KFile {
+			item = e.Path
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", e.Kind, e.ChannelID, item, e.Retries, oneline(e.Reason))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if n := len(entries) - maxSummaryRows; n > 0 {
+		fmt.Fprintf(w, "... and %d more\n", n)
+	}
+	return nil
+}
+
+func kindOrder(k Kind) int {
+	switch k {
+	case KChannel:
+		return 0
+	case KThread:
+		return 1
+	case KFile:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// oneline replaces the line breaks and tabs, that would break the table.
+func oneline(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ", "\t", " ").Replace(s)
+}
@@ -0,0 +1,107 @@
+package errreport
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rusq/fsadapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/downloader"
+	"github.com/rusq/slackdump/v3/internal/network"
+	"github.com/rusq/slackdump/v3/stream"
+)
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testReport() *Report {
+	r := New()
+	r.nowFn = func() time.Time { return testTime }
+	return r
+}
+
+func TestReport(t *testing.T) {
+	r := testReport()
+	r.DownloadError(downloader.Request{Fullpath: "C1/attachments/F1-a.png", URL: "https://files.slack.com/F1"}, &network.ErrRetryFailed{Err: errors.New("timeout"), Attempts: 3})
+	require.NoError(t, r.StreamError(stream.Result{Type: stream.RTChannel, ChannelID: "C1", Err: errors.New("not_in_channel")}))
+	require.NoError(t, r.StreamError(stream.Result{Type: stream.RTThread, ChannelID: "C2", ThreadTS: "1.0", Err: errors.New("thread_not_found")}))
+
+	want := []Entry{
+		{Time: testTime, Kind: KFile, Path: "C1/attachments/F1-a.png", URL: "https://files.slack.com/F1", Reason: (&network.ErrRetryFailed{Err: errors.New("timeout")}).Error(), Retries: 2},
+		{Time: testTime, Kind: KChannel, ChannelID: "C1", Reason: "not_in_channel"},
+		{Time: testTime, Kind: KThread, ChannelID: "C2", ThreadTS: "1.0", Reason: "thread_not_found"},
+	}
+	assert.Equal(t, want, r.Entries())
+
+	t.Run("write", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, r.Write(fsadapter.NewDirectory(dir)))
+
+		f, err := os.Open(filepath.Join(dir, Filename))
+		require.NoError(t, err)
+		defer f.Close()
+		var got []Entry
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			var e Entry
+			require.NoError(t, json.Unmarshal(s.Bytes(), &e))
+			got = append(got, e)
+		}
+		require.NoError(t, s.Err())
+		assert.Equal(t, want, got)
+	})
+	t.Run("summary", func(t *testing.T) {
+		var buf strings.Builder
+		require.NoError(t, r.Summary(&buf))
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 6)
+		assert.Equal(t, "Skipped channels: 1, failed threads: 1, failed files: 1 (see errors.jsonl)", lines[0])
+		assert.True(t, strings.HasPrefix(lines[2], "KIND"))
+		assert.True(t, strings.HasPrefix(lines[3], "channel"))
+		assert.True(t, strings.HasPrefix(lines[4], "thread"))
+		assert.True(t, strings.HasPrefix(lines[5], "file"))
+	})
+}
+
+func TestReport_empty(t *testing.T) {
+	r := New()
+	dir := t.TempDir()
+	require.NoError(t, r.Write(fsadapter.NewDirectory(dir)))
+	assert.NoFileExists(t, filepath.Join(dir, Filename))
+
+	var buf strings.Builder
+	require.NoError(t, r.Summary(&buf))
+	assert.Empty(t, buf.String())
+}
+
+func TestReport_Summary_truncated(t *testing.T) {
+	r := testReport()
+	for range maxSummaryRows + 5 {
+		r.Add(Entry{Kind: KChannel, ChannelID: "C1", Reason: "line\nbreak"})
+	}
+	var buf strings.Builder
+	require.NoError(t, r.Summary(&buf))
+	out := buf.String()
+	assert.Contains(t, out, "line break")
+	assert.True(t, strings.HasSuffix(out, "... and 5 more\n"))
+}
+
+func TestReport_Summary_retention(t *testing.T) {
+	r := testReport()
+	r.Add(Entry{Kind: KRetention, ChannelID: "C2", Reason: "not saved"})
+	r.Add(Entry{Kind: KChannel, ChannelID: "C1", Reason: "not_in_channel"})
+	var buf strings.Builder
+	require.NoError(t, r.Summary(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, "Skipped channels: 1, failed threads: 0, failed files: 0, at risk of retention: 1 (see errors.jsonl)", lines[0])
+	assert.True(t, strings.HasPrefix(lines[3], "channel"))
+	assert.True(t, strings.HasPrefix(lines[4], "retention"))
+}
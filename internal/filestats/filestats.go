@@ -0,0 +1,210 @@
+// Package filestats collects the storage usage statistics of the file
+// attachments, to help deciding what to clean up in the workspace.
+package filestats
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"sort"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+)
+
+// DefTopN is the default number of the largest files in the report.
+const DefTopN = 20
+
+// File is the file in the report.
+type File struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ChannelID string `json:"channel_id"`
+	UserID    string `json:"user_id"`
+	Size      int64  `json:"size"`
+	Created   int64  `json:"created,omitempty"` // unix time
+}
+
+// Usage is the storage used by the channel or the user.
+type Usage struct {
+	ID    string `json:"id"`
+	Name  string `json:"name,omitempty"`
+	Files int    `json:"files"`
+	Bytes int64  `json:"bytes"`
+}
+
+// Report is the storage usage report.
+type Report struct {
+	Files int   `json:"files"`
+	Bytes int64 `json:"bytes"`
+	// Channels and Users are sorted by the bytes used, largest first.
+	Channels []Usage `json:"channels"`
+	Users    []Usage `json:"users"`
+	// Largest are the largest files, largest first.
+	Largest []File `json:"largest"`
+}
+
+// Collector collects the file metadata.  Each file is accounted only once,
+// in the channel where it was seen first, even if it was shared to several
+// channels.  Zero value is ready to use.
+type Collector struct {
+	seen  map[string]struct{}
+	files []File
+}
+
+// Add adds the file f, posted in the channel channelID.  Files without ID,
+// and the files, that were deleted or are hidden, are ignored.
+func (c *Collector) Add(channelID string, f *slack.File) {
+	if f == nil || f.ID == "" || f.Mode == "tombstone" || f.Mode == "hidden_by_limit" {
+		return
+	}
+	if c.seen == nil {
+		c.seen = make(map[string]struct{})
+	}
+	if _, ok := c.seen[f.ID]; ok {
+		return
+	}
+	c.seen[f.ID] = struct{}{}
+	c.files = append(c.files, File{
+		ID:        f.ID,
+		Name:      f.Name,
+		ChannelID: channelID,
+		UserID:    f.User,
+		Size:      int64(f.Size),
+		Created:   int64(f.Created),
+	})
+}
+
+// AddMessage adds the files of the message m, posted in the channel
+// channelID.
+func (c *Collector) AddMessage(channelID string, m *slack.Message) {
+	for i := range m.Files {
+		c.Add(channelID, &m.Files[i])
+	}
+}
+
+// AddChunk adds the files from the message and file chunks.
+func (c *Collector) AddChunk(ch *chunk.Chunk) {
+	switch ch.Type {
+	case chunk.CMessages, chunk.CThreadMessages:
+		for i := range ch.Messages {
+			c.AddMessage(ch.ChannelID, &ch.Messages[i])
+		}
+	case chunk.CFiles:
+		for i := range ch.Files {
+			c.Add(ch.ChannelID, &ch.Files[i])
+		}
+	}
+}
+
+// Report returns the report with topN largest files, if topN is not
+// positive, all files are listed.  Channel and user names are resolved from
+// channels and users, if they are present there.
+func (c *Collector) Report(topN int, channels []slack.Channel, users []slack.User) *Report {
+	chanNames := make(map[string]string, len(channels))
+	for _, ch := range channels {
+		chanNames[ch.ID] = channelName(&ch)
+	}
+	userNames := make(map[string]string, len(users))
+	for _, u := range users {
+		userNames[u.ID] = u.Name
+	}
+
+	var (
+		r      Report
+		byChan = make(map[string]*Usage)
+		byUser = make(map[string]*Usage)
+	)
+	for _, f := range c.files {
+		r.Files++
+		r.Bytes += f.Size
+		account(byChan, f.ChannelID, chanNames, f.Size)
+		account(byUser, f.UserID, userNames, f.Size)
+	}
+	r.Channels = sorted(byChan)
+	r.Users = sorted(byUser)
+
+	largest := append([]File(nil), c.files...)
+	sort.SliceStable(largest, func(i, j int) bool {
+		if largest[i].Size != largest[j].Size {
+			return largest[i].Size > largest[j].Size
+		}
+		return largest[i].ID < largest[j].ID
+	})
+	if topN > 0 && len(largest) > topN {
+		largest = largest[:topN]
+	}
+	r.Largest = largest
+	return &r
+}
+
+func channelName(ch *slack.Channel) string {
+	switch {
+	case ch.Name != "":
+		return "#" + ch.Name
+	case ch.IsIM && ch.User != "":
+		return "@" + ch.User
+	}
+	return ""
+}
+
+func account(m map[string]*Usage, id string, names map[string]string, size int64) {
+	u, ok := m[id]
+	if !ok {
+		u = &Usage{ID: id, Name: names[id]}
+		m[id] = u
+	}
+	u.Files++
+	u.Bytes += size
+}
+
+// sorted returns the usages sorted by bytes, largest first.
+func sorted(m map[string]*Usage) []Usage {
+	out := make([]Usage, 0, len(m))
+	for _, u := range m {
+		out = append(out, *u)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Bytes != out[j].Bytes {
+			return out[i].Bytes > out[j].Bytes
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// FromChunks collects the files of all channels in the chunk directory cd,
+// and returns the report with topN largest files.
+func FromChunks(ctx context.Context, cd *chunk.Directory, topN int) (*Report, error) {
+	channels, err := cd.Channels()
+	if err != nil {
+		return nil, err
+	}
+	var c Collector
+	for _, ch := range channels {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		f, err := cd.Open(chunk.ToFileID(ch.ID, "", false))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		err = f.ForEach(func(ch *chunk.Chunk) error {
+			c.AddChunk(ch)
+			return nil
+		})
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	users, err := cd.Users()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return c.Report(topN, channels, users), nil
+}
@@ -0,0 +1,93 @@
+package filestats
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+)
+
+func testCollector() *Collector {
+	var c Collector
+	c.AddChunk(&chunk.Chunk{
+		Type:      chunk.CMessages,
+		ChannelID: "C1",
+		Messages: []slack.Message{
+			{Msg: slack.Msg{Files: []slack.File{
+				{ID: "F1", Name: "big.zip", User: "U1", Size: 3 << 20},
+				{ID: "F2", Name: "small.png", User: "U2", Size: 100},
+			}}},
+			{Msg: slack.Msg{Files: []slack.File{
+				{ID: "F9", Mode: "tombstone"},
+			}}},
+		},
+	})
+	// F1 is shared to C2, it must not be accounted twice.
+	c.AddChunk(&chunk.Chunk{
+		Type:      chunk.CFiles,
+		ChannelID: "C2",
+		Files: []slack.File{
+			{ID: "F1", Name: "big.zip", User: "U1", Size: 3 << 20},
+			{ID: "F3", Name: "doc.pdf", User: "U1", Size: 2048},
+		},
+	})
+	return &c
+}
+
+func TestCollector_Report(t *testing.T) {
+	c := testCollector()
+	channels := []slack.Channel{{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}, Name: "general"}}}
+	users := []slack.User{{ID: "U1", Name: "alice"}}
+
+	r := c.Report(2, channels, users)
+	if r.Files != 3 || r.Bytes != 3<<20+100+2048 {
+		t.Errorf("totals = %d files, %d bytes", r.Files, r.Bytes)
+	}
+	wantChans := []Usage{
+		{ID: "C1", Name: "#general", Files: 2, Bytes: 3<<20 + 100},
+		{ID: "C2", Files: 1, Bytes: 2048},
+	}
+	if !reflect.DeepEqual(r.Channels, wantChans) {
+		t.Errorf("channels = %+v, want %+v", r.Channels, wantChans)
+	}
+	wantUsers := []Usage{
+		{ID: "U1", Name: "alice", Files: 2, Bytes: 3<<20 + 2048},
+		{ID: "U2", Files: 1, Bytes: 100},
+	}
+	if !reflect.DeepEqual(r.Users, wantUsers) {
+		t.Errorf("users = %+v, want %+v", r.Users, wantUsers)
+	}
+	var got []string
+	for _, f := range r.Largest {
+		got = append(got, f.ID)
+	}
+	if want := []string{"F1", "F3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("largest = %v, want %v", got, want)
+	}
+	if all := c.Report(0, nil, nil); len(all.Largest) != 3 {
+		t.Errorf("expected all files with topN=0, got %d", len(all.Largest))
+	}
+}
+
+func TestReport_WriteText(t *testing.T) {
+	r := testCollector().Report(DefTopN, nil, nil)
+	var buf strings.Builder
+	if err := r.WriteText(&buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Total: 3 files, 3.0M",
+		"Channels (top 1 of 2)",
+		"Uploaders (top 1 of 2)",
+		"big.zip",
+		"small.png",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
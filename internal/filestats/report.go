@@ -0,0 +1,49 @@
+package filestats
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rusq/slackdump/v3/internal/humanize"
+)
+
+// WriteText writes the human-readable report to w.  The channel and user
+// lists are limited to topN entries, if topN is positive.
+func (r *Report) WriteText(w io.Writer, topN int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Total: %d files, %s\n", r.Files, humanize.Size(r.Bytes))
+
+	writeUsage(tw, "Channels", "CHANNEL", limit(r.Channels, topN), len(r.Channels))
+	writeUsage(tw, "Uploaders", "USER", limit(r.Users, topN), len(r.Users))
+
+	fmt.Fprintf(tw, "\nLargest files\n")
+	fmt.Fprintf(tw, "SIZE\tFILE\tNAME\tCHANNEL\tUSER\tCREATED\n")
+	for _, f := range r.Largest {
+		var created string
+		if f.Created > 0 {
+			created = time.Unix(f.Created, 0).UTC().Format(time.DateOnly)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", humanize.Size(f.Size), f.ID, f.Name, f.ChannelID, f.UserID, created)
+	}
+	return tw.Flush()
+}
+
+func writeUsage(w io.Writer, title, idTitle string, uu []Usage, total int) {
+	fmt.Fprintf(w, "\n%s", title)
+	if len(uu) < total {
+		fmt.Fprintf(w, " (top %d of %d)", len(uu), total)
+	}
+	fmt.Fprintf(w, "\nSIZE\tFILES\t%s\tNAME\n", idTitle)
+	for _, u := range uu {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", humanize.Size(u.Bytes), u.Files, u.ID, u.Name)
+	}
+}
+
+func limit(uu []Usage, n int) []Usage {
+	if n > 0 && len(uu) > n {
+		return uu[:n]
+	}
+	return uu
+}
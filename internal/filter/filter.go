@@ -0,0 +1,161 @@
+// Package filter drops the unwanted messages, i.e. the bot messages or the
+// channel join notifications, from the stream of the conversations, before
+// they are recorded, so that the export does not need post-processing.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rusq/slack"
+)
+
+// Predicate returns true, if the message m should be dropped.
+type Predicate func(m *slack.Message) bool
+
+// Message subtypes of the membership changes.
+var joinLeaveSubtypes = []string{
+	"channel_join",
+	"channel_leave",
+	"group_join",
+	"group_leave",
+}
+
+// Message subtypes of the system notifications, that are posted by Slack,
+// when the channel or its membership changes.
+var systemSubtypes = append([]string{
+	"channel_topic",
+	"channel_purpose",
+	"channel_name",
+	"channel_archive",
+	"channel_unarchive",
+	"group_topic",
+	"group_purpose",
+	"group_name",
+	"group_archive",
+	"group_unarchive",
+	"pinned_item",
+	"unpinned_item",
+}, joinLeaveSubtypes...)
+
+// Bots returns the predicate, that matches the messages posted by bots and
+// integrations.
+func Bots() Predicate {
+	return func(m *slack.Message) bool {
+		return m.BotID != "" || m.SubType == "bot_message"
+	}
+}
+
+// Subtypes returns the predicate, that matches the messages of any of the
+// subtypes st.
+func Subtypes(st ...string) Predicate {
+	set := make(map[string]struct{}, len(st))
+	for _, s := range st {
+		set[s] = struct{}{}
+	}
+	return func(m *slack.Message) bool {
+		_, ok := set[m.SubType]
+		return ok
+	}
+}
+
+// JoinLeave returns the predicate, that matches the channel join and leave
+// notifications.
+func JoinLeave() Predicate {
+	return Subtypes(joinLeaveSubtypes...)
+}
+
+// System returns the predicate, that matches the system notifications, i.e.
+// join and leave, topic, purpose and name changes, and pins.
+func System() Predicate {
+	return Subtypes(systemSubtypes...)
+}
+
+// Users returns the predicate, that matches the messages posted by any of
+// the users with the given IDs.
+func Users(ids ...string) Predicate {
+	set := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return func(m *slack.Message) bool {
+		_, ok := set[m.User]
+		return ok
+	}
+}
+
+// Text returns the predicate, that matches the messages, the text of which
+// matches the regular expression re.
+func Text(re *regexp.Regexp) Predicate {
+	return func(m *slack.Message) bool {
+		return re.MatchString(m.Text)
+	}
+}
+
+// Any returns the predicate, that matches the message, if any of preds
+// match.
+func Any(preds ...Predicate) Predicate {
+	return func(m *slack.Message) bool {
+		for _, p := range preds {
+			if p(m) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ErrInvalid is returned by [Parse] for the invalid filter specification.
+var ErrInvalid = errors.New("invalid filter")
+
+// Parse parses the filter specification, which is one of:
+//
+//   - "bots": the bot and integration messages;
+//   - "joins": the channel join and leave notifications;
+//   - "system": all system notifications, including joins;
+//   - "subtype:<name>[,<name>...]": the messages of the given subtypes;
+//   - "user:<ID>[,<ID>...]": the messages of the given users;
+//   - "text:<regexp>": the messages, the text of which matches the regular
+//     expression.
+func Parse(spec string) (Predicate, error) {
+	kind, arg, hasArg := strings.Cut(strings.TrimSpace(spec), ":")
+	switch kind {
+	case "bots":
+		return Bots(), nil
+	case "joins":
+		return JoinLeave(), nil
+	case "system":
+		return System(), nil
+	case "subtype", "user":
+		vals := splitList(arg)
+		if len(vals) == 0 {
+			return nil, fmt.Errorf("%w %q: expected %s:<value>[,<value>...]", ErrInvalid, spec, kind)
+		}
+		if kind == "user" {
+			return Users(vals...), nil
+		}
+		return Subtypes(vals...), nil
+	case "text":
+		if !hasArg || arg == "" {
+			return nil, fmt.Errorf("%w %q: expected text:<regexp>", ErrInvalid, spec)
+		}
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %s", ErrInvalid, spec, err)
+		}
+		return Text(re), nil
+	}
+	return nil, fmt.Errorf("%w %q: expected one of bots, joins, system, subtype:, user: or text:", ErrInvalid, spec)
+}
+
+func splitList(s string) []string {
+	var ret []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rusq/slack"
+)
+
+func msg(user, botID, subtype, text string) *slack.Message {
+	return &slack.Message{Msg: slack.Msg{User: user, BotID: botID, SubType: subtype, Text: text}}
+}
+
+func TestParse(t *testing.T) {
+	var (
+		human   = msg("U1", "", "", "hello world")
+		bot     = msg("", "B1", "bot_message", "deploy done")
+		appBot  = msg("U2", "B2", "", "reminder")
+		join    = msg("U3", "", "channel_join", "<@U3> has joined the channel")
+		topic   = msg("U1", "", "channel_topic", "set the channel topic")
+		standup = msg("U4", "", "", "Standup: nothing new")
+	)
+	tests := []struct {
+		spec    string
+		match   []*slack.Message
+		nomatch []*slack.Message
+	}{
+		{"bots", []*slack.Message{bot, appBot}, []*slack.Message{human, join}},
+		{"joins", []*slack.Message{join}, []*slack.Message{human, topic, bot}},
+		{"system", []*slack.Message{join, topic}, []*slack.Message{human, bot}},
+		{"subtype:channel_topic", []*slack.Message{topic}, []*slack.Message{join, human}},
+		{"subtype:channel_topic, channel_join", []*slack.Message{topic, join}, []*slack.Message{human}},
+		{"user:U1,U4", []*slack.Message{human, topic, standup}, []*slack.Message{bot, join}},
+		{"text:(?i)^standup", []*slack.Message{standup}, []*slack.Message{human}},
+		{"text:a:b", nil, []*slack.Message{human}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			p, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			for _, m := range tt.match {
+				if !p(m) {
+					t.Errorf("expected to match %+v", m.Msg)
+				}
+			}
+			for _, m := range tt.nomatch {
+				if p(m) {
+					t.Errorf("expected not to match %+v", m.Msg)
+				}
+			}
+		})
+	}
+}
+
+func TestParse_invalid(t *testing.T) {
+	for _, spec := range []string{"", "robots", "user:", "subtype: , ", "text:", "text:("} {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := Parse(spec); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Parse(%q) error = %v, want %v", spec, err, ErrInvalid)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package filter
+
+import (
+	"context"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/structures"
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+// Processor is the conversation processor middleware, that drops the
+// messages matching the predicate, and their files, before passing the rest
+// to the next processor.  The thread parent messages are never dropped, so
+// that the thread replies have a parent.
+type Processor struct {
+	processor.Passthrough
+	drop Predicate
+}
+
+// New returns the filter processor, that passes the messages, that do not
+// match drop, to next.
+func New(next processor.Conversations, drop Predicate) *Processor {
+	return &Processor{Passthrough: processor.Passthrough{Conversations: next}, drop: drop}
+}
+
+// Middleware returns the middleware, that drops the messages matching any
+// of the predicates preds.  If preds is empty, the middleware passes the
+// processor through unchanged.
+func Middleware(preds ...Predicate) processor.Middleware {
+	return func(next processor.Conversations) processor.Conversations {
+		if len(preds) == 0 {
+			return next
+		}
+		return New(next, Any(preds...))
+	}
+}
+
+// keep returns true, if the message m should be kept.
+func (p *Processor) keep(m *slack.Message) bool {
+	return !p.drop(m) || (m.ThreadTimestamp != "" && structures.IsThreadStart(m))
+}
+
+// filter returns the messages from mm, that should be kept.  The messages
+// are not copied, if all of them are kept.
+func (p *Processor) filter(mm []slack.Message) []slack.Message {
+	for i := range mm {
+		if p.keep(&mm[i]) {
+			continue
+		}
+		ret := append(make([]slack.Message, 0, len(mm)-1), mm[:i]...)
+		for j := i + 1; j < len(mm); j++ {
+			if p.keep(&mm[j]) {
+				ret = append(ret, mm[j])
+			}
+		}
+		return ret
+	}
+	return mm
+}
+
+// Messages passes the messages, that should be kept, to the next processor.
+// It is called even if all messages are dropped, as the next processor
+// tracks the completion of the channel.
+func (p *Processor) Messages(ctx context.Context, channelID string, numThreads int, isLast bool, mm []slack.Message) error {
+	return p.Passthrough.Messages(ctx, channelID, numThreads, isLast, p.filter(mm))
+}
+
+// ThreadMessages passes the thread replies, that should be kept, to the next
+// processor.
+func (p *Processor) ThreadMessages(ctx context.Context, channelID string, parent slack.Message, threadOnly, isLast bool, replies []slack.Message) error {
+	return p.Passthrough.ThreadMessages(ctx, channelID, parent, threadOnly, isLast, p.filter(replies))
+}
+
+// Files passes the files to the next processor, if their message is kept.
+func (p *Processor) Files(ctx context.Context, channel *slack.Channel, parent slack.Message, ff []slack.File) error {
+	if !p.keep(&parent) {
+		return nil
+	}
+	return p.Passthrough.Files(ctx, channel, parent, ff)
+}
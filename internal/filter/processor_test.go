@@ -0,0 +1,77 @@
+package filter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+// sink records the timestamps of the messages and the files it receives.
+type sink struct {
+	processor.Printer
+	messages []string
+	replies  []string
+	files    []string
+	last     int
+}
+
+func (s *sink) Messages(_ context.Context, _ string, _ int, isLast bool, mm []slack.Message) error {
+	for _, m := range mm {
+		s.messages = append(s.messages, m.Timestamp)
+	}
+	if isLast {
+		s.last++
+	}
+	return nil
+}
+
+func (s *sink) ThreadMessages(_ context.Context, _ string, _ slack.Message, _, isLast bool, replies []slack.Message) error {
+	for _, m := range replies {
+		s.replies = append(s.replies, m.Timestamp)
+	}
+	if isLast {
+		s.last++
+	}
+	return nil
+}
+
+func (s *sink) Files(_ context.Context, _ *slack.Channel, parent slack.Message, ff []slack.File) error {
+	for _, f := range ff {
+		s.files = append(s.files, parent.Timestamp+"/"+f.ID)
+	}
+	return nil
+}
+
+func TestProcessor(t *testing.T) {
+	ctx := context.Background()
+	var (
+		human     = slack.Message{Msg: slack.Msg{Timestamp: "1.0", User: "U1"}}
+		bot       = slack.Message{Msg: slack.Msg{Timestamp: "2.0", BotID: "B1"}}
+		botThread = slack.Message{Msg: slack.Msg{Timestamp: "3.0", ThreadTimestamp: "3.0", BotID: "B1", ReplyCount: 2}}
+		botReply  = slack.Message{Msg: slack.Msg{Timestamp: "3.1", ThreadTimestamp: "3.0", BotID: "B1"}}
+		reply     = slack.Message{Msg: slack.Msg{Timestamp: "3.2", ThreadTimestamp: "3.0", User: "U1"}}
+	)
+	s := new(sink)
+	p := processor.Chain(s, Middleware(Bots()))
+
+	require.NoError(t, p.Messages(ctx, "C1", 1, false, []slack.Message{human, bot, botThread}))
+	require.NoError(t, p.Messages(ctx, "C1", 0, true, []slack.Message{bot}))
+	require.NoError(t, p.ThreadMessages(ctx, "C1", botThread, false, true, []slack.Message{botReply, reply}))
+	require.NoError(t, p.Files(ctx, &slack.Channel{}, bot, []slack.File{{ID: "F1"}}))
+	require.NoError(t, p.Files(ctx, &slack.Channel{}, human, []slack.File{{ID: "F2"}}))
+
+	assert.Equal(t, []string{"1.0", "3.0"}, s.messages, "the thread parent must be kept")
+	assert.Equal(t, []string{"3.2"}, s.replies)
+	assert.Equal(t, []string{"1.0/F2"}, s.files)
+	assert.Equal(t, 2, s.last, "the calls with all messages dropped must be passed on")
+}
+
+func TestMiddleware_empty(t *testing.T) {
+	s := new(sink)
+	assert.Same(t, s, processor.Chain(s, Middleware()))
+}
@@ -0,0 +1,187 @@
+package format
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rusq/slack"
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+// Column is the column of the channel or user listing.
+type Column[T any] struct {
+	// Name is the name of the column, as given in the column selection.
+	Name string
+	// Header is the column header.
+	Header string
+	// Value returns the value of the column for v, ui is used to resolve the
+	// user IDs.
+	Value func(v *T, ui structures.UserIndex) string
+}
+
+// Columns is the ordered list of columns.
+type Columns[T any] []Column[T]
+
+// Names returns the names of the columns.
+func (cc Columns[T]) Names() []string {
+	names := make([]string, len(cc))
+	for i := range cc {
+		names[i] = cc[i].Name
+	}
+	return names
+}
+
+// Select returns the columns with the given names, in the order of names.
+// It returns an error, if any of the names is unknown.
+func (cc Columns[T]) Select(names ...string) (Columns[T], error) {
+	ret := make(Columns[T], 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		i := cc.index(name)
+		if i < 0 {
+			return nil, fmt.Errorf("unknown column %q, should be one of: %s", name, strings.Join(cc.Names(), ", "))
+		}
+		ret = append(ret, cc[i])
+	}
+	return ret, nil
+}
+
+func (cc Columns[T]) index(name string) int {
+	for i := range cc {
+		if cc[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Headers returns the column headers.
+func (cc Columns[T]) Headers() []string {
+	hdr := make([]string, len(cc))
+	for i := range cc {
+		hdr[i] = cc[i].Header
+	}
+	return hdr
+}
+
+// Row returns the values of the columns for v.
+func (cc Columns[T]) Row(v *T, ui structures.UserIndex) []string {
+	row := make([]string, len(cc))
+	for i := range cc {
+		row[i] = cc[i].Value(v, ui)
+	}
+	return row
+}
+
+// listTimeFmt is the format of the times in the listings.
+const listTimeFmt = "2006-01-02 15:04:05"
+
+// ChannelColumns are the columns available in the channel listings.
+var ChannelColumns = Columns[slack.Channel]{
+	{"id", "ID", func(ch *slack.Channel, _ structures.UserIndex) string { return ch.ID }},
+	{"name", "Name", func(ch *slack.Channel, ui structures.UserIndex) string { return NVL(ch.Name, ui.DisplayName(ch.User)) }},
+	{"type", "Type", func(ch *slack.Channel, _ structures.UserIndex) string { return channelType(ch) }},
+	{"members", "Members", func(ch *slack.Channel, _ structures.UserIndex) string { return strconv.Itoa(ch.NumMembers) }},
+	{"created", "Created", func(ch *slack.Channel, _ structures.UserIndex) string { return _ft(int64(ch.Created)) }},
+	{"archived", "Archived", func(ch *slack.Channel, _ structures.UserIndex) string { return _fb(ch.IsArchived) }},
+	{"shared", "Shared", func(ch *slack.Channel, _ structures.UserIndex) string { return _fb(structures.IsExtShared(ch)) }},
+	{"topic", "Topic", func(ch *slack.Channel, _ structures.UserIndex) string { return ch.Topic.Value }},
+	{"purpose", "Purpose", func(ch *slack.Channel, _ structures.UserIndex) string { return ch.Purpose.Value }},
+}
+
+// UserColumns are the columns available in the user listings.
+var UserColumns = Columns[slack.User]{
+	{"id", "ID", func(u *slack.User, _ structures.UserIndex) string { return u.ID }},
+	{"name", "Name", func(u *slack.User, _ structures.UserIndex) string { return u.Name }},
+	{"real_name", "Real Name", func(u *slack.User, _ structures.UserIndex) string { return u.RealName }},
+	{"display_name", "Display Name", func(u *slack.User, _ structures.UserIndex) string { return u.Profile.DisplayName }},
+	{"email", "Email", func(u *slack.User, _ structures.UserIndex) string { return u.Profile.Email }},
+	{"title", "Title", func(u *slack.User, _ structures.UserIndex) string { return u.Profile.Title }},
+	{"bot", "Bot", func(u *slack.User, _ structures.UserIndex) string { return _fb(u.IsBot) }},
+	{"admin", "Admin", func(u *slack.User, _ structures.UserIndex) string { return _fb(u.IsAdmin) }},
+	{"deleted", "Deleted", func(u *slack.User, _ structures.UserIndex) string { return _fb(u.Deleted) }},
+	{"restricted", "Restricted", func(u *slack.User, _ structures.UserIndex) string { return _fb(u.IsRestricted) }},
+	{"tz", "Timezone", func(u *slack.User, _ structures.UserIndex) string { return u.TZ }},
+	{"updated", "Updated", func(u *slack.User, _ structures.UserIndex) string { return u.Updated.Time().Format(listTimeFmt) }},
+}
+
+// channelType returns the type of the channel: im, mpim, private or public.
+func channelType(ch *slack.Channel) string {
+	switch structures.ChannelType(*ch) {
+	case structures.CIM:
+		return "im"
+	case structures.CMPIM:
+		return "mpim"
+	case structures.CPrivate:
+		return "private"
+	default:
+		return "public"
+	}
+}
+
+// WithColumns sets the columns of the channel and user listings, the names
+// are the names of [ChannelColumns] or [UserColumns].  It has effect on the
+// text, CSV and Markdown converters.  If not set, the converter default
+// columns are used.
+func WithColumns(names ...string) Option {
+	return func(o *options) {
+		o.columns = names
+	}
+}
+
+// selectColumns returns the columns of cc selected with [WithColumns], or
+// the columns named def, if none were selected.
+func selectColumns[T any](cc Columns[T], names []string, def ...string) (Columns[T], error) {
+	if len(names) == 0 {
+		names = def
+	}
+	return cc.Select(names...)
+}
+
+// cell returns s with the tabs and newlines replaced with spaces, so that
+// the value fits in the table cell.
+func cell(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// textTable writes the values vv as the table with the columns cc, aligned
+// with spaces.
+func textTable[T any](w io.Writer, cc Columns[T], vv []T, ui structures.UserIndex) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, strings.Join(cc.Headers(), "\t")); err != nil {
+		return fmt.Errorf("writer error: %w", err)
+	}
+	for i := range vv {
+		row := cc.Row(&vv[i], ui)
+		for j := range row {
+			row[j] = cell(row[j])
+		}
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return fmt.Errorf("writer error: %w", err)
+		}
+	}
+	return tw.Flush()
+}
+
+// csvTable writes the values vv as the CSV records with the columns cc,
+// after the header.
+func csvTable[T any](w *csv.Writer, cc Columns[T], vv []T, ui structures.UserIndex) error {
+	if err := w.Write(cc.Headers()); err != nil {
+		return err
+	}
+	for i := range vv {
+		if err := w.Write(cc.Row(&vv[i], ui)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,13 +1,18 @@
 package format
 
 import (
+	"bytes"
 	"context"
 	"encoding/csv"
 	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/rusq/slack"
+	"github.com/rusq/slackdump/v3/internal/emojitext"
+	"github.com/rusq/slackdump/v3/internal/langdetect"
+	"github.com/rusq/slackdump/v3/internal/structures"
 	"github.com/rusq/slackdump/v3/types"
 )
 
@@ -25,19 +30,25 @@ func init() {
 }
 
 func NewCSV(opts ...Option) Formatter {
+	return &CSV{csvSettings(opts)}
+}
+
+// csvSettings returns the CSV options with the defaults applied.
+func csvSettings(opts []Option) options {
 	settings := options{
 		csvOptions: csvOptions{
 			UseCRLF: false,
 			Comma:   ',',
 		},
+		emoji: emojitext.Standard,
 	}
 	for _, fn := range opts {
 		fn(&settings)
 	}
-	return &CSV{settings}
+	return settings
 }
 
-// timestamp, channel, username, text
+// timestamp[, time], channel, username, text[, language]
 
 func (c *CSV) Conversation(ctx context.Context, w io.Writer, u []slack.User, conv *types.Conversation) error {
 	csv := c.mkwriter(w)
@@ -47,7 +58,15 @@ func (c *CSV) Conversation(ctx context.Context, w io.Writer, u []slack.User, con
 	repl := userReplacer(ui)
 
 	for _, m := range conv.Messages {
-		if err := csv.Write([]string{m.Timestamp, conv.Name, ui.DisplayName(m.User), repl.Replace(m.Text)}); err != nil {
+		rec := []string{m.Timestamp}
+		if c.opts.rfc3339 {
+			rec = append(rec, structures.SlackTSToRFC3339(m.Timestamp))
+		}
+		rec = append(rec, conv.Name, ui.Sender(&m.Message), c.opts.emojiText(repl.Replace(m.Text)))
+		if c.opts.detectLang {
+			rec = append(rec, langdetect.Detect(m.Text))
+		}
+		if err := csv.Write(rec); err != nil {
 			return err
 		}
 	}
@@ -67,6 +86,14 @@ func (c *CSV) Channels(ctx context.Context, w io.Writer, u []slack.User, chans [
 	csv := c.mkwriter(w)
 	defer csv.Flush()
 
+	if len(c.opts.columns) > 0 {
+		cc, err := ChannelColumns.Select(c.opts.columns...)
+		if err != nil {
+			return err
+		}
+		return csvTable(csv, cc, chans, types.Users(u).IndexByID())
+	}
+
 	if err := csv.Write([]string{
 		"ID",
 		"Name",
@@ -76,6 +103,7 @@ func (c *CSV) Channels(ctx context.Context, w io.Writer, u []slack.User, chans [
 		"Is MPIM?",
 		"Is Private?",
 		"Is IM?",
+		"Is Shared?",
 		"Purpose",
 	}); err != nil {
 		return err
@@ -93,6 +121,7 @@ func (c *CSV) Channels(ctx context.Context, w io.Writer, u []slack.User, chans [
 			_fb(u.IsMpIM),
 			_fb(u.IsPrivate),
 			_fb(u.IsIM),
+			_fb(structures.IsExtShared(&u)),
 			u.Purpose.Value,
 		}); err != nil {
 			return err
@@ -117,6 +146,14 @@ func (c *CSV) Users(ctx context.Context, w io.Writer, users []slack.User) error
 	csv := c.mkwriter(w)
 	defer csv.Flush()
 
+	if len(c.opts.columns) > 0 {
+		cc, err := UserColumns.Select(c.opts.columns...)
+		if err != nil {
+			return err
+		}
+		return csvTable(csv, cc, users, nil)
+	}
+
 	if err := csv.Write([]string{"ID",
 		"Team ID",
 		"Name",
@@ -155,8 +192,108 @@ func (c *CSV) Users(ctx context.Context, w io.Writer, users []slack.User) error
 }
 
 func (c *CSV) mkwriter(w io.Writer) *csv.Writer {
+	return c.opts.mkwriter(w)
+}
+
+func (o *csvOptions) mkwriter(w io.Writer) *csv.Writer {
 	csv := csv.NewWriter(w)
-	csv.Comma = c.opts.Comma
-	csv.UseCRLF = c.opts.UseCRLF
+	csv.Comma = o.Comma
+	csv.UseCRLF = o.UseCRLF
 	return csv
 }
+
+// messageRowHeader is the header of the message rows, and
+// messageRowHeaderRFC3339 is the header with the RFC 3339 times.
+var (
+	messageRowHeader        = []string{"ts", "channel", "user", "thread_ts", "text", "reaction_count", "file_count"}
+	messageRowHeaderRFC3339 = []string{"ts", "ts_rfc3339", "channel", "user", "thread_ts", "thread_ts_rfc3339", "text", "reaction_count", "file_count"}
+)
+
+// MessageRows writes the messages as flat CSV rows, one message per row, to
+// be loaded into spreadsheets and BI tools.  User IDs are replaced with the
+// user names, both in the user column and in the message text.  The header
+// is written before the first row.  It is not safe for concurrent use.
+type MessageRows struct {
+	w      io.Writer
+	opts   options
+	ui     structures.UserIndex
+	repl   *strings.Replacer
+	header bool // header is written
+	buf    bytes.Buffer
+}
+
+// NewMessageRows returns the message rows writer, that writes to w, users
+// are used to resolve the user IDs.
+func NewMessageRows(w io.Writer, users []slack.User, opts ...Option) *MessageRows {
+	ui := types.Users(users).IndexByID()
+	return &MessageRows{
+		w:    w,
+		opts: csvSettings(opts),
+		ui:   ui,
+		repl: userReplacer(ui),
+	}
+}
+
+// Write writes the messages of the channel as rows.  The rows are written to
+// the underlying writer in one call.
+func (r *MessageRows) Write(channel string, mm ...slack.Message) error {
+	r.buf.Reset()
+	cw := r.opts.mkwriter(&r.buf)
+	if !r.header {
+		hdr := messageRowHeader
+		if r.opts.rfc3339 {
+			hdr = messageRowHeaderRFC3339
+		}
+		if err := cw.Write(hdr); err != nil {
+			return err
+		}
+	}
+	for i := range mm {
+		if err := cw.Write(r.row(channel, &mm[i])); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	if r.buf.Len() == 0 {
+		return nil
+	}
+	if _, err := r.w.Write(r.buf.Bytes()); err != nil {
+		return err
+	}
+	r.header = true
+	return nil
+}
+
+// row returns the row of the message m.
+func (r *MessageRows) row(channel string, m *slack.Message) []string {
+	var reactions int
+	for _, rc := range m.Reactions {
+		reactions += rc.Count
+	}
+	text := r.opts.emojiText(r.repl.Replace(m.Text))
+	if r.opts.rfc3339 {
+		return []string{
+			m.Timestamp,
+			structures.SlackTSToRFC3339(m.Timestamp),
+			channel,
+			r.ui.Sender(m),
+			m.ThreadTimestamp,
+			structures.SlackTSToRFC3339(m.ThreadTimestamp),
+			text,
+			strconv.Itoa(reactions),
+			strconv.Itoa(len(m.Files)),
+		}
+	}
+	return []string{
+		m.Timestamp,
+		channel,
+		r.ui.Sender(m),
+		m.ThreadTimestamp,
+		text,
+		strconv.Itoa(reactions),
+		strconv.Itoa(len(m.Files)),
+	}
+}
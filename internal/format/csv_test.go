@@ -0,0 +1,45 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageRows_Write(t *testing.T) {
+	users := []slack.User{{ID: "U1", Name: "bob"}, {ID: "U2", Name: "alice"}}
+	parent := slack.Message{Msg: slack.Msg{
+		User: "U1", Timestamp: "1.0", ThreadTimestamp: "1.0", Text: "hi <@U2>, see \"this\"",
+		Reactions: []slack.ItemReaction{{Name: "+1", Count: 2}, {Name: "eyes", Count: 1}},
+		Files:     []slack.File{{ID: "F1"}},
+	}}
+	reply := slack.Message{Msg: slack.Msg{User: "U2", Timestamp: "1.1", ThreadTimestamp: "1.0", Text: "ok"}}
+
+	var buf bytes.Buffer
+	rows := NewMessageRows(&buf, users)
+	require.NoError(t, rows.Write("general", parent))
+	require.NoError(t, rows.Write("general", reply))
+	require.NoError(t, rows.Write("general"))
+	want := `ts,channel,user,thread_ts,text,reaction_count,file_count
+1.0,general,bob,1.0,"hi <@alice>, see ""this""",3,1
+1.1,general,alice,1.0,ok,0,0
+`
+	assert.Equal(t, want, buf.String())
+}
+
+func TestMessageRows_Write_rfc3339(t *testing.T) {
+	reply := slack.Message{Msg: slack.Msg{User: "U1", Timestamp: "1700000001.000200", ThreadTimestamp: "1700000000.000100", Text: "ok"}}
+	msg := slack.Message{Msg: slack.Msg{User: "U1", Timestamp: "1700000002.000000", Text: "hi"}}
+
+	var buf bytes.Buffer
+	rows := NewMessageRows(&buf, []slack.User{{ID: "U1", Name: "bob"}}, WithRFC3339(true))
+	require.NoError(t, rows.Write("general", reply, msg))
+	want := `ts,ts_rfc3339,channel,user,thread_ts,thread_ts_rfc3339,text,reaction_count,file_count
+1700000001.000200,2023-11-14T22:13:21.000200Z,general,bob,1700000000.000100,2023-11-14T22:13:20.000100Z,ok,0,0
+1700000002.000000,2023-11-14T22:13:22.000000Z,general,bob,,,hi,0,0
+`
+	assert.Equal(t, want, buf.String())
+}
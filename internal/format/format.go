@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/rusq/slack"
+	"github.com/rusq/slackdump/v3/internal/emojitext"
 	"github.com/rusq/slackdump/v3/internal/structures"
 	"github.com/rusq/slackdump/v3/types"
 )
@@ -20,16 +21,28 @@ import (
 type Type int
 
 const (
-	CUnknown Type = iota // Unknown converter type
-	CText                // CText is the plain text converter
-	CCSV                 // CCSV is the CSV converter
-	CJSON                // CJSON is JSON format converter
+	CUnknown  Type = iota // Unknown converter type
+	CText                 // CText is the plain text converter
+	CCSV                  // CCSV is the CSV converter
+	CJSON                 // CJSON is JSON format converter
+	CSQLite               // CSQLite is the SQLite database converter
+	CNDJSON               // CNDJSON is the newline delimited JSON converter
+	CMarkdown             // CMarkdown is the Markdown converter
 )
 
 var Descriptions = map[Type]string{
-	CText: "Plain text format",
-	CCSV:  "CSV format",
-	CJSON: "JSON format",
+	CText:     "Plain text format",
+	CCSV:      "CSV format",
+	CJSON:     "JSON format",
+	CSQLite:   "SQLite database",
+	CNDJSON:   "Newline delimited JSON, one message per line",
+	CMarkdown: "Markdown, listings as tables",
+}
+
+// aliases are the alternative names of the converter types.
+var aliases = map[string]Type{
+	"table": CText,
+	"md":    CMarkdown,
 }
 
 // Types is a list of converter types.
@@ -65,15 +78,69 @@ type options struct {
 	textOptions
 	csvOptions
 	jsonOptions
+	// detectLang enables the language detection of the messages, supported
+	// by CSV and NDJSON converters.
+	detectLang bool
+	// emoji converts the emoji shortcodes in the message text, supported by
+	// the text and CSV converters.  nil disables the conversion.
+	emoji *emojitext.Converter
+	// rfc3339 adds the RFC 3339 times next to the slack timestamps, supported
+	// by CSV and NDJSON converters.
+	rfc3339 bool
+	// columns are the names of the columns of the channel and user
+	// listings, supported by the text, CSV and Markdown converters.
+	columns []string
 }
 
 // Option is the converter option.
 type Option func(*options)
 
+// WithLanguage enables tagging of each message with the ISO 639-1 code of
+// its language.  The language is detected from the message text, and is
+// empty, if it can't be determined.  It has effect on the CSV and NDJSON
+// converters.
+func WithLanguage(enabled bool) Option {
+	return func(o *options) {
+		o.detectLang = enabled
+	}
+}
+
+// WithRFC3339 enables the RFC 3339 time in UTC next to each slack timestamp
+// of the message, so that the consumers don't need to convert them.  It has
+// effect on the CSV and NDJSON converters, and the [MessageRows].
+func WithRFC3339(enabled bool) Option {
+	return func(o *options) {
+		o.rfc3339 = enabled
+	}
+}
+
+// WithEmoji sets the converter of the emoji shortcodes in the message text,
+// i.e. to use the custom emojis of the workspace.  nil disables the
+// conversion.  It has effect on the text and CSV converters, which convert
+// the standard emojis by default.
+func WithEmoji(c *emojitext.Converter) Option {
+	return func(o *options) {
+		o.emoji = c
+	}
+}
+
+// emojiText converts the emoji shortcodes in s, if the conversion is
+// enabled.
+func (o *options) emojiText(s string) string {
+	if o.emoji == nil {
+		return s
+	}
+	return o.emoji.Text(s)
+}
+
 var Converters = make(map[Type]func(opts ...Option) Formatter)
 
 func (e *Type) Set(v string) error {
 	v = strings.ToLower(v)
+	if t, ok := aliases[v]; ok {
+		*e = t
+		return nil
+	}
 	for i := 0; i < len(_Type_index)-1; i++ {
 		if strings.ToLower(_Type_name[_Type_index[i]:_Type_index[i+1]]) == v {
 			*e = Type(i)
@@ -0,0 +1,127 @@
+package format
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/rusq/slack"
+	"github.com/rusq/slackdump/v3/internal/emojitext"
+	"github.com/rusq/slackdump/v3/internal/structures"
+	"github.com/rusq/slackdump/v3/types"
+)
+
+var _ Formatter = &Markdown{}
+
+// Markdown is the Markdown formatter.  The channels and users are written
+// as tables, and the conversations as the list of messages, with the thread
+// replies quoted.
+type Markdown struct {
+	opts options
+}
+
+// Default columns of the Markdown listings.
+var (
+	mdChannelColumns = []string{"id", "name", "type", "members", "created", "archived", "shared", "purpose"}
+	mdUserColumns    = []string{"id", "name", "real_name", "email", "bot", "deleted"}
+)
+
+func init() {
+	Converters[CMarkdown] = NewMarkdown
+}
+
+func NewMarkdown(opts ...Option) Formatter {
+	settings := options{
+		emoji: emojitext.Standard,
+	}
+	for _, fn := range opts {
+		fn(&settings)
+	}
+	return &Markdown{opts: settings}
+}
+
+func (md *Markdown) Conversation(ctx context.Context, w io.Writer, u []slack.User, conv *types.Conversation) error {
+	buf := bufio.NewWriter(w)
+	defer buf.Flush()
+
+	ui := structures.NewUserIndex(u)
+	if _, err := fmt.Fprintf(buf, "# %s\n", NVL(conv.Name, conv.ID)); err != nil {
+		return fmt.Errorf("writer error: %w", err)
+	}
+	return md.messages(buf, conv.Messages, "", ui, userReplacer(ui))
+}
+
+// messages writes the messages mm, each line is prefixed with prefix.
+func (md *Markdown) messages(w io.Writer, mm []types.Message, prefix string, ui structures.UserIndex, repl *strings.Replacer) error {
+	for i := range mm {
+		m := &mm[i]
+		t, err := structures.ParseSlackTS(m.Timestamp)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s\n%s**%s** _%s_\n%s\n", prefix, prefix, ui.Sender(&m.Message), t.Format(textTimeFmt), prefix); err != nil {
+			return fmt.Errorf("writer error: %w", err)
+		}
+		text := md.opts.emojiText(html.UnescapeString(repl.Replace(m.Text)))
+		for _, line := range strings.Split(text, "\n") {
+			if _, err := fmt.Fprintf(w, "%s%s\n", prefix, line); err != nil {
+				return fmt.Errorf("writer error: %w", err)
+			}
+		}
+		if len(m.ThreadReplies) > 0 {
+			if err := md.messages(w, m.ThreadReplies, prefix+"> ", ui, repl); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (md *Markdown) Channels(ctx context.Context, w io.Writer, u []slack.User, chans []slack.Channel) error {
+	cc, err := selectColumns(ChannelColumns, md.opts.columns, mdChannelColumns...)
+	if err != nil {
+		return err
+	}
+	return mdTable(w, cc, chans, structures.NewUserIndex(u))
+}
+
+func (md *Markdown) Users(ctx context.Context, w io.Writer, u []slack.User) error {
+	cc, err := selectColumns(UserColumns, md.opts.columns, mdUserColumns...)
+	if err != nil {
+		return err
+	}
+	return mdTable(w, cc, u, nil)
+}
+
+// mdTable writes the values vv as the Markdown table with the columns cc.
+func mdTable[T any](w io.Writer, cc Columns[T], vv []T, ui structures.UserIndex) error {
+	buf := bufio.NewWriter(w)
+	hdr := cc.Headers()
+	sep := make([]string, len(hdr))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	mdRow(buf, hdr)
+	mdRow(buf, sep)
+	for i := range vv {
+		mdRow(buf, cc.Row(&vv[i], ui))
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("writer error: %w", err)
+	}
+	return nil
+}
+
+// mdCell escapes the pipe characters in the cell value.
+var mdCell = strings.NewReplacer("|", `\|`)
+
+func mdRow(w *bufio.Writer, row []string) {
+	w.WriteString("|")
+	for _, s := range row {
+		w.WriteString(" " + mdCell.Replace(cell(s)) + " |")
+	}
+	w.WriteString("\n")
+}
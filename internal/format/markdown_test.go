@@ -0,0 +1,65 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testListChannels = []slack.Channel{
+	{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1", NumMembers: 10}, Purpose: slack.Purpose{Value: "a | b"}}},
+	{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "D1", IsIM: true, User: "U1"}}},
+}
+
+func TestMarkdown_Channels(t *testing.T) {
+	var buf bytes.Buffer
+	md := NewMarkdown(WithColumns("name", "type", "members", "purpose"))
+	require.NoError(t, md.Channels(context.Background(), &buf, []slack.User{{ID: "U1", Name: "bob"}}, testListChannels))
+	want := `| Name | Type | Members | Purpose |
+| --- | --- | --- | --- |
+| general | public | 10 | a \| b |
+| bob | im | 0 |  |
+`
+	assert.Equal(t, want, buf.String())
+}
+
+func TestMarkdown_Users(t *testing.T) {
+	var buf bytes.Buffer
+	md := NewMarkdown()
+	require.NoError(t, md.Users(context.Background(), &buf, []slack.User{{ID: "U1", Name: "bob", RealName: "Bob", IsBot: true}}))
+	want := `| ID | Name | Real Name | Email | Bot | Deleted |
+| --- | --- | --- | --- | --- | --- |
+| U1 | bob | Bob |  | true | false |
+`
+	assert.Equal(t, want, buf.String())
+}
+
+func TestWithColumns(t *testing.T) {
+	users := []slack.User{{ID: "U1", Name: "bob", Profile: slack.UserProfile{Email: "bob@example.com"}}}
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, NewCSV(WithColumns("email", "id")).Users(context.Background(), &buf, users))
+		assert.Equal(t, "Email,ID\nbob@example.com,U1\n", buf.String())
+	})
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, NewText(WithColumns("id", "name")).Channels(context.Background(), &buf, nil, testListChannels[:1]))
+		assert.Equal(t, "ID  Name\nC1  general\n", buf.String())
+	})
+	t.Run("unknown column", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Error(t, NewCSV(WithColumns("who")).Users(context.Background(), &buf, users))
+	})
+}
+
+func TestType_Set(t *testing.T) {
+	for v, want := range map[string]Type{"markdown": CMarkdown, "md": CMarkdown, "table": CText, "CSV": CCSV} {
+		var typ Type
+		require.NoError(t, typ.Set(v))
+		assert.Equal(t, want, typ, v)
+	}
+}
@@ -0,0 +1,115 @@
+package format
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/rusq/slack"
+	"github.com/rusq/slackdump/v3/internal/langdetect"
+	"github.com/rusq/slackdump/v3/internal/structures"
+	"github.com/rusq/slackdump/v3/types"
+)
+
+var _ Formatter = &NDJSON{}
+
+// NDJSON is the newline delimited JSON formatter.  Each message, including
+// the thread replies, channel or user is written as a separate JSON object on
+// its own line, which is convenient for the analytics tools.
+type NDJSON struct {
+	opts options
+}
+
+func init() {
+	Converters[CNDJSON] = NewNDJSON
+}
+
+func NewNDJSON(opts ...Option) Formatter {
+	var settings options
+	for _, fn := range opts {
+		fn(&settings)
+	}
+	return &NDJSON{opts: settings}
+}
+
+// ndjsonMessage is the flattened message record.
+type ndjsonMessage struct {
+	ChannelID string `json:"channel_id"`
+	Channel   string `json:"channel"`
+	TS        string `json:"ts"`
+	ThreadTS  string `json:"thread_ts,omitempty"`
+	// TSRFC3339 and ThreadTSRFC3339 are set with the WithRFC3339 option.
+	TSRFC3339       string `json:"ts_rfc3339,omitempty"`
+	ThreadTSRFC3339 string `json:"thread_ts_rfc3339,omitempty"`
+	UserID          string `json:"user_id,omitempty"`
+	User            string `json:"user,omitempty"`
+	BotID           string `json:"bot_id,omitempty"`
+	AppID           string `json:"app_id,omitempty"`
+	App             string `json:"app,omitempty"`
+	Text            string `json:"text"`
+	Lang            string `json:"lang,omitempty"`
+}
+
+func (n *NDJSON) Conversation(ctx context.Context, w io.Writer, u []slack.User, conv *types.Conversation) error {
+	enc := json.NewEncoder(w)
+	ui := types.Users(u).IndexByID()
+	repl := userReplacer(ui)
+
+	var write func(mm []types.Message) error
+	write = func(mm []types.Message) error {
+		for _, m := range mm {
+			rec := ndjsonMessage{
+				ChannelID: conv.ID,
+				Channel:   conv.Name,
+				TS:        m.Timestamp,
+				ThreadTS:  m.ThreadTimestamp,
+				UserID:    m.User,
+				Text:      repl.Replace(m.Text),
+			}
+			if n.opts.rfc3339 {
+				rec.TSRFC3339 = structures.SlackTSToRFC3339(m.Timestamp)
+				rec.ThreadTSRFC3339 = structures.SlackTSToRFC3339(m.ThreadTimestamp)
+			}
+			if m.User != "" {
+				rec.User = ui.DisplayName(m.User)
+			}
+			if m.BotID != "" {
+				rec.BotID = m.BotID
+				if m.BotProfile != nil {
+					rec.AppID = m.BotProfile.AppID
+					rec.App = m.BotProfile.Name
+				}
+			}
+			if n.opts.detectLang {
+				rec.Lang = langdetect.Detect(m.Text)
+			}
+			if err := enc.Encode(rec); err != nil {
+				return err
+			}
+			if err := write(m.ThreadReplies); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return write(conv.Messages)
+}
+
+func (n *NDJSON) Channels(ctx context.Context, w io.Writer, u []slack.User, chans []slack.Channel) error {
+	return encodeLines(w, chans)
+}
+
+func (n *NDJSON) Users(ctx context.Context, w io.Writer, u []slack.User) error {
+	return encodeLines(w, u)
+}
+
+// encodeLines writes each element of vv as a JSON object on its own line.
+func encodeLines[T any](w io.Writer, vv []T) error {
+	enc := json.NewEncoder(w)
+	for _, v := range vv {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
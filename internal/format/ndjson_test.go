@@ -0,0 +1,91 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/types"
+)
+
+func TestNDJSON_Conversation(t *testing.T) {
+	msg := func(ts, threadTS, text string) types.Message {
+		return types.Message{Message: slack.Message{Msg: slack.Msg{User: "U1", Timestamp: ts, ThreadTimestamp: threadTS, Text: text}}}
+	}
+	parent := msg("1.0", "1.0", "the build is broken, can you have a look at the logs?")
+	parent.ThreadReplies = []types.Message{msg("1.1", "1.0", "Кажется, сборка снова сломалась")}
+	conv := &types.Conversation{ID: "C1", Name: "general", Messages: []types.Message{parent, msg("2.0", "", "ok")}}
+	users := []slack.User{{ID: "U1", Name: "bob"}}
+
+	t.Run("with language", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := NewNDJSON(WithLanguage(true)).Conversation(context.Background(), &buf, users, conv)
+		require.NoError(t, err)
+		want := `{"channel_id":"C1","channel":"general","ts":"1.0","thread_ts":"1.0","user_id":"U1","user":"bob","text":"the build is broken, can you have a look at the logs?","lang":"en"}
+{"channel_id":"C1","channel":"general","ts":"1.1","thread_ts":"1.0","user_id":"U1","user":"bob","text":"Кажется, сборка снова сломалась","lang":"ru"}
+{"channel_id":"C1","channel":"general","ts":"2.0","user_id":"U1","user":"bob","text":"ok"}
+`
+		assert.Equal(t, want, buf.String())
+	})
+	t.Run("without language", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := NewNDJSON().Conversation(context.Background(), &buf, users, conv)
+		require.NoError(t, err)
+		assert.NotContains(t, buf.String(), `"lang"`)
+	})
+}
+
+func TestNDJSON_Conversation_bot(t *testing.T) {
+	conv := &types.Conversation{ID: "C1", Name: "general", Messages: []types.Message{
+		{Message: slack.Message{Msg: slack.Msg{BotID: "B1", Timestamp: "1.0", Text: "deployed", BotProfile: &slack.BotProfile{ID: "B1", AppID: "A1", Name: "deploybot"}}}},
+		{Message: slack.Message{Msg: slack.Msg{BotID: "B2", Username: "legacy", Timestamp: "2.0", Text: "hi"}}},
+	}}
+	var buf bytes.Buffer
+	err := NewNDJSON().Conversation(context.Background(), &buf, nil, conv)
+	require.NoError(t, err)
+	want := `{"channel_id":"C1","channel":"general","ts":"1.0","bot_id":"B1","app_id":"A1","app":"deploybot","text":"deployed"}
+{"channel_id":"C1","channel":"general","ts":"2.0","bot_id":"B2","text":"hi"}
+`
+	assert.Equal(t, want, buf.String())
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := NewCSV().Conversation(context.Background(), &buf, nil, conv)
+		require.NoError(t, err)
+		assert.Equal(t, "1.0,general,deploybot,deployed\n2.0,general,legacy,hi\n", buf.String())
+	})
+}
+
+func TestCSV_Conversation_language(t *testing.T) {
+	conv := &types.Conversation{ID: "C1", Name: "general", Messages: []types.Message{
+		{Message: slack.Message{Msg: slack.Msg{User: "U1", Timestamp: "1.0", Text: "Ich glaube, der Build ist wieder kaputt"}}},
+	}}
+	var buf bytes.Buffer
+	err := NewCSV(WithLanguage(true)).Conversation(context.Background(), &buf, []slack.User{{ID: "U1", Name: "bob"}}, conv)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0,general,bob,\"Ich glaube, der Build ist wieder kaputt\",de\n", buf.String())
+}
+
+func TestRFC3339(t *testing.T) {
+	conv := &types.Conversation{ID: "C1", Name: "general", Messages: []types.Message{
+		{Message: slack.Message{Msg: slack.Msg{User: "U1", Timestamp: "1700000001.000200", ThreadTimestamp: "1700000000.000100", Text: "ok"}}},
+	}}
+	users := []slack.User{{ID: "U1", Name: "bob"}}
+	t.Run("ndjson", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := NewNDJSON(WithRFC3339(true)).Conversation(context.Background(), &buf, users, conv)
+		require.NoError(t, err)
+		want := `{"channel_id":"C1","channel":"general","ts":"1700000001.000200","thread_ts":"1700000000.000100","ts_rfc3339":"2023-11-14T22:13:21.000200Z","thread_ts_rfc3339":"2023-11-14T22:13:20.000100Z","user_id":"U1","user":"bob","text":"ok"}` + "\n"
+		assert.Equal(t, want, buf.String())
+	})
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := NewCSV(WithRFC3339(true)).Conversation(context.Background(), &buf, users, conv)
+		require.NoError(t, err)
+		assert.Equal(t, "1700000001.000200,2023-11-14T22:13:21.000200Z,general,bob,ok\n", buf.String())
+	})
+}
@@ -0,0 +1,86 @@
+package format
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/sqlite"
+	"github.com/rusq/slackdump/v3/types"
+)
+
+// SQLite is the SQLite database formatter.  The output is the binary SQLite
+// database file.
+type SQLite struct{}
+
+func init() {
+	Converters[CSQLite] = NewSQLite
+}
+
+func NewSQLite(...Option) Formatter {
+	return &SQLite{}
+}
+
+func (SQLite) Conversation(ctx context.Context, w io.Writer, u []slack.User, conv *types.Conversation) error {
+	return writeDB(ctx, w, func(db *sqlite.DB) error {
+		if err := db.InsertUsers(ctx, u); err != nil {
+			return err
+		}
+		mm := make([]slack.Message, 0, len(conv.Messages))
+		for _, m := range conv.Messages {
+			mm = append(mm, m.Message)
+			for _, r := range m.ThreadReplies {
+				mm = append(mm, r.Message)
+			}
+		}
+		return db.InsertMessages(ctx, conv.ID, mm)
+	})
+}
+
+func (SQLite) Channels(ctx context.Context, w io.Writer, u []slack.User, chans []slack.Channel) error {
+	return writeDB(ctx, w, func(db *sqlite.DB) error {
+		if err := db.InsertUsers(ctx, u); err != nil {
+			return err
+		}
+		return db.InsertChannels(ctx, chans)
+	})
+}
+
+func (SQLite) Users(ctx context.Context, w io.Writer, u []slack.User) error {
+	return writeDB(ctx, w, func(db *sqlite.DB) error {
+		return db.InsertUsers(ctx, u)
+	})
+}
+
+// writeDB creates the temporary database, calls fn to populate it, and
+// copies the database file to w.
+func writeDB(ctx context.Context, w io.Writer, fn func(db *sqlite.DB) error) error {
+	f, err := os.CreateTemp("", "slackdump-*.db")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	db, err := sqlite.Open(ctx, f.Name())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if err := fn(db); err != nil {
+		return err
+	}
+	if err := db.Close(); err != nil {
+		return err
+	}
+
+	f, err = os.Open(f.Name())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -6,12 +6,12 @@ import (
 	"fmt"
 	"html"
 	"io"
-	"sort"
 	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/rusq/slack"
+	"github.com/rusq/slackdump/v3/internal/emojitext"
 	"github.com/rusq/slackdump/v3/internal/structures"
 	"github.com/rusq/slackdump/v3/types"
 )
@@ -29,6 +29,7 @@ type Text struct {
 
 type textOptions struct {
 	msgSplitAfter time.Duration
+	renderers     []TextRenderer
 }
 
 func TextNewMessageThreshold(d time.Duration) Option {
@@ -37,6 +38,14 @@ func TextNewMessageThreshold(d time.Duration) Option {
 	}
 }
 
+// TextWithRenderers sets the renderers of the message parts that follow the
+// message text, replacing the [DefaultTextRenderers].
+func TextWithRenderers(r ...TextRenderer) Option {
+	return func(o *options) {
+		o.textOptions.renderers = r
+	}
+}
+
 func init() {
 	Converters[CText] = NewText
 }
@@ -45,7 +54,10 @@ func NewText(opts ...Option) Formatter {
 	settings := options{
 		textOptions: textOptions{
 			msgSplitAfter: defaultMsgSplitAfter,
-		}}
+			renderers:     DefaultTextRenderers,
+		},
+		emoji: emojitext.Standard,
+	}
 	for _, fn := range opts {
 		fn(&settings)
 	}
@@ -58,7 +70,7 @@ func (txt *Text) Conversation(ctx context.Context, w io.Writer, u []slack.User,
 
 	ui := structures.NewUserIndex(u)
 
-	return txt.txtConversations(w, conv.Messages, "", ui, userReplacer(ui))
+	return txt.txtConversations(buf, conv.Messages, "", ui, userReplacer(ui))
 }
 
 func (txt *Text) txtConversations(w io.Writer, m []types.Message, prefix string, userIdx structures.UserIndex, repl *strings.Replacer) error {
@@ -73,14 +85,19 @@ func (txt *Text) txtConversations(w io.Writer, m []types.Message, prefix string,
 		}
 		diff := t.Sub(prevTime)
 		if prevMsg.User == message.User && diff < txt.opts.msgSplitAfter {
-			fmt.Fprintf(w, prefix+"%s\n", message.Text)
+			fmt.Fprintf(w, prefix+"%s\n", txt.opts.emojiText(message.Text))
 		} else {
 			fmt.Fprintf(w, prefix+"\n"+prefix+"> %s [%s] @ %s:\n%s\n",
 				userIdx.Sender(&message.Message), message.User,
 				t.Format(textTimeFmt),
-				prefix+html.UnescapeString(repl.Replace(message.Text)),
+				prefix+txt.opts.emojiText(html.UnescapeString(repl.Replace(message.Text))),
 			)
 		}
+		for _, r := range txt.opts.renderers {
+			for _, line := range r.Render(&message.Message, userIdx, repl) {
+				fmt.Fprintf(w, prefix+"%s\n", line)
+			}
+		}
 		if len(message.ThreadReplies) > 0 {
 			if err := txt.txtConversations(w, message.ThreadReplies, "|   ", userIdx, repl); err != nil {
 				return err
@@ -92,7 +109,115 @@ func (txt *Text) txtConversations(w io.Writer, m []types.Message, prefix string,
 	return nil
 }
 
+// TextRenderer renders a part of the message that follows the message text
+// in the text output, i.e. reactions.
+type TextRenderer interface {
+	// Render returns the lines to be written after the message text, the
+	// line prefix is added by the caller.  ui is used to resolve the user
+	// names, and repl to replace the user mentions in the text.
+	Render(m *slack.Message, ui structures.UserIndex, repl *strings.Replacer) []string
+}
+
+// TextRendererFunc is the function adapter for the [TextRenderer].
+type TextRendererFunc func(m *slack.Message, ui structures.UserIndex, repl *strings.Replacer) []string
+
+func (f TextRendererFunc) Render(m *slack.Message, ui structures.UserIndex, repl *strings.Replacer) []string {
+	return f(m, ui, repl)
+}
+
+// DefaultTextRenderers are the renderers used by the text formatter, unless
+// set with [TextWithRenderers].
+var DefaultTextRenderers = []TextRenderer{
+	TextRendererFunc(TextShares),
+	TextRendererFunc(TextEdited),
+	TextRendererFunc(TextReactions),
+	TextRendererFunc(TextReplies),
+}
+
+// TextShares renders the source and the text of the shared messages, so that
+// the shared content can be told apart from the message that shares it.
+// Attachments that are not message shares are skipped.
+func TextShares(m *slack.Message, _ structures.UserIndex, repl *strings.Replacer) []string {
+	var lines []string
+	for i := range m.Attachments {
+		a := &m.Attachments[i]
+		ref, ok := structures.SharedRef(a)
+		if !ok {
+			continue
+		}
+		src := ref.ChannelID
+		if a.AuthorName != "" {
+			src += ", " + a.AuthorName
+		}
+		if t, err := structures.ParseSlackTS(ref.TS); err == nil {
+			src += " @ " + t.Format(textTimeFmt)
+		}
+		lines = append(lines, fmt.Sprintf("[shared from %s]: %s", src, html.UnescapeString(repl.Replace(a.Text))))
+	}
+	return lines
+}
+
+// TextEdited renders the edit marker with the time of the last edit.
+func TextEdited(m *slack.Message, ui structures.UserIndex, _ *strings.Replacer) []string {
+	if m.Edited == nil {
+		return nil
+	}
+	marker := "[edited"
+	if t, err := structures.ParseSlackTS(m.Edited.Timestamp); err == nil {
+		marker += " @ " + t.Format(textTimeFmt)
+	}
+	if m.Edited.User != "" && m.Edited.User != m.User {
+		marker += " by " + ui.DisplayName(m.Edited.User)
+	}
+	return []string{marker + "]"}
+}
+
+// TextReactions renders the reactions with their counts and the names of
+// the users who reacted.  Standard reaction emojis are rendered as Unicode
+// characters.
+func TextReactions(m *slack.Message, ui structures.UserIndex, _ *strings.Replacer) []string {
+	if len(m.Reactions) == 0 {
+		return nil
+	}
+	rr := make([]string, 0, len(m.Reactions))
+	for _, r := range m.Reactions {
+		s := fmt.Sprintf("%s %d", emojitext.Standard.Text(":"+r.Name+":"), r.Count)
+		if len(r.Users) > 0 {
+			names := make([]string, len(r.Users))
+			for i, id := range r.Users {
+				names[i] = ui.DisplayName(id)
+			}
+			s += " (" + strings.Join(names, ", ") + ")"
+		}
+		rr = append(rr, s)
+	}
+	return []string{"[reactions: " + strings.Join(rr, "; ") + "]"}
+}
+
+// TextReplies renders the reply count of the thread parent message, and the
+// time of the latest reply.
+func TextReplies(m *slack.Message, _ structures.UserIndex, _ *strings.Replacer) []string {
+	if m.ReplyCount == 0 || m.ThreadTimestamp != m.Timestamp {
+		return nil
+	}
+	s := fmt.Sprintf("[%d replies", m.ReplyCount)
+	if m.ReplyCount == 1 {
+		s = "[1 reply"
+	}
+	if t, err := structures.ParseSlackTS(m.LatestReply); err == nil && m.LatestReply != structures.LatestReplyNoReplies {
+		s += ", latest @ " + t.Format(textTimeFmt)
+	}
+	return []string{s + "]"}
+}
+
 func (txt *Text) Users(ctx context.Context, w io.Writer, u []slack.User) error {
+	if len(txt.opts.columns) > 0 {
+		cc, err := UserColumns.Select(txt.opts.columns...)
+		if err != nil {
+			return err
+		}
+		return textTable(w, cc, u, nil)
+	}
 	const strFormat = "%s\t%s\t%s\t%s\t%s\t%s\n"
 	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
 	defer writer.Flush()
@@ -105,35 +230,25 @@ func (txt *Text) Users(ctx context.Context, w io.Writer, u []slack.User) error {
 		return fmt.Errorf("writer error: %w", err)
 	}
 
-	var (
-		names   = make([]string, 0, len(u))
-		usermap = make(structures.UserIndex, len(u))
-	)
+	// data, in the order given.
 	for i := range u {
-		names = append(names, u[i].Name)
-		usermap[u[i].Name] = &u[i]
-	}
-	sort.Strings(names)
-
-	// data
-	for _, name := range names {
 		var (
 			deleted    string
 			bot        string
 			restricted string
 		)
-		if usermap[name].Deleted {
+		if u[i].Deleted {
 			deleted = "deleted"
 		}
-		if usermap[name].IsBot {
+		if u[i].IsBot {
 			bot = "bot"
 		}
-		if usermap[name].IsRestricted {
+		if u[i].IsRestricted {
 			restricted = "restricted"
 		}
 
 		_, err := fmt.Fprintf(writer, strFormat,
-			name, usermap[name].ID, bot, usermap[name].Profile.Email, deleted, restricted,
+			u[i].Name, u[i].ID, bot, u[i].Profile.Email, deleted, restricted,
 		)
 		if err != nil {
 			return fmt.Errorf("writer error: %w", err)
@@ -143,21 +258,32 @@ func (txt *Text) Users(ctx context.Context, w io.Writer, u []slack.User) error {
 }
 
 func (txt *Text) Channels(ctx context.Context, w io.Writer, u []slack.User, cc []slack.Channel) error {
-	const strFormat = "%s\t%s\t%s\n"
+	const strFormat = "%s\t%s\t%s\t%s\n"
 
 	ui := structures.NewUserIndex(u)
+	if len(txt.opts.columns) > 0 {
+		cols, err := ChannelColumns.Select(txt.opts.columns...)
+		if err != nil {
+			return err
+		}
+		return textTable(w, cols, cc, ui)
+	}
 
 	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
 	defer writer.Flush()
 
-	fmt.Fprintf(writer, strFormat, "ID", "Arch", "What")
+	fmt.Fprintf(writer, strFormat, "ID", "Arch", "Shared", "What")
 	for i, ch := range cc {
 		who := ui.ChannelName(ch)
 		archived := "-"
 		if cc[i].IsArchived || ui.IsDeleted(ch.User) {
 			archived = "arch"
 		}
-		fmt.Fprintf(writer, strFormat, ch.ID, archived, who)
+		shared := "-"
+		if structures.IsExtShared(&cc[i]) {
+			shared = "ext"
+		}
+		fmt.Fprintf(writer, strFormat, ch.ID, archived, shared, who)
 	}
 	return nil
 
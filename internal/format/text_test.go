@@ -3,9 +3,11 @@ package format
 import (
 	"bytes"
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/rusq/slack"
+	"github.com/rusq/slackdump/v3/internal/emojitext"
 	"github.com/rusq/slackdump/v3/internal/structures"
 	"github.com/rusq/slackdump/v3/types"
 	"github.com/stretchr/testify/assert"
@@ -56,6 +58,36 @@ var (
 	}
 )
 
+var testMsg5s = types.Message{Message: slack.Message{Msg: slack.Msg{
+	Type:      "message",
+	User:      "U10H7D9RR",
+	Timestamp: "1638497751.040300",
+	Text:      "look at this",
+	Attachments: []slack.Attachment{
+		{Text: "not a share", FromURL: "https://example.com"},
+		{
+			AuthorName: "Alice",
+			Text:       "message 4",
+			FromURL:    "https://ora600.slack.com/archives/C01SPFM1KNY/p1638524854042000",
+		},
+	},
+}}}
+
+var testMsg6r = types.Message{Message: slack.Message{Msg: slack.Msg{
+	Type:            "message",
+	User:            "U10H7D9RR",
+	Timestamp:       "1638497751.040300",
+	ThreadTimestamp: "1638497751.040300",
+	ReplyCount:      1,
+	LatestReply:     "1638524854.042000",
+	Text:            "edited and reacted",
+	Edited:          &slack.Edited{User: "U10H7D9RR", Timestamp: "1638497781.000000"},
+	Reactions: []slack.ItemReaction{
+		{Name: "+1", Count: 2, Users: []string{"U10H7D9RR", "UP58RAHCJ"}},
+		{Name: "tada", Count: 1},
+	},
+}}}
+
 // test retrofitted from v2.
 func TestText_Conversation(t *testing.T) {
 	type args struct {
@@ -75,10 +107,22 @@ func TestText_Conversation(t *testing.T) {
 			"\n> <external>:U10H7D9RR [U10H7D9RR] @ 03/12/2021 02:15:51 Z:\nTest message < > < >\nmessage 2\n",
 			false,
 		},
+		{
+			"shared message",
+			args{[]types.Message{testMsg5s}, "", nil},
+			"\n> <external>:U10H7D9RR [U10H7D9RR] @ 03/12/2021 02:15:51 Z:\nlook at this\n[shared from C01SPFM1KNY, Alice @ 03/12/2021 09:47:34 Z]: message 4\n",
+			false,
+		},
+		{
+			"edits, reactions and replies",
+			args{[]types.Message{testMsg6r}, "", nil},
+			"\n> <external>:U10H7D9RR [U10H7D9RR] @ 03/12/2021 02:15:51 Z:\nedited and reacted\n[edited @ 03/12/2021 02:16:21 Z]\n[reactions: \U0001F44D 2 (<external>:U10H7D9RR, <external>:UP58RAHCJ); \U0001F389 1]\n[1 reply, latest @ 03/12/2021 09:47:34 Z]\n",
+			false,
+		},
 		{
 			"two messages from the same person, far apart",
 			args{[]types.Message{testMsg1, testMsg4t}, "", nil},
-			"\n> <external>:U10H7D9RR [U10H7D9RR] @ 03/12/2021 02:15:51 Z:\nTest message < > < >\n\n> <external>:UP58RAHCJ [UP58RAHCJ] @ 03/12/2021 09:47:34 Z:\nmessage 4\n|   \n|   > <external>:U01HPAR0YFN [U01HPAR0YFN] @ 03/12/2021 18:05:26 Z:\n|   blah blah, reply 1\n",
+			"\n> <external>:U10H7D9RR [U10H7D9RR] @ 03/12/2021 02:15:51 Z:\nTest message < > < >\n\n> <external>:UP58RAHCJ [UP58RAHCJ] @ 03/12/2021 09:47:34 Z:\nmessage 4\n[3 replies]\n|   \n|   > <external>:U01HPAR0YFN [U01HPAR0YFN] @ 03/12/2021 18:05:26 Z:\n|   blah blah, reply 1\n",
 			false,
 		},
 	}
@@ -97,3 +141,65 @@ func TestText_Conversation(t *testing.T) {
 	}
 
 }
+
+func TestText_WithRenderers(t *testing.T) {
+	var buf bytes.Buffer
+	txt := NewText(TextWithRenderers(TextRendererFunc(func(m *slack.Message, _ structures.UserIndex, _ *strings.Replacer) []string {
+		return []string{"ts=" + m.Timestamp}
+	})))
+	if err := txt.Conversation(context.Background(), &buf, nil, &types.Conversation{Messages: []types.Message{testMsg6r}}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "\n> <external>:U10H7D9RR [U10H7D9RR] @ 03/12/2021 02:15:51 Z:\nedited and reacted\nts=1638497751.040300\n", buf.String())
+}
+
+func TestText_WithEmoji(t *testing.T) {
+	msg := types.Message{Message: slack.Message{Msg: slack.Msg{
+		User:      "U10H7D9RR",
+		Timestamp: "1638497751.040300",
+		Text:      "shipped :tada: :partyparrot:",
+	}}}
+	tests := []struct {
+		name  string
+		opts  []Option
+		wantW string
+	}{
+		{
+			"standard emojis by default",
+			nil,
+			"\n> <external>:U10H7D9RR [U10H7D9RR] @ 03/12/2021 02:15:51 Z:\nshipped \U0001F389 :partyparrot:\n",
+		},
+		{
+			"custom emojis",
+			[]Option{WithEmoji(emojitext.New(map[string]string{"partyparrot": "https://example.com/pp.gif"}))},
+			"\n> <external>:U10H7D9RR [U10H7D9RR] @ 03/12/2021 02:15:51 Z:\nshipped \U0001F389 :partyparrot: (https://example.com/pp.gif)\n",
+		},
+		{
+			"disabled",
+			[]Option{WithEmoji(nil)},
+			"\n> <external>:U10H7D9RR [U10H7D9RR] @ 03/12/2021 02:15:51 Z:\nshipped :tada: :partyparrot:\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := NewText(tt.opts...).Conversation(context.Background(), &buf, nil, &types.Conversation{Messages: []types.Message{msg}}); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantW, buf.String())
+		})
+	}
+}
+
+func TestCSV_Conversation_emoji(t *testing.T) {
+	var buf bytes.Buffer
+	conv := &types.Conversation{Name: "general", Messages: []types.Message{{Message: slack.Message{Msg: slack.Msg{
+		User:      "U10H7D9RR",
+		Timestamp: "1638497751.040300",
+		Text:      "shipped :tada:",
+	}}}}}
+	if err := NewCSV().Conversation(context.Background(), &buf, nil, conv); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "1638497751.040300,general,<external>:U10H7D9RR,shipped \U0001F389\n", buf.String())
+}
@@ -12,11 +12,14 @@ func _() {
 	_ = x[CText-1]
 	_ = x[CCSV-2]
 	_ = x[CJSON-3]
+	_ = x[CSQLite-4]
+	_ = x[CNDJSON-5]
+	_ = x[CMarkdown-6]
 }
 
-const _Type_name = "UnknownTextCSVJSON"
+const _Type_name = "UnknownTextCSVJSONSQLiteNDJSONMarkdown"
 
-var _Type_index = [...]uint8{0, 7, 11, 14, 18}
+var _Type_index = [...]uint8{0, 7, 11, 14, 18, 24, 30, 38}
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_Type_index)-1) {
@@ -0,0 +1,250 @@
+// Package golden compares the file tree, produced by slackdump, i.e. the
+// export, with the golden (reference) output.  JSON files are compared by
+// value, so that the differences in the formatting are ignored, other files
+// are compared byte by byte.
+package golden
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rusq/fsadapter"
+)
+
+// Kind is the kind of the difference.
+type Kind uint8
+
+const (
+	// Missing is the file, that is in the golden output, but was not
+	// produced.
+	Missing Kind = iota
+	// Extra is the file, that was produced, but is not in the golden output.
+	Extra
+	// Changed is the file, that differs from the golden output.
+	Changed
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Missing:
+		return "missing"
+	case Extra:
+		return "extra"
+	case Changed:
+		return "changed"
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Diff is the difference between the produced and the golden file.
+type Diff struct {
+	Path string
+	Kind Kind
+	// Detail describes the first difference in the changed file.
+	Detail string
+}
+
+func (d Diff) String() string {
+	if d.Detail == "" {
+		return d.Kind.String() + ": " + d.Path
+	}
+	return d.Kind.String() + ": " + d.Path + ": " + d.Detail
+}
+
+// Compare compares the files in got with the golden files in want, and
+// returns the differences, sorted by path.  Directories are not compared,
+// only the files in them.
+func Compare(got, want fs.FS) ([]Diff, error) {
+	gotFiles, err := files(got)
+	if err != nil {
+		return nil, fmt.Errorf("output: %w", err)
+	}
+	wantFiles, err := files(want)
+	if err != nil {
+		return nil, fmt.Errorf("golden: %w", err)
+	}
+
+	var diffs []Diff
+	for name := range wantFiles {
+		if _, ok := gotFiles[name]; !ok {
+			diffs = append(diffs, Diff{Path: name, Kind: Missing})
+		}
+	}
+	for name := range gotFiles {
+		if _, ok := wantFiles[name]; !ok {
+			diffs = append(diffs, Diff{Path: name, Kind: Extra})
+			continue
+		}
+		g, err := fs.ReadFile(got, name)
+		if err != nil {
+			return nil, err
+		}
+		w, err := fs.ReadFile(want, name)
+		if err != nil {
+			return nil, err
+		}
+		if detail, ok := equal(name, g, w); !ok {
+			diffs = append(diffs, Diff{Path: name, Kind: Changed, Detail: detail})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs, nil
+}
+
+// files returns the set of the file names in fsys.
+func files(fsys fs.FS) (map[string]struct{}, error) {
+	ret := make(map[string]struct{})
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			ret[p] = struct{}{}
+		}
+		return nil
+	})
+	return ret, err
+}
+
+// equal returns true if got and want are equal, otherwise it returns the
+// description of the first difference.
+func equal(name string, got, want []byte) (string, bool) {
+	if bytes.Equal(got, want) {
+		return "", true
+	}
+	if path.Ext(name) != ".json" {
+		return fmt.Sprintf("contents differ (%d bytes, want %d)", len(got), len(want)), false
+	}
+	g, gerr := decode(got)
+	w, werr := decode(want)
+	if gerr != nil || werr != nil {
+		return fmt.Sprintf("invalid JSON: %v", errors.Join(gerr, werr)), false
+	}
+	return jsonDiff("$", g, w)
+}
+
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("trailing data")
+	}
+	return v, nil
+}
+
+// jsonDiff returns the first difference between the decoded JSON values got
+// and want, p is the JSON path of the values.
+func jsonDiff(p string, got, want any) (string, bool) {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(w)+len(g))
+		for k := range w {
+			keys = append(keys, k)
+		}
+		for k := range g {
+			if _, ok := w[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			gv, gok := g[k]
+			wv, wok := w[k]
+			switch {
+			case !gok:
+				return p + "." + k + ": missing", false
+			case !wok:
+				return p + "." + k + ": unexpected", false
+			}
+			if d, ok := jsonDiff(p+"."+k, gv, wv); !ok {
+				return d, false
+			}
+		}
+		return "", true
+	case []any:
+		g, ok := got.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < len(w) && i < len(g); i++ {
+			if d, ok := jsonDiff(p+"["+strconv.Itoa(i)+"]", g[i], w[i]); !ok {
+				return d, false
+			}
+		}
+		if len(g) != len(w) {
+			return fmt.Sprintf("%s: %d elements, want %d", p, len(g), len(w)), false
+		}
+		return "", true
+	}
+	if reflect.DeepEqual(got, want) {
+		return "", true
+	}
+	return fmt.Sprintf("%s: %s, want %s", p, short(got), short(want)), false
+}
+
+// short returns the abbreviated JSON representation of v.
+func short(v any) string {
+	const max = 60
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if s := string(data); len(s) > max {
+		return s[:max] + "..."
+	}
+	return string(data)
+}
+
+// Open opens the golden output, that is either the directory or the ZIP
+// file.  The caller must call the returned close function when done.
+func Open(name string) (fs.FS, func() error, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if fi.IsDir() {
+		return os.DirFS(name), func() error { return nil }, nil
+	}
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return zr, zr.Close, nil
+}
+
+// Update replaces the golden output in dst with the files from src.  The
+// existing golden files, that are not in src, are left as is.
+func Update(dst fsadapter.FS, src fs.FS) error {
+	return fs.WalkDir(src, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := fs.ReadFile(src, p)
+		if err != nil {
+			return err
+		}
+		return dst.WriteFile(strings.TrimPrefix(p, "./"), data, 0o644)
+	})
+}
@@ -0,0 +1,59 @@
+package golden
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	want := fstest.MapFS{
+		"users.json":         {Data: []byte(`[{"id":"U1","name":"alice"}]`)},
+		"C1/2024-01-01.json": {Data: []byte(`[{"ts":"1.1","text":"hello"},{"ts":"1.2","text":"world"}]`)},
+		"missing.json":       {Data: []byte(`{}`)},
+		"notes.txt":          {Data: []byte("a\n")},
+	}
+	got := fstest.MapFS{
+		// same value, different formatting
+		"users.json":         {Data: []byte("[\n  {\n    \"name\": \"alice\",\n    \"id\": \"U1\"\n  }\n]\n")},
+		"C1/2024-01-01.json": {Data: []byte(`[{"ts":"1.1","text":"hello"},{"ts":"1.2","text":"there"}]`)},
+		"extra.json":         {Data: []byte(`{}`)},
+		"notes.txt":          {Data: []byte("b\n")},
+	}
+	diffs, err := Compare(got, want)
+	require.NoError(t, err)
+	assert.Equal(t, []Diff{
+		{Path: "C1/2024-01-01.json", Kind: Changed, Detail: `$[1].text: "there", want "world"`},
+		{Path: "extra.json", Kind: Extra},
+		{Path: "missing.json", Kind: Missing},
+		{Path: "notes.txt", Kind: Changed, Detail: "contents differ (2 bytes, want 2)"},
+	}, diffs)
+}
+
+func Test_jsonDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		got, want string
+		wantDiff  string
+	}{
+		{"equal", `{"a":[1,2]}`, `{"a":[1,2]}`, ""},
+		{"missing key", `{"a":1}`, `{"a":1,"b":2}`, "$.b: missing"},
+		{"unexpected key", `{"a":1,"c":2}`, `{"a":1}`, "$.c: unexpected"},
+		{"length", `[1,2,3]`, `[1,2]`, "$: 3 elements, want 2"},
+		{"type", `{"a":"1"}`, `{"a":1}`, `$.a: "1", want 1`},
+		{"nested", `{"a":{"b":[{"c":true}]}}`, `{"a":{"b":[{"c":false}]}}`, "$.a.b[0].c: true, want false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := decode([]byte(tt.got))
+			require.NoError(t, err)
+			w, err := decode([]byte(tt.want))
+			require.NoError(t, err)
+			d, ok := jsonDiff("$", g, w)
+			assert.Equal(t, tt.wantDiff == "", ok)
+			assert.Equal(t, tt.wantDiff, d)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+// Package humanize formats the values for humans.
+package humanize
+
+import "fmt"
+
+// Size returns a human-readable string representing a file size, for example
+// 240.4M or 2.3G.
+func Size(size int64) string {
+	const (
+		K = 1 << 10
+		M = 1 << 20
+		G = 1 << 30
+		T = 1 << 40
+	)
+
+	switch {
+	case size < K:
+		return fmt.Sprintf("%dB", size)
+	case size < M:
+		return fmt.Sprintf("%.1fK", float64(size)/K)
+	case size < G:
+		return fmt.Sprintf("%.1fM", float64(size)/M)
+	case size < T:
+		return fmt.Sprintf("%.1fG", float64(size)/G)
+	default:
+		return fmt.Sprintf("%.1fT", float64(size)/T)
+	}
+}
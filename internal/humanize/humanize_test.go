@@ -0,0 +1,27 @@
+package humanize
+
+import "testing"
+
+func TestSize(t *testing.T) {
+	tests := []struct {
+		name string
+		size int64
+		want string
+	}{
+		{"zero", 0, "0B"},
+		{"bytes", 1023, "1023B"},
+		{"kilobytes", 1024, "1.0K"},
+		{"fraction", 1536, "1.5K"},
+		{"megabytes", 240<<20 + 400<<10, "240.4M"},
+		{"gigabytes", 5 << 30, "5.0G"},
+		{"terabytes", 1 << 40, "1.0T"},
+		{"petabytes", 3 << 50, "3072.0T"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Size(tt.size); got != tt.want {
+				t.Errorf("Size(%d) = %q, want %q", tt.size, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package hydrate
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/rusq/slack"
+)
+
+// CacheFilename is the name of the hydration cache file within the archive
+// directory.
+const CacheFilename = "hydrate.json"
+
+// Cache contains the results of the lookups, it is stored in the archive, so
+// that the IDs are not looked up again on the subsequent conversions.
+type Cache struct {
+	Users      []slack.User      `json:"users,omitempty"`
+	Channels   []slack.Channel   `json:"channels,omitempty"`
+	UserGroups []slack.UserGroup `json:"usergroups,omitempty"`
+	// Missing contains the IDs that do not exist, i.e. were deleted, they
+	// are not looked up again.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// LoadCache loads the cache from the archive directory dir.  If the cache
+// file does not exist, it returns an empty cache.
+func LoadCache(dir string) (*Cache, error) {
+	f, err := os.Open(filepath.Join(dir, CacheFilename))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return new(Cache), nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var c Cache
+	if err := json.NewDecoder(f).Decode(&c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Save saves the cache to the archive directory dir.
+func (c *Cache) Save(dir string) error {
+	f, err := os.Create(filepath.Join(dir, CacheFilename))
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(c); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,379 @@
+// Package hydrate resolves the user, channel and user group IDs referenced in
+// the archived messages that are not present in the archive, i.e. the users
+// that left the workspace before the archive was created, or the channels
+// that were not archived.  Unknown IDs are looked up against the live API in
+// batches, when the client is available, and the results are cached in the
+// archive, so that the subsequent conversions and the viewer do not need
+// the network access.
+package hydrate
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rusq/slack"
+	"golang.org/x/time/rate"
+
+	"github.com/rusq/slackdump/v3/internal/network"
+)
+
+// Client is the subset of the Slack API client that is used to look up the
+// unknown IDs.
+type Client interface {
+	GetUsersInfoContext(ctx context.Context, users ...string) (*[]slack.User, error)
+	GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error)
+	GetUserGroupsContext(ctx context.Context, options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error)
+}
+
+const (
+	defBatchSize = 30 // users per users.info call
+	defRetries   = 3  // retries on rate limit and server errors
+)
+
+// Service is the hydration service.  It holds the resolved entities, which
+// are looked up or loaded from the cache.  It is safe for concurrent use.
+type Service struct {
+	cl        Client
+	lg        *slog.Logger
+	batchSize int
+	retries   int
+	limits    struct {
+		users, channels, groups *rate.Limiter
+	}
+
+	mu       sync.RWMutex
+	users    map[string]slack.User
+	channels map[string]slack.Channel
+	groups   map[string]slack.UserGroup
+	missing  map[string]struct{}
+	dirty    bool
+}
+
+// Option is the Service option.
+type Option func(*Service)
+
+// WithLogger sets the logger.
+func WithLogger(lg *slog.Logger) Option {
+	return func(s *Service) {
+		if lg != nil {
+			s.lg = lg
+		}
+	}
+}
+
+// WithBatchSize sets the number of users looked up with a single users.info
+// call.
+func WithBatchSize(n int) Option {
+	return func(s *Service) {
+		if n > 0 {
+			s.batchSize = n
+		}
+	}
+}
+
+// WithLimiter sets the limiter for all lookups, overriding the default
+// per-endpoint limits.
+func WithLimiter(l *rate.Limiter) Option {
+	return func(s *Service) {
+		if l != nil {
+			s.limits.users, s.limits.channels, s.limits.groups = l, l, l
+		}
+	}
+}
+
+// New creates the hydration service with the results loaded from the cache.
+// If cl is nil, the service works offline, using only the cached results.
+// cache may be nil.
+func New(cl Client, cache *Cache, opts ...Option) *Service {
+	s := &Service{
+		cl:        cl,
+		lg:        slog.Default(),
+		batchSize: defBatchSize,
+		retries:   defRetries,
+		users:     make(map[string]slack.User),
+		channels:  make(map[string]slack.Channel),
+		groups:    make(map[string]slack.UserGroup),
+		missing:   make(map[string]struct{}),
+	}
+	s.limits.users = network.NewLimiter(network.Tier4, 1, 0)
+	s.limits.channels = network.NewLimiter(network.Tier3, 1, 0)
+	s.limits.groups = network.NewLimiter(network.Tier2, 1, 0)
+	for _, opt := range opts {
+		opt(s)
+	}
+	if cache != nil {
+		for _, u := range cache.Users {
+			s.users[u.ID] = u
+		}
+		for _, c := range cache.Channels {
+			s.channels[c.ID] = c
+		}
+		for _, g := range cache.UserGroups {
+			s.groups[g.ID] = g
+		}
+		for _, id := range cache.Missing {
+			s.missing[id] = struct{}{}
+		}
+	}
+	return s
+}
+
+// Online returns true if the service looks up the unknown IDs with the API.
+func (s *Service) Online() bool {
+	return s.cl != nil
+}
+
+// Hydrate looks up the IDs from refs, that are neither in the known users and
+// channels, nor in the cache.  If the service is offline, it does nothing.
+// The lookups are best effort: the IDs that do not exist are remembered as
+// missing, and the IDs that fail to resolve due to other errors, i.e. the
+// missing token scope, are logged and skipped, so that they are retried the
+// next time.  It returns an error only if the context is cancelled.
+func (s *Service) Hydrate(ctx context.Context, refs *Refs, users []slack.User, channels []slack.Channel) error {
+	if s.cl == nil || refs == nil {
+		return nil
+	}
+	knownUsers := make(map[string]struct{}, len(users))
+	for _, u := range users {
+		knownUsers[u.ID] = struct{}{}
+	}
+	knownChannels := make(map[string]struct{}, len(channels))
+	for _, c := range channels {
+		knownChannels[c.ID] = struct{}{}
+	}
+
+	s.mu.RLock()
+	uids := unknown(refs.Users, knownUsers, s.users, s.missing)
+	cids := unknown(refs.Channels, knownChannels, s.channels, s.missing)
+	gids := unknown(refs.UserGroups, nil, s.groups, s.missing)
+	s.mu.RUnlock()
+
+	s.lg.DebugContext(ctx, "hydrating", "users", len(uids), "channels", len(cids), "usergroups", len(gids))
+	if err := s.lookupUsers(ctx, uids); err != nil {
+		return err
+	}
+	if err := s.lookupChannels(ctx, cids); err != nil {
+		return err
+	}
+	return s.lookupUserGroups(ctx, gids)
+}
+
+// unknown returns the sorted IDs from ids, that are not in any of the
+// known, resolved or missing sets.
+func unknown[T any](ids map[string]struct{}, known map[string]struct{}, resolved map[string]T, missing map[string]struct{}) []string {
+	var out []string
+	for _, id := range sortedKeys(ids) {
+		if _, ok := known[id]; ok {
+			continue
+		}
+		if _, ok := resolved[id]; ok {
+			continue
+		}
+		if _, ok := missing[id]; ok {
+			continue
+		}
+		out = append(out, id)
+	}
+	return out
+}
+
+// isNotFound returns true if the error is the Slack "*_not_found" error.
+func isNotFound(err error) bool {
+	var se slack.SlackErrorResponse
+	return errors.As(err, &se) && strings.HasSuffix(se.Err, "_not_found")
+}
+
+func (s *Service) lookupUsers(ctx context.Context, ids []string) error {
+	for len(ids) > 0 {
+		n := min(s.batchSize, len(ids))
+		batch := ids[:n]
+		ids = ids[n:]
+
+		var uu *[]slack.User
+		err := network.WithRetry(network.WithEndpoint(ctx, "users.info"), s.limits.users, s.retries, func() error {
+			var err error
+			uu, err = s.cl.GetUsersInfoContext(ctx, batch...)
+			return err
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if !isNotFound(err) {
+				s.lg.WarnContext(ctx, "unable to look up users", "user_ids", batch, "error", err)
+				continue
+			}
+			if len(batch) > 1 {
+				// one of the users in the batch does not exist, which fails
+				// the whole batch, find out which one.
+				if err := s.lookupUsersOneByOne(ctx, batch); err != nil {
+					return err
+				}
+				continue
+			}
+			s.setMissing(batch...)
+			continue
+		}
+		s.setUsers(batch, *uu)
+	}
+	return nil
+}
+
+func (s *Service) lookupUsersOneByOne(ctx context.Context, ids []string) error {
+	bs := s.batchSize
+	s.batchSize = 1
+	defer func() { s.batchSize = bs }()
+	return s.lookupUsers(ctx, ids)
+}
+
+// setUsers caches the users uu, and marks the IDs requested, that were not
+// returned, as missing.
+func (s *Service) setUsers(requested []string, uu []slack.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range uu {
+		s.users[u.ID] = u
+	}
+	for _, id := range requested {
+		if _, ok := s.users[id]; !ok {
+			s.missing[id] = struct{}{}
+		}
+	}
+	s.dirty = true
+}
+
+func (s *Service) setMissing(ids ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.missing[id] = struct{}{}
+	}
+	s.dirty = true
+}
+
+func (s *Service) lookupChannels(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		var ch *slack.Channel
+		err := network.WithRetry(network.WithEndpoint(ctx, "conversations.info"), s.limits.channels, s.retries, func() error {
+			var err error
+			ch, err = s.cl.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: id})
+			return err
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if isNotFound(err) {
+				s.setMissing(id)
+				continue
+			}
+			s.lg.WarnContext(ctx, "unable to look up the channel", "channel_id", id, "error", err)
+			continue
+		}
+		s.mu.Lock()
+		s.channels[ch.ID] = *ch
+		s.dirty = true
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// lookupUserGroups looks up the user groups.  There's no API to get a
+// single user group, so all user groups of the workspace are requested,
+// including the disabled ones, and the referenced ones are cached.
+func (s *Service) lookupUserGroups(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var groups []slack.UserGroup
+	err := network.WithRetry(network.WithEndpoint(ctx, "usergroups.list"), s.limits.groups, s.retries, func() error {
+		var err error
+		groups, err = s.cl.GetUserGroupsContext(ctx, slack.GetUserGroupsOptionIncludeDisabled(true))
+		return err
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.lg.WarnContext(ctx, "unable to look up user groups", "usergroup_ids", ids, "error", err)
+		return nil
+	}
+	byID := make(map[string]slack.UserGroup, len(groups))
+	for _, g := range groups {
+		byID[g.ID] = g
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		if g, ok := byID[id]; ok {
+			s.groups[id] = g
+		} else {
+			s.missing[id] = struct{}{}
+		}
+	}
+	s.dirty = true
+	return nil
+}
+
+// Users returns the known users, followed by the resolved users that are not
+// in the known list, sorted by ID.
+func (s *Service) Users(known []slack.User) []slack.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return merge(known, s.users, func(u slack.User) string { return u.ID })
+}
+
+// Channels returns the known channels, followed by the resolved channels that
+// are not in the known list, sorted by ID.
+func (s *Service) Channels(known []slack.Channel) []slack.Channel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return merge(known, s.channels, func(c slack.Channel) string { return c.ID })
+}
+
+// UserGroups returns the resolved user groups, sorted by ID.
+func (s *Service) UserGroups() []slack.UserGroup {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return merge(nil, s.groups, func(g slack.UserGroup) string { return g.ID })
+}
+
+func merge[T any](known []T, resolved map[string]T, id func(T) string) []T {
+	have := make(map[string]struct{}, len(known))
+	for _, v := range known {
+		have[id(v)] = struct{}{}
+	}
+	out := append([]T(nil), known...)
+	var extra []T
+	for k, v := range resolved {
+		if _, ok := have[k]; !ok {
+			extra = append(extra, v)
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return id(extra[i]) < id(extra[j]) })
+	return append(out, extra...)
+}
+
+// Dirty returns true if new results were looked up since the service was
+// created, and the cache should be saved.
+func (s *Service) Dirty() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dirty
+}
+
+// Cache returns the cache with all resolved results.
+func (s *Service) Cache() *Cache {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &Cache{
+		Users:      merge(nil, s.users, func(u slack.User) string { return u.ID }),
+		Channels:   merge(nil, s.channels, func(c slack.Channel) string { return c.ID }),
+		UserGroups: merge(nil, s.groups, func(g slack.UserGroup) string { return g.ID }),
+		Missing:    sortedKeys(s.missing),
+	}
+}
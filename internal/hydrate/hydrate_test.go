@@ -0,0 +1,258 @@
+package hydrate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/rusq/slack"
+	"golang.org/x/time/rate"
+)
+
+// fakeClient is the fake Slack client, that resolves the IDs from the
+// maps, and records the calls.
+type fakeClient struct {
+	users    map[string]slack.User
+	channels map[string]slack.Channel
+	groups   []slack.UserGroup
+
+	userCalls    [][]string
+	channelCalls []string
+	groupCalls   int
+}
+
+func (c *fakeClient) GetUsersInfoContext(_ context.Context, users ...string) (*[]slack.User, error) {
+	c.userCalls = append(c.userCalls, users)
+	var out []slack.User
+	for _, id := range users {
+		u, ok := c.users[id]
+		if !ok {
+			// slack fails the whole request, if any of the users is not
+			// found.
+			return nil, slack.SlackErrorResponse{Err: "user_not_found"}
+		}
+		out = append(out, u)
+	}
+	return &out, nil
+}
+
+func (c *fakeClient) GetConversationInfoContext(_ context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	c.channelCalls = append(c.channelCalls, input.ChannelID)
+	ch, ok := c.channels[input.ChannelID]
+	if !ok {
+		return nil, slack.SlackErrorResponse{Err: "channel_not_found"}
+	}
+	return &ch, nil
+}
+
+func (c *fakeClient) GetUserGroupsContext(context.Context, ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	c.groupCalls++
+	return c.groups, nil
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		users: map[string]slack.User{
+			"U1": {ID: "U1", Name: "alice"},
+			"U2": {ID: "U2", Name: "bob"},
+			"U3": {ID: "U3", Name: "carol"},
+		},
+		channels: map[string]slack.Channel{
+			"C2": {GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C2"}, Name: "random"}},
+		},
+		groups: []slack.UserGroup{{ID: "S1", Handle: "devs"}, {ID: "S2", Handle: "ops"}},
+	}
+}
+
+func newRefs(text string, users ...string) *Refs {
+	var r Refs
+	for _, u := range users {
+		r.AddMessage(&slack.Message{Msg: slack.Msg{User: u}})
+	}
+	r.addText(text)
+	return &r
+}
+
+func noLimits() Option {
+	return WithLimiter(rate.NewLimiter(rate.Inf, 1))
+}
+
+func ids[T any](vv []T, id func(T) string) []string {
+	var out []string
+	for _, v := range vv {
+		out = append(out, id(v))
+	}
+	return out
+}
+
+func userIDs(uu []slack.User) []string { return ids(uu, func(u slack.User) string { return u.ID }) }
+
+func TestRefs_AddMessage(t *testing.T) {
+	var r Refs
+	r.AddMessage(&slack.Message{Msg: slack.Msg{
+		User:       "U1",
+		Text:       "hey <@U2|bob>, see <#C1|general> and ask <!subteam^S1|@devs>",
+		Edited:     &slack.Edited{User: "U3"},
+		ReplyUsers: []string{"U4"},
+		Reactions:  []slack.ItemReaction{{Name: "+1", Users: []string{"W5"}}},
+		Attachments: []slack.Attachment{
+			{Text: "cc <@U6>"},
+		},
+	}})
+	if got, want := sortedKeys(r.Users), []string{"U1", "U2", "U3", "U4", "U6", "W5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("users = %v, want %v", got, want)
+	}
+	if got, want := sortedKeys(r.Channels), []string{"C1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("channels = %v, want %v", got, want)
+	}
+	if got, want := sortedKeys(r.UserGroups), []string{"S1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("usergroups = %v, want %v", got, want)
+	}
+	if r.Len() != 8 {
+		t.Errorf("Len() = %d, want 8", r.Len())
+	}
+}
+
+func TestService_Hydrate(t *testing.T) {
+	ctx := context.Background()
+	t.Run("batches and skips known", func(t *testing.T) {
+		cl := newFakeClient()
+		s := New(cl, nil, noLimits(), WithBatchSize(2))
+		known := []slack.User{{ID: "U1"}}
+		if err := s.Hydrate(ctx, newRefs("", "U1", "U2", "U3"), known, nil); err != nil {
+			t.Fatal(err)
+		}
+		if want := [][]string{{"U2", "U3"}}; !reflect.DeepEqual(cl.userCalls, want) {
+			t.Errorf("user calls = %v, want %v", cl.userCalls, want)
+		}
+		if got, want := userIDs(s.Users(known)), []string{"U1", "U2", "U3"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Users() = %v, want %v", got, want)
+		}
+		if !s.Dirty() {
+			t.Error("expected the service to be dirty")
+		}
+	})
+	t.Run("not found in batch", func(t *testing.T) {
+		cl := newFakeClient()
+		s := New(cl, nil, noLimits())
+		if err := s.Hydrate(ctx, newRefs("", "U1", "U9"), nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if want := [][]string{{"U1", "U9"}, {"U1"}, {"U9"}}; !reflect.DeepEqual(cl.userCalls, want) {
+			t.Errorf("user calls = %v, want %v", cl.userCalls, want)
+		}
+		c := s.Cache()
+		if got, want := userIDs(c.Users), []string{"U1"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("cached users = %v, want %v", got, want)
+		}
+		if want := []string{"U9"}; !reflect.DeepEqual(c.Missing, want) {
+			t.Errorf("missing = %v, want %v", c.Missing, want)
+		}
+	})
+	t.Run("channels and user groups", func(t *testing.T) {
+		cl := newFakeClient()
+		s := New(cl, nil, noLimits())
+		refs := newRefs("<#C1> <#C2> <#C3> <!subteam^S2> <!subteam^S9>")
+		known := []slack.Channel{{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}}}
+		if err := s.Hydrate(ctx, refs, nil, known); err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"C2", "C3"}; !reflect.DeepEqual(cl.channelCalls, want) {
+			t.Errorf("channel calls = %v, want %v", cl.channelCalls, want)
+		}
+		if cl.groupCalls != 1 {
+			t.Errorf("group calls = %d, want 1", cl.groupCalls)
+		}
+		chans := s.Channels(known)
+		if got, want := ids(chans, func(c slack.Channel) string { return c.ID }), []string{"C1", "C2"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Channels() = %v, want %v", got, want)
+		}
+		if got := s.UserGroups(); len(got) != 1 || got[0].Handle != "ops" {
+			t.Errorf("UserGroups() = %v, want ops", got)
+		}
+		if want := []string{"C3", "S9"}; !reflect.DeepEqual(s.Cache().Missing, want) {
+			t.Errorf("missing = %v, want %v", s.Cache().Missing, want)
+		}
+	})
+	t.Run("uses the cache", func(t *testing.T) {
+		dir := t.TempDir()
+		cl := newFakeClient()
+		s := New(cl, nil, noLimits())
+		if err := s.Hydrate(ctx, newRefs("", "U2", "U9"), nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Cache().Save(dir); err != nil {
+			t.Fatal(err)
+		}
+		cache, err := LoadCache(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cl2 := newFakeClient()
+		s2 := New(cl2, cache, noLimits())
+		if err := s2.Hydrate(ctx, newRefs("", "U2", "U9"), nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if len(cl2.userCalls) != 0 {
+			t.Errorf("unexpected user calls: %v", cl2.userCalls)
+		}
+		if s2.Dirty() {
+			t.Error("service should not be dirty")
+		}
+		if got, want := userIDs(s2.Users(nil)), []string{"U2"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Users() = %v, want %v", got, want)
+		}
+	})
+	t.Run("offline", func(t *testing.T) {
+		s := New(nil, &Cache{Users: []slack.User{{ID: "U1"}}})
+		if s.Online() {
+			t.Error("expected offline service")
+		}
+		if err := s.Hydrate(ctx, newRefs("", "U2"), nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := userIDs(s.Users(nil)), []string{"U1"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Users() = %v, want %v", got, want)
+		}
+	})
+	t.Run("other errors are not cached", func(t *testing.T) {
+		s := New(&errClient{}, nil, noLimits())
+		s.retries = 1
+		if err := s.Hydrate(ctx, newRefs("<#C1>", "U1"), nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if c := s.Cache(); len(c.Missing) != 0 || len(c.Users) != 0 {
+			t.Errorf("unexpected cache: %+v", c)
+		}
+	})
+}
+
+type errClient struct{ fakeClient }
+
+var errScope = slack.SlackErrorResponse{Err: "missing_scope"}
+
+func (errClient) GetUsersInfoContext(context.Context, ...string) (*[]slack.User, error) {
+	return nil, errScope
+}
+
+func (errClient) GetConversationInfoContext(context.Context, *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	return nil, errScope
+}
+
+func Test_isNotFound(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{slack.SlackErrorResponse{Err: "user_not_found"}, true},
+		{errors.Join(errors.New("callback error"), slack.SlackErrorResponse{Err: "channel_not_found"}), true},
+		{errScope, false},
+		{errors.New("user_not_found"), false},
+	}
+	for _, tt := range tests {
+		if got := isNotFound(tt.err); got != tt.want {
+			t.Errorf("isNotFound(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
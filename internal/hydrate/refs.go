@@ -0,0 +1,176 @@
+package hydrate
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"regexp"
+	"sort"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+var (
+	// <@U12345678> or <@U12345678|name>
+	reUserMention = regexp.MustCompile(`<@([UW][A-Z0-9]+)(?:\|[^>]*)?>`)
+	// <#C12345678> or <#C12345678|name>
+	reChannelMention = regexp.MustCompile(`<#([CGD][A-Z0-9]+)(?:\|[^>]*)?>`)
+	// <!subteam^S12345678> or <!subteam^S12345678|@handle>
+	reUserGroupMention = regexp.MustCompile(`<!subteam\^([A-Z0-9]+)(?:\|[^>]*)?>`)
+)
+
+// Refs is the set of the user, channel and user group IDs referenced in the
+// messages.  Zero value is ready to use.
+type Refs struct {
+	Users      map[string]struct{}
+	Channels   map[string]struct{}
+	UserGroups map[string]struct{}
+}
+
+func add(m *map[string]struct{}, id string) {
+	if id == "" {
+		return
+	}
+	if *m == nil {
+		*m = make(map[string]struct{})
+	}
+	(*m)[id] = struct{}{}
+}
+
+// AddMessage adds the IDs referenced in the message m: the author, the
+// editor, the thread participants, the reactions and the mentions in the
+// text.
+func (r *Refs) AddMessage(m *slack.Message) {
+	add(&r.Users, m.User)
+	if m.Edited != nil {
+		add(&r.Users, m.Edited.User)
+	}
+	for _, id := range m.ReplyUsers {
+		add(&r.Users, id)
+	}
+	for _, rr := range m.Reactions {
+		for _, id := range rr.Users {
+			add(&r.Users, id)
+		}
+	}
+	r.addText(m.Text)
+	for i := range m.Attachments {
+		r.addText(m.Attachments[i].Text)
+	}
+}
+
+// addText adds the IDs of the user, channel and user group mentions in the
+// message text.
+func (r *Refs) addText(s string) {
+	for _, sm := range reUserMention.FindAllStringSubmatch(s, -1) {
+		add(&r.Users, sm[1])
+	}
+	for _, sm := range reChannelMention.FindAllStringSubmatch(s, -1) {
+		add(&r.Channels, sm[1])
+	}
+	for _, sm := range reUserGroupMention.FindAllStringSubmatch(s, -1) {
+		add(&r.UserGroups, sm[1])
+	}
+}
+
+// Len returns the total number of the referenced IDs.
+func (r *Refs) Len() int {
+	return len(r.Users) + len(r.Channels) + len(r.UserGroups)
+}
+
+// sortedKeys returns the sorted keys of m, so that the API calls are made in
+// a stable order.
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Source is the source of the messages to scan for references.
+type Source interface {
+	// Channels should return all channels.
+	Channels() ([]slack.Channel, error)
+	// AllMessages should return all messages for the given channel id.
+	AllMessages(channelID string) ([]slack.Message, error)
+	// AllThreadMessages should return all messages for the given tuple
+	// (channelID, threadID).
+	AllThreadMessages(channelID, threadID string) ([]slack.Message, error)
+}
+
+// Scan collects the references from all messages and thread replies of all
+// channels in the source.  Conversations that are missing in the source are
+// skipped.
+func Scan(ctx context.Context, src Source) (*Refs, error) {
+	channels, err := src.Channels()
+	if err != nil {
+		return nil, err
+	}
+	var refs Refs
+	for _, ch := range channels {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		mm, err := src.AllMessages(ch.ID)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for i := range mm {
+			refs.AddMessage(&mm[i])
+			if !structures.IsThreadStart(&mm[i]) {
+				continue
+			}
+			tm, err := src.AllThreadMessages(ch.ID, mm[i].ThreadTimestamp)
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				return nil, err
+			}
+			for j := range tm {
+				refs.AddMessage(&tm[j])
+			}
+		}
+	}
+	return &refs, nil
+}
+
+// ScanChunks collects the references from all message chunks of the
+// channels in the chunk directory cd.
+func ScanChunks(ctx context.Context, cd *chunk.Directory, channels []slack.Channel) (*Refs, error) {
+	var refs Refs
+	for _, ch := range channels {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		f, err := cd.Open(chunk.ToFileID(ch.ID, "", false))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		err = f.ForEach(func(c *chunk.Chunk) error {
+			if c.Parent != nil {
+				refs.AddMessage(c.Parent)
+			}
+			for i := range c.Messages {
+				refs.AddMessage(&c.Messages[i])
+			}
+			return nil
+		})
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &refs, nil
+}
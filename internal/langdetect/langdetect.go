@@ -0,0 +1,205 @@
+// Package langdetect implements the lightweight language detection of the
+// message text.  It is intended for tagging the messages for the analytics,
+// and not for the linguistic precision:  languages that use their own script
+// are detected by the script, and languages that use the Latin script are
+// detected by the frequency of the common words.
+package langdetect
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Undetermined is returned by [Detect] if the language can't be determined.
+const Undetermined = ""
+
+const (
+	// minLetters is the minimum number of letters in the text for the
+	// detection to be attempted.
+	minLetters = 3
+	// minHits is the minimum number of common words that must be found in the
+	// text written in the Latin script.
+	minHits = 2
+)
+
+// slackMarkup matches the slack markup that should not affect the detection:
+// code blocks, inline code, links, mentions, and emojis.
+var slackMarkup = regexp.MustCompile("(?s)```.*?```|`[^`]*`|<[^>]*>|:[a-z0-9_+\\-]+:|https?://\\S+")
+
+// Detect returns the ISO 639-1 code of the language of the text, or
+// [Undetermined].
+func Detect(text string) string {
+	text = slackMarkup.ReplaceAllString(text, " ")
+	var (
+		letters int
+		scripts = make(map[string]int, 2)
+	)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		scripts[scriptOf(r)]++
+	}
+	if letters < minLetters {
+		return Undetermined
+	}
+	// Japanese uses Han together with kana, so any kana means Japanese.
+	if scripts["kana"] > 0 {
+		return "ja"
+	}
+	var (
+		script string
+		n      int
+	)
+	for s, cnt := range scripts {
+		if cnt > n || (cnt == n && s < script) {
+			script, n = s, cnt
+		}
+	}
+	switch script {
+	case "latin":
+		return detectLatin(text)
+	case "cyrillic":
+		return detectCyrillic(text)
+	case "arabic":
+		if strings.ContainsAny(text, "پچژگ") {
+			return "fa"
+		}
+		return "ar"
+	case "other":
+		return Undetermined
+	}
+	return scriptLang[script]
+}
+
+// scriptLang maps the script to the language, for the scripts used mostly by
+// a single language.
+var scriptLang = map[string]string{
+	"han":        "zh",
+	"hangul":     "ko",
+	"greek":      "el",
+	"hebrew":     "he",
+	"thai":       "th",
+	"devanagari": "hi",
+	"armenian":   "hy",
+	"georgian":   "ka",
+}
+
+func scriptOf(r rune) string {
+	switch {
+	case unicode.Is(unicode.Latin, r):
+		return "latin"
+	case unicode.Is(unicode.Cyrillic, r):
+		return "cyrillic"
+	case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+		return "kana"
+	case unicode.Is(unicode.Han, r):
+		return "han"
+	case unicode.Is(unicode.Hangul, r):
+		return "hangul"
+	case unicode.Is(unicode.Arabic, r):
+		return "arabic"
+	case unicode.Is(unicode.Greek, r):
+		return "greek"
+	case unicode.Is(unicode.Hebrew, r):
+		return "hebrew"
+	case unicode.Is(unicode.Thai, r):
+		return "thai"
+	case unicode.Is(unicode.Devanagari, r):
+		return "devanagari"
+	case unicode.Is(unicode.Armenian, r):
+		return "armenian"
+	case unicode.Is(unicode.Georgian, r):
+		return "georgian"
+	}
+	return "other"
+}
+
+func detectCyrillic(text string) string {
+	lower := strings.ToLower(text)
+	switch {
+	case strings.ContainsAny(lower, "іїєґ"):
+		return "uk"
+	case strings.ContainsAny(lower, "ў"):
+		return "be"
+	case strings.ContainsAny(lower, "ђћџљњ"):
+		return "sr"
+	}
+	return "ru"
+}
+
+// commonWords are the most frequent words of the languages using the Latin
+// script.  Words shared by several languages are listed for each of them.
+var commonWords = map[string][]string{
+	"en": {"the", "and", "is", "are", "to", "of", "in", "that", "it", "you", "for", "this", "with", "have", "was", "be", "not", "on", "we", "what"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ich", "sie", "es", "ein", "eine", "zu", "mit", "auf", "den", "von", "wir", "auch", "sind", "aber"},
+	"fr": {"le", "la", "les", "et", "est", "une", "un", "des", "pas", "je", "vous", "que", "qui", "pour", "dans", "sur", "avec", "nous", "ce", "mais"},
+	"es": {"el", "la", "los", "las", "y", "es", "una", "un", "que", "de", "no", "en", "por", "para", "con", "pero", "muy", "como", "está", "del"},
+	"it": {"il", "la", "e", "è", "di", "che", "non", "un", "una", "per", "sono", "con", "del", "della", "gli", "anche", "ma", "come", "questo", "ho"},
+	"pt": {"o", "a", "os", "as", "e", "é", "de", "que", "não", "um", "uma", "para", "com", "do", "da", "em", "mas", "você", "isso", "está"},
+	"nl": {"de", "het", "een", "en", "is", "niet", "ik", "van", "dat", "je", "op", "te", "met", "zijn", "voor", "maar", "ook", "wij", "er", "wat"},
+	"sv": {"och", "är", "att", "det", "en", "ett", "inte", "jag", "som", "på", "med", "för", "av", "till", "vi", "men", "har", "den", "du", "om"},
+	"pl": {"i", "w", "nie", "jest", "to", "się", "na", "że", "z", "do", "jak", "ale", "co", "tak", "czy", "po", "mi", "ten", "być", "są"},
+	"tr": {"ve", "bir", "bu", "da", "de", "için", "ne", "değil", "çok", "ben", "sen", "var", "yok", "ile", "gibi", "ama", "daha", "mı", "mi", "olarak"},
+	"id": {"dan", "yang", "di", "ini", "itu", "tidak", "saya", "ada", "untuk", "dengan", "ke", "dari", "akan", "kita", "bisa", "sudah", "juga", "apa", "kami", "atau"},
+}
+
+// wordIndex maps the word to the languages it is common in.
+var wordIndex = func() map[string][]string {
+	idx := make(map[string][]string)
+	for lang, words := range commonWords {
+		for _, w := range words {
+			idx[w] = append(idx[w], lang)
+		}
+	}
+	return idx
+}()
+
+// distinctive are the letters that are used by only one of the detected
+// languages, each occurrence counts as a common word.  Letters shared with
+// other languages are not listed, even if those are not detected, i.e. 'ç'
+// (French, Portuguese, Turkish) or 'å' (Swedish, Danish, Norwegian), as they
+// would tip the text in the other language to the listed one.
+var distinctive = map[rune]string{
+	'ñ': "es", '¿': "es", '¡': "es",
+	'ß': "de",
+	'ã': "pt",
+	'ą': "pl", 'ę': "pl", 'ł': "pl", 'ś': "pl", 'ź': "pl", 'ż': "pl", 'ń': "pl",
+	'ğ': "tr", 'ı': "tr", 'ş': "tr",
+}
+
+func detectLatin(text string) string {
+	lower := strings.ToLower(text)
+	score := make(map[string]int, len(commonWords))
+	words := strings.FieldsFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for _, w := range words {
+		for _, lang := range wordIndex[w] {
+			score[lang]++
+		}
+	}
+	for _, r := range lower {
+		if lang, ok := distinctive[r]; ok {
+			score[lang]++
+		}
+	}
+	var (
+		best      string
+		top, next int
+	)
+	for lang, s := range score {
+		switch {
+		case s > top || (s == top && lang < best):
+			best, top, next = lang, s, top
+		case s > next:
+			next = s
+		}
+	}
+	if top < minHits || top == next {
+		return Undetermined
+	}
+	return best
+}
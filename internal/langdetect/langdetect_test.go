@@ -0,0 +1,47 @@
+package langdetect
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"empty", "", Undetermined},
+		{"too short", "ok", Undetermined},
+		{"emoji only", ":thumbsup: :tada:", Undetermined},
+		{"mention only", "<@U12345678>", Undetermined},
+		{"english", "I think that the build is broken again, can you have a look?", "en"},
+		{"english with markup", "<@U123> the deploy is done, see <https://example.com|the log> :tada:", "en"},
+		{"german", "Ich glaube, der Build ist wieder kaputt, kannst du dir das mal ansehen?", "de"},
+		{"french", "Je pense que le build est cassé, vous pouvez regarder avec nous?", "fr"},
+		{"spanish", "Creo que la compilación está rota, ¿puedes revisarlo por favor?", "es"},
+		{"portuguese", "Acho que o build não está funcionando, você pode olhar isso?", "pt"},
+		{"italian", "Penso che il build non sia corretto, anche questo è rotto", "it"},
+		{"dutch", "Ik denk dat het niet werkt, maar ik kijk er ook naar", "nl"},
+		{"swedish", "Jag tror att det inte fungerar, men vi har en lösning", "sv"},
+		{"french with cedilla", "Le garçon français est là", "fr"},
+		{"turkish with cedilla", "Bu çok güzel ama ben de hazır değilim", "tr"},
+		{"portuguese with cedilla", "Acho que a função não está pronta", "pt"},
+		{"polish", "Myślę, że to nie jest dobre, ale co z tym zrobić?", "pl"},
+		{"russian", "Кажется, сборка снова сломалась, посмотришь?", "ru"},
+		{"ukrainian", "Здається, збірка знову зламалася, подивишся?", "uk"},
+		{"japanese", "ビルドがまた壊れているようです。確認してもらえますか？", "ja"},
+		{"chinese", "构建好像又坏了，你能看一下吗？", "zh"},
+		{"korean", "빌드가 또 깨진 것 같아요. 확인해 주시겠어요?", "ko"},
+		{"greek", "Νομίζω ότι το build χάλασε ξανά", "el"},
+		{"hebrew", "נראה שהבנייה נשברה שוב", "he"},
+		{"arabic", "يبدو أن البناء معطل مرة أخرى", "ar"},
+		{"thai", "ดูเหมือนว่าการสร้างจะเสียอีกแล้ว", "th"},
+		{"code block is ignored", "```Кажется, сборка снова сломалась``` the build is broken and it is not fixed", "en"},
+		{"no common words", "Kubernetes Terraform Grafana", Undetermined},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.text); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
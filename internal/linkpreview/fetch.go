@@ -0,0 +1,172 @@
+package linkpreview
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// DefTimeout is the default timeout of fetching one page.
+	DefTimeout = 10 * time.Second
+	// maxHead is the maximum number of bytes read from the page, the
+	// metadata is expected within the head.
+	maxHead = 512 << 10
+	// userAgent is the User-Agent, that the sites serve the previews to.
+	userAgent = "Mozilla/5.0 (compatible; slackdump link preview)"
+)
+
+// Fetcher fetches the link previews.
+type Fetcher struct {
+	cl  *http.Client
+	now func() time.Time
+}
+
+// NewFetcher returns the fetcher, that uses the client cl, or the client
+// with the [DefTimeout], if cl is nil.
+func NewFetcher(cl *http.Client) *Fetcher {
+	if cl == nil {
+		cl = &http.Client{Timeout: DefTimeout}
+	}
+	return &Fetcher{cl: cl, now: time.Now}
+}
+
+// Fetch fetches the page at link and returns its preview.  The errors are
+// recorded in the preview.
+func (f *Fetcher) Fetch(ctx context.Context, link string) Preview {
+	p := Preview{URL: link, FetchedAt: f.now().UTC()}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	resp, err := f.cl.Do(req)
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	defer resp.Body.Close()
+	p.Status = resp.StatusCode
+	if resp.StatusCode != http.StatusOK {
+		p.Error = resp.Status
+		return p
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt != "" && mt != "text/html" && mt != "application/xhtml+xml" {
+		// not a page, i.e. an image or a document, there's no metadata.
+		return p
+	}
+	meta := parseHead(io.LimitReader(resp.Body, maxHead))
+	p.Title = first(meta["og:title"], meta["twitter:title"], meta["title"])
+	p.Description = first(meta["og:description"], meta["twitter:description"], meta["description"])
+	p.SiteName = meta["og:site_name"]
+	if img := first(meta["og:image"], meta["twitter:image"]); img != "" {
+		p.Image = resolve(resp.Request.URL, img)
+	}
+	return p
+}
+
+// FetchAll fetches the previews of the links, with at most n fetches at a
+// time.  The previews are returned in the order of the links.
+func (f *Fetcher) FetchAll(ctx context.Context, links []string, n int) []Preview {
+	if n < 1 {
+		n = 1
+	}
+	var (
+		ret = make([]Preview, len(links))
+		sem = make(chan struct{}, n)
+		wg  sync.WaitGroup
+	)
+	for i, link := range links {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			ret[i] = f.Fetch(ctx, link)
+		}()
+	}
+	wg.Wait()
+	return ret
+}
+
+// parseHead returns the title and the meta tags of the page head, keyed by
+// the property or name, i.e. "og:title".  The title is keyed by "title".
+func parseHead(r io.Reader) map[string]string {
+	meta := make(map[string]string)
+	z := html.NewTokenizer(r)
+	inTitle := false
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return meta
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tn, hasAttr := z.TagName()
+			switch string(tn) {
+			case "body":
+				return meta
+			case "title":
+				inTitle = true
+			case "meta":
+				if !hasAttr {
+					continue
+				}
+				var key, content string
+				for more := true; more; {
+					var k, v []byte
+					k, v, more = z.TagAttr()
+					switch string(k) {
+					case "property", "name":
+						key = strings.ToLower(string(v))
+					case "content":
+						content = strings.TrimSpace(string(v))
+					}
+				}
+				if _, ok := meta[key]; key != "" && content != "" && !ok {
+					meta[key] = content
+				}
+			}
+		case html.TextToken:
+			if inTitle {
+				if _, ok := meta["title"]; !ok {
+					meta["title"] = strings.TrimSpace(string(z.Text()))
+				}
+			}
+		case html.EndTagToken:
+			tn, _ := z.TagName()
+			switch string(tn) {
+			case "title":
+				inTitle = false
+			case "head":
+				return meta
+			}
+		}
+	}
+}
+
+// first returns the first non-empty string.
+func first(ss ...string) string {
+	for _, s := range ss {
+		if s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// resolve returns the reference ref resolved against the page URL base.
+func resolve(base *url.URL, ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil || base == nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
@@ -0,0 +1,156 @@
+// Package linkpreview fetches and stores the snapshots of the external links
+// referenced in the messages, i.e. the page title, description and image, so
+// that the archive keeps the context, when the links rot.
+package linkpreview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+)
+
+// Filename is the name of the sidecar file with the link previews.
+const Filename = "link_previews.json"
+
+// Preview is the snapshot of the linked page.
+type Preview struct {
+	URL         string    `json:"url"`
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Image       string    `json:"image,omitempty"`
+	SiteName    string    `json:"site_name,omitempty"`
+	FetchedAt   time.Time `json:"fetched_at"`
+	// Status is the HTTP status code of the response, if any.
+	Status int `json:"status,omitempty"`
+	// Error is the reason, why the page could not be fetched.
+	Error string `json:"error,omitempty"`
+}
+
+// reLink matches the links in the message text, that Slack formats as
+// <URL> or <URL|label>.
+var reLink = regexp.MustCompile(`<(https?://[^|>\s]+)(?:\|[^>]*)?>`)
+
+// URLs returns the external links referenced in the message m text and
+// attachments, in the order of appearance, without duplicates.  Links to
+// Slack are skipped.
+func URLs(m *slack.Message) []string {
+	var (
+		ret  []string
+		seen = make(map[string]struct{})
+	)
+	add := func(s string) {
+		if _, ok := seen[s]; ok || !isExternal(s) {
+			return
+		}
+		seen[s] = struct{}{}
+		ret = append(ret, s)
+	}
+	for _, sm := range reLink.FindAllStringSubmatch(m.Text, -1) {
+		add(sm[1])
+	}
+	for i := range m.Attachments {
+		add(m.Attachments[i].FromURL)
+		add(m.Attachments[i].OriginalURL)
+	}
+	return ret
+}
+
+// isExternal returns true, if s is the http or https URL of the host, that
+// is not Slack.
+func isExternal(s string) bool {
+	if s == "" {
+		return false
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host != "slack.com" && !strings.HasSuffix(host, ".slack.com")
+}
+
+// Collector collects the unique links from the chunks.
+type Collector struct {
+	seen map[string]struct{}
+	urls []string
+}
+
+// AddMessages adds the links of the messages mm.
+func (c *Collector) AddMessages(mm ...slack.Message) {
+	if c.seen == nil {
+		c.seen = make(map[string]struct{})
+	}
+	for i := range mm {
+		for _, u := range URLs(&mm[i]) {
+			if _, ok := c.seen[u]; ok {
+				continue
+			}
+			c.seen[u] = struct{}{}
+			c.urls = append(c.urls, u)
+		}
+	}
+}
+
+// AddChunk adds the links of the messages in the chunk ch.
+func (c *Collector) AddChunk(ch *chunk.Chunk) {
+	switch ch.Type {
+	case chunk.CMessages, chunk.CThreadMessages:
+		c.AddMessages(ch.Messages...)
+	}
+}
+
+// URLs returns the collected links, sorted.
+func (c *Collector) URLs() []string {
+	ret := append([]string(nil), c.urls...)
+	sort.Strings(ret)
+	return ret
+}
+
+// FromChunks collects the links from the messages of all channels in the
+// chunk directory cd.
+func FromChunks(ctx context.Context, cd *chunk.Directory) ([]string, error) {
+	channels, err := cd.Channels()
+	if err != nil {
+		return nil, err
+	}
+	var c Collector
+	for _, ch := range channels {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		f, err := cd.Open(chunk.ToFileID(ch.ID, "", false))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		err = f.ForEach(func(ch *chunk.Chunk) error {
+			c.AddChunk(ch)
+			return nil
+		})
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.URLs(), nil
+}
+
+// Write writes the previews pp to w as the JSON array.
+func Write(w io.Writer, pp []Preview) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(pp)
+}
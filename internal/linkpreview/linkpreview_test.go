@@ -0,0 +1,150 @@
+package linkpreview
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rusq/slack"
+)
+
+func TestURLs(t *testing.T) {
+	tests := []struct {
+		name string
+		m    slack.Message
+		want []string
+	}{
+		{
+			"text links",
+			slack.Message{Msg: slack.Msg{Text: "see <https://example.com/a|the page> and <http://example.org/b>, again <https://example.com/a>"}},
+			[]string{"https://example.com/a", "http://example.org/b"},
+		},
+		{
+			"slack links are skipped",
+			slack.Message{Msg: slack.Msg{Text: "<https://acme.slack.com/archives/C1/p1> <https://slack.com/help> <mailto:bob@example.com>"}},
+			nil,
+		},
+		{
+			"attachments",
+			slack.Message{Msg: slack.Msg{
+				Text:        "<https://example.com/a>",
+				Attachments: []slack.Attachment{{FromURL: "https://example.com/a"}, {OriginalURL: "https://example.net/c"}},
+			}},
+			[]string{"https://example.com/a", "https://example.net/c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := URLs(&tt.m); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("URLs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollector(t *testing.T) {
+	var c Collector
+	c.AddMessages(
+		slack.Message{Msg: slack.Msg{Text: "<https://b.example.com>"}},
+		slack.Message{Msg: slack.Msg{Text: "<https://a.example.com> <https://b.example.com>"}},
+	)
+	want := []string{"https://a.example.com", "https://b.example.com"}
+	if got := c.URLs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("URLs() = %v, want %v", got, want)
+	}
+}
+
+const testPage = `<!DOCTYPE html>
+<html><head>
+<title>Fallback &amp; title</title>
+<meta name="description" content="Plain description">
+<meta property="og:title" content="Open Graph title">
+<meta property="og:image" content="/img/cover.png">
+<meta property="og:site_name" content="Example">
+</head><body><meta property="og:description" content="not in head"></body></html>`
+
+func TestFetcher_Fetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(testPage))
+	})
+	mux.HandleFunc("/title", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><title>Just &lt;title&gt;</title></head></html>`))
+	})
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	fetchedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	f := NewFetcher(srv.Client())
+	f.now = func() time.Time { return fetchedAt }
+
+	tests := []struct {
+		name    string
+		path    string
+		want    Preview
+		wantErr bool
+	}{
+		{
+			"open graph",
+			"/page",
+			Preview{Title: "Open Graph title", Description: "Plain description", Image: srv.URL + "/img/cover.png", SiteName: "Example", Status: 200},
+			false,
+		},
+		{
+			"title only",
+			"/title",
+			Preview{Title: "Just <title>", Status: 200},
+			false,
+		},
+		{
+			"not a page",
+			"/image.png",
+			Preview{Status: 200},
+			false,
+		},
+		{
+			"not found",
+			"/missing",
+			Preview{Status: 404},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := f.Fetch(context.Background(), srv.URL+tt.path)
+			if (got.Error != "") != tt.wantErr {
+				t.Fatalf("Fetch() error = %q, wantErr %v", got.Error, tt.wantErr)
+			}
+			got.Error = ""
+			tt.want.URL = srv.URL + tt.path
+			tt.want.FetchedAt = fetchedAt
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fetch() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetcher_FetchAll(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<title>" + strings.TrimPrefix(r.URL.Path, "/") + "</title>"))
+	}))
+	defer srv.Close()
+
+	links := []string{srv.URL + "/a", srv.URL + "/b", srv.URL + "/c"}
+	pp := NewFetcher(srv.Client()).FetchAll(context.Background(), links, 2)
+	for i, p := range pp {
+		if p.URL != links[i] || p.Title != links[i][len(srv.URL)+1:] {
+			t.Errorf("FetchAll()[%d] = %+v", i, p)
+		}
+	}
+}
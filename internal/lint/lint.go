@@ -0,0 +1,313 @@
+// Package lint checks the archive for the common fidelity problems, such as
+// the messages of the users, that are missing from the archive, or the
+// thread replies without the parent message.
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"time"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+	"github.com/rusq/slackdump/v3/internal/source"
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+// Check is the name of the check, that found the issue.
+type Check string
+
+const (
+	// CheckEmptyChannel reports the channels without messages.
+	CheckEmptyChannel Check = "empty-channel"
+	// CheckMissingUser reports the message authors, that are not in the
+	// user list of the archive.
+	CheckMissingUser Check = "missing-user"
+	// CheckOrphanThread reports the thread replies, whose parent message is
+	// not in the archive.
+	CheckOrphanThread Check = "orphan-thread"
+	// CheckOutOfRange reports the messages outside of the requested time
+	// range.
+	CheckOutOfRange Check = "out-of-range"
+	// CheckZeroByteFile reports the downloaded attachments of zero size.
+	CheckZeroByteFile Check = "zero-byte-file"
+)
+
+// Issue is the problem found in the archive.
+type Issue struct {
+	Check    Check    `json:"check"`
+	Severity Severity `json:"severity"`
+	// Channel is the ID of the channel, where the issue was found, if any.
+	Channel string `json:"channel,omitempty"`
+	// TS is the timestamp of the (first) message with the issue, if any.
+	TS      string `json:"ts,omitempty"`
+	Message string `json:"message"`
+}
+
+// Options are the lint options.
+type Options struct {
+	// Oldest and Latest is the time range, that was requested, when the
+	// archive was created.  Zero values mean that the range is not limited.
+	Oldest time.Time
+	Latest time.Time
+}
+
+// inRange returns true if t is within the requested time range.
+func (o Options) inRange(t time.Time) bool {
+	return (o.Oldest.IsZero() || !t.Before(o.Oldest)) && (o.Latest.IsZero() || !t.After(o.Latest))
+}
+
+type linter struct {
+	src  source.Sourcer
+	opts Options
+	// users is the set of the user IDs in the archive, nil, if the archive
+	// has no users.
+	users   map[string]bool
+	missing map[string]*missingUser
+	files   map[string]bool // checked file IDs
+	issues  []Issue
+}
+
+type missingUser struct {
+	channel string
+	ts      string
+	count   int
+}
+
+// Run runs all checks on the source and returns the report.
+func Run(ctx context.Context, src source.Sourcer, opts Options) (*Report, error) {
+	l := &linter{
+		src:     src,
+		opts:    opts,
+		missing: make(map[string]*missingUser),
+		files:   make(map[string]bool),
+	}
+	users, err := src.Users()
+	if err != nil && !notFound(err) {
+		return nil, err
+	}
+	if len(users) > 0 {
+		l.users = make(map[string]bool, len(users))
+		for _, u := range users {
+			l.users[u.ID] = true
+		}
+	} else {
+		l.add(Issue{Check: CheckMissingUser, Severity: Info, Message: "archive has no users, check skipped"})
+	}
+
+	channels, err := src.Channels()
+	if err != nil {
+		return nil, err
+	}
+	for i := range channels {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := l.channel(&channels[i]); err != nil {
+			return nil, fmt.Errorf("channel %s: %w", channels[i].ID, err)
+		}
+	}
+	for id, mu := range l.missing {
+		l.add(Issue{
+			Check:    CheckMissingUser,
+			Severity: Warning,
+			Channel:  mu.channel,
+			TS:       mu.ts,
+			Message:  fmt.Sprintf("user %s is not in the archive, referenced by %d message(s)", id, mu.count),
+		})
+	}
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		a, b := l.issues[i], l.issues[j]
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if a.Channel != b.Channel {
+			return a.Channel < b.Channel
+		}
+		return a.TS < b.TS
+	})
+	return &Report{Source: src.Name(), Issues: l.issues}, nil
+}
+
+func (l *linter) add(is Issue) {
+	l.issues = append(l.issues, is)
+}
+
+// replySource is implemented by the sources, that keep the thread replies
+// alongside the channel messages, i.e. Slack export, so that the replies
+// without the parent message can be found.
+type replySource interface {
+	ThreadReplies(channelID string) ([]slack.Message, error)
+}
+
+// channel runs the checks on the messages of the channel ch.
+func (l *linter) channel(ch *slack.Channel) error {
+	msgs, err := l.src.AllMessages(ch.ID)
+	if err != nil && !notFound(err) {
+		return err
+	}
+	var orphans []slack.Message
+	if rs, ok := l.src.(replySource); ok {
+		replies, err := rs.ThreadReplies(ch.ID)
+		if err != nil && !notFound(err) {
+			return err
+		}
+		seen := make(map[string]bool, len(msgs))
+		for i := range msgs {
+			seen[msgs[i].Timestamp] = true
+		}
+		for _, r := range replies {
+			if !seen[r.ThreadTimestamp] {
+				orphans = append(orphans, r)
+			}
+		}
+	}
+	if len(msgs) == 0 && len(orphans) == 0 {
+		l.emptyChannel(ch)
+		return nil
+	}
+
+	var (
+		seen     = make(map[string]bool, len(msgs))
+		outside  int
+		firstOut string
+	)
+	check := func(m *slack.Message) {
+		l.user(ch.ID, m)
+		l.attachments(ch.ID, m)
+		if t, err := structures.ParseSlackTS(m.Timestamp); err == nil && !l.opts.inRange(t) {
+			if outside == 0 {
+				firstOut = m.Timestamp
+			}
+			outside++
+		}
+	}
+	for i := range msgs {
+		seen[msgs[i].Timestamp] = true
+	}
+	for i := range msgs {
+		m := &msgs[i]
+		check(m)
+		if m.ThreadTimestamp != "" && m.ThreadTimestamp != m.Timestamp && !seen[m.ThreadTimestamp] {
+			l.orphan(ch.ID, m)
+		}
+		if !structures.IsThreadStart(m) || m.LatestReply == structures.LatestReplyNoReplies {
+			continue
+		}
+		replies, err := l.src.AllThreadMessages(ch.ID, m.ThreadTimestamp)
+		if err != nil {
+			if notFound(err) {
+				continue
+			}
+			return err
+		}
+		for j := range replies {
+			if r := &replies[j]; r.Timestamp != m.Timestamp && !seen[r.Timestamp] {
+				check(r)
+			}
+		}
+	}
+	for i := range orphans {
+		check(&orphans[i])
+		l.orphan(ch.ID, &orphans[i])
+	}
+	if outside > 0 {
+		l.add(Issue{
+			Check:    CheckOutOfRange,
+			Severity: Warning,
+			Channel:  ch.ID,
+			TS:       firstOut,
+			Message:  fmt.Sprintf("%d message(s) outside of the requested time range", outside),
+		})
+	}
+	return nil
+}
+
+// emptyChannel reports the channel without messages.  It is an error, if
+// Slack reported the latest message of the channel within the requested
+// range, otherwise the channel may be empty indeed.
+func (l *linter) emptyChannel(ch *slack.Channel) {
+	is := Issue{Check: CheckEmptyChannel, Severity: Info, Channel: ch.ID, Message: "channel has no messages"}
+	if ch.Latest != nil && ch.Latest.Timestamp != "" {
+		if t, err := structures.ParseSlackTS(ch.Latest.Timestamp); err == nil && l.opts.inRange(t) {
+			is.Severity = Error
+			is.TS = ch.Latest.Timestamp
+			is.Message = "channel has no messages, but its latest message is in the requested range"
+		}
+	}
+	l.add(is)
+}
+
+// user records the message author, that is not in the archive.
+func (l *linter) user(channelID string, m *slack.Message) {
+	if l.users == nil || m.User == "" || m.User == "USLACKBOT" || l.users[m.User] {
+		return
+	}
+	mu, ok := l.missing[m.User]
+	if !ok {
+		mu = &missingUser{channel: channelID, ts: m.Timestamp}
+		l.missing[m.User] = mu
+	}
+	mu.count++
+}
+
+// orphan reports the thread reply m without the parent message.  If the
+// parent is older than the requested range, it is expected to be missing.
+func (l *linter) orphan(channelID string, m *slack.Message) {
+	is := Issue{
+		Check:    CheckOrphanThread,
+		Severity: Error,
+		Channel:  channelID,
+		TS:       m.Timestamp,
+		Message:  "parent message " + m.ThreadTimestamp + " of the thread reply is missing",
+	}
+	if t, err := structures.ParseSlackTS(m.ThreadTimestamp); err == nil && !l.opts.inRange(t) {
+		is.Severity = Info
+		is.Message += ", it is outside of the requested time range"
+	}
+	l.add(is)
+}
+
+// attachments reports the downloaded files of the message m, that are empty.
+// The files, that were not downloaded, are not reported.
+func (l *linter) attachments(channelID string, m *slack.Message) {
+	fsys := l.src.FS()
+	if fsys == nil {
+		return
+	}
+	for i := range m.Files {
+		f := &m.Files[i]
+		if f.ID == "" || l.files[f.ID] {
+			continue
+		}
+		l.files[f.ID] = true
+		path, err := l.src.File(f.ID, f.Name)
+		if err != nil {
+			continue
+		}
+		fi, err := fs.Stat(fsys, path)
+		if err != nil || fi.Size() > 0 {
+			continue
+		}
+		is := Issue{
+			Check:    CheckZeroByteFile,
+			Severity: Warning,
+			Channel:  channelID,
+			TS:       m.Timestamp,
+			Message:  fmt.Sprintf("file %s (%s) is empty", f.ID, path),
+		}
+		if f.Size > 0 {
+			is.Severity = Error
+			is.Message = fmt.Sprintf("file %s (%s) is empty, expected %d bytes", f.ID, path, f.Size)
+		}
+		l.add(is)
+	}
+}
+
+func notFound(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, chunk.ErrNotFound)
+}
@@ -0,0 +1,197 @@
+package lint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/fs"
+	"path"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource is the source.Sourcer with the data in memory.
+type fakeSource struct {
+	channels []slack.Channel
+	users    []slack.User
+	messages map[string][]slack.Message
+	threads  map[string][]slack.Message // key is "channel:thread_ts"
+	fsys     fstest.MapFS
+}
+
+func (s *fakeSource) Name() string                               { return "fake" }
+func (s *fakeSource) Type() string                               { return "fake" }
+func (s *fakeSource) Channels() ([]slack.Channel, error)         { return s.channels, nil }
+func (s *fakeSource) Users() ([]slack.User, error)               { return s.users, nil }
+func (s *fakeSource) ChannelInfo(string) (*slack.Channel, error) { return nil, fs.ErrNotExist }
+func (s *fakeSource) FS() fs.FS                                  { return s.fsys }
+
+func (s *fakeSource) AllMessages(channelID string) ([]slack.Message, error) {
+	mm, ok := s.messages[channelID]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return mm, nil
+}
+
+func (s *fakeSource) AllThreadMessages(channelID, threadID string) ([]slack.Message, error) {
+	mm, ok := s.threads[channelID+":"+threadID]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return mm, nil
+}
+
+func (s *fakeSource) File(fileID string, filename string) (string, error) {
+	p := path.Join("__uploads", fileID, filename)
+	if _, ok := s.fsys[p]; !ok {
+		return "", fs.ErrNotExist
+	}
+	return p, nil
+}
+
+func msg(ts, threadTS, user string) slack.Message {
+	return slack.Message{Msg: slack.Msg{Timestamp: ts, ThreadTimestamp: threadTS, User: user}}
+}
+
+func testSource() *fakeSource {
+	parent := msg("1700000100.000000", "1700000100.000000", "U1")
+	parent.ReplyCount = 1
+	parent.LatestReply = "1700000200.000000"
+	withFiles := msg("1700000300.000000", "", "U1")
+	withFiles.Files = []slack.File{
+		{ID: "F1", Name: "broken.png", Size: 100},
+		{ID: "F2", Name: "ok.txt", Size: 2},
+		{ID: "F3", Name: "not-downloaded.txt", Size: 5},
+	}
+	return &fakeSource{
+		channels: []slack.Channel{
+			{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}},
+			{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C2", Latest: &slack.Message{Msg: slack.Msg{Timestamp: "1700000500.000000"}}}}},
+			{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "D1"}}},
+		},
+		users: []slack.User{{ID: "U1"}},
+		messages: map[string][]slack.Message{
+			"C1": {
+				parent,
+				withFiles,
+				msg("1700000400.000000", "1690000000.000000", "U1"), // orphan, parent before the range
+				msg("1700000450.000000", "1700000050.000000", "U1"), // orphan, parent in range
+				msg("1600000000.000000", "", "U1"),                  // out of range
+			},
+		},
+		threads: map[string][]slack.Message{
+			"C1:1700000100.000000": {parent, msg("1700000200.000000", "1700000100.000000", "U9")},
+		},
+		fsys: fstest.MapFS{
+			"__uploads/F1/broken.png": &fstest.MapFile{},
+			"__uploads/F2/ok.txt":     &fstest.MapFile{Data: []byte("ok")},
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	opts := Options{Oldest: time.Unix(1700000000, 0), Latest: time.Unix(1800000000, 0)}
+	r, err := Run(context.Background(), testSource(), opts)
+	require.NoError(t, err)
+	want := []Issue{
+		{Check: CheckZeroByteFile, Severity: Error, Channel: "C1", TS: "1700000300.000000", Message: "file F1 (__uploads/F1/broken.png) is empty, expected 100 bytes"},
+		{Check: CheckOrphanThread, Severity: Error, Channel: "C1", TS: "1700000450.000000", Message: "parent message 1700000050.000000 of the thread reply is missing"},
+		{Check: CheckEmptyChannel, Severity: Error, Channel: "C2", TS: "1700000500.000000", Message: "channel has no messages, but its latest message is in the requested range"},
+		{Check: CheckOutOfRange, Severity: Warning, Channel: "C1", TS: "1600000000.000000", Message: "1 message(s) outside of the requested time range"},
+		{Check: CheckMissingUser, Severity: Warning, Channel: "C1", TS: "1700000200.000000", Message: "user U9 is not in the archive, referenced by 1 message(s)"},
+		{Check: CheckOrphanThread, Severity: Info, Channel: "C1", TS: "1700000400.000000", Message: "parent message 1690000000.000000 of the thread reply is missing, it is outside of the requested time range"},
+		{Check: CheckEmptyChannel, Severity: Info, Channel: "D1", Message: "channel has no messages"},
+	}
+	assert.Equal(t, want, r.Issues)
+	assert.Equal(t, 3, r.Count(Error))
+	assert.Equal(t, 5, r.Count(Warning))
+	assert.Len(t, r.Filter(Warning).Issues, 5)
+}
+
+func TestRun_noRange(t *testing.T) {
+	r, err := Run(context.Background(), testSource(), Options{})
+	require.NoError(t, err)
+	for _, is := range r.Issues {
+		assert.NotEqual(t, CheckOutOfRange, is.Check)
+		if is.Check == CheckOrphanThread {
+			assert.Equal(t, Error, is.Severity, "without the range every orphan is an error")
+		}
+	}
+}
+
+func TestRun_noUsers(t *testing.T) {
+	src := testSource()
+	src.users = nil
+	r, err := Run(context.Background(), src, Options{})
+	require.NoError(t, err)
+	var n int
+	for _, is := range r.Issues {
+		if is.Check == CheckMissingUser {
+			n++
+			assert.Equal(t, Info, is.Severity)
+		}
+	}
+	assert.Equal(t, 1, n, "missing user check must be skipped")
+}
+
+// fakeExport keeps the thread replies alongside the channel messages.
+type fakeExport struct {
+	*fakeSource
+	replies map[string][]slack.Message
+}
+
+func (s *fakeExport) ThreadReplies(channelID string) ([]slack.Message, error) {
+	return s.replies[channelID], nil
+}
+
+func TestRun_threadReplies(t *testing.T) {
+	src := &fakeExport{
+		fakeSource: &fakeSource{
+			channels: []slack.Channel{{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}}},
+			users:    []slack.User{{ID: "U1"}},
+		},
+		replies: map[string][]slack.Message{
+			"C1": {msg("1700000200.000000", "1700000100.000000", "U1")},
+		},
+	}
+	r, err := Run(context.Background(), src, Options{})
+	require.NoError(t, err)
+	want := []Issue{
+		{Check: CheckOrphanThread, Severity: Error, Channel: "C1", TS: "1700000200.000000", Message: "parent message 1700000100.000000 of the thread reply is missing"},
+	}
+	assert.Equal(t, want, r.Issues, "channel with only orphan replies is not empty")
+}
+
+func TestReport_Write(t *testing.T) {
+	r := &Report{Source: "fake", Issues: []Issue{
+		{Check: CheckEmptyChannel, Severity: Error, Channel: "C2", Message: "channel has no messages"},
+		{Check: CheckMissingUser, Severity: Info, Message: "archive has no users, check skipped"},
+	}}
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteText(&buf))
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, "error     empty-channel  C2       -   channel has no messages", lines[1])
+	assert.Equal(t, "1 error(s), 0 warning(s), 1 info", lines[4])
+
+	buf.Reset()
+	require.NoError(t, r.WriteJSON(&buf))
+	var got Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, r, &got)
+	assert.Contains(t, buf.String(), `"severity": "error"`)
+}
+
+func TestParseSeverity(t *testing.T) {
+	s, err := ParseSeverity("WARNING")
+	require.NoError(t, err)
+	assert.Equal(t, Warning, s)
+	_, err = ParseSeverity("fatal")
+	assert.Error(t, err)
+}
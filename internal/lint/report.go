@@ -0,0 +1,115 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Severity is the severity of the issue.
+type Severity int
+
+const (
+	// Info is the issue, that is likely expected, i.e. the empty DM.
+	Info Severity = iota
+	// Warning is the issue, that may indicate the incomplete archive.
+	Warning
+	// Error is the issue, that indicates the incomplete archive.
+	Error
+)
+
+var severityNames = [...]string{Info: "info", Warning: "warning", Error: "error"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity parses the severity name, i.e. "warning".
+func ParseSeverity(s string) (Severity, error) {
+	for i, name := range severityNames {
+		if strings.EqualFold(s, name) {
+			return Severity(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q, must be one of: %s", s, strings.Join(severityNames[:], ", "))
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(b []byte) error {
+	v, err := ParseSeverity(string(b))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// Report is the result of the lint.  Issues are sorted by severity, most
+// severe first.
+type Report struct {
+	Source string  `json:"source"`
+	Issues []Issue `json:"issues"`
+}
+
+// Count returns the number of issues with the severity min or higher.
+func (r *Report) Count(min Severity) int {
+	var n int
+	for _, is := range r.Issues {
+		if is.Severity >= min {
+			n++
+		}
+	}
+	return n
+}
+
+// Filter returns the report with the issues of the severity min or higher.
+func (r *Report) Filter(min Severity) *Report {
+	fr := &Report{Source: r.Source, Issues: []Issue{}}
+	for _, is := range r.Issues {
+		if is.Severity >= min {
+			fr.Issues = append(fr.Issues, is)
+		}
+	}
+	return fr
+}
+
+// WriteText writes the report as the table.
+func (r *Report) WriteText(w io.Writer) error {
+	if len(r.Issues) == 0 {
+		_, err := fmt.Fprintln(w, "No issues found.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "SEVERITY\tCHECK\tCHANNEL\tTS\tMESSAGE")
+	for _, is := range r.Issues {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", is.Severity, is.Check, dash(is.Channel), dash(is.TS), is.Message)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d error(s), %d warning(s), %d info\n",
+		r.Count(Error), r.Count(Warning)-r.Count(Error), r.Count(Info)-r.Count(Warning))
+	return err
+}
+
+// WriteJSON writes the report in JSON format.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
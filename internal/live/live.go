@@ -0,0 +1,259 @@
+// Package live records the messages, that are delivered in real time over
+// the Slack Socket Mode connection, as they arrive, so that the messages
+// posted between the periodic dumps are not lost to the retention policies.
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/rusq/slack"
+	"github.com/rusq/slack/socketmode"
+
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+// Message subtypes, that require special handling.
+const (
+	subtypeChanged   = "message_changed"
+	subtypeDeleted   = "message_deleted"
+	subtypeReplied   = "message_replied"
+	subtypeBroadcast = "thread_broadcast"
+)
+
+// Listener passes the messages from the Socket Mode events to the
+// processor, i.e. [chunk.Recorder].  Channel messages are passed to
+// Messages, and the thread replies to ThreadMessages.  As the thread parent
+// is not delivered with the reply, the parent passed to ThreadMessages has
+// only the timestamps set.  Edited messages are passed as new messages with
+// the same timestamp, deletions are ignored, so that the recording retains
+// the deleted messages.  If the processor implements
+// [processor.MessageHistorian], the prior versions of the edited messages and
+// the tombstones of the deleted messages are passed to it as well.
+type Listener struct {
+	proc     processor.Messenger
+	channels map[string]bool
+	lg       *slog.Logger
+}
+
+// Option is the Listener option.
+type Option func(*Listener)
+
+// WithChannels limits the recorded messages to the channels with the given
+// IDs.  By default, messages from all channels are recorded.
+func WithChannels(ids ...string) Option {
+	return func(l *Listener) {
+		if len(ids) == 0 {
+			return
+		}
+		l.channels = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			l.channels[id] = true
+		}
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(lg *slog.Logger) Option {
+	return func(l *Listener) {
+		if lg != nil {
+			l.lg = lg
+		}
+	}
+}
+
+// New creates a new Listener, that passes the messages to proc.
+func New(proc processor.Messenger, opts ...Option) *Listener {
+	l := &Listener{
+		proc: proc,
+		lg:   slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Run connects to Slack with the Socket Mode client and processes the
+// events until the context is cancelled.  Each event is acknowledged, once
+// it is processed.  If the processor fails, Run returns the error, and the
+// event is not acknowledged, so that Slack delivers it again.
+func (l *Listener) Run(ctx context.Context, client *socketmode.Client) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- client.RunContext(ctx)
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case err := <-errC:
+			if err == nil || errors.Is(err, context.Canceled) {
+				return context.Cause(ctx)
+			}
+			return fmt.Errorf("socket mode: %w", err)
+		case evt := <-client.Events:
+			switch evt.Type {
+			case socketmode.EventTypeConnecting:
+				l.lg.InfoContext(ctx, "connecting to Slack")
+			case socketmode.EventTypeConnected:
+				l.lg.InfoContext(ctx, "connected, listening for messages")
+			case socketmode.EventTypeConnectionError:
+				l.lg.WarnContext(ctx, "connection error, reconnecting", "error", evt.Data)
+			case socketmode.EventTypeInvalidAuth:
+				return errors.New("socket mode: invalid app-level token")
+			case socketmode.EventTypeEventsAPI:
+				if evt.Request == nil {
+					continue
+				}
+				if err := l.HandlePayload(ctx, evt.Request.Payload); err != nil {
+					return err
+				}
+				l.ack(ctx, client, evt.Request)
+			default:
+				if evt.Request != nil && evt.Request.EnvelopeID != "" {
+					// interactive and slash command requests are not
+					// recorded, but must be acknowledged.
+					l.ack(ctx, client, evt.Request)
+				}
+			}
+		}
+	}
+}
+
+func (l *Listener) ack(ctx context.Context, client *socketmode.Client, req *socketmode.Request) {
+	if err := client.AckCtx(ctx, req.EnvelopeID, nil); err != nil {
+		l.lg.WarnContext(ctx, "unable to acknowledge the event", "envelope_id", req.EnvelopeID, "error", err)
+	}
+}
+
+// callback is the Events API callback payload.
+type callback struct {
+	Type  string          `json:"type"`
+	Event json.RawMessage `json:"event"`
+}
+
+// messageEvent holds the fields of the message event, that are not in the
+// [slack.Message].
+type messageEvent struct {
+	Type    string `json:"type"`
+	SubType string `json:"subtype"`
+	Channel string `json:"channel"`
+	// Message is the new version of the message for the message_changed
+	// and message_replied subtypes.
+	Message *slack.Message `json:"message"`
+	// PreviousMessage is the prior version of the message for the
+	// message_changed and message_deleted subtypes.
+	PreviousMessage *slack.Message `json:"previous_message"`
+	DeletedTS       string         `json:"deleted_ts"`
+}
+
+// HandlePayload processes the payload of the Events API request.  Events
+// other than messages are ignored.
+func (l *Listener) HandlePayload(ctx context.Context, payload json.RawMessage) error {
+	var cb callback
+	if err := json.Unmarshal(payload, &cb); err != nil {
+		return fmt.Errorf("invalid event payload: %w", err)
+	}
+	if cb.Type != "event_callback" || len(cb.Event) == 0 {
+		return nil
+	}
+	var ev messageEvent
+	if err := json.Unmarshal(cb.Event, &ev); err != nil {
+		return fmt.Errorf("invalid event: %w", err)
+	}
+	if ev.Type != "message" || ev.Channel == "" {
+		return nil
+	}
+	if l.channels != nil && !l.channels[ev.Channel] {
+		return nil
+	}
+	lg := l.lg.With("channel_id", ev.Channel, "subtype", ev.SubType)
+
+	var msg slack.Message
+	switch ev.SubType {
+	case subtypeDeleted:
+		lg.DebugContext(ctx, "message deleted, keeping the recorded copy", "ts", ev.DeletedTS)
+		return l.deleted(ctx, ev)
+	case subtypeChanged, subtypeReplied:
+		if ev.Message == nil {
+			return nil
+		}
+		if ev.SubType == subtypeChanged {
+			if err := l.edited(ctx, ev); err != nil {
+				return err
+			}
+		}
+		msg = *ev.Message
+	default:
+		if err := json.Unmarshal(cb.Event, &msg); err != nil {
+			return fmt.Errorf("invalid message event: %w", err)
+		}
+	}
+	msg.Channel = ev.Channel
+	lg.DebugContext(ctx, "message", "ts", msg.Timestamp, "thread_ts", msg.ThreadTimestamp)
+	return l.record(ctx, ev.Channel, msg)
+}
+
+// record passes the message to the processor.
+func (l *Listener) record(ctx context.Context, channelID string, msg slack.Message) error {
+	isReply := msg.ThreadTimestamp != "" && msg.ThreadTimestamp != msg.Timestamp
+	if !isReply || msg.SubType == subtypeBroadcast {
+		if err := l.proc.Messages(ctx, channelID, 0, false, []slack.Message{msg}); err != nil {
+			return fmt.Errorf("error recording message %s:%s: %w", channelID, msg.Timestamp, err)
+		}
+	}
+	if !isReply {
+		return nil
+	}
+	parent := slack.Message{Msg: slack.Msg{
+		Channel:         channelID,
+		Timestamp:       msg.ThreadTimestamp,
+		ThreadTimestamp: msg.ThreadTimestamp,
+	}}
+	if err := l.proc.ThreadMessages(ctx, channelID, parent, false, false, []slack.Message{msg}); err != nil {
+		return fmt.Errorf("error recording thread message %s:%s: %w", channelID, msg.Timestamp, err)
+	}
+	return nil
+}
+
+// edited passes the prior version of the edited message to the processor,
+// if it preserves the message history.
+func (l *Listener) edited(ctx context.Context, ev messageEvent) error {
+	h, ok := l.proc.(processor.MessageHistorian)
+	if !ok || ev.PreviousMessage == nil {
+		return nil
+	}
+	prev := *ev.PreviousMessage
+	prev.Channel = ev.Channel
+	if err := h.MessageEdited(ctx, ev.Channel, prev); err != nil {
+		return fmt.Errorf("error recording message edit %s:%s: %w", ev.Channel, prev.Timestamp, err)
+	}
+	return nil
+}
+
+// deleted passes the tombstone of the deleted message to the processor, if
+// it preserves the message history.  If the event has no prior version of
+// the message, the tombstone has only the timestamp.
+func (l *Listener) deleted(ctx context.Context, ev messageEvent) error {
+	h, ok := l.proc.(processor.MessageHistorian)
+	if !ok || ev.DeletedTS == "" {
+		return nil
+	}
+	var prev slack.Message
+	if ev.PreviousMessage != nil {
+		prev = *ev.PreviousMessage
+	}
+	prev.Channel = ev.Channel
+	prev.Timestamp = ev.DeletedTS
+	if err := h.MessageDeleted(ctx, ev.Channel, prev); err != nil {
+		return fmt.Errorf("error recording message deletion %s:%s: %w", ev.Channel, ev.DeletedTS, err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/rusq/slack"
+)
+
+// call is the recorded processor call.
+type call struct {
+	Method    string
+	ChannelID string
+	ParentTS  string
+	TS        string
+	Text      string
+}
+
+type fakeMessenger struct {
+	calls []call
+}
+
+func (m *fakeMessenger) Messages(ctx context.Context, channelID string, numThreads int, isLast bool, mm []slack.Message) error {
+	for _, msg := range mm {
+		m.calls = append(m.calls, call{"Messages", channelID, "", msg.Timestamp, msg.Text})
+	}
+	return nil
+}
+
+func (m *fakeMessenger) ThreadMessages(ctx context.Context, channelID string, parent slack.Message, threadOnly, isLast bool, replies []slack.Message) error {
+	for _, msg := range replies {
+		m.calls = append(m.calls, call{"ThreadMessages", channelID, parent.Timestamp, msg.Timestamp, msg.Text})
+	}
+	return nil
+}
+
+func payload(event string) json.RawMessage {
+	return json.RawMessage(`{"type":"event_callback","event":` + event + `}`)
+}
+
+func TestListener_HandlePayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		channels []string
+		payload  json.RawMessage
+		want     []call
+	}{
+		{
+			name:    "new message",
+			payload: payload(`{"type":"message","channel":"C1","user":"U1","text":"hello","ts":"1.000001"}`),
+			want:    []call{{"Messages", "C1", "", "1.000001", "hello"}},
+		},
+		{
+			name:    "thread reply",
+			payload: payload(`{"type":"message","channel":"C1","user":"U1","text":"reply","ts":"2.000001","thread_ts":"1.000001"}`),
+			want:    []call{{"ThreadMessages", "C1", "1.000001", "2.000001", "reply"}},
+		},
+		{
+			name:    "broadcast reply",
+			payload: payload(`{"type":"message","subtype":"thread_broadcast","channel":"C1","user":"U1","text":"both","ts":"2.000001","thread_ts":"1.000001"}`),
+			want: []call{
+				{"Messages", "C1", "", "2.000001", "both"},
+				{"ThreadMessages", "C1", "1.000001", "2.000001", "both"},
+			},
+		},
+		{
+			name:    "edited message",
+			payload: payload(`{"type":"message","subtype":"message_changed","channel":"C1","ts":"3.000001","message":{"type":"message","user":"U1","text":"edited","ts":"1.000001"}}`),
+			want:    []call{{"Messages", "C1", "", "1.000001", "edited"}},
+		},
+		{
+			name:    "deleted message",
+			payload: payload(`{"type":"message","subtype":"message_deleted","channel":"C1","ts":"3.000001","deleted_ts":"1.000001"}`),
+		},
+		{
+			name:     "channel filter",
+			channels: []string{"C2"},
+			payload:  payload(`{"type":"message","channel":"C1","user":"U1","text":"hello","ts":"1.000001"}`),
+		},
+		{
+			name:    "not a message",
+			payload: payload(`{"type":"reaction_added","user":"U1","reaction":"+1"}`),
+		},
+		{
+			name:    "url verification",
+			payload: json.RawMessage(`{"type":"url_verification","challenge":"x"}`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m fakeMessenger
+			l := New(&m, WithChannels(tt.channels...))
+			if err := l.HandlePayload(context.Background(), tt.payload); err != nil {
+				t.Fatalf("HandlePayload() error = %v", err)
+			}
+			if len(m.calls) != len(tt.want) {
+				t.Fatalf("calls = %v, want %v", m.calls, tt.want)
+			}
+			for i := range tt.want {
+				if m.calls[i] != tt.want[i] {
+					t.Errorf("call %d = %v, want %v", i, m.calls[i], tt.want[i])
+				}
+			}
+		})
+	}
+	t.Run("invalid payload", func(t *testing.T) {
+		l := New(&fakeMessenger{})
+		if err := l.HandlePayload(context.Background(), json.RawMessage(`{`)); err == nil {
+			t.Error("HandlePayload() expected an error")
+		}
+	})
+}
+
+// fakeHistorian is the messenger, that preserves the message history.
+type fakeHistorian struct {
+	fakeMessenger
+}
+
+func (m *fakeHistorian) MessageEdited(ctx context.Context, channelID string, prev slack.Message) error {
+	m.calls = append(m.calls, call{"MessageEdited", channelID, "", prev.Timestamp, prev.Text})
+	return nil
+}
+
+func (m *fakeHistorian) MessageDeleted(ctx context.Context, channelID string, prev slack.Message) error {
+	m.calls = append(m.calls, call{"MessageDeleted", channelID, "", prev.Timestamp, prev.Text})
+	return nil
+}
+
+func TestListener_HandlePayload_history(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload json.RawMessage
+		want    []call
+	}{
+		{
+			name:    "edited message",
+			payload: payload(`{"type":"message","subtype":"message_changed","channel":"C1","ts":"3.000001","message":{"type":"message","user":"U1","text":"edited","ts":"1.000001"},"previous_message":{"type":"message","user":"U1","text":"original","ts":"1.000001"}}`),
+			want: []call{
+				{"MessageEdited", "C1", "", "1.000001", "original"},
+				{"Messages", "C1", "", "1.000001", "edited"},
+			},
+		},
+		{
+			name:    "edited without previous",
+			payload: payload(`{"type":"message","subtype":"message_changed","channel":"C1","ts":"3.000001","message":{"type":"message","user":"U1","text":"edited","ts":"1.000001"}}`),
+			want:    []call{{"Messages", "C1", "", "1.000001", "edited"}},
+		},
+		{
+			name:    "deleted message",
+			payload: payload(`{"type":"message","subtype":"message_deleted","channel":"C1","ts":"3.000001","deleted_ts":"1.000001","previous_message":{"type":"message","user":"U1","text":"gone","ts":"1.000001"}}`),
+			want:    []call{{"MessageDeleted", "C1", "", "1.000001", "gone"}},
+		},
+		{
+			name:    "deleted tombstone",
+			payload: payload(`{"type":"message","subtype":"message_deleted","channel":"C1","ts":"3.000001","deleted_ts":"1.000001"}`),
+			want:    []call{{"MessageDeleted", "C1", "", "1.000001", ""}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m fakeHistorian
+			l := New(&m)
+			if err := l.HandlePayload(context.Background(), tt.payload); err != nil {
+				t.Fatalf("HandlePayload() error = %v", err)
+			}
+			if len(m.calls) != len(tt.want) {
+				t.Fatalf("calls = %v, want %v", m.calls, tt.want)
+			}
+			for i := range tt.want {
+				if m.calls[i] != tt.want[i] {
+					t.Errorf("call %d = %v, want %v", i, m.calls[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
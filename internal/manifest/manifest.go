@@ -0,0 +1,290 @@
+// Package manifest implements the export manifest, that is stored alongside
+// the exported data and records the information about the export runs that
+// contributed to it.
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rusq/fsadapter"
+
+	"github.com/rusq/slackdump/v3/internal/chunk/state"
+	"github.com/rusq/slackdump/v3/internal/fasttime"
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+// Filename is the name of the manifest file in the export.
+const Filename = "slackdump-manifest.json"
+
+// Version is the current manifest version.
+const Version = 1
+
+// ErrVersion is returned if the manifest version is not supported.
+var ErrVersion = errors.New("unsupported manifest version")
+
+// Manifest is the export manifest.
+type Manifest struct {
+	// Version is the version of the manifest.
+	Version int `json:"version"`
+	// Created is the time of the first export run.
+	Created time.Time `json:"created"`
+	// Updated is the time of the last export run.
+	Updated time.Time `json:"updated"`
+	// RunIDs are the IDs of the runs that contributed to the export, in the
+	// order of the runs.
+	RunIDs []string `json:"run_ids,omitempty"`
+	// Channels maps the channel ID to the channel information.
+	Channels map[string]*Channel `json:"channels,omitempty"`
+	// Annotations are the operator notes of all runs, in the order they
+	// were made.
+	Annotations []state.Annotation `json:"annotations,omitempty"`
+	// Plan is the workspace plan at the time of the last run, if known.
+	Plan string `json:"plan,omitempty"`
+	// AccessibleFrom is the time of the oldest message, that the API
+	// returned at the time of the last run, if the workspace history is
+	// limited, i.e. on the free plan.  Messages older than that are missing
+	// from the export, unless fetched by the earlier runs.
+	AccessibleFrom *time.Time `json:"accessible_from,omitempty"`
+	// Files maps the Slack file ID to the file downloaded into the export.
+	Files map[string]*File `json:"files,omitempty"`
+	// Parts maps the conversation day file, that exceeded the message limit,
+	// to the part files it was split into, in order.
+	Parts map[string][]string `json:"parts,omitempty"`
+
+	mu sync.RWMutex
+}
+
+// File is the file attachment, that was downloaded into the export.
+type File struct {
+	// Path is the path of the file within the export.
+	Path string `json:"path"`
+	// Size is the size of the file in bytes.
+	Size int64 `json:"size"`
+}
+
+// Channel holds the channel information.
+type Channel struct {
+	// Latest is the timestamp of the newest message in the channel that was
+	// exported, the high-water mark.
+	Latest string `json:"latest"`
+	// Updated is the time when the high-water mark was last advanced.
+	Updated time.Time `json:"updated"`
+}
+
+// New returns a new empty manifest.
+func New() *Manifest {
+	now := time.Now().UTC()
+	return &Manifest{
+		Version:  Version,
+		Created:  now,
+		Updated:  now,
+		Channels: make(map[string]*Channel),
+	}
+}
+
+// Load loads the manifest from the root of fsys.  If the manifest does not
+// exist, it returns an error wrapping fs.ErrNotExist.
+func Load(fsys fs.FS) (*Manifest, error) {
+	f, err := fsys.Open(Filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read reads the manifest from r.
+func Read(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("error decoding manifest: %w", err)
+	}
+	if m.Version < 1 || Version < m.Version {
+		return nil, fmt.Errorf("%w: %d", ErrVersion, m.Version)
+	}
+	if m.Channels == nil {
+		m.Channels = make(map[string]*Channel)
+	}
+	return &m, nil
+}
+
+// Save writes the manifest to the root of the fsa.
+func (m *Manifest) Save(fsa fsadapter.FS) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	wc, err := fsa.Create(Filename)
+	if err != nil {
+		return err
+	}
+	defer wc.Close()
+	enc := json.NewEncoder(wc)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+	return wc.Close()
+}
+
+// AddAnnotation records the operator note.
+func (m *Manifest) AddAnnotation(a state.Annotation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Annotations = append(m.Annotations, a)
+}
+
+// SetAccessRange records the workspace plan and the time of the oldest
+// accessible message.  Zero from means that the history is not limited.
+func (m *Manifest) SetAccessRange(plan string, from time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Plan = plan
+	if from.IsZero() {
+		m.AccessibleFrom = nil
+		return
+	}
+	from = from.UTC()
+	m.AccessibleFrom = &from
+}
+
+// AddFile records the file with the Slack file ID id, that was downloaded
+// into the export.  The path is stored with the forward slashes.
+func (m *Manifest) AddFile(id string, f File) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Files == nil {
+		m.Files = make(map[string]*File)
+	}
+	f.Path = filepath.ToSlash(f.Path)
+	m.Files[id] = &f
+}
+
+// File returns the file with the Slack file ID id, or false if the file
+// was not downloaded into the export.
+func (m *Manifest) File(id string) (File, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	f, ok := m.Files[id]
+	if !ok {
+		return File{}, false
+	}
+	return *f, true
+}
+
+// FileCount returns the number of the files in the manifest.
+func (m *Manifest) FileCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.Files)
+}
+
+// AddParts records that the day file was split into the part files.  The
+// paths are stored with the forward slashes.
+func (m *Manifest) AddParts(day string, parts []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Parts == nil {
+		m.Parts = make(map[string][]string)
+	}
+	pp := make([]string, len(parts))
+	for i, p := range parts {
+		pp[i] = filepath.ToSlash(p)
+	}
+	m.Parts[filepath.ToSlash(day)] = pp
+}
+
+// PartCount returns the number of the day files, that were split.
+func (m *Manifest) PartCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.Parts)
+}
+
+// AddRun records the run with id, and updates the Updated time.
+func (m *Manifest) AddRun(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Updated = time.Now().UTC()
+	if id != "" {
+		m.RunIDs = append(m.RunIDs, id)
+	}
+}
+
+// HighWater returns the high-water mark for the channel, or false if the
+// channel is not in the manifest.
+func (m *Manifest) HighWater(channelID string) (time.Time, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ch, ok := m.Channels[channelID]
+	if !ok || ch.Latest == "" {
+		return time.Time{}, false
+	}
+	t, err := structures.ParseSlackTS(ch.Latest)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// Advance sets the high-water mark for the channel to ts, if ts is newer
+// than the current mark.  It returns true if the mark was advanced.
+func (m *Manifest) Advance(channelID string, ts string) (bool, error) {
+	newTS, err := fasttime.TS2int(ts)
+	if err != nil {
+		return false, fmt.Errorf("invalid timestamp %q: %w", ts, err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ch, ok := m.Channels[channelID]; ok && ch.Latest != "" {
+		if curTS, err := fasttime.TS2int(ch.Latest); err == nil && newTS <= curTS {
+			return false, nil
+		}
+	}
+	m.Channels[channelID] = &Channel{Latest: ts, Updated: time.Now().UTC()}
+	return true, nil
+}
+
+// ChannelIDs returns the sorted list of the channel IDs in the manifest.
+func (m *Manifest) ChannelIDs() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := make([]string, 0, len(m.Channels))
+	for id := range m.Channels {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Merge advances the high-water marks of the channels to the marks in
+// other, where they are newer, and adds the channels, that are missing.
+// The run IDs are not merged.
+func (m *Manifest) Merge(other *Manifest) {
+	other.mu.RLock()
+	chans := make(map[string]Channel, len(other.Channels))
+	for id, ch := range other.Channels {
+		chans[id] = *ch
+	}
+	other.mu.RUnlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, ch := range chans {
+		cur, ok := m.Channels[id]
+		if ok && cur.Latest != "" {
+			curTS, err1 := fasttime.TS2int(cur.Latest)
+			newTS, err2 := fasttime.TS2int(ch.Latest)
+			if err1 == nil && (err2 != nil || newTS <= curTS) {
+				continue
+			}
+		}
+		m.Channels[id] = &ch
+	}
+}
@@ -0,0 +1,139 @@
+package manifest
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/rusq/fsadapter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManifest_Advance(t *testing.T) {
+	m := New()
+	if _, ok := m.HighWater("C1"); ok {
+		t.Fatal("unexpected high-water mark on empty manifest")
+	}
+	for _, tt := range []struct {
+		ts   string
+		want bool
+	}{
+		{"1700000002.000100", true},
+		{"1700000001.000000", false}, // older
+		{"1700000002.000100", false}, // same
+		{"1700000002.000200", true},
+	} {
+		got, err := m.Advance("C1", tt.ts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, tt.want, got, tt.ts)
+	}
+	assert.Equal(t, "1700000002.000200", m.Channels["C1"].Latest)
+	if _, err := m.Advance("C1", "garbage"); err == nil {
+		t.Error("expected error on invalid timestamp")
+	}
+	hw, ok := m.HighWater("C1")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1700000002), hw.Unix())
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	m := New()
+	m.AddRun("run-1")
+	if _, err := m.Advance("C1", "1700000001.000000"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Save(fsadapter.NewDirectory(dir)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(os.DirFS(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"run-1"}, got.RunIDs)
+	assert.Equal(t, []string{"C1"}, got.ChannelIDs())
+	assert.Equal(t, m.Channels["C1"].Latest, got.Channels["C1"].Latest)
+	assert.Nil(t, got.AccessibleFrom)
+
+	t.Run("access range", func(t *testing.T) {
+		from := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		m.SetAccessRange("free", from)
+		if err := m.Save(fsadapter.NewDirectory(dir)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := Load(os.DirFS(dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "free", got.Plan)
+		if assert.NotNil(t, got.AccessibleFrom) {
+			assert.True(t, from.Equal(*got.AccessibleFrom))
+		}
+		m.SetAccessRange("std", time.Time{})
+		assert.Nil(t, m.AccessibleFrom)
+	})
+	t.Run("files", func(t *testing.T) {
+		m.AddFile("F1", File{Path: filepath.Join("__uploads", "F1", "a.png"), Size: 42})
+		if err := m.Save(fsadapter.NewDirectory(dir)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := Load(os.DirFS(dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 1, got.FileCount())
+		f, ok := got.File("F1")
+		assert.True(t, ok)
+		assert.Equal(t, File{Path: "__uploads/F1/a.png", Size: 42}, f)
+		_, ok = got.File("F2")
+		assert.False(t, ok)
+	})
+	t.Run("parts", func(t *testing.T) {
+		dir := t.TempDir()
+		m := New()
+		m.AddParts(filepath.Join("general", "2024-01-02.json"), []string{
+			filepath.Join("general", "2024-01-02-part1.json"),
+			filepath.Join("general", "2024-01-02-part2.json"),
+		})
+		if err := m.Save(fsadapter.NewDirectory(dir)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := Load(os.DirFS(dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 1, got.PartCount())
+		assert.Equal(t, map[string][]string{
+			"general/2024-01-02.json": {"general/2024-01-02-part1.json", "general/2024-01-02-part2.json"},
+		}, got.Parts)
+	})
+	t.Run("not exist", func(t *testing.T) {
+		_, err := Load(fstest.MapFS{})
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+	})
+	t.Run("future version", func(t *testing.T) {
+		_, err := Load(fstest.MapFS{Filename: {Data: []byte(`{"version":99}`)}})
+		assert.ErrorIs(t, err, ErrVersion)
+	})
+}
+
+func TestManifest_Merge(t *testing.T) {
+	m := New()
+	m.Advance("C1", "1700000002.000000")
+	m.Advance("C2", "1700000002.000000")
+	other := New()
+	other.Advance("C1", "1700000001.000000") // older
+	other.Advance("C2", "1700000003.000000") // newer
+	other.Advance("C3", "1700000001.000000") // new
+	m.Merge(other)
+	assert.Equal(t, []string{"C1", "C2", "C3"}, m.ChannelIDs())
+	assert.Equal(t, "1700000002.000000", m.Channels["C1"].Latest)
+	assert.Equal(t, "1700000003.000000", m.Channels["C2"].Latest)
+	assert.Equal(t, "1700000001.000000", m.Channels["C3"].Latest)
+}
@@ -0,0 +1,337 @@
+// Package monitor exposes the health and the Prometheus metrics of the
+// long-running slackdump process over HTTP.  The metrics are written in the
+// Prometheus text exposition format, so that no client library is required.
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rusq/slackdump/v3/internal/network"
+)
+
+// Job statuses.
+const (
+	StatusRunning = "running"
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+)
+
+var allStatuses = []string{StatusRunning, StatusOK, StatusFailed}
+
+// Job is the state of the job, i.e. a command run.
+type Job struct {
+	Status    string        `json:"status"`
+	Started   time.Time     `json:"started"`
+	Duration  time.Duration `json:"duration,omitempty"`
+	LastError string        `json:"last_error,omitempty"`
+	Runs      int64         `json:"runs"`
+	Failures  int64         `json:"failures"`
+}
+
+// Monitor holds the state of the jobs and the queue gauges.  It is safe for
+// concurrent use.  Zero value is not usable, use [New].
+type Monitor struct {
+	started time.Time
+	stats   *network.CallStats
+
+	mu       sync.RWMutex
+	jobs     map[string]*Job
+	queues   map[string]func() int
+	adaptive *network.Adaptive
+}
+
+// New creates a new Monitor, that reports the package-wide API call
+// statistics of the network package.
+func New() *Monitor {
+	return &Monitor{
+		started: time.Now(),
+		stats:   network.Stats(),
+		jobs:    make(map[string]*Job),
+		queues:  make(map[string]func() int),
+	}
+}
+
+// Start marks the job as running.
+func (m *Monitor) Start(job string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[job]
+	if !ok {
+		j = new(Job)
+		m.jobs[job] = j
+	}
+	j.Status = StatusRunning
+	j.Started = time.Now()
+	j.Duration = 0
+	j.Runs++
+}
+
+// Finish marks the job as finished, with the error, if it failed.
+func (m *Monitor) Finish(job string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[job]
+	if !ok {
+		return
+	}
+	j.Duration = time.Since(j.Started)
+	if err != nil {
+		j.Status = StatusFailed
+		j.LastError = err.Error()
+		j.Failures++
+		return
+	}
+	j.Status = StatusOK
+}
+
+// Jobs returns the copy of the job states.
+func (m *Monitor) Jobs() map[string]Job {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ret := make(map[string]Job, len(m.jobs))
+	for name, j := range m.jobs {
+		ret[name] = *j
+	}
+	return ret
+}
+
+// Queue registers the queue depth gauge.  fn is called on each scrape, and
+// should return the current number of the items in the queue.  Registering
+// the queue with the same name replaces the previous one, nil fn removes it.
+func (m *Monitor) Queue(name string, fn func() int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if fn == nil {
+		delete(m.queues, name)
+		return
+	}
+	m.queues[name] = fn
+}
+
+// Adaptive registers the adaptive rate controller, which rates are reported
+// on each scrape.  Registering the controller replaces the previous one, nil
+// removes it.
+func (m *Monitor) Adaptive(ad *network.Adaptive) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.adaptive = ad
+}
+
+// Handler returns the HTTP handler serving the "/healthz" and "/metrics"
+// endpoints.
+func (m *Monitor) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", m.handleHealth)
+	mux.HandleFunc("/metrics", m.handleMetrics)
+	return mux
+}
+
+// health is the response of the health endpoint.
+type health struct {
+	Status string         `json:"status"`
+	Uptime string         `json:"uptime"`
+	Jobs   map[string]Job `json:"jobs,omitempty"`
+}
+
+func (m *Monitor) handleHealth(w http.ResponseWriter, r *http.Request) {
+	h := health{
+		Status: StatusOK,
+		Uptime: time.Since(m.started).Round(time.Second).String(),
+		Jobs:   m.Jobs(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h); err != nil {
+		slog.Default().WarnContext(r.Context(), "error writing health response", "error", err)
+	}
+}
+
+func (m *Monitor) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := m.WriteMetrics(w); err != nil {
+		slog.Default().WarnContext(r.Context(), "error writing metrics", "error", err)
+	}
+}
+
+// WriteMetrics writes the metrics in the Prometheus text exposition format.
+func (m *Monitor) WriteMetrics(w io.Writer) error {
+	pw := &promWriter{w: w}
+
+	pw.metric("slackdump_up", "gauge", "Whether the slackdump process is up.")
+	pw.sample("slackdump_up", nil, 1)
+	pw.metric("slackdump_start_time_seconds", "gauge", "Start time of the process since unix epoch in seconds.")
+	pw.sample("slackdump_start_time_seconds", nil, float64(m.started.Unix()))
+
+	jobs := m.Jobs()
+	names := sortedKeys(jobs)
+	pw.metric("slackdump_job_status", "gauge", "Current status of the job, 1 for the current status.")
+	for _, name := range names {
+		for _, st := range allStatuses {
+			v := 0.0
+			if jobs[name].Status == st {
+				v = 1
+			}
+			pw.sample("slackdump_job_status", []string{"job", name, "status", st}, v)
+		}
+	}
+	pw.metric("slackdump_job_runs_total", "counter", "Number of the job runs.")
+	for _, name := range names {
+		pw.sample("slackdump_job_runs_total", []string{"job", name}, float64(jobs[name].Runs))
+	}
+	pw.metric("slackdump_job_failures_total", "counter", "Number of the failed job runs.")
+	for _, name := range names {
+		pw.sample("slackdump_job_failures_total", []string{"job", name}, float64(jobs[name].Failures))
+	}
+	pw.metric("slackdump_job_last_duration_seconds", "gauge", "Duration of the last finished job run.")
+	for _, name := range names {
+		pw.sample("slackdump_job_last_duration_seconds", []string{"job", name}, jobs[name].Duration.Seconds())
+	}
+
+	api := m.stats.Snapshot()
+	endpoints := sortedKeys(api)
+	pw.metric("slackdump_api_calls_total", "counter", "Number of the Slack API call attempts.")
+	for _, ep := range endpoints {
+		pw.sample("slackdump_api_calls_total", []string{"endpoint", ep}, float64(api[ep].Calls))
+	}
+	pw.metric("slackdump_api_errors_total", "counter", "Number of the failed Slack API call attempts.")
+	for _, ep := range endpoints {
+		pw.sample("slackdump_api_errors_total", []string{"endpoint", ep}, float64(api[ep].Errors))
+	}
+	pw.metric("slackdump_api_rate_limited_total", "counter", "Number of the rate limited Slack API call attempts.")
+	for _, ep := range endpoints {
+		pw.sample("slackdump_api_rate_limited_total", []string{"endpoint", ep}, float64(api[ep].RateLimited))
+	}
+	m.mu.RLock()
+	ad := m.adaptive
+	m.mu.RUnlock()
+	if ad != nil {
+		fams := ad.Families()
+		pw.metric("slackdump_api_rate_per_minute", "gauge", "Current adaptive request rate of the Slack API endpoint.")
+		for _, ep := range sortedKeys(fams) {
+			pw.sample("slackdump_api_rate_per_minute", []string{"endpoint", ep}, fams[ep].Rate)
+		}
+	}
+
+	m.mu.RLock()
+	queues := make(map[string]int, len(m.queues))
+	for name, fn := range m.queues {
+		queues[name] = fn()
+	}
+	m.mu.RUnlock()
+	pw.metric("slackdump_queue_depth", "gauge", "Number of the items waiting in the queue.")
+	for _, name := range sortedKeys(queues) {
+		pw.sample("slackdump_queue_depth", []string{"queue", name}, float64(queues[name]))
+	}
+
+	return pw.err
+}
+
+// promWriter writes the metrics in the Prometheus text format, keeping the
+// first error.
+type promWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (pw *promWriter) printf(format string, a ...any) {
+	if pw.err != nil {
+		return
+	}
+	_, pw.err = fmt.Fprintf(pw.w, format, a...)
+}
+
+func (pw *promWriter) metric(name, typ, help string) {
+	pw.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes the sample with labels, given as name-value pairs.
+func (pw *promWriter) sample(name string, labels []string, v float64) {
+	if len(labels) == 0 {
+		pw.printf("%s %s\n", name, formatValue(v))
+		return
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+labelEscaper.Replace(labels[i+1])+`"`)
+	}
+	pw.printf("%s{%s} %s\n", name, strings.Join(pairs, ","), formatValue(v))
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ErrNotLocal is returned by [Listen], if the address is not a loopback
+// address.
+var ErrNotLocal = errors.New("monitoring address must be a loopback address")
+
+// Listen starts listening on the monitoring addr.  If the host part of addr
+// is empty, it listens on 127.0.0.1.  Only the loopback addresses are
+// allowed, as the endpoints are not authenticated.
+func Listen(addr string) (net.Listener, error) {
+	addr, err := localAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	return net.Listen("tcp", addr)
+}
+
+// Serve serves the monitoring endpoints on the listener until the context is
+// cancelled.
+func (m *Monitor) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{
+		Handler:           m.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		sctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(sctx)
+	}()
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// localAddr validates the address, and returns it with the default host.
+func localAddr(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid monitoring address %q: %w", addr, err)
+	}
+	switch host {
+	case "":
+		host = "127.0.0.1"
+	case "localhost":
+	default:
+		ip := net.ParseIP(host)
+		if ip == nil || !ip.IsLoopback() {
+			return "", fmt.Errorf("%w: %s", ErrNotLocal, addr)
+		}
+	}
+	return net.JoinHostPort(host, port), nil
+}
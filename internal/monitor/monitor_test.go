@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMonitor_Handler(t *testing.T) {
+	m := New()
+	m.Start("export")
+	m.Finish("export", errors.New("boom"))
+	m.Start("export")
+	m.Queue("transform", func() int { return 3 })
+
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+
+	t.Run("healthz", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/healthz")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d", resp.StatusCode)
+		}
+		var h health
+		if err := json.NewDecoder(resp.Body).Decode(&h); err != nil {
+			t.Fatal(err)
+		}
+		j := h.Jobs["export"]
+		if j.Status != StatusRunning || j.Runs != 2 || j.Failures != 1 || j.LastError != "boom" {
+			t.Errorf("unexpected job state: %+v", j)
+		}
+	})
+	t.Run("metrics", func(t *testing.T) {
+		var sb strings.Builder
+		if err := m.WriteMetrics(&sb); err != nil {
+			t.Fatal(err)
+		}
+		out := sb.String()
+		for _, want := range []string{
+			"slackdump_up 1\n",
+			`slackdump_job_status{job="export",status="running"} 1` + "\n",
+			`slackdump_job_status{job="export",status="failed"} 0` + "\n",
+			`slackdump_job_failures_total{job="export"} 1` + "\n",
+			`slackdump_queue_depth{queue="transform"} 3` + "\n",
+			"# TYPE slackdump_api_calls_total counter\n",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("metrics output does not contain %q:\n%s", want, out)
+			}
+		}
+	})
+}
+
+func Test_localAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		want    string
+		wantErr error
+	}{
+		{":9090", "127.0.0.1:9090", nil},
+		{"localhost:9090", "localhost:9090", nil},
+		{"[::1]:9090", "[::1]:9090", nil},
+		{"127.0.0.2:9090", "127.0.0.2:9090", nil},
+		{"0.0.0.0:9090", "", ErrNotLocal},
+		{"example.com:9090", "", ErrNotLocal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			got, err := localAddr(tt.addr)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("localAddr() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("localAddr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if _, err := localAddr("9090"); err == nil {
+		t.Error("expected error for the address without port")
+	}
+}
@@ -0,0 +1,50 @@
+package mtimefs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rusq/fsadapter"
+)
+
+var _ Creator = Directory{}
+
+// Directory is the directory filesystem adapter, that sets the modification
+// time of the files created with CreateAt.
+type Directory struct {
+	fsadapter.Directory
+	dir string
+}
+
+// NewDirectory returns a new Directory filesystem adapter for the directory
+// dir.
+func NewDirectory(dir string) Directory {
+	return Directory{Directory: fsadapter.NewDirectory(dir), dir: dir}
+}
+
+// CreateAt creates the file name in the directory, its modification time is
+// set to modTime on Close.
+func (d Directory) CreateAt(name string, modTime time.Time) (io.WriteCloser, error) {
+	wc, err := d.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return &chtimesCloser{WriteCloser: wc, name: filepath.Join(d.dir, name), modTime: modTime}, nil
+}
+
+// chtimesCloser sets the access and modification times of the file on
+// Close.
+type chtimesCloser struct {
+	io.WriteCloser
+	name    string
+	modTime time.Time
+}
+
+func (c *chtimesCloser) Close() error {
+	if err := c.WriteCloser.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(c.name, c.modTime, c.modTime)
+}
@@ -0,0 +1,42 @@
+// Package mtimefs provides the local filesystem adapters, that can set the
+// modification time of the created files, so that the downloaded
+// attachments carry the time they were uploaded to Slack, instead of the
+// time they were downloaded.
+package mtimefs
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rusq/fsadapter"
+)
+
+// Creator is implemented by the filesystems that can set the modification
+// time of the created file.
+type Creator interface {
+	// CreateAt creates the file name with the modification time modTime.
+	// The time is applied when the file is closed.
+	CreateAt(name string, modTime time.Time) (io.WriteCloser, error)
+}
+
+// Create creates the file name on the filesystem fsa with the modification
+// time modTime, if fsa supports it, otherwise, or if modTime is zero, the
+// file is created with fsa.Create.
+func Create(fsa fsadapter.FS, name string, modTime time.Time) (io.WriteCloser, error) {
+	if c, ok := fsa.(Creator); ok && !modTime.IsZero() {
+		return c.CreateAt(name, modTime)
+	}
+	return fsa.Create(name)
+}
+
+// New returns the filesystem adapter for the location, same as
+// [fsadapter.New]: ZIP file for the locations with ".zip" extension, and the
+// directory otherwise.
+func New(location string) (fsadapter.FSCloser, error) {
+	if strings.EqualFold(filepath.Ext(location), ".zip") {
+		return NewZipFile(location)
+	}
+	return NewDirectory(location), nil
+}
@@ -0,0 +1,90 @@
+package mtimefs
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rusq/fsadapter"
+	"github.com/stretchr/testify/assert"
+)
+
+var testModTime = time.Date(2021, 3, 14, 15, 9, 26, 0, time.UTC)
+
+func writeFile(t *testing.T, fsa fsadapter.FS, name string, modTime time.Time) {
+	t.Helper()
+	wc, err := Create(fsa, name, modTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(wc, "content of "+name); err != nil {
+		t.Fatal(err)
+	}
+	if err := wc.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirectory_CreateAt(t *testing.T) {
+	dir := t.TempDir()
+	fsa := NewDirectory(dir)
+	writeFile(t, fsa, filepath.Join("files", "F1-a.txt"), testModTime)
+	writeFile(t, fsa, "b.txt", time.Time{})
+
+	fi, err := os.Stat(filepath.Join(dir, "files", "F1-a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, fi.ModTime().Equal(testModTime), "got mod time %s", fi.ModTime())
+
+	fi, err = os.Stat(filepath.Join(dir, "b.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.WithinDuration(t, time.Now(), fi.ModTime(), time.Minute)
+}
+
+func TestZIP_CreateAt(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.zip")
+	fsa, err := New(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fsa, "C1/attachments/F1-a.txt", testModTime)
+	writeFile(t, fsa, "C1/attachments/F2-b.txt", testModTime.Add(time.Hour))
+	writeFile(t, fsa, "channels.json", time.Time{})
+	if err := fsa.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var names []string
+	got := make(map[string]time.Time)
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		got[f.Name] = f.Modified
+	}
+	assert.Equal(t, []string{"C1/", "C1/attachments/", "C1/attachments/F1-a.txt", "C1/attachments/F2-b.txt", "channels.json"}, names)
+	assert.True(t, got["C1/attachments/F1-a.txt"].Equal(testModTime), "got mod time %s", got["C1/attachments/F1-a.txt"])
+	assert.True(t, got["C1/attachments/F2-b.txt"].Equal(testModTime.Add(time.Hour)))
+	assert.WithinDuration(t, time.Now(), got["channels.json"], time.Minute)
+}
+
+func TestCreate_unsupported(t *testing.T) {
+	dir := t.TempDir()
+	// plain directory adapter does not support setting the time, the file
+	// is created as usual.
+	writeFile(t, fsadapter.NewDirectory(dir), "a.txt", testModTime)
+	fi, err := os.Stat(filepath.Join(dir, "a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, fi.ModTime().Equal(testModTime))
+}
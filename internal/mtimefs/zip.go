@@ -0,0 +1,137 @@
+package mtimefs
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rusq/fsadapter"
+)
+
+var (
+	_ fsadapter.FSCloser = &ZIP{}
+	_ Creator            = &ZIP{}
+)
+
+// ZIP is the ZIP file filesystem adapter, that records the modification time
+// of the files created with CreateAt in the archive entries.  The archive
+// can hold only one open file at a time, Create and CreateAt block until the
+// previously created file is closed.
+type ZIP struct {
+	zw   *zip.Writer
+	f    *os.File
+	mu   sync.Mutex
+	seen map[string]bool // seen holds the directories created in the archive.
+}
+
+// NewZipFile creates the ZIP file filename.
+func NewZipFile(filename string) (*ZIP, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &ZIP{zw: zip.NewWriter(f), f: f, seen: make(map[string]bool)}, nil
+}
+
+func (z *ZIP) String() string {
+	return fmt.Sprintf("<zip archive: %s>", z.f.Name())
+}
+
+// Create creates the file name in the archive, with the current time as the
+// modification time.
+func (z *ZIP) Create(name string) (io.WriteCloser, error) {
+	return z.CreateAt(name, time.Now())
+}
+
+// CreateAt creates the file name in the archive with the modification time
+// modTime.
+func (z *ZIP) CreateAt(name string, modTime time.Time) (io.WriteCloser, error) {
+	z.mu.Lock() // unlocked when the caller closes the file.
+	w, err := z.create(name, modTime)
+	if err != nil {
+		z.mu.Unlock()
+		return nil, err
+	}
+	return &entryWriter{w: w, unlock: z.mu.Unlock}, nil
+}
+
+// WriteFile writes data to the file name in the archive.
+func (z *ZIP) WriteFile(name string, data []byte, _ os.FileMode) error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	w, err := z.create(name, time.Now())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Close finalises the archive and closes the file.  All files must be closed
+// before calling Close.
+func (z *ZIP) Close() error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	return errors.Join(z.zw.Close(), z.f.Close())
+}
+
+// create creates the archive entry, must be called with the lock held.
+func (z *ZIP) create(name string, modTime time.Time) (io.Writer, error) {
+	name = path.Clean(filepath.ToSlash(name))
+	if err := z.ensureDir(path.Dir(name)); err != nil {
+		return nil, err
+	}
+	return z.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modTime,
+	})
+}
+
+// ensureDir creates the directory entries for dir and all its parents, that
+// were not created yet, as some unarchivers do not handle the files in the
+// directories that have no entries.
+func (z *ZIP) ensureDir(dir string) error {
+	if dir == "." || dir == "/" || z.seen[dir] {
+		return nil
+	}
+	if err := z.ensureDir(path.Dir(dir)); err != nil {
+		return err
+	}
+	if _, err := z.zw.Create(strings.TrimSuffix(dir, "/") + "/"); err != nil {
+		return err
+	}
+	z.seen[dir] = true
+	return nil
+}
+
+// entryWriter is the writer of the archive entry, that releases the archive
+// lock on Close.
+type entryWriter struct {
+	w      io.Writer
+	unlock func()
+	once   sync.Once
+	closed bool
+}
+
+func (ew *entryWriter) Write(p []byte) (int, error) {
+	if ew.closed {
+		return 0, errors.New("file already closed")
+	}
+	return ew.w.Write(p)
+}
+
+func (ew *entryWriter) Close() error {
+	ew.once.Do(func() {
+		ew.closed = true
+		ew.unlock()
+	})
+	return nil
+}
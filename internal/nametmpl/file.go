@@ -0,0 +1,149 @@
+package nametmpl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/structures/files"
+)
+
+const fileTmplName = "filetmpl"
+
+// DefaultFile is the file naming template, that reproduces the naming of
+// the attachments in the standard export.
+const DefaultFile = `{{.Channel}}/attachments/{{.ID}}-{{.Name}}`
+
+// FileVars are the variables, that are available in the file naming
+// template.  All values are safe to use as the path elements.
+type FileVars struct {
+	// ID is the file ID.
+	ID string
+	// Name is the original file name, Base is the name without the
+	// extension, and Ext is the extension with the leading dot.
+	Name string
+	Base string
+	Ext  string
+	// Channel is the channel name, or the channel ID, if the name is not
+	// known, i.e. for the DMs.
+	Channel   string
+	ChannelID string
+	// User is the ID of the user, who uploaded the file.
+	User string
+	// Date is the upload date in YYYY-MM-DD format (UTC), and Year, Month
+	// and Day are its components.
+	Date  string
+	Year  string
+	Month string
+	Day   string
+	// Hash is the short stable hash of the file ID, that can be used to
+	// make the names unique instead of the ID.  It is not the hash of the
+	// file contents: the path is used in the message links before the file
+	// is downloaded, so it must not depend on the contents.
+	Hash string
+}
+
+// tf marks the fields, that make the file name unique, with OK, the rest
+// are partial.
+var tf = FileVars{
+	ID:        mOK,
+	Name:      mPartialOK,
+	Base:      mPartialOK,
+	Ext:       mPartialOK,
+	Channel:   mPartialOK,
+	ChannelID: mPartialOK,
+	User:      mPartialOK,
+	Date:      mPartialOK,
+	Year:      mPartialOK,
+	Month:     mPartialOK,
+	Day:       mPartialOK,
+	Hash:      mOK,
+}
+
+// FileTemplate is the file naming template, that returns the path of the
+// downloaded file.
+type FileTemplate struct {
+	t *template.Template
+}
+
+// NewFile returns the file naming template from the string t.  The template
+// must reference the file ID or its hash, so that the file names are unique.
+// The "/" in the template separates the directories.
+func NewFile(t string) (*FileTemplate, error) {
+	tmpl, err := template.New(fileTmplName).Option("missingkey=error").Parse(t)
+	if err != nil {
+		return nil, err
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, tf); err != nil {
+		return nil, err
+	}
+	if !strings.Contains(buf.String(), mOK) {
+		return nil, fmt.Errorf("file template must contain {{.ID}} or {{.Hash}}: %q", t)
+	}
+	return &FileTemplate{t: tmpl}, nil
+}
+
+// Execute returns the path of the file f, posted in the channel ci.  The
+// path is relative, and does not escape the output directory.
+func (t *FileTemplate) Execute(ci *slack.Channel, f *slack.File) string {
+	var buf strings.Builder
+	if err := t.t.Execute(&buf, NewFileVars(ci, f)); err != nil {
+		// the template is checked when compiled.
+		panic(err)
+	}
+	p := strings.TrimPrefix(path.Clean("/"+buf.String()), "/")
+	return filepath.FromSlash(p)
+}
+
+// NewFileVars returns the template variables of the file f, posted in the
+// channel ci.
+func NewFileVars(ci *slack.Channel, f *slack.File) FileVars {
+	name := files.Name(f)
+	ext := path.Ext(name)
+	created := f.Created.Time().UTC()
+	if f.Created == 0 {
+		created = time.Unix(int64(f.Timestamp), 0).UTC()
+	}
+	sum := sha256.Sum256([]byte(f.ID))
+	channel := ci.Name
+	if channel == "" {
+		channel = ci.ID
+	}
+	return FileVars{
+		ID:        safe(f.ID),
+		Name:      safe(name),
+		Base:      safe(strings.TrimSuffix(name, ext)),
+		Ext:       safe(ext),
+		Channel:   safe(channel),
+		ChannelID: safe(ci.ID),
+		User:      safe(f.User),
+		Date:      created.Format("2006-01-02"),
+		Year:      created.Format("2006"),
+		Month:     created.Format("01"),
+		Day:       created.Format("02"),
+		Hash:      hex.EncodeToString(sum[:6]),
+	}
+}
+
+// safe replaces the characters, that are not allowed in the path elements,
+// and the relative path elements.
+func safe(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "." || s == ".." {
+		return "_"
+	}
+	return s
+}
@@ -0,0 +1,105 @@
+package nametmpl
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rusq/slack"
+)
+
+func TestNewFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		t       string
+		wantErr bool
+	}{
+		{"default", DefaultFile, false},
+		{"hash is ok", "{{.Date}}/{{.Hash}}{{.Ext}}", false},
+		{"user and id", "{{.User}}/{{.ID}}-{{.Name}}", false},
+		{"name only is not ok", "{{.Channel}}/{{.Name}}", true},
+		{"unknown field", "{{.ID}}-{{.Who_dis}}", true},
+		{"invalid template", "{{.ID", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFile(tt.t)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileTemplate_Execute(t *testing.T) {
+	created := slack.JSONTime(time.Date(2024, 3, 5, 23, 0, 0, 0, time.UTC).Unix())
+	file := &slack.File{ID: "F123", Name: "report.final.pdf", User: "U42", Created: created}
+	tests := []struct {
+		name string
+		t    string
+		ci   *slack.Channel
+		f    *slack.File
+		want string
+	}{
+		{
+			"default",
+			DefaultFile,
+			&slack.Channel{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1"}}},
+			file,
+			"general/attachments/F123-report.final.pdf",
+		},
+		{
+			"channel ID if no name",
+			"{{.Channel}}/{{.ID}}",
+			&slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "D1"}}},
+			file,
+			"D1/F123",
+		},
+		{
+			"date and user",
+			"{{.Year}}/{{.Month}}/{{.Day}}/{{.User}}/{{.Base}}-{{.ID}}{{.Ext}}",
+			&slack.Channel{},
+			file,
+			"2024/03/05/U42/report.final-F123.pdf",
+		},
+		{
+			"hash",
+			"{{.Date}}/{{.Hash}}",
+			&slack.Channel{},
+			file,
+			"2024-03-05/" + NewFileVars(&slack.Channel{}, file).Hash,
+		},
+		{
+			"empty values are skipped",
+			"{{.User}}/{{.ID}}",
+			&slack.Channel{},
+			&slack.File{ID: "F1"},
+			"F1",
+		},
+		{
+			"path elements in the values are escaped",
+			"{{.Name}}/{{.ID}}",
+			&slack.Channel{},
+			&slack.File{ID: "F1", Name: "../../etc/passwd"},
+			".._.._etc_passwd/F1",
+		},
+		{
+			"relative template does not escape",
+			"../../{{.ID}}",
+			&slack.Channel{},
+			&slack.File{ID: "F1"},
+			"F1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft, err := NewFile(tt.t)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ft.Execute(tt.ci, tt.f); got != filepath.FromSlash(tt.want) {
+				t.Errorf("Execute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// In this file: adaptive rate limiting.
+
+const (
+	// rampStep is the fraction of the tier rate, by which the rate of the
+	// method family is increased after the clean run of responses.
+	rampStep = 0.1
+	// minFactor is the minimum rate of the method family, as a fraction of
+	// the tier rate, it doesn't go lower than that on repeated backoffs.
+	minFactor = 0.25
+
+	defMaxBoost  = 2.0
+	defRampAfter = 20
+)
+
+// AdaptiveLimit configures the adaptive rate limiting, see [Adaptive].
+type AdaptiveLimit struct {
+	// Enabled enables the adaptive rate limiting.  If disabled, the static
+	// tier limits are used.  It is disabled by default.
+	Enabled bool `json:"enabled,omitempty" yaml:"enabled,omitempty" toml:"enabled,omitempty"`
+	// MaxBoost is the maximum rate of the API method, as a multiple of its
+	// tier rate.
+	MaxBoost float64 `json:"max_boost,omitempty" yaml:"max_boost,omitempty" toml:"max_boost,omitempty" validate:"omitempty,gte=1,lte=10"`
+	// RampAfter is the number of consecutive successful calls to the API
+	// method, after which its rate is increased.
+	RampAfter int `json:"ramp_after,omitempty" yaml:"ramp_after,omitempty" toml:"ramp_after,omitempty" validate:"omitempty,gte=1"`
+}
+
+// Adaptive adjusts the request rate of each API method family based on the
+// responses:  when the method is rate limited, its rate is halved, and when
+// it responds without rate limiting for a while, the rate is increased step
+// by step, up to MaxBoost times the tier rate.  Each method family gets its
+// own limiter, so that backing off on one method does not slow down the
+// others.  It is safe for concurrent use.
+type Adaptive struct {
+	mu        sync.Mutex
+	maxBoost  float64
+	rampAfter int
+	families  map[string]*family
+}
+
+// family is the state of the API method family.
+type family struct {
+	lim         *rate.Limiter
+	base        rate.Limit // tier rate
+	clean       int        // consecutive successful calls
+	rateLimited int64
+	retryAfter  time.Duration
+}
+
+// FamilyStats is the state of the API method family.
+type FamilyStats struct {
+	// Rate is the current rate in requests per minute.
+	Rate float64
+	// Base is the tier rate in requests per minute.
+	Base float64
+	// RateLimited is the number of times the method was rate limited.
+	RateLimited int64
+	// RetryAfter is the last Retry-After value received for the method.
+	RetryAfter time.Duration
+}
+
+// NewAdaptive returns the new Adaptive rate controller.  Zero values of
+// the limit are replaced with the defaults.  The Enabled field is not
+// checked, see [Limits.NewAdaptive].
+func NewAdaptive(l AdaptiveLimit) *Adaptive {
+	a := &Adaptive{
+		maxBoost:  l.MaxBoost,
+		rampAfter: l.RampAfter,
+		families:  make(map[string]*family),
+	}
+	if a.maxBoost < 1 {
+		a.maxBoost = defMaxBoost
+	}
+	if a.rampAfter < 1 {
+		a.rampAfter = defRampAfter
+	}
+	return a
+}
+
+// limiter returns the limiter of the method family.  When called for the
+// first time, the limiter is created with the rate and burst of the tier
+// limiter lim.
+func (a *Adaptive) limiter(name string, lim *rate.Limiter) *rate.Limiter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, ok := a.families[name]
+	if !ok {
+		f = &family{lim: rate.NewLimiter(lim.Limit(), lim.Burst()), base: lim.Limit()}
+		a.families[name] = f
+	}
+	return f.lim
+}
+
+// success registers the successful call to the method family, and ramps up
+// its rate, if the calls were successful for long enough.  It returns the
+// new rate and true, if it was changed.
+func (a *Adaptive) success(name string) (rate.Limit, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, ok := a.families[name]
+	if !ok || f.base == rate.Inf {
+		return 0, false
+	}
+	f.clean++
+	if f.clean < a.rampAfter {
+		return 0, false
+	}
+	f.clean = 0
+	ceiling := f.base * rate.Limit(a.maxBoost)
+	cur := f.lim.Limit()
+	if cur >= ceiling {
+		return 0, false
+	}
+	r := min(cur+f.base*rampStep, ceiling)
+	f.lim.SetLimit(r)
+	return r, true
+}
+
+// limited registers the rate limited call to the method family, and halves
+// its rate.  It returns the new rate.
+func (a *Adaptive) limited(name string, retryAfter time.Duration) rate.Limit {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, ok := a.families[name]
+	if !ok {
+		return 0
+	}
+	f.clean = 0
+	f.rateLimited++
+	f.retryAfter = retryAfter
+	if f.base == rate.Inf {
+		return rate.Inf
+	}
+	r := max(f.lim.Limit()/2, f.base*minFactor)
+	f.lim.SetLimit(r)
+	return r
+}
+
+// Families returns the state of all method families.
+func (a *Adaptive) Families() map[string]FamilyStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ret := make(map[string]FamilyStats, len(a.families))
+	for name, f := range a.families {
+		ret[name] = FamilyStats{
+			Rate:        perMinute(f.lim.Limit()),
+			Base:        perMinute(f.base),
+			RateLimited: f.rateLimited,
+			RetryAfter:  f.retryAfter,
+		}
+	}
+	return ret
+}
+
+func perMinute(l rate.Limit) float64 {
+	return float64(l) * time.Minute.Seconds()
+}
@@ -0,0 +1,81 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestAdaptive(t *testing.T) {
+	const base = rate.Limit(10)
+	a := NewAdaptive(AdaptiveLimit{MaxBoost: 1.5, RampAfter: 2})
+	tier := rate.NewLimiter(base, 3)
+	lim := a.limiter("conversations.history", tier)
+	assert.NotSame(t, tier, lim, "family must have its own limiter")
+	assert.Same(t, lim, a.limiter("conversations.history", tier))
+	assert.Equal(t, base, lim.Limit())
+	assert.Equal(t, 3, lim.Burst())
+
+	t.Run("ramps up to the ceiling", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			a.success("conversations.history")
+		}
+		assert.InDelta(t, float64(base*1.5), float64(lim.Limit()), 1e-9)
+	})
+	t.Run("backs off to the floor", func(t *testing.T) {
+		r := a.limited("conversations.history", 3*time.Second)
+		assert.InDelta(t, float64(base*0.75), float64(r), 1e-9)
+		for i := 0; i < 10; i++ {
+			a.limited("conversations.history", 5*time.Second)
+		}
+		assert.Equal(t, base*minFactor, lim.Limit())
+	})
+	t.Run("backoff resets the clean run", func(t *testing.T) {
+		a.success("conversations.history")
+		a.limited("conversations.history", time.Second)
+		_, changed := a.success("conversations.history")
+		assert.False(t, changed)
+		_, changed = a.success("conversations.history")
+		assert.True(t, changed)
+	})
+	t.Run("other families are not affected", func(t *testing.T) {
+		other := a.limiter("conversations.replies", tier)
+		assert.Equal(t, base, other.Limit())
+	})
+
+	fams := a.Families()
+	assert.Equal(t, int64(12), fams["conversations.history"].RateLimited)
+	assert.Equal(t, time.Second, fams["conversations.history"].RetryAfter)
+	assert.InDelta(t, 600.0, fams["conversations.history"].Base, 1e-9)
+	assert.InDelta(t, 600.0, fams["conversations.replies"].Rate, 1e-9)
+}
+
+func TestNewAdaptive_defaults(t *testing.T) {
+	a := NewAdaptive(AdaptiveLimit{Enabled: true})
+	assert.Equal(t, defMaxBoost, a.maxBoost)
+	assert.Equal(t, defRampAfter, a.rampAfter)
+}
+
+func TestWithAdaptiveRetry(t *testing.T) {
+	a := NewAdaptive(AdaptiveLimit{MaxBoost: 2, RampAfter: 1})
+
+	tier := rate.NewLimiter(testRateLimit, 1)
+	ctx := WithEndpoint(context.Background(), "test.adaptive")
+	if err := WithAdaptiveRetry(ctx, a, tier, 3, retryFn(1, time.Millisecond, nil)); err != nil {
+		t.Fatal(err)
+	}
+	// one rate limited call and one clean call.
+	fs := a.Families()["test.adaptive"]
+	assert.Equal(t, int64(1), fs.RateLimited)
+	assert.InDelta(t, perMinute(testRateLimit*0.6), fs.Rate, 1e-6)
+	assert.Equal(t, rate.Limit(testRateLimit), tier.Limit(), "tier limiter must not be changed")
+
+	// calls without the endpoint are not tracked.
+	if err := WithAdaptiveRetry(context.Background(), a, tier, 3, retryFn(0, 0, nil)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, a.Families(), 1)
+}
@@ -21,6 +21,9 @@ type Limits struct {
 	Tier4 TierLimit `json:"tier_4,omitempty" yaml:"tier_4,omitempty" toml:"tier_4,omitempty"`
 	// Request Limits
 	Request RequestLimit `json:"per_request,omitempty" yaml:"per_request,omitempty" toml:"per_request,omitempty"`
+	// Adaptive rate limiting, adjusts the rate of each API method within
+	// its tier limits.
+	Adaptive AdaptiveLimit `json:"adaptive,omitempty" yaml:"adaptive,omitempty" toml:"adaptive,omitempty"`
 }
 
 // TierLimit represents a Slack API Tier limits.
@@ -67,6 +70,11 @@ var DefLimits = Limits{
 		Channels:      100, // channels are Tier2 rate limited. Slack is greedy and never returns more than 100 per call.
 		Replies:       200, // the API-default is 1000 (see conversations.replies), but on large threads it may fail (see #54)
 	},
+	Adaptive: AdaptiveLimit{
+		Enabled:   false,       // opt-in, as it exceeds the documented tier rates.
+		MaxBoost:  defMaxBoost, // up to twice the tier rate, backs off on the first 429.
+		RampAfter: defRampAfter,
+	},
 }
 
 // NoLimits is setting the limits to high values, effectively disabling them.
@@ -128,9 +136,21 @@ func (o *Limits) Apply(other Limits) error {
 	apply(&o.Request.Conversations, other.Request.Conversations)
 	apply(&o.Request.Channels, other.Request.Channels)
 	apply(&o.Request.Replies, other.Request.Replies)
+	apply(&o.Adaptive.Enabled, other.Adaptive.Enabled)
+	apply(&o.Adaptive.MaxBoost, other.Adaptive.MaxBoost)
+	apply(&o.Adaptive.RampAfter, other.Adaptive.RampAfter)
 	return o.Validate()
 }
 
+// NewAdaptive returns the new adaptive rate controller for the limits, or
+// nil, if the adaptive rate limiting is disabled.
+func (o *Limits) NewAdaptive() *Adaptive {
+	if !o.Adaptive.Enabled {
+		return nil
+	}
+	return NewAdaptive(o.Adaptive)
+}
+
 func (o *Limits) Validate() error {
 	return cfgValidator.Struct(o)
 }
@@ -14,6 +14,7 @@ func TestLimits_Apply(t *testing.T) {
 		Tier3           TierLimit
 		Tier4           TierLimit
 		Request         RequestLimit
+		Adaptive        AdaptiveLimit
 	}
 	type args struct {
 		other Limits
@@ -73,6 +74,7 @@ func TestLimits_Validate(t *testing.T) {
 		Tier3           TierLimit
 		Tier4           TierLimit
 		Request         RequestLimit
+		Adaptive        AdaptiveLimit
 	}
 	tests := []struct {
 		name    string
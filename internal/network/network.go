@@ -65,6 +65,8 @@ func netWait(n int) time.Duration {
 // function wasn't able to complete without errors.
 type ErrRetryFailed struct {
 	Err error
+	// Attempts is the number of attempts made.
+	Attempts int
 }
 
 func (e *ErrRetryFailed) Error() string {
@@ -80,16 +82,70 @@ func (e *ErrRetryFailed) Is(target error) bool {
 	return ok
 }
 
+// Attempts returns the number of attempts made before the operation failed
+// with err.  It returns 1 if err is not an [ErrRetryFailed].
+func Attempts(err error) int {
+	var rf *ErrRetryFailed
+	if errors.As(err, &rf) && rf.Attempts > 0 {
+		return rf.Attempts
+	}
+	return 1
+}
+
+// TransientError wraps the error that the callback considers transient, i.e.
+// an incomplete transfer.  [WithRetry] retries the callback that returned it
+// without an additional delay.
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
 // WithRetry will run the callback function fn. If the function returns
 // slack.RateLimitedError, it will delay, and then call it again up to
 // maxAttempts times. It will return an error if it runs out of attempts.
+//
+// If the context contains the endpoint name (see [WithEndpoint]), the rate
+// limit penalties are recorded in the [PenaltyBox] from the context (see
+// [WithPenaltyBox]), or in the package-wide one, and the outstanding penalty
+// is honoured before the first attempt.
 func WithRetry(ctx context.Context, lim *rate.Limiter, maxAttempts int, fn func() error) error {
+	return WithAdaptiveRetry(ctx, nil, lim, maxAttempts, fn)
+}
+
+// WithAdaptiveRetry is [WithRetry] with the adaptive rate controller ad.  If
+// ad is not nil, and the context contains the endpoint name, the endpoint is
+// throttled by its own limiter, that starts with the rate of lim, and adapts
+// to the rate limiting responses.  If ad is nil, it is equivalent to
+// WithRetry.
+func WithAdaptiveRetry(ctx context.Context, ad *Adaptive, lim *rate.Limiter, maxAttempts int, fn func() error) error {
 	var ok bool
 	if maxAttempts == 0 {
 		maxAttempts = defNumAttempts
 	}
+	endpoint := endpointFromContext(ctx)
 	lg := slog.With("maxAttempts", maxAttempts)
 
+	if ad != nil && endpoint != "" {
+		lim = ad.limiter(endpoint, lim)
+	} else {
+		ad = nil
+	}
+
+	pb := penaltiesFromContext(ctx)
+	if d := pb.Remaining(endpoint); d > 0 {
+		lg.InfoContext(ctx, "endpoint was rate limited recently, sleeping", "endpoint", endpoint, "delay", d.String())
+		if err := sleepCtx(ctx, d); err != nil {
+			return err
+		}
+	}
+
 	var lastErr error
 	for attempt := 0; attempt < maxAttempts; attempt++ {
 		// calling wait to ensure that we don't exceed the rate limit
@@ -102,7 +158,18 @@ func WithRetry(ctx context.Context, lim *rate.Limiter, maxAttempts int, fn func(
 		}
 
 		cbErr := fn()
+		stats.update(endpoint, func(s *EndpointStats) {
+			s.Calls++
+			if cbErr != nil && !strings.EqualFold(cbErr.Error(), "pagination complete") {
+				s.Errors++
+			}
+		})
 		if cbErr == nil {
+			if ad != nil {
+				if r, changed := ad.success(endpoint); changed {
+					lg.DebugContext(ctx, "increasing the request rate", "endpoint", endpoint, "per_minute", perMinute(r))
+				}
+			}
 			ok = true
 			break
 		}
@@ -115,11 +182,18 @@ func WithRetry(ctx context.Context, lim *rate.Limiter, maxAttempts int, fn func(
 			rle *slack.RateLimitedError
 			sce slack.StatusCodeError
 			ne  *net.OpError // read tcp error: see #234
+			te  *TransientError
 		)
 		switch {
 		case errors.As(cbErr, &rle):
 			slog.InfoContext(ctx, "got rate limited, sleeping", "retry_after_sec", rle.RetryAfter, "error", cbErr)
 			tracelogf(ctx, "info", "got rate limited, sleeping %s (%s)", rle.RetryAfter, cbErr)
+			pb.Record(endpoint, rle.RetryAfter)
+			if ad != nil {
+				r := ad.limited(endpoint, rle.RetryAfter)
+				lg.DebugContext(ctx, "decreasing the request rate", "endpoint", endpoint, "per_minute", perMinute(r))
+			}
+			stats.update(endpoint, func(s *EndpointStats) { s.RateLimited++ })
 			if err := sleepCtx(ctx, rle.RetryAfter); err != nil {
 				return err
 			}
@@ -147,12 +221,16 @@ func WithRetry(ctx context.Context, lim *rate.Limiter, maxAttempts int, fn func(
 				}
 				continue
 			}
+		case errors.As(cbErr, &te):
+			slog.WarnContext(ctx, "got transient error, retrying", "error", cbErr)
+			tracelogf(ctx, "info", "got transient error, retrying (%s)", cbErr)
+			continue
 		}
 
 		return fmt.Errorf("callback error: %w", cbErr)
 	}
 	if !ok {
-		return &ErrRetryFailed{Err: lastErr}
+		return &ErrRetryFailed{Err: lastErr, Attempts: maxAttempts}
 	}
 	return nil
 }
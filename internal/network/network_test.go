@@ -170,6 +170,17 @@ func TestWithRetry(t *testing.T) {
 			false,
 			calcExpRunDuration(2),
 		},
+		{
+			"transient error",
+			args{
+				context.Background(),
+				rate.NewLimiter(10.0, 1),
+				3,
+				errSeqFn(&TransientError{Err: errors.New("short read")}, 2, nil),
+			},
+			false,
+			calcRunDuration(10.0, 2),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -281,6 +292,13 @@ func TestWithRetry(t *testing.T) {
 	})
 }
 
+func TestAttempts(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, 1, Attempts(errors.New("not today")))
+	assert.Equal(t, 3, Attempts(&ErrRetryFailed{Err: errors.New("not today"), Attempts: 3}))
+	assert.Equal(t, 3, Attempts(fmt.Errorf("wrapped: %w", &ErrRetryFailed{Attempts: 3})))
+}
+
 func Test_cubicWait(t *testing.T) {
 	t.Parallel()
 	type args struct {
@@ -0,0 +1,167 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// penaltyTTL is the maximum age of the penalty record, after which it is
+// considered irrelevant and is discarded on load.
+const penaltyTTL = 1 * time.Hour
+
+// Penalty is the rate limit penalty observed for an API endpoint.
+type Penalty struct {
+	// Last is the time of the last "429 Too Many Requests" response.
+	Last time.Time `json:"last"`
+	// RetryAfter is the value of the Retry-After header of the last
+	// response.
+	RetryAfter time.Duration `json:"retry_after"`
+	// Count is the number of times the endpoint was rate limited.
+	Count int `json:"count"`
+}
+
+// Until returns the time until which the endpoint is penalised.
+func (p Penalty) Until() time.Time {
+	return p.Last.Add(p.RetryAfter)
+}
+
+// PenaltyBox tracks the rate limit penalties per endpoint.  It is safe for
+// concurrent use.  Zero value is usable.
+type PenaltyBox struct {
+	mu        sync.RWMutex
+	endpoints map[string]Penalty
+}
+
+// NewPenaltyBox returns an empty PenaltyBox.
+func NewPenaltyBox() *PenaltyBox {
+	return &PenaltyBox{endpoints: make(map[string]Penalty)}
+}
+
+// Record records the rate limit penalty for the endpoint.
+func (pb *PenaltyBox) Record(endpoint string, retryAfter time.Duration) {
+	if endpoint == "" {
+		return
+	}
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if pb.endpoints == nil {
+		pb.endpoints = make(map[string]Penalty)
+	}
+	p := pb.endpoints[endpoint]
+	p.Last = time.Now()
+	p.RetryAfter = retryAfter
+	p.Count++
+	pb.endpoints[endpoint] = p
+}
+
+// Remaining returns the remaining penalty time for the endpoint.  It returns
+// zero if the endpoint is not penalised.
+func (pb *PenaltyBox) Remaining(endpoint string) time.Duration {
+	if endpoint == "" {
+		return 0
+	}
+	pb.mu.RLock()
+	defer pb.mu.RUnlock()
+	p, ok := pb.endpoints[endpoint]
+	if !ok {
+		return 0
+	}
+	if d := time.Until(p.Until()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Penalties returns a copy of all penalties.
+func (pb *PenaltyBox) Penalties() map[string]Penalty {
+	pb.mu.RLock()
+	defer pb.mu.RUnlock()
+	ret := make(map[string]Penalty, len(pb.endpoints))
+	for k, v := range pb.endpoints {
+		ret[k] = v
+	}
+	return ret
+}
+
+// Save writes the penalties to w.
+func (pb *PenaltyBox) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(pb.Penalties())
+}
+
+// LoadPenaltyBox reads the penalties from r.  Penalties that are older than
+// penaltyTTL are discarded.
+func LoadPenaltyBox(r io.Reader) (*PenaltyBox, error) {
+	var m map[string]Penalty
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	pb := NewPenaltyBox()
+	for k, v := range m {
+		if time.Since(v.Last) > penaltyTTL {
+			continue
+		}
+		pb.endpoints[k] = v
+	}
+	return pb, nil
+}
+
+var (
+	penalties   = NewPenaltyBox()
+	penaltiesMu sync.RWMutex
+)
+
+// SetPenaltyBox sets the package-wide PenaltyBox, i.e. one loaded from the
+// previous run.
+func SetPenaltyBox(pb *PenaltyBox) {
+	if pb == nil {
+		return
+	}
+	penaltiesMu.Lock()
+	defer penaltiesMu.Unlock()
+	penalties = pb
+}
+
+// Penalties returns the package-wide PenaltyBox.
+func Penalties() *PenaltyBox {
+	penaltiesMu.RLock()
+	defer penaltiesMu.RUnlock()
+	return penalties
+}
+
+type penaltyBoxKey struct{}
+
+// WithPenaltyBox returns the context with the PenaltyBox pb, that is used by
+// [WithRetry] instead of the package-wide one.  It allows to keep the
+// penalties of different workspaces apart, as they have separate rate
+// limits.
+func WithPenaltyBox(ctx context.Context, pb *PenaltyBox) context.Context {
+	return context.WithValue(ctx, penaltyBoxKey{}, pb)
+}
+
+// penaltiesFromContext returns the PenaltyBox from the context, or the
+// package-wide one, if the context has none.
+func penaltiesFromContext(ctx context.Context) *PenaltyBox {
+	if pb, ok := ctx.Value(penaltyBoxKey{}).(*PenaltyBox); ok && pb != nil {
+		return pb
+	}
+	return Penalties()
+}
+
+type endpointKey struct{}
+
+// WithEndpoint returns the context with the API endpoint name, which is used
+// by [WithRetry] to track rate limit penalties per endpoint.
+func WithEndpoint(ctx context.Context, endpoint string) context.Context {
+	return context.WithValue(ctx, endpointKey{}, endpoint)
+}
+
+// endpointFromContext returns the endpoint name from the context.
+func endpointFromContext(ctx context.Context) string {
+	ep, _ := ctx.Value(endpointKey{}).(string)
+	return ep
+}
@@ -0,0 +1,68 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPenaltyBox_Remaining(t *testing.T) {
+	pb := NewPenaltyBox()
+	if d := pb.Remaining("conversations.history"); d != 0 {
+		t.Errorf("Remaining() = %s, want 0", d)
+	}
+	pb.Record("conversations.history", 10*time.Second)
+	if d := pb.Remaining("conversations.history"); d <= 0 || d > 10*time.Second {
+		t.Errorf("Remaining() = %s, want (0, 10s]", d)
+	}
+	if d := pb.Remaining("conversations.replies"); d != 0 {
+		t.Errorf("Remaining() = %s, want 0 for other endpoint", d)
+	}
+	pb.Record("conversations.history", 0)
+	if got := pb.Penalties()["conversations.history"].Count; got != 2 {
+		t.Errorf("Count = %d, want 2", got)
+	}
+}
+
+func TestPenaltyBox_SaveLoad(t *testing.T) {
+	pb := NewPenaltyBox()
+	pb.Record("users.list", time.Minute)
+	pb.endpoints["stale"] = Penalty{Last: time.Now().Add(-2 * penaltyTTL), RetryAfter: time.Second, Count: 1}
+
+	var buf bytes.Buffer
+	if err := pb.Save(&buf); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := LoadPenaltyBox(&buf)
+	if err != nil {
+		t.Fatalf("LoadPenaltyBox() error = %v", err)
+	}
+	if d := got.Remaining("users.list"); d <= 0 {
+		t.Errorf("Remaining() = %s, want > 0", d)
+	}
+	if _, ok := got.Penalties()["stale"]; ok {
+		t.Error("stale penalty was loaded")
+	}
+}
+
+func TestWithEndpoint(t *testing.T) {
+	ctx := WithEndpoint(context.Background(), "search.messages")
+	if got := endpointFromContext(ctx); got != "search.messages" {
+		t.Errorf("endpointFromContext() = %q, want %q", got, "search.messages")
+	}
+	if got := endpointFromContext(context.Background()); got != "" {
+		t.Errorf("endpointFromContext() = %q, want empty", got)
+	}
+}
+
+func TestWithPenaltyBox(t *testing.T) {
+	pb := NewPenaltyBox()
+	ctx := WithPenaltyBox(context.Background(), pb)
+	if got := penaltiesFromContext(ctx); got != pb {
+		t.Error("penaltiesFromContext() did not return the context PenaltyBox")
+	}
+	if got := penaltiesFromContext(context.Background()); got != Penalties() {
+		t.Error("penaltiesFromContext() did not return the package-wide PenaltyBox")
+	}
+}
@@ -0,0 +1,60 @@
+package network
+
+import (
+	"sync"
+)
+
+// EndpointStats contains the API call statistics of the endpoint.
+type EndpointStats struct {
+	// Calls is the number of the API call attempts.
+	Calls int64
+	// Errors is the number of the failed attempts, including the rate
+	// limited ones.
+	Errors int64
+	// RateLimited is the number of the attempts that were rate limited.
+	RateLimited int64
+}
+
+// CallStats collects the API call statistics per endpoint.  It is safe for
+// concurrent use.  Zero value is usable.
+type CallStats struct {
+	mu sync.Mutex
+	m  map[string]EndpointStats
+}
+
+// unknownEndpoint is the name used for the calls without the endpoint in the
+// context, see [WithEndpoint].
+const unknownEndpoint = "unknown"
+
+func (cs *CallStats) update(endpoint string, fn func(*EndpointStats)) {
+	if endpoint == "" {
+		endpoint = unknownEndpoint
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.m == nil {
+		cs.m = make(map[string]EndpointStats)
+	}
+	s := cs.m[endpoint]
+	fn(&s)
+	cs.m[endpoint] = s
+}
+
+// Snapshot returns the copy of the statistics.
+func (cs *CallStats) Snapshot() map[string]EndpointStats {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	ret := make(map[string]EndpointStats, len(cs.m))
+	for ep, s := range cs.m {
+		ret[ep] = s
+	}
+	return ret
+}
+
+var stats CallStats
+
+// Stats returns the package-wide API call statistics, collected by
+// [WithRetry].
+func Stats() *CallStats {
+	return &stats
+}
@@ -0,0 +1,62 @@
+package osext
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// AtomicFile is a file, that replaces the target file only when it's closed.
+// The data is written to the temporary file in the same directory, which is
+// renamed to the target name on [AtomicFile.Close], so that the readers see
+// either the previous or the new version of the file, but never the partially
+// written one.
+type AtomicFile struct {
+	*os.File
+	name string
+	done bool
+}
+
+// CreateAtomic creates the temporary file for the file name.  The temporary
+// file name starts with a dot and ends with ".tmp".  Caller must call Close
+// to replace the target file, or Abort to discard the written data.
+func CreateAtomic(name string) (*AtomicFile, error) {
+	dir, base := filepath.Split(name)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.CreateTemp(dir, "."+base+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	return &AtomicFile{File: f, name: name}, nil
+}
+
+// Close flushes the data to disk, closes the temporary file and renames it
+// to the target name.  If any of the steps fails, the temporary file is
+// removed, and the target file is left intact.
+func (f *AtomicFile) Close() error {
+	if f.done {
+		return os.ErrClosed
+	}
+	f.done = true
+	err := errors.Join(f.File.Sync(), f.File.Close())
+	if err == nil {
+		err = os.Rename(f.File.Name(), f.name)
+	}
+	if err != nil {
+		_ = os.Remove(f.File.Name())
+		return err
+	}
+	return nil
+}
+
+// Abort closes and removes the temporary file.  It is a no-op, if the file
+// was already closed, so it's safe to defer it right after [CreateAtomic].
+func (f *AtomicFile) Abort() error {
+	if f.done {
+		return nil
+	}
+	f.done = true
+	return errors.Join(f.File.Close(), os.Remove(f.File.Name()))
+}
@@ -0,0 +1,52 @@
+package osext
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtomicFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "file.txt")
+	require.NoError(t, os.WriteFile(name, []byte("old"), 0o644))
+
+	t.Run("abort keeps the original file", func(t *testing.T) {
+		f, err := CreateAtomic(name)
+		require.NoError(t, err)
+		_, err = f.WriteString("partial")
+		require.NoError(t, err)
+		require.NoError(t, f.Abort())
+		assertContents(t, name, "old")
+		assertNoTemp(t, dir)
+	})
+	t.Run("close replaces the file", func(t *testing.T) {
+		f, err := CreateAtomic(name)
+		require.NoError(t, err)
+		defer f.Abort()
+		_, err = f.WriteString("new")
+		require.NoError(t, err)
+		assertContents(t, name, "old") // not replaced until closed
+		require.NoError(t, f.Close())
+		assertContents(t, name, "new")
+		assertNoTemp(t, dir)
+		assert.ErrorIs(t, f.Close(), os.ErrClosed)
+	})
+}
+
+func assertContents(t *testing.T, name, want string) {
+	t.Helper()
+	got, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got))
+}
+
+func assertNoTemp(t *testing.T, dir string) {
+	t.Helper()
+	tmp, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, tmp)
+}
@@ -2,26 +2,204 @@ package osext
 
 import (
 	"compress/gzip"
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 const tempMask = "osext-*"
 
+// Compression is the compression algorithm of the file.
+type Compression string
+
+const (
+	CompressNone Compression = ""
+	CompressGZIP Compression = "gzip"
+	CompressZSTD Compression = "zstd"
+)
+
+// ErrCompression is returned if the compression algorithm is not supported.
+var ErrCompression = errors.New("unsupported compression, use one of: gzip, zstd")
+
+// compression extensions
+const (
+	extGZIP = ".gz"
+	extZSTD = ".zst"
+)
+
+// ParseCompression parses the compression name, empty string and "none" mean
+// no compression.
+func ParseCompression(s string) (Compression, error) {
+	switch c := Compression(strings.ToLower(s)); c {
+	case CompressNone, "none":
+		return CompressNone, nil
+	case CompressGZIP, "gz":
+		return CompressGZIP, nil
+	case CompressZSTD, "zst":
+		return CompressZSTD, nil
+	default:
+		return CompressNone, fmt.Errorf("%w: %q", ErrCompression, s)
+	}
+}
+
+// CompressionOf returns the compression of the file, detected by the file
+// name suffix.
+func CompressionOf(filename string) Compression {
+	switch {
+	case strings.HasSuffix(filename, extGZIP):
+		return CompressGZIP
+	case strings.HasSuffix(filename, extZSTD):
+		return CompressZSTD
+	default:
+		return CompressNone
+	}
+}
+
+// Ext returns the file name suffix of the compression.
+func (c Compression) Ext() string {
+	switch c {
+	case CompressGZIP:
+		return extGZIP
+	case CompressZSTD:
+		return extZSTD
+	default:
+		return ""
+	}
+}
+
+// WithExt appends the compression suffix to the filename, unless it is
+// already there.
+func (c Compression) WithExt(filename string) string {
+	if ext := c.Ext(); ext != "" && !strings.HasSuffix(filename, ext) {
+		return filename + ext
+	}
+	return filename
+}
+
+// NewWriter returns the writer, that compresses the data written to w.
+// Closing the writer flushes the compressed stream, but does not close w.
+func (c Compression) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	switch c {
+	case CompressNone:
+		return nopWriteCloser{w}, nil
+	case CompressGZIP:
+		return gzip.NewWriter(w), nil
+	case CompressZSTD:
+		return zstd.NewWriter(w)
+	default:
+		return nil, ErrCompression
+	}
+}
+
+// NewReader returns the reader, that decompresses the data read from r.
+func (c Compression) NewReader(r io.Reader) (io.ReadCloser, error) {
+	switch c {
+	case CompressNone:
+		return io.NopCloser(r), nil
+	case CompressGZIP:
+		return gzip.NewReader(r)
+	case CompressZSTD:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return nil, ErrCompression
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// CreateFile creates the file, compressed according to the filename suffix
+// (".gz" or ".zst").  Closing the returned writer closes the file.
+func CreateFile(filename string) (io.WriteCloser, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	cw, err := CompressionOf(filename).NewWriter(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &fileWriter{WriteCloser: cw, f: f}, nil
+}
+
+// fileWriter closes the compressor and the underlying file.
+type fileWriter struct {
+	io.WriteCloser
+	f *os.File
+}
+
+// Name returns the name of the file.
+func (w *fileWriter) Name() string {
+	return w.f.Name()
+}
+
+func (w *fileWriter) Close() error {
+	if err := w.WriteCloser.Close(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// OpenFile opens the file for reading.  If the filename has the compression
+// suffix (".gz" or ".zst"), the file is decompressed into a temporary file,
+// which is removed on close.
+func OpenFile(filename string) (io.ReadSeekCloser, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	c := CompressionOf(filename)
+	if c == CompressNone {
+		return f, nil
+	}
+	defer f.Close()
+	tf, err := unpack(f, c)
+	if err != nil {
+		return nil, err
+	}
+	return RemoveOnClose(tf), nil
+}
+
 // UnGZIP decompresses a gzip file and returns a temporary file handler.
 // it must be removed after use.  It expects r to contain a gzip file data.
 func UnGZIP(r io.Reader) (*os.File, error) {
-	gr, err := gzip.NewReader(r)
+	return unpack(r, CompressGZIP)
+}
+
+// UnZSTD decompresses a zstd file and returns a temporary file handler.
+// it must be removed after use.  It expects r to contain a zstd file data.
+func UnZSTD(r io.Reader) (*os.File, error) {
+	return unpack(r, CompressZSTD)
+}
+
+// unpack decompresses r with the compression c into a temporary file.
+func unpack(r io.Reader, c Compression) (*os.File, error) {
+	cr, err := c.NewReader(r)
 	if err != nil {
 		return nil, err
 	}
-	defer gr.Close()
+	defer cr.Close()
 	f, err := os.CreateTemp("", tempMask)
 	if err != nil {
 		return nil, err
 	}
-	_, err = io.Copy(f, gr)
+	_, err = io.Copy(f, cr)
 	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
 		return nil, err
 	}
 	if err := f.Sync(); err != nil {
@@ -93,3 +93,70 @@ func TestUnGZIP(t *testing.T) {
 		})
 	}
 }
+
+func TestCompression_roundtrip(t *testing.T) {
+	d := t.TempDir()
+	for _, name := range []string{"plain.jsonl", "rec.jsonl.gz", "rec.jsonl.zst"} {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(d, name)
+			w, err := CreateFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := io.WriteString(w, "test\n"); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if CompressionOf(name) != CompressNone {
+				raw, err := os.ReadFile(filename)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.NotEqual(t, []byte("test\n"), raw, "not compressed")
+			}
+			r, err := OpenFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, []byte("test\n"), got)
+		})
+	}
+}
+
+func TestParseCompression(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Compression
+		wantErr bool
+	}{
+		{"", CompressNone, false},
+		{"none", CompressNone, false},
+		{"gzip", CompressGZIP, false},
+		{"GZ", CompressGZIP, false},
+		{"zstd", CompressZSTD, false},
+		{"zst", CompressZSTD, false},
+		{"lz4", CompressNone, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseCompression(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCompression() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompression_WithExt(t *testing.T) {
+	assert.Equal(t, "a.jsonl", CompressNone.WithExt("a.jsonl"))
+	assert.Equal(t, "a.jsonl.gz", CompressGZIP.WithExt("a.jsonl"))
+	assert.Equal(t, "a.jsonl.zst", CompressZSTD.WithExt("a.jsonl.zst"))
+}
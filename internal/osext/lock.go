@@ -0,0 +1,34 @@
+package osext
+
+import "os"
+
+// FileLock is the exclusive advisory lock, held on the lock file.  The lock
+// is shared between the processes, but it's not reentrant:  acquiring the
+// lock on the same file twice, without releasing it first, blocks forever.
+type FileLock struct {
+	f *os.File
+}
+
+// LockFile acquires the exclusive lock on the file name, creating it, if it
+// doesn't exist.  It blocks until the lock is acquired.  The lock file is not
+// removed on Unlock, as another process might be waiting on it.
+func LockFile(name string) (*FileLock, error) {
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock(f); err != nil {
+		f.Close()
+		return nil, &Error{File: name, Err: err}
+	}
+	return &FileLock{f: f}, nil
+}
+
+// Unlock releases the lock.
+func (l *FileLock) Unlock() error {
+	if err := unlock(l.f); err != nil {
+		l.f.Close()
+		return &Error{File: l.f.Name(), Err: err}
+	}
+	return l.f.Close()
+}
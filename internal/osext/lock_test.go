@@ -0,0 +1,48 @@
+package osext
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), ".lock")
+	const workers = 8
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		holders int
+		maxSeen int
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l, err := LockFile(name)
+			if !assert.NoError(t, err) {
+				return
+			}
+			mu.Lock()
+			holders++
+			maxSeen = max(maxSeen, holders)
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			holders--
+			mu.Unlock()
+			assert.NoError(t, l.Unlock())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, maxSeen, "lock must be held by one holder at a time")
+
+	// the lock can be acquired again after it's released.
+	l, err := LockFile(name)
+	require.NoError(t, err)
+	require.NoError(t, l.Unlock())
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package osext
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockRange is the number of bytes locked.  Locking the first byte is
+// enough for the advisory lock, the lock file itself is empty.
+const lockRange = 1
+
+func lock(f *os.File) error {
+	var ol windows.Overlapped
+	return windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, lockRange, 0, &ol)
+}
+
+func unlock(f *os.File) error {
+	var ol windows.Overlapped
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, lockRange, 0, &ol)
+}
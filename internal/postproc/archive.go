@@ -0,0 +1,106 @@
+package postproc
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// archiveExt returns the file extension for the archive format.
+func archiveExt(format string) string {
+	if format == FormatTarGz {
+		return ".tar.gz"
+	}
+	return ".zip"
+}
+
+// compressDir packs the directory dir into the archive of the format, and
+// writes it to w.
+func compressDir(ctx context.Context, w io.Writer, dir, format string) error {
+	if format == FormatTarGz {
+		return tarGzDir(ctx, w, dir)
+	}
+	return zipDir(ctx, w, dir)
+}
+
+// walkFiles calls fn for each regular file in dir, with the slash-separated
+// path relative to dir.
+func walkFiles(ctx context.Context, dir string, fn func(name, path string, fi fs.FileInfo) error) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(rel), path, fi)
+	})
+}
+
+func copyFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func zipDir(ctx context.Context, w io.Writer, dir string) error {
+	zw := zip.NewWriter(w)
+	if err := walkFiles(ctx, dir, func(name, path string, fi fs.FileInfo) error {
+		hdr, err := zip.FileInfoHeader(fi)
+		if err != nil {
+			return err
+		}
+		hdr.Name = name
+		hdr.Method = zip.Deflate
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		return copyFile(fw, path)
+	}); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func tarGzDir(ctx context.Context, w io.Writer, dir string) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	if err := walkFiles(ctx, dir, func(name, path string, fi fs.FileInfo) error {
+		hdr, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = name
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		return copyFile(tw, path)
+	}); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
@@ -0,0 +1,172 @@
+// Package postproc implements the post-processing pipeline, that is run
+// after the successful export: it compresses the export directory, encrypts
+// the archive with age, and uploads it to the remote destination.  The
+// pipeline is configured declaratively with the TOML file:
+//
+//	[compress]
+//	format = "tar.gz"
+//
+//	[encrypt]
+//	recipients = ["age1..."]
+//
+//	[upload]
+//	destination = "s3://bucket/backups"
+//	remove_local = true
+//
+// The progress is saved to the state file next to the export, so that the
+// interrupted pipeline, i.e. a partially uploaded archive, can be resumed.
+package postproc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/BurntSushi/toml"
+
+	"github.com/rusq/slackdump/v3/internal/remotefs"
+)
+
+// Archive formats.
+const (
+	FormatZIP   = "zip"
+	FormatTarGz = "tar.gz"
+)
+
+// DefaultRetries is the default number of the upload retries.
+const DefaultRetries = 3
+
+// Config is the pipeline configuration.  Each of the stages is optional,
+// but at least one must be set.
+type Config struct {
+	Compress *Compress `toml:"compress"`
+	Encrypt  *Encrypt  `toml:"encrypt"`
+	Upload   *Upload   `toml:"upload"`
+}
+
+// Compress is the compression stage configuration.  The export directory is
+// packed into the archive of the format.  Exports that are already in a ZIP
+// file are not compressed again.
+type Compress struct {
+	// Format is the archive format: "zip" (default) or "tar.gz".
+	Format string `toml:"format"`
+}
+
+// Encrypt is the encryption stage configuration.  The archive is encrypted
+// with age to each of the recipients.
+type Encrypt struct {
+	// Recipients is the list of the age X25519 recipients, "age1...".
+	Recipients []string `toml:"recipients"`
+	// RecipientsFile is the file with the recipients, one per line, as
+	// accepted by "age -R".  Empty lines and comments are ignored.
+	RecipientsFile string `toml:"recipients_file"`
+}
+
+// Upload is the upload stage configuration.
+type Upload struct {
+	// Destination is the remote location, i.e. "s3://bucket/prefix" or
+	// "sftp://user@host/backups".
+	Destination string `toml:"destination"`
+	// Retries is the number of the retries of each upload request.
+	Retries int `toml:"retries"`
+	// RemoveLocal removes the archive and the encrypted file created by the
+	// pipeline, once they are uploaded.  The export itself is not removed.
+	RemoveLocal bool `toml:"remove_local"`
+}
+
+// ErrEmptyConfig is returned if none of the stages is configured.
+var ErrEmptyConfig = errors.New("post-processing configuration has no stages")
+
+// Load loads and validates the configuration from the file.
+func Load(filename string) (*Config, error) {
+	var cfg Config
+	md, err := toml.DecodeFile(filename, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("post-processing configuration: %w", err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("post-processing configuration: unknown keys: %v", undecoded)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("post-processing configuration: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Validate validates the configuration and sets the defaults.
+func (c *Config) Validate() error {
+	if c.Compress == nil && c.Encrypt == nil && c.Upload == nil {
+		return ErrEmptyConfig
+	}
+	if c.Compress != nil {
+		switch c.Compress.Format {
+		case "":
+			c.Compress.Format = FormatZIP
+		case FormatZIP, FormatTarGz:
+		default:
+			return fmt.Errorf("unknown archive format %q, must be %q or %q", c.Compress.Format, FormatZIP, FormatTarGz)
+		}
+	}
+	if c.Encrypt != nil {
+		if _, err := c.Encrypt.recipients(); err != nil {
+			return err
+		}
+	}
+	if c.Upload != nil {
+		if !remotefs.IsRemote(c.Upload.Destination) {
+			return fmt.Errorf("upload destination %q is not a remote location", c.Upload.Destination)
+		}
+		if c.Upload.Retries < 0 {
+			return errors.New("upload retries must not be negative")
+		}
+		if c.Upload.Retries == 0 {
+			c.Upload.Retries = DefaultRetries
+		}
+	}
+	return nil
+}
+
+// recipients returns the parsed recipients.
+func (e *Encrypt) recipients() ([]age.Recipient, error) {
+	lines := append([]string{}, e.Recipients...)
+	if e.RecipientsFile != "" {
+		fl, err := readRecipients(e.RecipientsFile)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, fl...)
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("encryption requires at least one recipient")
+	}
+	ret := make([]age.Recipient, 0, len(lines))
+	for _, l := range lines {
+		r, err := age.ParseX25519Recipient(l)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, r)
+	}
+	return ret, nil
+}
+
+func readRecipients(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var ret []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		l := strings.TrimSpace(sc.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		ret = append(ret, l)
+	}
+	return ret, sc.Err()
+}
@@ -0,0 +1,250 @@
+package postproc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"github.com/rusq/fsadapter"
+
+	"github.com/rusq/slackdump/v3/internal/osext"
+	"github.com/rusq/slackdump/v3/internal/remotefs"
+)
+
+// StateSuffix is appended to the export location to get the name of the
+// pipeline state file.
+const StateSuffix = ".post.json"
+
+// State is the progress of the pipeline.  It is saved after each completed
+// step, and removed once the pipeline completes.
+type State struct {
+	Source    string       `json:"source"`
+	Archive   string       `json:"archive,omitempty"`
+	Encrypted string       `json:"encrypted,omitempty"`
+	Upload    *UploadState `json:"upload,omitempty"`
+}
+
+// UploadState is the progress of the multipart upload.
+type UploadState struct {
+	Destination string          `json:"destination"`
+	Name        string          `json:"name"`
+	Size        int64           `json:"size"`
+	PartSize    int             `json:"part_size"`
+	UploadID    string          `json:"upload_id"`
+	Parts       []remotefs.Part `json:"parts,omitempty"`
+}
+
+// matches returns true if the upload can be resumed for the file.
+func (u *UploadState) matches(dest, name string, size int64, partSize int) bool {
+	return u != nil && u.UploadID != "" && u.Destination == dest && u.Name == name && u.Size == size && u.PartSize == partSize
+}
+
+// Pipeline is the post-processing pipeline.
+type Pipeline struct {
+	cfg        *Config
+	recipients []age.Recipient
+	lg         *slog.Logger
+	openFS     func(string) (fsadapter.FSCloser, error)
+	retryDelay time.Duration
+}
+
+// Option configures the Pipeline.
+type Option func(*Pipeline)
+
+// WithLogger sets the logger.
+func WithLogger(lg *slog.Logger) Option {
+	return func(p *Pipeline) {
+		if lg != nil {
+			p.lg = lg
+		}
+	}
+}
+
+// New creates the pipeline from the configuration.
+func New(cfg *Config, opts ...Option) (*Pipeline, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	p := &Pipeline{
+		cfg:        cfg,
+		lg:         slog.Default(),
+		openFS:     remotefs.New,
+		retryDelay: time.Second,
+	}
+	if cfg.Encrypt != nil {
+		var err error
+		if p.recipients, err = cfg.Encrypt.recipients(); err != nil {
+			return nil, err
+		}
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+// StateFile returns the name of the state file for the export location.
+func StateFile(source string) string {
+	return strings.TrimRight(source, `/\`) + StateSuffix
+}
+
+// ResetState removes the state file of the previous run for the export
+// location, it should be called when the export is made anew, so that the
+// stale artifacts are not reused.
+func ResetState(source string) error {
+	if err := os.Remove(StateFile(source)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Run runs the pipeline on the export location source, that is a directory
+// or a ZIP file.  If the state file of the previous run exists, the
+// completed steps are skipped, and the interrupted upload is resumed.  The
+// state file is removed once the pipeline completes.
+func (p *Pipeline) Run(ctx context.Context, source string) error {
+	source = strings.TrimRight(source, `/\`)
+	fi, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	stfile := StateFile(source)
+	st, err := loadState(stfile, source)
+	if err != nil {
+		return err
+	}
+	save := func() error {
+		return saveState(stfile, st)
+	}
+
+	artifact := source
+	compress := p.cfg.Compress
+	if !fi.IsDir() {
+		if compress != nil {
+			p.lg.InfoContext(ctx, "export is a file, skipping compression", "source", source)
+		}
+		compress = nil
+	} else if compress == nil && (p.cfg.Encrypt != nil || p.cfg.Upload != nil) {
+		// encryption and upload require a single file.
+		compress = &Compress{Format: FormatZIP}
+	}
+	if compress != nil {
+		if !exists(st.Archive) {
+			st.Archive = source + archiveExt(compress.Format)
+			p.lg.InfoContext(ctx, "compressing the export", "source", source, "archive", st.Archive)
+			if err := writeAtomic(st.Archive, func(w io.Writer) error {
+				return compressDir(ctx, w, source, compress.Format)
+			}); err != nil {
+				return fmt.Errorf("compression failed: %w", err)
+			}
+			if err := save(); err != nil {
+				return err
+			}
+		}
+		artifact = st.Archive
+	}
+	if p.cfg.Encrypt != nil {
+		if !exists(st.Encrypted) {
+			st.Encrypted = artifact + ".age"
+			p.lg.InfoContext(ctx, "encrypting", "file", artifact, "recipients", len(p.recipients))
+			if err := writeAtomic(st.Encrypted, func(w io.Writer) error {
+				return encryptFile(w, artifact, p.recipients)
+			}); err != nil {
+				return fmt.Errorf("encryption failed: %w", err)
+			}
+			if err := save(); err != nil {
+				return err
+			}
+		}
+		artifact = st.Encrypted
+	}
+	if p.cfg.Upload != nil {
+		if err := p.upload(ctx, artifact, st, save); err != nil {
+			return fmt.Errorf("upload failed: %w", err)
+		}
+		if p.cfg.Upload.RemoveLocal {
+			for _, f := range []string{st.Archive, st.Encrypted} {
+				if f != "" && f != source {
+					if err := os.Remove(f); err != nil && !errors.Is(err, os.ErrNotExist) {
+						p.lg.WarnContext(ctx, "unable to remove the local file", "file", f, "error", err)
+					}
+				}
+			}
+		}
+	}
+	if err := ResetState(source); err != nil {
+		return err
+	}
+	p.lg.InfoContext(ctx, "post-processing completed", "result", artifact)
+	return nil
+}
+
+func exists(name string) bool {
+	if name == "" {
+		return false
+	}
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// writeAtomic writes the file with fn through the temporary file, so that
+// the incomplete file is never left under the final name.
+func writeAtomic(name string, fn func(w io.Writer) error) error {
+	f, err := osext.CreateAtomic(name)
+	if err != nil {
+		return err
+	}
+	defer f.Abort()
+	if err := fn(f); err != nil {
+		return err
+	}
+	// temporary files are created with 0600.
+	if err := f.Chmod(0o644); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func encryptFile(w io.Writer, name string, recipients []age.Recipient) error {
+	ew, err := age.Encrypt(w, recipients...)
+	if err != nil {
+		return err
+	}
+	if err := copyFile(ew, name); err != nil {
+		return err
+	}
+	return ew.Close()
+}
+
+func loadState(name, source string) (*State, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &State{Source: source}, nil
+		}
+		return nil, err
+	}
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("invalid post-processing state file %s: %w", name, err)
+	}
+	if st.Source != source {
+		return &State{Source: source}, nil
+	}
+	return &st, nil
+}
+
+func saveState(name string, st *State) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0o644)
+}
@@ -0,0 +1,276 @@
+package postproc
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/rusq/fsadapter"
+
+	"github.com/rusq/slackdump/v3/internal/remotefs"
+)
+
+// fakeRemote is the in-memory remote filesystem, that supports the
+// multipart uploads.
+type fakeRemote struct {
+	partSize int
+
+	mu       sync.Mutex
+	objects  map[string][]byte
+	parts    map[string]map[int][]byte
+	creates  int
+	uploads  int
+	failPart int // part number, that fails to upload
+}
+
+func newFakeRemote(partSize int) *fakeRemote {
+	return &fakeRemote{partSize: partSize, objects: make(map[string][]byte), parts: make(map[string]map[int][]byte)}
+}
+
+func (f *fakeRemote) open(string) (fsadapter.FSCloser, error) { return f, nil }
+
+type objWriter struct {
+	bytes.Buffer
+	f    *fakeRemote
+	name string
+}
+
+func (w *objWriter) Close() error {
+	return w.f.WriteFile(w.name, w.Bytes(), 0o644)
+}
+
+func (f *fakeRemote) Create(name string) (io.WriteCloser, error) {
+	return &objWriter{f: f, name: name}, nil
+}
+
+func (f *fakeRemote) WriteFile(name string, data []byte, _ os.FileMode) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[name] = bytes.Clone(data)
+	return nil
+}
+
+func (f *fakeRemote) Close() error { return nil }
+
+func (f *fakeRemote) PartSize() int { return f.partSize }
+
+func (f *fakeRemote) CreateMultipart(_ context.Context, name string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.creates++
+	id := name + "-upload"
+	f.parts[id] = make(map[int][]byte)
+	return id, nil
+}
+
+func (f *fakeRemote) UploadPart(_ context.Context, name, uploadID string, num int, data []byte) (remotefs.Part, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if num == f.failPart {
+		return remotefs.Part{}, errors.New("connection reset")
+	}
+	f.uploads++
+	f.parts[uploadID][num] = bytes.Clone(data)
+	return remotefs.Part{Number: num, ETag: "etag"}, nil
+}
+
+func (f *fakeRemote) CompleteMultipart(_ context.Context, name, uploadID string, parts []remotefs.Part) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var buf bytes.Buffer
+	for _, p := range parts {
+		buf.Write(f.parts[uploadID][p.Number])
+	}
+	f.objects[name] = buf.Bytes()
+	delete(f.parts, uploadID)
+	return nil
+}
+
+func (f *fakeRemote) AbortMultipart(_ context.Context, name, uploadID string) error {
+	return nil
+}
+
+func testPipeline(t *testing.T, cfg *Config, remote *fakeRemote) *Pipeline {
+	t.Helper()
+	p, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.openFS = remote.open
+	p.retryDelay = 0
+	return p
+}
+
+func makeExport(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "export")
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func untar(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	ret := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ret[hdr.Name] = string(data)
+	}
+	return ret
+}
+
+func TestPipeline_Run(t *testing.T) {
+	files := map[string]string{
+		"users.json":                   `[]`,
+		"channels.json":                `[]`,
+		"general/2024-01-01.json":      `[{"text":"hello"}]`,
+		"attachments/F1-picture.jpg":   "jpeg",
+		"general/attachments/F2-a.txt": "text",
+	}
+	src := makeExport(t, files)
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{
+		Compress: &Compress{Format: FormatTarGz},
+		Encrypt:  &Encrypt{Recipients: []string{id.Recipient().String()}},
+		Upload:   &Upload{Destination: "s3://bucket/backups", RemoveLocal: true},
+	}
+	remote := newFakeRemote(1 << 20)
+	if err := testPipeline(t, cfg, remote).Run(context.Background(), src); err != nil {
+		t.Fatal(err)
+	}
+
+	obj, ok := remote.objects["export.tar.gz.age"]
+	if !ok {
+		t.Fatalf("object is not uploaded, objects: %v", len(remote.objects))
+	}
+	r, err := age.Decrypt(bytes.NewReader(obj), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := untar(t, r)
+	if len(got) != len(files) {
+		t.Errorf("archive has %d files, want %d", len(got), len(files))
+	}
+	for name, want := range files {
+		if got[name] != want {
+			t.Errorf("file %s = %q, want %q", name, got[name], want)
+		}
+	}
+	for _, name := range []string{src + ".tar.gz", src + ".tar.gz.age", StateFile(src)} {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("%s must be removed", name)
+		}
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Errorf("export must be retained: %v", err)
+	}
+}
+
+func TestPipeline_Run_resume(t *testing.T) {
+	// incompressible content, to have several parts.
+	data := make([]byte, 10_000)
+	var x uint32 = 1
+	for i := range data {
+		x = x*1664525 + 1013904223
+		data[i] = byte(x >> 24)
+	}
+	src := filepath.Join(t.TempDir(), "export.zip")
+	if err := os.WriteFile(src, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{Upload: &Upload{Destination: "s3://bucket", Retries: 1}}
+	remote := newFakeRemote(3000)
+	remote.failPart = 3
+	p := testPipeline(t, cfg, remote)
+
+	if err := p.Run(context.Background(), src); err == nil {
+		t.Fatal("expected an error")
+	}
+	st, err := loadState(StateFile(src), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Upload == nil || len(st.Upload.Parts) != 2 {
+		t.Fatalf("state must have 2 uploaded parts, got: %+v", st.Upload)
+	}
+
+	remote.failPart = 0
+	if err := p.Run(context.Background(), src); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(remote.objects["export.zip"], data) {
+		t.Errorf("uploaded object mismatch, got %d bytes", len(remote.objects["export.zip"]))
+	}
+	if remote.creates != 1 {
+		t.Errorf("multipart upload created %d times, want 1", remote.creates)
+	}
+	if remote.uploads != 4 {
+		t.Errorf("parts uploaded %d times, want 4", remote.uploads)
+	}
+	if _, err := os.Stat(StateFile(src)); !os.IsNotExist(err) {
+		t.Error("state file must be removed")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(s string) string {
+		name := filepath.Join(dir, "post.toml")
+		if err := os.WriteFile(name, []byte(s), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return name
+	}
+	cfg, err := Load(write("[compress]\n[upload]\ndestination = \"s3://bucket/prefix\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Compress.Format != FormatZIP || cfg.Upload.Retries != DefaultRetries {
+		t.Errorf("defaults are not set: %+v %+v", cfg.Compress, cfg.Upload)
+	}
+	for _, bad := range []string{
+		"",
+		"[compress]\nformat = \"rar\"\n",
+		"[upload]\ndestination = \"/local/dir\"\n",
+		"[encrypt]\nrecipients = []\n",
+		"[encrypt]\nrecipients = [\"age1invalid\"]\n",
+		"[upload]\ndestination = \"s3://bucket\"\nunknown = 1\n",
+	} {
+		if _, err := Load(write(bad)); err == nil {
+			t.Errorf("Load(%q) expected an error", bad)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package postproc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rusq/slackdump/v3/internal/remotefs"
+)
+
+// upload uploads the file to the destination.  If the destination supports
+// the multipart uploads, the file is uploaded in parts, and the progress is
+// recorded in st, so that the upload can be resumed from the last uploaded
+// part.  Otherwise, the file is uploaded in one go.
+func (p *Pipeline) upload(ctx context.Context, file string, st *State, save func() error) error {
+	dest := p.cfg.Upload.Destination
+	fsys, err := p.openFS(dest)
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	fi, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(file)
+	lg := p.lg.With("file", file, "destination", dest)
+	mu, ok := fsys.(remotefs.MultipartUploader)
+	if !ok || fi.Size() <= int64(mu.PartSize()) {
+		lg.InfoContext(ctx, "uploading", "size", fi.Size())
+		return p.retry(ctx, func() error {
+			w, err := fsys.Create(name)
+			if err != nil {
+				return err
+			}
+			if err := copyFile(w, file); err != nil {
+				w.Close()
+				return err
+			}
+			return w.Close()
+		})
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	partSize := mu.PartSize()
+	if st.Upload.matches(dest, name, fi.Size(), partSize) {
+		lg.InfoContext(ctx, "resuming the upload", "uploaded_parts", len(st.Upload.Parts))
+	} else {
+		var uploadID string
+		if err := p.retry(ctx, func() (err error) {
+			uploadID, err = mu.CreateMultipart(ctx, name)
+			return err
+		}); err != nil {
+			return err
+		}
+		st.Upload = &UploadState{
+			Destination: dest,
+			Name:        name,
+			Size:        fi.Size(),
+			PartSize:    partSize,
+			UploadID:    uploadID,
+		}
+		if err := save(); err != nil {
+			return err
+		}
+		lg.InfoContext(ctx, "uploading", "size", fi.Size(), "part_size", partSize)
+	}
+
+	numParts := int((fi.Size() + int64(partSize) - 1) / int64(partSize))
+	buf := make([]byte, partSize)
+	for num := len(st.Upload.Parts) + 1; num <= numParts; num++ {
+		n, err := f.ReadAt(buf, int64(num-1)*int64(partSize))
+		if err != nil && err != io.EOF {
+			return err
+		}
+		var part remotefs.Part
+		if err := p.retry(ctx, func() (err error) {
+			part, err = mu.UploadPart(ctx, name, st.Upload.UploadID, num, buf[:n])
+			return err
+		}); err != nil {
+			return fmt.Errorf("part %d of %d: %w", num, numParts, err)
+		}
+		st.Upload.Parts = append(st.Upload.Parts, part)
+		if err := save(); err != nil {
+			return err
+		}
+		lg.DebugContext(ctx, "uploaded part", "part", num, "of", numParts)
+	}
+	return p.retry(ctx, func() error {
+		return mu.CompleteMultipart(ctx, name, st.Upload.UploadID, st.Upload.Parts)
+	})
+}
+
+// retry calls fn until it succeeds, or the number of retries is exhausted.
+func (p *Pipeline) retry(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= p.cfg.Upload.Retries; attempt++ {
+		if attempt > 0 {
+			p.lg.WarnContext(ctx, "upload request failed, retrying", "attempt", attempt, "error", err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * p.retryDelay):
+			}
+		}
+		if err = fn(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+package redact
+
+import (
+	"context"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+// Processor is the conversation processor middleware, that redacts the
+// messages before passing them to the next processor.  The messages it
+// receives are not modified, the redacted ones are copies.
+type Processor struct {
+	processor.Passthrough
+	r *Redactor
+}
+
+// NewProcessor returns the redacting processor, that passes the messages,
+// redacted with r, to next.
+func NewProcessor(next processor.Conversations, r *Redactor) *Processor {
+	return &Processor{Passthrough: processor.Passthrough{Conversations: next}, r: r}
+}
+
+// Middleware returns the middleware, that redacts the messages with the
+// rules.  If rules is empty, the middleware passes the processor through
+// unchanged.
+func Middleware(rules ...Rule) processor.Middleware {
+	return func(next processor.Conversations) processor.Conversations {
+		if len(rules) == 0 {
+			return next
+		}
+		return NewProcessor(next, New(rules...))
+	}
+}
+
+// messages returns mm with the messages redacted.  The slice is copied only
+// if any of the messages were redacted.
+func (p *Processor) messages(mm []slack.Message) []slack.Message {
+	var ret []slack.Message
+	for i := range mm {
+		m := mm[i]
+		if !p.r.Message(&m) {
+			continue
+		}
+		if ret == nil {
+			ret = make([]slack.Message, len(mm))
+			copy(ret, mm)
+		}
+		ret[i] = m
+	}
+	if ret == nil {
+		return mm
+	}
+	return ret
+}
+
+func (p *Processor) Messages(ctx context.Context, channelID string, numThreads int, isLast bool, mm []slack.Message) error {
+	return p.Passthrough.Messages(ctx, channelID, numThreads, isLast, p.messages(mm))
+}
+
+func (p *Processor) ThreadMessages(ctx context.Context, channelID string, parent slack.Message, threadOnly, isLast bool, replies []slack.Message) error {
+	p.r.Message(&parent)
+	return p.Passthrough.ThreadMessages(ctx, channelID, parent, threadOnly, isLast, p.messages(replies))
+}
+
+func (p *Processor) Files(ctx context.Context, channel *slack.Channel, parent slack.Message, ff []slack.File) error {
+	p.r.Message(&parent)
+	return p.Passthrough.Files(ctx, channel, parent, ff)
+}
+
+// Pins redacts the pinned messages, before passing the items on.
+func (p *Processor) Pins(ctx context.Context, channelID string, items []slack.Item) error {
+	var ret []slack.Item
+	for i := range items {
+		if items[i].Message == nil {
+			continue
+		}
+		m := *items[i].Message
+		if !p.r.Message(&m) {
+			continue
+		}
+		if ret == nil {
+			ret = make([]slack.Item, len(items))
+			copy(ret, items)
+		}
+		ret[i].Message = &m
+	}
+	if ret == nil {
+		ret = items
+	}
+	return p.Passthrough.Pins(ctx, channelID, ret)
+}
+
+// MessageEdited redacts the prior version of the edited message, before
+// passing it on.
+func (p *Processor) MessageEdited(ctx context.Context, channelID string, prev slack.Message) error {
+	p.r.Message(&prev)
+	return p.Passthrough.MessageEdited(ctx, channelID, prev)
+}
+
+// MessageDeleted redacts the last known version of the deleted message,
+// before passing it on.
+func (p *Processor) MessageDeleted(ctx context.Context, channelID string, prev slack.Message) error {
+	p.r.Message(&prev)
+	return p.Passthrough.MessageDeleted(ctx, channelID, prev)
+}
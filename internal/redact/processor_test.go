@@ -0,0 +1,85 @@
+package redact
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+// sink records the text of the messages it receives.
+type sink struct {
+	processor.Printer
+	texts []string
+}
+
+func (s *sink) Messages(_ context.Context, _ string, _ int, _ bool, mm []slack.Message) error {
+	for _, m := range mm {
+		s.texts = append(s.texts, m.Text)
+	}
+	return nil
+}
+
+func (s *sink) ThreadMessages(_ context.Context, _ string, parent slack.Message, _, _ bool, replies []slack.Message) error {
+	s.texts = append(s.texts, "parent: "+parent.Text)
+	for _, m := range replies {
+		s.texts = append(s.texts, m.Text)
+	}
+	return nil
+}
+
+func TestProcessor(t *testing.T) {
+	ctx := context.Background()
+	s := new(sink)
+	p := processor.Chain(s, Middleware(Emails))
+
+	mm := []slack.Message{
+		{Msg: slack.Msg{Text: "hi"}},
+		{Msg: slack.Msg{Text: "mail me at jane@example.com"}},
+	}
+	require.NoError(t, p.Messages(ctx, "C1", 0, true, mm))
+	require.NoError(t, p.ThreadMessages(ctx, "C1", mm[1], false, true, mm[:1]))
+
+	assert.Equal(t, []string{"hi", "mail me at [REDACTED:email]", "parent: mail me at [REDACTED:email]", "hi"}, s.texts)
+	assert.Equal(t, "mail me at jane@example.com", mm[1].Text, "the received messages must not be modified")
+}
+
+func (s *sink) Pins(_ context.Context, _ string, items []slack.Item) error {
+	for _, it := range items {
+		if it.Message != nil {
+			s.texts = append(s.texts, "pin: "+it.Message.Text)
+		}
+	}
+	return nil
+}
+
+func (s *sink) Bookmarks(context.Context, string, []slack.Bookmark) error {
+	return nil
+}
+
+func TestProcessor_Pins(t *testing.T) {
+	s := new(sink)
+	p := processor.Chain(s, Middleware(Emails))
+
+	msg := &slack.Message{Msg: slack.Msg{Text: "mail me at jane@example.com"}}
+	items := []slack.Item{
+		slack.NewFileItem(&slack.File{ID: "F1"}),
+		slack.NewMessageItem("C1", msg),
+	}
+	pp, ok := p.(processor.Pinner)
+	require.True(t, ok)
+	require.NoError(t, pp.Pins(context.Background(), "C1", items))
+
+	assert.Equal(t, []string{"pin: mail me at [REDACTED:email]"}, s.texts)
+	assert.Equal(t, "mail me at jane@example.com", msg.Text, "the received messages must not be modified")
+	assert.Same(t, msg, items[1].Message)
+}
+
+func TestMiddleware_empty(t *testing.T) {
+	s := new(sink)
+	assert.Same(t, s, processor.Chain(s, Middleware()))
+}
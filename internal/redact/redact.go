@@ -0,0 +1,196 @@
+// Package redact replaces the sensitive data, i.e. the credit card numbers,
+// the access tokens and the email addresses, in the message text and
+// attachments with the placeholder, so that the archive can be shared more
+// widely.
+package redact
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rusq/slack"
+)
+
+// Rule is the redaction rule: the text matching the regular expression is
+// replaced with "[REDACTED:<name>]".
+type Rule struct {
+	Name string
+	re   *regexp.Regexp
+	// valid, if set, is called for each match, and the match is redacted
+	// only if it returns true.
+	valid func(s string) bool
+}
+
+// NewRule returns the rule with the name, that redacts the text matching re.
+func NewRule(name string, re *regexp.Regexp) Rule {
+	return Rule{Name: name, re: re}
+}
+
+// apply returns s with the matches redacted, and true, if there were any.
+func (r Rule) apply(s string) (string, bool) {
+	changed := false
+	ret := r.re.ReplaceAllStringFunc(s, func(match string) string {
+		if r.valid != nil && !r.valid(match) {
+			return match
+		}
+		changed = true
+		return "[REDACTED:" + r.Name + "]"
+	})
+	return ret, changed
+}
+
+// Built-in rules.
+var (
+	// Cards matches the payment card numbers of 13 to 19 digits, optionally
+	// separated by spaces or dashes, that pass the Luhn check.
+	Cards = Rule{
+		Name:  "card",
+		re:    regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		valid: luhn,
+	}
+	// Tokens matches the Slack, GitHub and AWS access tokens and keys, and
+	// the bearer tokens.
+	Tokens = Rule{
+		Name: "token",
+		re: regexp.MustCompile(`\bxox[abposre]-[0-9A-Za-z-]{10,}` +
+			`|\bxapp-\d-[0-9A-Za-z-]{10,}` +
+			`|\bgh[pousr]_[0-9A-Za-z]{36,}` +
+			`|\b(?:AKIA|ASIA)[0-9A-Z]{16}\b` +
+			`|\b(?i:bearer)\s+[0-9A-Za-z._~+/-]{16,}=*`),
+	}
+	// Emails matches the email addresses.
+	Emails = Rule{
+		Name: "email",
+		re:   regexp.MustCompile(`[0-9A-Za-z._%+-]+@[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*\.[A-Za-z]{2,}`),
+	}
+)
+
+// luhn returns true if the digits in s pass the Luhn checksum.
+func luhn(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+// ErrInvalid is returned by [Parse] for the invalid rule specification.
+var ErrInvalid = errors.New("invalid redaction rule")
+
+// Parse parses the rule specification, which is one of:
+//
+//   - "cards": the payment card numbers;
+//   - "tokens": the access tokens and keys;
+//   - "emails": the email addresses;
+//   - "re:<regexp>": the text matching the regular expression, it is
+//     replaced with "[REDACTED:custom]";
+//   - "<name>=<regexp>": the same, but replaced with "[REDACTED:<name>]".
+func Parse(spec string) (Rule, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "cards":
+		return Cards, nil
+	case "tokens":
+		return Tokens, nil
+	case "emails":
+		return Emails, nil
+	}
+	name, expr, ok := strings.Cut(spec, "=")
+	if rest, found := strings.CutPrefix(spec, "re:"); found {
+		name, expr, ok = "custom", rest, true
+	}
+	if !ok || name == "" || expr == "" {
+		return Rule{}, fmt.Errorf("%w %q: expected one of cards, tokens, emails, re:<regexp> or <name>=<regexp>", ErrInvalid, spec)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return Rule{}, fmt.Errorf("%w %q: %s", ErrInvalid, spec, err)
+	}
+	return NewRule(name, re), nil
+}
+
+// Redactor applies the redaction rules.
+type Redactor struct {
+	rules []Rule
+}
+
+// New returns the redactor with the rules, that are applied in order.
+func New(rules ...Rule) *Redactor {
+	return &Redactor{rules: rules}
+}
+
+// String returns s with the sensitive data redacted, and true, if anything
+// was redacted.
+func (r *Redactor) String(s string) (string, bool) {
+	if s == "" {
+		return s, false
+	}
+	changed := false
+	for _, rule := range r.rules {
+		var ok bool
+		if s, ok = rule.apply(s); ok {
+			changed = true
+		}
+	}
+	return s, changed
+}
+
+// field redacts the string pointed to by s, and returns true, if it was
+// changed.
+func (r *Redactor) field(s *string) bool {
+	var changed bool
+	*s, changed = r.String(*s)
+	return changed
+}
+
+// Message redacts the text and the attachments of the message m in place,
+// and returns true, if anything was redacted.  The attachments slice is
+// copied before modification, so that the original message is not
+// affected.  The blocks of the redacted message are removed, as they repeat
+// the text.
+func (r *Redactor) Message(m *slack.Message) bool {
+	changed := r.field(&m.Text)
+	if len(m.Attachments) > 0 {
+		aa := make([]slack.Attachment, len(m.Attachments))
+		copy(aa, m.Attachments)
+		attChanged := false
+		for i := range aa {
+			a := &aa[i]
+			for _, s := range []*string{&a.Fallback, &a.Title, &a.Pretext, &a.Text, &a.Footer} {
+				if r.field(s) {
+					attChanged = true
+				}
+			}
+			if len(a.Fields) > 0 {
+				a.Fields = append([]slack.AttachmentField(nil), a.Fields...)
+				for j := range a.Fields {
+					t, v := r.field(&a.Fields[j].Title), r.field(&a.Fields[j].Value)
+					if t || v {
+						attChanged = true
+					}
+				}
+			}
+		}
+		if attChanged {
+			m.Attachments = aa
+			changed = true
+		}
+	}
+	if changed {
+		m.Blocks = slack.Blocks{}
+	}
+	return changed
+}
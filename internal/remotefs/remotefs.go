@@ -0,0 +1,84 @@
+// Package remotefs provides the fsadapter.FS implementations for the remote
+// storage locations: S3 buckets, SFTP and WebDAV servers, so that the output
+// can be streamed directly to the remote storage, without staging it
+// locally.  The storage is selected by the URL scheme of the location.
+package remotefs
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/rusq/fsadapter"
+
+	"github.com/rusq/slackdump/v3/internal/mtimefs"
+)
+
+// Supported location schemes.
+const (
+	SchemeS3      = "s3"
+	SchemeSFTP    = "sftp"
+	SchemeWebDAV  = "webdav"
+	SchemeWebDAVS = "webdavs"
+)
+
+// scheme returns the scheme of the location, if it is in form
+// "scheme://...", or an empty string.
+func scheme(location string) string {
+	s, _, ok := strings.Cut(location, "://")
+	if !ok {
+		return ""
+	}
+	return strings.ToLower(s)
+}
+
+// clean returns the slash-separated relative path for the file name.
+func clean(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, "\\", "/")), "/")
+}
+
+// IsRemote returns true if the location is a remote storage location.
+func IsRemote(location string) bool {
+	switch scheme(location) {
+	case SchemeS3, SchemeSFTP, SchemeWebDAV, SchemeWebDAVS:
+		return true
+	}
+	return false
+}
+
+// New returns the filesystem adapter for the location.  Remote locations,
+// i.e. "s3://bucket/prefix", "sftp://host/dir" or "webdavs://host/dir", are
+// handled by the respective adapters, anything else is passed to
+// [mtimefs.New].
+func New(location string) (fsadapter.FSCloser, error) {
+	switch scheme(location) {
+	case SchemeS3:
+		return NewS3(location)
+	case SchemeSFTP:
+		return NewSFTP(location)
+	case SchemeWebDAV, SchemeWebDAVS:
+		return NewWebDAV(location)
+	default:
+		return mtimefs.New(location)
+	}
+}
+
+// Part is the uploaded part of the multipart upload.
+type Part struct {
+	Number int    `json:"number"`
+	ETag   string `json:"etag"`
+}
+
+// MultipartUploader is implemented by the filesystems that support the
+// multipart uploads.  Unlike the writer returned by Create, the upload can be
+// resumed after the failure, if the upload ID and the uploaded parts were
+// retained by the caller.
+type MultipartUploader interface {
+	// PartSize returns the size of the part, all parts, except the last
+	// one, must be of this size.
+	PartSize() int
+	CreateMultipart(ctx context.Context, name string) (uploadID string, err error)
+	UploadPart(ctx context.Context, name, uploadID string, num int, data []byte) (Part, error)
+	CompleteMultipart(ctx context.Context, name, uploadID string, parts []Part) error
+	AbortMultipart(ctx context.Context, name, uploadID string) error
+}
@@ -0,0 +1,288 @@
+package remotefs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// DefaultPartSize is the default size of the multipart upload part.
+	DefaultPartSize = 8 << 20
+	// MinPartSize is the minimum part size allowed by S3, except for the
+	// last part.
+	MinPartSize = int(manager.MinUploadPartSize)
+	// defaultRegion is used if the region is not configured.
+	defaultRegion = "us-east-1"
+)
+
+// S3 is the fsadapter.FS implementation that writes the files to the S3
+// bucket.  Files smaller than the part size are uploaded with a single PUT
+// request, larger files are streamed with the multipart upload by the AWS
+// SDK upload manager.  The failed requests are retried by the SDK.  It is
+// safe for concurrent use.
+type S3 struct {
+	client   *s3.Client
+	uploader *manager.Uploader
+	bucket   string
+	prefix   string
+	partSize int
+
+	httpClient *http.Client
+}
+
+// S3Option configures the S3 adapter.
+type S3Option func(*S3)
+
+// WithPartSize sets the size of the multipart upload part.  Values less than
+// [MinPartSize] are ignored.
+func WithPartSize(n int) S3Option {
+	return func(s *S3) {
+		if n >= MinPartSize {
+			s.partSize = n
+		}
+	}
+}
+
+// WithHTTPClient sets the HTTP client.
+func WithHTTPClient(cl *http.Client) S3Option {
+	return func(s *S3) {
+		if cl != nil {
+			s.httpClient = cl
+		}
+	}
+}
+
+// NewS3 creates the S3 adapter for the location in form
+// "s3://bucket/prefix".  The following query parameters are recognised:
+//   - region: the bucket region, overrides the configured region;
+//   - endpoint: the S3-compatible service URL, i.e. "http://localhost:9000",
+//     overrides AWS_ENDPOINT_URL_S3 and AWS_ENDPOINT_URL.
+//
+// The credentials and the region are resolved with the default AWS
+// credential chain: the environment variables, the shared configuration and
+// credentials files (AWS_PROFILE), the web identity token, and the ECS or
+// EC2 instance roles.  If the custom endpoint is set, the path-style
+// addressing is used, otherwise the virtual-hosted style.
+func NewS3(location string, opts ...S3Option) (*S3, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 location: %w", err)
+	}
+	if u.Scheme != SchemeS3 {
+		return nil, fmt.Errorf("not an S3 location: %q", location)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("S3 location %q: bucket is not set", location)
+	}
+	s := &S3{
+		bucket:   u.Host,
+		prefix:   strings.Trim(u.Path, "/"),
+		partSize: DefaultPartSize,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	q := u.Query()
+	var loadOpts []func(*config.LoadOptions) error
+	if region := q.Get("region"); region != "" {
+		loadOpts = append(loadOpts, config.WithRegion(region))
+	}
+	if s.httpClient != nil {
+		loadOpts = append(loadOpts, config.WithHTTPClient(s.httpClient))
+	}
+	cfg, err := config.LoadDefaultConfig(context.Background(), loadOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the AWS configuration: %w", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultRegion
+	}
+	endpoint := q.Get("endpoint")
+	if endpoint != "" {
+		if eu, err := url.Parse(endpoint); err != nil || eu.Host == "" {
+			return nil, fmt.Errorf("invalid S3 endpoint: %q", endpoint)
+		}
+	}
+	pathStyle := endpoint != "" || os.Getenv("AWS_ENDPOINT_URL_S3") != "" || os.Getenv("AWS_ENDPOINT_URL") != ""
+
+	s.client = s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+		o.UsePathStyle = pathStyle
+		// the S3-compatible services may not support the flexible
+		// checksums.
+		o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+	})
+	s.uploader = manager.NewUploader(s.client, func(u *manager.Uploader) {
+		u.PartSize = int64(s.partSize)
+	})
+	return s, nil
+}
+
+// key returns the object key for the file name.
+func (s *S3) key(name string) string {
+	name = clean(name)
+	if s.prefix == "" {
+		return name
+	}
+	return s.prefix + "/" + name
+}
+
+// upload uploads the object from r, with a single request or in parts,
+// depending on the size.
+func (s *S3) upload(ctx context.Context, key string, r io.Reader) error {
+	_, err := s.uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        r,
+		ContentType: contentType(key),
+	})
+	return err
+}
+
+func contentType(key string) *string {
+	if ct := mime.TypeByExtension(path.Ext(key)); ct != "" {
+		return aws.String(ct)
+	}
+	return nil
+}
+
+// WriteFile uploads the file.  perm is ignored.
+func (s *S3) WriteFile(name string, data []byte, _ os.FileMode) error {
+	return s.upload(context.Background(), s.key(name), bytes.NewReader(data))
+}
+
+// Create returns the writer for the file.  The data is streamed to the
+// upload manager as it is written, the upload is completed when the writer
+// is closed.  If the upload fails, the multipart upload is aborted, and the
+// error is returned by Write or Close.
+func (s *S3) Create(name string) (io.WriteCloser, error) {
+	pr, pw := io.Pipe()
+	w := &s3Writer{pw: pw, done: make(chan struct{})}
+	go func() {
+		defer close(w.done)
+		w.err = s.upload(context.Background(), s.key(name), pr)
+		// unblock the writer, if the upload failed before reading all
+		// data.
+		pr.CloseWithError(w.err)
+	}()
+	return w, nil
+}
+
+// Close does nothing, the files are uploaded when they are closed.
+func (s *S3) Close() error {
+	return nil
+}
+
+func (s *S3) String() string {
+	return SchemeS3 + "://" + path.Join(s.bucket, s.prefix)
+}
+
+// s3Writer is the write end of the pipe, that is read by the upload
+// manager.
+type s3Writer struct {
+	pw   *io.PipeWriter
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+func (w *s3Writer) Close() error {
+	w.once.Do(func() {
+		w.pw.Close()
+		<-w.done
+	})
+	return w.err
+}
+
+var _ MultipartUploader = (*S3)(nil)
+
+// PartSize returns the size of the multipart upload part.
+func (s *S3) PartSize() int {
+	return s.partSize
+}
+
+// CreateMultipart initiates the multipart upload of the file, and returns
+// the upload ID.
+func (s *S3) CreateMultipart(ctx context.Context, name string) (string, error) {
+	key := s.key(name)
+	out, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		ContentType: contentType(key),
+	})
+	if err != nil {
+		return "", err
+	}
+	if aws.ToString(out.UploadId) == "" {
+		return "", fmt.Errorf("s3: %s: no upload ID in the response", key)
+	}
+	return *out.UploadId, nil
+}
+
+// UploadPart uploads the part number num of the multipart upload.
+func (s *S3) UploadPart(ctx context.Context, name, uploadID string, num int, data []byte) (Part, error) {
+	key := s.key(name)
+	out, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(int32(num)),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+	if err != nil {
+		return Part{}, err
+	}
+	if aws.ToString(out.ETag) == "" {
+		return Part{}, fmt.Errorf("s3: %s: no ETag for part %d", key, num)
+	}
+	return Part{Number: num, ETag: *out.ETag}, nil
+}
+
+// CompleteMultipart completes the multipart upload from the parts.
+func (s *S3) CompleteMultipart(ctx context.Context, name, uploadID string, parts []Part) error {
+	cp := make([]types.CompletedPart, len(parts))
+	for i, p := range parts {
+		cp[i] = types.CompletedPart{PartNumber: aws.Int32(int32(p.Number)), ETag: aws.String(p.ETag)}
+	}
+	_, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(s.key(name)),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: cp},
+	})
+	return err
+}
+
+// AbortMultipart aborts the multipart upload, discarding the uploaded parts.
+func (s *S3) AbortMultipart(ctx context.Context, name, uploadID string) error {
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(s.key(name)),
+		UploadId: aws.String(uploadID),
+	})
+	return err
+}
@@ -0,0 +1,114 @@
+package remotefs
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/sftp"
+)
+
+// testSFTP returns the SFTP adapter connected to the in-memory server, the
+// client to inspect the server files, and the function that stops the
+// server.
+func testSFTP(t *testing.T, root string) (*SFTP, *sftp.Client, func()) {
+	t.Helper()
+	c2sR, c2sW := io.Pipe()
+	s2cR, s2cW := io.Pipe()
+	srv := sftp.NewRequestServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{c2sR, s2cW}, sftp.InMemHandler())
+	go srv.Serve()
+	stop := func() {
+		srv.Close()
+		c2sR.Close()
+		s2cW.Close()
+	}
+
+	c, err := sftp.NewClientPipe(s2cR, c2sW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := newSFTP(c, nil, root)
+	t.Cleanup(func() {
+		// the client waits for the server to hang up.
+		stop()
+		fsys.Close()
+	})
+	return fsys, c, stop
+}
+
+func TestSFTP_Create(t *testing.T) {
+	fsys, c, _ := testSFTP(t, "backups/slack")
+
+	big := strings.Repeat("0123456789", 10_000) // several write requests
+	var wg sync.WaitGroup
+	for _, name := range []string{"general/2024-01-01.json", "general/2024-01-02.json", "random/2024-01-01.json"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w, err := fsys.Create(name)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if _, err := io.WriteString(w, big); err != nil {
+				t.Error(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := fsys.WriteFile("users.json", []byte("[]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(name string) string {
+		f, err := c.Open(name)
+		if err != nil {
+			t.Errorf("open %s: %v", name, err)
+			return ""
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			t.Errorf("read %s: %v", name, err)
+		}
+		return string(data)
+	}
+	for _, name := range []string{"general/2024-01-01.json", "general/2024-01-02.json", "random/2024-01-01.json"} {
+		if got := read("/backups/slack/" + name); got != big {
+			t.Errorf("file %s: size = %d, want %d", name, len(got), len(big))
+		}
+	}
+	if got := read("/backups/slack/users.json"); got != "[]" {
+		t.Errorf("users.json = %q", got)
+	}
+}
+
+func TestSFTP_connectionLost(t *testing.T) {
+	fsys, _, stop := testSFTP(t, ".")
+	stop()
+	if _, err := fsys.Create("general/2024-01-01.json"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func Test_sftpRoot(t *testing.T) {
+	tests := map[string]string{
+		"":               ".",
+		"/":              ".",
+		"/backups/":      "backups",
+		"//srv/backups":  "/srv/backups",
+		"//srv/backups/": "/srv/backups",
+	}
+	for in, want := range tests {
+		if got := sftpRoot(in); got != want {
+			t.Errorf("sftpRoot(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
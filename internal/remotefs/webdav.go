@@ -0,0 +1,174 @@
+package remotefs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// WebDAV is the fsadapter.FS implementation that writes the files to the
+// WebDAV server.  The files are streamed to the server with the chunked PUT
+// requests, and the missing collections (directories) are created with
+// MKCOL.  It is safe for concurrent use.
+type WebDAV struct {
+	client *http.Client
+	base   *url.URL
+	user   string
+	pass   string
+
+	mu   sync.Mutex
+	dirs map[string]bool // collections known to exist
+}
+
+// NewWebDAV creates the WebDAV adapter for the location in form
+// "webdav://[user[:password]@]host[:port]/path", or "webdavs://..." for
+// HTTPS.  If the password is not set in the URL, it is read from the
+// WEBDAV_PASSWORD environment variable.
+func NewWebDAV(location string) (*WebDAV, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WebDAV location: %w", err)
+	}
+	var scheme string
+	switch u.Scheme {
+	case SchemeWebDAV:
+		scheme = "http"
+	case SchemeWebDAVS:
+		scheme = "https"
+	default:
+		return nil, fmt.Errorf("not a WebDAV location: %q", location)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("WebDAV location %q: host is not set", location)
+	}
+	d := &WebDAV{
+		client: http.DefaultClient,
+		base:   &url.URL{Scheme: scheme, Host: u.Host, Path: "/" + strings.Trim(u.Path, "/")},
+		dirs:   map[string]bool{"/": true},
+	}
+	if u.User != nil {
+		d.user = u.User.Username()
+		if p, ok := u.User.Password(); ok {
+			d.pass = p
+		} else {
+			d.pass = os.Getenv("WEBDAV_PASSWORD")
+		}
+	}
+	return d, nil
+}
+
+// do sends the request for the path p, relative to the server root.
+func (d *WebDAV) do(ctx context.Context, method, p string, body io.Reader, size int64) (*http.Response, error) {
+	u := *d.base
+	u.Path = p
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	if d.user != "" {
+		req.SetBasicAuth(d.user, d.pass)
+	}
+	return d.client.Do(req)
+}
+
+// mkdirAll creates the collection dir, relative to the base path, and all
+// its parents, including the base path itself.
+func (d *WebDAV) mkdirAll(ctx context.Context, dir string) error {
+	dir = path.Join(d.base.Path, dir)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.dirs[dir] {
+		return nil
+	}
+	cur := "/"
+	for _, part := range strings.Split(strings.Trim(dir, "/"), "/") {
+		cur = path.Join(cur, part)
+		if d.dirs[cur] {
+			continue
+		}
+		resp, err := d.do(ctx, "MKCOL", cur+"/", nil, 0)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		// 405 Method Not Allowed is returned, if the collection exists.
+		if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusMethodNotAllowed {
+			return fmt.Errorf("webdav: MKCOL %s: %s", cur, resp.Status)
+		}
+		d.dirs[cur] = true
+	}
+	return nil
+}
+
+func (d *WebDAV) put(ctx context.Context, name string, body io.Reader, size int64) error {
+	if err := d.mkdirAll(ctx, path.Dir(name)); err != nil {
+		return err
+	}
+	resp, err := d.do(ctx, http.MethodPut, path.Join(d.base.Path, name), body, size)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webdav: PUT %s: %s", name, resp.Status)
+	}
+	return nil
+}
+
+// WriteFile uploads the file.  perm is ignored.
+func (d *WebDAV) WriteFile(name string, data []byte, _ os.FileMode) error {
+	return d.put(context.Background(), clean(name), bytes.NewReader(data), int64(len(data)))
+}
+
+// Create returns the writer for the file, the data is streamed to the
+// server as it is written.  The upload completes when the writer is closed.
+func (d *WebDAV) Create(name string) (io.WriteCloser, error) {
+	name = clean(name)
+	if err := d.mkdirAll(context.Background(), path.Dir(name)); err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	w := &pipeWriter{PipeWriter: pw, done: make(chan error, 1)}
+	go func() {
+		// size is unknown, the request is sent with the chunked encoding.
+		err := d.put(context.Background(), name, pr, -1)
+		pr.CloseWithError(err)
+		w.done <- err
+	}()
+	return w, nil
+}
+
+// Close does nothing.
+func (d *WebDAV) Close() error {
+	return nil
+}
+
+func (d *WebDAV) String() string {
+	return d.base.String()
+}
+
+// pipeWriter is the writer end of the pipe, that waits for the reader to
+// finish on Close.
+type pipeWriter struct {
+	*io.PipeWriter
+	done chan error
+	once sync.Once
+	err  error
+}
+
+func (w *pipeWriter) Close() error {
+	w.once.Do(func() {
+		w.PipeWriter.Close()
+		w.err = <-w.done
+	})
+	return w.err
+}
@@ -0,0 +1,111 @@
+package remotefs
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDAV is the minimal WebDAV server, that supports MKCOL and PUT.
+type fakeDAV struct {
+	mu    sync.Mutex
+	dirs  map[string]bool
+	files map[string]string
+}
+
+func (d *fakeDAV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if u, p, ok := r.BasicAuth(); !ok || u != "user" || p != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	p := strings.TrimSuffix(r.URL.Path, "/")
+	switch r.Method {
+	case "MKCOL":
+		if d.dirs[p] {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if !d.dirs[path.Dir(p)] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		d.dirs[p] = true
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodPut:
+		if !d.dirs[path.Dir(p)] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		d.files[p] = string(data)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestWebDAV(t *testing.T) {
+	dav := &fakeDAV{dirs: map[string]bool{"/": true, "/dav": true}, files: make(map[string]string)}
+	srv := httptest.NewServer(dav)
+	defer srv.Close()
+
+	t.Setenv("WEBDAV_PASSWORD", "secret")
+	fsys, err := New("webdav://user@" + strings.TrimPrefix(srv.URL, "http://") + "/dav/slack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	w, err := fsys.Create("general/2024-01-01.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	big := strings.Repeat("x", 1<<20)
+	if _, err := io.WriteString(w, big); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.WriteFile("users.json", []byte("[]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := dav.files["/dav/slack/general/2024-01-01.json"]; got != big {
+		t.Errorf("file size = %d, want %d", len(got), len(big))
+	}
+	if got := dav.files["/dav/slack/users.json"]; got != "[]" {
+		t.Errorf("users.json = %q", got)
+	}
+
+	t.Setenv("WEBDAV_PASSWORD", "wrong")
+	bad, err := NewWebDAV("webdav://user@" + strings.TrimPrefix(srv.URL, "http://") + "/dav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bad.WriteFile("x.json", nil, 0o644); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("WriteFile() error = %v, want 401", err)
+	}
+}
+
+func TestIsRemote(t *testing.T) {
+	for loc, want := range map[string]bool{
+		"s3://bucket":        true,
+		"SFTP://host/dir":    true,
+		"webdav://host/dir":  true,
+		"webdavs://host/dir": true,
+		"export.zip":         false,
+		"/tmp/export":        false,
+		`C:\exports\out.zip`: false,
+		"file:///tmp/export": false,
+	} {
+		if got := IsRemote(loc); got != want {
+			t.Errorf("IsRemote(%q) = %v, want %v", loc, got, want)
+		}
+	}
+}
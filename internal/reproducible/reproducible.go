@@ -0,0 +1,162 @@
+// Package reproducible provides the filesystem adapters, that produce the
+// byte-stable output for the identical input, regardless of the order in
+// which the files are written, so that the archives can be diffed and
+// content-hashed.
+package reproducible
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rusq/fsadapter"
+
+	"github.com/rusq/slackdump/v3/internal/remotefs"
+)
+
+// EnvSourceDateEpoch is the environment variable with the UNIX timestamp,
+// that overrides the modification time of the archive entries, see
+// https://reproducible-builds.org/specs/source-date-epoch/.
+const EnvSourceDateEpoch = "SOURCE_DATE_EPOCH"
+
+// DefaultModTime is the default modification time of the archive entries,
+// the earliest time that can be represented in the ZIP file.
+var DefaultModTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ModTime returns the modification time for the archive entries: the time
+// set by the SOURCE_DATE_EPOCH environment variable, or [DefaultModTime].
+func ModTime() (time.Time, error) {
+	v := os.Getenv(EnvSourceDateEpoch)
+	if v == "" {
+		return DefaultModTime, nil
+	}
+	sec, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s value %q: %w", EnvSourceDateEpoch, v, err)
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
+
+// New returns the filesystem adapter for the location.  ZIP files are
+// written with [ZIP], anything else is opened with [remotefs.New], as the
+// files in directories and on the remote storage do not depend on the order
+// of writes.
+func New(location string) (fsadapter.FSCloser, error) {
+	if remotefs.IsRemote(location) || !strings.EqualFold(filepath.Ext(location), ".zip") {
+		return remotefs.New(location)
+	}
+	mt, err := ModTime()
+	if err != nil {
+		return nil, err
+	}
+	return NewZIPFile(location, mt)
+}
+
+// ZIP is the filesystem adapter for the ZIP file, that has the entries
+// sorted by name and has fixed modification times.  The files are staged in
+// the temporary directory next to the ZIP file, and the archive is written
+// on Close.  It is safe for concurrent use.
+type ZIP struct {
+	f       *os.File
+	stage   string
+	dir     fsadapter.Directory
+	modTime time.Time
+
+	once sync.Once
+	err  error
+}
+
+// NewZIPFile creates the ZIP file filename.  modTime is set as the
+// modification time of all entries.
+func NewZIPFile(filename string, modTime time.Time) (*ZIP, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	stage, err := os.MkdirTemp(filepath.Dir(filename), "."+filepath.Base(filename)+"-*")
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &ZIP{
+		f:       f,
+		stage:   stage,
+		dir:     fsadapter.NewDirectory(stage),
+		modTime: modTime.UTC(),
+	}, nil
+}
+
+// Create creates the file in the archive.
+func (z *ZIP) Create(name string) (io.WriteCloser, error) {
+	return z.dir.Create(name)
+}
+
+// WriteFile writes the file to the archive.
+func (z *ZIP) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return z.dir.WriteFile(name, data, perm)
+}
+
+// Close writes the archive and removes the staged files.  All files must be
+// closed before calling Close.
+func (z *ZIP) Close() error {
+	z.once.Do(func() {
+		defer os.RemoveAll(z.stage)
+		z.err = errors.Join(z.write(), z.f.Close())
+	})
+	return z.err
+}
+
+func (z *ZIP) String() string {
+	return fmt.Sprintf("<reproducible zip archive: %s>", z.f.Name())
+}
+
+// write writes the staged files to the archive.  WalkDir visits the entries
+// in lexical order, that makes the order of the entries stable.
+func (z *ZIP) write() error {
+	zw := zip.NewWriter(z.f)
+	if err := filepath.WalkDir(z.stage, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == z.stage {
+			return nil
+		}
+		rel, err := filepath.Rel(z.stage, path)
+		if err != nil {
+			return err
+		}
+		hdr := &zip.FileHeader{
+			Name:     filepath.ToSlash(rel),
+			Method:   zip.Deflate,
+			Modified: z.modTime,
+		}
+		if d.IsDir() {
+			hdr.Name += "/"
+			hdr.Method = zip.Store
+			_, err := zw.CreateHeader(hdr)
+			return err
+		}
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(w, f)
+		return err
+	}); err != nil {
+		return err
+	}
+	return zw.Close()
+}
@@ -0,0 +1,101 @@
+package reproducible
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeZIP(t *testing.T, filename string, names []string) {
+	t.Helper()
+	z, err := NewZIPFile(filename, DefaultModTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		w, err := z.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, "content of "+name); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestZIP(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.zip")
+	b := filepath.Join(dir, "b.zip")
+	writeZIP(t, a, []string{"users.json", "general/2024-01-02.json", "general/2024-01-01.json", "channels.json"})
+	time.Sleep(1100 * time.Millisecond) // the current time must not leak into the archive
+	writeZIP(t, b, []string{"channels.json", "general/2024-01-01.json", "users.json", "general/2024-01-02.json"})
+
+	da, err := os.ReadFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := os.ReadFile(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(da, db) {
+		t.Error("archives differ")
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(da), int64(len(da)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range zr.File {
+		got = append(got, f.Name)
+		if !f.Modified.Equal(DefaultModTime) {
+			t.Errorf("%s: modified = %v, want %v", f.Name, f.Modified, DefaultModTime)
+		}
+	}
+	want := []string{"channels.json", "general/", "general/2024-01-01.json", "general/2024-01-02.json", "users.json"}
+	if len(got) != len(want) {
+		t.Fatalf("entries = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entries = %v, want %v", got, want)
+			break
+		}
+	}
+
+	// staging directories are removed.
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ents) != 2 {
+		t.Errorf("unexpected files left in the output directory: %v", ents)
+	}
+}
+
+func TestModTime(t *testing.T) {
+	t.Setenv(EnvSourceDateEpoch, "")
+	if got, err := ModTime(); err != nil || !got.Equal(DefaultModTime) {
+		t.Errorf("ModTime() = %v, %v, want %v", got, err, DefaultModTime)
+	}
+	t.Setenv(EnvSourceDateEpoch, "1700000000")
+	if got, err := ModTime(); err != nil || !got.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("ModTime() = %v, %v", got, err)
+	}
+	t.Setenv(EnvSourceDateEpoch, "yesterday")
+	if _, err := ModTime(); err == nil {
+		t.Error("ModTime() expected an error")
+	}
+}
@@ -0,0 +1,135 @@
+// Package retention schedules the fetching of the conversations according to
+// the message retention policy of the workspace, so that the conversations
+// with the messages at risk of deletion are fetched first, and reports the
+// conversations, the messages of which might have been deleted before they
+// were saved.
+package retention
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/edge"
+)
+
+// Day is the unit of the retention durations.
+const Day = 24 * time.Hour
+
+// Policy is the message retention policy: the messages older than the
+// duration are deleted by Slack.  Zero duration means that the messages are
+// kept forever.
+type Policy struct {
+	Public  time.Duration // public channels
+	Private time.Duration // private channels and group DMs
+	DM      time.Duration // direct messages
+}
+
+// Uniform returns the policy with the same duration for all conversation
+// types.
+func Uniform(d time.Duration) Policy {
+	return Policy{Public: d, Private: d, DM: d}
+}
+
+// FromPrefs returns the retention policy from the workspace preferences, as
+// returned by client.userBoot.  The retention of the conversation type is
+// set, if the retention type is not 0 ("keep everything"), and the duration
+// in days is positive.
+func FromPrefs(p *edge.Prefs) Policy {
+	days := func(typ, dur int64) time.Duration {
+		if typ == 0 || dur <= 0 {
+			return 0
+		}
+		return time.Duration(dur) * Day
+	}
+	return Policy{
+		Public:  days(p.RetentionType, p.RetentionDuration),
+		Private: days(p.GroupRetentionType, p.GroupRetentionDuration),
+		DM:      days(p.DmRetentionType, p.DmRetentionDuration),
+	}
+}
+
+// IsZero returns true if the messages of all conversation types are kept
+// forever.
+func (p Policy) IsZero() bool {
+	return p.Public == 0 && p.Private == 0 && p.DM == 0
+}
+
+// For returns the retention duration of the conversation ch.
+func (p Policy) For(ch *slack.Channel) time.Duration {
+	switch {
+	case ch.IsIM:
+		return p.DM
+	case ch.IsPrivate || ch.IsGroup || ch.IsMpIM:
+		return p.Private
+	}
+	return p.Public
+}
+
+// ForID returns the retention duration of the conversation, guessing its
+// type from the ID.  It is used, when the conversation information is not
+// available.  The channels with "C" prefix may be private, in which case the
+// public channel retention is returned.
+func (p Policy) ForID(channelID string) time.Duration {
+	switch {
+	case strings.HasPrefix(channelID, "D"):
+		return p.DM
+	case strings.HasPrefix(channelID, "G"):
+		return p.Private
+	}
+	return p.Public
+}
+
+func (p Policy) String() string {
+	if p.Public == p.Private && p.Private == p.DM {
+		return FormatDuration(p.Public)
+	}
+	return fmt.Sprintf("public: %s, private: %s, dm: %s", FormatDuration(p.Public), FormatDuration(p.Private), FormatDuration(p.DM))
+}
+
+// ParseDuration parses the retention duration, that is either the number of
+// days with "d" suffix, i.e. "90d", the number of weeks with "w" suffix, or
+// the Go duration, i.e. "36h".
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = Day
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * Day
+	}
+	var d time.Duration
+	if unit != 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid retention duration %q", s)
+		}
+		d = time.Duration(n) * unit
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid retention duration %q", s)
+		}
+	}
+	if d < 0 {
+		return 0, errors.New("retention duration must not be negative")
+	}
+	return d, nil
+}
+
+// FormatDuration formats the retention duration d in days, if it is a whole
+// number of days, "forever" if it is zero.
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "forever"
+	case d%Day == 0:
+		return strconv.Itoa(int(d/Day)) + "d"
+	}
+	return d.String()
+}
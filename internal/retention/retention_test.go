@@ -0,0 +1,86 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v3/internal/edge"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"days", "90d", 90 * Day, false},
+		{"weeks", "2w", 14 * Day, false},
+		{"go duration", "36h", 36 * time.Hour, false},
+		{"spaces", " 7d ", 7 * Day, false},
+		{"zero", "0d", 0, false},
+		{"negative", "-1d", 0, true},
+		{"invalid days", "xd", 0, true},
+		{"invalid", "ninety", 0, true},
+		{"empty", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDuration(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDuration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "forever", FormatDuration(0))
+	assert.Equal(t, "90d", FormatDuration(90*Day))
+	assert.Equal(t, "36h0m0s", FormatDuration(36*time.Hour))
+}
+
+func TestFromPrefs(t *testing.T) {
+	p := FromPrefs(&edge.Prefs{
+		RetentionType:          1,
+		RetentionDuration:      90,
+		GroupRetentionType:     0,
+		GroupRetentionDuration: 30,
+		DmRetentionType:        1,
+		DmRetentionDuration:    0,
+	})
+	assert.Equal(t, Policy{Public: 90 * Day}, p)
+	assert.False(t, p.IsZero())
+	assert.True(t, FromPrefs(&edge.Prefs{}).IsZero())
+}
+
+func TestPolicy_For(t *testing.T) {
+	p := Policy{Public: 1 * Day, Private: 2 * Day, DM: 3 * Day}
+	tests := []struct {
+		name string
+		ch   slack.Channel
+		want time.Duration
+	}{
+		{"public", slack.Channel{}, 1 * Day},
+		{"private", slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{IsPrivate: true}}}, 2 * Day},
+		{"mpim", slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{IsMpIM: true}}}, 2 * Day},
+		{"im", slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{IsIM: true}}}, 3 * Day},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, p.For(&tt.ch))
+		})
+	}
+	assert.Equal(t, 1*Day, p.ForID("C01"))
+	assert.Equal(t, 2*Day, p.ForID("G01"))
+	assert.Equal(t, 3*Day, p.ForID("D01"))
+}
+
+func TestPolicy_String(t *testing.T) {
+	assert.Equal(t, "90d", Uniform(90*Day).String())
+	assert.Equal(t, "public: 90d, private: forever, dm: 7d", Policy{Public: 90 * Day, DM: 7 * Day}.String())
+}
@@ -0,0 +1,162 @@
+package retention
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+// MinWindow is the minimum time window, in which the messages of the saved
+// conversation might have been deleted, for the conversation to be reported
+// at risk.  Slack deletes the expired messages in batches, so the shorter
+// windows are unlikely to lose any messages.
+const MinWindow = time.Hour
+
+// Scheduler orders the conversations by the risk of deletion of their
+// messages, and tracks the time, when each conversation was saved.  It is
+// safe for concurrent use.
+type Scheduler struct {
+	policy Policy
+	start  time.Time
+	nowFn  func() time.Time
+
+	mu        sync.Mutex
+	scheduled map[string]time.Duration // channel ID -> retention
+	order     []string                 // channel IDs in the scheduled order
+	done      map[string]time.Time     // channel ID -> time saved
+}
+
+// NewScheduler returns the scheduler for the policy p, the run starts at the
+// current time.
+func NewScheduler(p Policy) *Scheduler {
+	return newScheduler(p, time.Now)
+}
+
+func newScheduler(p Policy, nowFn func() time.Time) *Scheduler {
+	return &Scheduler{
+		policy:    p,
+		start:     nowFn(),
+		nowFn:     nowFn,
+		scheduled: make(map[string]time.Duration),
+		done:      make(map[string]time.Time),
+	}
+}
+
+// Policy returns the retention policy of the scheduler.
+func (s *Scheduler) Policy() Policy {
+	return s.policy
+}
+
+// Order sorts the items in place, so that the conversations at risk come
+// first: the conversations, that are subject to the retention, before the
+// ones that are not, the shorter retention first, and then the older
+// channels first, as they are more likely to have the messages close to the
+// deletion.  chans is the index of the known conversations by ID, it may be
+// nil, then the conversation type is guessed from its ID.
+func (s *Scheduler) Order(items []structures.EntityItem, chans map[string]*slack.Channel) {
+	type key struct {
+		retention time.Duration
+		created   int64
+	}
+	keys := make(map[string]key, len(items))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, item := range items {
+		id := channelID(item.Id)
+		k := key{retention: s.policy.ForID(id)}
+		if ch, ok := chans[id]; ok {
+			k = key{retention: s.policy.For(ch), created: int64(ch.Created)}
+		}
+		keys[item.Id] = k
+		if _, ok := s.scheduled[id]; !ok {
+			s.order = append(s.order, id)
+		}
+		s.scheduled[id] = k.retention
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := keys[items[i].Id], keys[items[j].Id]
+		switch {
+		case a.retention == 0 || b.retention == 0:
+			return a.retention != 0 && b.retention == 0
+		case a.retention != b.retention:
+			return a.retention < b.retention
+		case a.created == 0 || b.created == 0:
+			return a.created != 0 && b.created == 0
+		}
+		return a.created < b.created
+	})
+}
+
+// Done records that the conversation was saved completely.
+func (s *Scheduler) Done(channelID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.done[channelID]; !ok {
+		s.done[channelID] = s.nowFn()
+	}
+}
+
+// Risk describes the conversation, the messages of which might have been
+// deleted before they were saved.
+type Risk struct {
+	ChannelID string
+	Retention time.Duration
+	// Saved is false, if the conversation was not saved completely.
+	Saved bool
+	// From and To is the time range of the messages, that might have been
+	// deleted, while the run was in progress.  For the conversations, that
+	// were not saved, the messages newer than To are deleted as they expire.
+	From, To time.Time
+}
+
+func (r Risk) String() string {
+	const layout = "2006-01-02 15:04"
+	if !r.Saved {
+		return fmt.Sprintf("not saved, %s retention: messages posted before %s are deleted, the rest expire in turn", FormatDuration(r.Retention), r.To.UTC().Format(layout))
+	}
+	return fmt.Sprintf("%s retention: messages posted between %s and %s might have been deleted before they were saved", FormatDuration(r.Retention), r.From.UTC().Format(layout), r.To.UTC().Format(layout))
+}
+
+// Risks returns the conversations subject to the retention, that were not
+// saved, or were saved later than [MinWindow] after the start of the run, in
+// the scheduled order.
+func (s *Scheduler) Risks() []Risk {
+	now := s.nowFn()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ret []Risk
+	for _, id := range s.order {
+		r := s.scheduled[id]
+		if r == 0 {
+			continue
+		}
+		doneAt, saved := s.done[id]
+		if !saved {
+			doneAt = now
+		} else if doneAt.Sub(s.start) < MinWindow {
+			continue
+		}
+		ret = append(ret, Risk{
+			ChannelID: id,
+			Retention: r,
+			Saved:     saved,
+			From:      s.start.Add(-r),
+			To:        doneAt.Add(-r),
+		})
+	}
+	return ret
+}
+
+// channelID returns the channel ID of the entity list item, that may be a
+// thread link.
+func channelID(id string) string {
+	if sl, err := structures.ParseLink(id); err == nil {
+		return sl.Channel
+	}
+	return id
+}
@@ -0,0 +1,73 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+// fakeClock is the clock, that is advanced by the test.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func ids(items []structures.EntityItem) []string {
+	var ret []string
+	for _, it := range items {
+		ret = append(ret, it.Id)
+	}
+	return ret
+}
+
+func TestScheduler_Order(t *testing.T) {
+	p := Policy{Public: 90 * Day, DM: 30 * Day}
+	s := NewScheduler(p)
+	items := []structures.EntityItem{
+		{Id: "G01"},        // private, kept forever
+		{Id: "C02"},        // public, newer
+		{Id: "C01:123.45"}, // public thread, older channel
+		{Id: "D01"},        // DM, shortest retention
+		{Id: "C03"},        // public, unknown
+	}
+	chans := map[string]*slack.Channel{
+		"C01": {GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C01", Created: 100}}},
+		"C02": {GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C02", Created: 200}}},
+	}
+	s.Order(items, chans)
+	assert.Equal(t, []string{"D01", "C01:123.45", "C02", "C03", "G01"}, ids(items))
+}
+
+func TestScheduler_Risks(t *testing.T) {
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	clk := &fakeClock{t: start}
+	s := newScheduler(Uniform(90*Day), clk.now)
+	s.Order([]structures.EntityItem{{Id: "C01"}, {Id: "C02"}, {Id: "C03"}}, nil)
+
+	clk.t = start.Add(10 * time.Minute)
+	s.Done("C01") // saved within the window
+	clk.t = start.Add(2 * time.Hour)
+	s.Done("C02") // saved late
+	clk.t = start.Add(3 * time.Hour)
+
+	got := s.Risks()
+	want := []Risk{
+		{ChannelID: "C02", Retention: 90 * Day, Saved: true, From: start.Add(-90 * Day), To: start.Add(2*time.Hour - 90*Day)},
+		{ChannelID: "C03", Retention: 90 * Day, Saved: false, From: start.Add(-90 * Day), To: start.Add(3*time.Hour - 90*Day)},
+	}
+	require.Equal(t, want, got)
+	assert.Contains(t, got[0].String(), "between 2024-01-02 00:00 and 2024-01-02 02:00")
+	assert.Contains(t, got[1].String(), "not saved")
+}
+
+func TestScheduler_Risks_forever(t *testing.T) {
+	s := NewScheduler(Policy{DM: 7 * Day})
+	s.Order([]structures.EntityItem{{Id: "C01"}}, nil)
+	assert.Empty(t, s.Risks())
+}
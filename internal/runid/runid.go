@@ -0,0 +1,34 @@
+// Package runid holds the identifier of the current slackdump run.  The run ID
+// is attached to log messages, state files and chunk file trailers, so that
+// the artifacts produced by the same run can be correlated.
+package runid
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+var (
+	mu      sync.RWMutex
+	current = uuid.NewString()
+)
+
+// ID returns the ID of the current run.  Unless set with [Set], it is a
+// random UUID generated on startup.
+func ID() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Set sets the ID of the current run.  This is useful when several slackdump
+// invocations form a single pipeline.  Empty id is ignored.
+func Set(id string) {
+	if id == "" {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	current = id
+}
@@ -0,0 +1,239 @@
+// Package schedule implements the schedules of the periodic runs: the fixed
+// interval, and the cron expression in the standard 5-field format.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule returns the time of the next run after t.
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// Every is the schedule with the fixed interval between the runs.
+type Every time.Duration
+
+// Next returns t plus the interval.
+func (e Every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+func (e Every) String() string {
+	return "every " + time.Duration(e).String()
+}
+
+// Cron is the schedule defined by the cron expression.
+type Cron struct {
+	expr   string
+	minute uint64 // bits 0-59
+	hour   uint64 // bits 0-23
+	dom    uint64 // bits 1-31
+	month  uint64 // bits 1-12
+	dow    uint64 // bits 0-6, Sunday is 0
+	// domStar and dowStar are set if the day of month or the day of week
+	// field is "*".  If both fields are restricted, the day matches if
+	// either of them matches, as in the cron(8).
+	domStar, dowStar bool
+}
+
+// field is the range of values of the cron field.
+type field struct {
+	name     string
+	min, max int
+}
+
+var (
+	fMinute = field{"minute", 0, 59}
+	fHour   = field{"hour", 0, 23}
+	fDom    = field{"day of month", 1, 31}
+	fMonth  = field{"month", 1, 12}
+	fDow    = field{"day of week", 0, 7} // 7 is Sunday as well
+)
+
+// descriptors are the supported shorthands.
+var descriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+// ParseCron parses the cron expression of 5 space-separated fields: minute,
+// hour, day of month, month and day of week.  Each field is "*", a number,
+// a range "a-b", or a comma-separated list of those, each optionally
+// followed by the step "/n".  Names of months and days are not supported.
+// The shorthands "@hourly", "@daily", "@weekly", "@monthly" and "@yearly"
+// are accepted as well.
+func ParseCron(expr string) (*Cron, error) {
+	spec := strings.TrimSpace(expr)
+	if d, ok := descriptors[spec]; ok {
+		spec = d
+	}
+	ff := strings.Fields(spec)
+	if len(ff) != 5 {
+		return nil, fmt.Errorf("cron expression %q: expected 5 fields, got %d", expr, len(ff))
+	}
+	c := &Cron{expr: expr}
+	var err error
+	if c.minute, err = parseField(ff[0], fMinute); err != nil {
+		return nil, fmt.Errorf("cron expression %q: %w", expr, err)
+	}
+	if c.hour, err = parseField(ff[1], fHour); err != nil {
+		return nil, fmt.Errorf("cron expression %q: %w", expr, err)
+	}
+	if c.dom, err = parseField(ff[2], fDom); err != nil {
+		return nil, fmt.Errorf("cron expression %q: %w", expr, err)
+	}
+	if c.month, err = parseField(ff[3], fMonth); err != nil {
+		return nil, fmt.Errorf("cron expression %q: %w", expr, err)
+	}
+	if c.dow, err = parseField(ff[4], fDow); err != nil {
+		return nil, fmt.Errorf("cron expression %q: %w", expr, err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+		c.dow &^= 1 << 7
+	}
+	c.domStar = ff[2] == "*"
+	c.dowStar = ff[4] == "*"
+	if _, err := c.next(time.Now()); err != nil {
+		return nil, fmt.Errorf("cron expression %q: %w", expr, err)
+	}
+	return c, nil
+}
+
+func parseField(s string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		b, err := parseRange(part, f)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+// parseRange parses the single element of the list: "*", "n", "a-b", with
+// the optional step.
+func parseRange(s string, f field) (uint64, error) {
+	rng, stepStr, hasStep := strings.Cut(s, "/")
+	step := 1
+	if hasStep {
+		var err error
+		if step, err = strconv.Atoi(stepStr); err != nil || step < 1 {
+			return 0, fmt.Errorf("%s: invalid step %q", f.name, stepStr)
+		}
+	}
+	var lo, hi int
+	switch {
+	case rng == "*":
+		lo, hi = f.min, f.max
+		if f == fDow {
+			hi = 6 // avoid setting Sunday twice
+		}
+	case strings.Contains(rng, "-"):
+		a, b, _ := strings.Cut(rng, "-")
+		var err error
+		if lo, err = parseValue(a, f); err != nil {
+			return 0, err
+		}
+		if hi, err = parseValue(b, f); err != nil {
+			return 0, err
+		}
+		if hi < lo {
+			return 0, fmt.Errorf("%s: invalid range %q", f.name, rng)
+		}
+	default:
+		v, err := parseValue(rng, f)
+		if err != nil {
+			return 0, err
+		}
+		lo, hi = v, v
+		if hasStep {
+			// "n/step" means from n to the end of the range.
+			hi = f.max
+		}
+	}
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << v
+	}
+	return bits, nil
+}
+
+func parseValue(s string, f field) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid value %q", f.name, s)
+	}
+	if v < f.min || f.max < v {
+		return 0, fmt.Errorf("%s: value %d out of range %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// maxYears limits the search of the next run time, so that the expressions
+// that never match, i.e. "0 0 31 2 *", do not loop forever.
+const maxYears = 5
+
+// errNoMatch is returned by next, if the expression never matches.
+var errNoMatch = errors.New("cron expression never matches")
+
+// Next returns the first time after t that matches the expression, in the
+// location of t.  It returns the zero time, if there's no such time within
+// the next several years.
+func (c *Cron) Next(t time.Time) time.Time {
+	next, err := c.next(t)
+	if err != nil {
+		return time.Time{}
+	}
+	return next
+}
+
+func (c *Cron) next(t time.Time) (time.Time, error) {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxYears, 0, 0)
+	loc := t.Location()
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t, nil
+	}
+	return time.Time{}, errNoMatch
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	domOK := c.dom&(1<<uint(t.Day())) != 0
+	dowOK := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+func (c *Cron) String() string {
+	return c.expr
+}
@@ -56,6 +56,11 @@ func (c *ChunkDir) ChannelInfo(channelID string) (*slack.Channel, error) {
 	return f.ChannelInfo(channelID)
 }
 
+// UserGroups returns the recorded user groups.
+func (c *ChunkDir) UserGroups() ([]slack.UserGroup, error) {
+	return c.d.UserGroups()
+}
+
 func (c *ChunkDir) Channels() ([]slack.Channel, error) {
 	return c.d.Channels()
 }
@@ -71,3 +76,13 @@ func (c *ChunkDir) Type() string {
 func (c *ChunkDir) Users() ([]slack.User, error) {
 	return c.d.Users()
 }
+
+// WorkspaceInfo returns the workspace information recorded in the chunk
+// directory.
+func (c *ChunkDir) WorkspaceInfo() (*slack.AuthTestResponse, error) {
+	return c.d.WorkspaceInfo()
+}
+
+func (c *ChunkDir) Close() error {
+	return c.d.Close()
+}
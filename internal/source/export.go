@@ -11,7 +11,7 @@ import (
 	"github.com/rusq/slackdump/v3/internal/structures"
 )
 
-// Export implements viewer.Sourcer for the zip file Slack export format.
+// Export implements Sourcer for the zip file Slack export format.
 type Export struct {
 	fs        fs.FS
 	channels  []slack.Channel
@@ -69,6 +69,12 @@ func (e *Export) Users() ([]slack.User, error) {
 	return e.idx.Users, nil
 }
 
+// UserGroups returns the user groups from the usergroups.json file, if the
+// export has it.
+func (e *Export) UserGroups() ([]slack.UserGroup, error) {
+	return unmarshal[[]slack.UserGroup](e.fs, "usergroups.json")
+}
+
 func (e *Export) Close() error {
 	return nil
 }
@@ -91,7 +97,7 @@ func (e *Export) AllMessages(channelID string) ([]slack.Message, error) {
 		mm = append(mm, *m)
 		return nil
 	}); err != nil {
-		return nil, fmt.Errorf("AllMessages: walk: %s", err)
+		return nil, fmt.Errorf("AllMessages: walk: %w", err)
 	}
 	return mm, nil
 }
@@ -118,11 +124,12 @@ func (e *Export) walkChannelMessages(channelID string, fn func(m *slack.Message)
 			return err
 		}
 		for i, m := range em {
-			if m.Msg == nil {
+			msg := m.Message()
+			if msg == nil {
 				slog.Default().Debug("skipping an empty message", "pth", pth, "index", i)
 				continue
 			}
-			if err := fn(&slack.Message{Msg: *m.Msg}); err != nil {
+			if err := fn(msg); err != nil {
 				return err
 			}
 		}
@@ -142,11 +149,26 @@ func (e *Export) AllThreadMessages(channelID, threadID string) ([]slack.Message,
 		}
 		return nil
 	}); err != nil {
-		return nil, fmt.Errorf("AllThreadMessages: walk: %s", err)
+		return nil, fmt.Errorf("AllThreadMessages: walk: %w", err)
 	}
 	return tm, nil
 }
 
+// ThreadReplies returns all thread replies in the channel, that were not
+// broadcast to the channel, including the ones without the parent message.
+func (e *Export) ThreadReplies(channelID string) ([]slack.Message, error) {
+	var rm []slack.Message
+	if err := e.walkChannelMessages(channelID, func(m *slack.Message) error {
+		if isThreadMessage(&m.Msg) && m.SubType != structures.SubTypeThreadBroadcast {
+			rm = append(rm, *m)
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("ThreadReplies: walk: %w", err)
+	}
+	return rm, nil
+}
+
 func (e *Export) ChannelInfo(channelID string) (*slack.Channel, error) {
 	c, err := e.Channels()
 	if err != nil {
@@ -7,13 +7,14 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"testing/fstest"
 
 	"github.com/rusq/slack"
 	"github.com/rusq/slackdump/v3/internal/fixtures"
 	"github.com/stretchr/testify/assert"
 )
 
-var testZipFile = filepath.Join("..", "..", "..", "tmp", "realexport.zip")
+var testZipFile = filepath.Join("..", "..", "tmp", "realexport.zip")
 
 func openTestZip(t *testing.T, name string) *zip.ReadCloser {
 	fixtures.SkipInCI(t)
@@ -115,6 +116,30 @@ func TestExport_AllMessages(t *testing.T) {
 	}
 }
 
+func TestExport_ThreadReplies(t *testing.T) {
+	e := &Export{
+		fs: fstest.MapFS{
+			"general/2024-01-01.json": &fstest.MapFile{Data: []byte(`[
+				{"ts":"1.0","thread_ts":"1.0","text":"parent"},
+				{"ts":"2.0","thread_ts":"1.0","text":"reply"},
+				{"ts":"3.0","thread_ts":"1.0","subtype":"thread_broadcast","text":"broadcast"},
+				{"ts":"4.0","thread_ts":"0.5","text":"orphan"},
+				{"ts":"5.0","text":"message"}
+			]`)},
+		},
+		chanNames: map[string]string{"C1": "general"},
+	}
+	got, err := e.ThreadReplies("C1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ts []string
+	for _, m := range got {
+		ts = append(ts, m.Timestamp)
+	}
+	assert.Equal(t, []string{"2.0", "4.0"}, ts)
+}
+
 func Test_buildFileIndex(t *testing.T) {
 	fixtures.SkipInCI(t)
 
@@ -131,7 +156,7 @@ func Test_buildFileIndex(t *testing.T) {
 		{
 			name: "test",
 			args: args{
-				fsys: os.DirFS(filepath.Join("..", "..", "..", "tmp", "stdexport")),
+				fsys: os.DirFS(filepath.Join("..", "..", "tmp", "stdexport")),
 				dir:  ".",
 			},
 			want:    map[string]string{},
@@ -0,0 +1,110 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3"
+)
+
+// Session implements Sourcer for the live Slack API.  The channels and the
+// users are fetched once and cached, the messages are fetched on each call.
+// File attachments are not available.
+type Session struct {
+	ctx  context.Context
+	sess *slackdump.Session
+
+	mu       sync.Mutex
+	channels []slack.Channel
+	users    []slack.User
+	fstNotFound
+}
+
+// NewSession returns the source that fetches the data with the session sess.
+// The Sourcer methods have no context, so ctx is used for all API calls.
+func NewSession(ctx context.Context, sess *slackdump.Session) *Session {
+	return &Session{ctx: ctx, sess: sess}
+}
+
+func (s *Session) Name() string {
+	if info := s.sess.Info(); info != nil {
+		return info.URL
+	}
+	return "api"
+}
+
+func (s *Session) Type() string {
+	return "api"
+}
+
+func (s *Session) Channels() ([]slack.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.channels == nil {
+		cc, err := s.sess.GetChannels(s.ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.channels = cc
+	}
+	return s.channels, nil
+}
+
+func (s *Session) Users() ([]slack.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.users == nil {
+		uu, err := s.sess.GetUsers(s.ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.users = uu
+	}
+	return s.users, nil
+}
+
+// AllMessages returns all channel messages without thread messages.
+func (s *Session) AllMessages(channelID string) ([]slack.Message, error) {
+	conv, err := s.sess.DumpRaw(s.ctx, channelID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	return convertMessages(conv.Messages), nil
+}
+
+func (s *Session) AllThreadMessages(channelID, threadID string) ([]slack.Message, error) {
+	conv, err := s.sess.DumpThread(s.ctx, channelID, threadID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	return convertMessages(conv.Messages), nil
+}
+
+// ChannelInfo returns the channel from the channel list, or, if it is not
+// there, requests it from the API.
+func (s *Session) ChannelInfo(channelID string) (*slack.Channel, error) {
+	cc, err := s.Channels()
+	if err != nil {
+		return nil, err
+	}
+	for i := range cc {
+		if cc[i].ID == channelID {
+			return &cc[i], nil
+		}
+	}
+	ch, err := s.sess.Client().GetConversationInfoContext(s.ctx, &slack.GetConversationInfoInput{ChannelID: channelID})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", fs.ErrNotExist, channelID, err)
+	}
+	return ch, nil
+}
+
+// WorkspaceInfo returns the information of the workspace of the session.
+func (s *Session) WorkspaceInfo() (*slack.AuthTestResponse, error) {
+	return s.sess.Info(), nil
+}
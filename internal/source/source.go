@@ -0,0 +1,189 @@
+// Package source provides the uniform access to the Slackdump data,
+// regardless of where it comes from:  the chunk directory, the Slack export,
+// the dump, or the live API.
+package source
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/chunk"
+)
+
+// Sourcer is an interface for retrieving data from different sources.
+type Sourcer interface {
+	// Name should return the name of the retriever underlying media, i.e.
+	// directory or archive.
+	Name() string
+	// Type should return the type of the retriever, i.e. "chunk" or "export".
+	Type() string
+	// Channels should return all channels.
+	Channels() ([]slack.Channel, error)
+	// Users should return all users.
+	Users() ([]slack.User, error)
+	// AllMessages should return all messages for the given channel id.
+	AllMessages(channelID string) ([]slack.Message, error)
+	// AllThreadMessages should return all messages for the given tuple
+	// (channelID, threadID).
+	AllThreadMessages(channelID, threadID string) ([]slack.Message, error)
+	// ChannelInfo should return the channel information for the given channel
+	// id.
+	ChannelInfo(channelID string) (*slack.Channel, error)
+	// FS should return the filesystem with file attachments.
+	FS() fs.FS
+	// File should return the path of the file within the filesystem returned
+	// by FS().
+	File(fileID string, filename string) (string, error)
+}
+
+// UserGrouper is implemented by the sources, that may have the user groups
+// of the workspace recorded.
+type UserGrouper interface {
+	// UserGroups should return the user groups, or an error, if they were
+	// not recorded.
+	UserGroups() ([]slack.UserGroup, error)
+}
+
+var (
+	_ Sourcer = &Export{}
+	_ Sourcer = &ChunkDir{}
+	_ Sourcer = &Dump{}
+	_ Sourcer = &Session{}
+
+	_ UserGrouper = &Export{}
+	_ UserGrouper = &ChunkDir{}
+)
+
+// Flags describe the type of the source.
+type Flags int16
+
+const (
+	FUnknown   Flags = 0
+	FDirectory Flags = 1 << iota
+	FZIP
+	FChunk
+	FExport
+	FDump
+)
+
+// Load detects the type of the source at the location src and opens it.  If
+// the returned source implements io.Closer, it should be closed by the
+// caller.
+func Load(ctx context.Context, src string) (Sourcer, error) {
+	lg := slog.With("source", src)
+	fi, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+	switch Detect(src, fi) {
+	case FChunk | FDirectory:
+		lg.DebugContext(ctx, "loading chunk directory")
+		dir, err := chunk.OpenDir(src)
+		if err != nil {
+			return nil, err
+		}
+		return NewChunkDir(dir), nil
+	case FExport | FZIP:
+		lg.DebugContext(ctx, "loading export zip")
+		f, err := zip.OpenReader(src)
+		if err != nil {
+			return nil, err
+		}
+		return NewExport(f, src)
+	case FExport | FDirectory:
+		lg.DebugContext(ctx, "loading export directory")
+		return NewExport(os.DirFS(src), src)
+	case FDump | FZIP:
+		lg.DebugContext(ctx, "loading dump zip")
+		f, err := zip.OpenReader(src)
+		if err != nil {
+			return nil, err
+		}
+		return NewDump(f, src)
+	case FDump | FDirectory:
+		lg.DebugContext(ctx, "loading dump directory")
+		return NewDump(os.DirFS(src), src)
+	default:
+		return nil, fmt.Errorf("unsupported source type: %s", src)
+	}
+}
+
+// Detect returns the type of the source at the location src with the file
+// information fi.
+func Detect(src string, fi fs.FileInfo) Flags {
+	var fsys fs.FS // this will be our media for accessing files
+	var flags Flags
+	if fi.IsDir() {
+		fsys = os.DirFS(src)
+		flags |= FDirectory
+	} else if fi.Mode().IsRegular() && strings.ToLower(path.Ext(src)) == ".zip" {
+		f, err := zip.OpenReader(src)
+		if err != nil {
+			return FUnknown
+		}
+		defer f.Close()
+		fsys = f
+		flags |= FZIP
+	} else {
+		return FUnknown
+	}
+	if ff, err := fs.Glob(fsys, "[CD]*.json"); err == nil && len(ff) > 0 {
+		return flags | FDump
+	}
+	if isChunkDir(fsys) {
+		if flags&FZIP != 0 {
+			return FUnknown // compressed chunk directories are not supported
+		}
+		return flags | FChunk
+	}
+	if _, err := fs.Stat(fsys, "channels.json"); err == nil {
+		return flags | FExport
+	}
+	return FUnknown
+}
+
+func unmarshalOne[T any](fsys fs.FS, name string) (T, error) {
+	var v T
+	f, err := fsys.Open(name)
+	if err != nil {
+		return v, err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(&v); err != nil {
+		return v, err
+	}
+	return v, nil
+}
+
+func unmarshal[T ~[]S, S any](fsys fs.FS, name string) (T, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var v T
+	if err := json.NewDecoder(f).Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// isChunkDir returns true if fsys has the workspace chunk file, compressed
+// with any of the supported algorithms.
+func isChunkDir(fsys fs.FS) bool {
+	for _, name := range []string{"workspace.json.gz", "workspace.json.zst"} {
+		if _, err := fs.Stat(fsys, name); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	mkdir := func(t *testing.T, files ...string) string {
+		dir := t.TempDir()
+		for _, f := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, f), []byte("{}"), 0o644))
+		}
+		return dir
+	}
+	tests := []struct {
+		name string
+		dir  string
+		want Flags
+	}{
+		{"dump", mkdir(t, "C123.json"), FDump | FDirectory},
+		{"chunk", mkdir(t, "workspace.json.gz"), FChunk | FDirectory},
+		{"export", mkdir(t, "channels.json", "users.json"), FExport | FDirectory},
+		{"unknown", mkdir(t, "readme.txt"), FUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fi, err := os.Stat(tt.dir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, Detect(tt.dir, fi))
+		})
+	}
+	t.Run("regular file", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "recording.jsonl")
+		require.NoError(t, os.WriteFile(name, []byte("{}"), 0o644))
+		fi, err := os.Stat(name)
+		require.NoError(t, err)
+		assert.Equal(t, FUnknown, Detect(name, fi))
+	})
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "C123.json"), []byte(`{"channel_id":"C123","name":"general","messages":[{"ts":"1.0","text":"hello"}]}`), 0o644))
+
+	src, err := Load(context.Background(), dir)
+	require.NoError(t, err)
+	assert.Equal(t, "dump", src.Type())
+	mm, err := src.AllMessages("C123")
+	require.NoError(t, err)
+	require.Len(t, mm, 1)
+	assert.Equal(t, "hello", mm[0].Text)
+
+	_, err = Load(context.Background(), t.TempDir())
+	assert.Error(t, err, "empty directory is not a source")
+}
@@ -0,0 +1,25 @@
+// Package driver opens the SQLite databases with the pure Go driver.  The
+// driver is not available on all platforms, on the unsupported ones [Open]
+// returns [ErrUnsupported].
+package driver
+
+import (
+	"database/sql"
+	"errors"
+	"runtime"
+)
+
+// name is the name of the registered driver.
+const name = "sqlite"
+
+// ErrUnsupported is returned by [Open] on the platforms, that the SQLite
+// driver does not support.
+var ErrUnsupported = errors.New("SQLite is not supported on " + runtime.GOOS + "/" + runtime.GOARCH)
+
+// Open opens the SQLite database file.
+func Open(filename string) (*sql.DB, error) {
+	if !Supported {
+		return nil, ErrUnsupported
+	}
+	return sql.Open(name, filename)
+}
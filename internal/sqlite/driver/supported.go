@@ -0,0 +1,10 @@
+//go:build (darwin && (amd64 || arm64)) || (freebsd && (386 || amd64 || arm || arm64)) || (linux && (386 || amd64 || arm || arm64 || loong64 || ppc64le || riscv64 || s390x)) || (openbsd && (amd64 || arm64)) || (windows && (386 || amd64 || arm64))
+
+package driver
+
+import (
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+// Supported is true, if the SQLite driver is available on this platform.
+const Supported = true
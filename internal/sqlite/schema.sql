@@ -0,0 +1,86 @@
+-- Slackdump SQLite archive schema.
+CREATE TABLE IF NOT EXISTS runs (
+	run_id  TEXT PRIMARY KEY,
+	started TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS channels (
+	id          TEXT PRIMARY KEY,
+	name        TEXT,
+	topic       TEXT,
+	purpose     TEXT,
+	user_id     TEXT, -- the other party of the direct message conversation
+	is_private  BOOLEAN NOT NULL DEFAULT FALSE,
+	is_im       BOOLEAN NOT NULL DEFAULT FALSE,
+	is_mpim     BOOLEAN NOT NULL DEFAULT FALSE,
+	is_archived BOOLEAN NOT NULL DEFAULT FALSE,
+	created     INTEGER, -- unix time
+	data        JSON NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS channels_name ON channels (name);
+
+CREATE TABLE IF NOT EXISTS users (
+	id           TEXT PRIMARY KEY,
+	team_id      TEXT,
+	name         TEXT,
+	real_name    TEXT,
+	display_name TEXT,
+	email        TEXT,
+	is_bot       BOOLEAN NOT NULL DEFAULT FALSE,
+	is_admin     BOOLEAN NOT NULL DEFAULT FALSE,
+	deleted      BOOLEAN NOT NULL DEFAULT FALSE,
+	data         JSON NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS users_name ON users (name);
+
+CREATE TABLE IF NOT EXISTS messages (
+	channel_id  TEXT NOT NULL,
+	ts          TEXT NOT NULL,
+	thread_ts   TEXT,    -- set for thread parents and replies
+	is_parent   BOOLEAN NOT NULL DEFAULT FALSE,
+	user_id     TEXT,
+	subtype     TEXT,
+	text        TEXT,
+	time        INTEGER NOT NULL, -- unix time of ts
+	reply_count INTEGER NOT NULL DEFAULT 0,
+	data        JSON NOT NULL,
+	PRIMARY KEY (channel_id, ts)
+);
+
+CREATE INDEX IF NOT EXISTS messages_thread ON messages (channel_id, thread_ts);
+CREATE INDEX IF NOT EXISTS messages_user ON messages (user_id);
+CREATE INDEX IF NOT EXISTS messages_time ON messages (time);
+
+CREATE TABLE IF NOT EXISTS files (
+	id          TEXT NOT NULL,
+	channel_id  TEXT NOT NULL,
+	message_ts  TEXT NOT NULL,
+	name        TEXT,
+	title       TEXT,
+	mimetype    TEXT,
+	filetype    TEXT,
+	size        INTEGER,
+	url_private TEXT,
+	created     INTEGER, -- unix time
+	data        JSON NOT NULL,
+	PRIMARY KEY (id, channel_id, message_ts)
+);
+
+CREATE INDEX IF NOT EXISTS files_message ON files (channel_id, message_ts);
+
+-- bot_messages lists the messages posted by bots and integrations, with the
+-- app that posted them.
+CREATE VIEW IF NOT EXISTS bot_messages AS
+SELECT
+	channel_id,
+	ts,
+	thread_ts,
+	json_extract(data, '$.bot_id')             AS bot_id,
+	json_extract(data, '$.bot_profile.app_id') AS app_id,
+	COALESCE(json_extract(data, '$.bot_profile.name'), json_extract(data, '$.username')) AS app_name,
+	text,
+	time
+FROM messages
+WHERE json_extract(data, '$.bot_id') IS NOT NULL AND json_extract(data, '$.bot_id') != '';
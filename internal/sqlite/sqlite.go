@@ -0,0 +1,207 @@
+// Package sqlite implements the SQLite output backend.  It writes the
+// channels, users, messages (including thread replies) and file metadata
+// into the SQLite database, so that the archive can be queried with SQL.
+//
+// All tables have the "data" column with the original JSON object, the rest
+// of the columns are extracted from it for the convenience of querying.
+// Records are upserted, so writing the same data twice does not create
+// duplicates.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/fasttime"
+	"github.com/rusq/slackdump/v3/internal/runid"
+	"github.com/rusq/slackdump/v3/internal/sqlite/driver"
+)
+
+// SchemaVersion is the version of the database schema.
+const SchemaVersion = 1
+
+//go:embed schema.sql
+var schema string
+
+// DB is the SQLite database.
+type DB struct {
+	db *sql.DB
+}
+
+// Open opens or creates the SQLite database file and initialises the schema.
+func Open(ctx context.Context, filename string) (*DB, error) {
+	db, err := driver.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	d := &DB{db: db}
+	if err := d.init(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *DB) init(ctx context.Context) error {
+	if err := d.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("error opening database: %w", err)
+	}
+	var ver int
+	if err := d.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&ver); err != nil {
+		return err
+	}
+	if ver > SchemaVersion {
+		return fmt.Errorf("database schema version %d is newer than supported %d", ver, SchemaVersion)
+	}
+	if _, err := d.db.ExecContext(ctx, schema); err != nil {
+		return fmt.Errorf("error creating schema: %w", err)
+	}
+	if _, err := d.db.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion)); err != nil {
+		return err
+	}
+	_, err := d.db.ExecContext(ctx, "INSERT INTO runs (run_id, started) VALUES (?, ?) ON CONFLICT (run_id) DO NOTHING", runid.ID(), time.Now().UTC())
+	return err
+}
+
+// Close closes the database.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// DB returns the underlying database handle.
+func (d *DB) DB() *sql.DB {
+	return d.db
+}
+
+// tx runs fn in a transaction, committing it, if fn returns no error.
+func (d *DB) tx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
+// InsertChannels upserts the channels.
+func (d *DB) InsertChannels(ctx context.Context, cc []slack.Channel) error {
+	const stmt = `INSERT OR REPLACE INTO channels
+		(id, name, topic, purpose, user_id, is_private, is_im, is_mpim, is_archived, created, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	return d.tx(ctx, func(tx *sql.Tx) error {
+		st, err := tx.PrepareContext(ctx, stmt)
+		if err != nil {
+			return err
+		}
+		defer st.Close()
+		for i := range cc {
+			c := &cc[i]
+			data, err := json.Marshal(c)
+			if err != nil {
+				return err
+			}
+			if _, err := st.ExecContext(ctx, c.ID, c.Name, c.Topic.Value, c.Purpose.Value, c.User, c.IsPrivate, c.IsIM, c.IsMpIM, c.IsArchived, c.Created.Time().Unix(), data); err != nil {
+				return fmt.Errorf("channel %s: %w", c.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// InsertUsers upserts the users.
+func (d *DB) InsertUsers(ctx context.Context, uu []slack.User) error {
+	const stmt = `INSERT OR REPLACE INTO users
+		(id, team_id, name, real_name, display_name, email, is_bot, is_admin, deleted, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	return d.tx(ctx, func(tx *sql.Tx) error {
+		st, err := tx.PrepareContext(ctx, stmt)
+		if err != nil {
+			return err
+		}
+		defer st.Close()
+		for i := range uu {
+			u := &uu[i]
+			data, err := json.Marshal(u)
+			if err != nil {
+				return err
+			}
+			if _, err := st.ExecContext(ctx, u.ID, u.TeamID, u.Name, u.RealName, u.Profile.DisplayName, u.Profile.Email, u.IsBot, u.IsAdmin, u.Deleted, data); err != nil {
+				return fmt.Errorf("user %s: %w", u.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// InsertMessages upserts the messages of the channel, and the metadata of
+// the files attached to them.  Thread replies are inserted in the same
+// table, with the thread_ts set to the timestamp of the thread parent.
+func (d *DB) InsertMessages(ctx context.Context, channelID string, mm []slack.Message) error {
+	const (
+		msgStmt = `INSERT OR REPLACE INTO messages
+			(channel_id, ts, thread_ts, is_parent, user_id, subtype, text, time, reply_count, data)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		fileStmt = `INSERT OR REPLACE INTO files
+			(id, channel_id, message_ts, name, title, mimetype, filetype, size, url_private, created, data)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	)
+	return d.tx(ctx, func(tx *sql.Tx) error {
+		ms, err := tx.PrepareContext(ctx, msgStmt)
+		if err != nil {
+			return err
+		}
+		defer ms.Close()
+		fs, err := tx.PrepareContext(ctx, fileStmt)
+		if err != nil {
+			return err
+		}
+		defer fs.Close()
+
+		for i := range mm {
+			m := &mm[i]
+			data, err := json.Marshal(m)
+			if err != nil {
+				return err
+			}
+			var unix int64
+			if ts, err := fasttime.TS2int(m.Timestamp); err == nil {
+				unix = ts / 1_000_000
+			}
+			isParent := m.ThreadTimestamp != "" && m.ThreadTimestamp == m.Timestamp
+			if _, err := ms.ExecContext(ctx, channelID, m.Timestamp, nullable(m.ThreadTimestamp), isParent, nullable(m.User), nullable(m.SubType), m.Text, unix, m.ReplyCount, data); err != nil {
+				return fmt.Errorf("message %s:%s: %w", channelID, m.Timestamp, err)
+			}
+			for j := range m.Files {
+				f := &m.Files[j]
+				if f.ID == "" {
+					continue
+				}
+				fdata, err := json.Marshal(f)
+				if err != nil {
+					return err
+				}
+				if _, err := fs.ExecContext(ctx, f.ID, channelID, m.Timestamp, f.Name, f.Title, f.Mimetype, f.Filetype, f.Size, f.URLPrivate, f.Created.Time().Unix(), fdata); err != nil {
+					return fmt.Errorf("file %s: %w", f.ID, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// nullable returns nil for the empty string, so that it is stored as NULL.
+func nullable(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
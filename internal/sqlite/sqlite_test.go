@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDB(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(ctx, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.InsertUsers(ctx, []slack.User{{ID: "U1", Name: "alice"}, {ID: "U2", Name: "bob"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.InsertChannels(ctx, []slack.Channel{{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1"}}}}); err != nil {
+		t.Fatal(err)
+	}
+	msgs := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000001.000000", ThreadTimestamp: "1700000001.000000", User: "U1", Text: "parent", ReplyCount: 1,
+			Files: []slack.File{{ID: "F1", Name: "file.txt", Size: 42}}}},
+		{Msg: slack.Msg{Timestamp: "1700000002.000000", ThreadTimestamp: "1700000001.000000", User: "U2", Text: "reply"}},
+		{Msg: slack.Msg{Timestamp: "1700000003.000000", User: "U1", Text: "hello"}},
+		{Msg: slack.Msg{Timestamp: "1700000004.000000", BotID: "B1", Text: "deployed", BotProfile: &slack.BotProfile{ID: "B1", AppID: "A1", Name: "deploybot"}}},
+	}
+	if err := db.InsertMessages(ctx, "C1", msgs); err != nil {
+		t.Fatal(err)
+	}
+	// inserting again must not create duplicates
+	msgs[2].Text = "hello, edited"
+	if err := db.InsertMessages(ctx, "C1", msgs); err != nil {
+		t.Fatal(err)
+	}
+
+	count := func(query string, args ...any) int {
+		t.Helper()
+		var n int
+		if err := db.DB().QueryRowContext(ctx, query, args...).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	assert.Equal(t, 2, count("SELECT COUNT(*) FROM users"))
+	assert.Equal(t, 1, count("SELECT COUNT(*) FROM channels WHERE name = 'general'"))
+	assert.Equal(t, 4, count("SELECT COUNT(*) FROM messages WHERE channel_id = ?", "C1"))
+	assert.Equal(t, 2, count("SELECT COUNT(*) FROM messages WHERE thread_ts = ?", "1700000001.000000"))
+	assert.Equal(t, 1, count("SELECT COUNT(*) FROM messages WHERE is_parent"))
+	assert.Equal(t, 1, count("SELECT COUNT(*) FROM files WHERE message_ts = ? AND size = 42", "1700000001.000000"))
+	assert.Equal(t, 1, count("SELECT COUNT(*) FROM messages WHERE text = 'hello, edited' AND time = 1700000003"))
+	assert.Equal(t, 1, count("SELECT COUNT(*) FROM messages m JOIN users u ON u.id = m.user_id WHERE u.name = 'bob'"))
+	assert.Equal(t, 1, count("SELECT COUNT(*) FROM bot_messages WHERE app_id = 'A1' AND app_name = 'deploybot'"))
+
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// reopening existing database
+	db2, err := Open(ctx, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db2.Close()
+	var runs int
+	if err := db2.DB().QueryRowContext(ctx, "SELECT COUNT(*) FROM runs").Scan(&runs); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, runs, "same run must be recorded once")
+}
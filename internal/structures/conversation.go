@@ -26,3 +26,9 @@ func ChannelType(ch slack.Channel) int {
 		return CPublic
 	}
 }
+
+// IsExtShared returns true if the channel is shared, or is pending to be
+// shared, with the external organisations (Slack Connect).
+func IsExtShared(ch *slack.Channel) bool {
+	return ch.IsExtShared || ch.IsPendingExtShared
+}
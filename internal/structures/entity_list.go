@@ -7,9 +7,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/rusq/slack"
 )
 
 const (
@@ -17,8 +20,26 @@ const (
 	// exclusions, i.e. for export or when downloading conversations.
 	excludePrefix = "^"
 	filePrefix    = "@"
-	timeSeparator = "/"
-	timeFmt       = "2006-01-02T15:04:05"
+	// namePrefix marks the channel name pattern, i.e. "#dev-*", that is
+	// resolved to channel IDs with [EntityList.ResolveNames].
+	namePrefix = "#"
+	// escapedNamePrefix is used in list files for the name patterns, as
+	// lines starting with "#" are comments.
+	escapedNamePrefix = `\` + namePrefix
+	// timeSeparator separates the entity and its time range, i.e.
+	// "C123|2023-01-01T00:00:00|2023-06-30T23:59:59".
+	timeSeparator = "|"
+	// legacyTimeSeparator is accepted in place of timeSeparator for the
+	// entities that are not URLs, i.e. "C123/2023-01-01T00:00:00".
+	legacyTimeSeparator = "/"
+	// listSeparator separates the entity and its time range in the
+	// "C123,2023-01-01,2023-06-30" form.
+	listSeparator = ","
+	// paramSeparator separates the entity and its time range parameters in
+	// the "C123#oldest=2023-01-01#latest=2023-06-30" form.
+	paramSeparator = "#"
+	timeFmt        = "2006-01-02T15:04:05"
+	dateFmt        = "2006-01-02"
 
 	// maxFileEntries is the maximum non-empty entries that will be read
 	// from the file.
@@ -26,8 +47,10 @@ const (
 )
 
 var (
-	ErrMaxFileSize = errors.New("maximum file size exceeded")
-	ErrEmptyList   = errors.New("empty list")
+	ErrMaxFileSize  = errors.New("maximum file size exceeded")
+	ErrEmptyList    = errors.New("empty list")
+	ErrInvalidRange = errors.New("invalid time range")
+	ErrInvalidName  = errors.New("invalid channel name pattern")
 )
 
 type EntityItem struct {
@@ -42,21 +65,32 @@ func (ei *EntityItem) String() string {
 	if !ei.Include {
 		sb.WriteString(excludePrefix)
 	}
-	sb.WriteString(ei.Id)
-	if !ei.Oldest.IsZero() {
-		sb.WriteString(timeSeparator)
-		sb.WriteString(ei.Oldest.Format(timeFmt))
+	sb.WriteString(joinTimeTuple(ei.Id, ei.Oldest, ei.Latest))
+	return sb.String()
+}
+
+// joinTimeTuple returns the entity id with the time range in the canonical
+// "id|oldest|latest" form.  Zero times are left empty, if both are zero, it
+// returns the id.
+func joinTimeTuple(id string, oldest, latest time.Time) string {
+	if oldest.IsZero() && latest.IsZero() {
+		return id
 	}
-	if !ei.Latest.IsZero() {
-		sb.WriteString(timeSeparator)
-		sb.WriteString(ei.Latest.Format(timeFmt))
+	var o, l string
+	if !oldest.IsZero() {
+		o = oldest.Format(timeFmt)
 	}
-	return sb.String()
+	if !latest.IsZero() {
+		l = latest.Format(timeFmt)
+	}
+	return strings.Join([]string{id, o, l}, timeSeparator)
 }
 
 // EntityList is an Inclusion/Exclusion list
 type EntityList struct {
-	index       map[string]*EntityItem
+	index map[string]*EntityItem
+	// patterns are the channel name patterns, that are not resolved yet.
+	patterns    []*EntityItem
 	mu          sync.RWMutex
 	hasIncludes bool
 	hasExcludes bool
@@ -94,6 +128,40 @@ func hasFilePrefix(s string) bool {
 	return strings.HasPrefix(s, filePrefix)
 }
 
+func isNamePattern(s string) bool {
+	return strings.HasPrefix(s, namePrefix)
+}
+
+// cutNamePrefix splits the entity into the name pattern prefix ("#" or
+// "^#"), and the rest.  If the entity is not a name pattern, the prefix is
+// empty.
+func cutNamePrefix(s string) (string, string) {
+	n := 0
+	if HasExcludePrefix(s) {
+		n = len(excludePrefix)
+	}
+	if !isNamePattern(s[n:]) {
+		return "", s
+	}
+	n += len(namePrefix)
+	return s[:n], s[n:]
+}
+
+// parseNamePattern validates the channel name pattern, which is the channel
+// name with the optional shell wildcards, i.e. "#dev-*".  It returns the
+// pattern in lower case, as channel names are always lower case.
+func parseNamePattern(s string) (string, error) {
+	name := strings.TrimPrefix(s, namePrefix)
+	if name == "" {
+		return "", fmt.Errorf("%w: %q: empty name", ErrInvalidName, s)
+	}
+	name = strings.ToLower(name)
+	if _, err := path.Match(name, ""); err != nil {
+		return "", fmt.Errorf("%w: %q: %w", ErrInvalidName, s, err)
+	}
+	return namePrefix + name, nil
+}
+
 // NewEntityList creates an EntityList from a slice of IDs or URLs (entites).
 func NewEntityList(entities []string) (*EntityList, error) {
 	var el EntityList
@@ -167,6 +235,9 @@ func readEntityIndex(r io.Reader, maxEntries int) (map[string]bool, error) {
 			}
 			continue
 		}
+		if strings.HasPrefix(line, escapedNamePrefix) {
+			line = line[1:]
+		}
 		// test if it's a valid line
 		elements = append(elements, line)
 		if exit {
@@ -182,7 +253,87 @@ func getTimeTuple(item string) []string {
 	if strings.HasPrefix(item, filePrefix) {
 		return []string{item}
 	}
-	return strings.SplitN(item, timeSeparator, 3)
+	sep := timeSeparator
+	if !strings.Contains(item, timeSeparator) && !IsURL(strings.TrimPrefix(item, excludePrefix)) {
+		sep = legacyTimeSeparator
+	}
+	return strings.SplitN(item, sep, 3)
+}
+
+// expandRange converts the entity with the time range in the
+// "id,oldest,latest" or "id#oldest=...#latest=..." form to the canonical
+// "id|oldest|latest" form.  Times can be specified as dates (YYYY-MM-DD),
+// or as date and time (YYYY-MM-DDTHH:MM:SS), the latest date includes the
+// whole day.  Entities in any other form are returned as is.
+func expandRange(item string) (string, error) {
+	if strings.HasPrefix(item, filePrefix) {
+		return item, nil
+	}
+	// the name pattern prefix is not the parameter separator.
+	pfx, body := cutNamePrefix(item)
+	var id, oldest, latest string
+	switch {
+	case strings.Contains(body, paramSeparator):
+		parts := strings.Split(body, paramSeparator)
+		id = pfx + parts[0]
+		for _, p := range parts[1:] {
+			k, v, ok := strings.Cut(p, "=")
+			if !ok {
+				return "", fmt.Errorf("%w: %q: expected key=value, got %q", ErrInvalidRange, item, p)
+			}
+			switch strings.ToLower(k) {
+			case "oldest":
+				oldest = v
+			case "latest":
+				latest = v
+			default:
+				return "", fmt.Errorf("%w: %q: unknown parameter %q", ErrInvalidRange, item, k)
+			}
+		}
+	case strings.Contains(body, listSeparator):
+		parts := strings.Split(body, listSeparator)
+		if len(parts) > 3 {
+			return "", fmt.Errorf("%w: %q: too many values", ErrInvalidRange, item)
+		}
+		id, oldest = pfx+parts[0], parts[1]
+		if len(parts) == 3 {
+			latest = parts[2]
+		}
+	default:
+		return item, nil
+	}
+	o, err := parseRangeTime(oldest, false)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q: %w", ErrInvalidRange, item, err)
+	}
+	l, err := parseRangeTime(latest, true)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q: %w", ErrInvalidRange, item, err)
+	}
+	if !o.IsZero() && !l.IsZero() && l.Before(o) {
+		return "", fmt.Errorf("%w: %q: latest is before oldest", ErrInvalidRange, item)
+	}
+	return joinTimeTuple(id, o, l), nil
+}
+
+// parseRangeTime parses the time of the entity time range.  If the value is
+// a date and isLatest is set, it returns the last second of that day.  Empty
+// string returns zero time.
+func parseRangeTime(s string, isLatest bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(timeFmt, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(dateFmt, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected %s or %s", s, dateFmt, timeFmt)
+	}
+	if isLatest {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return t, nil
 }
 
 func (el *EntityList) fromIndex(index map[string]bool) {
@@ -205,7 +356,11 @@ func (el *EntityList) fromIndex(index map[string]bool) {
 			item.Latest, _ = time.Parse(timeFmt, parts[2])
 		}
 
-		el.index[item.Id] = item
+		if isNamePattern(item.Id) {
+			el.patterns = append(el.patterns, item)
+		} else {
+			el.index[item.Id] = item
+		}
 		if include {
 			el.hasIncludes = true
 		} else {
@@ -250,7 +405,68 @@ func (el *EntityList) HasExcludes() bool {
 
 // IsEmpty returns true if there's no entries in the list.
 func (el *EntityList) IsEmpty() bool {
-	return len(el.index) == 0
+	return len(el.index) == 0 && len(el.patterns) == 0
+}
+
+// HasPatterns returns true if the list has channel name patterns, that must
+// be resolved with [EntityList.ResolveNames] before the list is used.
+func (el *EntityList) HasPatterns() bool {
+	el.mu.RLock()
+	defer el.mu.RUnlock()
+	return len(el.patterns) > 0
+}
+
+// ResolveNames resolves the channel name patterns to the IDs of the matching
+// channels from cc, and adds them to the list with the time range of the
+// pattern.  Included patterns do not override the entities that are already
+// in the list, while the excluded patterns do, same as the excluded IDs.  It
+// returns the patterns that did not match any channel.
+func (el *EntityList) ResolveNames(cc []slack.Channel) []string {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	if el.index == nil {
+		el.index = make(map[string]*EntityItem)
+	}
+	var unmatched []string
+	// includes first, so that the excludes take precedence.
+	for _, include := range []bool{true, false} {
+		for _, p := range el.patterns {
+			if p.Include != include {
+				continue
+			}
+			n := 0
+			for i := range cc {
+				if !matchName(p.Id, &cc[i]) {
+					continue
+				}
+				n++
+				if _, ok := el.index[cc[i].ID]; ok && include {
+					continue
+				}
+				el.index[cc[i].ID] = &EntityItem{Id: cc[i].ID, Oldest: p.Oldest, Latest: p.Latest, Include: include}
+			}
+			if n == 0 {
+				unmatched = append(unmatched, p.String())
+			}
+		}
+	}
+	el.patterns = nil
+	return unmatched
+}
+
+// matchName returns true if the channel name matches the name pattern.
+func matchName(pattern string, ch *slack.Channel) bool {
+	pattern = strings.TrimPrefix(pattern, namePrefix)
+	for _, name := range []string{ch.Name, ch.NameNormalized} {
+		if name == "" {
+			continue
+		}
+		if ok, _ := path.Match(pattern, strings.ToLower(name)); ok {
+			return true
+		}
+	}
+	return false
 }
 
 func buildEntryIndex(links []string) (map[string]bool, error) {
@@ -262,6 +478,10 @@ func buildEntryIndex(links []string) (map[string]bool, error) {
 		if ent == "" {
 			continue
 		}
+		ent, err := expandRange(ent)
+		if err != nil {
+			return nil, err
+		}
 		parts := getTimeTuple(ent)
 		switch {
 		case HasExcludePrefix(parts[0]):
@@ -269,11 +489,11 @@ func buildEntryIndex(links []string) (map[string]bool, error) {
 			if trimmed == "" {
 				continue
 			}
-			sl, err := ParseLink(trimmed)
+			id, err := parseEntityID(trimmed)
 			if err != nil {
 				return nil, err
 			}
-			parts[0] = sl.String()
+			parts[0] = id
 			excluded = append(excluded, strings.Join(parts, timeSeparator))
 		case hasFilePrefix(parts[0]):
 			trimmed := strings.TrimPrefix(parts[0], filePrefix)
@@ -283,11 +503,11 @@ func buildEntryIndex(links []string) (map[string]bool, error) {
 			files = append(files, trimmed)
 		default:
 			// no prefix
-			sl, err := ParseLink(parts[0])
+			id, err := parseEntityID(parts[0])
 			if err != nil {
 				return nil, err
 			}
-			parts[0] = sl.String()
+			parts[0] = id
 			index[strings.Join(parts, timeSeparator)] = true
 		}
 	}
@@ -311,6 +531,19 @@ func buildEntryIndex(links []string) (map[string]bool, error) {
 	return index, nil
 }
 
+// parseEntityID returns the canonical form of the entity, which is either the
+// link, or the channel name pattern.
+func parseEntityID(s string) (string, error) {
+	if isNamePattern(s) {
+		return parseNamePattern(s)
+	}
+	sl, err := ParseLink(s)
+	if err != nil {
+		return "", err
+	}
+	return sl.String(), nil
+}
+
 // C returns a channel where all included entries are streamed.
 // The channel is closed when all entries have been sent, or when the context
 // is cancelled.
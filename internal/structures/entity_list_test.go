@@ -7,6 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+
 	"github.com/rusq/slackdump/v3/internal/fixtures"
 )
 
@@ -611,6 +614,52 @@ func Test_buildEntityIndex(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"with ranges in list and parameter form",
+			args{[]string{
+				"C123,2023-01-01,2023-06-30",
+				"C234,,2023-06-30T12:00:00",
+				"C345,2023-01-01",
+				"^C456#oldest=2023-01-01",
+				"C567#latest=2023-06-30#oldest=2023-01-01T10:00:00",
+				"C678/2023-01-01T00:00:00/2023-06-30T23:59:59",
+				"https://fake.slack.com/archives/CHM82GF99,2023-01-01",
+			}},
+			map[string]bool{
+				"C123|2023-01-01T00:00:00|2023-06-30T23:59:59": true,
+				"C234||2023-06-30T12:00:00":                    true,
+				"C345|2023-01-01T00:00:00|":                    true,
+				"C456|2023-01-01T00:00:00|":                    false,
+				"C567|2023-01-01T10:00:00|2023-06-30T23:59:59": true,
+				"C678|2023-01-01T00:00:00|2023-06-30T23:59:59": true,
+				"CHM82GF99|2023-01-01T00:00:00|":               true,
+			},
+			false,
+		},
+		{
+			"invalid date in list form",
+			args{[]string{"C123,yesterday"}},
+			nil,
+			true,
+		},
+		{
+			"unknown parameter",
+			args{[]string{"C123#since=2023-01-01"}},
+			nil,
+			true,
+		},
+		{
+			"too many values",
+			args{[]string{"C123,2023-01-01,2023-01-02,2023-01-03"}},
+			nil,
+			true,
+		},
+		{
+			"latest before oldest",
+			args{[]string{"C123,2023-06-30,2023-01-01"}},
+			nil,
+			true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -797,3 +846,73 @@ func TestNewEntityListFromString(t *testing.T) {
 		})
 	}
 }
+
+func Test_buildEntityIndex_namePatterns(t *testing.T) {
+	td := t.TempDir()
+	got, err := buildEntryIndex([]string{
+		"#Dev-*",
+		"^#random-*",
+		"#ops,2024-01-01",
+		"#team-?#oldest=2024-02-01",
+		"@" + fixtures.MkTestFile(t, td, "# comment\n\\#general\n^#dev-noisy\n"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{
+		"#dev-*":                       true,
+		"#random-*":                    false,
+		"#ops|2024-01-01T00:00:00|":    true,
+		"#team-?|2024-02-01T00:00:00|": true,
+		"#general":                     true,
+		"#dev-noisy":                   false,
+	}
+	assert.Equal(t, want, got)
+
+	for _, bad := range []string{"#", "^#", "#dev-[", "#ops,bad"} {
+		if _, err := buildEntryIndex([]string{bad}); err == nil {
+			t.Errorf("buildEntryIndex(%q) expected an error", bad)
+		}
+	}
+}
+
+func TestEntityList_ResolveNames(t *testing.T) {
+	ch := func(id, name string) slack.Channel {
+		var c slack.Channel
+		c.ID, c.Name = id, name
+		return c
+	}
+	cc := []slack.Channel{
+		ch("C1", "dev-backend"),
+		ch("C2", "dev-noisy"),
+		ch("C3", "random-cats"),
+		ch("C4", "general"),
+		ch("D5", ""),
+	}
+	el, err := NewEntityList([]string{"#dev-*", "^#dev-noisy", "C4|2023-01-01T00:00:00|", "#general,2024-01-01", "#nothing-*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, el.HasPatterns())
+	assert.True(t, el.HasIncludes())
+	assert.True(t, el.HasExcludes())
+
+	unmatched := el.ResolveNames(cc)
+	assert.Equal(t, []string{"#nothing-*"}, unmatched)
+	assert.False(t, el.HasPatterns())
+	want := map[string]*EntityItem{
+		"C1": {Id: "C1", Include: true},
+		"C2": {Id: "C2", Include: false},
+		// explicitly listed entity keeps its range
+		"C4": {Id: "C4", Oldest: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Include: true},
+	}
+	assert.Equal(t, want, el.Index())
+
+	el, err = NewEntityList([]string{"^#random-*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, el.HasIncludes())
+	assert.Empty(t, el.ResolveNames(cc))
+	assert.Equal(t, map[string]*EntityItem{"C3": {Id: "C3", Include: false}}, el.Index())
+}
@@ -0,0 +1,86 @@
+package files
+
+// In this file: Slack documents, i.e. canvases and posts.
+
+import (
+	"strings"
+
+	"github.com/rusq/slack"
+)
+
+// DocKind is the kind of the Slack document.  Documents are the files, that
+// are edited in Slack, and have no uploaded contents, their contents is
+// rendered by Slack on download.
+type DocKind uint8
+
+const (
+	// DocNone is the ordinary file.
+	DocNone DocKind = iota
+	// DocCanvas is the canvas.
+	DocCanvas
+	// DocPost is the legacy post, that was replaced by canvases.
+	DocPost
+)
+
+// docExt is the extension of the downloaded document, Slack renders both
+// canvases and posts as HTML.
+const docExt = ".html"
+
+// Document returns the kind of the document f, or DocNone, if f is the
+// ordinary file.
+func Document(f *slack.File) DocKind {
+	switch {
+	case f.Filetype == "quip" || f.Filetype == "canvas" || f.Mode == "canvas":
+		return DocCanvas
+	case f.Filetype == "space" || f.Filetype == "post" || f.Mode == "space" || f.Mode == "post":
+		return DocPost
+	}
+	return DocNone
+}
+
+// Name returns the name of the file f, as it is saved on disk.  For the
+// ordinary files it's the file name, and for the documents, that often have
+// no name, it is the title with the ".html" extension.
+func Name(f *slack.File) string {
+	if Document(f) == DocNone {
+		return f.Name
+	}
+	name := f.Title
+	if name == "" {
+		name = f.Name
+	}
+	if name == "" {
+		name = f.ID
+	}
+	name = strings.Map(safeRune, name)
+	if !strings.HasSuffix(strings.ToLower(name), docExt) {
+		name += docExt
+	}
+	return name
+}
+
+// safeRune replaces the characters, that are not allowed in the file names
+// on some systems.
+func safeRune(r rune) rune {
+	if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+		return '_'
+	}
+	return r
+}
+
+// DownloadURL returns the URL to download the contents of the file f from.
+// Posts are downloaded from the private URL, that returns the rendered post,
+// canvases from the private download URL, falling back to the private URL,
+// and ordinary files from the private download URL.
+func DownloadURL(f *slack.File) string {
+	switch Document(f) {
+	case DocNone:
+		return f.URLPrivateDownload
+	case DocPost:
+		return f.URLPrivate
+	}
+	if f.URLPrivateDownload == "" {
+		return f.URLPrivate
+	}
+	return f.URLPrivateDownload
+}
@@ -0,0 +1,66 @@
+package files
+
+import (
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocument(t *testing.T) {
+	tests := []struct {
+		name string
+		f    slack.File
+		want DocKind
+	}{
+		{"ordinary file", slack.File{Filetype: "png", Mode: "hosted"}, DocNone},
+		{"canvas", slack.File{Filetype: "quip", Mode: "quip"}, DocCanvas},
+		{"canvas mode", slack.File{Mode: "canvas"}, DocCanvas},
+		{"post", slack.File{Filetype: "space", Mode: "space"}, DocPost},
+		{"post filetype", slack.File{Filetype: "post"}, DocPost},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Document(&tt.f))
+		})
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		name string
+		f    slack.File
+		want string
+	}{
+		{"ordinary file", slack.File{ID: "F1", Name: "a.txt", Title: "A"}, "a.txt"},
+		{"canvas title", slack.File{ID: "F1", Filetype: "quip", Title: "Q3 plan"}, "Q3 plan.html"},
+		{"unsafe title", slack.File{ID: "F1", Filetype: "quip", Title: "a/b: c?"}, "a_b_ c_.html"},
+		{"html title", slack.File{ID: "F1", Filetype: "quip", Title: "page.HTML"}, "page.HTML"},
+		{"post name", slack.File{ID: "F1", Filetype: "space", Name: "-"}, "-.html"},
+		{"no name", slack.File{ID: "F1", Filetype: "space"}, "F1.html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Name(&tt.f))
+		})
+	}
+}
+
+func TestDownloadURL(t *testing.T) {
+	tests := []struct {
+		name string
+		f    slack.File
+		want string
+	}{
+		{"ordinary file", slack.File{URLPrivate: "p", URLPrivateDownload: "d"}, "d"},
+		{"ordinary file without download url", slack.File{URLPrivate: "p"}, ""},
+		{"canvas", slack.File{Filetype: "quip", URLPrivate: "p", URLPrivateDownload: "d"}, "d"},
+		{"canvas without download url", slack.File{Filetype: "quip", URLPrivate: "p"}, "p"},
+		{"post", slack.File{Filetype: "space", URLPrivate: "p", URLPrivateDownload: "d"}, "p"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DownloadURL(&tt.f))
+		})
+	}
+}
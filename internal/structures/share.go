@@ -0,0 +1,54 @@
+package structures
+
+// In this file: shared message references.
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/rusq/slack"
+)
+
+// SharedMessage is the reference to the original message, that was shared
+// into another conversation, or unfurled from the message link.
+type SharedMessage struct {
+	// ChannelID is the ID of the conversation of the original message.
+	ChannelID string
+	// TS is the timestamp of the original message.
+	TS string
+	// ThreadTS is the timestamp of the thread, if the original message is a
+	// thread reply.
+	ThreadTS string
+}
+
+// SharedRef returns the reference to the original message, if the
+// attachment a is a message share.  Slack does not set the channel ID and
+// the timestamp of the original message in a way the attachment structure
+// retains, so they are parsed from the permalink in "from_url", i.e.:
+//
+//	https://xxxx.slack.com/archives/C0123456/p1577694990000400?thread_ts=1577694000.000100&cid=C0123456
+func SharedRef(a *slack.Attachment) (SharedMessage, bool) {
+	if a == nil || a.FromURL == "" {
+		return SharedMessage{}, false
+	}
+	uri, err := url.Parse(a.FromURL)
+	if err != nil || !strings.HasSuffix(uri.Hostname(), ".slack.com") {
+		return SharedMessage{}, false
+	}
+	parts := strings.Split(strings.Trim(uri.Path, "/"), "/")
+	if len(parts) != 3 || !strings.EqualFold(parts[0], "archives") || parts[1] == "" {
+		return SharedMessage{}, false
+	}
+	ts, err := ParseThreadID(parts[2])
+	if err != nil {
+		return SharedMessage{}, false
+	}
+	ref := SharedMessage{
+		ChannelID: parts[1],
+		TS:        FormatSlackTS(ts),
+	}
+	if threadTS := uri.Query().Get("thread_ts"); threadTS != "" && threadTS != ref.TS {
+		ref.ThreadTS = threadTS
+	}
+	return ref, true
+}
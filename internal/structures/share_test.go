@@ -0,0 +1,50 @@
+package structures
+
+import (
+	"testing"
+
+	"github.com/rusq/slack"
+)
+
+func TestSharedRef(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       *slack.Attachment
+		want    SharedMessage
+		wantShr bool
+	}{
+		{"nil", nil, SharedMessage{}, false},
+		{"not a share", &slack.Attachment{Text: "unfurled", FromURL: "https://example.com/page"}, SharedMessage{}, false},
+		{"channel link", &slack.Attachment{FromURL: sampleChannelURL}, SharedMessage{}, false},
+		{
+			"channel message",
+			&slack.Attachment{FromURL: sampleThreadURL},
+			SharedMessage{ChannelID: sampleChannelID, TS: "1577694990.000400"},
+			true,
+		},
+		{
+			"thread parent",
+			&slack.Attachment{FromURL: sampleThreadURL + "?thread_ts=1577694990.000400&cid=" + sampleChannelID},
+			SharedMessage{ChannelID: sampleChannelID, TS: "1577694990.000400"},
+			true,
+		},
+		{
+			"thread reply",
+			&slack.Attachment{FromURL: sampleThreadURL + "?thread_ts=1577694000.000100&cid=" + sampleChannelID},
+			SharedMessage{ChannelID: sampleChannelID, TS: "1577694990.000400", ThreadTS: "1577694000.000100"},
+			true,
+		},
+		{"invalid ts", &slack.Attachment{FromURL: sampleChannelURL + "/pxyz"}, SharedMessage{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SharedRef(tt.a)
+			if ok != tt.wantShr {
+				t.Errorf("SharedRef() ok = %v, want %v", ok, tt.wantShr)
+			}
+			if got != tt.want {
+				t.Errorf("SharedRef() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
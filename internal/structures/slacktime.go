@@ -53,3 +53,32 @@ func FormatSlackTS(ts time.Time) string {
 	lo := ts.UnixNano() % 1_000_000
 	return fmt.Sprintf("%d.%06d", hi, lo)
 }
+
+// rfc3339Micro is the RFC 3339 layout with the microsecond precision of the
+// slack timestamps.
+const rfc3339Micro = "2006-01-02T15:04:05.000000Z07:00"
+
+// SlackTSToRFC3339 returns the slack timestamp ts as the RFC 3339 time in
+// UTC, preserving the microseconds, i.e. "1534552745.065949" is returned as
+// "2018-08-18T00:39:05.065949Z".  It returns an empty string, if ts is empty
+// or invalid.
+func SlackTSToRFC3339(ts string) string {
+	sHi, sLo, _ := strings.Cut(ts, ".")
+	hi, err := strconv.ParseInt(sHi, 10, 64)
+	if err != nil {
+		return ""
+	}
+	var lo int64
+	if sLo != "" {
+		if len(sLo) > 6 {
+			sLo = sLo[:6]
+		}
+		if lo, err = strconv.ParseInt(sLo, 10, 64); err != nil {
+			return ""
+		}
+		for i := len(sLo); i < 6; i++ {
+			lo *= 10
+		}
+	}
+	return time.Unix(hi, lo*int64(time.Microsecond)).UTC().Format(rfc3339Micro)
+}
@@ -112,3 +112,25 @@ func Test_parseThreadID(t *testing.T) {
 		})
 	}
 }
+
+func TestSlackTSToRFC3339(t *testing.T) {
+	tests := []struct {
+		name string
+		ts   string
+		want string
+	}{
+		{"ok", "1534552745.065949", "2018-08-18T00:39:05.065949Z"},
+		{"short fraction", "1534552745.5", "2018-08-18T00:39:05.500000Z"},
+		{"no fraction", "1534552745", "2018-08-18T00:39:05.000000Z"},
+		{"empty", "", ""},
+		{"invalid", "x.1", ""},
+		{"invalid fraction", "1534552745.x", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SlackTSToRFC3339(tt.ts); got != tt.want {
+				t.Errorf("SlackTSToRFC3339() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
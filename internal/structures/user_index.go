@@ -79,8 +79,12 @@ func (idx UserIndex) Sender(msg *slack.Message) string {
 	if userid != "" {
 		return idx.DisplayName(userid)
 	}
+	// messages of the bots and integrations may have no user.
+	if msg.BotProfile != nil && msg.BotProfile.Name != "" {
+		return msg.BotProfile.Name
+	}
 
-	return ""
+	return msg.Username
 }
 
 // IsDeleted checks if the user is deleted and returns appropriate value. It
@@ -29,6 +29,7 @@ type mainView struct {
 	Messages       []slack.Message
 	ThreadMessages []slack.Message
 	ThreadID       string
+	Theme          Theme
 }
 
 // view returns a mainView struct with the channels and the name and type of
@@ -38,6 +39,7 @@ func (v *Viewer) view() mainView {
 		channels: v.ch,
 		Name:     filepath.Base(v.src.Name()),
 		Type:     v.src.Type(),
+		Theme:    v.theme,
 	}
 }
 
@@ -6,13 +6,23 @@ import (
 	"html/template"
 	"log/slog"
 	"mime"
+	"path"
 	"strings"
 	"time"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/structures/files"
 )
 
+// DefaultFilePrefix is the default URL prefix of the file attachments.
+const DefaultFilePrefix = "/slackdump/file"
+
 var FuncMap = template.FuncMap{
 	"epoch":    Epoch,
 	"mimetype": Mimetype,
+	"filepath": Filepath,
+	"filename": Filename,
 }
 
 func Epoch(ts json.Number) string {
@@ -44,3 +54,14 @@ func Mimetype(mt string) string {
 	}
 	return t
 }
+
+// Filepath returns the URL of the file attachment with the default prefix.
+func Filepath(id, name string) string {
+	return path.Join(DefaultFilePrefix, id, name)
+}
+
+// Filename returns the name of the saved file attachment, it differs from
+// the file name for canvases and posts.
+func Filename(f slack.File) string {
+	return files.Name(&f)
+}
@@ -9,21 +9,31 @@ import (
 	"log"
 	"log/slog"
 	"os"
+	"path"
 	"strings"
 
 	"github.com/rusq/slack"
 	"github.com/rusq/slackdump/v3/internal/osext"
+	"github.com/rusq/slackdump/v3/internal/structures"
 	"github.com/rusq/slackdump/v3/internal/viewer/renderer/functions"
 )
 
 const debug = true
 
 type Slack struct {
-	tmpl *template.Template
-	uu   map[string]slack.User    // map of user id to user
-	cc   map[string]slack.Channel // map of channel id to channel
+	tmpl       *template.Template
+	uu         map[string]slack.User      // map of user id to user
+	cc         map[string]slack.Channel   // map of channel id to channel
+	ug         map[string]slack.UserGroup // map of user group id to user group
+	filePrefix string                     // URL prefix of the file attachments
+	shareLink  ShareLinkFunc              // link to the original shared message
 }
 
+// ShareLinkFunc should return the URL of the original message of the share
+// within the archive, or an empty string, if the message is not in the
+// archive.
+type ShareLinkFunc func(ref structures.SharedMessage) string
+
 type SlackOption func(*Slack)
 
 func WithUsers(uu map[string]slack.User) SlackOption {
@@ -38,19 +48,53 @@ func WithChannels(cc map[string]slack.Channel) SlackOption {
 	}
 }
 
+// WithUserGroups sets the user groups, that are used to resolve the user
+// group mentions.
+func WithUserGroups(ug map[string]slack.UserGroup) SlackOption {
+	return func(sm *Slack) {
+		sm.ug = ug
+	}
+}
+
+// WithFilePrefix sets the URL prefix of the file attachments, the file URL
+// is "<prefix>/<file_id>/<filename>".  The prefix can be relative, i.e. for
+// the static pages.  Default is [functions.DefaultFilePrefix].
+func WithFilePrefix(prefix string) SlackOption {
+	return func(sm *Slack) {
+		sm.filePrefix = prefix
+	}
+}
+
+// WithShareLinkFunc sets the function that resolves the links to the
+// original messages of the shares.  If it is not set, shares are annotated
+// with the source conversation, but not linked.
+func WithShareLinkFunc(fn ShareLinkFunc) SlackOption {
+	return func(sm *Slack) {
+		sm.shareLink = fn
+	}
+}
+
 //go:embed templates/*.html
 var templates embed.FS
 
 func NewSlack(tmpl *template.Template, opts ...SlackOption) *Slack {
 	s := &Slack{
-		tmpl: template.Must(tmpl.New("blocks").Funcs(functions.FuncMap).ParseFS(templates, "templates/*.html")),
+		filePrefix: functions.DefaultFilePrefix,
 	}
 	for _, opt := range opts {
 		opt(s)
 	}
+	s.tmpl = template.Must(tmpl.New("blocks").Funcs(functions.FuncMap).Funcs(template.FuncMap{
+		"filepath": s.filepath,
+	}).ParseFS(templates, "templates/*.html"))
 	return s
 }
 
+// filepath returns the URL of the file attachment.
+func (s *Slack) filepath(id, name string) string {
+	return path.Join(s.filePrefix, id, name)
+}
+
 func (*Slack) RenderText(ctx context.Context, s string) (v template.HTML) {
 	return template.HTML(parseSlackMd(s))
 }
@@ -98,13 +142,47 @@ func (s *Slack) renderAttachments(ctx context.Context, buf *strings.Builder, msg
 	}
 }
 
+// attachment is the attachment template data.
+type attachment struct {
+	slack.Attachment
+	// Share is set, if the attachment is a shared message.
+	Share *share
+}
+
+// share is the source of the shared message.
+type share struct {
+	// Channel is the display name of the conversation of the original
+	// message.
+	Channel string
+	// Link is the URL of the original message within the archive, empty if
+	// the original message is not in the archive.
+	Link string
+}
+
 func (s *Slack) renderAttachment(ctx context.Context, buf *strings.Builder, msgTS string, a slack.Attachment) {
 	attrMsgID := slog.String("message_ts", msgTS)
-	if err := s.tmpl.ExecuteTemplate(buf, "attachment.html", a); err != nil {
+	if err := s.tmpl.ExecuteTemplate(buf, "attachment.html", attachment{Attachment: a, Share: s.share(&a)}); err != nil {
 		slog.ErrorContext(ctx, "error rendering attachment", "error", err, attrMsgID)
 	}
 }
 
+// share returns the source of the shared message, or nil, if a is not a
+// message share.
+func (s *Slack) share(a *slack.Attachment) *share {
+	ref, ok := structures.SharedRef(a)
+	if !ok {
+		return nil
+	}
+	sh := &share{Channel: ref.ChannelID}
+	if ch, ok := s.cc[ref.ChannelID]; ok && ch.Name != "" {
+		sh.Channel = "#" + ch.Name
+	}
+	if s.shareLink != nil {
+		sh.Link = s.shareLink(ref)
+	}
+	return sh
+}
+
 func (s *Slack) renderFiles(ctx context.Context, buf *strings.Builder, msgTS string, files []slack.File) {
 	attrMsgID := slog.String("message_ts", msgTS)
 	if files == nil {
@@ -5,8 +5,9 @@ import (
 	"log/slog"
 	"strings"
 
-	emj "github.com/enescakir/emoji"
 	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/emojitext"
 )
 
 func (s *Slack) mbtRichText(ib slack.Block) (string, string, error) {
@@ -197,8 +198,10 @@ func (s *Slack) rtseEmoji(ie slack.RichTextSectionElement) (string, string, erro
 	if !ok {
 		return "", "", NewErrIncorrectType(&slack.RichTextSectionEmojiElement{}, ie)
 	}
-	// TODO: resolve and render emoji.
-	em := emj.Parse(fmt.Sprintf(":%s:", e.Name))
+	em, ok := emojitext.FromCodepoints(e.Unicode)
+	if !ok {
+		em = emojitext.Standard.HTMLEmoji(e.Name, e.SkinTone)
+	}
 	return applyStyle(em, e.Style), "", nil
 }
 
@@ -232,10 +235,10 @@ func (s *Slack) rtseUserGroup(ie slack.RichTextSectionElement) (string, string,
 		return "", "", NewErrIncorrectType(&slack.RichTextSectionUserGroupElement{}, ie)
 	}
 	var name string
-	if c, ok := s.cc[e.UsergroupID]; s.cc != nil && ok {
-		name = c.Name
+	if g, ok := s.ug[e.UsergroupID]; s.ug != nil && ok {
+		name = g.Handle
 	} else {
-		slog.Warn("channel not found", "usergroup_id", e.UsergroupID)
+		slog.Warn("user group not found", "usergroup_id", e.UsergroupID)
 		name = e.UsergroupID
 	}
 
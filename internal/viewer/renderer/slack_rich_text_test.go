@@ -179,6 +179,16 @@ func TestSlack_rtseUserGroup(t *testing.T) {
 			``,
 			false,
 		},
+		{
+			"resolved user group",
+			&Slack{ug: map[string]slack.UserGroup{"S12345678": {ID: "S12345678", Handle: "devs"}}},
+			args{
+				ie: slack.NewRichTextSectionUserGroupElement("S12345678"),
+			},
+			`<div class="slack-rich-text-section-user-group"><@devs></div>`,
+			``,
+			false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -197,6 +207,46 @@ func TestSlack_rtseUserGroup(t *testing.T) {
 	}
 }
 
+func TestSlack_rtseEmoji(t *testing.T) {
+	tests := []struct {
+		name string
+		ie   slack.RichTextSectionElement
+		want string
+	}{
+		{
+			"unicode",
+			&slack.RichTextSectionEmojiElement{Type: slack.RTSEEmoji, Name: "wave", SkinTone: 3, Unicode: "1f44b-1f3fc"},
+			"\U0001F44B\U0001F3FC",
+		},
+		{
+			"name only",
+			&slack.RichTextSectionEmojiElement{Type: slack.RTSEEmoji, Name: "tada"},
+			"\U0001F389",
+		},
+		{
+			"name with skin tone",
+			&slack.RichTextSectionEmojiElement{Type: slack.RTSEEmoji, Name: "wave", SkinTone: 3},
+			"\U0001F44B\U0001F3FC",
+		},
+		{
+			"custom emoji",
+			&slack.RichTextSectionEmojiElement{Type: slack.RTSEEmoji, Name: "partyparrot", Style: &slack.RichTextSectionTextStyle{Bold: true}},
+			"<b>:partyparrot:</b>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := (&Slack{}).rtseEmoji(tt.ie)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Slack.rtseEmoji() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSlack_rtseColor(t *testing.T) {
 	type args struct {
 		ie slack.RichTextSectionElement
@@ -2,6 +2,8 @@ package renderer
 
 import (
 	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/emojitext"
 )
 
 /*
@@ -20,5 +22,5 @@ func (*Slack) mbtSection(ib slack.Block) (string, string, error) {
 	if !ok {
 		return "", "", NewErrIncorrectType(&slack.SectionBlock{}, ib)
 	}
-	return pre("slack-section-text", b.Text.Text), "", nil
+	return pre("slack-section-text", emojitext.Standard.Text(b.Text.Text)), "", nil
 }
@@ -1,8 +1,8 @@
 package renderer
 
-import "html"
+import "github.com/rusq/slackdump/v3/internal/emojitext"
 
 func parseSlackMd(s string) string {
 	// TODO parse legacy markdown
-	return "<pre>" + html.EscapeString(s) + "</pre>"
+	return "<pre>" + emojitext.Standard.HTML(s) + "</pre>"
 }
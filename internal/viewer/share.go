@@ -0,0 +1,99 @@
+package viewer
+
+import (
+	"log/slog"
+	"net/url"
+	"sync"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/source"
+	st "github.com/rusq/slackdump/v3/internal/structures"
+	"github.com/rusq/slackdump/v3/internal/viewer/renderer"
+)
+
+// linkFunc returns the link to the message within the viewer pages.
+type linkFunc func(ref st.SharedMessage) string
+
+// serverLink returns the link to the message in the viewer server.  Thread
+// replies are linked to the thread view.
+func serverLink(ref st.SharedMessage) string {
+	u := "/archives/" + url.PathEscape(ref.ChannelID)
+	if ref.ThreadTS != "" {
+		u += "/" + url.PathEscape(ref.ThreadTS)
+	}
+	return u + "#" + ref.TS
+}
+
+// staticLink returns the link to the message in the static site, threads
+// are expanded inline on the channel page.
+func staticLink(ref st.SharedMessage) string {
+	return url.PathEscape(ref.ChannelID) + ".html#" + ref.TS
+}
+
+// messageIndex answers whether the message is present in the source.
+// Conversations are loaded lazily, on the first lookup, and cached.
+type messageIndex struct {
+	src source.Sourcer
+	lg  *slog.Logger
+
+	mu  sync.Mutex
+	idx map[string]map[string]struct{} // conversation key to message timestamps
+}
+
+func newMessageIndex(src source.Sourcer, lg *slog.Logger) *messageIndex {
+	return &messageIndex{src: src, lg: lg, idx: make(map[string]map[string]struct{})}
+}
+
+// has returns true if the message ref is in the source.
+func (mi *messageIndex) has(ref st.SharedMessage) bool {
+	key := ref.ChannelID
+	if ref.ThreadTS != "" {
+		key += ":" + ref.ThreadTS
+	}
+
+	mi.mu.Lock()
+	defer mi.mu.Unlock()
+	set, ok := mi.idx[key]
+	if !ok {
+		set = mi.load(ref)
+		mi.idx[key] = set
+	}
+	_, ok = set[ref.TS]
+	return ok
+}
+
+// load returns the timestamps of the messages of the conversation or the
+// thread of ref.  If the conversation is not in the source, the set is
+// empty.
+func (mi *messageIndex) load(ref st.SharedMessage) map[string]struct{} {
+	var (
+		mm  []slack.Message
+		err error
+	)
+	if ref.ThreadTS != "" {
+		mm, err = mi.src.AllThreadMessages(ref.ChannelID, ref.ThreadTS)
+	} else {
+		mm, err = mi.src.AllMessages(ref.ChannelID)
+	}
+	set := make(map[string]struct{}, len(mm))
+	if err != nil {
+		mi.lg.Debug("shared message conversation is not available", "channel_id", ref.ChannelID, "thread_ts", ref.ThreadTS, "error", err)
+		return set
+	}
+	for _, m := range mm {
+		set[m.Timestamp] = struct{}{}
+	}
+	return set
+}
+
+// shareLinkFunc returns the function that links the shares to the original
+// messages using link, if they are present in the source.
+func (mi *messageIndex) shareLinkFunc(link linkFunc) renderer.ShareLinkFunc {
+	return func(ref st.SharedMessage) string {
+		if !mi.has(ref) {
+			return ""
+		}
+		return link(ref)
+	}
+}
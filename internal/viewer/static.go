@@ -0,0 +1,177 @@
+package viewer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/fasttime"
+	"github.com/rusq/slackdump/v3/internal/source"
+	st "github.com/rusq/slackdump/v3/internal/structures"
+	"github.com/rusq/slackdump/v3/internal/structures/files"
+)
+
+// staticFilesDir is the directory within the generated site, where the file
+// attachments are copied to.
+const staticFilesDir = "files"
+
+// staticView is the data of the static page.
+type staticView struct {
+	mainView
+	// Threads maps the thread timestamp to the thread replies, excluding
+	// the parent message.
+	Threads map[string][]slack.Message
+}
+
+// Generate renders the source r as a static HTML site, that can be browsed
+// without running the viewer server.  The site consists of the index.html
+// with the list of channels, and the page per each channel, named
+// "<channel_id>.html", with all threads expanded inline.  File attachments
+// available in the source are copied to the "files" directory of the site.
+// The site is written to fsa.
+func Generate(ctx context.Context, fsa fsadapter.FS, r source.Sourcer, opts ...Option) error {
+	v, err := newViewer(r, staticFilesDir, staticLink, opts...)
+	if err != nil {
+		return err
+	}
+	if err := v.writePage(fsa, "index.html", staticView{mainView: v.view()}); err != nil {
+		return err
+	}
+	for _, cc := range [][]slack.Channel{v.ch.Public, v.ch.Private, v.ch.MPIM, v.ch.DM} {
+		for _, ch := range cc {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := v.generateChannel(ctx, fsa, ch); err != nil {
+				return fmt.Errorf("channel %s: %w", ch.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// generateChannel writes the page of the channel ch and copies the file
+// attachments of its messages.
+func (v *Viewer) generateChannel(ctx context.Context, fsa fsadapter.FS, ch slack.Channel) error {
+	lg := v.lg.With("in", "generateChannel", "channel", ch.ID)
+	mm, err := v.src.AllMessages(ch.ID)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := ascending(mm); err != nil {
+		return err
+	}
+
+	threads := make(map[string][]slack.Message)
+	for i := range mm {
+		if !st.IsThreadStart(&mm[i]) {
+			continue
+		}
+		ts := mm[i].ThreadTimestamp
+		tm, err := v.src.AllThreadMessages(ch.ID, ts)
+		if err != nil {
+			lg.WarnContext(ctx, "skipping thread", "thread_ts", ts, "error", err)
+			continue
+		}
+		threads[ts] = slices.DeleteFunc(tm, func(m slack.Message) bool {
+			return m.Timestamp == ts // parent message is already on the page
+		})
+	}
+
+	for _, m := range mm {
+		v.copyFiles(ctx, fsa, m.Files)
+		for _, reply := range threads[m.ThreadTimestamp] {
+			v.copyFiles(ctx, fsa, reply.Files)
+		}
+	}
+
+	page := staticView{
+		mainView: v.view(),
+		Threads:  threads,
+	}
+	page.Conversation = ch
+	page.Messages = mm
+	lg.DebugContext(ctx, "writing channel page", "message_count", len(mm), "thread_count", len(threads))
+	return v.writePage(fsa, ch.ID+".html", page)
+}
+
+// writePage renders the static template with the page data to the file name.
+func (v *Viewer) writePage(fsa fsadapter.FS, name string, page staticView) error {
+	w, err := fsa.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := v.tmpl.ExecuteTemplate(w, "static.html", page); err != nil {
+		w.Close()
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return w.Close()
+}
+
+// copyFiles copies the file attachments from the source to the files
+// directory of the site.  Files that are missing in the source, i.e. were not
+// downloaded, are skipped, they will be displayed as broken links.
+func (v *Viewer) copyFiles(ctx context.Context, fsa fsadapter.FS, ff []slack.File) {
+	for _, f := range ff {
+		if err := v.copyFile(fsa, f.ID, files.Name(&f)); err != nil {
+			lg := v.lg.With("in", "copyFiles", "file_id", f.ID, "filename", f.Name)
+			if errors.Is(err, fs.ErrNotExist) {
+				lg.DebugContext(ctx, "file not found in the source")
+				continue
+			}
+			lg.WarnContext(ctx, "unable to copy file", "error", err)
+		}
+	}
+}
+
+func (v *Viewer) copyFile(fsa fsadapter.FS, id, name string) error {
+	if id == "" || name == "" {
+		return fs.ErrNotExist
+	}
+	src, err := v.src.File(id, name)
+	if err != nil {
+		return err
+	}
+	in, err := v.src.FS().Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := fsa.Create(path.Join(staticFilesDir, id, name))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// ascending ensures that the messages are sorted in the ascending order of
+// timestamps, some sources store them in the reverse order.
+func ascending(mm []slack.Message) error {
+	if len(mm) < 2 {
+		return nil
+	}
+	first, err := fasttime.TS2int(mm[0].Timestamp)
+	if err != nil {
+		return err
+	}
+	last, err := fasttime.TS2int(mm[len(mm)-1].Timestamp)
+	if err != nil {
+		return err
+	}
+	if first > last {
+		slices.Reverse(mm)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package viewer
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/rusq/fsadapter"
+	"github.com/rusq/slack"
+)
+
+// fakeSource is the in-memory source for the static generation tests.
+type fakeSource struct {
+	channels []slack.Channel
+	users    []slack.User
+	messages map[string][]slack.Message
+	threads  map[string][]slack.Message
+	fsys     fstest.MapFS
+}
+
+func (s *fakeSource) Name() string                       { return "fake" }
+func (s *fakeSource) Type() string                       { return "test" }
+func (s *fakeSource) Channels() ([]slack.Channel, error) { return s.channels, nil }
+func (s *fakeSource) Users() ([]slack.User, error)       { return s.users, nil }
+func (s *fakeSource) FS() fs.FS                          { return s.fsys }
+
+func (s *fakeSource) AllMessages(channelID string) ([]slack.Message, error) {
+	mm, ok := s.messages[channelID]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return mm, nil
+}
+
+func (s *fakeSource) AllThreadMessages(channelID, threadID string) ([]slack.Message, error) {
+	return s.threads[channelID+":"+threadID], nil
+}
+
+func (s *fakeSource) ChannelInfo(channelID string) (*slack.Channel, error) {
+	for _, c := range s.channels {
+		if c.ID == channelID {
+			return &c, nil
+		}
+	}
+	return nil, fs.ErrNotExist
+}
+
+func (s *fakeSource) File(fileID string, filename string) (string, error) {
+	name := path.Join("attachments", fileID+"-"+filename)
+	if _, err := fs.Stat(s.fsys, name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func testMessage(ts, user, text string) slack.Message {
+	return slack.Message{Msg: slack.Msg{Timestamp: ts, User: user, Text: text}}
+}
+
+func TestGenerate(t *testing.T) {
+	parent := testMessage("1700000001.000000", "U1", "thread parent")
+	parent.ThreadTimestamp = parent.Timestamp
+	parent.ReplyCount = 1
+	parent.LatestReply = "1700000002.000000"
+	reply := testMessage("1700000002.000000", "U2", "thread reply")
+	reply.ThreadTimestamp = parent.Timestamp
+	withFile := testMessage("1700000003.000000", "U1", "see attached")
+	withFile.Files = []slack.File{{ID: "F1", Name: "report.txt"}, {ID: "F2", Name: "missing.txt"}}
+
+	src := &fakeSource{
+		channels: []slack.Channel{
+			{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1"}}},
+			{GroupConversation: slack.GroupConversation{Name: "empty", Conversation: slack.Conversation{ID: "C2"}}},
+		},
+		users: []slack.User{
+			{ID: "U1", Name: "alice", Profile: slack.UserProfile{DisplayName: "Alice", Image48: "https://example.com/alice.png"}},
+			{ID: "U2", Name: "bob", Profile: slack.UserProfile{DisplayName: "Bob"}},
+		},
+		messages: map[string][]slack.Message{
+			// reverse order, as some sources store them.
+			"C1": {withFile, parent},
+		},
+		threads: map[string][]slack.Message{
+			"C1:" + parent.Timestamp: {parent, reply},
+		},
+		fsys: fstest.MapFS{
+			"attachments/F1-report.txt": &fstest.MapFile{Data: []byte("report contents")},
+		},
+	}
+
+	dir := t.TempDir()
+	if err := Generate(context.Background(), fsadapter.NewDirectory(dir), src, WithTheme(ThemeDark)); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	index := readFile(t, filepath.Join(dir, "index.html"))
+	for _, want := range []string{`href="C1.html"`, `href="C2.html"`, `data-theme="dark"`} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index.html does not contain %q", want)
+		}
+	}
+
+	page := readFile(t, filepath.Join(dir, "C1.html"))
+	for _, want := range []string{
+		"thread parent",
+		"thread reply",
+		"<details",
+		`src="https://example.com/alice.png"`,
+		`files/F1/report.txt`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("C1.html does not contain %q", want)
+		}
+	}
+	if strings.Index(page, "thread parent") > strings.Index(page, "see attached") {
+		t.Error("messages are not in the ascending order")
+	}
+	if n := strings.Count(page, "thread parent"); n != 1 {
+		t.Errorf("thread parent rendered %d times, want 1", n)
+	}
+
+	if got := readFile(t, filepath.Join(dir, "files", "F1", "report.txt")); got != "report contents" {
+		t.Errorf("attachment contents = %q, want %q", got, "report contents")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "files", "F2")); !os.IsNotExist(err) {
+		t.Errorf("missing attachment should not be created, stat error = %v", err)
+	}
+	if page := readFile(t, filepath.Join(dir, "C2.html")); !strings.Contains(page, "No Messages.") {
+		t.Error("C2.html should display the empty conversation")
+	}
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGenerate_shares(t *testing.T) {
+	original := testMessage("1700000001.000100", "U1", "original announcement")
+	shared := testMessage("1700000005.000000", "U2", "look at this")
+	shared.Attachments = []slack.Attachment{{
+		AuthorName: "Alice",
+		Text:       "original announcement",
+		FromURL:    "https://ora600.slack.com/archives/C1/p1700000001000100",
+		Ts:         "1700000001.000100",
+	}}
+	missing := testMessage("1700000006.000000", "U2", "and this")
+	missing.Attachments = []slack.Attachment{{
+		Text:    "from elsewhere",
+		FromURL: "https://ora600.slack.com/archives/C9/p1700000001000200",
+	}}
+
+	src := &fakeSource{
+		channels: []slack.Channel{
+			{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C1"}}},
+			{GroupConversation: slack.GroupConversation{Name: "random", Conversation: slack.Conversation{ID: "C2"}}},
+		},
+		users: []slack.User{{ID: "U1", Name: "alice"}, {ID: "U2", Name: "bob"}},
+		messages: map[string][]slack.Message{
+			"C1": {original},
+			"C2": {shared, missing},
+		},
+	}
+
+	dir := t.TempDir()
+	if err := Generate(context.Background(), fsadapter.NewDirectory(dir), src); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	page := readFile(t, filepath.Join(dir, "C2.html"))
+	for _, want := range []string{
+		`Shared from <a href="C1.html#1700000001.000100">#general</a>`,
+		`Shared from C9 <span class="share-missing">(not in the archive)</span>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("C2.html does not contain %q", want)
+		}
+	}
+}
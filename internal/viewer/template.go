@@ -20,10 +20,12 @@ func initTemplates(v *Viewer) {
 			"is_app_msg":      isAppMsg,
 			"displayname":     v.um.DisplayName,
 			"username":        v.username, // username returns the username for the message
+			"avatar":          v.avatar,   // avatar returns the avatar URL of the message sender
 			"time":            localtime,
 			"rendertext":      func(s string) template.HTML { return v.r.RenderText(context.Background(), s) },     // render message text
 			"render":          func(m *slack.Message) template.HTML { return v.r.Render(context.Background(), m) }, // render message
 			"is_thread_start": st.IsThreadStart,
+			"daterange":       daterange,
 		},
 	).ParseFS(fsys, "templates/*.html"))
 	v.tmpl = tmpl
@@ -37,6 +39,27 @@ func localtime(ts string) string {
 	return t.Local().Format(time.DateTime)
 }
 
+// daterange returns the date range of the messages, it assumes that messages
+// are sorted in the ascending order.
+func daterange(mm []slack.Message) string {
+	if len(mm) == 0 {
+		return ""
+	}
+	first, err := st.ParseSlackTS(mm[0].Timestamp)
+	if err != nil {
+		return ""
+	}
+	last, err := st.ParseSlackTS(mm[len(mm)-1].Timestamp)
+	if err != nil {
+		return ""
+	}
+	from, to := first.Local().Format(time.DateOnly), last.Local().Format(time.DateOnly)
+	if from == to {
+		return from
+	}
+	return from + " – " + to
+}
+
 type sender int
 
 const (
@@ -76,6 +99,22 @@ func (v *Viewer) username(m *slack.Message) string {
 	}
 }
 
+// avatar returns the URL of the sender's avatar image, or an empty string, if
+// it's not known.
+func (v *Viewer) avatar(m *slack.Message) string {
+	switch msgsender(m) {
+	case sUser:
+		if u, ok := v.um[m.User]; ok {
+			return u.Profile.Image48
+		}
+	case sBot, sApp:
+		if m.BotProfile != nil && m.BotProfile.Icons != nil {
+			return m.BotProfile.Icons.Image48
+		}
+	}
+	return ""
+}
+
 func isAppMsg(m *slack.Message) bool {
 	return msgsender(m) == sApp
 }
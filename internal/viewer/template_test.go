@@ -5,9 +5,11 @@ import (
 	"log/slog"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/rusq/slack"
 	"github.com/rusq/slackdump/v3/internal/fixtures"
+	"github.com/rusq/slackdump/v3/internal/source"
 	st "github.com/rusq/slackdump/v3/internal/structures"
 	"github.com/rusq/slackdump/v3/internal/viewer/renderer"
 )
@@ -16,7 +18,7 @@ func TestViewer_username(t *testing.T) {
 	type fields struct {
 		ch   channels
 		um   st.UserIndex
-		rtr  Sourcer
+		rtr  source.Sourcer
 		tmpl *template.Template
 		srv  *http.Server
 		lg   *slog.Logger
@@ -60,3 +62,50 @@ func TestViewer_username(t *testing.T) {
 		})
 	}
 }
+
+func Test_daterange(t *testing.T) {
+	date := func(sec int64) string {
+		return time.Unix(sec, 0).Local().Format(time.DateOnly)
+	}
+	tests := []struct {
+		name string
+		mm   []slack.Message
+		want string
+	}{
+		{"empty", nil, ""},
+		{
+			"single day",
+			[]slack.Message{{Msg: slack.Msg{Timestamp: "1700000000.000100"}}, {Msg: slack.Msg{Timestamp: "1700000060.000200"}}},
+			date(1700000000),
+		},
+		{
+			"range",
+			[]slack.Message{{Msg: slack.Msg{Timestamp: "1600000000.000100"}}, {Msg: slack.Msg{Timestamp: "1700000000.000200"}}},
+			date(1600000000) + " – " + date(1700000000),
+		},
+		{"invalid", []slack.Message{{Msg: slack.Msg{Timestamp: "x"}}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := daterange(tt.mm); got != tt.want {
+				t.Errorf("daterange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTheme_Set(t *testing.T) {
+	var th Theme
+	if got := th.String(); got != string(ThemeAuto) {
+		t.Errorf("String() = %q, want %q", got, ThemeAuto)
+	}
+	if err := th.Set("Dark"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if th != ThemeDark {
+		t.Errorf("Set() = %q, want %q", th, ThemeDark)
+	}
+	if err := th.Set("solarized"); err == nil {
+		t.Error("Set() expected error for unknown theme")
+	}
+}
@@ -4,19 +4,22 @@ package viewer
 import (
 	"context"
 	"errors"
+	"fmt"
 	"html/template"
-	"io/fs"
 	"log/slog"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/rusq/slack"
 
+	"github.com/rusq/slackdump/v3/internal/hydrate"
+	"github.com/rusq/slackdump/v3/internal/source"
 	st "github.com/rusq/slackdump/v3/internal/structures"
 	"github.com/rusq/slackdump/v3/internal/viewer/renderer"
-	"github.com/rusq/slackdump/v3/internal/viewer/source"
+	"github.com/rusq/slackdump/v3/internal/viewer/renderer/functions"
 )
 
 var debug = os.Getenv("DEBUG") != ""
@@ -32,58 +35,109 @@ type Viewer struct {
 	// data
 	ch   channels
 	um   st.UserIndex
-	src  Sourcer
+	src  source.Sourcer
 	tmpl *template.Template
 
+	// settings
+	theme Theme
+	hy    *hydrate.Service
+
 	// handles
 	srv *http.Server
 	lg  *slog.Logger
 	r   renderer.Renderer
 }
 
-// Sourcer is an interface for retrieving data from different sources.
-type Sourcer interface {
-	// Name should return the name of the retriever underlying media, i.e.
-	// directory or archive.
-	Name() string
-	// Type should return the type of the retriever, i.e. "chunk" or "export".
-	Type() string
-	// Channels should return all channels.
-	Channels() ([]slack.Channel, error)
-	// Users should return all users.
-	Users() ([]slack.User, error)
-	// AllMessages should return all messages for the given channel id.
-	AllMessages(channelID string) ([]slack.Message, error)
-	// AllThreadMessages should return all messages for the given tuple
-	// (channelID, threadID).
-	AllThreadMessages(channelID, threadID string) ([]slack.Message, error)
-	// ChannelInfo should return the channel information for the given channel
-	// id.
-	ChannelInfo(channelID string) (*slack.Channel, error)
-	// FS should return the filesystem with file attachments.
-	FS() fs.FS
-	// File should return the path of the file within the filesystem returned
-	// by FS().
-	File(fileID string, filename string) (string, error)
-}
-
 const (
 	hour = 60 * time.Minute
 )
 
-// type assertion
-var (
-	_ Sourcer = &source.Export{}
-	_ Sourcer = &source.ChunkDir{}
-	_ Sourcer = &source.Dump{}
+// Theme is the colour theme of the viewer pages.
+type Theme string
+
+const (
+	// ThemeAuto follows the colour scheme preference of the system.
+	ThemeAuto Theme = "auto"
+	// ThemeLight is the light theme.
+	ThemeLight Theme = "light"
+	// ThemeDark is the dark theme.
+	ThemeDark Theme = "dark"
 )
 
+// String implements the flag.Value interface.
+func (t *Theme) String() string {
+	if t == nil || *t == "" {
+		return string(ThemeAuto)
+	}
+	return string(*t)
+}
+
+// Set implements the flag.Value interface.
+func (t *Theme) Set(s string) error {
+	switch th := Theme(strings.ToLower(s)); th {
+	case ThemeAuto, ThemeLight, ThemeDark:
+		*t = th
+	default:
+		return fmt.Errorf("unknown theme: %q, must be one of: %s, %s, %s", s, ThemeAuto, ThemeLight, ThemeDark)
+	}
+	return nil
+}
+
+// Option is the viewer option.
+type Option func(*Viewer)
+
+// WithTheme sets the colour theme of the viewer.  Default is [ThemeAuto].
+func WithTheme(t Theme) Option {
+	return func(v *Viewer) {
+		if t != "" {
+			v.theme = t
+		}
+	}
+}
+
+// WithHydrator sets the hydration service, that resolves the users, channels
+// and user groups, that are referenced in the messages, but are not in the
+// source.  The resolved channels are only used to render the mentions and
+// are not listed in the channel list.
+func WithHydrator(h *hydrate.Service) Option {
+	return func(v *Viewer) {
+		v.hy = h
+	}
+}
+
 // New creates new viewer instance.  Once [Viewer.ListenAndServe] is called, the
 // viewer will start serving the web interface on the given address.  The
 // address should be in the form of ":8080". The viewer will use the given
-// [Sourcer] to retrieve the data, see "source" package for available options.
+// [source.Sourcer] to retrieve the data, see "source" package for available options.
 // It will initialise the logger from the context.
-func New(ctx context.Context, addr string, r Sourcer) (*Viewer, error) {
+func New(ctx context.Context, addr string, r source.Sourcer, opts ...Option) (*Viewer, error) {
+	v, err := newViewer(r, functions.DefaultFilePrefix, serverLink, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
+	mux.HandleFunc("/", v.indexHandler)
+	// https: //ora600.slack.com/archives/CHY5HUESG
+	mux.HandleFunc("/archives/{id}", v.newFileHandler(v.channelHandler))
+	// https: //ora600.slack.com/archives/DHMAB25DY/p1710063528879959
+	mux.HandleFunc("/archives/{id}/{ts}", v.newFileHandler(v.threadHandler))
+	mux.HandleFunc("/team/{user_id}", v.userHandler)
+	mux.Handle("/slackdump/file/{id}/{filename}", cacheMwareFunc(3*hour)(http.HandlerFunc(v.fileHandler)))
+	v.srv = &http.Server{
+		Addr:    addr,
+		Handler: middleware.Logger(mux),
+	}
+
+	return v, nil
+}
+
+// newViewer initialises the viewer data and templates from the source r.
+// filePrefix is the URL prefix of the file attachments, link is the function
+// that returns the link to the message, it is used to link the shared
+// messages to their originals.
+func newViewer(r source.Sourcer, filePrefix string, link linkFunc, opts ...Option) (*Viewer, error) {
 	all, err := r.Channels()
 	if err != nil {
 		return nil, err
@@ -97,12 +151,29 @@ func New(ctx context.Context, addr string, r Sourcer) (*Viewer, error) {
 	um := st.NewUserIndex(uu)
 
 	v := &Viewer{
-		src: r,
-		ch:  cc,
-		um:  um,
-		lg:  slog.Default(),
+		src:   r,
+		ch:    cc,
+		um:    um,
+		lg:    slog.Default(),
+		theme: ThemeAuto,
+	}
+	for _, opt := range opts {
+		opt(v)
 	}
 	// postinit
+	var ug []slack.UserGroup
+	if src, ok := r.(source.UserGrouper); ok {
+		if groups, err := src.UserGroups(); err == nil {
+			ug = groups
+		}
+	}
+	if v.hy != nil {
+		uu = v.hy.Users(uu)
+		v.um = st.NewUserIndex(uu)
+		all = v.hy.Channels(all)
+		// resolved groups take precedence over the recorded ones.
+		ug = append(ug, v.hy.UserGroups()...)
+	}
 	initTemplates(v)
 	if debug {
 		v.r = &renderer.Debug{}
@@ -111,23 +182,11 @@ func New(ctx context.Context, addr string, r Sourcer) (*Viewer, error) {
 			v.tmpl,
 			renderer.WithUsers(indexusers(uu)),
 			renderer.WithChannels(indexchannels(all)),
+			renderer.WithUserGroups(indexusergroups(ug)),
+			renderer.WithFilePrefix(filePrefix),
+			renderer.WithShareLinkFunc(newMessageIndex(r, v.lg).shareLinkFunc(link)),
 		)
 	}
-
-	mux := http.NewServeMux()
-	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
-	mux.HandleFunc("/", v.indexHandler)
-	// https: //ora600.slack.com/archives/CHY5HUESG
-	mux.HandleFunc("/archives/{id}", v.newFileHandler(v.channelHandler))
-	// https: //ora600.slack.com/archives/DHMAB25DY/p1710063528879959
-	mux.HandleFunc("/archives/{id}/{ts}", v.newFileHandler(v.threadHandler))
-	mux.HandleFunc("/team/{user_id}", v.userHandler)
-	mux.Handle("/slackdump/file/{id}/{filename}", cacheMwareFunc(3*hour)(http.HandlerFunc(v.fileHandler)))
-	v.srv = &http.Server{
-		Addr:    addr,
-		Handler: middleware.Logger(mux),
-	}
-
 	return v, nil
 }
 
@@ -147,6 +206,14 @@ func (v *Viewer) Close() error {
 	return ee
 }
 
+func indexusergroups(ug []slack.UserGroup) map[string]slack.UserGroup {
+	var gm = make(map[string]slack.UserGroup, len(ug))
+	for _, g := range ug {
+		gm[g.ID] = g
+	}
+	return gm
+}
+
 func indexusers(uu []slack.User) map[string]slack.User {
 	var um = make(map[string]slack.User, len(uu))
 	for _, u := range uu {
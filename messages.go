@@ -32,6 +32,12 @@ func (s *Session) Dump(ctx context.Context, link string, oldest, latest time.Tim
 	if err != nil {
 		return nil, err
 	}
+	return s.dumpWithFiles(ctx, sl, oldest, latest, processFn...)
+}
+
+// dumpWithFiles dumps the conversation or thread sl, downloading the files,
+// if it is enabled in the session options.
+func (s *Session) dumpWithFiles(ctx context.Context, sl structures.SlackLink, oldest, latest time.Time, processFn ...ProcessFunc) (*types.Conversation, error) {
 	if s.cfg.dumpFiles {
 		fn, cancelFn, err := s.newFileProcessFn(ctx, sl.Channel, s.limiter(network.NoTier))
 		if err != nil {
@@ -68,6 +74,8 @@ func (s *Session) dump(ctx context.Context, sl structures.SlackLink, oldest, lat
 	if !sl.IsValid() {
 		return nil, errors.New("invalid link")
 	}
+	s.cfg.progress.AddTotal(1)
+	defer s.cfg.progress.ChannelDone()
 
 	if sl.IsThread() {
 		return s.dumpThreadAsConversation(ctx, sl, oldest, latest, processFn...)
@@ -108,7 +116,7 @@ func (s *Session) dumpChannel(ctx context.Context, channelID string, oldest, lat
 			resp *slack.GetConversationHistoryResponse
 		)
 		reqStart := time.Now()
-		if err := network.WithRetry(ctx, convLimiter, s.cfg.limits.Tier3.Retries, func() error {
+		if err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "conversations.history"), s.adaptive, convLimiter, s.cfg.limits.Tier3.Retries, func() error {
 			var err error
 			trace.WithRegion(ctx, "GetConversationHistoryContext", func() {
 				resp, err = s.client.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
@@ -133,6 +141,7 @@ func (s *Session) dumpChannel(ctx context.Context, channelID string, oldest, lat
 		}
 
 		chunk := types.ConvertMsgs(resp.Messages)
+		s.cfg.progress.AddMessages(len(chunk))
 
 		results, err := runProcessFuncs(chunk, channelID, pfns...)
 		if err != nil {
@@ -166,9 +175,14 @@ func (s *Session) dumpChannel(ctx context.Context, channelID string, oldest, lat
 }
 
 func (s *Session) getChannelName(ctx context.Context, l *rate.Limiter, channelID string) (string, error) {
+	if s.cfg.infoCache != nil {
+		if ci, ok := s.cfg.infoCache.Get(channelID); ok {
+			return ci.Name, nil
+		}
+	}
 	// get channel name
 	var ci *slack.Channel
-	if err := network.WithRetry(ctx, l, s.cfg.limits.Tier3.Retries, func() error {
+	if err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "conversations.info"), s.adaptive, l, s.cfg.limits.Tier3.Retries, func() error {
 		var err error
 		ci, err = s.client.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: channelID})
 		return err
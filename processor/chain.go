@@ -0,0 +1,71 @@
+package processor
+
+import (
+	"context"
+
+	"github.com/rusq/slack"
+)
+
+// Middleware wraps the [Conversations] processor next, to observe, modify or
+// filter the data before passing it on, i.e. to collect the statistics or to
+// drop the unwanted messages.  The returned processor must call next to pass
+// the data further down the chain.
+type Middleware func(next Conversations) Conversations
+
+// Chain returns the processor, that passes the data through the middlewares
+// mw in the given order, and then to proc, so that mw[0] receives the data
+// first.  If mw is empty, proc is returned.
+func Chain(proc Conversations, mw ...Middleware) Conversations {
+	for i := len(mw) - 1; i >= 0; i-- {
+		proc = mw[i](proc)
+	}
+	return proc
+}
+
+// Passthrough is the base of the middleware processors: it passes all calls
+// to the embedded processor, including the calls of the optional [Pinner]
+// and [MessageHistorian] interfaces, which would be lost by embedding the
+// [Conversations] interface directly.  The middleware embeds it and
+// overrides the methods it is interested in.
+type Passthrough struct {
+	Conversations
+}
+
+var (
+	_ Pinner           = Passthrough{}
+	_ MessageHistorian = Passthrough{}
+)
+
+// Pins passes the pinned items to the next processor, if it is a [Pinner].
+func (p Passthrough) Pins(ctx context.Context, channelID string, items []slack.Item) error {
+	if pp, ok := p.Conversations.(Pinner); ok {
+		return pp.Pins(ctx, channelID, items)
+	}
+	return nil
+}
+
+// Bookmarks passes the bookmarks to the next processor, if it is a [Pinner].
+func (p Passthrough) Bookmarks(ctx context.Context, channelID string, bookmarks []slack.Bookmark) error {
+	if pp, ok := p.Conversations.(Pinner); ok {
+		return pp.Bookmarks(ctx, channelID, bookmarks)
+	}
+	return nil
+}
+
+// MessageEdited passes the prior version of the edited message to the next
+// processor, if it is a [MessageHistorian].
+func (p Passthrough) MessageEdited(ctx context.Context, channelID string, prev slack.Message) error {
+	if h, ok := p.Conversations.(MessageHistorian); ok {
+		return h.MessageEdited(ctx, channelID, prev)
+	}
+	return nil
+}
+
+// MessageDeleted passes the last known version of the deleted message to the
+// next processor, if it is a [MessageHistorian].
+func (p Passthrough) MessageDeleted(ctx context.Context, channelID string, prev slack.Message) error {
+	if h, ok := p.Conversations.(MessageHistorian); ok {
+		return h.MessageDeleted(ctx, channelID, prev)
+	}
+	return nil
+}
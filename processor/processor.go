@@ -5,6 +5,8 @@ import (
 	"io"
 
 	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/types"
 )
 
 // Conversations is the interface for conversation fetching with files.
@@ -35,6 +37,28 @@ type Messenger interface {
 	ThreadMessages(ctx context.Context, channelID string, parent slack.Message, threadOnly, isLast bool, replies []slack.Message) error
 }
 
+// MessageHistorian is the optional interface of the [Messenger] processor,
+// that preserves the prior versions of the edited and deleted messages,
+// instead of overwriting them.
+type MessageHistorian interface {
+	// MessageEdited is called with the version of the message before the
+	// edit.  The new version is passed to Messages or ThreadMessages.
+	MessageEdited(ctx context.Context, channelID string, prev slack.Message) error
+	// MessageDeleted is called with the last known version of the deleted
+	// message.
+	MessageDeleted(ctx context.Context, channelID string, prev slack.Message) error
+}
+
+// Pinner is the optional interface of the [Conversations] processor, that
+// receives the pinned items and the bookmarks of each channel.  They are
+// fetched only if the stream is configured to, see stream.OptPins.
+type Pinner interface {
+	// Pins is called with the pinned items of the channel.
+	Pins(ctx context.Context, channelID string, items []slack.Item) error
+	// Bookmarks is called with the bookmarks of the channel.
+	Bookmarks(ctx context.Context, channelID string, bookmarks []slack.Bookmark) error
+}
+
 type Filer interface {
 	// Files method is called for each file that is retrieved. The parent message is
 	// passed in as well.
@@ -46,10 +70,23 @@ type Users interface {
 	Users(ctx context.Context, users []slack.User) error
 }
 
+type UserGroups interface {
+	// UserGroups method is called with the user groups of the workspace.
+	UserGroups(ctx context.Context, groups []slack.UserGroup) error
+}
+
 type WorkspaceInfo interface {
 	WorkspaceInfo(context.Context, *slack.AuthTestResponse) error
 }
 
+// WorkspaceDetailer is the optional interface of the [WorkspaceInfo]
+// processor.  If the processor implements it, WorkspaceDetails is called
+// instead of WorkspaceInfo with the extended workspace information, that is
+// available to the current user.
+type WorkspaceDetailer interface {
+	WorkspaceDetails(ctx context.Context, atr *slack.AuthTestResponse, wd *types.Workspace) error
+}
+
 type Channels interface {
 	// Channels is called for each channel chunk that is retrieved.
 	Channels(ctx context.Context, channels []slack.Channel) error
@@ -77,7 +77,11 @@ func (s *Session) newFileProcessFn(ctx context.Context, dir string, l *rate.Limi
 	if s.fs == nil {
 		return nil, nil, errors.New("filesystem not set, unable to download files")
 	}
-	dl := downloader.New(s.client, s.fs, downloader.Limiter(l), downloader.Retries(s.cfg.limits.DownloadRetries), downloader.Workers(s.cfg.limits.Workers))
+	opts := append([]downloader.Option{downloader.Limiter(l)}, s.DownloadOptions()...)
+	if s.cfg.progress != nil {
+		opts = append(opts, downloader.WithTracker(s.cfg.progress.Downloads()))
+	}
+	dl := downloader.New(s.client, s.fs, opts...)
 	// set up a file downloader and add it to the post-process functions
 	// slice
 	fileRequests := make(chan downloader.Request, filesCbufSz)
@@ -108,7 +112,9 @@ func pipeAndUpdateFiles(filesC chan<- downloader.Request, msgs []types.Message,
 	_ = files.Extract(msgs, files.Root, func(file slack.File, addr files.Addr) error {
 		filesC <- downloader.Request{
 			Fullpath: path.Join(dir, downloader.Filename(&file)),
-			URL:      file.URLPrivateDownload,
+			URL:      files.DownloadURL(&file),
+			FileID:   file.ID,
+			ModTime:  downloader.ModTime(&file),
 		}
 		total++
 		return files.Update(msgs, addr, files.UpdatePathFn(path.Join(dir, downloader.Filename(&file))))
@@ -0,0 +1,219 @@
+// Package progress provides the progress reporting for the Slack data
+// fetching.  [Tracker] accumulates the counters of completed channels,
+// fetched messages and downloaded files, estimates the time remaining, and
+// reports the snapshot of counters to the callback function.
+//
+// Tracker is safe for concurrent use, and all its methods are no-op on the
+// nil Tracker, so that the callers don't need to check if the progress
+// reporting is enabled.
+package progress
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rusq/slackdump/v3/downloader"
+	"github.com/rusq/slackdump/v3/internal/humanize"
+)
+
+// DefInterval is the default minimum interval between the reports.
+const DefInterval = 200 * time.Millisecond
+
+// timeNow is the time function, replaced in tests.
+var timeNow = time.Now
+
+// Stats is the snapshot of the progress counters.
+type Stats struct {
+	// Channels is the number of completed channels and threads.
+	Channels int
+	// ChannelsTotal is the number of channels and threads known so far, it
+	// may grow while the channel list is being fetched.  Zero means that
+	// the total is unknown.
+	ChannelsTotal int
+	// Messages is the number of fetched messages, including thread replies.
+	Messages int
+	// Files is the number of downloaded files.
+	Files int
+	// Bytes is the number of bytes written by the downloaded files.
+	Bytes int64
+	// Elapsed is the time since the tracker was created.
+	Elapsed time.Duration
+	// ETA is the estimated time remaining, zero if it can't be estimated.
+	ETA time.Duration
+}
+
+func (s Stats) String() string {
+	var buf strings.Builder
+	if s.ChannelsTotal > 0 {
+		fmt.Fprintf(&buf, "%d/%d channels", s.Channels, s.ChannelsTotal)
+	} else {
+		fmt.Fprintf(&buf, "%d channels", s.Channels)
+	}
+	fmt.Fprintf(&buf, ", %d messages", s.Messages)
+	if s.Files > 0 {
+		fmt.Fprintf(&buf, ", %d files (%s)", s.Files, humanize.Size(s.Bytes))
+	}
+	if s.ETA > 0 {
+		fmt.Fprintf(&buf, ", ETA %s", s.ETA.Round(time.Second))
+	}
+	return buf.String()
+}
+
+// Func is the function that receives the progress reports.
+type Func func(Stats)
+
+// Tracker tracks the progress of the fetching.
+type Tracker struct {
+	start    time.Time
+	fn       Func
+	interval time.Duration
+
+	total    atomic.Int64
+	channels atomic.Int64
+	messages atomic.Int64
+	files    atomic.Int64
+	bytes    atomic.Int64
+
+	mu   sync.Mutex // serialises the calls to fn.
+	last time.Time  // time of the last report.
+}
+
+// Option is the functional option for the Tracker.
+type Option func(*Tracker)
+
+// WithInterval sets the minimum interval between the reports.  Zero or
+// negative interval makes the tracker report every update.
+func WithInterval(d time.Duration) Option {
+	return func(t *Tracker) {
+		t.interval = d
+	}
+}
+
+// New creates a new Tracker, that calls fn with the updated stats, at most
+// once in [DefInterval].  fn may be nil, if the caller polls the stats with
+// [Tracker.Stats].  fn is never called concurrently.
+func New(fn Func, opts ...Option) *Tracker {
+	t := &Tracker{
+		start:    timeNow(),
+		fn:       fn,
+		interval: DefInterval,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// ToChan returns the Func that sends the stats to channel c.  If the channel
+// is not ready to receive, the report is dropped, so that the fetching is
+// never blocked by the slow reader.
+func ToChan(c chan<- Stats) Func {
+	return func(s Stats) {
+		select {
+		case c <- s:
+		default:
+		}
+	}
+}
+
+// AddTotal adds n to the total number of channels.
+func (t *Tracker) AddTotal(n int) {
+	if t == nil {
+		return
+	}
+	t.total.Add(int64(n))
+	t.report(false)
+}
+
+// ChannelDone marks one channel or thread as complete.
+func (t *Tracker) ChannelDone() {
+	if t == nil {
+		return
+	}
+	t.channels.Add(1)
+	t.report(false)
+}
+
+// AddMessages adds n to the number of fetched messages.
+func (t *Tracker) AddMessages(n int) {
+	if t == nil || n <= 0 {
+		return
+	}
+	t.messages.Add(int64(n))
+	t.report(false)
+}
+
+// FileDone marks one file as downloaded, n is the number of bytes written.
+func (t *Tracker) FileDone(n int64) {
+	if t == nil {
+		return
+	}
+	t.files.Add(1)
+	t.bytes.Add(n)
+	t.report(false)
+}
+
+// Finish reports the final stats, regardless of the interval.
+func (t *Tracker) Finish() {
+	if t == nil {
+		return
+	}
+	t.report(true)
+}
+
+// Stats returns the current stats.
+func (t *Tracker) Stats() Stats {
+	if t == nil {
+		return Stats{}
+	}
+	s := Stats{
+		Channels:      int(t.channels.Load()),
+		ChannelsTotal: int(t.total.Load()),
+		Messages:      int(t.messages.Load()),
+		Files:         int(t.files.Load()),
+		Bytes:         t.bytes.Load(),
+		Elapsed:       timeNow().Sub(t.start),
+	}
+	if s.ChannelsTotal < s.Channels {
+		// threads, requested directly, may complete before they are counted.
+		s.ChannelsTotal = s.Channels
+	}
+	if 0 < s.Channels && s.Channels < s.ChannelsTotal {
+		s.ETA = time.Duration(float64(s.Elapsed) / float64(s.Channels) * float64(s.ChannelsTotal-s.Channels))
+	}
+	return s
+}
+
+// report calls the callback function, if the interval since the last
+// report has passed, or force is true.
+func (t *Tracker) report(force bool) {
+	if t.fn == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := timeNow()
+	if !force && now.Sub(t.last) < t.interval {
+		return
+	}
+	t.last = now
+	t.fn(t.Stats())
+}
+
+// Downloads returns the [downloader.Tracker], that counts the downloaded
+// files and bytes written.
+func (t *Tracker) Downloads() downloader.Tracker {
+	return dlTracker{t}
+}
+
+// dlTracker adapts the Tracker to the downloader.Tracker interface.
+type dlTracker struct {
+	t *Tracker
+}
+
+func (d dlTracker) Pending(downloader.Request)             {}
+func (d dlTracker) Failed(downloader.Request, error)       {}
+func (d dlTracker) Complete(_ downloader.Request, n int64) { d.t.FileDone(n) }
@@ -0,0 +1,123 @@
+package progress
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v3/downloader"
+)
+
+// fakeClock replaces timeNow with the clock, that is advanced manually.
+func fakeClock(t *testing.T) *time.Time {
+	t.Helper()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	old := timeNow
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = old })
+	return &now
+}
+
+func TestTracker_Stats(t *testing.T) {
+	now := fakeClock(t)
+	tr := New(nil)
+	tr.AddTotal(4)
+	tr.AddMessages(100)
+	tr.AddMessages(-1) // ignored
+	tr.FileDone(1 << 20)
+	tr.ChannelDone()
+	*now = now.Add(30 * time.Second)
+
+	got := tr.Stats()
+	assert.Equal(t, Stats{
+		Channels:      1,
+		ChannelsTotal: 4,
+		Messages:      100,
+		Files:         1,
+		Bytes:         1 << 20,
+		Elapsed:       30 * time.Second,
+		ETA:           90 * time.Second,
+	}, got)
+	assert.Equal(t, "1/4 channels, 100 messages, 1 files (1.0M), ETA 1m30s", got.String())
+}
+
+func TestTracker_StatsNoETA(t *testing.T) {
+	fakeClock(t)
+	tr := New(nil)
+	tr.ChannelDone()
+	got := tr.Stats()
+	assert.Equal(t, 1, got.ChannelsTotal, "total must not be less than complete")
+	assert.Zero(t, got.ETA)
+	assert.Equal(t, "1/1 channels, 0 messages", got.String())
+}
+
+func TestTracker_report(t *testing.T) {
+	now := fakeClock(t)
+	var got []Stats
+	tr := New(func(s Stats) { got = append(got, s) }, WithInterval(time.Second))
+	tr.AddTotal(2) // reported, first report
+	tr.AddMessages(10)
+	tr.AddMessages(10)
+	*now = now.Add(time.Second)
+	tr.ChannelDone() // reported, interval passed
+	tr.ChannelDone()
+	tr.Finish() // always reported
+
+	if assert.Len(t, got, 3) {
+		assert.Equal(t, 2, got[0].ChannelsTotal)
+		assert.Equal(t, 1, got[1].Channels)
+		assert.Equal(t, 20, got[1].Messages)
+		assert.Equal(t, 2, got[2].Channels)
+	}
+}
+
+func TestTracker_nil(t *testing.T) {
+	var tr *Tracker
+	assert.NotPanics(t, func() {
+		tr.AddTotal(1)
+		tr.AddMessages(1)
+		tr.ChannelDone()
+		tr.FileDone(1)
+		tr.Finish()
+	})
+	assert.Equal(t, Stats{}, tr.Stats())
+}
+
+func TestTracker_concurrent(t *testing.T) {
+	tr := New(func(Stats) {}, WithInterval(0))
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				tr.AddMessages(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1000, tr.Stats().Messages)
+}
+
+func TestTracker_Downloads(t *testing.T) {
+	tr := New(nil)
+	dt := tr.Downloads()
+	req := downloader.Request{Fullpath: "a/b", URL: "http://example.com/b"}
+	dt.Pending(req)
+	dt.Complete(req, 42)
+	dt.Failed(req, assert.AnError)
+	got := tr.Stats()
+	assert.Equal(t, 1, got.Files)
+	assert.Equal(t, int64(42), got.Bytes)
+}
+
+func TestToChan(t *testing.T) {
+	c := make(chan Stats, 1)
+	fn := ToChan(c)
+	fn(Stats{Messages: 1})
+	fn(Stats{Messages: 2}) // dropped, channel is full
+	assert.Equal(t, Stats{Messages: 1}, <-c)
+	assert.Empty(t, c)
+}
@@ -0,0 +1,111 @@
+package slackdump
+
+// In this file: search related code.
+
+import (
+	"context"
+	"errors"
+	"runtime/trace"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/network"
+	"github.com/rusq/slackdump/v3/types"
+)
+
+// searchCount is the number of search results requested per API call, it is
+// the maximum allowed by the search.messages API.
+const searchCount = 100
+
+// SearchMessages returns all messages matching the query.  The query supports
+// the Slack search modifiers, i.e. "from:@user in:#channel before:2024-01-01".
+// Results are returned in the order they were received from the API, newest
+// first.
+func (s *Session) SearchMessages(ctx context.Context, query string) ([]slack.SearchMessage, error) {
+	ctx, task := trace.NewTask(ctx, "SearchMessages")
+	defer task.End()
+
+	if query == "" {
+		return nil, errors.New("empty search query")
+	}
+
+	var (
+		lim = s.limiter(network.Tier2)
+		p   = slack.SearchParameters{
+			Sort:          "timestamp",
+			SortDirection: "desc",
+			Count:         searchCount,
+			Cursor:        "*",
+		}
+		mm []slack.SearchMessage
+	)
+	for i := 1; ; i++ {
+		var sm *slack.SearchMessages
+		if err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "search.messages"), s.adaptive, lim, s.cfg.limits.Tier2.Retries, func() error {
+			var err error
+			sm, err = s.client.SearchMessagesContext(ctx, query, p)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		mm = append(mm, sm.Matches...)
+		s.log.InfoContext(ctx, "search", "request", i, "fetched", len(sm.Matches), "total", len(mm))
+		if sm.NextCursor == "" {
+			break
+		}
+		p.Cursor = sm.NextCursor
+	}
+	return mm, nil
+}
+
+// DumpSearch returns all messages matching the query grouped by the channel,
+// in the same format as Dump.  Conversations are in the order in which the
+// channels first appear in the search results, messages within each
+// conversation are sorted by timestamp.
+func (s *Session) DumpSearch(ctx context.Context, query string) ([]*types.Conversation, error) {
+	sm, err := s.SearchMessages(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return searchConversations(sm), nil
+}
+
+// searchConversations groups the search results by the channel.
+func searchConversations(sm []slack.SearchMessage) []*types.Conversation {
+	var (
+		convs []*types.Conversation
+		idx   = make(map[string]*types.Conversation)
+	)
+	for _, m := range sm {
+		c, ok := idx[m.Channel.ID]
+		if !ok {
+			c = &types.Conversation{ID: m.Channel.ID, Name: m.Channel.Name}
+			idx[m.Channel.ID] = c
+			convs = append(convs, c)
+		}
+		c.Messages = append(c.Messages, searchMessage(m))
+	}
+	for _, c := range convs {
+		types.SortMessages(c.Messages)
+	}
+	return convs
+}
+
+// searchMessage converts the search result to the message.
+func searchMessage(sm slack.SearchMessage) types.Message {
+	return types.Message{
+		Message: slack.Message{
+			Msg: slack.Msg{
+				Type:        sm.Type,
+				Channel:     sm.Channel.ID,
+				User:        sm.User,
+				Username:    sm.Username,
+				Timestamp:   sm.Timestamp,
+				Text:        sm.Text,
+				Blocks:      sm.Blocks,
+				Attachments: sm.Attachments,
+				Permalink:   sm.Permalink,
+			},
+		},
+	}
+}
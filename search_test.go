@@ -0,0 +1,107 @@
+package slackdump
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/rusq/slackdump/v3/types"
+)
+
+func TestSession_SearchMessages(t *testing.T) {
+	var (
+		page1 = []slack.SearchMessage{
+			{Channel: slack.CtxChannel{ID: "C1"}, Timestamp: "3.0"},
+			{Channel: slack.CtxChannel{ID: "C2"}, Timestamp: "2.0"},
+		}
+		page2 = []slack.SearchMessage{
+			{Channel: slack.CtxChannel{ID: "C1"}, Timestamp: "1.0"},
+		}
+	)
+	tests := []struct {
+		name     string
+		query    string
+		expectfn func(m *mockClienter)
+		want     []slack.SearchMessage
+		wantErr  bool
+	}{
+		{
+			"paginates",
+			"from:@bob in:#general",
+			func(m *mockClienter) {
+				first := m.EXPECT().
+					SearchMessagesContext(gomock.Any(), "from:@bob in:#general", gomock.Cond(func(p slack.SearchParameters) bool { return p.Cursor == "*" })).
+					Return(&slack.SearchMessages{Matches: page1, Pagination: slack.Pagination{NextCursor: "next"}}, nil)
+				m.EXPECT().
+					SearchMessagesContext(gomock.Any(), "from:@bob in:#general", gomock.Cond(func(p slack.SearchParameters) bool { return p.Cursor == "next" })).
+					Return(&slack.SearchMessages{Matches: page2}, nil).
+					After(first)
+			},
+			append(append([]slack.SearchMessage{}, page1...), page2...),
+			false,
+		},
+		{
+			"empty query",
+			"",
+			func(m *mockClienter) {},
+			nil,
+			true,
+		},
+		{
+			"error is propagated",
+			"foo",
+			func(m *mockClienter) {
+				m.EXPECT().
+					SearchMessagesContext(gomock.Any(), "foo", gomock.Any()).
+					Return(nil, errors.New("not today sir"))
+			},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcl := NewmockClienter(gomock.NewController(t))
+			tt.expectfn(mcl)
+			s := &Session{
+				client: mcl,
+				cfg:    defConfig,
+				log:    slog.Default(),
+			}
+			got, err := s.SearchMessages(context.Background(), tt.query)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_searchConversations(t *testing.T) {
+	sm := []slack.SearchMessage{
+		{Channel: slack.CtxChannel{ID: "C2", Name: "random"}, User: "U1", Timestamp: "3.0", Text: "c"},
+		{Channel: slack.CtxChannel{ID: "C1", Name: "general"}, User: "U2", Timestamp: "2.0", Text: "b"},
+		{Channel: slack.CtxChannel{ID: "C2", Name: "random"}, User: "U1", Timestamp: "1.0", Text: "a"},
+	}
+	got := searchConversations(sm)
+	require.Len(t, got, 2)
+
+	assert.Equal(t, "C2", got[0].ID)
+	assert.Equal(t, "random", got[0].Name)
+	require.Len(t, got[0].Messages, 2)
+	assert.Equal(t, "1.0", got[0].Messages[0].Timestamp)
+	assert.Equal(t, "3.0", got[0].Messages[1].Timestamp)
+	assert.Equal(t, "C2", got[0].Messages[0].Channel)
+
+	assert.Equal(t, []types.Message{searchMessage(sm[1])}, got[1].Messages)
+	assert.Equal(t, "U2", got[1].Messages[0].User)
+	assert.Equal(t, "b", got[1].Messages[0].Text)
+}
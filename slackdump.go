@@ -16,8 +16,10 @@ import (
 	"github.com/rusq/fsadapter"
 
 	"github.com/rusq/slackdump/v3/auth"
+	"github.com/rusq/slackdump/v3/downloader"
 	"github.com/rusq/slackdump/v3/internal/edge"
 	"github.com/rusq/slackdump/v3/internal/network"
+	"github.com/rusq/slackdump/v3/progress"
 	"github.com/rusq/slackdump/v3/stream"
 )
 
@@ -34,6 +36,11 @@ type Session struct {
 
 	wspInfo *WorkspaceInfo // workspace info
 
+	adaptive *network.Adaptive // adaptive rate controller, nil if disabled
+	// bandwidth is the file download rate limiter, shared by all
+	// downloaders of the session, nil if unlimited.
+	bandwidth *rate.Limiter
+
 	cfg config
 }
 
@@ -56,6 +63,8 @@ type Slacker interface {
 
 	SearchMessagesContext(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchMessages, error)
 	SearchFilesContext(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchFiles, error)
+
+	GetBotInfoContext(ctx context.Context, parameters slack.GetBotInfoParameters) (*slack.Bot, error)
 }
 
 // clienter is the interface with some functions of slack.Client with the sole
@@ -127,6 +136,52 @@ func WithSlackClient(cl clienter) Option {
 	}
 }
 
+// WithProgress sets the progress tracker, that receives the updates on the
+// fetched conversations, messages and downloaded files.  It is also passed to
+// the streams created with [Session.Stream].
+func WithProgress(p *progress.Tracker) Option {
+	return func(s *Session) {
+		s.cfg.progress = p
+	}
+}
+
+// WithChannelInfoCache sets the persistent channel information cache, that
+// is consulted before calling the conversations.info API.  It is also passed
+// to the streams created with [Session.Stream].
+func WithChannelInfoCache(c stream.ChannelInfoCache) Option {
+	return func(s *Session) {
+		s.cfg.infoCache = c
+	}
+}
+
+// WithCheckpointer sets the checkpointer, that persists the pagination
+// cursors of the conversations, so that the interrupted stream can be resumed
+// from them.  It is passed to the streams created with [Session.Stream], see
+// [stream.OptCheckpointer].
+func WithCheckpointer(cp stream.Checkpointer) Option {
+	return func(s *Session) {
+		s.cfg.checkpointer = cp
+	}
+}
+
+// WithFileWorkers sets the number of concurrent file download workers,
+// overriding the Workers value of the limits, see [WithLimits].  Zero or
+// negative value leaves the value of the limits.
+func WithFileWorkers(n int) Option {
+	return func(s *Session) {
+		s.cfg.fileWorkers = n
+	}
+}
+
+// WithBandwidthLimit limits the combined rate of the file downloads to bps
+// bytes per second.  Zero or negative value means no limit.  The limit is
+// shared by all downloaders, that use [Session.DownloadOptions].
+func WithBandwidthLimit(bps int64) Option {
+	return func(s *Session) {
+		s.cfg.bandwidth = bps
+	}
+}
+
 func WithForceEnterprise(b bool) Option {
 	return func(s *Session) {
 		s.cfg.forceEnterprise = b
@@ -160,6 +215,8 @@ func NewNoValidate(ctx context.Context, prov auth.Provider, opts ...Option) (*Se
 	for _, opt := range opts {
 		opt(sd)
 	}
+	sd.adaptive = sd.cfg.limits.NewAdaptive()
+	sd.bandwidth = downloader.NewBandwidthLimiter(sd.cfg.bandwidth)
 
 	if err := sd.initClient(ctx, prov, sd.cfg.forceEnterprise); err != nil {
 		return nil, err
@@ -168,6 +225,12 @@ func NewNoValidate(ctx context.Context, prov auth.Provider, opts ...Option) (*Se
 	return sd, nil
 }
 
+// Adaptive returns the adaptive rate controller of the session, or nil, if
+// the adaptive rate limiting is disabled in the limits.
+func (s *Session) Adaptive() *network.Adaptive {
+	return s.adaptive
+}
+
 // initWorkspaceInfo gets from the API and sets the workspace information for
 // the session.
 func (s *Session) initWorkspaceInfo(ctx context.Context, cl Slacker) error {
@@ -231,6 +294,20 @@ func (s *Session) Client() *slack.Client {
 	return nil // never gets here
 }
 
+// DownloadOptions returns the file downloader options, initialised from the
+// session limits, see [WithFileWorkers] and [WithBandwidthLimit].
+func (s *Session) DownloadOptions() []downloader.Option {
+	workers := s.cfg.limits.Workers
+	if s.cfg.fileWorkers > 0 {
+		workers = s.cfg.fileWorkers
+	}
+	return []downloader.Option{
+		downloader.Retries(s.cfg.limits.DownloadRetries),
+		downloader.Workers(workers),
+		downloader.WithBandwidthLimiter(s.bandwidth),
+	}
+}
+
 // CurrentUserID returns the user ID of the authenticated user.
 func (s *Session) CurrentUserID() string {
 	return s.wspInfo.UserID
@@ -260,5 +337,17 @@ func (s *Session) Info() *WorkspaceInfo {
 
 // Stream streams the channel, calling proc functions for each chunk.
 func (s *Session) Stream(opts ...stream.Option) *stream.Stream {
+	if s.cfg.progress != nil {
+		opts = append([]stream.Option{stream.OptProgress(s.cfg.progress)}, opts...)
+	}
+	if s.cfg.infoCache != nil {
+		opts = append([]stream.Option{stream.OptChannelInfoCache(s.cfg.infoCache)}, opts...)
+	}
+	if s.cfg.checkpointer != nil {
+		opts = append([]stream.Option{stream.OptCheckpointer(s.cfg.checkpointer)}, opts...)
+	}
+	if s.adaptive != nil {
+		opts = append([]stream.Option{stream.OptAdaptive(s.adaptive)}, opts...)
+	}
 	return stream.New(s.client, &s.cfg.limits, opts...)
 }
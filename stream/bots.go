@@ -0,0 +1,116 @@
+package stream
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/network"
+)
+
+// botCache caches the bot profiles resolved with bots.info, the bots that
+// failed to resolve are cached as nil, so that they are not requested again.
+type botCache struct {
+	mu sync.Mutex
+	m  map[string]*slack.BotProfile
+}
+
+func (c *botCache) get(id string) (*slack.BotProfile, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bp, ok := c.m[id]
+	return bp, ok
+}
+
+func (c *botCache) set(id string, bp *slack.BotProfile) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.m == nil {
+		c.m = make(map[string]*slack.BotProfile)
+	}
+	c.m[id] = bp
+}
+
+// needsBotProfile returns true if the message is posted by a bot, and the bot
+// profile is missing or lacks the app ID or name.  Older bot messages and
+// messages of the legacy integrations do not have the bot profile.
+func needsBotProfile(m *slack.Message) bool {
+	if m.BotID == "" {
+		return false
+	}
+	return m.BotProfile == nil || m.BotProfile.AppID == "" || m.BotProfile.Name == ""
+}
+
+// resolveBots fills in the bot profiles of the bot messages in mm, which do
+// not have it, using the bots.info API.  Resolving is best effort, the bots
+// that can't be resolved, i.e. deleted ones, are left as is.
+func (cs *Stream) resolveBots(ctx context.Context, mm []slack.Message) {
+	for i := range mm {
+		m := &mm[i]
+		if !needsBotProfile(m) {
+			continue
+		}
+		bp := cs.botProfile(ctx, m.BotID)
+		if bp == nil {
+			continue
+		}
+		m.BotProfile = mergeBotProfile(m.BotProfile, bp)
+	}
+}
+
+// botProfile returns the cached bot profile, or requests it from the API.
+func (cs *Stream) botProfile(ctx context.Context, botID string) *slack.BotProfile {
+	if bp, ok := cs.botCache.get(botID); ok {
+		return bp
+	}
+	var bot *slack.Bot
+	if err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "bots.info"), cs.limits.adaptive, cs.limits.bots, cs.limits.tier.Tier3.Retries, func() error {
+		var err error
+		bot, err = cs.client.GetBotInfoContext(ctx, slack.GetBotInfoParameters{Bot: botID})
+		return err
+	}); err != nil {
+		if ctx.Err() == nil {
+			slog.WarnContext(ctx, "unable to resolve the bot", "bot_id", botID, "error", err)
+			cs.botCache.set(botID, nil)
+		}
+		return nil
+	}
+	bp := &slack.BotProfile{
+		ID:      bot.ID,
+		AppID:   bot.AppID,
+		Name:    bot.Name,
+		Deleted: bot.Deleted,
+		Updated: int64(bot.Updated),
+	}
+	if bot.Icons != (slack.Icons{}) {
+		icons := bot.Icons
+		bp.Icons = &icons
+	}
+	cs.botCache.set(botID, bp)
+	return bp
+}
+
+// mergeBotProfile returns a copy of the bot profile from the message, with
+// the missing fields filled in from the resolved profile.
+func mergeBotProfile(have, res *slack.BotProfile) *slack.BotProfile {
+	if have == nil {
+		cp := *res
+		return &cp
+	}
+	bp := *have
+	if bp.ID == "" {
+		bp.ID = res.ID
+	}
+	if bp.AppID == "" {
+		bp.AppID = res.AppID
+	}
+	if bp.Name == "" {
+		bp.Name = res.Name
+	}
+	if bp.Icons == nil {
+		bp.Icons = res.Icons
+	}
+	return &bp
+}
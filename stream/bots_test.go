@@ -0,0 +1,59 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v3/internal/network"
+)
+
+// fakeBotSlacker returns the bots from the map, and counts the calls.
+type fakeBotSlacker struct {
+	Slacker
+	bots  map[string]slack.Bot
+	calls int
+}
+
+func (f *fakeBotSlacker) GetBotInfoContext(ctx context.Context, parameters slack.GetBotInfoParameters) (*slack.Bot, error) {
+	f.calls++
+	b, ok := f.bots[parameters.Bot]
+	if !ok {
+		return nil, errors.New("bot_not_found")
+	}
+	return &b, nil
+}
+
+func TestStream_resolveBots(t *testing.T) {
+	cl := &fakeBotSlacker{
+		bots: map[string]slack.Bot{
+			"B1": {ID: "B1", AppID: "A1", Name: "deploybot", Icons: slack.Icons{Image48: "https://example.com/48.png"}},
+		},
+	}
+	cs := New(cl, &network.NoLimits)
+
+	mm := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1.0", User: "U1"}},
+		{Msg: slack.Msg{Timestamp: "2.0", BotID: "B1"}},
+		{Msg: slack.Msg{Timestamp: "3.0", BotID: "B1", BotProfile: &slack.BotProfile{ID: "B1", Name: "Deploy Bot"}}},
+		{Msg: slack.Msg{Timestamp: "4.0", BotID: "B2"}},
+		{Msg: slack.Msg{Timestamp: "5.0", BotID: "B3", BotProfile: &slack.BotProfile{ID: "B3", AppID: "A3", Name: "complete"}}},
+	}
+	cs.resolveBots(context.Background(), mm)
+	cs.resolveBots(context.Background(), mm[3:4]) // B2 failure is cached
+
+	assert.Nil(t, mm[0].BotProfile)
+	assert.Equal(t, &slack.BotProfile{ID: "B1", AppID: "A1", Name: "deploybot", Icons: &slack.Icons{Image48: "https://example.com/48.png"}}, mm[1].BotProfile)
+	// existing name is retained, app ID is filled in.
+	assert.Equal(t, "Deploy Bot", mm[2].BotProfile.Name)
+	assert.Equal(t, "A1", mm[2].BotProfile.AppID)
+	assert.Nil(t, mm[3].BotProfile)
+	assert.Equal(t, "complete", mm[4].BotProfile.Name)
+
+	assert.Equal(t, 2, cl.calls, "B1 and B2 must be requested once each")
+	// the cached profile is not shared with the message.
+	assert.NotSame(t, mm[1].BotProfile, mm[2].BotProfile)
+}
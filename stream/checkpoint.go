@@ -0,0 +1,224 @@
+package stream
+
+// In this file: the pagination cursor checkpointing.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/rusq/slackdump/v3/internal/osext"
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+// Checkpoint is the saved position within the conversation.
+type Checkpoint struct {
+	// Cursor is the cursor of the next page to fetch.
+	Cursor string `json:"cursor,omitempty"`
+	// Done is set, once all pages of the conversation, and, for channels,
+	// all threads found in them, were processed.
+	Done bool `json:"done,omitempty"`
+}
+
+// Checkpointer persists the checkpoints of the conversations, so that the
+// interrupted stream can be resumed from the last processed page.  The keys
+// identify the conversation and the time range it was requested with, they
+// should be treated as opaque.  It must be safe for concurrent use.
+type Checkpointer interface {
+	// Load returns the checkpoint saved for the key, and false, if there is
+	// none.
+	Load(key string) (Checkpoint, bool, error)
+	// Save saves the checkpoint for the key.
+	Save(key string, c Checkpoint) error
+}
+
+// checkpointKey returns the checkpointer key of the request.  The cursors are
+// only valid for the same request parameters, so the key includes the time
+// range.
+func (cs *Stream) checkpointKey(req request) string {
+	return fmt.Sprintf("%s|%s|%s",
+		req.sl,
+		structures.FormatSlackTS(structures.NVLTime(req.Oldest, cs.oldest)),
+		structures.FormatSlackTS(structures.NVLTime(req.Latest, cs.latest)),
+	)
+}
+
+// convCheckpoint is the checkpoint state of a single conversation.  The
+// checkpoint of the channel page is saved once the page, all pages before it,
+// and all threads found on them are processed, so that no thread is lost, if
+// the stream is interrupted while the threads are being fetched.
+type convCheckpoint struct {
+	cp    Checkpointer
+	key   string
+	start Checkpoint // checkpoint loaded at the start.
+
+	mu    sync.Mutex
+	pages []*pageMark // pages, that are not saved yet, oldest first.
+	cur   *pageMark   // page being processed.
+}
+
+// loadCheckpoint loads the checkpoint of the request.  It returns nil, if
+// checkpointing is disabled.
+func (cs *Stream) loadCheckpoint(req request) (*convCheckpoint, error) {
+	if cs.checkpointer == nil {
+		return nil, nil
+	}
+	key := cs.checkpointKey(req)
+	c, _, err := cs.checkpointer.Load(key)
+	if err != nil {
+		return nil, fmt.Errorf("error loading checkpoint %q: %w", key, err)
+	}
+	return &convCheckpoint{cp: cs.checkpointer, key: key, start: c}, nil
+}
+
+// begin registers the page, next is the checkpoint to save, once the page is
+// processed.
+func (c *convCheckpoint) begin(next Checkpoint) *pageMark {
+	if c == nil {
+		return nil
+	}
+	m := &pageMark{c: c, next: next}
+	c.mu.Lock()
+	c.pages = append(c.pages, m)
+	c.cur = m
+	c.mu.Unlock()
+	return m
+}
+
+// current returns the page being processed.
+func (c *convCheckpoint) current() *pageMark {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cur
+}
+
+// save saves the checkpoint of the last page, that has all the pages before
+// it complete.
+func (c *convCheckpoint) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var n int
+	for n < len(c.pages) && c.pages[n].complete() {
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	next := c.pages[n-1].next
+	c.pages = c.pages[n:]
+	return c.cp.Save(c.key, next)
+}
+
+// pageMark tracks the processing of the page and its threads.
+type pageMark struct {
+	c    *convCheckpoint
+	next Checkpoint
+
+	// the fields below are protected by c.mu.
+	processed bool
+	pending   int // number of threads, that are not processed yet.
+}
+
+func (m *pageMark) complete() bool {
+	return m.processed && m.pending <= 0
+}
+
+// addThreads registers n threads found on the page.
+func (m *pageMark) addThreads(n int) {
+	if m == nil {
+		return
+	}
+	m.c.mu.Lock()
+	m.pending += n
+	m.c.mu.Unlock()
+}
+
+// done marks the page as processed, and saves the checkpoint, if possible.
+func (m *pageMark) done() error {
+	if m == nil {
+		return nil
+	}
+	m.c.mu.Lock()
+	m.processed = true
+	m.c.mu.Unlock()
+	return m.c.save()
+}
+
+// threadDone marks one thread of the page as processed, and saves the
+// checkpoint, if possible.  The error is logged, as there is no caller to
+// return it to.
+func (m *pageMark) threadDone() {
+	if m == nil {
+		return
+	}
+	m.c.mu.Lock()
+	m.pending--
+	m.c.mu.Unlock()
+	if err := m.c.save(); err != nil {
+		slog.Warn("unable to save the checkpoint", "key", m.c.key, "error", err)
+	}
+}
+
+// FileCheckpointer is the [Checkpointer], that keeps the checkpoints in the
+// JSON file.  The file is rewritten on each save.
+type FileCheckpointer struct {
+	filename string
+
+	mu sync.Mutex
+	cp map[string]Checkpoint
+}
+
+// NewFileCheckpointer returns the checkpointer, that keeps the checkpoints in
+// the file filename, loading the existing ones, if the file exists.
+func NewFileCheckpointer(filename string) (*FileCheckpointer, error) {
+	fc := &FileCheckpointer{filename: filename, cp: make(map[string]Checkpoint)}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fc, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fc.cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %q: %w", filename, err)
+	}
+	return fc, nil
+}
+
+// Load returns the checkpoint for the key.
+func (fc *FileCheckpointer) Load(key string) (Checkpoint, bool, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	c, ok := fc.cp[key]
+	return c, ok, nil
+}
+
+// Save saves the checkpoint for the key, and writes all checkpoints to the
+// file.
+func (fc *FileCheckpointer) Save(key string, c Checkpoint) error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.cp[key] = c
+	data, err := json.MarshalIndent(fc.cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	// the file is replaced atomically, so that the checkpoint file is not
+	// corrupted, if the process is interrupted.
+	f, err := osext.CreateAtomic(fc.filename)
+	if err != nil {
+		return err
+	}
+	defer f.Abort()
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	return f.Close()
+}
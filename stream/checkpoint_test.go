@@ -0,0 +1,114 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusq/slackdump/v3/internal/network"
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+// memCheckpointer is the in-memory checkpointer, that records the saves.
+type memCheckpointer struct {
+	cp    map[string]Checkpoint
+	saves []Checkpoint
+}
+
+func (m *memCheckpointer) Load(key string) (Checkpoint, bool, error) {
+	c, ok := m.cp[key]
+	return c, ok, nil
+}
+
+func (m *memCheckpointer) Save(key string, c Checkpoint) error {
+	if m.cp == nil {
+		m.cp = make(map[string]Checkpoint)
+	}
+	m.cp[key] = c
+	m.saves = append(m.saves, c)
+	return nil
+}
+
+func TestStream_channel_checkpoint(t *testing.T) {
+	var msgs []slack.Message
+	for i := range 7 {
+		msgs = append(msgs, slack.Message{Msg: slack.Msg{Timestamp: strconv.Itoa(1700000000+i) + ".000000"}})
+	}
+	var (
+		cp  memCheckpointer
+		req = request{sl: &structures.SlackLink{Channel: "C1"}}
+		cs  = New(&fakeHistory{msgs: msgs, pageSize: 3}, &network.NoLimits, OptCheckpointer(&cp))
+	)
+	errStop := errors.New("stop")
+
+	// the first run is interrupted on the second page.
+	ck, err := cs.loadCheckpoint(req)
+	require.NoError(t, err)
+	req.ck = ck
+	var got []slack.Message
+	err = cs.channel(context.Background(), req, func(mm []slack.Message, isLast bool) error {
+		if len(got) > 0 {
+			return errStop
+		}
+		got = append(got, mm...)
+		return nil
+	})
+	require.ErrorIs(t, err, errStop)
+	assert.Equal(t, []Checkpoint{{Cursor: "3"}}, cp.saves)
+
+	// the second run resumes from the second page.
+	ck, err = cs.loadCheckpoint(req)
+	require.NoError(t, err)
+	req.ck = ck
+	err = cs.channel(context.Background(), req, func(mm []slack.Message, isLast bool) error {
+		got = append(got, mm...)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, msgs, got)
+	assert.Equal(t, []Checkpoint{{Cursor: "3"}, {Cursor: "6"}, {Done: true}}, cp.saves)
+}
+
+func Test_convCheckpoint_threads(t *testing.T) {
+	var cp memCheckpointer
+	c := &convCheckpoint{cp: &cp, key: "C1"}
+
+	// the first page has a thread, that is processed after the second page.
+	p1 := c.begin(Checkpoint{Cursor: "1"})
+	p1.addThreads(1)
+	require.NoError(t, p1.done())
+	assert.Same(t, p1, c.current())
+	p2 := c.begin(Checkpoint{Done: true})
+	require.NoError(t, p2.done())
+	assert.Empty(t, cp.saves, "page with the pending thread must not be saved")
+
+	p1.threadDone()
+	assert.Equal(t, []Checkpoint{{Done: true}}, cp.saves)
+}
+
+func TestFileCheckpointer(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "checkpoints.json")
+	fc, err := NewFileCheckpointer(filename)
+	require.NoError(t, err)
+	_, ok, err := fc.Load("C1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, fc.Save("C1", Checkpoint{Cursor: "abc"}))
+	require.NoError(t, fc.Save("C2", Checkpoint{Done: true}))
+
+	fc, err = NewFileCheckpointer(filename)
+	require.NoError(t, err)
+	c, ok, err := fc.Load("C1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, Checkpoint{Cursor: "abc"}, c)
+	c, _, _ = fc.Load("C2")
+	assert.Equal(t, Checkpoint{Done: true}, c)
+}
@@ -2,6 +2,7 @@ package stream
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"runtime/trace"
@@ -12,6 +13,7 @@ import (
 	"github.com/rusq/slackdump/v3/internal/network"
 	"github.com/rusq/slackdump/v3/internal/structures"
 	"github.com/rusq/slackdump/v3/processor"
+	"github.com/rusq/slackdump/v3/progress"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -57,10 +59,17 @@ func (cs *Stream) ConversationsCB(ctx context.Context, proc processor.Conversati
 // result with IsLast is received, the caller can assume that all threads and
 // messages for that channel have been processed.  For example, see
 // [cmd/slackdump/internal/export/expproc].
-func (cs *Stream) Conversations(ctx context.Context, proc processor.Conversations, items <-chan structures.EntityItem) error {
+//
+// The data is passed through the middlewares mw in the given order, before
+// it reaches proc, see [processor.Chain].  It allows to register several
+// processors, i.e. the statistics collector and the filter in front of the
+// recorder, for one pass over the API data.
+func (cs *Stream) Conversations(ctx context.Context, proc processor.Conversations, items <-chan structures.EntityItem, mw ...processor.Middleware) error {
 	ctx, task := trace.NewTask(ctx, "AsyncConversations")
 	defer task.End()
 
+	proc = processor.Chain(proc, mw...)
+
 	// create channels
 	chansC := make(chan request, msgChanSz)
 	threadsC := make(chan request, threadChanSz)
@@ -107,7 +116,9 @@ func (cs *Stream) Conversations(ctx context.Context, proc processor.Conversation
 					}
 					if err := processLink(chansC, threadsC, item); err != nil {
 						resultsC <- Result{Type: RTMain, Err: fmt.Errorf("item error: %q: %w", item.String(), err)}
+						continue
 					}
+					cs.progress.AddTotal(1)
 				}
 			}
 		}()
@@ -121,10 +132,18 @@ func (cs *Stream) Conversations(ctx context.Context, proc processor.Conversation
 	}()
 
 	// result processing.
+	done := newCompletion(cs.progress)
 	for res := range resultsC {
+		done.update(res)
 		if err := res.Err; err != nil {
 			trace.Logf(ctx, "error", "type: %s, chan_id: %s, thread_ts: %s, error: %s", res.Type, res.ChannelID, res.ThreadTS, err.Error())
-			return err
+			if !cs.skippable(ctx, res) {
+				return err
+			}
+			if err := cs.errorFn(res); err != nil {
+				return err
+			}
+			continue
 		}
 		for _, fn := range cs.resultFn {
 			if err := fn(res); err != nil {
@@ -136,6 +155,77 @@ func (cs *Stream) Conversations(ctx context.Context, proc processor.Conversation
 	return nil
 }
 
+// completion tracks the completion of the channels and threads, and reports
+// the completed ones to the progress tracker.  The channel is complete, once
+// the last page of its messages and all of its threads are received.  It is
+// not safe for concurrent use.
+type completion struct {
+	pt *progress.Tracker
+	// threads is the number of outstanding threads of the channel, it may
+	// become negative, as the thread results may arrive before the channel
+	// result that has them counted.
+	threads map[string]int
+	// last is set for the channels, once the last page of messages is
+	// received.
+	last map[string]bool
+}
+
+// newCompletion returns the completion tracker, or nil, if pt is nil.
+func newCompletion(pt *progress.Tracker) *completion {
+	if pt == nil {
+		return nil
+	}
+	return &completion{
+		pt:      pt,
+		threads: make(map[string]int),
+		last:    make(map[string]bool),
+	}
+}
+
+// update updates the completion state with the result res.  The failed
+// results are considered complete.
+func (c *completion) update(res Result) {
+	if c == nil || res.ChannelID == "" {
+		return
+	}
+	final := res.IsLast || res.Err != nil
+	switch res.Type {
+	case RTChannel:
+		c.threads[res.ChannelID] += res.ThreadCount
+		if final {
+			c.last[res.ChannelID] = true
+		}
+	case RTThread:
+		if !final {
+			return
+		}
+		if res.threadOnly {
+			c.pt.ChannelDone()
+			return
+		}
+		c.threads[res.ChannelID]--
+	default:
+		return
+	}
+	if c.last[res.ChannelID] && c.threads[res.ChannelID] <= 0 {
+		delete(c.last, res.ChannelID)
+		delete(c.threads, res.ChannelID)
+		c.pt.ChannelDone()
+	}
+}
+
+// skippable returns true if the failed result can be passed to the error
+// function, instead of aborting the stream.
+func (cs *Stream) skippable(ctx context.Context, res Result) bool {
+	if cs.errorFn == nil || ctx.Err() != nil {
+		return false
+	}
+	if res.ChannelID == "" || (res.Type != RTChannel && res.Type != RTThread) {
+		return false
+	}
+	return !errors.Is(res.Err, context.Canceled) && !errors.Is(res.Err, context.DeadlineExceeded)
+}
+
 // processLink parses the link and sends it to the appropriate output channel.
 func processLink(channels chan<- request, threads chan<- request, link structures.EntityItem) error {
 	sl, err := structures.ParseLink(link.Id)
@@ -160,6 +250,12 @@ type request struct {
 	threadOnly bool
 	Oldest     time.Time
 	Latest     time.Time
+
+	// ck is the checkpoint of the conversation, if checkpointing is enabled.
+	ck *convCheckpoint
+	// mark is the channel page, the thread was found on, it is notified,
+	// once the thread is processed.
+	mark *pageMark
 }
 
 func (we *Result) Error() string {
@@ -171,15 +267,21 @@ func (we *Result) Unwrap() error {
 }
 
 func (cs *Stream) channel(ctx context.Context, req request, callback func(mm []slack.Message, isLast bool) error) error {
-	ctx, task := trace.NewTask(ctx, "channel")
+	ctx, task := linkTask(ctx, "channel", req.sl)
 	defer task.End()
+	if !req.Oldest.IsZero() || !req.Latest.IsZero() {
+		trace.Logf(ctx, "range", "oldest=%s, latest=%s", req.Oldest, req.Latest)
+	}
 
 	lg := slog.With("channel_id", req.sl.String())
 
 	cursor := ""
-	for {
+	if req.ck != nil {
+		cursor = req.ck.start.Cursor
+	}
+	for pageNum := 1; ; pageNum++ {
 		var resp *slack.GetConversationHistoryResponse
-		if err := network.WithRetry(ctx, cs.limits.channels, cs.limits.tier.Tier3.Retries, func() error {
+		if err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "conversations.history"), cs.limits.adaptive, cs.limits.channels, cs.limits.tier.Tier3.Retries, func() error {
 			var apiErr error
 			r := trace.StartRegion(ctx, "GetConversationHistoryContext")
 			defer r.End()
@@ -199,7 +301,9 @@ func (cs *Stream) channel(ctx context.Context, req request, callback func(mm []s
 			trace.Logf(ctx, "error", "not ok, api error=%s", resp.Error)
 			return fmt.Errorf("response not ok, slack error: %s", resp.Error)
 		}
+		trace.Logf(ctx, "page", "n=%d, messages=%d, has_more=%t", pageNum, len(resp.Messages), resp.HasMore)
 
+		mark := req.ck.begin(Checkpoint{Cursor: resp.ResponseMetaData.NextCursor, Done: !resp.HasMore})
 		r := trace.StartRegion(ctx, "channel_callback")
 		err := callback(resp.Messages, !resp.HasMore)
 		r.End()
@@ -207,6 +311,9 @@ func (cs *Stream) channel(ctx context.Context, req request, callback func(mm []s
 			// lg.Printf("channel %s, callback error: %s", id, err)
 			return fmt.Errorf("channel %s, callback error: %w", req.sl.Channel, err)
 		}
+		if err := mark.done(); err != nil {
+			return fmt.Errorf("channel %s, error saving checkpoint: %w", req.sl.Channel, err)
+		}
 
 		if !resp.HasMore {
 			lg.DebugContext(ctx, "server reported channel done")
@@ -220,7 +327,7 @@ func (cs *Stream) channel(ctx context.Context, req request, callback func(mm []s
 // thread fetches the whole thread identified by SlackLink, calling callback
 // function fn for each slice received.
 func (cs *Stream) thread(ctx context.Context, req request, callback func(mm []slack.Message, isLast bool) error) error {
-	ctx, task := trace.NewTask(ctx, "thread")
+	ctx, task := linkTask(ctx, "thread", req.sl)
 	defer task.End()
 
 	if !req.sl.IsThread() {
@@ -231,13 +338,18 @@ func (cs *Stream) thread(ctx context.Context, req request, callback func(mm []sl
 	lg.DebugContext(ctx, "- getting")
 
 	var cursor string
-	for {
+	if req.ck != nil {
+		cursor = req.ck.start.Cursor
+	}
+	for pageNum := 1; ; pageNum++ {
 		var (
 			msgs    []slack.Message
 			hasmore bool
 		)
-		if err := network.WithRetry(ctx, cs.limits.threads, cs.limits.tier.Tier3.Retries, func() error {
+		if err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "conversations.replies"), cs.limits.adaptive, cs.limits.threads, cs.limits.tier.Tier3.Retries, func() error {
 			var apiErr error
+			r := trace.StartRegion(ctx, "GetConversationRepliesContext")
+			defer r.End()
 			msgs, hasmore, cursor, apiErr = cs.client.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
 				ChannelID: req.sl.Channel,
 				Timestamp: req.sl.ThreadTS,
@@ -251,10 +363,12 @@ func (cs *Stream) thread(ctx context.Context, req request, callback func(mm []sl
 		}); err != nil {
 			return err
 		}
+		trace.Logf(ctx, "page", "n=%d, messages=%d, has_more=%t", pageNum, len(msgs), hasmore)
 
+		mark := req.ck.begin(Checkpoint{Cursor: cursor, Done: !hasmore})
 		// got just the leader message, no replies
 		if len(msgs) <= 1 {
-			return nil
+			return mark.done()
 		}
 
 		r := trace.StartRegion(ctx, "thread_callback")
@@ -263,6 +377,9 @@ func (cs *Stream) thread(ctx context.Context, req request, callback func(mm []sl
 		if err != nil {
 			return err
 		}
+		if err := mark.done(); err != nil {
+			return fmt.Errorf("thread %s, error saving checkpoint: %w", req.sl, err)
+		}
 
 		if !hasmore {
 			break
@@ -272,9 +389,10 @@ func (cs *Stream) thread(ctx context.Context, req request, callback func(mm []sl
 }
 
 // procChanMsg processes the message slice mm, for each threaded message, it
-// sends the thread request on threadC.  It returns thread count in the mm and
-// error if any.
-func procChanMsg(ctx context.Context, proc processor.Conversations, threadC chan<- request, channel *slack.Channel, isLast bool, mm []slack.Message) (int, error) {
+// sends the thread request on threadC, the replies are fetched within the
+// time range rng of the channel request, and notify the page mark, once
+// processed.  It returns thread count in the mm and error if any.
+func procChanMsg(ctx context.Context, proc processor.Conversations, threadC chan<- request, channel *slack.Channel, rng timeRange, mark *pageMark, isLast bool, mm []slack.Message) (int, error) {
 	lg := slog.With("channel_id", channel.ID, "is_last", isLast, "msg_count", len(mm))
 
 	var trs = make([]request, 0, len(mm))
@@ -291,6 +409,9 @@ func procChanMsg(ctx context.Context, proc processor.Conversations, threadC chan
 					Channel:  channel.ID,
 					ThreadTS: mm[i].Msg.ThreadTimestamp,
 				},
+				Oldest: rng.Oldest,
+				Latest: rng.Latest,
+				mark:   mark,
 			})
 		}
 		if err := procFiles(ctx, proc, channel, mm[i]); err != nil {
@@ -303,6 +424,7 @@ func procChanMsg(ctx context.Context, proc processor.Conversations, threadC chan
 		}
 		return 0, fmt.Errorf("channel %s: failed to process message chunk starting with id=%s (size=%d): %w", channel.ID, mm[0].Msg.Timestamp, len(mm), err)
 	}
+	mark.addThreads(len(trs))
 	for _, tr := range trs {
 		threadC <- tr
 	}
@@ -347,15 +469,19 @@ func procFiles(ctx context.Context, proc processor.Filer, channel *slack.Channel
 
 // procChannelInfo fetches the channel info and passes it to the processor.
 func (cs *Stream) procChannelInfo(ctx context.Context, proc processor.ChannelInformer, channelID string, threadTS string) (*slack.Channel, error) {
-	ctx, task := trace.NewTask(ctx, "channelInfo")
+	ctx, task := linkTask(ctx, "channelInfo", &structures.SlackLink{Channel: channelID, ThreadTS: threadTS})
 	defer task.End()
 
-	trace.Logf(ctx, "channel_id", "%s, threadTS=%q", channelID, threadTS)
-
 	// to avoid fetching the same channel info multiple times, we cache it.
 	var info *slack.Channel
-	if info = cs.chanCache.get(channelID); info == nil {
-		if err := network.WithRetry(ctx, cs.limits.channels, cs.limits.tier.Tier3.Retries, func() error {
+	if info = cs.chanCache.get(channelID); info == nil && cs.infoCache != nil {
+		if ci, ok := cs.infoCache.Get(channelID); ok {
+			info = ci
+			cs.chanCache.set(channelID, info)
+		}
+	}
+	if info == nil {
+		if err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "conversations.info"), cs.limits.adaptive, cs.limits.channels, cs.limits.tier.Tier3.Retries, func() error {
 			var err error
 			info, err = cs.client.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{
 				ChannelID:         channelID,
@@ -370,6 +496,9 @@ func (cs *Stream) procChannelInfo(ctx context.Context, proc processor.ChannelInf
 			return nil, fmt.Errorf("api error: %s: %w", channelID, err)
 		}
 		cs.chanCache.set(channelID, info)
+		if cs.infoCache != nil {
+			cs.infoCache.Put(info)
+		}
 	}
 	if err := proc.ChannelInfo(ctx, info, threadTS); err != nil {
 		return nil, err
@@ -385,7 +514,7 @@ func (cs *Stream) procChannelUsers(ctx context.Context, proc processor.ChannelIn
 	for {
 		var u []string
 		var next string
-		if err := network.WithRetry(ctx, cs.limits.channels, cs.limits.tier.Tier4.Retries, func() error {
+		if err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "conversations.members"), cs.limits.adaptive, cs.limits.channels, cs.limits.tier.Tier4.Retries, func() error {
 			var err error
 			u, next, err = cs.client.GetUsersInConversationContext(ctx, &slack.GetUsersInConversationParameters{
 				ChannelID: channelID,
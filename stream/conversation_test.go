@@ -3,10 +3,12 @@ package stream
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/rusq/slack"
 	"github.com/rusq/slackdump/v3/internal/fixtures"
 	"github.com/rusq/slackdump/v3/mocks/mock_processor"
+	"github.com/rusq/slackdump/v3/progress"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
@@ -120,7 +122,7 @@ func Test_procChanMsg(t *testing.T) {
 			if tt.expectFn != nil {
 				tt.expectFn(mp)
 			}
-			got, err := procChanMsg(tt.args.ctx, mp, tt.args.threadC, tt.args.channel, tt.args.isLast, tt.args.mm)
+			got, err := procChanMsg(tt.args.ctx, mp, tt.args.threadC, tt.args.channel, timeRange{}, nil, tt.args.isLast, tt.args.mm)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("procChanMsg() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -131,3 +133,55 @@ func Test_procChanMsg(t *testing.T) {
 		})
 	}
 }
+
+func Test_procChanMsg_threadRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mp := mock_processor.NewMockConversations(ctrl)
+	mm := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000000.000100", ThreadTimestamp: "1700000000.000100", ReplyCount: 1}},
+	}
+	mp.EXPECT().Messages(gomock.Any(), TestChannel.ID, 1, true, mm).Return(nil)
+
+	rng := timeRange{
+		Oldest: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		Latest: time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC),
+	}
+	threadC := make(chan request, 1)
+	n, err := procChanMsg(context.Background(), mp, threadC, TestChannel, rng, nil, true, mm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, n)
+	req := <-threadC
+	assert.Equal(t, "1700000000.000100", req.sl.ThreadTS)
+	assert.Equal(t, rng.Oldest, req.Oldest, "thread should inherit the channel range")
+	assert.Equal(t, rng.Latest, req.Latest, "thread should inherit the channel range")
+}
+
+func Test_completion_update(t *testing.T) {
+	pt := progress.New(nil)
+	c := newCompletion(pt)
+	results := []Result{
+		{Type: RTChannel, ChannelID: "C1", ThreadCount: 1},
+		// thread result may arrive before the channel result that counts it.
+		{Type: RTThread, ChannelID: "C1", ThreadTS: "2", IsLast: true},
+		{Type: RTChannel, ChannelID: "C1", ThreadCount: 2, IsLast: true},
+		{Type: RTThread, ChannelID: "C1", ThreadTS: "1", IsLast: false},
+		{Type: RTThread, ChannelID: "C1", ThreadTS: "1", IsLast: true},
+		{Type: RTChannel, ChannelID: "C2", IsLast: true},                                 // complete: no threads
+		{Type: RTThread, ChannelID: "C3", ThreadTS: "1", IsLast: true, threadOnly: true}, // complete: thread only
+		{Type: RTChannel, ChannelID: "C4", Err: assert.AnError},                          // complete: failed
+		{Type: RTMain, Err: assert.AnError},
+	}
+	for _, res := range results {
+		c.update(res)
+	}
+	assert.Equal(t, 3, pt.Stats().Channels, "C1 is not complete yet")
+	c.update(Result{Type: RTThread, ChannelID: "C1", ThreadTS: "3", Err: assert.AnError})
+	assert.Equal(t, 4, pt.Stats().Channels)
+	assert.Empty(t, c.threads)
+	assert.Empty(t, c.last)
+
+	// nil completion is a no-op.
+	assert.NotPanics(t, func() { newCompletion(nil).update(results[0]) })
+}
@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/network"
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+// pinLister is implemented by the clients, that can list the pinned items of
+// the channel, i.e. [slack.Client].
+type pinLister interface {
+	ListPinsContext(ctx context.Context, channel string) ([]slack.Item, *slack.Paging, error)
+}
+
+// OptPins enables fetching of the pinned items and the bookmarks of each
+// channel, they are passed to the processor, if it implements
+// [processor.Pinner].  The pins.list API is Tier 2, so it slows down the
+// streaming of many small channels.
+func OptPins(enabled bool) Option {
+	return func(cs *Stream) {
+		cs.pins = enabled
+	}
+}
+
+// procPins fetches the pinned items and the bookmarks of the channel and
+// passes them to the processor, if it implements [processor.Pinner], and
+// fetching is enabled.  The API errors are logged and ignored, as pins and
+// bookmarks are complementary to the messages.
+func (cs *Stream) procPins(ctx context.Context, proc processor.Conversations, channelID string) error {
+	p, ok := proc.(processor.Pinner)
+	if !cs.pins || !ok {
+		return nil
+	}
+	lg := slog.With("channel_id", channelID)
+	if pl, ok := cs.client.(pinLister); ok {
+		var items []slack.Item
+		err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "pins.list"), cs.limits.adaptive, cs.limits.pins, cs.limits.tier.Tier2.Retries, func() error {
+			var err error
+			items, _, err = pl.ListPinsContext(ctx, channelID)
+			return err
+		})
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+			lg.WarnContext(ctx, "unable to get the pinned items", "error", err)
+		default:
+			if err := p.Pins(ctx, channelID, items); err != nil {
+				return err
+			}
+		}
+	}
+
+	var bookmarks []slack.Bookmark
+	err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "bookmarks.list"), cs.limits.adaptive, cs.limits.bookmarks, cs.limits.tier.Tier3.Retries, func() error {
+		var err error
+		bookmarks, err = cs.client.ListBookmarks(channelID)
+		return err
+	})
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case err != nil:
+		lg.WarnContext(ctx, "unable to get the bookmarks", "error", err)
+		return nil
+	}
+	return p.Bookmarks(ctx, channelID, bookmarks)
+}
@@ -0,0 +1,88 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v3/internal/network"
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+// fakePinSlacker returns the pinned items and bookmarks.
+type fakePinSlacker struct {
+	Slacker
+	pins      []slack.Item
+	bookmarks []slack.Bookmark
+	pinErr    error
+}
+
+func (f *fakePinSlacker) ListPinsContext(ctx context.Context, channel string) ([]slack.Item, *slack.Paging, error) {
+	if f.pinErr != nil {
+		return nil, nil, f.pinErr
+	}
+	return f.pins, &slack.Paging{}, nil
+}
+
+func (f *fakePinSlacker) ListBookmarks(channelID string) ([]slack.Bookmark, error) {
+	return f.bookmarks, nil
+}
+
+// pinCollector is the processor, that collects the pins and bookmarks.
+type pinCollector struct {
+	processor.Conversations
+	pins      map[string][]slack.Item
+	bookmarks map[string][]slack.Bookmark
+}
+
+func (p *pinCollector) Pins(ctx context.Context, channelID string, items []slack.Item) error {
+	p.pins[channelID] = items
+	return nil
+}
+
+func (p *pinCollector) Bookmarks(ctx context.Context, channelID string, bookmarks []slack.Bookmark) error {
+	p.bookmarks[channelID] = bookmarks
+	return nil
+}
+
+func newPinCollector() *pinCollector {
+	return &pinCollector{pins: make(map[string][]slack.Item), bookmarks: make(map[string][]slack.Bookmark)}
+}
+
+func TestStream_procPins(t *testing.T) {
+	ctx := context.Background()
+	cl := &fakePinSlacker{
+		pins:      []slack.Item{{Type: "message", Channel: "C1", Message: &slack.Message{Msg: slack.Msg{Timestamp: "1.0"}}}},
+		bookmarks: []slack.Bookmark{{ID: "Bk1", ChannelID: "C1", Title: "Runbook"}},
+	}
+	t.Run("disabled", func(t *testing.T) {
+		proc := newPinCollector()
+		cs := New(cl, &network.NoLimits)
+		if err := cs.procPins(ctx, proc, "C1"); err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, proc.pins)
+		assert.Empty(t, proc.bookmarks)
+	})
+	t.Run("enabled", func(t *testing.T) {
+		proc := newPinCollector()
+		cs := New(cl, &network.NoLimits, OptPins(true))
+		if err := cs.procPins(ctx, proc, "C1"); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, cl.pins, proc.pins["C1"])
+		assert.Equal(t, cl.bookmarks, proc.bookmarks["C1"])
+	})
+	t.Run("pins error is not fatal", func(t *testing.T) {
+		proc := newPinCollector()
+		cs := New(&fakePinSlacker{pinErr: errors.New("not_in_channel"), bookmarks: cl.bookmarks}, &network.NoLimits, OptPins(true))
+		if err := cs.procPins(ctx, proc, "C1"); err != nil {
+			t.Fatal(err)
+		}
+		assert.NotContains(t, proc.pins, "C1")
+		assert.Equal(t, cl.bookmarks, proc.bookmarks["C1"])
+	})
+}
@@ -0,0 +1,150 @@
+package stream
+
+// In this file: fetching the channel history split into date sub-ranges.
+
+import (
+	"context"
+	"log/slog"
+	"runtime/trace"
+	"time"
+
+	"github.com/rusq/slack"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+const (
+	// minSplitSpan is the minimum duration of a sub-range, channels with
+	// shorter time span are split into fewer sub-ranges.
+	minSplitSpan = 24 * time.Hour
+	// splitBufSz is the number of pages each sub-range fetcher can buffer
+	// ahead of the consumer.
+	splitBufSz = 32
+	// tsStep is the smallest step between two slack timestamps.  The
+	// fractional part of the slack timestamp is stored in nanoseconds by
+	// [structures.ParseSlackTS] and [structures.FormatSlackTS].
+	tsStep = time.Nanosecond
+)
+
+// timeRange is the closed time interval.
+type timeRange struct {
+	Oldest time.Time
+	Latest time.Time
+}
+
+// splitRange splits the time range [oldest, latest] into at most n adjacent
+// non-overlapping sub-ranges of equal duration, each at least minSpan long.
+// Sub-ranges are returned newest first, in the same order, as the API
+// returns the messages.  Sub-range boundaries are aligned to a second.
+func splitRange(oldest, latest time.Time, n int, minSpan time.Duration) []timeRange {
+	span := latest.Sub(oldest)
+	if minSpan > 0 && span/time.Duration(n) < minSpan {
+		n = int(span / minSpan)
+	}
+	if n <= 1 {
+		return []timeRange{{Oldest: oldest, Latest: latest}}
+	}
+	step := span / time.Duration(n)
+	ranges := make([]timeRange, 0, n)
+	hi := latest
+	for i := 1; i < n; i++ {
+		lo := latest.Add(-step * time.Duration(i)).Truncate(time.Second)
+		ranges = append(ranges, timeRange{Oldest: lo, Latest: hi})
+		hi = lo.Add(-tsStep)
+	}
+	return append(ranges, timeRange{Oldest: oldest, Latest: hi})
+}
+
+// page is the single response of the sub-range fetcher.
+type page struct {
+	mm []slack.Message
+}
+
+// splitChannel fetches the channel messages of the request, split into the
+// date sub-ranges, which are fetched concurrently.  The callback is called for
+// each page in the same order, as if the channel was fetched by
+// [Stream.channel], and isLast is set only for the last page of the oldest
+// sub-range.  created is the channel creation time, it is used as the start
+// of the range, if the oldest time is not set.
+func (cs *Stream) splitChannel(ctx context.Context, req request, created time.Time, callback func(mm []slack.Message, isLast bool) error) error {
+	ctx, task := linkTask(ctx, "splitChannel", req.sl)
+	defer task.End()
+
+	var (
+		oldest = structures.NVLTime(structures.NVLTime(req.Oldest, cs.oldest), created)
+		latest = structures.NVLTime(structures.NVLTime(req.Latest, cs.latest), time.Now())
+	)
+	if oldest.IsZero() || !oldest.Before(latest) {
+		return cs.channel(ctx, req, callback)
+	}
+	ranges := splitRange(oldest, latest, cs.split, minSplitSpan)
+	if len(ranges) == 1 {
+		return cs.channel(ctx, req, callback)
+	}
+	trace.Logf(ctx, "split", "oldest=%s, latest=%s, n=%d", oldest, latest, len(ranges))
+	slog.DebugContext(ctx, "fetching channel in sub-ranges", "channel_id", req.sl.Channel, "oldest", oldest, "latest", latest, "n", len(ranges))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	g, gctx := errgroup.WithContext(ctx)
+	var (
+		pages = make([]chan page, len(ranges))
+		errs  = make([]error, len(ranges))
+	)
+	for i, rng := range ranges {
+		pages[i] = make(chan page, splitBufSz)
+		sub := req
+		sub.Oldest, sub.Latest = rng.Oldest, rng.Latest
+		g.Go(func() error {
+			err := cs.channel(gctx, sub, func(mm []slack.Message, _ bool) error {
+				select {
+				case <-gctx.Done():
+					return context.Cause(gctx)
+				case pages[i] <- page{mm: mm}:
+					return nil
+				}
+			})
+			// errs[i] is read by the consumer after the channel is closed.
+			errs[i] = err
+			close(pages[i])
+			return err
+		})
+	}
+
+	err := emitPages(pages, errs, callback)
+	if err != nil {
+		// stop the fetchers, if the callback failed.
+		cancel()
+	}
+	if werr := g.Wait(); err == nil {
+		err = werr
+	}
+	return err
+}
+
+// emitPages calls the callback for each page, reading the page channels in
+// order.  The page is held until the next one arrives, so that the last page
+// can be marked with isLast.  errs[i] is the error of the fetcher of the
+// pages[i], it is checked once pages[i] is closed, and if set, emitPages
+// returns it without marking any page as last.
+func emitPages(pages []chan page, errs []error, callback func(mm []slack.Message, isLast bool) error) error {
+	var (
+		held    []slack.Message
+		hasHeld bool
+	)
+	for i, pc := range pages {
+		for p := range pc {
+			if hasHeld {
+				if err := callback(held, false); err != nil {
+					return err
+				}
+			}
+			held, hasHeld = p.mm, true
+		}
+		if errs[i] != nil {
+			return errs[i]
+		}
+	}
+	return callback(held, true)
+}
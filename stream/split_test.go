@@ -0,0 +1,146 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v3/internal/network"
+	"github.com/rusq/slackdump/v3/internal/structures"
+)
+
+func Test_splitRange(t *testing.T) {
+	var (
+		oldest = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		latest = time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC)
+	)
+	t.Run("splits into adjacent ranges, newest first", func(t *testing.T) {
+		got := splitRange(oldest, latest, 4, 24*time.Hour)
+		want := []timeRange{
+			{Oldest: time.Date(2020, 1, 4, 0, 0, 0, 0, time.UTC), Latest: latest},
+			{Oldest: time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC), Latest: time.Date(2020, 1, 4, 0, 0, 0, 0, time.UTC).Add(-tsStep)},
+			{Oldest: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), Latest: time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC).Add(-tsStep)},
+			{Oldest: oldest, Latest: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).Add(-tsStep)},
+		}
+		assert.Equal(t, want, got)
+	})
+	t.Run("minimum span limits the number of ranges", func(t *testing.T) {
+		got := splitRange(oldest, latest, 10, 48*time.Hour)
+		assert.Len(t, got, 2)
+	})
+	t.Run("span too short", func(t *testing.T) {
+		got := splitRange(oldest, oldest.Add(time.Hour), 4, 24*time.Hour)
+		assert.Equal(t, []timeRange{{Oldest: oldest, Latest: oldest.Add(time.Hour)}}, got)
+	})
+	t.Run("boundaries are formatted as adjacent timestamps", func(t *testing.T) {
+		got := splitRange(oldest, latest, 2, 0)
+		assert.Equal(t, "1578009599.999999", structures.FormatSlackTS(got[1].Latest))
+		assert.Equal(t, "1578009600.000000", structures.FormatSlackTS(got[0].Oldest))
+	})
+}
+
+// fakeHistory serves the conversation history from the list of messages,
+// sorted newest first, respecting the time range.
+type fakeHistory struct {
+	Slacker
+	msgs     []slack.Message
+	pageSize int
+	failTS   string // fail the request, that has this oldest timestamp
+}
+
+func (f *fakeHistory) GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+	if f.failTS != "" && params.Oldest == f.failTS {
+		return nil, errors.New("test error")
+	}
+	var inRange []slack.Message
+	for _, m := range f.msgs {
+		if (params.Oldest == "" || m.Timestamp >= params.Oldest) && (params.Latest == "" || m.Timestamp <= params.Latest) {
+			inRange = append(inRange, m)
+		}
+	}
+	start := 0
+	if params.Cursor != "" {
+		start, _ = strconv.Atoi(params.Cursor)
+	}
+	end := min(start+f.pageSize, len(inRange))
+	resp := &slack.GetConversationHistoryResponse{
+		SlackResponse: slack.SlackResponse{Ok: true},
+		Messages:      inRange[start:end],
+		HasMore:       end < len(inRange),
+	}
+	if resp.HasMore {
+		resp.ResponseMetaData.NextCursor = strconv.Itoa(end)
+	}
+	return resp, nil
+}
+
+func TestStream_splitChannel(t *testing.T) {
+	var (
+		oldest = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		latest = time.Date(2020, 1, 11, 0, 0, 0, 0, time.UTC)
+	)
+	// one message every 6 hours, including the range boundaries.
+	var msgs []slack.Message
+	for ts := latest; !ts.Before(oldest); ts = ts.Add(-6 * time.Hour) {
+		msgs = append(msgs, slack.Message{Msg: slack.Msg{Timestamp: structures.FormatSlackTS(ts)}})
+	}
+	req := request{sl: &structures.SlackLink{Channel: "C1"}, Oldest: oldest, Latest: latest}
+
+	t.Run("messages are returned in order", func(t *testing.T) {
+		cs := New(&fakeHistory{msgs: msgs, pageSize: 3}, &network.NoLimits, OptChannelSplit(4))
+		var (
+			got    []slack.Message
+			nLast  int
+			isLast bool
+		)
+		err := cs.splitChannel(context.Background(), req, time.Time{}, func(mm []slack.Message, last bool) error {
+			got = append(got, mm...)
+			if last {
+				nLast++
+			}
+			isLast = last
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, msgs, got)
+		assert.Equal(t, 1, nLast, "isLast must be set once")
+		assert.True(t, isLast, "the last page must be marked as last")
+	})
+	t.Run("channel creation time is used when oldest is not set", func(t *testing.T) {
+		cs := New(&fakeHistory{msgs: msgs, pageSize: 100}, &network.NoLimits, OptChannelSplit(4))
+		req := request{sl: &structures.SlackLink{Channel: "C1"}, Latest: latest}
+		var calls int
+		var got []slack.Message
+		err := cs.splitChannel(context.Background(), req, oldest, func(mm []slack.Message, last bool) error {
+			calls++
+			got = append(got, mm...)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, msgs, got)
+		assert.Equal(t, 4, calls, "expected one page per sub-range")
+	})
+	t.Run("fetcher error", func(t *testing.T) {
+		fh := &fakeHistory{msgs: msgs, pageSize: 3, failTS: structures.FormatSlackTS(oldest)}
+		cs := New(fh, &network.NoLimits, OptChannelSplit(4))
+		err := cs.splitChannel(context.Background(), req, time.Time{}, func(mm []slack.Message, last bool) error {
+			if last {
+				t.Error("page marked as last on error")
+			}
+			return nil
+		})
+		assert.Error(t, err)
+	})
+	t.Run("callback error", func(t *testing.T) {
+		cs := New(&fakeHistory{msgs: msgs, pageSize: 1}, &network.NoLimits, OptChannelSplit(4))
+		err := cs.splitChannel(context.Background(), req, time.Time{}, func(mm []slack.Message, last bool) error {
+			return errors.New("callback error")
+		})
+		assert.EqualError(t, err, "callback error")
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"runtime/trace"
 	"sync"
 	"time"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/rusq/slackdump/v3/internal/network"
 	"github.com/rusq/slackdump/v3/processor"
+	"github.com/rusq/slackdump/v3/progress"
 )
 
 const (
@@ -45,6 +47,8 @@ type Slacker interface {
 
 	SearchMessagesContext(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchMessages, error)
 	SearchFilesContext(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchFiles, error)
+
+	GetBotInfoContext(ctx context.Context, parameters slack.GetBotInfoParameters) (*slack.Bot, error)
 }
 
 // Stream is used to fetch conversations from Slack.  It is safe for concurrent
@@ -54,8 +58,33 @@ type Stream struct {
 	client         Slacker
 	limits         rateLimits
 	chanCache      *chanCache
+	botCache       *botCache
 	fastSearch     bool
 	resultFn       []func(sr Result) error
+	// errorFn is called for the failed channels and threads, if it returns
+	// nil, the failed entity is skipped, and the stream continues.
+	errorFn func(sr Result) error
+	// split is the number of date sub-ranges fetched concurrently for each
+	// channel.
+	split int
+	// progress receives the progress updates, may be nil.
+	progress *progress.Tracker
+	// infoCache is the persistent channel information cache, may be nil.
+	infoCache ChannelInfoCache
+	// checkpointer persists the pagination cursors, may be nil.
+	checkpointer Checkpointer
+	// pins enables fetching of the pinned items and bookmarks.
+	pins bool
+}
+
+// ChannelInfoCache is the persistent cache of the channel information, that
+// outlives the Stream, i.e. the on-disk cache shared between the runs.
+type ChannelInfoCache interface {
+	// Get returns the cached channel information, if it's present and not
+	// expired.
+	Get(channelID string) (*slack.Channel, bool)
+	// Put adds the channel information to the cache.
+	Put(ch *slack.Channel)
 }
 
 // chanCache is used to cache channel info to avoid fetching it multiple times.
@@ -105,6 +134,10 @@ type Result struct {
 	Count int
 	// Err contains the error if the result is an error.
 	Err error
+
+	// threadOnly is set for the thread results, if the thread was requested
+	// directly, and not found in the channel.
+	threadOnly bool
 }
 
 func (s Result) String() string {
@@ -124,9 +157,15 @@ type rateLimits struct {
 	channels    *rate.Limiter
 	threads     *rate.Limiter
 	users       *rate.Limiter
+	usersinfo   *rate.Limiter
 	searchmsg   *rate.Limiter
 	searchfiles *rate.Limiter
+	bots        *rate.Limiter
+	pins        *rate.Limiter
+	usergroups  *rate.Limiter
+	bookmarks   *rate.Limiter
 	tier        *network.Limits
+	adaptive    *network.Adaptive // nil, if the adaptive limiting is disabled
 }
 
 func limits(l *network.Limits) rateLimits {
@@ -134,9 +173,15 @@ func limits(l *network.Limits) rateLimits {
 		channels:    network.NewLimiter(network.Tier3, l.Tier3.Burst, int(l.Tier3.Boost)),
 		threads:     network.NewLimiter(network.Tier3, l.Tier3.Burst, int(l.Tier3.Boost)),
 		users:       network.NewLimiter(network.Tier2, l.Tier2.Burst, int(l.Tier2.Boost)),
+		usersinfo:   network.NewLimiter(network.Tier4, l.Tier4.Burst, int(l.Tier4.Boost)),
 		searchmsg:   network.NewLimiter(network.Tier2, l.Tier2.Burst, int(l.Tier2.Boost)),
 		searchfiles: network.NewLimiter(network.Tier2, l.Tier2.Burst, int(l.Tier2.Boost)),
+		bots:        network.NewLimiter(network.Tier3, l.Tier3.Burst, int(l.Tier3.Boost)),
+		pins:        network.NewLimiter(network.Tier2, l.Tier2.Burst, int(l.Tier2.Boost)),
+		usergroups:  network.NewLimiter(network.Tier2, l.Tier2.Burst, int(l.Tier2.Boost)),
+		bookmarks:   network.NewLimiter(network.Tier3, l.Tier3.Burst, int(l.Tier3.Boost)),
 		tier:        l,
+		adaptive:    l.NewAdaptive(),
 	}
 }
 
@@ -164,12 +209,70 @@ func OptResultFn(fn func(sr Result) error) Option {
 	}
 }
 
+// OptErrorFn sets the callback function that is called for each channel or
+// thread that failed to be fetched.  If fn returns nil, the channel or thread
+// is skipped, and the stream continues, otherwise the stream is aborted with
+// the returned error.  Without this option, any error aborts the stream.
+// Context errors always abort the stream.
+func OptErrorFn(fn func(sr Result) error) Option {
+	return func(cs *Stream) {
+		cs.errorFn = fn
+	}
+}
+
 func OptFastSearch() Option {
 	return func(cs *Stream) {
 		cs.fastSearch = true
 	}
 }
 
+// OptChannelSplit sets the number of date sub-ranges each channel history is
+// split into, the sub-ranges are fetched concurrently.  It speeds up fetching
+// of the channels with a long history.  Values less than 2 disable splitting.
+func OptChannelSplit(n int) Option {
+	return func(cs *Stream) {
+		cs.split = n
+	}
+}
+
+// OptProgress sets the progress tracker, that receives the number of
+// completed channels and fetched messages.
+func OptProgress(p *progress.Tracker) Option {
+	return func(cs *Stream) {
+		cs.progress = p
+	}
+}
+
+// OptChannelInfoCache sets the persistent channel information cache, that
+// is consulted before calling the conversations.info API.
+func OptChannelInfoCache(c ChannelInfoCache) Option {
+	return func(cs *Stream) {
+		cs.infoCache = c
+	}
+}
+
+// OptCheckpointer sets the checkpointer, that persists the pagination
+// cursors of the conversations after each processed page.  The conversations,
+// that have a checkpoint, are resumed from it, and the completed ones are
+// skipped.  A channel page is checkpointed once all threads found on it are
+// processed, so the pages after the checkpoint may be delivered to the
+// processor again.  The channels fetched with [OptChannelSplit] are not
+// checkpointed, their threads are.
+func OptCheckpointer(cp Checkpointer) Option {
+	return func(cs *Stream) {
+		cs.checkpointer = cp
+	}
+}
+
+// OptAdaptive sets the adaptive rate controller, so that the rates learned
+// by the caller are shared with the stream.  By default, the stream creates
+// its own controller, if the adaptive rate limiting is enabled in the limits.
+func OptAdaptive(ad *network.Adaptive) Option {
+	return func(cs *Stream) {
+		cs.limits.adaptive = ad
+	}
+}
+
 // New creates a new Stream instance that allows to stream different
 // slack entities.
 func New(cl Slacker, l *network.Limits, opts ...Option) *Stream {
@@ -177,6 +280,7 @@ func New(cl Slacker, l *network.Limits, opts ...Option) *Stream {
 		client:    cl,
 		limits:    limits(l),
 		chanCache: new(chanCache),
+		botCache:  new(botCache),
 	}
 	for _, opt := range opts {
 		opt(cs)
@@ -189,7 +293,9 @@ func New(cl Slacker, l *network.Limits, opts ...Option) *Stream {
 
 // WorkspaceInfo fetches the workspace info and passes it to the processor.
 // Getting it might be needed when the transformer need the current User ID or
-// Team ID. (Different teams within one workspace are not yet supported.)
+// Team ID. (Different teams within one workspace are not yet supported.)  If
+// the processor implements [processor.WorkspaceDetailer], the extended
+// workspace information is fetched as well, see [Stream.WorkspaceDetails].
 func (cs *Stream) WorkspaceInfo(ctx context.Context, proc processor.WorkspaceInfo) error {
 	ctx, task := trace.NewTask(ctx, "WorkspaceInfo")
 	defer task.End()
@@ -198,6 +304,9 @@ func (cs *Stream) WorkspaceInfo(ctx context.Context, proc processor.WorkspaceInf
 	if err != nil {
 		return err
 	}
+	if wdp, ok := proc.(processor.WorkspaceDetailer); ok {
+		return wdp.WorkspaceDetails(ctx, atr, cs.WorkspaceDetails(ctx, atr))
+	}
 
 	return proc.WorkspaceInfo(ctx, atr)
 }
@@ -210,7 +319,7 @@ func (cs *Stream) Users(ctx context.Context, proc processor.Users, opt ...slack.
 	p := cs.client.GetUsersPaginated(opt...)
 	var apiErr error
 	for apiErr == nil {
-		if apiErr = network.WithRetry(ctx, cs.limits.users, cs.limits.tier.Tier2.Retries, func() error {
+		if apiErr = network.WithAdaptiveRetry(network.WithEndpoint(ctx, "users.list"), cs.limits.adaptive, cs.limits.users, cs.limits.tier.Tier2.Retries, func() error {
 			var err error
 			p, err = p.Next(ctx)
 			return err
@@ -225,6 +334,81 @@ func (cs *Stream) Users(ctx context.Context, proc processor.Users, opt ...slack.
 	return p.Failure(errors.Unwrap(apiErr))
 }
 
+// usersInfoBatchSz is the number of users requested in a single users.info
+// call.
+const usersInfoBatchSz = 30
+
+// usersInformer is implemented by the clients, that can look up the users by
+// ID, i.e. [slack.Client].
+type usersInformer interface {
+	GetUsersInfoContext(ctx context.Context, users ...string) (*[]slack.User, error)
+}
+
+// UsersByID fetches the users with the given IDs, instead of listing all
+// users of the workspace, which is much faster, if only a fraction of users
+// is needed.  Users that are not found are skipped.
+func (cs *Stream) UsersByID(ctx context.Context, proc processor.Users, ids []string) error {
+	ctx, task := trace.NewTask(ctx, "UsersByID")
+	defer task.End()
+
+	ui, ok := cs.client.(usersInformer)
+	if !ok {
+		return errors.New("client does not support the user lookup")
+	}
+	for len(ids) > 0 {
+		batch := ids[:min(usersInfoBatchSz, len(ids))]
+		ids = ids[len(batch):]
+
+		uu, err := cs.usersInfo(ctx, ui, batch)
+		if err != nil {
+			if !isUserNotFound(err) {
+				return fmt.Errorf("API error: %w", err)
+			}
+			// one of the users does not exist, which fails the whole
+			// batch, look them up one by one.
+			for _, id := range batch {
+				u, err := cs.usersInfo(ctx, ui, []string{id})
+				if err != nil {
+					if !isUserNotFound(err) {
+						return fmt.Errorf("API error: %w", err)
+					}
+					slog.WarnContext(ctx, "user not found, skipping", "user_id", id)
+					continue
+				}
+				uu = append(uu, u...)
+			}
+		}
+		if len(uu) == 0 {
+			continue
+		}
+		if err := proc.Users(ctx, uu); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cs *Stream) usersInfo(ctx context.Context, ui usersInformer, ids []string) ([]slack.User, error) {
+	var uu *[]slack.User
+	if err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "users.info"), cs.limits.adaptive, cs.limits.usersinfo, cs.limits.tier.Tier4.Retries, func() error {
+		var err error
+		uu, err = ui.GetUsersInfoContext(ctx, ids...)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if uu == nil {
+		return nil, nil
+	}
+	return *uu, nil
+}
+
+// isUserNotFound returns true if the error is the Slack "user_not_found" error.
+func isUserNotFound(err error) bool {
+	var se slack.SlackErrorResponse
+	return errors.As(err, &se) && se.Err == "user_not_found"
+}
+
 // TODO: test this.
 func (cs *Stream) ListChannels(ctx context.Context, proc processor.Channels, p *slack.GetConversationsParameters) error {
 	ctx, task := trace.NewTask(ctx, "Channels")
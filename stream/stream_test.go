@@ -1,13 +1,18 @@
 package stream
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
 	"runtime/trace"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,6 +26,7 @@ import (
 	"github.com/rusq/slackdump/v3/internal/network"
 	"github.com/rusq/slackdump/v3/internal/structures"
 	"github.com/rusq/slackdump/v3/mocks/mock_processor"
+	"github.com/rusq/slackdump/v3/processor"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
@@ -119,6 +125,113 @@ func TestReplay(t *testing.T) {
 	}
 }
 
+// threadFilesSource returns the chunk file with the channel, that has a
+// thread, the parent and replies of which have files attached.
+func threadFilesSource(t *testing.T) *bytes.Reader {
+	t.Helper()
+	ctx := context.Background()
+	var buf bytes.Buffer
+	rec := chunk.NewRecorder(&buf)
+	ch := fixtures.DummyChannel("CTF1")
+	parent := slack.Message{Msg: slack.Msg{
+		Timestamp:       "1610000000.000000",
+		ThreadTimestamp: "1610000000.000000",
+		ReplyCount:      2,
+		LatestReply:     "1610000000.000002",
+		Files:           []slack.File{{ID: "FPARENT", Name: "parent.txt"}},
+	}}
+	replies := []slack.Message{
+		{Msg: slack.Msg{
+			Timestamp:       "1610000000.000001",
+			ThreadTimestamp: "1610000000.000000",
+			Files:           []slack.File{{ID: "FREPLY1", Name: "reply1.txt"}},
+		}},
+		{Msg: slack.Msg{
+			Timestamp:       "1610000000.000002",
+			ThreadTimestamp: "1610000000.000000",
+			Files:           []slack.File{{ID: "FREPLY2", Name: "reply2.txt"}},
+		}},
+	}
+	for _, fn := range []func() error{
+		func() error { return rec.ChannelInfo(ctx, ch, "") },
+		func() error { return rec.ChannelUsers(ctx, ch.ID, "", []string{"U123"}) },
+		func() error { return rec.ChannelInfo(ctx, ch, parent.ThreadTimestamp) },
+		func() error { return rec.ChannelUsers(ctx, ch.ID, parent.ThreadTimestamp, []string{"U123"}) },
+		func() error { return rec.Messages(ctx, ch.ID, 1, true, []slack.Message{parent}) },
+		func() error { return rec.ThreadMessages(ctx, ch.ID, parent, false, true, replies) },
+	} {
+		if err := fn(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+// recordedFiles replays the source chunk file through the stream and returns
+// the thread timestamps of the recorded file chunks, keyed by the file ID.
+func recordedFiles(t *testing.T, src io.ReadSeeker, item structures.EntityItem, opts ...chunktest.Option) map[string]string {
+	t.Helper()
+	srv := chunktest.NewServer(src, "U123", opts...)
+	defer srv.Close()
+	sd := slack.New("test", slack.OptionAPIURL(srv.URL()))
+
+	var buf bytes.Buffer
+	rec := chunk.NewRecorder(&buf)
+	cs := New(sd, &network.NoLimits)
+	if err := cs.SyncConversations(context.Background(), rec, item); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	cf, err := chunk.FromReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	if err := cf.ForEach(func(c *chunk.Chunk) error {
+		if c == nil || c.Type != chunk.CFiles {
+			return nil
+		}
+		for _, f := range c.Files {
+			if _, seen := got[f.ID]; seen {
+				t.Errorf("file %s recorded more than once", f.ID)
+			}
+			got[f.ID] = c.ThreadTS
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestReplay_threadFiles(t *testing.T) {
+	const threadTS = "1610000000.000000"
+	want := map[string]string{
+		"FPARENT": threadTS,
+		"FREPLY1": threadTS,
+		"FREPLY2": threadTS,
+	}
+	t.Run("channel", func(t *testing.T) {
+		got := recordedFiles(t, threadFilesSource(t), structures.EntityItem{Id: "CTF1"})
+		assert.Equal(t, want, got)
+	})
+	t.Run("thread link", func(t *testing.T) {
+		got := recordedFiles(t, threadFilesSource(t), structures.EntityItem{Id: "CTF1:" + threadTS})
+		assert.Equal(t, want, got)
+	})
+	t.Run("rate limited", func(t *testing.T) {
+		// every other request is rate limited, the stream must retry and
+		// produce the same result.
+		got := recordedFiles(t, threadFilesSource(t), structures.EntityItem{Id: "CTF1"}, chunktest.WithRateLimit(2, 0))
+		assert.Equal(t, want, got)
+	})
+}
+
 var testThread = []slack.Message{
 	{
 		Msg: slack.Msg{
@@ -342,3 +455,150 @@ func TestStream_Users(t *testing.T) {
 	err := s.Users(ctx, m)
 	assert.Error(t, err)
 }
+
+func TestStream_UsersByID(t *testing.T) {
+	ctx := context.Background()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		ids := strings.Split(r.FormValue("users"), ",")
+		if slices.Contains(ids, "UMISSING") {
+			_, _ = w.Write([]byte(`{"ok":false,"error":"user_not_found"}`))
+			return
+		}
+		var resp struct {
+			Ok    bool         `json:"ok"`
+			Users []slack.User `json:"users"`
+		}
+		resp.Ok = true
+		for _, id := range ids {
+			resp.Users = append(resp.Users, slack.User{ID: id, Name: "name-" + id})
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+	s := New(slack.New("test", slack.OptionAPIURL(srv.URL+"/")), &network.NoLimits)
+
+	var got []string
+	m := mock_processor.NewMockUsers(gomock.NewController(t))
+	m.EXPECT().Users(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, uu []slack.User) error {
+		for _, u := range uu {
+			got = append(got, u.ID)
+		}
+		return nil
+	}).AnyTimes()
+	err := s.UsersByID(ctx, m, []string{"U1", "UMISSING", "U2"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"U1", "U2"}, got)
+}
+
+func TestStream_Conversations_errorFn(t *testing.T) {
+	items := func() <-chan structures.EntityItem {
+		itemC := make(chan structures.EntityItem, 2)
+		itemC <- structures.EntityItem{Id: "CMISSING"}
+		itemC <- structures.EntityItem{Id: "CTF1"}
+		close(itemC)
+		return itemC
+	}
+	run := func(t *testing.T, opts ...Option) error {
+		t.Helper()
+		srv := chunktest.NewServer(threadFilesSource(t), "U123")
+		defer srv.Close()
+		sd := slack.New("test", slack.OptionAPIURL(srv.URL()))
+
+		rec := chunk.NewRecorder(io.Discard)
+		defer rec.Close()
+		return New(sd, &network.NoLimits, opts...).Conversations(context.Background(), rec, items())
+	}
+	t.Run("aborts without error function", func(t *testing.T) {
+		assert.Error(t, run(t))
+	})
+	t.Run("skips with error function", func(t *testing.T) {
+		var failed []Result
+		err := run(t, OptErrorFn(func(sr Result) error {
+			failed = append(failed, sr)
+			return nil
+		}))
+		assert.NoError(t, err)
+		if assert.Len(t, failed, 1) {
+			assert.Equal(t, RTChannel, failed[0].Type)
+			assert.Equal(t, "CMISSING", failed[0].ChannelID)
+			assert.Error(t, failed[0].Err)
+		}
+	})
+}
+
+// counter is the middleware, that counts the messages passing through it.
+type counter struct {
+	processor.Passthrough
+	messages, replies int
+}
+
+func (c *counter) Messages(ctx context.Context, channelID string, numThreads int, isLast bool, mm []slack.Message) error {
+	c.messages += len(mm)
+	return c.Passthrough.Messages(ctx, channelID, numThreads, isLast, mm)
+}
+
+func (c *counter) ThreadMessages(ctx context.Context, channelID string, parent slack.Message, threadOnly, isLast bool, replies []slack.Message) error {
+	c.replies += len(replies)
+	return c.Passthrough.ThreadMessages(ctx, channelID, parent, threadOnly, isLast, replies)
+}
+
+// noReplies is the middleware, that drops the thread replies.
+type noReplies struct {
+	processor.Passthrough
+}
+
+func (noReplies) ThreadMessages(context.Context, string, slack.Message, bool, bool, []slack.Message) error {
+	return nil
+}
+
+func TestStream_Conversations_middleware(t *testing.T) {
+	srv := chunktest.NewServer(threadFilesSource(t), "U123")
+	defer srv.Close()
+	sd := slack.New("test", slack.OptionAPIURL(srv.URL()))
+
+	var buf bytes.Buffer
+	rec := chunk.NewRecorder(&buf)
+	var stats *counter
+	mw := []processor.Middleware{
+		func(next processor.Conversations) processor.Conversations {
+			stats = &counter{Passthrough: processor.Passthrough{Conversations: next}}
+			return stats
+		},
+		func(next processor.Conversations) processor.Conversations {
+			return noReplies{processor.Passthrough{Conversations: next}}
+		},
+	}
+	items := make(chan structures.EntityItem, 1)
+	items <- structures.EntityItem{Id: "CTF1", Include: true}
+	close(items)
+
+	cs := New(sd, &network.NoLimits)
+	if err := cs.Conversations(context.Background(), rec, items, mw...); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// the statistics middleware is first in the chain, it sees the replies,
+	// that are dropped by the filter before they reach the recorder.
+	assert.Equal(t, 1, stats.messages)
+	assert.Equal(t, 2, stats.replies)
+
+	cf, err := chunk.FromReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cf.ForEach(func(c *chunk.Chunk) error {
+		if c != nil && c.Type == chunk.CThreadMessages {
+			t.Errorf("thread messages were recorded: %v", c.Messages)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"runtime/trace"
+	"time"
 
 	"github.com/rusq/slack"
 
+	"github.com/rusq/slackdump/v3/internal/structures"
 	"github.com/rusq/slackdump/v3/processor"
 )
 
@@ -23,26 +25,79 @@ func (cs *Stream) channelWorker(ctx context.Context, proc processor.Conversation
 			if !more {
 				return // channel closed
 			}
-			channel, err := cs.procChannelInfoWithUsers(ctx, proc, req.sl.Channel, req.sl.ThreadTS)
-			if err != nil {
-				results <- Result{Type: RTChannel, ChannelID: req.sl.Channel, Err: err}
-				continue
-			}
-			if err := cs.channel(ctx, req, func(mm []slack.Message, isLast bool) error {
-				n, err := procChanMsg(ctx, proc, threadC, channel, isLast, mm)
-				if err != nil {
-					return err
-				}
-				results <- Result{Type: RTChannel, ChannelID: req.sl.Channel, ThreadCount: n, IsLast: isLast}
-				return nil
-			}); err != nil {
-				results <- Result{Type: RTChannel, ChannelID: req.sl.Channel, Err: err}
-				continue
-			}
+			cs.channelRequest(ctx, proc, results, threadC, req)
 		}
 	}
 }
 
+// channelRequest fetches the channel of the request req, including the
+// channel info and users.  The channel task is the parent of all the page
+// fetches of the channel, so that the stalls can be traced to the channel.
+func (cs *Stream) channelRequest(ctx context.Context, proc processor.Conversations, results chan<- Result, threadC chan<- request, req request) {
+	ctx, task := linkTask(ctx, "conversation", req.sl)
+	defer task.End()
+
+	if cs.split <= 1 {
+		ck, err := cs.loadCheckpoint(req)
+		if err != nil {
+			results <- Result{Type: RTChannel, ChannelID: req.sl.Channel, Err: err}
+			return
+		}
+		if ck != nil && ck.start.Done {
+			results <- Result{Type: RTChannel, ChannelID: req.sl.Channel, IsLast: true}
+			return
+		}
+		req.ck = ck
+	}
+	channel, err := cs.procChannelInfoWithUsers(ctx, proc, req.sl.Channel, req.sl.ThreadTS)
+	if err != nil {
+		results <- Result{Type: RTChannel, ChannelID: req.sl.Channel, Err: err}
+		return
+	}
+	if req.sl.ThreadTS == "" {
+		// pins are recorded before the messages, so that they are present
+		// when the channel is transformed.
+		if err := cs.procPins(ctx, proc, req.sl.Channel); err != nil {
+			results <- Result{Type: RTChannel, ChannelID: req.sl.Channel, Err: err}
+			return
+		}
+	}
+	cb := func(mm []slack.Message, isLast bool) error {
+		cs.resolveBots(ctx, mm)
+		cs.progress.AddMessages(len(mm))
+		n, err := procChanMsg(ctx, proc, threadC, channel, timeRange{Oldest: req.Oldest, Latest: req.Latest}, req.ck.current(), isLast, mm)
+		if err != nil {
+			return err
+		}
+		results <- Result{Type: RTChannel, ChannelID: req.sl.Channel, ThreadCount: n, IsLast: isLast}
+		return nil
+	}
+	if cs.split > 1 {
+		var created time.Time
+		if channel.Created > 0 {
+			created = channel.Created.Time()
+		}
+		err = cs.splitChannel(ctx, req, created, cb)
+	} else {
+		err = cs.channel(ctx, req, cb)
+	}
+	if err != nil {
+		results <- Result{Type: RTChannel, ChannelID: req.sl.Channel, Err: err}
+	}
+}
+
+// linkTask starts the trace task for the slack link.  The trace viewer groups
+// the tasks by name, so the names are fixed, and the concurrent tasks are told
+// apart by the "channel_id" and "thread_ts" annotations.
+func linkTask(ctx context.Context, name string, sl *structures.SlackLink) (context.Context, *trace.Task) {
+	ctx, task := trace.NewTask(ctx, name)
+	trace.Log(ctx, "channel_id", sl.Channel)
+	if sl.ThreadTS != "" {
+		trace.Log(ctx, "thread_ts", sl.ThreadTS)
+	}
+	return ctx, task
+}
+
 func (cs *Stream) threadWorker(ctx context.Context, proc processor.Conversations, results chan<- Result, threadReq <-chan request) {
 	ctx, task := trace.NewTask(ctx, "threadWorker")
 	defer task.End()
@@ -61,6 +116,18 @@ func (cs *Stream) threadWorker(ctx context.Context, proc processor.Conversations
 				continue
 			}
 
+			ck, err := cs.loadCheckpoint(req)
+			if err != nil {
+				results <- Result{Type: RTThread, ChannelID: req.sl.Channel, ThreadTS: req.sl.ThreadTS, Err: err, threadOnly: req.threadOnly}
+				continue
+			}
+			if ck != nil && ck.start.Done {
+				req.mark.threadDone()
+				results <- Result{Type: RTThread, ChannelID: req.sl.Channel, ThreadTS: req.sl.ThreadTS, IsLast: true, threadOnly: req.threadOnly}
+				continue
+			}
+			req.ck = ck
+
 			channel := new(slack.Channel)
 			if req.threadOnly {
 				var err error
@@ -72,16 +139,30 @@ func (cs *Stream) threadWorker(ctx context.Context, proc processor.Conversations
 				// hackety hack
 				channel.ID = req.sl.Channel
 			}
+			headDone := false
 			if err := cs.thread(ctx, req, func(msgs []slack.Message, isLast bool) error {
+				cs.resolveBots(ctx, msgs)
+				cs.progress.AddMessages(len(msgs) - 1) // the parent is repeated on each page.
+				if req.threadOnly && !headDone && len(msgs) > 0 {
+					// when only the thread is requested, the thread parent
+					// does not go through the channel messages, so its files
+					// are collected here, once, as slack returns the parent
+					// with every page of replies.
+					if err := procFiles(ctx, proc, channel, msgs[0]); err != nil {
+						return err
+					}
+					headDone = true
+				}
 				if err := procThreadMsg(ctx, proc, channel, req.sl.ThreadTS, req.threadOnly, isLast, msgs); err != nil {
 					return err
 				}
-				results <- Result{Type: RTThread, ChannelID: req.sl.Channel, ThreadTS: req.sl.ThreadTS, IsLast: isLast}
+				results <- Result{Type: RTThread, ChannelID: req.sl.Channel, ThreadTS: req.sl.ThreadTS, IsLast: isLast, threadOnly: req.threadOnly}
 				return nil
 			}); err != nil {
-				results <- Result{Type: RTThread, ChannelID: req.sl.Channel, ThreadTS: req.sl.ThreadTS, Err: err}
+				results <- Result{Type: RTThread, ChannelID: req.sl.Channel, ThreadTS: req.sl.ThreadTS, Err: err, threadOnly: req.threadOnly}
 				continue
 			}
+			req.mark.threadDone()
 		}
 	}
 }
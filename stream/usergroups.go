@@ -0,0 +1,67 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/trace"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/internal/network"
+	"github.com/rusq/slackdump/v3/processor"
+)
+
+// userGroupLister is implemented by the clients, that can list the user
+// groups and their members, i.e. [slack.Client].
+type userGroupLister interface {
+	GetUserGroupsContext(ctx context.Context, options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error)
+	GetUserGroupMembersContext(ctx context.Context, userGroup string) ([]string, error)
+}
+
+// UserGroups fetches the user groups of the workspace, including the
+// disabled ones, with their members, and passes them to the processor.  The
+// members of the groups, that were returned without them, are fetched with
+// usergroups.users.list.
+func (cs *Stream) UserGroups(ctx context.Context, proc processor.UserGroups) error {
+	ctx, task := trace.NewTask(ctx, "UserGroups")
+	defer task.End()
+
+	ul, ok := cs.client.(userGroupLister)
+	if !ok {
+		return errors.New("client does not support the user groups")
+	}
+	var groups []slack.UserGroup
+	if err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "usergroups.list"), cs.limits.adaptive, cs.limits.usergroups, cs.limits.tier.Tier2.Retries, func() error {
+		var err error
+		groups, err = ul.GetUserGroupsContext(ctx,
+			slack.GetUserGroupsOptionIncludeUsers(true),
+			slack.GetUserGroupsOptionIncludeCount(true),
+			slack.GetUserGroupsOptionIncludeDisabled(true),
+		)
+		return err
+	}); err != nil {
+		return fmt.Errorf("API error: %w", err)
+	}
+	for i := range groups {
+		if len(groups[i].Users) > 0 || groups[i].UserCount == 0 {
+			continue
+		}
+		var members []string
+		if err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "usergroups.users.list"), cs.limits.adaptive, cs.limits.usergroups, cs.limits.tier.Tier2.Retries, func() error {
+			var err error
+			members, err = ul.GetUserGroupMembersContext(ctx, groups[i].ID)
+			return err
+		}); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// i.e. disabled groups have no members to list.
+			slog.WarnContext(ctx, "unable to get the user group members", "usergroup_id", groups[i].ID, "error", err)
+			continue
+		}
+		groups[i].Users = members
+	}
+	return proc.UserGroups(ctx, groups)
+}
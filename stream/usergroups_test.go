@@ -0,0 +1,71 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v3/internal/network"
+)
+
+// fakeUserGroupSlacker returns the user groups, and the members of the
+// groups from the map.
+type fakeUserGroupSlacker struct {
+	Slacker
+	groups  []slack.UserGroup
+	members map[string][]string
+	calls   int
+}
+
+func (f *fakeUserGroupSlacker) GetUserGroupsContext(ctx context.Context, options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	return f.groups, nil
+}
+
+func (f *fakeUserGroupSlacker) GetUserGroupMembersContext(ctx context.Context, userGroup string) ([]string, error) {
+	f.calls++
+	m, ok := f.members[userGroup]
+	if !ok {
+		return nil, errors.New("no_such_subteam")
+	}
+	return m, nil
+}
+
+type userGroupCollector []slack.UserGroup
+
+func (c *userGroupCollector) UserGroups(ctx context.Context, groups []slack.UserGroup) error {
+	*c = append(*c, groups...)
+	return nil
+}
+
+func TestStream_UserGroups(t *testing.T) {
+	cl := &fakeUserGroupSlacker{
+		groups: []slack.UserGroup{
+			{ID: "S1", Handle: "devs", UserCount: 2, Users: []string{"U1", "U2"}},
+			{ID: "S2", Handle: "ops", UserCount: 1},
+			{ID: "S3", Handle: "empty"},
+			{ID: "S4", Handle: "gone", UserCount: 1},
+		},
+		members: map[string][]string{"S2": {"U3"}},
+	}
+	cs := New(cl, &network.NoLimits)
+	var got userGroupCollector
+	if err := cs.UserGroups(context.Background(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, got, 4) {
+		assert.Equal(t, []string{"U1", "U2"}, got[0].Users)
+		assert.Equal(t, []string{"U3"}, got[1].Users)
+		assert.Empty(t, got[2].Users)
+		assert.Empty(t, got[3].Users, "failed lookup is skipped")
+	}
+	assert.Equal(t, 2, cl.calls, "members are fetched only for S2 and S4")
+}
+
+func TestStream_UserGroups_unsupported(t *testing.T) {
+	cs := New(&fakeBotSlacker{}, &network.NoLimits)
+	var got userGroupCollector
+	assert.Error(t, cs.UserGroups(context.Background(), &got))
+}
@@ -0,0 +1,62 @@
+package stream
+
+import (
+	"context"
+	"log/slog"
+	"runtime/trace"
+
+	"github.com/rusq/slack"
+
+	"github.com/rusq/slackdump/v3/types"
+)
+
+// teamInformer is implemented by the clients, that can fetch the team
+// information with the public API, i.e. [slack.Client].
+type teamInformer interface {
+	GetTeamInfoContext(ctx context.Context) (*slack.TeamInfo, error)
+	GetBillableInfoContext(ctx context.Context, params slack.GetBillableInfoParams) (map[string]slack.BillingActive, error)
+}
+
+// workspaceDescriber is implemented by the clients, that can get the
+// workspace details not available with the public API, i.e. the plan and the
+// Enterprise Grid organisation, as the edge client does.
+type workspaceDescriber interface {
+	DescribeWorkspace(ctx context.Context, atr *slack.AuthTestResponse, wd *types.Workspace) error
+}
+
+// WorkspaceDetails returns the extended workspace information: the team
+// info, the billable info, and, if the client supports it, the plan and the
+// Enterprise Grid organisation.  Each piece of information is optional, as
+// it might not be permitted for the current user, i.e. the billable info is
+// available only to the admins, so the errors are logged and ignored.  It
+// never returns nil.
+func (cs *Stream) WorkspaceDetails(ctx context.Context, atr *slack.AuthTestResponse) *types.Workspace {
+	ctx, task := trace.NewTask(ctx, "WorkspaceDetails")
+	defer task.End()
+
+	var wd types.Workspace
+	if ti, ok := cs.client.(teamInformer); ok {
+		info, err := ti.GetTeamInfoContext(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "unable to get the team info", "error", err)
+		} else {
+			wd.TeamInfo = info
+		}
+		bi, err := ti.GetBillableInfoContext(ctx, slack.GetBillableInfoParams{})
+		if err != nil {
+			// not an admin, or a free workspace.
+			slog.DebugContext(ctx, "billable info is not available", "error", err)
+		} else {
+			wd.BillableInfo = bi
+		}
+	}
+	if wsd, ok := cs.client.(workspaceDescriber); ok {
+		if err := wsd.DescribeWorkspace(ctx, atr, &wd); err != nil {
+			slog.WarnContext(ctx, "unable to get the workspace details", "error", err)
+		}
+	}
+	if wd.Enterprise == nil && atr != nil && atr.EnterpriseID != "" {
+		wd.Enterprise = &types.Enterprise{ID: atr.EnterpriseID}
+	}
+	return &wd
+}
@@ -0,0 +1,68 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rusq/slackdump/v3/internal/network"
+	"github.com/rusq/slackdump/v3/types"
+)
+
+// fakeTeamSlacker returns the team info, and fails the billable info
+// request, as it happens for non-admin users.
+type fakeTeamSlacker struct {
+	Slacker
+	info *slack.TeamInfo
+}
+
+func (f *fakeTeamSlacker) GetTeamInfoContext(ctx context.Context) (*slack.TeamInfo, error) {
+	return f.info, nil
+}
+
+func (f *fakeTeamSlacker) GetBillableInfoContext(ctx context.Context, params slack.GetBillableInfoParams) (map[string]slack.BillingActive, error) {
+	return nil, errors.New("not_allowed_token_type")
+}
+
+// fakeDescriberSlacker additionally describes the workspace.
+type fakeDescriberSlacker struct {
+	fakeTeamSlacker
+}
+
+func (f *fakeDescriberSlacker) DescribeWorkspace(ctx context.Context, atr *slack.AuthTestResponse, wd *types.Workspace) error {
+	wd.Plan = "enterprise"
+	wd.Enterprise = &types.Enterprise{ID: atr.EnterpriseID, Name: "Acme Corp"}
+	return nil
+}
+
+func TestStream_WorkspaceDetails(t *testing.T) {
+	info := &slack.TeamInfo{ID: "T1", Name: "Acme", Domain: "acme"}
+	atr := &slack.AuthTestResponse{TeamID: "T1", Team: "Acme", EnterpriseID: "E1"}
+
+	t.Run("public API", func(t *testing.T) {
+		cs := New(&fakeTeamSlacker{info: info}, &network.NoLimits)
+		got := cs.WorkspaceDetails(context.Background(), atr)
+		assert.Equal(t, &types.Workspace{
+			TeamInfo:   info,
+			Enterprise: &types.Enterprise{ID: "E1"},
+		}, got)
+	})
+	t.Run("describer", func(t *testing.T) {
+		cs := New(&fakeDescriberSlacker{fakeTeamSlacker{info: info}}, &network.NoLimits)
+		got := cs.WorkspaceDetails(context.Background(), atr)
+		assert.Equal(t, &types.Workspace{
+			TeamInfo:   info,
+			Plan:       "enterprise",
+			Enterprise: &types.Enterprise{ID: "E1", Name: "Acme Corp"},
+		}, got)
+	})
+	t.Run("not supported", func(t *testing.T) {
+		cs := New(&fakeBotSlacker{}, &network.NoLimits)
+		got := cs.WorkspaceDetails(context.Background(), &slack.AuthTestResponse{TeamID: "T1"})
+		assert.NotNil(t, got)
+		assert.True(t, got.IsEmpty())
+	})
+}
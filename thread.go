@@ -17,6 +17,19 @@ import (
 
 type threadFunc func(ctx context.Context, l *rate.Limiter, channelID string, threadTS string, oldest, latest time.Time, processFn ...ProcessFunc) ([]types.Message, error)
 
+// DumpThread dumps a single thread identified by channelID and threadTS, with
+// all the replies and their reactions.  If the file download is enabled in
+// the session options, it also downloads the files attached to the thread
+// messages.  oldest and latest set the timeframe of the replies, zero values
+// mean no limit.
+func (s *Session) DumpThread(ctx context.Context, channelID, threadTS string, oldest, latest time.Time, processFn ...ProcessFunc) (*types.Conversation, error) {
+	sl := structures.SlackLink{Channel: channelID, ThreadTS: threadTS}
+	if !sl.IsThread() {
+		return nil, errors.New("channel ID and thread timestamp are required")
+	}
+	return s.dumpWithFiles(ctx, sl, oldest, latest, processFn...)
+}
+
 // dumpThreadAsConversation dumps a single thread identified by (channelID,
 // threadTS). Optionally one can provide a number of processFn that will be
 // applied to each chunk of messages returned by one API call.
@@ -110,7 +123,7 @@ func (s *Session) dumpThread(
 			nextCursor string
 		)
 		reqStart := time.Now()
-		if err := network.WithRetry(ctx, l, s.cfg.limits.Tier3.Retries, func() error {
+		if err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "conversations.replies"), s.adaptive, l, s.cfg.limits.Tier3.Retries, func() error {
 			var err error
 			trace.WithRegion(ctx, "GetConversationRepliesContext", func() {
 				msgs, hasmore, nextCursor, err = s.client.GetConversationRepliesContext(
@@ -137,6 +150,9 @@ func (s *Session) dumpThread(
 		// strip the first message after the first call to avoid duplicates.
 		if 0 < i && 1 < len(msgs) {
 			msgs = msgs[1:]
+			s.cfg.progress.AddMessages(len(msgs))
+		} else {
+			s.cfg.progress.AddMessages(len(msgs) - 1) // thread parent is counted with the channel.
 		}
 		thread = append(thread, types.ConvertMsgs(msgs)...)
 
@@ -178,6 +178,37 @@ func TestSession_DumpThreadWithFiles(t *testing.T) {
 	}
 }
 
+func TestSession_DumpThread(t *testing.T) {
+	t.Run("channel or thread is empty", func(t *testing.T) {
+		sd := &Session{cfg: defConfig, log: slog.Default()}
+		if _, err := sd.DumpThread(context.Background(), "CHANNEL", "", time.Time{}, time.Time{}); err == nil {
+			t.Error("expected error on empty thread")
+		}
+		if _, err := sd.DumpThread(context.Background(), "", "THREAD", time.Time{}, time.Time{}); err == nil {
+			t.Error("expected error on empty channel")
+		}
+	})
+	t.Run("ok", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mc := NewmockClienter(ctrl)
+		mc.EXPECT().
+			GetConversationRepliesContext(
+				gomock.Any(),
+				&slack.GetConversationRepliesParameters{ChannelID: "CHANNEL", Timestamp: "1638497751.040300", Limit: network.DefLimits.Request.Replies, Inclusive: true},
+			).
+			Return([]slack.Message{testMsg2.Message, testMsg1.Message}, false, "", nil).
+			Times(1)
+		mockConvInfo(mc, "CHANNEL", "channel_name")
+
+		sd := &Session{client: mc, cfg: defConfig, log: slog.Default()}
+		got, err := sd.DumpThread(context.Background(), "CHANNEL", "1638497751.040300", time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, &types.Conversation{Name: "channel_name", ID: "CHANNEL", ThreadTS: "1638497751.040300", Messages: []types.Message{testMsg1, testMsg2}}, got)
+	})
+}
+
 func TestSession_populateThreads(t *testing.T) {
 	type args struct {
 		ctx       context.Context
@@ -1,5 +1,7 @@
 package types
 
+import "github.com/rusq/slack"
+
 // Conversation keeps the slice of messages.
 type Conversation struct {
 	// ID is the channel ID.
@@ -9,8 +11,19 @@ type Conversation struct {
 	ThreadTS string `json:"thread_ts,omitempty"`
 	// Name is the channel name.
 	Name string `json:"name"`
+	// IsArchived is true, if the channel is archived.
+	IsArchived bool `json:"is_archived,omitempty"`
+	// IsExtShared is true, if the channel is shared with the external
+	// organisations (Slack Connect).
+	IsExtShared bool `json:"is_ext_shared,omitempty"`
 	// Messages is a slice of messages.
 	Messages []Message `json:"messages"`
+	// Pins is a slice of the pinned items of the channel, it is populated
+	// only if the pins were fetched.
+	Pins []slack.Item `json:"pins,omitempty"`
+	// Bookmarks is a slice of the channel bookmarks, it is populated only
+	// if the bookmarks were fetched.
+	Bookmarks []slack.Bookmark `json:"bookmarks,omitempty"`
 }
 
 func (c Conversation) String() string {
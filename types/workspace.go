@@ -0,0 +1,81 @@
+package types
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rusq/slack"
+)
+
+// FreePlanHistory is the message history available on the free plan
+// workspaces, the API does not return the messages older than that.
+const FreePlanHistory = 90 * 24 * time.Hour
+
+// planFree is the plan of the free workspaces.
+const planFree = "free"
+
+// Workspace is the extended information about the workspace, that
+// complements the [slack.AuthTestResponse], so that the archive describes
+// which workspace and plan it came from.  The fields that are not available
+// to the current user are empty.
+type Workspace struct {
+	// TeamInfo is the output of the team.info API.
+	TeamInfo *slack.TeamInfo `json:"team_info,omitempty"`
+	// Plan is the workspace plan, i.e. "std" or "enterprise".  It is only
+	// available to the web client.
+	Plan string `json:"plan,omitempty"`
+	// Enterprise is the Enterprise Grid organisation, if the workspace is a
+	// part of it.
+	Enterprise *Enterprise `json:"enterprise,omitempty"`
+	// BillableInfo is the billing status of the workspace users by the user
+	// ID, the output of the team.billableInfo API.  It is only available to
+	// the workspace admins.
+	BillableInfo map[string]slack.BillingActive `json:"billable_info,omitempty"`
+}
+
+// Enterprise is the Enterprise Grid organisation information.
+type Enterprise struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// Workspaces are the organisation workspaces that the current user is a
+	// member of.
+	Workspaces []EnterpriseWorkspace `json:"workspaces,omitempty"`
+}
+
+// EnterpriseWorkspace is the workspace of the Enterprise Grid organisation.
+type EnterpriseWorkspace struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Domain string `json:"domain,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+// IsEmpty returns true if none of the information is available.
+func (w *Workspace) IsEmpty() bool {
+	return w == nil || (w.TeamInfo == nil && w.Plan == "" && w.Enterprise == nil && len(w.BillableInfo) == 0)
+}
+
+// IsFreePlan returns true if the workspace is known to be on the free plan.
+// The plan is only available to the web client, so false is returned if
+// it's unknown.
+func (w *Workspace) IsFreePlan() bool {
+	return w != nil && strings.EqualFold(w.Plan, planFree)
+}
+
+// AccessibleFrom returns the time of the oldest message that the API returns
+// at the time now, or zero time, if the history is not limited.
+func (w *Workspace) AccessibleFrom(now time.Time) time.Time {
+	if !w.IsFreePlan() {
+		return time.Time{}
+	}
+	return now.Add(-FreePlanHistory)
+}
+
+// UserCount returns the number of the workspace users, or zero, if it's
+// unknown.  It is only available to the workspace admins.
+func (w *Workspace) UserCount() int {
+	if w == nil {
+		return 0
+	}
+	return len(w.BillableInfo)
+}
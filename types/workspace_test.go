@@ -0,0 +1,36 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rusq/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspace_AccessibleFrom(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		w    *Workspace
+		want time.Time
+	}{
+		{"nil", nil, time.Time{}},
+		{"unknown plan", &Workspace{}, time.Time{}},
+		{"standard", &Workspace{Plan: "std"}, time.Time{}},
+		{"free", &Workspace{Plan: "free"}, now.Add(-90 * 24 * time.Hour)},
+		{"free uppercase", &Workspace{Plan: "FREE"}, now.Add(-90 * 24 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.w.AccessibleFrom(now))
+		})
+	}
+}
+
+func TestWorkspace_UserCount(t *testing.T) {
+	var w *Workspace
+	assert.Equal(t, 0, w.UserCount())
+	w = &Workspace{BillableInfo: map[string]slack.BillingActive{"U1": {}, "U2": {}}}
+	assert.Equal(t, 2, w.UserCount())
+}
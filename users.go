@@ -47,7 +47,7 @@ func (s *Session) fetchUsers(ctx context.Context) (types.Users, error) {
 	)
 
 	l := s.limiter(network.Tier2)
-	if err := network.WithRetry(ctx, l, s.cfg.limits.Tier2.Retries, func() error {
+	if err := network.WithAdaptiveRetry(network.WithEndpoint(ctx, "users.list"), s.adaptive, l, s.cfg.limits.Tier2.Retries, func() error {
 		var err error
 		users, err = s.client.GetUsersContext(ctx)
 		return err